@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// maxRateLimitClients bounds the clients tracked by the rate limiter; idle
+// clients are forgotten first when it is reached, then the least recent one
+const maxRateLimitClients = 10000
+
+// accessPolicy decides which requests the server accepts
+type accessPolicy struct {
+	tokens   []string // Accepted bearer tokens; empty to allow anonymous access
+	readOnly bool
+	limiter  *rateLimiter // nil for no rate limit
+}
+
+// loadAccessPolicy builds the access policy from the [serve] table of
+// config.toml, overridden by the serve command's flags
+func loadAccessPolicy(cmd *cobra.Command) (*accessPolicy, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	settings := config.Serve{}
+	if cfg.Serve != nil {
+		settings = *cfg.Serve
+	}
+
+	if cmd.Flags().Changed("token-env") {
+		settings.TokenEnv, _ = cmd.Flags().GetString("token-env")
+	}
+	if cmd.Flags().Changed("rate-limit") {
+		settings.RateLimit, _ = cmd.Flags().GetFloat64("rate-limit")
+	}
+	if cmd.Flags().Changed("burst") {
+		settings.Burst, _ = cmd.Flags().GetInt("burst")
+	}
+	if cmd.Flags().Changed("read-only") {
+		settings.ReadOnly, _ = cmd.Flags().GetBool("read-only")
+	}
+
+	p := &accessPolicy{readOnly: settings.ReadOnly}
+
+	if settings.TokenEnv != "" {
+		for _, token := range strings.Split(os.Getenv(settings.TokenEnv), ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				p.tokens = append(p.tokens, token)
+			}
+		}
+		if len(p.tokens) == 0 {
+			return nil, fmt.Errorf("no tokens found in environment variable %s", settings.TokenEnv)
+		}
+	}
+
+	if settings.RateLimit < 0 || settings.Burst < 0 {
+		return nil, fmt.Errorf("rate limit and burst cannot be negative")
+	}
+	if settings.RateLimit > 0 {
+		burst := settings.Burst
+		if burst == 0 {
+			burst = int(math.Ceil(settings.RateLimit))
+		}
+		p.limiter = newRateLimiter(settings.RateLimit, burst)
+	}
+
+	return p, nil
+}
+
+// accessError is a request refused by the access policy, with the HTTP
+// status it is answered with
+type accessError struct {
+	status     int
+	retryAfter time.Duration // For rate limited requests
+	msg        string
+}
+
+func (e *accessError) Error() string {
+	return e.msg
+}
+
+// allow applies the policy to a request, answering it with an error and
+// returning false when it is refused. queryToken accepts the token from the
+// query, for routes browsers reach without setting headers.
+func (p *accessPolicy) allow(w http.ResponseWriter, r *http.Request, queryToken bool) bool {
+	if err := p.admit(requestToken(r, queryToken), clientAddress(r)); err != nil {
+		if err.status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cartomancer"`)
+		}
+		if err.retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(err.retryAfter.Seconds()))))
+		}
+		http.Error(w, err.msg, err.status)
+		return false
+	}
+
+	if p.readOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "server is read-only", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// admit checks the token presented by a client at an address against the
+// accepted tokens and the rate limit. Requests of the HTTP and gRPC APIs are
+// admitted alike and share the client's rate limit.
+func (p *accessPolicy) admit(token, address string) *accessError {
+	authenticated := p.accepts(token)
+
+	// Clients are told apart by token when they have a valid one and by
+	// address otherwise, so failed logins are rate limited too. Without
+	// configured tokens, any token is accepted and tells nothing apart.
+	client := address
+	if authenticated && len(p.tokens) > 0 {
+		sum := sha256.Sum256([]byte(token))
+		client = "token:" + hex.EncodeToString(sum[:8])
+	}
+	if p.limiter != nil {
+		if wait, ok := p.limiter.allow(client, time.Now()); !ok {
+			return &accessError{status: http.StatusTooManyRequests, retryAfter: wait, msg: "rate limit exceeded"}
+		}
+	}
+
+	if !authenticated {
+		return &accessError{status: http.StatusUnauthorized, msg: "a valid token is required"}
+	}
+	return nil
+}
+
+// accepts reports whether a token is accepted. Any token, or none, is
+// accepted when no tokens are configured.
+func (p *accessPolicy) accepts(token string) bool {
+	if len(p.tokens) == 0 {
+		return true
+	}
+	if token == "" {
+		return false
+	}
+
+	for _, accepted := range p.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(accepted)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// requestToken returns the token presented with a request. Tokens are read
+// from the Authorization header, or with queryToken from the token query
+// parameter. Query tokens end up in access logs and Referer headers, so they
+// are only accepted for browser WebSocket clients, which cannot set headers.
+func requestToken(r *http.Request, queryToken bool) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && queryToken {
+		token = r.URL.Query().Get("token")
+	}
+	return token
+}
+
+// clientAddress returns the IP address a request came from
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter is a token bucket per client: each client may make burst
+// requests at once, refilled at rate requests per second
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket holds the requests a client has left
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter allowing rate requests per second per client
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: map[string]*bucket{}}
+}
+
+// allow takes a request from the client's bucket, returning how long to wait
+// for the next request when the bucket is empty
+func (l *rateLimiter) allow(client string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[client]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitClients {
+			l.forgetIdle(now)
+		}
+		if len(l.buckets) >= maxRateLimitClients {
+			l.forgetOldest()
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// forgetIdle drops clients whose buckets have refilled, as they are
+// indistinguishable from new clients
+func (l *rateLimiter) forgetIdle(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for client, b := range l.buckets {
+		if now.Sub(b.last) >= full {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// forgetOldest drops the client whose last request is the least recent, to
+// make room when no client is idle
+func (l *rateLimiter) forgetOldest() {
+	var oldest string
+	var oldestLast time.Time
+	found := false
+	for client, b := range l.buckets {
+		if !found || b.last.Before(oldestLast) {
+			oldest, oldestLast, found = client, b.last, true
+		}
+	}
+	delete(l.buckets, oldest)
+}
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/daily"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Timing and sizing of the altar display
+const (
+	altarTick        = 100 * time.Millisecond // Interval between redraws of the border colors
+	altarHueStep     = 1.5                    // Degrees the border hue moves each tick
+	altarHueSpread   = 40                     // Hue offset between neighbouring cards
+	altarMinWidth    = 8                      // Narrowest card, in columns
+	altarMaxWidth    = 40                     // Widest card, in columns
+	altarImageHeight = 480                    // Height images are scaled down to before rendering
+)
+
+var altarCmd = &cobra.Command{
+	Use:   "altar",
+	Short: "Show a slowly changing display of cards as a terminal dashboard",
+	Long: `Altar takes over the terminal with a decorative display: the card of the day
+pinned on the left and a row of cards beside it that turn over one at a time,
+framed by borders that slowly cycle through the colors. Cards are sized to the
+terminal and the layout follows it when the window is resized.
+
+The card of the day is the one shown by daily, and changes over at midnight.
+Press q, Esc or Ctrl-C to leave.
+
+Examples:
+  cartomancer altar
+  cartomancer altar -d thoth --interval 30s`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval < time.Second {
+			return fmt.Errorf("--interval must be at least 1s")
+		}
+
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+
+		ph := newPlaceholders(cmd)
+		defer ph.printSummary()
+
+		a := &altar{
+			deck:     d,
+			deckPath: deckPath,
+			theme:    t,
+			ph:       ph,
+			rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+		return a.run(interval)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(altarCmd)
+
+	altarCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	altarCmd.Flags().Duration("interval", 12*time.Second, "Time between cards turning over")
+	addBestEffortFlag(altarCmd)
+}
+
+// altarCard is a card on the altar with its image and rendered art
+type altarCard struct {
+	card  *card.Card
+	image image.Image // Nil when the card has no image
+	art   []string
+}
+
+// altar is the state of the altar display
+type altar struct {
+	deck     *deck.Deck
+	deckPath string
+	theme    *theme.Theme
+	ph       *placeholders
+	rng      *rand.Rand
+
+	date  string     // Date of the pinned card of the day
+	daily *altarCard // Card of the day, pinned to the left
+	slots []*altarCard
+	queue []*card.Card // Cards waiting to be shown, in shuffled order
+	next  int          // Slot that turns over next
+
+	opts       render.Options
+	cols, rows int
+	hue        float64
+}
+
+// run takes over the terminal until the user quits, turning over a card every
+// interval and redrawing the borders every tick
+func (a *altar) run(interval time.Duration) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("altar requires a terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("error entering raw mode: %v", err)
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	keys := make(chan string)
+	go func() {
+		buf := make([]byte, 8)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- string(buf[:n])
+		}
+	}()
+
+	if err := a.layout(); err != nil {
+		return err
+	}
+
+	tick := time.NewTicker(altarTick)
+	defer tick.Stop()
+	turn := time.NewTicker(interval)
+	defer turn.Stop()
+
+	for {
+		if err := a.draw(nil); err != nil {
+			return err
+		}
+
+		select {
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			switch key {
+			case "q", "Q", "\033", "\003":
+				return nil
+			}
+		case <-turn.C:
+			if err := a.turn(); err != nil {
+				return err
+			}
+		case <-tick.C:
+			a.hue = math.Mod(a.hue+altarHueStep, 360)
+
+			// Lay the cards out again when the window changes size
+			cols, rows := terminalSize()
+			if cols != a.cols || rows != a.rows {
+				if err := a.layout(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// terminalSize returns the size of the terminal, or 80x24 when unknown
+func terminalSize() (int, int) {
+	cols, rows, err := term.GetSize(int(terminalOut.Fd()))
+	if err != nil || cols <= 0 || rows <= 0 {
+		return 80, 24
+	}
+	return cols, rows
+}
+
+// layout sizes the cards to fill the terminal height and fills as many slots
+// as fit beside the card of the day
+func (a *altar) layout() error {
+	a.cols, a.rows = terminalSize()
+	fmt.Print("\033[2J")
+
+	if err := a.pinDaily(); err != nil {
+		return err
+	}
+
+	// Rows for the art, less the frame (4 rows), the header and the status line
+	aspect := float64(20) / 17 / 2
+	if a.daily.image != nil {
+		bounds := a.daily.image.Bounds()
+		aspect = float64(bounds.Dy()) / float64(bounds.Dx()) / 2
+	}
+	height := max(a.rows-8, 1)
+	width := min(max(int(float64(height)/aspect), altarMinWidth), altarMaxWidth, max(a.cols-6, altarMinWidth))
+	a.opts = viewOptions(render.Options{Width: width, Height: max(min(int(float64(width)*aspect), height), 1), TrueColor: true})
+
+	// Each framed card takes its width plus the border and a gap of two
+	// columns, with a wider gap after the card of the day and a margin
+	slots := max((a.cols-4-(width+2)-2)/(width+4), 0)
+	if len(a.slots) > slots {
+		a.slots = a.slots[:slots]
+	}
+	for i := range a.slots {
+		a.slots[i].art = a.renderArt(a.slots[i])
+	}
+	a.daily.art = a.renderArt(a.daily)
+	for len(a.slots) < slots {
+		a.slots = append(a.slots, a.load(a.nextCard()))
+	}
+	a.next %= max(len(a.slots), 1)
+	return nil
+}
+
+// pinDaily loads the card of the day, picking it as daily would when it is
+// not cached
+func (a *altar) pinDaily() error {
+	today := daily.DateString(time.Now())
+	if a.daily != nil && a.date == today {
+		return nil
+	}
+
+	var c *card.Card
+	if entry, ok := daily.Load(config.GetCacheDir(), today, a.deckPath); ok {
+		c, _ = a.deck.GetCard(entry.CardID)
+	}
+	if c == nil {
+		var err error
+		if c, err = daily.Pick(today, a.deck.ID, a.deck.Cards()); err != nil {
+			return err
+		}
+	}
+
+	a.date = today
+	a.daily = a.load(c)
+	return nil
+}
+
+// nextCard takes the next card from the shuffled queue, skipping the cards
+// already on the altar and reshuffling the deck when the queue runs out
+func (a *altar) nextCard() *card.Card {
+	for attempts := 0; attempts < 2*len(a.deck.Cards()); attempts++ {
+		if len(a.queue) == 0 {
+			a.queue = append([]*card.Card(nil), a.deck.Cards()...)
+			a.rng.Shuffle(len(a.queue), func(i, j int) {
+				a.queue[i], a.queue[j] = a.queue[j], a.queue[i]
+			})
+		}
+		c := a.queue[0]
+		a.queue = a.queue[1:]
+		if !a.showing(c) {
+			return c
+		}
+	}
+	return a.deck.Cards()[a.rng.Intn(len(a.deck.Cards()))]
+}
+
+// showing reports whether a card is already on the altar
+func (a *altar) showing(c *card.Card) bool {
+	if a.daily != nil && a.daily.card.ID == c.ID {
+		return true
+	}
+	for _, s := range a.slots {
+		if s.card.ID == c.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// load reads a card's image, falling back to placeholder art in best-effort mode
+func (a *altar) load(c *card.Card) *altarCard {
+	ac := &altarCard{card: c}
+	if img, err := loadViewImage(a.deck.AssetRoots(), c, altarImageHeight); err == nil {
+		ac.image = img
+	} else if img, ok := a.ph.image(c, placeholderWidth, placeholderHeight); ok {
+		ac.image = img
+	}
+	ac.art = a.renderArt(ac)
+	return ac
+}
+
+// renderArt renders a card's image at the current card size, or blank art
+// when it has none
+func (a *altar) renderArt(ac *altarCard) []string {
+	if ac.image != nil && a.opts.Width > 0 {
+		if lines, err := render.Lines(ac.image, a.opts); err == nil {
+			return lines
+		}
+	}
+	return blankArt(max(a.opts.Height, 1), max(a.opts.Width, 1))
+}
+
+// turn flips the next slot over to a new card, animating the change
+func (a *altar) turn() error {
+	if err := a.pinDaily(); err != nil {
+		return err
+	}
+	if len(a.slots) == 0 {
+		return nil
+	}
+
+	i := a.next
+	a.next = (a.next + 1) % len(a.slots)
+	old, replacement := a.slots[i], a.load(a.nextCard())
+
+	if old.image != nil && replacement.image != nil {
+		frames, err := render.FlipFrames(old.image, replacement.image, a.opts, flipSteps)
+		if err != nil {
+			return fmt.Errorf("error rendering flip animation: %v", err)
+		}
+		for _, frame := range frames {
+			if err := a.draw(map[int][]string{i: frame}); err != nil {
+				return err
+			}
+			time.Sleep(flipFrameDelay)
+		}
+	}
+
+	a.slots[i] = replacement
+	return nil
+}
+
+// draw writes the altar to the screen, centered in the terminal. Slots in
+// frames are drawn with the given art in place of their card's.
+func (a *altar) draw(frames map[int][]string) error {
+	accent := func(offset float64) *theme.Theme {
+		c := colorful.Hsv(math.Mod(a.hue+offset, 360), 0.45, 0.9)
+		r, g, b := c.RGB255()
+		return a.theme.WithAccent(r, g, b)
+	}
+
+	blocks := [][]string{frameArt(a.daily.art, a.daily.card.Name, frameAuto, accent(0))}
+	if len(a.slots) > 0 {
+		blocks = append(blocks, nil) // Extra gap setting the card of the day apart
+	}
+	for i, s := range a.slots {
+		art := s.art
+		if frame, ok := frames[i]; ok {
+			art = frame
+		}
+		blocks = append(blocks, frameArt(art, s.card.Name, frameAuto, accent(float64(i+1)*altarHueSpread)))
+	}
+	body := joinColumns(blocks, "  ")
+
+	header := a.theme.Heading.Sprint(a.deck.Name) + a.theme.Muted.Sprint("  ·  Card of the Day: ") +
+		a.theme.Value.Sprint(a.daily.card.Name)
+	status := a.theme.Muted.Sprint("q quit")
+
+	lines := append([]string{header, ""}, body...)
+	top := max((a.rows-1-len(lines))/2, 0)
+	indent := strings.Repeat(" ", max((a.cols-visibleWidth(body[0]))/2, 0))
+
+	// Raw mode needs explicit carriage returns
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("\033[H")
+	for i := 0; i < a.rows-1; i++ {
+		if j := i - top; j >= 0 && j < len(lines) {
+			out.WriteString(indent + lines[j])
+		}
+		out.WriteString("\033[K\r\n")
+	}
+	out.WriteString(indent + status + "\033[K")
+	return out.Flush()
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/alttext"
+	"github.com/arcanaland/cartomancer/internal/anki"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/spf13/cobra"
+)
+
+// ankiFields are the fields of the note type exported decks use, available to
+// templates as {{Name}}, {{Image}} and so on
+var ankiFields = []string{"Name", "Image", "Keywords", "Element", "AltText", "CardID"}
+
+// Default card templates and styling of exported decks
+const (
+	ankiFront = `<div class="art">{{Image}}</div>`
+	ankiBack  = `{{FrontSide}}
+
+<hr id="answer">
+
+<div class="name">{{Name}}</div>
+{{#Keywords}}<div class="keywords">{{Keywords}}</div>{{/Keywords}}
+{{#Element}}<div class="element">{{Element}}</div>{{/Element}}`
+	ankiCSS = `.card {
+  font-family: sans-serif;
+  font-size: 20px;
+  text-align: center;
+}
+.art img {
+  max-width: 100%;
+  max-height: 70vh;
+}
+.name {
+  font-size: 28px;
+  font-weight: bold;
+}
+.keywords {
+  margin-top: 0.5em;
+  font-style: italic;
+}
+.element {
+  margin-top: 0.5em;
+  text-transform: capitalize;
+}`
+)
+
+// exportAnkiCmd represents the export-anki command
+var exportAnkiCmd = &cobra.Command{
+	Use:   "export-anki",
+	Short: "Export a deck as an Anki package for spaced repetition study",
+	Long: `Export-anki writes an Anki package (.apkg) with one note per card: the card
+art on the front, and its name, traditional imagery and element on the back.
+Import the package in Anki with File > Import. Notes keep their identity across
+exports, so importing an updated package again updates the existing notes and
+keeps your review history.
+
+The templates use Anki's template syntax with the fields Name, Image, Keywords,
+Element, AltText and CardID. Replace the defaults with --front, --back and --css.
+
+Examples:
+  cartomancer export-anki -o tarot.apkg
+  cartomancer export-anki --deck rider-waite-smith -o rws.apkg
+  cartomancer export-anki --back back.html --css cards.css -o tarot.apkg`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		output, _ := cmd.Flags().GetString("output")
+		name, _ := cmd.Flags().GetString("name")
+
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		if output == "" {
+			output = d.ID + ".apkg"
+		}
+		if name == "" {
+			name = d.Name
+		}
+
+		pkg := &anki.Package{
+			DeckName:  name,
+			ModelName: "Cartomancer Tarot",
+			Fields:    ankiFields,
+			Front:     ankiFront,
+			Back:      ankiBack,
+			CSS:       ankiCSS,
+		}
+		for flag, field := range map[string]*string{"front": &pkg.Front, "back": &pkg.Back, "css": &pkg.CSS} {
+			filename, _ := cmd.Flags().GetString(flag)
+			if filename == "" {
+				continue
+			}
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return fmt.Errorf("error reading %s template: %v", flag, err)
+			}
+			*field = string(data)
+		}
+
+		missing := 0
+		for _, c := range d.Cards() {
+			image := ""
+			if path := ankiCardArt(d, c.ID); path != "" {
+				media := anki.MediaName(d.ID, c.ID+filepath.Ext(path))
+				pkg.Media = append(pkg.Media, anki.Media{Name: media, Path: path})
+				image = fmt.Sprintf(`<img src="%s" alt="%s">`, html.EscapeString(media), html.EscapeString(c.AltText))
+			} else {
+				missing++
+			}
+
+			tags := []string{"cartomancer", d.ID, c.Type}
+			if c.Suit != "" {
+				tags = append(tags, c.Suit)
+			}
+
+			pkg.Notes = append(pkg.Notes, anki.Note{
+				GUID: anki.GUID(d.ID, c.ID),
+				Fields: []string{
+					html.EscapeString(c.Name),
+					image,
+					html.EscapeString(alttext.Keywords(c)),
+					html.EscapeString(c.Element),
+					html.EscapeString(c.AltText),
+					c.ID,
+				},
+				Tags: tags,
+			})
+		}
+		if missing > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d cards have no art; their notes have no image\n", missing)
+		}
+
+		if err := pkg.Save(output); err != nil {
+			os.Remove(output)
+			return fmt.Errorf("error writing %s: %v", output, err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d cards to %s\n", len(pkg.Notes), output)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportAnkiCmd)
+
+	exportAnkiCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	exportAnkiCmd.Flags().StringP("output", "o", "", "Output file (default: <deck-id>.apkg)")
+	exportAnkiCmd.Flags().String("name", "", "Name of the deck in Anki (default: the deck's name)")
+	exportAnkiCmd.Flags().String("front", "", "File with the front template")
+	exportAnkiCmd.Flags().String("back", "", "File with the back template")
+	exportAnkiCmd.Flags().String("css", "", "File with the card styling")
+}
+
+// ankiCardArt returns the highest resolution art of a card, or "" when the
+// deck has none
+func ankiCardArt(d *deck.Deck, id string) string {
+	parts := strings.Split(id, ".")
+	for _, root := range d.AssetRoots() {
+		if path, err := findCardArt(root, parts); err == nil {
+			return path
+		}
+	}
+	return ""
+}
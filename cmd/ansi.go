@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// ansiWatchInterval is how often ansi preview --watch checks the art for changes
+const ansiWatchInterval = 300 * time.Millisecond
+
+// ansiCmd represents the ansi command
+var ansiCmd = &cobra.Command{
+	Use:   "ansi",
+	Short: "Author hand-crafted ANSI card art",
+	Long: `Tools for artists who draw a deck's terminal art directly rather than
+converting images: edit a card's .ansi file with a live preview, and preview
+and lint art as it will be shown.
+
+See ansi2png to turn ANSI art into images.`,
+}
+
+// ansiEditCmd represents the ansi edit command
+var ansiEditCmd = &cobra.Command{
+	Use:   "edit <card_id>",
+	Short: "Edit a card's ANSI art in $EDITOR with a live preview",
+	Long: `Edit opens the card's .ansi file in the deck's ansi32 directory (or another
+chosen with --tier) in $VISUAL or $EDITOR (falling back to vi), creating it if
+it does not exist yet.
+
+Inside tmux, a pane beside the editor shows the art as it will be drawn and
+redraws it on every save, along with the problems lint finds (see ansi
+preview). Elsewhere, run 'cartomancer ansi preview --watch' on the file in
+another terminal for the same. The art and its problems are printed when the
+editor exits.
+
+Examples:
+  cartomancer ansi edit 0 --deck ./my-deck
+  cartomancer ansi edit cups/queen --tier ansi256
+  cartomancer ansi edit XVII --no-preview`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := cardAnsiPath(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("error creating ANSI art directory: %v", err)
+			}
+			if err := os.WriteFile(path, nil, 0644); err != nil {
+				return fmt.Errorf("error creating ANSI art: %v", err)
+			}
+			fmt.Printf("Created %s\n", path)
+		}
+
+		if noPreview, _ := cmd.Flags().GetBool("no-preview"); !noPreview {
+			if closePane, err := openPreviewPane(path); err == nil {
+				defer closePane()
+			}
+		}
+		if err := runEditor(path); err != nil {
+			return err
+		}
+		_, err = printAnsiPreview(path)
+		return err
+	},
+}
+
+// ansiPreviewCmd represents the ansi preview command
+var ansiPreviewCmd = &cobra.Command{
+	Use:   "preview <card_id|file>",
+	Short: "Show ANSI art as it will be drawn and lint it",
+	Long: `Preview prints a .ansi file, or the ANSI art of a card in the deck's ansi32
+directory (or another chosen with --tier), followed by the problems that make
+hand-written art draw wrongly: lines of a different width from the rest,
+colors left set at the end of a line, which bleed past the art, escape
+sequences that are not colors and tabs.
+
+With --watch, the preview is redrawn whenever the file changes, until
+interrupted; ansi edit opens one beside the editor inside tmux.
+
+Examples:
+  cartomancer ansi preview 0 --deck ./my-deck
+  cartomancer ansi preview ./my-deck/ansi32/major_arcana/00.ansi --watch`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			if path, err = cardAnsiPath(cmd, args[0]); err != nil {
+				return err
+			}
+		}
+
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			return watchAnsiPreview(path)
+		}
+		problems, err := printAnsiPreview(path)
+		if err != nil {
+			return err
+		}
+		if problems > 0 {
+			return fmt.Errorf("ANSI art has problems")
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(ansiCmd)
+	ansiCmd.AddCommand(ansiEditCmd)
+	ansiCmd.AddCommand(ansiPreviewCmd)
+
+	for _, c := range []*cobra.Command{ansiEditCmd, ansiPreviewCmd} {
+		c.Flags().StringP("deck", "d", "", "Deck the card belongs to (default from config)")
+		c.Flags().String("tier", bakeTiers[0], "ANSI directory of the deck holding the art")
+	}
+	ansiEditCmd.Flags().Bool("no-preview", false, "Do not open a preview pane inside tmux")
+	ansiPreviewCmd.Flags().Bool("watch", false, "Redraw the preview whenever the file changes")
+}
+
+// cardAnsiPath returns the path of a card's ANSI art in the --tier directory
+// of the --deck deck
+func cardAnsiPath(cmd *cobra.Command, cardID string) (string, error) {
+	tier, _ := cmd.Flags().GetString("tier")
+	if !strings.HasPrefix(tier, "ansi") || filepath.Base(tier) != tier {
+		return "", fmt.Errorf("invalid tier: %s (ANSI directories are named ansi32, ansi256, ansi-<profile>, ...)", tier)
+	}
+
+	d, err := flagDeck(cmd)
+	if err != nil {
+		return "", err
+	}
+	c, err := d.GetCard(cardID)
+	if err != nil {
+		return "", err
+	}
+	return buildCardPath(filepath.Join(d.Path, tier), strings.Split(c.ID, "."), ".ansi")
+}
+
+// printAnsiPreview prints a file of ANSI art followed by its lint problems,
+// returning the number of problems
+func printAnsiPreview(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("error reading ANSI art: %v", err)
+	}
+	if len(data) > 0 {
+		// Reset the colors in case the art leaves them set
+		fmt.Print(strings.TrimSuffix(string(data), "\n") + "\033[0m\n\n")
+	}
+
+	problems := render.LintANSI(string(data))
+	if len(problems) == 0 {
+		fmt.Printf("✅ No problems found in '%s'.\n", path)
+		return 0, nil
+	}
+	fmt.Printf("❌ '%s' has %d problems:\n", path, len(problems))
+	for i, problem := range problems {
+		fmt.Printf("%d. %s\n", i+1, problem)
+	}
+	return len(problems), nil
+}
+
+// watchAnsiPreview redraws the preview of a file of ANSI art whenever it
+// changes, until interrupted
+func watchAnsiPreview(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("error watching ANSI art: %v", err)
+	}
+
+	var shown time.Time
+	for {
+		// Editors that save by replacing the file leave it missing for a
+		// moment, so errors wait for the next check
+		if info, err := os.Stat(path); err == nil && !info.ModTime().Equal(shown) {
+			shown = info.ModTime()
+			fmt.Print("\033[H\033[2J")
+			if _, err := printAnsiPreview(path); err != nil {
+				fmt.Println(err)
+			}
+			fmt.Printf("\nWatching for changes, updated %s\n", shown.Format("15:04:05"))
+		}
+		time.Sleep(ansiWatchInterval)
+	}
+}
+
+// openPreviewPane splits the tmux window to show a watching preview of the
+// art beside the editor, returning a function that closes the pane. It fails
+// outside tmux.
+func openPreviewPane(path string) (func(), error) {
+	if os.Getenv("TMUX") == "" {
+		return nil, fmt.Errorf("not running inside tmux")
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// -d keeps the editor's pane active; -P prints the new pane's ID
+	preview := shellQuote(self) + " ansi preview --watch " + shellQuote(absPath)
+	out, err := exec.Command("tmux", "split-window", "-h", "-d", "-P", "-F", "#{pane_id}", preview).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error opening preview pane: %v", err)
+	}
+	pane := strings.TrimSpace(string(out))
+	return func() {
+		exec.Command("tmux", "kill-pane", "-t", pane).Run()
+	}, nil
+}
+
+// shellQuote quotes a word for the shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/composite"
+	"github.com/spf13/cobra"
+)
+
+// ansi2pngCmd represents the ansi2png command
+var ansi2pngCmd = &cobra.Command{
+	Use:   "ansi2png <file.ansi>",
+	Short: "Convert an ANSI art file to a PNG image",
+	Long: `Ansi2png draws an ANSI art file as a terminal would show it and saves it as a
+PNG image, so decks made of hand-crafted ANSI art can have previews, registry
+thumbnails and images for exports.
+
+Half blocks and the other block elements fill their part of each character
+cell, so art rendered by cartomancer converts pixel for pixel; other characters
+are drawn in a built-in bitmap font. Each character cell is 6x12 font pixels,
+and --scale sets the size of a font pixel in image pixels. Use "-" to read the
+art from stdin.
+
+Examples:
+  cartomancer ansi2png ansi32/major_arcana/00.ansi -o fool.png
+  cartomancer ansi2png fool.ansi --scale 4
+  cartomancer render XVII | cartomancer ansi2png - -o star.png`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return fmt.Errorf("error reading ANSI art: %v", err)
+		}
+
+		scale, _ := cmd.Flags().GetInt("scale")
+		if scale < 1 {
+			return fmt.Errorf("--scale must be positive")
+		}
+
+		outputPath, _ := cmd.Flags().GetString("output")
+		if outputPath == "" {
+			if args[0] == "-" {
+				return fmt.Errorf("--output is required when reading from stdin")
+			}
+			outputPath = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0])) + ".png"
+		}
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("error creating image: %v", err)
+		}
+		defer file.Close()
+
+		if err := png.Encode(file, composite.RasterizeANSI(string(data), scale)); err != nil {
+			return fmt.Errorf("error encoding image: %v", err)
+		}
+
+		fmt.Printf("Image saved to %s\n", outputPath)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(ansi2pngCmd)
+
+	ansi2pngCmd.Flags().StringP("output", "o", "", "Output file (default: the input's name with .png)")
+	ansi2pngCmd.Flags().Int("scale", 2, "Size of a font pixel in image pixels")
+}
+
+// loadAnsiArtImage draws the card's ANSI art shipped with the deck as an image
+func loadAnsiArtImage(roots []string, c *card.Card) (image.Image, error) {
+	parts := strings.Split(c.ID, ".")
+	for _, root := range roots {
+		if path, ok := findPrebuiltAnsi(root, parts); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return composite.RasterizeANSI(string(data), 1), nil
+		}
+	}
+	return nil, fmt.Errorf("no ANSI art found for card: %s", c.ID)
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/pool"
+	"github.com/arcanaland/cartomancer/internal/spread"
+)
+
+// maxDrawCount bounds the count parameter of the draw endpoint
+const maxDrawCount = 78
+
+// apiDeck describes a library deck in the JSON API
+type apiDeck struct {
+	ID          string    `json:"id"` // Library directory name, used in URLs
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Default     bool      `json:"default,omitempty"`
+	CardCount   int       `json:"card_count"`
+	Cards       []apiCard `json:"cards,omitempty"`
+}
+
+// apiCard describes a card in the JSON API
+type apiCard struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Suit     string `json:"suit,omitempty"`
+	AltText  string `json:"alt_text,omitempty"`
+	Image    string `json:"image"`              // Path of the card image endpoint
+	Position string `json:"position,omitempty"` // Spread position, for drawn cards
+}
+
+// apiSpread describes a spread in the JSON API
+type apiSpread struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Positions []string `json:"positions"`
+}
+
+// apiDraw is the result of a draw
+type apiDraw struct {
+	Deck   string    `json:"deck"`
+	Pool   string    `json:"pool"`
+	Spread string    `json:"spread,omitempty"`
+	Seed   int64     `json:"seed"`
+	Cards  []apiCard `json:"cards"`
+}
+
+// newAPICard describes a card of the library deck with the given ID
+func newAPICard(deckID string, c *card.Card) apiCard {
+	return apiCard{
+		ID:      c.ID,
+		Name:    c.Name,
+		Type:    c.Type,
+		Suit:    c.Suit,
+		AltText: c.AltText,
+		Image:   "/decks/" + url.PathEscape(deckID) + "/cards/" + url.PathEscape(c.ID) + "/image",
+	}
+}
+
+// apiError is a refused API request with the HTTP status it is answered with
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) Error() string {
+	return e.msg
+}
+
+// errorStatus returns the HTTP status for an error of the API
+func errorStatus(err error) int {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.status
+	}
+	var accessErr *accessError
+	if errors.As(err, &accessErr) {
+		return accessErr.status
+	}
+	return deckErrorStatus(err)
+}
+
+// writeJSON answers a request with a value encoded as JSON
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// writeError answers a request with an error of the API
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), errorStatus(err))
+}
+
+// handleDecks lists the decks in the deck library
+func (s *server) handleDecks(w http.ResponseWriter, r *http.Request) {
+	decks, err := s.listDecks()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, decks)
+}
+
+// handleDeck describes a library deck and its cards
+func (s *server) handleDeck(w http.ResponseWriter, r *http.Request) {
+	info, err := s.describeDeck(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, info)
+}
+
+// handleSpreads lists the built-in and custom spreads
+func (s *server) handleSpreads(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, listSpreads())
+}
+
+// handleDraw draws from a library deck; the seed parameter repeats a draw
+func (s *server) handleDraw(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	count := 1
+	if v := query.Get("count"); v != "" {
+		var err error
+		if count, err = strconv.Atoi(v); err != nil {
+			writeError(w, errDrawCount)
+			return
+		}
+	}
+	seed := time.Now().UnixNano()
+	if v := query.Get("seed"); v != "" {
+		var err error
+		if seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			http.Error(w, "seed must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	result, err := s.draw(r.PathValue("id"), count, query.Get("pool"), query.Get("spread"), seed)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, result)
+}
+
+// listDecks describes the decks in the deck library, without their cards.
+// Directories that do not hold a valid deck are left out, as by deck ls.
+func (s *server) listDecks() ([]apiDeck, error) {
+	entries, err := os.ReadDir(config.GetDeckLibraryPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading deck library: %v", err)
+	}
+	defaultDeck, _ := config.GetDefaultDeck()
+
+	decks := []apiDeck{}
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(config.GetDeckLibraryPath(), entry.Name()))
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		d, err := s.deck(entry.Name())
+		if err != nil {
+			continue
+		}
+		decks = append(decks, apiDeck{
+			ID:          entry.Name(),
+			Name:        d.Name,
+			Description: d.Description,
+			Default:     entry.Name() == defaultDeck,
+			CardCount:   len(d.Cards()),
+		})
+	}
+	return decks, nil
+}
+
+// describeDeck describes a library deck and its cards
+func (s *server) describeDeck(id string) (apiDeck, error) {
+	d, err := s.deck(id)
+	if err != nil {
+		return apiDeck{}, err
+	}
+	defaultDeck, _ := config.GetDefaultDeck()
+
+	cards := d.Cards()
+	info := apiDeck{
+		ID:          id,
+		Name:        d.Name,
+		Description: d.Description,
+		Default:     id == defaultDeck,
+		CardCount:   len(cards),
+		Cards:       make([]apiCard, len(cards)),
+	}
+	for i, c := range cards {
+		info.Cards[i] = newAPICard(id, c)
+	}
+	return info, nil
+}
+
+// listSpreads describes the built-in and custom spreads
+func listSpreads() []apiSpread {
+	spreads := []apiSpread{}
+	for _, name := range spread.Names() {
+		sp, err := spread.Get(name)
+		if err != nil {
+			continue
+		}
+		positions := make([]string, len(sp.Positions))
+		for i, p := range sp.Positions {
+			positions[i] = p.Name
+		}
+		spreads = append(spreads, apiSpread{ID: sp.ID, Name: sp.Name, Positions: positions})
+	}
+	return spreads
+}
+
+// errDrawCount refuses draws of too few or too many cards
+var errDrawCount = &apiError{http.StatusBadRequest,
+	fmt.Sprintf("count must be a number of cards between 1 and %d", maxDrawCount)}
+
+// draw shuffles a library deck and draws count cards from a pool of it
+// (full for an empty name), or deals the cards of a spread when one is
+// given. Draws are not stored, so they are allowed on read-only servers;
+// the same seed gives the same draw.
+func (s *server) draw(id string, count int, poolName, spreadID string, seed int64) (apiDraw, error) {
+	if count < 1 || count > maxDrawCount {
+		return apiDraw{}, errDrawCount
+	}
+	d, err := s.deck(id)
+	if err != nil {
+		return apiDraw{}, err
+	}
+
+	result := apiDraw{Deck: id, Pool: poolName, Spread: spreadID, Seed: seed, Cards: []apiCard{}}
+	if result.Pool == "" {
+		result.Pool = "full"
+	}
+	cards, err := shuffleNamedPool(d, result.Pool, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return apiDraw{}, &apiError{http.StatusBadRequest, err.Error()}
+	}
+
+	if result.Spread != "" {
+		sp, err := spread.Get(result.Spread)
+		if err != nil {
+			return apiDraw{}, &apiError{http.StatusBadRequest, err.Error()}
+		}
+		draws, err := sp.DealFrom(cards)
+		if err != nil {
+			return apiDraw{}, &apiError{http.StatusBadRequest, err.Error()}
+		}
+		for _, draw := range draws {
+			c := newAPICard(id, draw.Card)
+			c.Position = draw.Position.Name
+			result.Cards = append(result.Cards, c)
+		}
+		return result, nil
+	}
+
+	if count > len(cards) {
+		return apiDraw{}, &apiError{http.StatusBadRequest, fmt.Sprintf("cannot draw %d cards from %d", count, len(cards))}
+	}
+	for _, c := range cards[:count] {
+		result.Cards = append(result.Cards, newAPICard(id, c))
+	}
+	return result, nil
+}
+
+// shuffleNamedPool returns the cards of a deck's pool in shuffled order,
+// weighted for pools that weigh their cards
+func shuffleNamedPool(d *deck.Deck, poolName string, rng *rand.Rand) ([]*card.Card, error) {
+	p, err := pool.Lookup(poolName, d.SuitNames())
+	if err != nil {
+		return nil, err
+	}
+	cards, err := p.Select(d.Cards())
+	if err != nil {
+		return nil, err
+	}
+	if p.Weighted() {
+		return p.Shuffle(cards, rng), nil
+	}
+	rng.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+	return cards, nil
+}
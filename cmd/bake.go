@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/atomicfile"
+	"github.com/arcanaland/cartomancer/internal/bake"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// bakeTiers are the directories of prebuilt ANSI art that show looks in
+var bakeTiers = []string{"ansi32", "ansi256"}
+
+// deckBakeCmd represents the deck bake command
+var deckBakeCmd = &cobra.Command{
+	Use:   "bake [deck]",
+	Short: "Prerender a deck's card art as ANSI art shipped with the deck",
+	Long: `Bake renders the art of every card to ANSI art in the deck's ansi32 directory
+(or ansi256 with --tier), where show and the other commands use it instead of
+rendering the images on first use. Art is rendered from the highest resolution
+raster tier that has the card.
+
+Bake profiles in config.toml describe other variants of the art, such as art
+sized for 80x24 terminals or limited to 256 colors, and --profile bakes one
+into its own directory of the deck, ansi-<profile> unless the profile sets dir,
+so a deck can ship several. --width, --height and --tier override the profile.
+
+  [bake_profiles.tty80]
+  width = 28
+  height = 22
+  colors = "256"         # truecolor, 256, 16 or none
+  dither = true          # dither 256 and 16 color art
+  renderer = "halfblock" # or ascii, for fonts without block elements
+
+Baking is incremental: a manifest in the ANSI directory records the checksum of
+each card's source image and the render settings, and cards whose image and
+settings are unchanged are skipped. --force bakes every card again. A summary
+of the baked, skipped and failed cards is printed at the end.
+
+Examples:
+  cartomancer deck bake ./my-deck
+  cartomancer deck bake ./my-deck --width 60 --height 48
+  cartomancer deck bake ./my-deck --force
+  cartomancer deck bake ./my-deck --profile tty80`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		tier, _ := cmd.Flags().GetString("tier")
+		opts := render.DefaultOptions()
+		profile, _ := cmd.Flags().GetString("profile")
+		if profile != "" {
+			profileTier, profileOpts, err := bakeProfile(profile)
+			if err != nil {
+				return err
+			}
+			opts = profileOpts
+			if !cmd.Flags().Changed("tier") {
+				tier = profileTier
+			}
+		}
+		if (profile == "" || cmd.Flags().Changed("tier")) && !contains(bakeTiers, tier) {
+			return fmt.Errorf("unknown tier: %s (supported: %s)", tier, strings.Join(bakeTiers, ", "))
+		}
+		if cmd.Flags().Changed("width") {
+			opts.Width, _ = cmd.Flags().GetInt("width")
+		}
+		if cmd.Flags().Changed("height") {
+			opts.Height, _ = cmd.Flags().GetInt("height")
+		}
+		if opts.Width <= 0 || opts.Height <= 0 {
+			return fmt.Errorf("--width and --height must be positive")
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		b := &baker{
+			deck:     d,
+			dir:      filepath.Join(d.Path, tier),
+			opts:     opts,
+			params:   bakeParams(opts),
+			force:    force,
+			manifest: bake.Load(filepath.Join(d.Path, tier)),
+		}
+		return b.run()
+	},
+}
+
+func init() {
+	deckCmd.AddCommand(deckBakeCmd)
+
+	defaults := render.DefaultOptions()
+	deckBakeCmd.Flags().String("tier", bakeTiers[0], "ANSI directory to bake into: "+strings.Join(bakeTiers, " or "))
+	deckBakeCmd.Flags().Int("width", defaults.Width, "Width of the art in columns")
+	deckBakeCmd.Flags().Int("height", defaults.Height, "Height of the art in rows")
+	deckBakeCmd.Flags().String("profile", "", "Bake profile from config.toml to bake")
+	deckBakeCmd.Flags().Bool("force", false, "Bake every card, even when its image and settings are unchanged")
+}
+
+// bakeProfile returns the directory and render options of a bake profile
+// from config.toml
+func bakeProfile(name string) (string, render.Options, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return "", render.Options{}, fmt.Errorf("error loading config: %v", err)
+	}
+	profile, ok := cfg.BakeProfiles[name]
+	if !ok {
+		if len(cfg.BakeProfiles) == 0 {
+			return "", render.Options{}, fmt.Errorf("no bake profiles configured in %s (see deck bake --help)", config.GetConfigFilePath())
+		}
+		names := make([]string, 0, len(cfg.BakeProfiles))
+		for key := range cfg.BakeProfiles {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		return "", render.Options{}, fmt.Errorf("bake profile not found: %s (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	dir := profile.Dir
+	if dir == "" {
+		dir = "ansi-" + name
+	}
+	if !strings.HasPrefix(dir, "ansi") || filepath.Base(dir) != dir {
+		return "", render.Options{}, fmt.Errorf("bake profile %s: dir must be a directory name starting with \"ansi\": %s", name, dir)
+	}
+
+	opts := render.DefaultOptions()
+	if profile.Width > 0 {
+		opts.Width = profile.Width
+	}
+	if profile.Height > 0 {
+		opts.Height = profile.Height
+	}
+	switch profile.Colors {
+	case "", config.BakeColorsTrue:
+	case config.BakeColors256:
+		opts.Colors = render.Colors256
+	case config.BakeColors16:
+		opts.Colors = render.Colors16
+	case config.BakeColorsNone:
+		opts.TrueColor = false
+	default:
+		return "", render.Options{}, fmt.Errorf("bake profile %s: unsupported colors: %s (supported: %s)", name, profile.Colors, strings.Join(config.BakeColors(), ", "))
+	}
+	opts.Dither = profile.Dither
+	opts.Renderer = profile.Renderer
+	return dir, opts, nil
+}
+
+// bakeParams describes the render settings recorded in the manifest, so
+// changing them bakes the cards again
+func bakeParams(opts render.Options) string {
+	params := fmt.Sprintf("%dx%d source-height=%d truecolor=%v", opts.Width, opts.Height, ansiSourceHeight, opts.TrueColor)
+	if opts.Colors != render.ColorsTrue {
+		params += fmt.Sprintf(" colors=%d", opts.Colors)
+	}
+	if opts.Dither {
+		params += " dither=true"
+	}
+	if opts.Renderer != "" && opts.Renderer != render.RendererHalfBlock {
+		params += " renderer=" + opts.Renderer
+	}
+	return params
+}
+
+// baker bakes the ANSI art of a deck
+type baker struct {
+	deck     *deck.Deck
+	dir      string // ANSI directory baked into
+	opts     render.Options
+	params   string // Render settings recorded in the manifest
+	force    bool
+	manifest *bake.Manifest
+
+	baked, skipped, noArt int
+	failures              []string
+}
+
+// run bakes every card, saves the manifest and prints a summary
+func (b *baker) run() error {
+	for _, c := range b.deck.Cards() {
+		b.bakeCard(c)
+	}
+	if err := b.manifest.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Baked %d cards, skipped %d unchanged, %d failed", b.baked, b.skipped, len(b.failures))
+	if b.noArt > 0 {
+		fmt.Printf(" (%d cards have no raster art)", b.noArt)
+	}
+	fmt.Println()
+	for _, failure := range b.failures {
+		fmt.Fprintf(os.Stderr, "  %s\n", failure)
+	}
+	if len(b.failures) > 0 {
+		return fmt.Errorf("%d cards failed to bake", len(b.failures))
+	}
+	return nil
+}
+
+// bakeCard renders one card's art unless it is unchanged since the last bake
+func (b *baker) bakeCard(c *card.Card) {
+	parts := strings.Split(c.ID, ".")
+	source, err := b.source(parts)
+	if err != nil {
+		b.noArt++
+		return
+	}
+	dest, err := buildCardPath(b.dir, parts, ".ansi")
+	if err != nil {
+		b.fail(c, err)
+		return
+	}
+
+	sum, err := bake.HashFile(source)
+	if err != nil {
+		b.fail(c, err)
+		return
+	}
+	rel, _ := filepath.Rel(b.deck.Path, source)
+	entry := bake.Entry{Source: filepath.ToSlash(rel), SHA256: sum, Params: b.params}
+	if _, err := os.Stat(dest); err == nil && !b.force && b.manifest.Fresh(c.ID, entry) {
+		b.skipped++
+		return
+	}
+
+	img, err := decodeScaledImageFile(source, ansiSourceHeight)
+	if err != nil {
+		b.fail(c, err)
+		return
+	}
+	art, err := render.RenderANSI(img, b.opts)
+	if err != nil {
+		b.fail(c, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		b.fail(c, err)
+		return
+	}
+	if err := atomicfile.WriteFile(dest, []byte(art), 0644); err != nil {
+		b.fail(c, err)
+		return
+	}
+	b.manifest.Record(c.ID, entry)
+	b.baked++
+}
+
+// source returns the card's image in the highest resolution raster tier
+func (b *baker) source(parts []string) (string, error) {
+	tiers, err := rasterTiers(b.deck.Path)
+	if err != nil {
+		return "", err
+	}
+	for _, tier := range tiers {
+		for _, ext := range rasterExtensions {
+			path, err := buildCardPath(filepath.Join(b.deck.Path, tier), parts, ext)
+			if err != nil {
+				return "", err
+			}
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no raster image found")
+}
+
+// fail records a card that could not be baked, so it is retried next time
+func (b *baker) fail(c *card.Card, err error) {
+	b.manifest.Forget(c.ID)
+	b.failures = append(b.failures, fmt.Sprintf("%s: %v", c.ID, err))
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/composite"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/spread"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"golang.org/x/term"
+)
+
+// boardCardWidth is the width in terminal columns of each card on a board
+const boardCardWidth = 14
+
+// board lays out the cards of a reading in the terminal, face up or face down
+type board struct {
+	draws []spread.Draw
+	faces [][]string // Rendered art for each draw, nil when the card has no raster image
+	back  []string   // Rendered card back, scaled to the same cell size as the faces
+	theme *theme.Theme
+
+	// Source images and cell size, kept to render flip animations
+	faceImages []image.Image
+	backImage  image.Image
+	opts       render.Options
+}
+
+// newBoard renders the faces of the drawn cards and the deck's card back at a
+// common cell size. Decks without a card back get a generated placeholder, as
+// do cards without a face in best-effort mode.
+func newBoard(d *deck.Deck, draws []spread.Draw, t *theme.Theme, ph *placeholders) (*board, error) {
+	b := &board{draws: draws, theme: t, faces: make([][]string, len(draws))}
+
+	images := make([]image.Image, len(draws))
+	var sizeRef image.Image
+	for i, draw := range draws {
+		if img, err := loadViewImage(d.AssetRoots(), draw.Card, 0); err == nil {
+			images[i] = img
+			if sizeRef == nil {
+				sizeRef = img
+			}
+		}
+	}
+
+	var back image.Image
+	if backPath, err := d.CardBackPath(); err == nil {
+		back, _ = decodeImageFile(backPath)
+	}
+	if sizeRef == nil {
+		sizeRef = back
+	}
+
+	// Size the cells from the card aspect ratio; each row holds two pixels
+	opts := render.Options{Width: boardCardWidth, TrueColor: true}
+	if sizeRef != nil {
+		bounds := sizeRef.Bounds()
+		opts.Height = max(boardCardWidth*bounds.Dy()/bounds.Dx()/2, 1)
+	} else {
+		opts.Height = boardCardWidth * 17 / 20
+	}
+
+	if back == nil {
+		layout := composite.DefaultLayout(opts.Width*2, opts.Height*2)
+		if terminalBackground() == theme.BackgroundLight {
+			layout = layout.Light()
+		}
+		back = composite.PlaceholderBack(opts.Width*2, opts.Height*2, layout.BlankCard, layout.Foreground)
+	}
+
+	opts = viewOptions(opts)
+	b.backImage, b.opts = back, opts
+	b.faceImages = make([]image.Image, len(draws))
+
+	var err error
+	if b.back, err = render.Lines(back, opts); err != nil {
+		return nil, fmt.Errorf("error rendering card back: %v", err)
+	}
+
+	for i, img := range images {
+		if img == nil {
+			placeholder, ok := ph.image(draws[i].Card, opts.Width*2, opts.Height*2)
+			if !ok {
+				continue
+			}
+			img = placeholder
+		}
+		b.faceImages[i] = img
+		if b.faces[i], err = render.Lines(img, opts); err != nil {
+			return nil, fmt.Errorf("error rendering %s: %v", draws[i].Card.ID, err)
+		}
+	}
+
+	return b, nil
+}
+
+// render returns the board lines with the first revealed cards face up. Cards
+// are grouped into rows by their position in the spread and captioned with the
+// card name when face up or the position name when face down.
+func (b *board) render(revealed int) []string {
+	return b.renderFlipping(revealed, nil)
+}
+
+// renderFlipping renders the board like render, with the card after the
+// revealed ones drawn as a frame of its flip animation when flip is not nil
+func (b *board) renderFlipping(revealed int, flip []string) []string {
+	order := make([]int, len(b.draws))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		pi, pj := b.draws[order[i]].Position, b.draws[order[j]].Position
+		if pi.Y != pj.Y {
+			return pi.Y < pj.Y
+		}
+		return pi.X < pj.X
+	})
+
+	var lines []string
+	var row [][]string
+	rowY := 0.0
+	flush := func() {
+		if len(row) > 0 {
+			lines = append(lines, joinColumns(row, "  ")...)
+			lines = append(lines, "")
+		}
+		row = nil
+	}
+
+	for _, i := range order {
+		draw := b.draws[i]
+		if len(row) > 0 && draw.Position.Y != rowY {
+			flush()
+		}
+		rowY = draw.Position.Y
+
+		art, caption := b.back, draw.Position.Name
+		if i == revealed && flip != nil {
+			art = flip
+		}
+		if i < revealed {
+			caption = draw.Card.Name
+			art = b.faces[i]
+			if art == nil {
+				art = blankArt(len(b.back), visibleWidth(b.back[0]))
+			}
+		}
+		row = append(row, frameArt(art, caption, frameAuto, b.theme))
+	}
+	flush()
+
+	return lines
+}
+
+// blankArt returns empty art of the given size for cards without an image
+func blankArt(height, width int) []string {
+	lines := make([]string, height)
+	for i := range lines {
+		lines[i] = strings.Repeat(" ", width)
+	}
+	return lines
+}
+
+// joinColumns places blocks of lines side by side, padding shorter blocks
+func joinColumns(blocks [][]string, gap string) []string {
+	height := 0
+	for _, block := range blocks {
+		height = max(height, len(block))
+	}
+
+	lines := make([]string, height)
+	for i := range lines {
+		var line strings.Builder
+		for j, block := range blocks {
+			if j > 0 {
+				line.WriteString(gap)
+			}
+			width := 0
+			for _, l := range block {
+				width = max(width, visibleWidth(l))
+			}
+			if i < len(block) {
+				line.WriteString(block[i])
+				line.WriteString(strings.Repeat(" ", width-visibleWidth(block[i])))
+			} else {
+				line.WriteString(strings.Repeat(" ", width))
+			}
+		}
+		lines[i] = line.String()
+	}
+	return lines
+}
+
+// printBoard writes the board lines indented to stdout
+func printBoard(lines []string) {
+	fmt.Println()
+	for _, line := range lines {
+		fmt.Println("  " + line)
+	}
+}
+
+// flipFrames returns the frames of a drawn card turning face up, or nil when
+// the card has no image to turn over to
+func (b *board) flipFrames(i int) [][]string {
+	if b.faceImages[i] == nil {
+		return nil
+	}
+	frames, err := render.FlipFrames(b.backImage, b.faceImages[i], b.opts, flipSteps)
+	if err != nil {
+		return nil
+	}
+	return frames
+}
+
+// stepThrough reveals the cards of a board one at a time, waiting for Enter
+// between cards. When stdout is a terminal the screen is cleared between steps
+// and each card flips over as it is revealed. The keys pressed and each card
+// revealed are recorded in rec, which may be nil.
+func stepThrough(b *board, in io.Reader, rec *recording) {
+	reader := bufio.NewReader(rec.input(in))
+	clear := term.IsTerminal(int(terminalOut.Fd()))
+
+	for revealed := 0; revealed <= len(b.draws); revealed++ {
+		if revealed > 0 {
+			draw := b.draws[revealed-1]
+			rec.mark(draw.Position.Name + ": " + draw.Card.Name)
+		}
+		if clear && revealed > 0 {
+			// Frames are drawn over the previous board, which has the same size
+			for _, frame := range b.flipFrames(revealed - 1) {
+				fmt.Print("\033[H")
+				printBoard(b.renderFlipping(revealed-1, frame))
+				time.Sleep(flipFrameDelay)
+			}
+		}
+		if clear {
+			fmt.Print("\033[H\033[2J")
+		}
+		printBoard(b.render(revealed))
+
+		if revealed == len(b.draws) {
+			return
+		}
+
+		next := b.draws[revealed].Position.Name
+		fmt.Print(b.theme.Muted.Sprintf("Press Enter to reveal %s (%d/%d) ", next, revealed+1, len(b.draws)))
+		if _, err := reader.ReadString('\n'); err != nil {
+			// Input closed: reveal everything that is left
+			fmt.Println()
+			revealed = len(b.draws) - 1
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cacheEntry is a cached response body: an encoded image of serve, with its
+// entity tag, or a rendered output of render
+type cacheEntry struct {
+	key  string
+	data []byte
+	etag string // Set for images
+}
+
+// byteCache holds entries up to a total size of their data, evicting the
+// least recently used first
+type byteCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	order   *list.List // Most recently used at the front
+	entries map[string]*list.Element
+}
+
+// newByteCache returns an empty cache holding up to maxSize bytes
+func newByteCache(maxSize int64) *byteCache {
+	return &byteCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// get returns a cached entry, marking it as recently used
+func (c *byteCache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry), true
+}
+
+// bytes returns the total size of the cached entries
+func (c *byteCache) bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// clear removes all cached entries
+func (c *byteCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = map[string]*list.Element{}
+	c.size = 0
+}
+
+// add caches an entry, evicting older entries to stay within the size limit.
+// Entries larger than the whole cache are not cached.
+func (c *byteCache) add(key string, entry *cacheEntry) {
+	size := int64(len(entry.data))
+	if size > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	entry.key = key
+	c.entries[key] = c.order.PushFront(entry)
+	c.size += size
+
+	for c.size > c.maxSize {
+		oldest := c.order.Back()
+		evicted := c.order.Remove(oldest).(*cacheEntry)
+		delete(c.entries, evicted.key)
+		c.size -= int64(len(evicted.data))
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/combo"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/spf13/cobra"
+)
+
+var comboCmd = &cobra.Command{
+	Use:   "combo <card1> [card2]",
+	Short: "Show the meaning of two cards read together",
+	Long: `Combo shows the meaning of a pair of cards read together, as in Lenormand
+readings and traditional tarot pairings. With a single card, every known pair
+that includes it is listed.
+
+A few well-known tarot pairings are built in. Decks can ship their own in a
+combinations.toml file next to deck.toml, and your own go in .toml files in
+XDG_DATA_HOME/cartomancer/combinations; yours take precedence over the deck's,
+which take precedence over the built-in ones:
+
+  [[pair]]
+  cards = ["The Lovers", "cups.2"]   # names or card IDs
+  meaning = "A committed partnership"
+
+Spreads note the meaning of any known pair dealt into touching positions.
+
+Examples:
+  cartomancer combo "The Lovers" "Two of Cups"
+  cartomancer combo XVI XIII
+  cartomancer combo --deck my-lenormand custom_cards.major_arcana.rider`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ids []string
+		for _, arg := range args {
+			id, _, err := card.ParseName(arg)
+			if err != nil {
+				return card.WithSuggestion(arg, err)
+			}
+			ids = append(ids, id)
+		}
+
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		combos, err := loadCombos(d)
+		if err != nil {
+			return err
+		}
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+
+		name := func(id string) string {
+			if c, err := d.GetCard(id); err == nil {
+				return c.Name
+			}
+			return card.DefaultName(id)
+		}
+
+		fmt.Println()
+		if len(ids) == 2 {
+			fmt.Println(t.Heading.Sprintf("%s + %s", name(ids[0]), name(ids[1])))
+			if p, ok := combos.Lookup(ids[0], ids[1]); ok {
+				fmt.Println("  " + t.Value.Sprint(p.Meaning))
+			} else {
+				fmt.Println("  " + t.Muted.Sprint("No combination meaning is recorded for this pair"))
+			}
+			fmt.Println()
+			return nil
+		}
+
+		fmt.Println(t.Heading.Sprint(name(ids[0])))
+		pairs := combos.With(ids[0])
+		if len(pairs) == 0 {
+			fmt.Println("  " + t.Muted.Sprint("No combination meanings are recorded for this card"))
+		}
+		for _, p := range pairs {
+			other := p.Cards[0]
+			if other == ids[0] {
+				other = p.Cards[1]
+			}
+			fmt.Println("  " + t.Label.Sprintf("+ %s:", name(other)) + " " + t.Value.Sprint(p.Meaning))
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(comboCmd)
+
+	comboCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+}
+
+// loadCombos returns the built-in pair meanings overlaid with the deck's
+// combinations.toml and then the user's combinations directory
+func loadCombos(d *deck.Deck) (*combo.Set, error) {
+	combos := combo.Builtin()
+	if err := combos.LoadFile(filepath.Join(d.Path, "combinations.toml")); err != nil {
+		return nil, err
+	}
+	if err := combos.LoadDir(config.GetCombinationsDir()); err != nil {
+		return nil, err
+	}
+	return combos, nil
+}
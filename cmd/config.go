@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command group
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the cartomancer configuration",
+}
+
+// configCheckCmd represents the config check command
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check config.toml for mistakes",
+	Long: `Check reports problems in config.toml with their line numbers: syntax errors,
+unknown keys, values of the wrong type, unsupported themes, symbol sets, numbering
+styles, info panel fields and hook events, and a default deck that cannot be found.
+Close matches are suggested for misspelled names.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := config.Check()
+		if err != nil {
+			return err
+		}
+
+		for _, field := range result.Config.ShowFields {
+			if _, ok := fieldLabels[field]; !ok {
+				result.Add("show_fields", fmt.Sprintf("unknown field %q in show_fields (available: %s)",
+					field, strings.Join(fieldNames(), ", ")), "")
+			}
+		}
+
+		if len(result.Diagnostics) == 0 {
+			fmt.Printf("✅ %s is valid\n", result.Path)
+			return nil
+		}
+
+		sort.SliceStable(result.Diagnostics, func(i, j int) bool {
+			return result.Diagnostics[i].Line < result.Diagnostics[j].Line
+		})
+
+		fmt.Printf("❌ %s has %d problems:\n", result.Path, len(result.Diagnostics))
+		for _, d := range result.Diagnostics {
+			fmt.Printf("  %s\n", d)
+		}
+
+		cmd.SilenceUsage = true
+		return fmt.Errorf("invalid configuration")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configCheckCmd)
+}
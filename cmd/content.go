@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"sync"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"github.com/nfnt/resize"
+)
+
+// blurFactor is how far art is scaled down before being scaled back up to blur it
+const blurFactor = 24
+
+// withheldColor and withheldLightColor fill the blank card shown in place of
+// art withheld in safe mode, on dark and light terminal backgrounds
+var (
+	withheldColor      = color.RGBA{0x2a, 0x2a, 0x2e, 0xff}
+	withheldLightColor = color.RGBA{0xdc, 0xdc, 0xe0, 0xff}
+)
+
+// contentFilter hides the art of cards carrying content warnings chosen in
+// the [content_filter] table of config.toml. In safe mode, set by --safe-mode
+// or safe_mode in config.toml, the art of every card with a warning is
+// withheld altogether.
+type contentFilter struct {
+	hide map[string]bool
+	all  bool
+	mode string
+	safe bool
+
+	mu    sync.Mutex
+	backs map[string]image.Image // Card backs by deck path, for the back mode
+}
+
+var (
+	filterOnce sync.Once
+	filter     *contentFilter
+)
+
+// activeContentFilter returns the configured content filter, or nil when no
+// warnings are hidden
+func activeContentFilter() *contentFilter {
+	filterOnce.Do(func() {
+		safe, _ := RootCmd.PersistentFlags().GetBool("safe-mode")
+		cfg, err := config.LoadConfig()
+		if err == nil && cfg.SafeMode {
+			safe = true
+		}
+		if !safe && (err != nil || cfg.ContentFilter == nil || len(cfg.ContentFilter.Hide) == 0) {
+			return
+		}
+
+		f := &contentFilter{hide: map[string]bool{}, safe: safe, backs: map[string]image.Image{}}
+		if err != nil || cfg.ContentFilter == nil {
+			filter = f
+			return
+		}
+		f.mode = cfg.ContentFilter.Mode
+		for _, w := range cfg.ContentFilter.Hide {
+			if w == "*" {
+				f.all = true
+			}
+			f.hide[strings.ToLower(strings.TrimSpace(w))] = true
+		}
+		if f.mode == "" {
+			f.mode = config.HideBlur
+		}
+		filter = f
+	})
+	return filter
+}
+
+// hidesArt reports whether the content filter hides a card's art
+func hidesArt(c *card.Card) bool {
+	f := activeContentFilter()
+	if f == nil {
+		return false
+	}
+	if f.safe && len(c.ContentWarnings) > 0 {
+		return true
+	}
+	for _, w := range c.ContentWarnings {
+		if f.all || f.hide[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// withholdsArt reports whether safe mode withholds a card's art, leaving only
+// its details to be shown
+func withholdsArt(c *card.Card) bool {
+	f := activeContentFilter()
+	return f != nil && f.safe && len(c.ContentWarnings) > 0
+}
+
+// coverArt returns what is shown in place of a hidden card's art: the art
+// blurred, the deck's card back or placeholder art, or a blank card in safe
+// mode. The art may be nil when the card has none to blur.
+func coverArt(roots []string, c *card.Card, art image.Image) image.Image {
+	f := activeContentFilter()
+	light := terminalBackground() == theme.BackgroundLight
+	switch {
+	case withholdsArt(c):
+		fill := withheldColor
+		if light {
+			fill = withheldLightColor
+		}
+		blank := image.NewRGBA(image.Rect(0, 0, placeholderWidth, placeholderHeight))
+		draw.Draw(blank, blank.Bounds(), image.NewUniform(fill), image.Point{}, draw.Src)
+		return blank
+	case f != nil && f.mode == config.HideBack:
+		if back := f.back(roots[len(roots)-1]); back != nil {
+			return back
+		}
+	case f != nil && f.mode == config.HideBlur && art != nil:
+		b := art.Bounds()
+		small := resize.Resize(uint(max(b.Dx()/blurFactor, 1)), 0, art, resize.Bilinear)
+		return resize.Resize(uint(b.Dx()), uint(b.Dy()), small, resize.Bilinear)
+	}
+
+	accent, ok := suitColors[c.Suit]
+	if !ok {
+		accent = majorArcanaColor
+	}
+	return placeholderArt(c, accent, light).Draw(placeholderWidth, placeholderHeight)
+}
+
+// back returns the card back of the deck at a path, loading it once, or nil
+// when the deck cannot be loaded
+func (f *contentFilter) back(deckPath string) image.Image {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	back, ok := f.backs[deckPath]
+	if !ok {
+		if d, err := deck.LoadDeck(deckPath); err == nil {
+			back = deckBackImage(d)
+		}
+		f.backs[deckPath] = back
+	}
+	return back
+}
+
+var (
+	transformOnce sync.Once
+	artTransform  func(color.RGBA) color.RGBA
+)
+
+// colorTransform returns the color transform of the color vision profile
+// set by --cb-profile or cb_profile in config.toml, or nil when none is set.
+// An invalid profile in config.toml is left for config check to report.
+func colorTransform() func(color.RGBA) color.RGBA {
+	transformOnce.Do(func() {
+		profile, _ := RootCmd.PersistentFlags().GetString("cb-profile")
+		if profile == "" {
+			if cfg, err := config.LoadConfig(); err == nil {
+				profile = cfg.CBProfile
+			}
+		}
+		if profile != "" {
+			artTransform, _ = render.CVDTransform(profile)
+		}
+	})
+	return artTransform
+}
+
+// viewOptions returns render options for art shown to the user, with the
+// colors of their color vision profile
+func viewOptions(opts render.Options) render.Options {
+	opts.Transform = colorTransform()
+	return opts
+}
+
+// loadViewImage loads a card image for display like loadScaledImage, covering
+// the art of cards hidden by the content filter. Exports of the deck itself,
+// such as registry previews and print sheets, load images directly instead.
+func loadViewImage(roots []string, c *card.Card, maxHeight int) (image.Image, error) {
+	img, err := loadScaledImage(roots, c, maxHeight)
+	if err != nil || !hidesArt(c) {
+		return img, err
+	}
+	return coverArt(roots, c, img), nil
+}
+
+// hiddenAnsiArt renders the cover art of a hidden card as ANSI art
+func hiddenAnsiArt(roots []string, c *card.Card) (string, error) {
+	img, err := loadViewImage(roots, c, 0)
+	if err != nil {
+		// Cards with only prebuilt ANSI art have nothing to blur
+		img = coverArt(roots, c, nil)
+	}
+	return render.RenderANSI(img, render.DefaultOptions())
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/clipboard"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+// What --copy places on the clipboard
+const (
+	copyText  = "text"  // The plain-text summary
+	copyImage = "image" // The card image
+)
+
+// copyImageHeight is the height card images are scaled down to for the
+// clipboard, plenty for chats and journals
+const copyImageHeight = 1200
+
+// addCopyFlag registers the --copy flag. Passing --copy without a value copies
+// the plain-text summary; --copy=image copies the card image.
+func addCopyFlag(cmd *cobra.Command) {
+	cmd.Flags().String("copy", "", "Copy the result to the clipboard: text, or image for the image of a single card")
+	cmd.Flags().Lookup("copy").NoOptDefVal = copyText
+}
+
+// copyMode returns the validated --copy value, empty when nothing is to be copied
+func copyMode(cmd *cobra.Command) (string, error) {
+	mode, _ := cmd.Flags().GetString("copy")
+	if mode != "" && mode != copyText && mode != copyImage {
+		return "", fmt.Errorf("invalid --copy: %s (supported: %s, %s)", mode, copyText, copyImage)
+	}
+	return mode, nil
+}
+
+// copyResult places the plain-text summary, or the image of the single card
+// shown, on the clipboard and says so on stderr
+func copyResult(mode, summary string, roots []string, cards []*card.Card) error {
+	var method string
+	var err error
+	switch mode {
+	case copyText:
+		method, err = clipboard.CopyText(summary)
+	case copyImage:
+		if len(cards) != 1 {
+			return fmt.Errorf("--copy=image copies the image of a single card; use --copy for the text")
+		}
+		var data []byte
+		if data, err = cardPNG(roots, cards[0]); err != nil {
+			return err
+		}
+		method, err = clipboard.CopyImage(data)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "📋 Copied the %s to the clipboard via %s\n", mode, method)
+	return nil
+}
+
+// cardPNG encodes a card image for the clipboard
+func cardPNG(roots []string, c *card.Card) ([]byte, error) {
+	img, err := loadViewImage(roots, c, copyImageHeight)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error encoding image: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// plainSummary lists the info panel fields of cards as plain text, one
+// "Label: value" line per field, with a blank line between cards. Symbols
+// are Unicode, as Nerd Font glyphs do not survive being pasted elsewhere.
+func plainSummary(cards []*card.Card, opts displayOptions) (string, error) {
+	plain := *opts.Theme
+	plain.Symbols, _ = theme.LookupSymbols(theme.SymbolsUnicode)
+	opts.Theme = &plain
+
+	var b strings.Builder
+	for i, c := range cards {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, field := range opts.Fields {
+			value, err := fieldValue(field, c, opts)
+			if err != nil {
+				return "", err
+			}
+			if value != "" {
+				fmt.Fprintf(&b, "%s: %s\n", fieldLabels[field], value)
+			}
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/daily"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+	"github.com/arcanaland/cartomancer/internal/notes"
+	"github.com/arcanaland/cartomancer/internal/prompts"
+	"github.com/arcanaland/cartomancer/internal/report"
+	"github.com/arcanaland/cartomancer/internal/spread"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+var dailyCmd = &cobra.Command{
+	Use:   "daily",
+	Short: "Show the card of the day",
+	Long: `Daily shows the card of the day. The card is chosen from the date and the deck,
+so it stays the same for the whole day, and is cached so repeated calls are cheap.
+
+Use --format prompt to print a short colored segment (symbol and card name)
+suitable for tmux status lines and shell prompts. Use --format waybar to print
+the JSON object expected by a waybar custom module (text, tooltip with the
+card's description, and a class of major or the suit name for styling), or
+--format polybar for a plain line for a polybar custom/script module.
+
+Use --prompt, or set daily = true in the [prompts] table of config.toml, to
+follow the card with a journaling prompt for the occasion: one of the eight
+sabbats of the Wheel of the Year, the new or full moon, or otherwise the
+season. Set hemisphere = "south" in [prompts] to turn the wheel and seasons
+for the southern hemisphere. Replace the prompts of any occasion in
+XDG_DATA_HOME/cartomancer/prompts.toml, where {card} stands for the card's name:
+
+  [full-moon]
+  name = "Full Moon"
+  prompts = ["What has {card} brought to light this cycle?"]
+
+Occasions: samhain, yule, imbolc, ostara, beltane, litha, lughnasadh, mabon,
+new-moon, full-moon, spring, summer, autumn and winter.
+
+Examples:
+  cartomancer daily
+  cartomancer daily --format prompt
+  cartomancer daily --prompt
+  set -g status-right '#(cartomancer daily --format prompt)'
+
+  # waybar config.jsonc
+  "custom/tarot": {"exec": "cartomancer daily --format waybar", "return-type": "json", "interval": 3600}
+
+  # polybar config.ini
+  [module/tarot]
+  type = custom/script
+  exec = cartomancer daily --format polybar
+  interval = 3600`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "card" && !isStatusFormat(format) {
+			return fmt.Errorf("unknown format: %s (supported: card, prompt, waybar, polybar)", format)
+		}
+
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		today := daily.DateString(time.Now())
+		cacheDir := config.GetCacheDir()
+
+		// Prompts and status bars are rendered constantly, so answer from the cache when possible
+		if isStatusFormat(format) {
+			if entry, ok := daily.Load(cacheDir, today, deckPath); ok {
+				return printStatusSegment(cmd, format, entry)
+			}
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		var entry *daily.Entry
+		var drawnPayload *drawPayload
+		if cached, ok := daily.Load(cacheDir, today, deckPath); ok {
+			entry = cached
+		} else {
+			c, err := daily.Pick(today, d.ID, d.Cards())
+			if err != nil {
+				return err
+			}
+
+			entry = &daily.Entry{
+				Date:     today,
+				DeckPath: deckPath,
+				CardID:   c.ID,
+				Name:     c.Name,
+				Type:     c.Type,
+				Suit:     c.Suit,
+				AltText:  c.AltText,
+			}
+			if err := daily.Save(cacheDir, entry); err != nil {
+				return err
+			}
+
+			// Hooks only see the first draw of the day, not every cached lookup
+			payload := newDrawPayload("daily", deckPath, 0, []spread.Draw{
+				{Position: spread.Position{Name: "Card of the Day"}, Card: c},
+			})
+			drawnPayload = &payload
+		}
+
+		if err := showDaily(cmd, format, d, entry); err != nil {
+			return err
+		}
+		if drawnPayload == nil {
+			return nil
+		}
+		return runHooks(hooks.PostDraw, *drawnPayload)
+	},
+}
+
+// showDaily shows the card of the day in the format given by --format
+func showDaily(cmd *cobra.Command, format string, d *deck.Deck, entry *daily.Entry) error {
+	if isStatusFormat(format) {
+		return printStatusSegment(cmd, format, entry)
+	}
+
+	c, err := d.GetCard(entry.CardID)
+	if err != nil {
+		return fmt.Errorf("error getting card: %v", err)
+	}
+
+	if templatePath, _ := cmd.Flags().GetString("template"); templatePath != "" {
+		return renderTemplate(templatePath, &report.Reading{
+			Spread:   "Card of the Day",
+			SpreadID: "daily",
+			Deck:     d.Name,
+			Date:     time.Now(),
+			Cards:    []report.Card{{Position: "Card of the Day", Order: 1, Card: c}},
+		})
+	}
+
+	opts, err := loadDisplayOptions(cmd, d.Name)
+	if err != nil {
+		return err
+	}
+	opts.DeckLicense = d.License
+	if opts.Readings, err = d.Readings(); err != nil {
+		return err
+	}
+	if opts.Notes, err = notes.NewStore(config.GetNotesDir()).ForDeck(d.ID); err != nil {
+		return err
+	}
+
+	variant, _ := cmd.Flags().GetString("variant")
+	if err := d.UseVariant(variant); err != nil {
+		return err
+	}
+
+	opts.Theme = styledTheme(opts.Theme, d.StyleFor(c))
+	if err := showCardArt(d.AssetRoots(), c, opts); err != nil {
+		return err
+	}
+	return printDailyPrompt(cmd, c.Name, opts.Theme)
+}
+
+// printDailyPrompt prints a journaling prompt for the day's sabbat, moon or
+// season when enabled by --prompt or the prompts.daily setting
+func printDailyPrompt(cmd *cobra.Command, cardName string, t *theme.Theme) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+	settings := config.Prompts{}
+	if cfg.Prompts != nil {
+		settings = *cfg.Prompts
+	}
+
+	enabled := settings.Daily
+	if cmd.Flags().Changed("prompt") {
+		enabled, _ = cmd.Flags().GetBool("prompt")
+	}
+	if !enabled {
+		return nil
+	}
+
+	set, err := prompts.Load(config.GetPromptsPath())
+	if err != nil {
+		return err
+	}
+	occasion, prompt, ok := set.For(time.Now(), settings.Hemisphere, cardName)
+	if !ok {
+		return nil
+	}
+
+	fmt.Println("  " + t.Heading.Sprint(occasion.Name))
+	for _, line := range wrapText(prompt, 72) {
+		fmt.Println("  " + t.Value.Sprint(line))
+	}
+	fmt.Println()
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(dailyCmd)
+
+	dailyCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	dailyCmd.Flags().String("format", "card", "Output format: card, prompt, waybar or polybar")
+	dailyCmd.Flags().Bool("prompt", false, "Add a journaling prompt for the day's sabbat, moon phase or season (default from config)")
+	dailyCmd.Flags().String("template", "", "Render the card with a Go text/template file (see spread --help)")
+	addFrameFlag(dailyCmd)
+	dailyCmd.Flags().String("variant", "", "Use a deck variant whose overlay images replace the base art")
+}
+
+// isStatusFormat reports whether a format prints a one-line status segment
+func isStatusFormat(format string) bool {
+	return format == "prompt" || format == "waybar" || format == "polybar"
+}
+
+// waybarOutput is the JSON object read by waybar custom modules
+type waybarOutput struct {
+	Text    string `json:"text"`
+	Tooltip string `json:"tooltip"`
+	Class   string `json:"class"`
+}
+
+// printStatusSegment prints the card of the day as a single short segment in
+// the given status format
+func printStatusSegment(cmd *cobra.Command, format string, entry *daily.Entry) error {
+	t, err := loadTheme(cmd)
+	if err != nil {
+		return err
+	}
+
+	symbol := t.Symbols.Arcana(false)
+	class := "major"
+	if entry.Type == "minor_arcana" {
+		symbol = t.Symbols.Suit(entry.Suit)
+		class = entry.Suit
+	}
+
+	switch format {
+	case "waybar":
+		// Waybar renders text and tooltips as Pango markup
+		tooltip := html.EscapeString(entry.Name)
+		if entry.AltText != "" {
+			tooltip += "\n" + html.EscapeString(entry.AltText)
+		}
+		out, err := json.Marshal(waybarOutput{
+			Text:    symbol + " " + html.EscapeString(entry.Name),
+			Tooltip: tooltip,
+			Class:   class,
+		})
+		if err != nil {
+			return fmt.Errorf("error encoding waybar output: %v", err)
+		}
+		fmt.Println(string(out))
+	case "polybar":
+		// Polybar shows escape sequences literally and interprets %{...} tags
+		fmt.Println(symbol + " " + strings.ReplaceAll(entry.Name, "%{", "%%{"))
+	default:
+		fmt.Println(t.Label.Sprint(symbol) + " " + t.Value.Sprint(entry.Name))
+	}
+
+	return nil
+}
@@ -1,12 +1,34 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/arcanaland/cartomancer/internal/alttext"
+	"github.com/arcanaland/cartomancer/internal/archive"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/composite"
 	"github.com/arcanaland/cartomancer/internal/config"
 	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+	"github.com/arcanaland/cartomancer/internal/imagegen"
+	"github.com/arcanaland/cartomancer/internal/license"
+	"github.com/arcanaland/cartomancer/internal/registry"
+	"github.com/arcanaland/cartomancer/internal/script"
+	"github.com/arcanaland/cartomancer/internal/social"
+	"github.com/arcanaland/cartomancer/internal/validator"
+	"github.com/nfnt/resize"
 	"github.com/spf13/cobra"
 )
 
@@ -145,9 +167,1787 @@ var deckInitCmd = &cobra.Command{
 	},
 }
 
+// deckWhichCmd represents the deck which command
+var deckWhichCmd = &cobra.Command{
+	Use:   "which [name_or_id]",
+	Short: "Show how a deck reference resolves to a path",
+	Long: `Which prints how a deck reference is resolved: as a directory in your deck library,
+as a relative path, or by matching the deck ID declared in deck.toml. When several
+decks match, the selected one is marked and the skipped candidates are listed with
+the reason they were passed over.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		res, err := config.ResolveDeck(args[0])
+
+		for _, candidate := range res.Candidates {
+			detail := candidate.Path
+			if candidate.ID != "" {
+				detail = fmt.Sprintf("%s (id %s, version %s)", candidate.Path, candidate.ID, candidate.Version)
+			}
+
+			if candidate.Skipped == "" {
+				fmt.Printf("* %-8s %s [SELECTED]\n", candidate.Source, detail)
+			} else {
+				fmt.Printf("  %-8s %s [skipped: %s]\n", candidate.Source, detail, candidate.Skipped)
+			}
+		}
+
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("\n%s resolves to: %s\n", args[0], res.Path)
+	},
+}
+
+// deckInfoCmd represents the deck info command
+var deckInfoCmd = &cobra.Command{
+	Use:   "info [deck]",
+	Short: "Show a deck's details and content warnings",
+	Long: `Info prints the details declared in a deck's deck.toml: its name, version,
+author, license, publisher, website and tags, the number of cards, and the
+content warnings given for the whole deck or for individual cards in the
+[content_warnings] table.
+
+Cards whose warnings you would rather not see can have their art blurred or
+replaced in config.toml:
+
+  [content_filter]
+  hide = ["nudity", "death imagery"]   # "*" hides every flagged card
+  mode = "blur"                        # blur, back or placeholder
+
+With --safe-mode or safe_mode = true in config.toml, the art of every card
+with a content warning is withheld and only its details are shown.
+
+Examples:
+  cartomancer deck info
+  cartomancer deck info thoth`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(t.Heading.Sprint(d.Name) + t.Muted.Sprintf(" (%s %s)", d.ID, d.Version))
+		fields := [][2]string{
+			{"Author", d.Author},
+			{"License", d.License},
+			{"Publisher", d.Publisher},
+			{"Website", d.Website},
+			{"Tags", strings.Join(d.Tags, ", ")},
+			{"Cards", strconv.Itoa(len(d.Cards()))},
+		}
+		for _, field := range fields {
+			if field[1] != "" {
+				fmt.Println(t.Label.Sprintf("%-11s", field[0]+":") + t.Value.Sprint(field[1]))
+			}
+		}
+
+		// Warnings given for the whole deck are listed once rather than per card
+		flagged := make(map[string][]string)
+		for _, c := range d.Cards() {
+			for _, w := range c.ContentWarnings {
+				if !contains(d.ContentWarnings, w) {
+					flagged[w] = append(flagged[w], c.Name)
+				}
+			}
+		}
+		if len(d.ContentWarnings) == 0 && len(flagged) == 0 {
+			return nil
+		}
+
+		fmt.Println()
+		fmt.Println(t.Heading.Sprint("Content warnings"))
+		if len(d.ContentWarnings) > 0 {
+			fmt.Println("  " + t.Label.Sprint("Whole deck: ") + t.Value.Sprint(strings.Join(d.ContentWarnings, ", ")))
+		}
+		warnings := make([]string, 0, len(flagged))
+		for w := range flagged {
+			warnings = append(warnings, w)
+		}
+		sort.Strings(warnings)
+		for _, w := range warnings {
+			fmt.Println("  " + t.Label.Sprintf("%s: ", w) + t.Value.Sprint(strings.Join(flagged[w], ", ")))
+		}
+		return nil
+	},
+}
+
+// installPayload is the hook payload for an installed deck
+type installPayload struct {
+	Deck string `json:"deck"`
+	ID   string `json:"id"`
+}
+
+// deckInstallCmd represents the deck install command
+var deckInstallCmd = &cobra.Command{
+	Use:   "install [archive]",
+	Short: "Install a deck from a .zip or .tar.gz archive",
+	Long: `Install unpacks a deck archive into your deck library. The archive may contain
+the deck files at its root or inside a single top-level directory.
+
+Archives are treated as untrusted: entries with absolute paths or parent directory
+references, symbolic and hard links, device files and oversized files are rejected,
+and extracted files are given normalized permissions. Files named in the deck's
+.deckignore are left out, as they would be from a package.
+
+Once the deck is installed, post-install hooks from config.toml are run with
+the installed deck's path and ID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		force, _ := cmd.Flags().GetBool("force")
+
+		libraryPath := config.GetDeckLibraryPath()
+		if err := os.MkdirAll(libraryPath, 0755); err != nil {
+			return fmt.Errorf("error creating deck library: %v", err)
+		}
+
+		// Extract next to the library so the final rename stays on one filesystem
+		tmpDir, err := os.MkdirTemp(libraryPath, ".install-")
+		if err != nil {
+			return fmt.Errorf("error creating temporary directory: %v", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		if err := archive.Extract(args[0], tmpDir); err != nil {
+			return fmt.Errorf("error extracting %s: %v", args[0], err)
+		}
+
+		deckRoot, err := findDeckRoot(tmpDir)
+		if err != nil {
+			return err
+		}
+		if err := archive.RemoveIgnored(deckRoot); err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckRoot)
+		if err != nil {
+			return fmt.Errorf("archive does not contain a valid deck: %v", err)
+		}
+
+		if name == "" {
+			name = d.ID
+		}
+		if name == "" || deck.CheckID(name) != nil {
+			return fmt.Errorf("invalid deck name %q; use --name to choose one", name)
+		}
+
+		target := filepath.Join(libraryPath, name)
+		if _, err := os.Lstat(target); err == nil {
+			if !force {
+				return fmt.Errorf("deck %s already exists in %s (use --force to replace it)", name, libraryPath)
+			}
+			if err := os.RemoveAll(target); err != nil {
+				return fmt.Errorf("error removing existing deck: %v", err)
+			}
+		}
+
+		if err := os.Rename(deckRoot, target); err != nil {
+			return fmt.Errorf("error installing deck: %v", err)
+		}
+
+		fmt.Printf("Installed %s (%s) to %s\n", d.Name, d.Version, target)
+		return runHooks(hooks.PostInstall, installPayload{Deck: target, ID: d.ID})
+	},
+}
+
+// findDeckRoot locates deck.toml at the top of an extracted archive or inside
+// its single top-level directory
+func findDeckRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "deck.toml")); err == nil {
+		return dir, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("error reading extracted archive: %v", err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		root := filepath.Join(dir, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(root, "deck.toml")); err == nil {
+			return root, nil
+		}
+	}
+
+	return "", fmt.Errorf("deck.toml not found in archive")
+}
+
+// Asset modes for deck clone
+const (
+	cloneAssetsAll       = "all"       // Copy every file
+	cloneAssetsNames     = "names"     // Copy deck.toml and names/ only
+	cloneAssetsStructure = "structure" // Copy deck.toml and recreate the directory layout
+)
+
+// deckCloneCmd represents the deck clone command
+var deckCloneCmd = &cobra.Command{
+	Use:     "clone [source] [destination]",
+	Aliases: []string{"fork"},
+	Short:   "Copy a deck to bootstrap a derivative deck",
+	Long: `Clone copies a deck into a new directory and rewrites its deck.toml with a new
+ID, version and name, as a starting point for recolors, borderless editions and
+translations of an existing deck.
+
+The destination is created in your deck library unless it contains a path
+separator. Use --assets to control what is copied:
+  all        every file (default)
+  names      deck.toml and the names/ directory, without images or ANSI art
+  structure  deck.toml and the empty directory layout`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, _ := cmd.Flags().GetString("id")
+		version, _ := cmd.Flags().GetString("version")
+		name, _ := cmd.Flags().GetString("name")
+		assets, _ := cmd.Flags().GetString("assets")
+
+		switch assets {
+		case cloneAssetsAll, cloneAssetsNames, cloneAssetsStructure:
+		default:
+			return fmt.Errorf("invalid --assets mode: %s (supported: all, names, structure)", assets)
+		}
+
+		srcPath, err := config.GetDeckPath(args[0])
+		if err != nil {
+			return err
+		}
+		src, err := deck.LoadDeck(srcPath)
+		if err != nil {
+			return fmt.Errorf("error loading source deck: %v", err)
+		}
+
+		destPath := args[1]
+		if !strings.ContainsRune(destPath, filepath.Separator) && !strings.ContainsRune(destPath, '/') {
+			destPath = filepath.Join(config.GetDeckLibraryPath(), destPath)
+		}
+		if _, err := os.Lstat(destPath); err == nil {
+			return fmt.Errorf("destination already exists: %s", destPath)
+		}
+		if deck.Contains(srcPath, destPath) {
+			return fmt.Errorf("destination %s is inside the source deck", destPath)
+		}
+
+		if id == "" {
+			id = filepath.Base(destPath)
+		}
+		if version == "" {
+			version = "0.1.0"
+		}
+
+		if err := copyDeckTree(srcPath, destPath, assets); err != nil {
+			os.RemoveAll(destPath)
+			return err
+		}
+
+		tomlPath := filepath.Join(destPath, "deck.toml")
+		data, err := os.ReadFile(tomlPath)
+		if err != nil {
+			return fmt.Errorf("error reading cloned deck.toml: %v", err)
+		}
+
+		updates := map[string]string{"id": id, "version": version}
+		if name != "" {
+			updates["name"] = name
+		}
+		rewritten := rewriteDeckSection(string(data), updates)
+		if err := os.WriteFile(tomlPath, []byte(rewritten), 0644); err != nil {
+			return fmt.Errorf("error writing cloned deck.toml: %v", err)
+		}
+
+		fmt.Printf("Cloned %s (%s %s) to %s as %s %s\n", src.Name, src.ID, src.Version, destPath, id, version)
+		return nil
+	},
+}
+
+// copyDeckTree copies a deck directory according to the asset mode. Symbolic
+// links and other non-regular files are skipped rather than followed.
+func copyDeckTree(src, dest, assets string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		topLevel := strings.Split(filepath.ToSlash(rel), "/")[0]
+		switch assets {
+		case cloneAssetsNames:
+			if rel != "deck.toml" && topLevel != "names" {
+				return nil
+			}
+		case cloneAssetsStructure:
+			if rel != "deck.toml" {
+				return nil
+			}
+		}
+
+		return copyFile(path, target)
+	})
+}
+
+// copyFile copies a regular file, creating the destination with mode 0644
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", dest, err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("error copying %s: %v", src, err)
+	}
+	return out.Close()
+}
+
+// rewriteDeckSection replaces keys in the [deck] table of a deck.toml while
+// keeping comments and formatting. Keys missing from the table are appended to it.
+func rewriteDeckSection(data string, updates map[string]string) string {
+	lines := strings.Split(data, "\n")
+	keyLine := regexp.MustCompile(`^(\s*)([A-Za-z_]+)(\s*=\s*).*$`)
+
+	inDeck := false
+	deckEnd := -1
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			if inDeck && deckEnd < 0 {
+				deckEnd = i
+			}
+			inDeck = trimmed == "[deck]"
+			continue
+		}
+		if !inDeck {
+			continue
+		}
+
+		m := keyLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if value, ok := updates[m[2]]; ok {
+			lines[i] = fmt.Sprintf("%s%s%s%q", m[1], m[2], m[3], value)
+			seen[m[2]] = true
+		}
+	}
+	if inDeck && deckEnd < 0 {
+		deckEnd = len(lines)
+	}
+
+	var missing []string
+	for _, key := range []string{"id", "name", "version"} {
+		if value, ok := updates[key]; ok && !seen[key] {
+			missing = append(missing, fmt.Sprintf("%s = %q", key, value))
+		}
+	}
+	if len(missing) == 0 {
+		return strings.Join(lines, "\n")
+	}
+	if deckEnd < 0 {
+		return "[deck]\n" + strings.Join(missing, "\n") + "\n\n" + strings.Join(lines, "\n")
+	}
+
+	// Insert before any blank lines that separate [deck] from the next table
+	for deckEnd > 0 && strings.TrimSpace(lines[deckEnd-1]) == "" {
+		deckEnd--
+	}
+	result := append([]string{}, lines[:deckEnd]...)
+	result = append(result, missing...)
+	result = append(result, lines[deckEnd:]...)
+	return strings.Join(result, "\n")
+}
+
+// deckExportSocialCmd represents the deck export-social command
+var deckExportSocialCmd = &cobra.Command{
+	Use:   "export-social [deck]",
+	Short: "Render a banner image of a deck for listings and READMEs",
+	Long: `Export-social composites a banner image showing the deck's icon, name and a
+fanned sample of its cards, for registry listings, social previews and
+repository READMEs. The default deck is used when none is given.
+
+Built-in templates: og (1200x630), github (1280x640) and banner (1500x500).
+Custom templates are read from XDG_DATA_HOME/cartomancer/social/*.toml:
+
+  id = "square"
+  width = 1080
+  height = 1080
+  background = "#101018"
+  foreground = "#f0f0f0"
+  accent = "#c9a227"             # default: the deck's style accent
+  cards = ["0", "XVII", "cups/queen"]
+  fan = 60                       # total fan angle in degrees
+
+Examples:
+  cartomancer deck export-social
+  cartomancer deck export-social rider-waite-smith --template github -o preview.png`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		if err := social.LoadDir(config.GetSocialTemplatesDir()); err != nil {
+			return err
+		}
+		templateID, _ := cmd.Flags().GetString("template")
+		tmpl, err := social.Get(templateID)
+		if err != nil {
+			return err
+		}
+
+		content, err := socialContent(d, tmpl)
+		if err != nil {
+			return err
+		}
+
+		outputPath, _ := cmd.Flags().GetString("output")
+		if outputPath == "" {
+			outputPath = fmt.Sprintf("%s-%s.png", d.ID, tmpl.ID)
+		}
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("error creating image: %v", err)
+		}
+		defer file.Close()
+
+		if err := png.Encode(file, tmpl.Render(content)); err != nil {
+			return fmt.Errorf("error encoding image: %v", err)
+		}
+
+		fmt.Printf("Banner saved to %s\n", outputPath)
+		return nil
+	},
+}
+
+// socialContent gathers the title, icon and sample card images of a deck for a banner
+func socialContent(d *deck.Deck, tmpl *social.Template) (social.Content, error) {
+	content := social.Content{Title: d.Name}
+
+	if d.Author != "" {
+		content.Details = append(content.Details, "by "+d.Author)
+	}
+	summary := fmt.Sprintf("%d cards", len(d.Cards()))
+	if d.Version != "" {
+		summary = "v" + strings.TrimPrefix(d.Version, "v") + " - " + summary
+	}
+	content.Details = append(content.Details, summary)
+
+	// Icons may be vector images, which are simply left out
+	if iconPath, err := d.IconPath(); err == nil {
+		if icon, err := decodeImageFile(iconPath); err == nil {
+			content.Icon = icon
+		}
+	}
+
+	var back image.Image
+	for _, id := range tmpl.SampleCards() {
+		canonical, err := card.ParseID(id)
+		if err != nil {
+			return content, err
+		}
+		c, err := d.GetCard(canonical)
+		if err != nil {
+			// Partial decks may not contain every sample card
+			continue
+		}
+
+		if content.Accent == nil {
+			if accent, ok := d.StyleFor(c).AccentColor(); ok {
+				content.Accent = accent
+			}
+		}
+
+		img, err := loadHighestResImage(d.AssetRoots(), c)
+		if err != nil {
+			if back == nil {
+				back = deckBackImage(d)
+			}
+			img = back
+		}
+		content.Cards = append(content.Cards, img)
+	}
+
+	return content, nil
+}
+
+// deckBackImage decodes the deck's card back, falling back to a placeholder
+func deckBackImage(d *deck.Deck) image.Image {
+	if backPath, err := d.CardBackPath(); err == nil {
+		if back, err := decodeImageFile(backPath); err == nil {
+			return back
+		}
+	}
+
+	layout := composite.DefaultLayout(300, 525)
+	return composite.PlaceholderBack(layout.CardWidth, layout.CardHeight, layout.BlankCard, layout.Foreground)
+}
+
+// deckPackageCmd represents the deck package command
+var deckPackageCmd = &cobra.Command{
+	Use:   "package [deck]",
+	Short: "Build a reproducible deck archive and checksum manifest",
+	Long: `Package writes a deck to <id>-<version>.tar.gz for distribution. Files are
+stored in lexical order with fixed timestamps, permissions and owners, and
+hidden files are left out, so packaging the same deck on any machine produces
+a byte-identical archive with the same checksum.
+
+Working files kept alongside the deck, such as layered sources, can be left out
+by listing them in a .deckignore file at the deck root. Patterns follow the
+rules of .gitignore, and publish and validate honor the file too:
+
+  *.psd
+  sources/
+  /h750/**/drafts
+  !card_backs/print.psd
+
+Alongside the archive, <id>-<version>.manifest.json lists every packaged file
+with its size and SHA-256 checksum. The output directory must be outside the
+deck, so that the artifacts are never packaged along with it.
+
+With --policy, the deck is first checked against a registry policy file of
+size limits and required tiers (see validate) and nothing is packaged if it
+breaks the policy.
+
+Examples:
+  cartomancer deck package ./my-deck
+  cartomancer deck package ./my-deck -o dist
+  cartomancer deck package ./my-deck --policy registry-policy.toml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		if policyPath, _ := cmd.Flags().GetString("policy"); policyPath != "" {
+			if err := checkPolicy(deckPath, policyPath); err != nil {
+				return err
+			}
+		}
+
+		outDir, _ := cmd.Flags().GetString("output")
+		if err := registry.CheckOutDir(d, outDir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			return fmt.Errorf("error creating output directory: %v", err)
+		}
+
+		meta, err := registry.Package(d, outDir)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Packaged %s %s:\n", d.Name, d.Version)
+		for _, a := range []registry.Artifact{meta.Archive, meta.Manifest} {
+			fmt.Printf("  %-40s %s\n", a.Path(), a.SHA256)
+		}
+		return nil
+	},
+}
+
+// deckAuditPathsCmd represents the deck audit-paths command
+var deckAuditPathsCmd = &cobra.Command{
+	Use:   "audit-paths [deck]",
+	Short: "Check a deck's file names for portability problems",
+	Long: `Audit-paths checks the names of a deck's files for problems that break the
+deck when it is moved between macOS, Linux and Windows or packaged:
+
+  - names in one directory that differ only by case, such as ace.png and Ace.png
+  - names that are not valid UTF-8
+  - names Windows reserves (CON, NUL, COM1 and the like) or cannot store, with
+    characters such as : or ? or a trailing dot or space
+  - paths over 200 bytes, which can pass the Windows limit once installed
+  - symlinks, which cannot be packaged
+
+Hidden files and directories and those named in the deck's .deckignore are left
+out, as they are from packages. validate reports the same problems as warnings;
+audit-paths fails when there are any.
+
+Examples:
+  cartomancer deck audit-paths ./my-deck
+  cartomancer deck audit-paths thoth`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		problems, err := validator.AuditPaths(deckPath)
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			fmt.Printf("✅ No path problems found in '%s'.\n", deckPath)
+			return nil
+		}
+
+		fmt.Printf("❌ Deck '%s' has %d path problems:\n", deckPath, len(problems))
+		for i, problem := range problems {
+			fmt.Printf("%d. %s\n", i+1, problem)
+		}
+		return fmt.Errorf("path audit failed")
+	},
+}
+
+// deckPublishCmd represents the deck publish command
+var deckPublishCmd = &cobra.Command{
+	Use:   "publish [deck]",
+	Short: "Package a deck and upload it to a registry",
+	Long: `Publish validates a deck strictly, packages it as <id>-<version>.tar.gz, renders
+preview thumbnails (drawn from the ANSI art of decks without images) and
+writes registry metadata (<id>-<version>.json) listing the deck's details, tags
+and the SHA-256 checksum of every artifact (see deck package for the archive
+and its manifest). The artifacts are then uploaded to a registry configured in config.toml:
+
+  [registries.github]
+  backend = "github"             # assets of the release tagged <id>-v<version>
+  repo = "owner/decks"
+  token_env = "GITHUB_TOKEN"
+
+  [registries.bucket]
+  backend = "s3"                 # credentials from AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY
+  bucket = "my-decks"
+  region = "eu-west-1"
+  prefix = "decks"
+  # endpoint = "https://minio.example.com"   for S3-compatible storage
+
+  [registries.web]
+  backend = "http"               # PUT <url>/<prefix>/<name>
+  url = "https://decks.example.com/upload"
+  token_env = "DECKS_TOKEN"
+
+Any validation error or warning stops the publish. Use --dry-run to build the
+artifacts without uploading them.
+
+Examples:
+  cartomancer deck publish ./my-deck --dry-run -o dist
+  cartomancer deck publish ./my-deck --registry github`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		// Resolve the registry up front so configuration errors surface before validating
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		var target namedRegistry
+		if !dryRun {
+			name, _ := cmd.Flags().GetString("registry")
+			if target, err = publishRegistry(name); err != nil {
+				return err
+			}
+		}
+
+		if err := validateStrict(deckPath); err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		var backend registry.Backend
+		if !dryRun {
+			release := fmt.Sprintf("%s-v%s", d.ID, strings.TrimPrefix(d.Version, "v"))
+			if backend, err = registry.NewBackend(target.Registry, release); err != nil {
+				return fmt.Errorf("registry %s: %v", target.name, err)
+			}
+		}
+
+		outDir, _ := cmd.Flags().GetString("output")
+		if outDir == "" {
+			outDir, err = os.MkdirTemp("", "cartomancer-publish-")
+			if err != nil {
+				return fmt.Errorf("error creating output directory: %v", err)
+			}
+		} else if err := registry.CheckOutDir(d, outDir); err != nil {
+			return err
+		} else if err := os.MkdirAll(outDir, 0755); err != nil {
+			return fmt.Errorf("error creating output directory: %v", err)
+		}
+
+		meta, err := registry.Package(d, outDir)
+		if err != nil {
+			return err
+		}
+		meta.Previews, err = writePreviews(d, outDir)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			metaArtifact, err := registry.WriteMetadata(meta, outDir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Packaged %s %s in %s:\n", d.Name, d.Version, outDir)
+			for _, a := range append([]registry.Artifact{meta.Archive, meta.Manifest, metaArtifact}, meta.Previews...) {
+				fmt.Printf("  %-40s %s\n", a.Name, a.SHA256)
+			}
+			return nil
+		}
+
+		fmt.Printf("Publishing %s %s to %s\n", d.Name, d.Version, target.name)
+		for _, a := range append([]*registry.Artifact{&meta.Archive, &meta.Manifest}, previewPointers(meta.Previews)...) {
+			if a.URL, err = backend.Upload(a); err != nil {
+				return fmt.Errorf("error uploading %s: %v", a.Name, err)
+			}
+			fmt.Printf("  uploaded %s\n", a.Name)
+		}
+
+		// The metadata records the download URLs, so it is uploaded last
+		metaArtifact, err := registry.WriteMetadata(meta, outDir)
+		if err != nil {
+			return err
+		}
+		url, err := backend.Upload(&metaArtifact)
+		if err != nil {
+			return fmt.Errorf("error uploading %s: %v", metaArtifact.Name, err)
+		}
+		fmt.Printf("  uploaded %s\n\nMetadata: %s\n", metaArtifact.Name, url)
+
+		return nil
+	},
+}
+
+// namedRegistry is a registry from config.toml together with its name
+type namedRegistry struct {
+	config.Registry
+	name string
+}
+
+// publishRegistry returns the named registry, or the only configured registry
+// when no name is given
+func publishRegistry(name string) (namedRegistry, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return namedRegistry{}, fmt.Errorf("error loading config: %v", err)
+	}
+
+	names := make([]string, 0, len(cfg.Registries))
+	for key := range cfg.Registries {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	switch {
+	case len(names) == 0:
+		return namedRegistry{}, fmt.Errorf("no registries configured in %s (see deck publish --help)", config.GetConfigFilePath())
+	case name == "" && len(names) == 1:
+		name = names[0]
+	case name == "":
+		return namedRegistry{}, fmt.Errorf("several registries configured, choose one with --registry (available: %s)", strings.Join(names, ", "))
+	}
+
+	r, ok := cfg.Registries[name]
+	if !ok {
+		return namedRegistry{}, fmt.Errorf("registry not found: %s (available: %s)", name, strings.Join(names, ", "))
+	}
+	return namedRegistry{Registry: r, name: name}, nil
+}
+
+// validateStrict validates a deck, including custom rules, and fails on any
+// error or warning
+func validateStrict(deckPath string) error {
+	v := validator.NewValidator(deckPath)
+	v.Public = true
+	if _, err := v.Validate(); err != nil {
+		return fmt.Errorf("validation error: %v", err)
+	}
+
+	rules, err := script.LoadRules(config.GetRulesDir())
+	if err != nil {
+		return err
+	}
+	results, err := v.ValidateRules(rules)
+	if err != nil {
+		return fmt.Errorf("validation error: %v", err)
+	}
+
+	problems := append(results.Errors, results.Warnings...)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	fmt.Printf("Deck '%s' is not ready to publish (%d errors, %d warnings):\n",
+		deckPath, len(results.Errors), len(results.Warnings))
+	for i, problem := range problems {
+		fmt.Printf("%d. %s\n", i+1, problem)
+	}
+	return fmt.Errorf("strict validation failed")
+}
+
+// checkPolicy checks a deck against a registry policy file, listing any
+// violations
+func checkPolicy(deckPath, policyPath string) error {
+	policy, err := validator.LoadPolicy(policyPath)
+	if err != nil {
+		return err
+	}
+	results, err := validator.NewValidator(deckPath).ValidatePolicy(policy)
+	if err != nil {
+		return fmt.Errorf("validation error: %v", err)
+	}
+	if len(results.Errors) == 0 {
+		return nil
+	}
+
+	fmt.Printf("Deck '%s' breaks the policy in %s (%d errors):\n", deckPath, policyPath, len(results.Errors))
+	for i, problem := range results.Errors {
+		fmt.Printf("%d. %s\n", i+1, problem)
+	}
+	return fmt.Errorf("policy check failed")
+}
+
+// previewCards lists the cards rendered as preview thumbnails
+var previewCards = []string{"major_arcana.00", "major_arcana.01", "major_arcana.02"}
+
+// previewHeight is the height in pixels of card preview thumbnails
+const previewHeight = 256
+
+// writePreviews renders a social banner and thumbnails of the card back and a
+// few cards into outDir
+func writePreviews(d *deck.Deck, outDir string) ([]registry.Artifact, error) {
+	base := registry.BaseName(d)
+	var previews []registry.Artifact
+
+	save := func(img image.Image, suffix, label string) error {
+		path := filepath.Join(outDir, fmt.Sprintf("%s-%s.png", base, suffix))
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error creating preview: %v", err)
+		}
+		err = png.Encode(file, img)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("error encoding preview: %v", err)
+		}
+
+		a, err := registry.NewArtifact(path, "image/png")
+		if err != nil {
+			return err
+		}
+		a.Label = label
+		previews = append(previews, a)
+		return nil
+	}
+
+	tmpl, err := social.Get("og")
+	if err != nil {
+		return nil, err
+	}
+	content, err := socialContent(d, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if err := save(tmpl.Render(content), "social", "social banner"); err != nil {
+		return nil, err
+	}
+
+	thumbnail := func(img image.Image) image.Image {
+		return resize.Resize(0, previewHeight, img, resize.Lanczos3)
+	}
+
+	if backPath, err := d.CardBackPath(); err == nil {
+		if back, err := decodeScaledImageFile(backPath, previewHeight); err == nil {
+			if err := save(thumbnail(back), "back", "card back"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, id := range previewCards {
+		c, err := d.GetCard(id)
+		if err != nil {
+			continue
+		}
+		img, err := loadScaledImage(d.AssetRoots(), c, previewHeight)
+		if err != nil {
+			// Decks of ANSI art alone get thumbnails of the art as drawn
+			// in a terminal
+			if img, err = loadAnsiArtImage(d.AssetRoots(), c); err != nil {
+				continue
+			}
+		}
+		if err := save(thumbnail(img), strings.ReplaceAll(id, ".", "-"), id); err != nil {
+			return nil, err
+		}
+	}
+
+	return previews, nil
+}
+
+// previewPointers returns pointers to the previews so uploads can record their URLs
+func previewPointers(previews []registry.Artifact) []*registry.Artifact {
+	pointers := make([]*registry.Artifact, len(previews))
+	for i := range previews {
+		pointers[i] = &previews[i]
+	}
+	return pointers
+}
+
+// deckAttributionCmd represents the deck attribution command
+var deckAttributionCmd = &cobra.Command{
+	Use:   "attribution [deck]",
+	Short: "Print the credits and licenses of decks",
+	Long: `Attribution prints a credits document naming the author, publisher, website and
+license of a deck, and who wrote its card descriptions. Use --all to include
+every deck in your library, for example when bundling deck assets in an app.
+
+Examples:
+  cartomancer deck attribution
+  cartomancer deck attribution --all > CREDITS.md
+  cartomancer deck attribution --all --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "markdown" && format != "text" && format != "json" {
+			return fmt.Errorf("unknown format: %s (supported: markdown, text, json)", format)
+		}
+
+		all, _ := cmd.Flags().GetBool("all")
+		if all && len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with a deck argument")
+		}
+
+		var deckPaths []string
+		if all {
+			entries, err := os.ReadDir(config.GetDeckLibraryPath())
+			if err != nil {
+				return fmt.Errorf("error reading deck library: %v", err)
+			}
+			for _, entry := range entries {
+				deckPaths = append(deckPaths, filepath.Join(config.GetDeckLibraryPath(), entry.Name()))
+			}
+		} else {
+			var deckFlag string
+			if len(args) > 0 {
+				deckFlag = args[0]
+			}
+			deckPath, err := resolveDeckPath(deckFlag)
+			if err != nil {
+				return err
+			}
+			deckPaths = []string{deckPath}
+		}
+
+		var credits []deckCredits
+		for _, deckPath := range deckPaths {
+			d, err := deck.LoadDeck(deckPath)
+			if err != nil {
+				if !all {
+					return fmt.Errorf("error loading deck: %v", err)
+				}
+				fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", filepath.Base(deckPath), err)
+				continue
+			}
+			credits = append(credits, newDeckCredits(d))
+		}
+
+		if format == "json" {
+			out, err := json.MarshalIndent(credits, "", "  ")
+			if err != nil {
+				return fmt.Errorf("error encoding attribution: %v", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		printCredits(credits, format == "markdown")
+		return nil
+	},
+}
+
+// deckCredits is the attribution information of a deck
+type deckCredits struct {
+	Deck          string          `json:"deck"`
+	ID            string          `json:"id"`
+	Version       string          `json:"version,omitempty"`
+	Author        string          `json:"author,omitempty"`
+	Publisher     string          `json:"publisher,omitempty"`
+	Website       string          `json:"website,omitempty"`
+	License       string          `json:"license,omitempty"` // SPDX expression as declared
+	Licenses      []licenseCredit `json:"licenses,omitempty"`
+	NonCommercial bool            `json:"non_commercial,omitempty"`
+	Descriptions  string          `json:"descriptions,omitempty"` // Credit for the card descriptions
+}
+
+// licenseCredit names a license in an expression
+type licenseCredit struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// newDeckCredits collects the attribution information of a deck
+func newDeckCredits(d *deck.Deck) deckCredits {
+	c := deckCredits{
+		Deck:         d.Name,
+		ID:           d.ID,
+		Version:      d.Version,
+		Author:       d.Author,
+		Publisher:    d.Publisher,
+		Website:      d.Website,
+		License:      d.License,
+		Descriptions: d.AltTextAttribution,
+	}
+
+	// Malformed expressions are still credited verbatim
+	if expr, err := license.Parse(d.License); err == nil {
+		c.License = expr.Text
+		c.NonCommercial = expr.NonCommercial()
+		for _, id := range expr.Licenses {
+			c.Licenses = append(c.Licenses, licenseCredit{ID: id, Name: license.Name(id), URL: license.URL(id)})
+		}
+	}
+
+	return c
+}
+
+// printCredits prints a credits document as Markdown or plain text
+func printCredits(credits []deckCredits, markdown bool) {
+	heading, item := "", "  "
+	if markdown {
+		heading, item = "## ", "- "
+		fmt.Println("# Credits")
+		fmt.Println()
+	}
+
+	for _, c := range credits {
+		title := c.Deck
+		if c.Version != "" {
+			title += " " + c.Version
+		}
+		fmt.Println(heading + title)
+		if markdown {
+			fmt.Println()
+		}
+
+		field := func(label, value string) {
+			if value != "" {
+				fmt.Printf("%s%s: %s\n", item, label, value)
+			}
+		}
+		field("Author", c.Author)
+		field("Publisher", c.Publisher)
+		field("Website", c.Website)
+
+		switch {
+		case len(c.Licenses) == 1:
+			field("License", formatLicense(c.Licenses[0], markdown))
+		case len(c.Licenses) > 1:
+			field("License", c.License)
+			for _, l := range c.Licenses {
+				fmt.Printf("%s  %s\n", item, formatLicense(l, markdown))
+			}
+		default:
+			field("License", c.License)
+		}
+		if c.License == "" {
+			field("License", "not specified")
+		}
+		if c.NonCommercial {
+			field("Note", "includes a license restricted to non-commercial use")
+		}
+		field("Card descriptions", c.Descriptions)
+		fmt.Println()
+	}
+}
+
+// formatLicense formats a license name with its ID and link
+func formatLicense(l licenseCredit, markdown bool) string {
+	text := l.ID
+	if l.Name != l.ID {
+		text = fmt.Sprintf("%s (%s)", l.Name, l.ID)
+	}
+	switch {
+	case l.URL == "":
+		return text
+	case markdown:
+		return fmt.Sprintf("[%s](%s)", text, l.URL)
+	default:
+		return fmt.Sprintf("%s <%s>", text, l.URL)
+	}
+}
+
+// deckGenPlaceholdersCmd represents the deck gen-placeholders command
+var deckGenPlaceholdersCmd = &cobra.Command{
+	Use:   "gen-placeholders [deck]",
+	Short: "Generate placeholder art for cards without images",
+	Long: `Gen-placeholders draws simple stand-in images for every card that has no art
+yet, so a work-in-progress deck can be tested with full coverage. Each image
+shows the card name, its number or rank and its suit symbol on a block of the
+deck's accent color for the card, or a color for its suit.
+
+Images are written as PNGs to the deck's placeholders/ directory, which show,
+spread and the other commands only use for cards with no art of their own.
+Placeholders for cards that have since been given art are removed, and
+validate warns while the directory exists so it is not published by mistake.
+
+Examples:
+  cartomancer deck gen-placeholders ./my-deck
+  cartomancer deck gen-placeholders ./my-deck --height 1200 --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		height, _ := cmd.Flags().GetInt("height")
+		if height < 64 {
+			return fmt.Errorf("invalid height: %d (minimum 64)", height)
+		}
+		width := height * placeholderWidth / placeholderHeight
+		if w, h, ok := cardAspect(d); ok {
+			width = height * w / h
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		dir := filepath.Join(d.Path, deck.PlaceholderDir)
+		var generated, kept, removed int
+		for _, c := range d.Cards() {
+			parts := strings.Split(c.ID, ".")
+			path, err := buildCardPath(dir, parts, ".png")
+			if err != nil {
+				return err
+			}
+
+			if _, err := findCardArt(d.Path, parts); err == nil {
+				if err := os.Remove(path); err == nil {
+					removed++
+				}
+				continue
+			}
+			if _, err := os.Stat(path); err == nil && !force {
+				kept++
+				continue
+			}
+
+			accent, ok := d.StyleFor(c).AccentColor()
+			if !ok {
+				if accent, ok = suitColors[c.Suit]; !ok {
+					accent = majorArcanaColor
+				}
+			}
+			if err := writePNG(path, placeholderArt(c, accent, false).Draw(width, height)); err != nil {
+				return fmt.Errorf("error writing placeholder for %s: %v", c.ID, err)
+			}
+			generated++
+		}
+		removeEmptyDirs(dir)
+
+		fmt.Printf("Generated %d placeholders in %s", generated, dir)
+		if kept > 0 {
+			fmt.Printf(", kept %d existing (use --force to redraw)", kept)
+		}
+		if removed > 0 {
+			fmt.Printf(", removed %d now covered by art", removed)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// cardAspect returns the dimensions of the first card image in a deck that can
+// be measured, so placeholders match the shape of the existing art
+func cardAspect(d *deck.Deck) (int, int, bool) {
+	for _, c := range d.Cards() {
+		path, err := findCardArt(d.Path, strings.Split(c.ID, "."))
+		if err != nil {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		cfg, _, err := image.DecodeConfig(file)
+		file.Close()
+		if err == nil && cfg.Width > 0 && cfg.Height > 0 {
+			return cfg.Width, cfg.Height, true
+		}
+	}
+	return 0, 0, false
+}
+
+// writePNG encodes an image to a PNG file, creating its parent directories
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(file, img)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// removeEmptyDirs removes root and the directories below it that contain no files
+func removeEmptyDirs(root string) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			removeEmptyDirs(filepath.Join(root, entry.Name()))
+		}
+	}
+	// Fails, as intended, unless the directory is now empty
+	os.Remove(root)
+}
+
+// deckGenArtCmd represents the deck gen-art command
+var deckGenArtCmd = &cobra.Command{
+	Use:   "gen-art [deck]",
+	Short: "Generate draft card art with an image generation service",
+	Long: `Gen-art sends a prompt for each card to an image generation service configured
+in config.toml and saves the results to a review directory, laid out like a
+deck image tier (major_arcana/17.png, minor_arcana/cups/two.png, ...). Nothing
+is written to the deck itself: copy the images you want to keep into the deck.
+
+Prompts are built from the card's name, its description from the names file,
+its element and the style setting, and are recorded with their seeds in
+prompts.json in the review directory. By default only cards without art are
+generated; use --cards to choose cards or --all for the whole deck.
+
+  [image_backends.sd]
+  backend = "automatic1111"     # Stable Diffusion web UI started with --api
+  url = "http://127.0.0.1:7860"
+  style = "woodcut print, muted colors"
+  negative_prompt = "text, watermark"
+  width = 512
+  height = 896
+  steps = 30
+
+  [image_backends.comfy]
+  backend = "comfyui"
+  url = "http://127.0.0.1:8188"
+  workflow = "workflows/tarot.json"   # API-format export, relative to config.toml
+                                      # with %prompt%, %negative_prompt%, %seed%,
+                                      # %width%, %height% and %steps% placeholders
+
+  [image_backends.custom]
+  backend = "command"           # any other API or tool
+  command = "my-generator --out \"$CARTOMANCER_OUTPUT\" \"$CARTOMANCER_PROMPT\""
+
+The command backend receives CARTOMANCER_CARD, CARTOMANCER_PROMPT,
+CARTOMANCER_NEGATIVE_PROMPT, CARTOMANCER_WIDTH, CARTOMANCER_HEIGHT,
+CARTOMANCER_STEPS and CARTOMANCER_SEED, and writes the image to
+CARTOMANCER_OUTPUT. The HTTP backends send token_env as a bearer token.
+
+Examples:
+  cartomancer deck gen-art ./my-deck --dry-run
+  cartomancer deck gen-art ./my-deck --backend sd --cards XVII,cups/queen
+  cartomancer deck gen-art ./my-deck --all --seed 42 -o review`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		cards, err := genArtCards(cmd, d)
+		if err != nil {
+			return err
+		}
+		if len(cards) == 0 {
+			fmt.Println("Every card already has art; use --all or --cards to generate more")
+			return nil
+		}
+
+		name, _ := cmd.Flags().GetString("backend")
+		target, err := imageBackend(name)
+		if err != nil {
+			return err
+		}
+
+		style, _ := cmd.Flags().GetString("style")
+		seed, _ := cmd.Flags().GetInt64("seed")
+		if seed == 0 {
+			seed = time.Now().UnixNano() % 1_000_000_000
+		}
+
+		requests := make([]imagegen.Request, len(cards))
+		for i, c := range cards {
+			requests[i] = imagegen.NewRequest(target.ImageBackend, c, style, seed+int64(i))
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			for _, req := range requests {
+				fmt.Printf("%s (seed %d)\n  %s\n", req.CardID, req.Seed, req.Prompt)
+			}
+			return nil
+		}
+
+		backend, err := imagegen.New(target.ImageBackend)
+		if err != nil {
+			return fmt.Errorf("image backend %s: %v", target.name, err)
+		}
+
+		outDir, _ := cmd.Flags().GetString("output")
+		if outDir == "" {
+			outDir = d.ID + "-review"
+		}
+		force, _ := cmd.Flags().GetBool("force")
+
+		review, err := loadReviewPrompts(outDir)
+		if err != nil {
+			return err
+		}
+
+		var failed int
+		for i, req := range requests {
+			parts := strings.Split(req.CardID, ".")
+			if existing, ok := reviewImage(outDir, parts); ok && !force {
+				fmt.Printf("  skipped %s (%s exists, use --force to regenerate)\n", cards[i].Name, existing)
+				continue
+			}
+
+			fmt.Printf("  generating %s...", cards[i].Name)
+			data, err := backend.Generate(req)
+			if err != nil {
+				fmt.Printf(" failed: %v\n", err)
+				failed++
+				continue
+			}
+
+			path, err := buildCardPath(outDir, parts, imagegen.Extension(data))
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				return fmt.Errorf("error writing %s: %v", path, err)
+			}
+			review[req.CardID] = req
+			fmt.Printf(" %s\n", path)
+		}
+
+		if err := saveReviewPrompts(outDir, review); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d images failed to generate", failed, len(requests))
+		}
+		fmt.Printf("\nReview the images in %s and copy the ones you keep into the deck\n", outDir)
+		return nil
+	},
+}
+
+// genArtCards returns the cards selected by the --cards and --all flags, or the
+// cards without art
+func genArtCards(cmd *cobra.Command, d *deck.Deck) ([]*card.Card, error) {
+	ids, _ := cmd.Flags().GetStringSlice("cards")
+	if len(ids) > 0 {
+		return cardsByID(d, ids)
+	}
+
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		return d.Cards(), nil
+	}
+
+	var missing []*card.Card
+	for _, c := range d.Cards() {
+		if _, err := findCardArt(d.Path, strings.Split(c.ID, ".")); err != nil {
+			missing = append(missing, c)
+		}
+	}
+	return missing, nil
+}
+
+// reviewImage returns the generated image for a card in a review directory
+func reviewImage(dir string, parts []string) (string, bool) {
+	for _, ext := range []string{".png", ".jpg", ".webp"} {
+		if path, err := buildCardPath(dir, parts, ext); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// namedImageBackend is an image backend from config.toml together with its name
+type namedImageBackend struct {
+	config.ImageBackend
+	name string
+}
+
+// imageBackend returns the named image backend, or the only configured backend
+// when no name is given
+func imageBackend(name string) (namedImageBackend, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return namedImageBackend{}, fmt.Errorf("error loading config: %v", err)
+	}
+
+	names := make([]string, 0, len(cfg.ImageBackends))
+	for key := range cfg.ImageBackends {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	switch {
+	case len(names) == 0:
+		return namedImageBackend{}, fmt.Errorf("no image backends configured in %s (see deck gen-art --help)", config.GetConfigFilePath())
+	case name == "" && len(names) == 1:
+		name = names[0]
+	case name == "":
+		return namedImageBackend{}, fmt.Errorf("several image backends configured, choose one with --backend (available: %s)", strings.Join(names, ", "))
+	}
+
+	b, ok := cfg.ImageBackends[name]
+	if !ok {
+		return namedImageBackend{}, fmt.Errorf("image backend not found: %s (available: %s)", name, strings.Join(names, ", "))
+	}
+	return namedImageBackend{ImageBackend: b, name: name}, nil
+}
+
+// reviewPromptsFile records the request behind each image in a review directory
+const reviewPromptsFile = "prompts.json"
+
+// loadReviewPrompts reads the requests recorded in a review directory, keyed by card ID
+func loadReviewPrompts(dir string) (map[string]imagegen.Request, error) {
+	review := map[string]imagegen.Request{}
+
+	data, err := os.ReadFile(filepath.Join(dir, reviewPromptsFile))
+	if os.IsNotExist(err) {
+		return review, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", reviewPromptsFile, err)
+	}
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", reviewPromptsFile, err)
+	}
+	return review, nil
+}
+
+// saveReviewPrompts writes the requests recorded in a review directory
+func saveReviewPrompts(dir string, review map[string]imagegen.Request) error {
+	if len(review) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %v", reviewPromptsFile, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, reviewPromptsFile), append(data, '\n'), 0644)
+}
+
+// deckGenAltTextCmd represents the deck gen-alt-text command
+var deckGenAltTextCmd = &cobra.Command{
+	Use:   "gen-alt-text [deck]",
+	Short: "Draft alt text for cards that lack it",
+	Long: `Gen-alt-text reports the cards without alt text and drafts a description for
+each of them, writing the drafts to a proposal file for review. The proposal
+uses the alt_text tables of a names file, so entries can be checked against
+the card art, corrected and copied into names/<lang>.toml.
+
+By default the drafts describe each card's traditional imagery. Use --template
+to draft from a Go text/template file instead, which receives the card fields
+(.Name, .Suit, .Rank, .Number, .Element, ...) and .Keywords, the traditional
+imagery. Use --llm to draft with a language model through an OpenAI-compatible
+endpoint configured in config.toml:
+
+  [llm]
+  url = "http://localhost:11434/v1"   # Ollama, llama.cpp, or a hosted API
+  model = "llava"
+  vision = true                       # send the card art with the prompt
+  token_env = "OPENAI_API_KEY"        # optional bearer token
+
+Drafts are a starting point: always review them against the actual art.
+
+Examples:
+  cartomancer deck gen-alt-text ./my-deck
+  cartomancer deck gen-alt-text ./my-deck --llm -o proposal.toml
+  cartomancer deck gen-alt-text ./my-deck --cards XVII,cups/queen --all`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		missing := d.MissingAltText()
+		fmt.Printf("%d of %d cards in %s have no alt text\n", len(missing), len(d.Cards()), d.Name)
+
+		cards := missing
+		if ids, _ := cmd.Flags().GetStringSlice("cards"); len(ids) > 0 {
+			if cards, err = cardsByID(d, ids); err != nil {
+				return err
+			}
+		}
+		if all, _ := cmd.Flags().GetBool("all"); !all {
+			cards = withoutAltText(cards)
+		}
+		if len(cards) == 0 {
+			fmt.Println("Nothing to draft; use --all to redraft cards that have alt text")
+			return nil
+		}
+
+		drafter, source, err := altTextDrafter(cmd, d)
+		if err != nil {
+			return err
+		}
+
+		var proposals []alttext.Proposal
+		var failed int
+		for _, c := range cards {
+			text, err := drafter.Draft(c)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", c.Name, err)
+				failed++
+				continue
+			}
+			proposals = append(proposals, alttext.Proposal{Card: c, Text: text})
+		}
+
+		outPath, _ := cmd.Flags().GetString("output")
+		file, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("error creating proposal file: %v", err)
+		}
+		header := fmt.Sprintf("Alt text proposals for %s, drafted %s.\n"+
+			"Review each description against the card art, then copy the entries into names/<lang>.toml.",
+			d.Name, source)
+		err = alttext.WriteProposals(file, header, proposals)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("error writing proposal file: %v", err)
+		}
+
+		fmt.Printf("Drafted alt text for %d cards in %s\n", len(proposals), outPath)
+		if failed > 0 {
+			return fmt.Errorf("%d of %d drafts failed", failed, len(cards))
+		}
+		return nil
+	},
+}
+
+// altTextDrafter returns the drafter selected by the --llm and --template
+// flags and a description of it for the proposal header
+func altTextDrafter(cmd *cobra.Command, d *deck.Deck) (alttext.Drafter, string, error) {
+	if useLLM, _ := cmd.Flags().GetBool("llm"); useLLM {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, "", fmt.Errorf("error loading config: %v", err)
+		}
+		if cfg.LLM == nil {
+			return nil, "", fmt.Errorf("no [llm] endpoint configured in %s (see deck gen-alt-text --help)", config.GetConfigFilePath())
+		}
+
+		image := func(c *card.Card) string {
+			path, err := findCardArt(d.Path, strings.Split(c.ID, "."))
+			if err != nil || strings.HasSuffix(path, ".svg") {
+				return ""
+			}
+			return path
+		}
+		drafter, err := alttext.NewLLM(*cfg.LLM, image)
+		if err != nil {
+			return nil, "", err
+		}
+		return drafter, "with " + cfg.LLM.Model, nil
+	}
+
+	if path, _ := cmd.Flags().GetString("template"); path != "" {
+		tmpl, err := alttext.LoadTemplate(path)
+		if err != nil {
+			return nil, "", err
+		}
+		return tmpl, "from " + filepath.Base(path), nil
+	}
+
+	return alttext.DefaultTemplate(), "from traditional card imagery", nil
+}
+
+// cardsByID looks up cards by their IDs in any notation accepted by card.ParseID
+func cardsByID(d *deck.Deck, ids []string) ([]*card.Card, error) {
+	var cards []*card.Card
+	for _, id := range ids {
+		c, err := d.GetCard(id)
+		if err != nil {
+			return nil, fmt.Errorf("error getting card: %v", err)
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+// withoutAltText returns the cards that have no alt text
+func withoutAltText(cards []*card.Card) []*card.Card {
+	var missing []*card.Card
+	for _, c := range cards {
+		if strings.TrimSpace(c.AltText) == "" {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
 func init() {
 	RootCmd.AddCommand(deckCmd)
 	deckCmd.AddCommand(deckListCmd)
 	deckCmd.AddCommand(deckSetDefaultCmd)
 	deckCmd.AddCommand(deckInitCmd)
+	deckCmd.AddCommand(deckWhichCmd)
+	deckCmd.AddCommand(deckInfoCmd)
+	deckCmd.AddCommand(deckInstallCmd)
+	deckCmd.AddCommand(deckCloneCmd)
+	deckCmd.AddCommand(deckExportSocialCmd)
+	deckCmd.AddCommand(deckPackageCmd)
+	deckCmd.AddCommand(deckAuditPathsCmd)
+	deckCmd.AddCommand(deckPublishCmd)
+	deckCmd.AddCommand(deckAttributionCmd)
+	deckCmd.AddCommand(deckGenPlaceholdersCmd)
+	deckCmd.AddCommand(deckGenArtCmd)
+	deckCmd.AddCommand(deckGenAltTextCmd)
+
+	deckInstallCmd.Flags().String("name", "", "Directory name in the deck library (default: the deck ID)")
+	deckInstallCmd.Flags().Bool("force", false, "Replace an existing deck with the same name")
+
+	deckCloneCmd.Flags().String("id", "", "Deck ID for the clone (default: destination directory name)")
+	deckCloneCmd.Flags().String("version", "", "Version for the clone (default: 0.1.0)")
+	deckCloneCmd.Flags().String("name", "", "Display name for the clone (default: keep the source name)")
+	deckCloneCmd.Flags().String("assets", cloneAssetsAll, "What to copy: all, names or structure")
+
+	deckExportSocialCmd.Flags().String("template", "og", "Banner template: og, github, banner or a custom template ID")
+	deckExportSocialCmd.Flags().StringP("output", "o", "", "Output PNG path (default: <deck-id>-<template>.png)")
+
+	deckPackageCmd.Flags().StringP("output", "o", ".", "Directory for the archive and manifest")
+	deckPackageCmd.Flags().String("policy", "", "Refuse to package a deck that breaks a registry policy file")
+
+	deckPublishCmd.Flags().String("registry", "", "Registry from config.toml to upload to (default: the only one configured)")
+	deckPublishCmd.Flags().Bool("dry-run", false, "Build and checksum the artifacts without uploading them")
+	deckPublishCmd.Flags().StringP("output", "o", "", "Directory for the artifacts (default: a temporary directory)")
+
+	deckAttributionCmd.Flags().Bool("all", false, "Include every deck in your deck library")
+	deckAttributionCmd.Flags().String("format", "markdown", "Output format: markdown, text or json")
+
+	deckGenPlaceholdersCmd.Flags().Int("height", 750, "Height in pixels of the placeholder images")
+	deckGenPlaceholdersCmd.Flags().Bool("force", false, "Redraw placeholders that already exist")
+
+	deckGenArtCmd.Flags().String("backend", "", "Image backend from config.toml (default: the only one configured)")
+	deckGenArtCmd.Flags().StringSlice("cards", nil, "Cards to generate, e.g. XVII,cups/queen (default: cards without art)")
+	deckGenArtCmd.Flags().Bool("all", false, "Generate art for every card, including cards that have art")
+	deckGenArtCmd.Flags().String("style", "", "Style appended to every prompt (default: the backend's style setting)")
+	deckGenArtCmd.Flags().Int64("seed", 0, "Seed for the first card, incremented for each card (default random)")
+	deckGenArtCmd.Flags().StringP("output", "o", "", "Review directory for the images (default: <deck-id>-review)")
+	deckGenArtCmd.Flags().Bool("force", false, "Regenerate cards that already have an image in the review directory")
+	deckGenArtCmd.Flags().Bool("dry-run", false, "Print the prompts without generating anything")
+
+	deckGenAltTextCmd.Flags().Bool("llm", false, "Draft with the language model endpoint configured in config.toml")
+	deckGenAltTextCmd.Flags().String("template", "", "Draft from a Go text/template file")
+	deckGenAltTextCmd.Flags().StringSlice("cards", nil, "Cards to draft, e.g. XVII,cups/queen (default: cards without alt text)")
+	deckGenAltTextCmd.Flags().Bool("all", false, "Also draft cards that already have alt text")
+	deckGenAltTextCmd.Flags().StringP("output", "o", "alt-text-proposal.toml", "Proposal file to write")
 }
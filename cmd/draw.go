@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+	"github.com/arcanaland/cartomancer/internal/numerology"
+	"github.com/arcanaland/cartomancer/internal/pool"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+// drawCmd represents the draw command
+var drawCmd = &cobra.Command{
+	Use:   "draw [count]",
+	Short: "Draw cards from the deck or a subset of it",
+	Long: `Draw shuffles the deck, or a pool of cards from it, and draws the given number of
+cards (default 1).
+
+Built-in pools: full, majors, minors, courts, pips, wands, cups, swords and
+pentacles. Custom pools are read from XDG_DATA_HOME/cartomancer/pools/*.toml:
+
+  id = "love"
+  name = "Love and relationships"
+  cards = ["cups", "major_arcana.06", "XVII"]  # pool names or card IDs
+
+  [weights]                                    # optional, unlisted cards weigh 1
+  "major_arcana.06" = 3
+
+Use --numerology to add up the values of the cards drawn (major arcana count
+their number, pips theirs and court cards nothing) and reduce the sum to a
+quintessence card, and to list the ranks and suits that repeat.
+
+Use --decks to draw from several decks at once, for comparative readings or to
+mix an oracle deck into a tarot reading: the pool is taken from each deck and
+shuffled together, and every card drawn is listed with the deck it came from.
+With --art the cards are shown with their own deck's art.
+
+Use --from favorites to draw only from the cards on your favorites list (see
+fav --help). Favorites are marked with a star wherever cards are listed.
+
+Use --copy to place the cards drawn on the clipboard as plain text, or
+--copy=image for the image of a single card drawn (see show --help).
+
+Examples:
+  cartomancer draw
+  cartomancer draw 5 --pool majors
+  cartomancer draw 3 --pool love --seed 42
+  cartomancer draw 5 --numerology
+  cartomancer draw 2 --from favorites
+  cartomancer draw 3 --copy
+  cartomancer draw --decks thoth,rider-waite-smith --count 3 --art`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		count, _ := cmd.Flags().GetInt("count")
+		if len(args) > 0 {
+			if cmd.Flags().Changed("count") {
+				return fmt.Errorf("give the card count as an argument or with --count, not both")
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid card count: %s", args[0])
+			}
+			count = n
+		}
+		if count < 1 {
+			return fmt.Errorf("invalid card count: %d", count)
+		}
+
+		decks, err := loadDrawDecks(cmd)
+		if err != nil {
+			return err
+		}
+
+		seed, _ := cmd.Flags().GetInt64("seed")
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		shuffled, from, p, err := shuffleDecks(cmd, decks, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			return err
+		}
+		if count > len(shuffled) {
+			return fmt.Errorf("cannot draw %d cards from pool %s, which has %d", count, p.ID, len(shuffled))
+		}
+		drawn := shuffled[:count]
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+		copying, err := copyMode(cmd)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, len(decks))
+		for i, d := range decks {
+			names[i] = d.Name
+		}
+
+		fmt.Println()
+		fmt.Println(t.Label.Sprint("Pool: ") + t.Value.Sprint(p.Name))
+		if len(decks) > 1 {
+			fmt.Println(t.Label.Sprint("Decks: ") + t.Value.Sprint(strings.Join(names, ", ")))
+		} else {
+			fmt.Println(t.Label.Sprint("Deck: ") + t.Value.Sprint(decks[0].Name))
+		}
+		fmt.Println()
+		for i, c := range drawn {
+			line := fmt.Sprintf("  %2d. %s%s %s", i+1, t.Value.Sprint(c.Name), favoriteMarker(t, c), t.Muted.Sprintf("(%s)", c.ID))
+			if len(decks) > 1 {
+				line += t.Muted.Sprintf(" %s %s", t.Symbols.Separator, from[c].Name)
+			}
+			fmt.Println(line)
+		}
+		fmt.Println()
+
+		if copying != "" {
+			summary := drawSummary(p.Name, names, drawn, from)
+			if err := copyResult(copying, summary, from[drawn[0]].AssetRoots(), drawn); err != nil {
+				return err
+			}
+		}
+
+		if numerology, _ := cmd.Flags().GetBool("numerology"); numerology {
+			displayNumerology(drawn, decks[0], t)
+		}
+
+		if art, _ := cmd.Flags().GetBool("art"); art {
+			opts, err := loadDisplayOptions(cmd, strings.Join(names, ", "))
+			if err != nil {
+				return err
+			}
+			defer opts.Placeholders.printSummary()
+
+			// Each card is drawn with the art of the deck it came from
+			cardDecks := make([]*deck.Deck, len(drawn))
+			for i, c := range drawn {
+				cardDecks[i] = from[c]
+			}
+			if err := showMixedGrid(cardDecks, drawn, opts, 0); err != nil {
+				return err
+			}
+		}
+
+		return runHooks(hooks.PostDraw, newDrawsPayload(decks, seed, drawn, from))
+	},
+}
+
+// newDrawsPayload builds the hook payload for cards drawn without a spread,
+// naming the deck of each card when they come from several decks
+func newDrawsPayload(decks []*deck.Deck, seed int64, drawn []*card.Card, from map[*card.Card]*deck.Deck) drawPayload {
+	payload := drawPayload{Spread: "draw", Seed: seed}
+	if len(decks) == 1 {
+		payload.Deck = decks[0].Path
+	} else {
+		for _, d := range decks {
+			payload.Decks = append(payload.Decks, d.Path)
+		}
+	}
+	for _, c := range drawn {
+		data := drawnCardData{ID: c.ID, Name: c.Name}
+		if len(decks) > 1 {
+			data.Deck = from[c].Path
+		}
+		payload.Cards = append(payload.Cards, data)
+	}
+	return payload
+}
+
+// drawSummary describes a draw as plain text for the clipboard: the pool,
+// the decks and the cards drawn, with the deck of each when there are several
+func drawSummary(poolName string, deckNames []string, drawn []*card.Card, from map[*card.Card]*deck.Deck) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Pool: %s\n", poolName)
+	if len(deckNames) > 1 {
+		fmt.Fprintf(&b, "Decks: %s\n", strings.Join(deckNames, ", "))
+	} else {
+		fmt.Fprintf(&b, "Deck: %s\n", deckNames[0])
+	}
+	for i, c := range drawn {
+		fmt.Fprintf(&b, "%d. %s (%s)", i+1, c.Name, c.ID)
+		if len(deckNames) > 1 {
+			fmt.Fprintf(&b, ", %s", from[c].Name)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// loadDrawDecks loads the decks named by --decks, or the single deck named by
+// --deck or the default deck from config
+func loadDrawDecks(cmd *cobra.Command) ([]*deck.Deck, error) {
+	deckFlag, _ := cmd.Flags().GetString("deck")
+	names, _ := cmd.Flags().GetStringSlice("decks")
+	if len(names) > 0 && deckFlag != "" {
+		return nil, fmt.Errorf("use --deck or --decks, not both")
+	}
+	if len(names) == 0 {
+		names = []string{deckFlag}
+	}
+
+	decks := make([]*deck.Deck, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		deckPath, err := resolveDeckPath(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[deckPath] {
+			return nil, fmt.Errorf("deck %s is listed more than once", name)
+		}
+		seen[deckPath] = true
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return nil, deckLoadError(deckPath, err)
+		}
+		decks = append(decks, d)
+	}
+	return decks, nil
+}
+
+// displayNumerology prints the sum of the card values with its reductions and
+// quintessence card, and the ranks and suits that repeat
+func displayNumerology(cards []*card.Card, d *deck.Deck, t *theme.Theme) {
+	s := numerology.Analyze(cards)
+
+	quintessence := card.MajorArcanaName(fmt.Sprintf("%02d", s.Quintessence))
+	if c, err := d.GetCard(card.NewMajorArcana(s.Quintessence).ID); err == nil {
+		quintessence = c.Name
+	}
+
+	fmt.Println(t.Heading.Sprint("Numerology"))
+	fmt.Println("  " + t.Label.Sprint("Sum:          ") + t.Value.Sprintf("%d, reduced to %d", s.Sum, s.Reduced))
+	fmt.Println("  " + t.Label.Sprint("Quintessence: ") + t.Value.Sprint(quintessence) +
+		t.Muted.Sprintf(" (%d)", s.Quintessence))
+
+	var repeats []string
+	for _, r := range s.Ranks {
+		repeats = append(repeats, fmt.Sprintf("%s ×%d", titleCase(r.Name), r.Count))
+	}
+	for _, r := range s.Suits {
+		name := titleCase(r.Name)
+		if r.Name == numerology.MajorArcana {
+			name = "Major Arcana"
+		}
+		repeats = append(repeats, fmt.Sprintf("%s ×%d", name, r.Count))
+	}
+	if len(repeats) > 0 {
+		fmt.Println("  " + t.Label.Sprint("Repeated:     ") + t.Value.Sprint(strings.Join(repeats, ", ")))
+	}
+	fmt.Println()
+}
+
+// shufflePool shuffles the cards of the pool selected by the --pool flag,
+// honouring pool weights. Without the flag the full deck is used.
+func shufflePool(cmd *cobra.Command, d *deck.Deck, rng *rand.Rand) ([]*card.Card, *pool.Pool, error) {
+	cards, _, p, err := shuffleDecks(cmd, []*deck.Deck{d}, rng)
+	return cards, p, err
+}
+
+// shuffleDecks shuffles the pool selected by the --pool flag, taken from each
+// of the decks, into one blended pile like shufflePool. The returned map gives
+// the deck each card came from.
+func shuffleDecks(cmd *cobra.Command, decks []*deck.Deck, rng *rand.Rand) ([]*card.Card, map[*card.Card]*deck.Deck, *pool.Pool, error) {
+	if err := pool.LoadDir(config.GetPoolsDir()); err != nil {
+		return nil, nil, nil, err
+	}
+
+	name, _ := cmd.Flags().GetString("pool")
+	source, _ := cmd.Flags().GetString("from")
+	var favorites *pool.Pool
+	switch source {
+	case "":
+	case "favorites":
+		if name != "" {
+			return nil, nil, nil, fmt.Errorf("use --pool or --from, not both")
+		}
+		var err error
+		if favorites, err = favoritesPool(); err != nil {
+			return nil, nil, nil, err
+		}
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown card source: %s (available: favorites)", source)
+	}
+	if name == "" {
+		name = "full"
+	}
+
+	var p *pool.Pool
+	var cards []*card.Card
+	from := make(map[*card.Card]*deck.Deck)
+	for _, d := range decks {
+		deckPool := favorites
+		if deckPool == nil {
+			var err error
+			if deckPool, err = pool.Lookup(name, d.SuitNames()); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		if p == nil {
+			p = deckPool
+		}
+
+		selected, err := deckPool.Select(d.Cards())
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %v", d.Name, err)
+		}
+		for _, c := range selected {
+			from[c] = d
+		}
+		cards = append(cards, selected...)
+	}
+
+	if p.Weighted() {
+		return p.Shuffle(cards, rng), from, p, nil
+	}
+
+	rng.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+	return cards, from, p, nil
+}
+
+func init() {
+	RootCmd.AddCommand(drawCmd)
+
+	drawCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	drawCmd.Flags().StringSlice("decks", nil, "Draw from several decks shuffled together, e.g. --decks thoth,./my-oracle")
+	drawCmd.Flags().IntP("count", "n", 1, "Number of cards to draw, instead of the count argument")
+	drawCmd.Flags().Bool("art", false, "Show the art of the drawn cards, each from the deck it came from")
+	drawCmd.Flags().String("pool", "", "Draw from a subset of the deck: a built-in or custom pool (default full)")
+	drawCmd.Flags().String("from", "", "Draw from a list of your own instead of a pool: favorites (see fav --help)")
+	drawCmd.Flags().Int64("seed", 0, "Seed for the shuffle, for reproducible draws (default random)")
+	addCopyFlag(drawCmd)
+	drawCmd.Flags().Bool("numerology", false, "Sum the card values and show the quintessence card and repeated ranks and suits")
+	addBestEffortFlag(drawCmd)
+}
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/alttext"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/idmap"
+	"github.com/arcanaland/cartomancer/internal/notes"
+	"github.com/arcanaland/cartomancer/internal/sqlite"
+	"github.com/spf13/cobra"
+)
+
+// Formats of the export command
+const (
+	exportCSV    = "csv"
+	exportSQLite = "sqlite"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export card data as CSV or SQLite for spreadsheets and other apps",
+	Long: `Export writes the metadata of every card in a deck in bulk: IDs, numbering,
+names in each language of the deck, alt text, traditional imagery, elemental
+correspondences, art credits and the paths of the card's assets relative to
+the deck directory.
+
+CSV has one row per card, with a name_<language> column for each language
+file in the deck. SQLite databases hold a cards table with the same columns
+(names in the deck's primary language), a card_names table with the name of
+every card in every language, and a deck table describing the deck.
+
+With --notes, a note column holds your own notes on the cards (see note
+--help). With --id-scheme, a column such as tarot_api_id holds each card's ID in
+another app's scheme, for joining the data with that app's datasets.
+
+The format defaults to the extension of the output file (.csv, or .db,
+.sqlite and .sqlite3), and CSV is written to stdout without --output.
+
+Examples:
+  cartomancer export > cards.csv
+  cartomancer export --deck rider-waite-smith -o cards.db
+  cartomancer export --format sqlite -o rws.data`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		schemeName, _ := cmd.Flags().GetString("id-scheme")
+		withNotes, _ := cmd.Flags().GetBool("notes")
+
+		if format == "" {
+			switch strings.ToLower(filepath.Ext(output)) {
+			case ".db", ".sqlite", ".sqlite3":
+				format = exportSQLite
+			default:
+				format = exportCSV
+			}
+		}
+		if format != exportCSV && format != exportSQLite {
+			return fmt.Errorf("unknown format: %s (supported: %s, %s)", format, exportCSV, exportSQLite)
+		}
+		if format == exportSQLite && output == "" {
+			return fmt.Errorf("SQLite exports need an output file (--output)")
+		}
+
+		var scheme *idmap.Scheme
+		if schemeName != "" {
+			var err error
+			if scheme, err = idmap.Get(schemeName); err != nil {
+				return err
+			}
+		}
+
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		names, err := d.LocalizedNames()
+		if err != nil {
+			return fmt.Errorf("error loading card names: %v", err)
+		}
+		var cardNotes map[string]string
+		if withNotes {
+			if cardNotes, err = notes.NewStore(config.GetNotesDir()).ForDeck(d.ID); err != nil {
+				return err
+			}
+		}
+
+		languages := make([]string, 0, len(names))
+		for lang := range names {
+			languages = append(languages, lang)
+		}
+		sort.Strings(languages)
+
+		if format == exportSQLite {
+			if err := exportSQLiteFile(output, d, names, languages, scheme, cardNotes); err != nil {
+				return fmt.Errorf("error writing %s: %v", output, err)
+			}
+			fmt.Fprintf(os.Stderr, "Exported %d cards to %s\n", len(d.Cards()), output)
+			return nil
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("error creating %s: %v", output, err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := exportCSVFile(w, d, names, languages, scheme, cardNotes); err != nil {
+			return fmt.Errorf("error writing CSV: %v", err)
+		}
+		if output != "" {
+			fmt.Fprintf(os.Stderr, "Exported %d cards to %s\n", len(d.Cards()), output)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	exportCmd.Flags().StringP("output", "o", "", "Output file (default: CSV on stdout)")
+	exportCmd.Flags().String("format", "", "Output format: csv or sqlite (default from the output file extension)")
+	exportCmd.Flags().Bool("notes", false, "Add a column with your own notes on the cards")
+	exportCmd.Flags().String("id-scheme", "", "Add a column with each card's ID in another app's scheme: "+strings.Join(idmap.Names(), ", "))
+}
+
+// exportColumns are the card columns shared by the CSV and SQLite exports,
+// with their SQLite column types
+var exportColumns = []struct {
+	name    string
+	sqlType string
+}{
+	{"id", "TEXT NOT NULL"},
+	{"type", "TEXT"},
+	{"number", "TEXT"},
+	{"suit", "TEXT"},
+	{"rank", "TEXT"},
+	{"value", "INTEGER"},
+	{"element", "TEXT"},
+	{"is_court", "INTEGER"},
+	{"name", "TEXT"},
+	{"alt_text", "TEXT"},
+	{"imagery", "TEXT"},
+	{"artist", "TEXT"},
+	{"source", "TEXT"},
+	{"art_license", "TEXT"},
+	{"image", "TEXT"},
+	{"ansi", "TEXT"},
+}
+
+// exportValues returns the values of exportColumns for a card
+func exportValues(d *deck.Deck, c *card.Card) []interface{} {
+	image, ansi := cardAssetPaths(d, c)
+	return []interface{}{
+		c.ID, c.Type, c.Number, c.Suit, c.Rank, int64(c.Value), c.Element, c.IsCourt,
+		c.Name, c.AltText, alttext.Keywords(c),
+		c.Credit.Artist, c.Credit.Source, c.Credit.License,
+		image, ansi,
+	}
+}
+
+// cardAssetPaths returns the paths of a card's art and prebuilt ANSI art
+// relative to the deck directory, or "" when the deck has none
+func cardAssetPaths(d *deck.Deck, c *card.Card) (string, string) {
+	parts := strings.Split(c.ID, ".")
+	var image, ansi string
+	for _, root := range d.AssetRoots() {
+		if image == "" {
+			if path, err := findCardArt(root, parts); err == nil {
+				image = deckRelativePath(d, path)
+			}
+		}
+		if ansi == "" {
+			if path, ok := findPrebuiltAnsi(root, parts); ok {
+				ansi = deckRelativePath(d, path)
+			}
+		}
+	}
+	return image, ansi
+}
+
+// deckRelativePath returns a path relative to the deck directory, with
+// forward slashes on every platform
+func deckRelativePath(d *deck.Deck, path string) string {
+	rel, err := filepath.Rel(d.Path, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// schemeColumn returns the name of the column holding card IDs in a scheme
+func schemeColumn(scheme *idmap.Scheme) string {
+	return strings.ReplaceAll(scheme.Name, "-", "_") + "_id"
+}
+
+// foreignID returns a card's ID in a scheme, or "" for custom cards the
+// scheme has no ID for
+func foreignID(scheme *idmap.Scheme, id string) string {
+	foreign, err := scheme.Foreign(id)
+	if err != nil {
+		return ""
+	}
+	return foreign
+}
+
+// exportCSVFile writes one row per card. The note column is added when notes
+// is not nil.
+func exportCSVFile(w io.Writer, d *deck.Deck, names map[string]map[string]string, languages []string, scheme *idmap.Scheme, notes map[string]string) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, 0, len(exportColumns)+len(languages))
+	for _, col := range exportColumns {
+		header = append(header, col.name)
+	}
+	for _, lang := range languages {
+		header = append(header, "name_"+lang)
+	}
+	if notes != nil {
+		header = append(header, "note")
+	}
+	if scheme != nil {
+		header = append(header, schemeColumn(scheme))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, c := range d.Cards() {
+		record := make([]string, 0, len(header))
+		for _, v := range exportValues(d, c) {
+			switch v := v.(type) {
+			case int64:
+				record = append(record, strconv.FormatInt(v, 10))
+			case bool:
+				record = append(record, strconv.FormatBool(v))
+			default:
+				record = append(record, fmt.Sprint(v))
+			}
+		}
+		for _, lang := range languages {
+			record = append(record, names[lang][c.ID])
+		}
+		if notes != nil {
+			record = append(record, notes[c.ID])
+		}
+		if scheme != nil {
+			record = append(record, foreignID(scheme, c.ID))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportSQLiteFile writes the deck, cards and card_names tables. The note
+// column is added to cards when notes is not nil.
+func exportSQLiteFile(path string, d *deck.Deck, names map[string]map[string]string, languages []string, scheme *idmap.Scheme, notes map[string]string) error {
+	db := sqlite.New()
+
+	deckTable := db.CreateTable("deck",
+		"CREATE TABLE deck (id TEXT, name TEXT, version TEXT, author TEXT, license TEXT, description TEXT)")
+	if err := deckTable.Insert(1, d.ID, d.Name, d.Version, d.Author, d.License, d.Description); err != nil {
+		return err
+	}
+
+	// The position in the deck doubles as the rowid
+	columns := []string{"position INTEGER PRIMARY KEY"}
+	for _, col := range exportColumns {
+		columns = append(columns, col.name+" "+col.sqlType)
+	}
+	if notes != nil {
+		columns = append(columns, "note TEXT")
+	}
+	if scheme != nil {
+		columns = append(columns, schemeColumn(scheme)+" TEXT")
+	}
+	cards := db.CreateTable("cards", "CREATE TABLE cards ("+strings.Join(columns, ", ")+")")
+
+	cardNames := db.CreateTable("card_names", "CREATE TABLE card_names (card_id TEXT NOT NULL, language TEXT NOT NULL, name TEXT)")
+	var nameRow int64
+
+	for _, c := range d.Cards() {
+		values := append([]interface{}{nil}, exportValues(d, c)...)
+		if notes != nil {
+			values = append(values, notes[c.ID])
+		}
+		if scheme != nil {
+			values = append(values, foreignID(scheme, c.ID))
+		}
+		if err := cards.Insert(int64(c.Index), values...); err != nil {
+			return err
+		}
+
+		for _, lang := range languages {
+			if name, ok := names[lang][c.ID]; ok {
+				nameRow++
+				if err := cardNames.Insert(nameRow, c.ID, lang, name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return db.Save(path)
+}
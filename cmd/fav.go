@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/favorites"
+	"github.com/arcanaland/cartomancer/internal/pool"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+// favCmd represents the fav command group
+var favCmd = &cobra.Command{
+	Use:   "fav",
+	Short: "Keep a list of favorite cards",
+	Long: `Fav keeps a list of the cards you want quick access to, such as the ones you
+are studying. Favorites are kept by card ID, so they carry over to every deck,
+and are marked with a star where cards are listed: draws, spreads and the grids
+of show.
+
+Draw from your favorites with 'cartomancer draw --from favorites'.
+
+Examples:
+  cartomancer fav add XVII cups/queen
+  cartomancer fav ls
+  cartomancer fav rm cups/queen
+  cartomancer draw 2 --from favorites`,
+}
+
+// favAddCmd represents the fav add command
+var favAddCmd = &cobra.Command{
+	Use:   "add <card_id...>",
+	Short: "Add cards to your favorites",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := flagDeck(cmd)
+		if err != nil {
+			return err
+		}
+
+		list, err := favorites.Load(config.GetFavoritesPath())
+		if err != nil {
+			return err
+		}
+
+		var cards []*card.Card
+		for _, arg := range args {
+			c, err := d.GetCard(arg)
+			if err != nil {
+				return err
+			}
+			cards = append(cards, c)
+		}
+
+		for _, c := range cards {
+			if list.Add(c.ID) {
+				fmt.Printf("Added %s to favorites\n", c.Name)
+			} else {
+				fmt.Printf("%s is already a favorite\n", c.Name)
+			}
+		}
+		return list.Save(config.GetFavoritesPath())
+	},
+}
+
+// favListCmd represents the fav ls command
+var favListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your favorite cards",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := flagDeck(cmd)
+		if err != nil {
+			return err
+		}
+
+		list, err := favorites.Load(config.GetFavoritesPath())
+		if err != nil {
+			return err
+		}
+		if len(list.Cards) == 0 {
+			fmt.Println("No favorites yet; add some with 'cartomancer fav add <card_id>'")
+			return nil
+		}
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println()
+		for i, id := range list.Cards {
+			if c, err := d.GetCard(id); err == nil {
+				fmt.Printf("  %2d. %s %s\n", i+1, t.Value.Sprint(c.Name), t.Muted.Sprintf("(%s)", c.ID))
+			} else {
+				fmt.Printf("  %2d. %s %s\n", i+1, t.Value.Sprint(id), t.Muted.Sprintf("(not in %s)", d.Name))
+			}
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// favRemoveCmd represents the fav rm command
+var favRemoveCmd = &cobra.Command{
+	Use:   "rm <card_id...>",
+	Short: "Remove cards from your favorites",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := flagDeck(cmd)
+		if err != nil {
+			return err
+		}
+
+		list, err := favorites.Load(config.GetFavoritesPath())
+		if err != nil {
+			return err
+		}
+
+		for _, arg := range args {
+			// Favorites from other decks may not be cards of this one
+			id, name := arg, arg
+			if c, err := d.GetCard(arg); err == nil {
+				id, name = c.ID, c.Name
+			} else if canonical, err := card.ParseID(arg); err == nil {
+				id, name = canonical, canonical
+			}
+
+			if !list.Remove(id) {
+				return fmt.Errorf("%s is not a favorite", name)
+			}
+			fmt.Printf("Removed %s from favorites\n", name)
+		}
+		return list.Save(config.GetFavoritesPath())
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(favCmd)
+	favCmd.AddCommand(favAddCmd)
+	favCmd.AddCommand(favListCmd)
+	favCmd.AddCommand(favRemoveCmd)
+
+	favCmd.PersistentFlags().StringP("deck", "d", "", "Deck used to look up card IDs and names (default from config)")
+}
+
+// flagDeck loads the deck named by the --deck flag or the default deck
+func flagDeck(cmd *cobra.Command) (*deck.Deck, error) {
+	deckFlag, _ := cmd.Flags().GetString("deck")
+	deckPath, err := resolveDeckPath(deckFlag)
+	if err != nil {
+		return nil, err
+	}
+	d, err := deck.LoadDeck(deckPath)
+	if err != nil {
+		return nil, deckLoadError(deckPath, err)
+	}
+	return d, nil
+}
+
+var (
+	favoritesOnce sync.Once
+	favoriteList  *favorites.List
+)
+
+// favoriteMarker returns the favorite symbol, preceded by a space, for cards
+// on the user's favorites list and "" for other cards
+func favoriteMarker(t *theme.Theme, c *card.Card) string {
+	favoritesOnce.Do(func() {
+		favoriteList, _ = favorites.Load(config.GetFavoritesPath())
+	})
+	if favoriteList == nil || !favoriteList.Has(c.ID) {
+		return ""
+	}
+	return " " + t.Heading.Sprint(t.Symbols.Favorite)
+}
+
+// favoritesPool returns a pool of the user's favorite cards, for draw --from
+func favoritesPool() (*pool.Pool, error) {
+	list, err := favorites.Load(config.GetFavoritesPath())
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Cards) == 0 {
+		return nil, fmt.Errorf("no favorites yet; add some with 'cartomancer fav add <card_id>'")
+	}
+	return &pool.Pool{ID: "favorites", Name: "Favorites", Members: list.Cards}, nil
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/correspondence"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/theme"
+)
+
+// displayOptions controls how a card is presented by show
+type displayOptions struct {
+	DeckName  string
+	Numbering string
+	Fields    []string
+	Theme     *theme.Theme
+	Frame     string // Border style for card art: "", auto or a border name
+
+	// License of the deck, shown for cards without a license of their own
+	DeckLicense string
+
+	// Stand-in art for cards with missing images, in best-effort mode
+	Placeholders *placeholders
+
+	// The deck author's interpretations, nil when the deck has none
+	Readings *deck.Readings
+
+	// The user's own notes on the deck's cards, keyed by card ID
+	Notes map[string]string
+}
+
+// defaultFields lists the info panel fields shown when none are configured
+var defaultFields = []string{"name", "deck", "id", "type", "number", "suit", "rank", "description", "upright", "reversed", "note", "warnings"}
+
+// fieldLabels maps each info panel field to its label
+var fieldLabels = map[string]string{
+	"name":            "Card",
+	"deck":            "Deck",
+	"id":              "ID",
+	"type":            "Type",
+	"number":          "No.",
+	"suit":            "Suit",
+	"rank":            "Rank",
+	"element":         "Element",
+	"keywords":        "Keywords",
+	"meanings":        "Meanings",
+	"correspondences": "Correspondences",
+	"description":     "Description",
+	"upright":         "Upright",
+	"reversed":        "Reversed",
+	"note":            "Note",
+	"warnings":        "Warnings",
+	"artist":          "Artist",
+	"source":          "Source",
+	"license":         "License",
+}
+
+// creditFields lists the fields added to the info panel by --credits
+var creditFields = []string{"artist", "source", "license"}
+
+// fieldNames returns the available field names in their default order
+func fieldNames() []string {
+	names := append([]string{}, defaultFields...)
+	names = append(names, "element", "keywords", "meanings", "correspondences")
+	return append(names, creditFields...)
+}
+
+// validateFields checks that every requested field is known
+func validateFields(fields []string) error {
+	for _, field := range fields {
+		if _, ok := fieldLabels[field]; !ok {
+			return fmt.Errorf("unknown field: %s (available: %s)", field, strings.Join(fieldNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// isParagraph reports whether a field holds running text, shown wrapped
+// beneath its label rather than beside it. Values of paragraph fields may
+// hold several lines.
+func isParagraph(field string) bool {
+	return field == "description" || field == "upright" || field == "reversed" || field == "meanings" || field == "note"
+}
+
+// fieldValue returns the value of an info panel field for a card. Fields that
+// do not apply to the card, such as suit for the major arcana, return "".
+func fieldValue(field string, c *card.Card, opts displayOptions) (string, error) {
+	t := opts.Theme
+	isMinor := c.Type == "minor_arcana"
+
+	switch field {
+	case "name":
+		return c.Name, nil
+	case "deck":
+		return opts.DeckName, nil
+	case "id":
+		return c.ID, nil
+	case "type":
+		if isMinor {
+			return fmt.Sprintf("Minor Arcana %s %s", t.Symbols.Separator, t.Symbols.Arcana(true)), nil
+		}
+		return fmt.Sprintf("Major Arcana %s %s", t.Symbols.Separator, t.Symbols.Arcana(false)), nil
+	case "number":
+		if isMinor {
+			return "", nil
+		}
+		return card.FormatNumber(c.Number, opts.Numbering)
+	case "suit":
+		if !isMinor {
+			return "", nil
+		}
+		return fmt.Sprintf("%s %s %s", c.Suit, t.Symbols.Separator, t.Symbols.Suit(c.Suit)), nil
+	case "rank":
+		if !isMinor {
+			return "", nil
+		}
+		return c.Rank, nil
+	case "element":
+		return c.Element, nil
+	case "keywords":
+		meaning, _ := opts.Readings.For(c)
+		return strings.Join(meaning.Keywords, ", "), nil
+	case "meanings":
+		// Both orientations, one to a line
+		meaning, _ := opts.Readings.For(c)
+		var lines []string
+		if meaning.Upright != "" {
+			lines = append(lines, "Upright: "+meaning.Upright)
+		}
+		if meaning.Reversed != "" {
+			lines = append(lines, "Reversed: "+meaning.Reversed)
+		}
+		return strings.Join(lines, "\n"), nil
+	case "correspondences":
+		element := titleCase(correspondence.Element(c))
+		if attribution := correspondence.Attribution(c); attribution != "" && attribution != element {
+			return fmt.Sprintf("%s (%s)", attribution, element), nil
+		}
+		return element, nil
+	case "description":
+		return c.AltText, nil
+	case "upright":
+		meaning, _ := opts.Readings.For(c)
+		return meaning.Upright, nil
+	case "reversed":
+		meaning, _ := opts.Readings.For(c)
+		return meaning.Reversed, nil
+	case "note":
+		return opts.Notes[c.ID], nil
+	case "warnings":
+		return strings.Join(c.ContentWarnings, ", "), nil
+	case "artist":
+		return c.Credit.Artist, nil
+	case "source":
+		return c.Credit.Source, nil
+	case "license":
+		if c.Credit.License != "" {
+			return c.Credit.License, nil
+		}
+		return opts.DeckLicense, nil
+	}
+
+	return "", fmt.Errorf("unknown field: %s", field)
+}
+
+// compactLine renders the selected fields of a card on a single line
+func compactLine(c *card.Card, opts displayOptions) (string, error) {
+	var values []string
+	for _, field := range opts.Fields {
+		value, err := fieldValue(field, c, opts)
+		if err != nil {
+			return "", err
+		}
+		if value != "" {
+			values = append(values, opts.Theme.Value.Sprint(strings.ReplaceAll(value, "\n", " ")))
+		}
+	}
+
+	return strings.Join(values, " "+opts.Theme.Muted.Sprint(opts.Theme.Symbols.Separator)+" "), nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"golang.org/x/term"
+)
+
+// Timing of the card flip animation
+const (
+	flipSteps      = 5                     // Frames for each side of the card
+	flipFrameDelay = 45 * time.Millisecond // Time each frame is shown
+)
+
+// playFlip animates a card turning over from the deck's card back to its face,
+// then erases the animation so the card can be shown as usual. Nothing is
+// drawn when stdout is not a terminal, the card has no image or its art is
+// withheld in safe mode.
+func playFlip(d *deck.Deck, c *card.Card, ph *placeholders) error {
+	if !term.IsTerminal(int(os.Stdout.Fd())) || withholdsArt(c) {
+		return nil
+	}
+
+	face, err := loadViewImage(d.AssetRoots(), c, 0)
+	if err != nil {
+		placeholder, ok := ph.image(c, placeholderWidth, placeholderHeight)
+		if !ok {
+			return nil
+		}
+		face = placeholder
+	}
+	back := deckBackImage(d)
+
+	frames, err := render.FlipFrames(back, face, viewOptions(render.DefaultOptions()), flipSteps)
+	if err != nil {
+		return fmt.Errorf("error rendering flip animation: %v", err)
+	}
+
+	fmt.Print("\033[?25l")
+	defer fmt.Print("\033[?25h")
+	for i, frame := range frames {
+		if i > 0 {
+			fmt.Printf("\033[%dA", len(frame))
+		}
+		fmt.Print(strings.Join(frame, "\n") + "\n")
+		time.Sleep(flipFrameDelay)
+	}
+	fmt.Printf("\033[%dA\033[J", len(frames[len(frames)-1]))
+	return nil
+}
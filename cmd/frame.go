@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+// frameAuto selects the border from the deck style or theme
+const frameAuto = "auto"
+
+// addFrameFlag registers the --frame flag. Passing --frame without a value
+// uses the deck's or theme's border; a border name selects that style.
+func addFrameFlag(cmd *cobra.Command) {
+	cmd.Flags().String("frame", "", "Draw a border with a caption bar around card art: auto or a border style ("+
+		strings.Join(theme.BorderNames(), ", ")+")")
+	cmd.Flags().Lookup("frame").NoOptDefVal = frameAuto
+}
+
+// frameStyle returns the validated --frame value, empty when no frame was requested
+func frameStyle(cmd *cobra.Command) (string, error) {
+	frame, _ := cmd.Flags().GetString("frame")
+	if frame == "" || frame == frameAuto {
+		return frame, nil
+	}
+
+	if _, ok := theme.GetBorder(frame); !ok {
+		return "", fmt.Errorf("unknown frame style: %s (available: %s, %s)",
+			frame, frameAuto, strings.Join(theme.BorderNames(), ", "))
+	}
+	return frame, nil
+}
+
+// frameArt draws a border around ANSI art lines with the caption in a bar
+// below the art. The border is drawn in the theme's label color and the
+// caption in its heading color, truncated to the art width. A style other
+// than auto overrides the theme's border.
+func frameArt(lines []string, caption, style string, t *theme.Theme) []string {
+	t = t.WithBorder(style)
+
+	// Drop the empty line left by a trailing newline
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, visibleWidth(line))
+	}
+	width = max(width, 4)
+
+	b := t.Border
+	rule := strings.Repeat(b.Horizontal, width)
+	side := t.Label.Sprint(b.Vertical)
+
+	framed := make([]string, 0, len(lines)+4)
+	framed = append(framed, t.Label.Sprint(b.TopLeft+rule+b.TopRight))
+	for _, line := range lines {
+		padding := strings.Repeat(" ", width-visibleWidth(line))
+		framed = append(framed, side+line+padding+side)
+	}
+
+	caption = truncateText(caption, width-2)
+	padding := width - 1 - utf8.RuneCountInString(caption)
+	framed = append(framed,
+		t.Label.Sprint(b.TeeLeft+rule+b.TeeRight),
+		side+" "+t.Heading.Sprint(caption)+strings.Repeat(" ", padding)+side,
+		t.Label.Sprint(b.BottomLeft+rule+b.BottomRight))
+
+	return framed
+}
+
+// truncateText shortens text to at most width runes, ending with an ellipsis when cut
+func truncateText(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// visibleWidth returns the number of terminal columns a string occupies,
+// ignoring ANSI escape sequences
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(stripAnsi(s))
+}
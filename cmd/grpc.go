@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/validator"
+	"github.com/arcanaland/cartomancer/pkg/client/cartomancerv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService answers the gRPC API with the same functions as the JSON API,
+// so both describe decks, cards and draws alike
+type grpcService struct {
+	cartomancerv1.UnimplementedCartomancerServer
+	s *server
+}
+
+// newGRPCServer returns a gRPC server for the decks of s, admitting calls
+// with the server's access policy
+func newGRPCServer(s *server) *grpc.Server {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(s.admitGRPC))
+	cartomancerv1.RegisterCartomancerServer(srv, &grpcService{s: s})
+	return srv
+}
+
+// admitGRPC applies the access policy to a call. Tokens are read from the
+// authorization metadata, as "Bearer <token>" like the Authorization header.
+func (s *server) admitGRPC(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token, _ = strings.CutPrefix(values[0], "Bearer ")
+		}
+	}
+	var address string
+	if p, ok := peer.FromContext(ctx); ok {
+		address = p.Addr.String()
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			address = host
+		}
+	}
+
+	if err := s.access.Load().admit(token, address); err != nil {
+		if err.retryAfter > 0 {
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(err.retryAfter.Seconds())))))
+		}
+		return nil, grpcError(err)
+	}
+	return handler(ctx, req)
+}
+
+// grpcError converts an error of the API to a gRPC status with the code
+// matching its HTTP status
+func grpcError(err error) error {
+	code := codes.Internal
+	switch errorStatus(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	}
+	return status.Error(code, err.Error())
+}
+
+func (g *grpcService) ListDecks(ctx context.Context, req *cartomancerv1.ListDecksRequest) (*cartomancerv1.ListDecksResponse, error) {
+	decks, err := g.s.listDecks()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &cartomancerv1.ListDecksResponse{}
+	for _, d := range decks {
+		resp.Decks = append(resp.Decks, protoDeck(d))
+	}
+	return resp, nil
+}
+
+func (g *grpcService) GetDeck(ctx context.Context, req *cartomancerv1.GetDeckRequest) (*cartomancerv1.Deck, error) {
+	d, err := g.s.describeDeck(req.Deck)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return protoDeck(d), nil
+}
+
+func (g *grpcService) GetCard(ctx context.Context, req *cartomancerv1.GetCardRequest) (*cartomancerv1.Card, error) {
+	d, err := g.s.deck(req.Deck)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	c, err := d.GetCard(req.CardId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "card not found: %s", req.CardId)
+	}
+	return protoCard(newAPICard(req.Deck, c)), nil
+}
+
+func (g *grpcService) GetCardImage(ctx context.Context, req *cartomancerv1.GetCardImageRequest) (*cartomancerv1.CardImage, error) {
+	entry, err := g.s.cardImage(req.Deck, req.CardId, int(req.Height))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &cartomancerv1.CardImage{Png: entry.data, Etag: entry.etag}, nil
+}
+
+func (g *grpcService) ListSpreads(ctx context.Context, req *cartomancerv1.ListSpreadsRequest) (*cartomancerv1.ListSpreadsResponse, error) {
+	resp := &cartomancerv1.ListSpreadsResponse{}
+	for _, sp := range listSpreads() {
+		resp.Spreads = append(resp.Spreads, &cartomancerv1.Spread{Id: sp.ID, Name: sp.Name, Positions: sp.Positions})
+	}
+	return resp, nil
+}
+
+func (g *grpcService) Draw(ctx context.Context, req *cartomancerv1.DrawRequest) (*cartomancerv1.DrawResponse, error) {
+	count := int(req.Count)
+	if count == 0 {
+		count = 1
+	}
+	seed := req.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	result, err := g.s.draw(req.Deck, count, req.Pool, req.Spread, seed)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &cartomancerv1.DrawResponse{
+		Deck:   result.Deck,
+		Pool:   result.Pool,
+		Spread: result.Spread,
+		Seed:   result.Seed,
+		Cards:  protoCards(result.Cards),
+	}, nil
+}
+
+func (g *grpcService) Validate(ctx context.Context, req *cartomancerv1.ValidateRequest) (*cartomancerv1.ValidateResponse, error) {
+	d, err := g.s.deck(req.Deck)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	v := validator.NewValidator(d.Path)
+	v.Public = req.Public
+	results, err := v.Validate()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "validation error: %v", err)
+	}
+	return &cartomancerv1.ValidateResponse{
+		Valid:    len(results.Errors) == 0,
+		Errors:   results.Errors,
+		Warnings: results.Warnings,
+	}, nil
+}
+
+// protoDeck converts a deck of the JSON API to its gRPC message
+func protoDeck(d apiDeck) *cartomancerv1.Deck {
+	return &cartomancerv1.Deck{
+		Id:          d.ID,
+		Name:        d.Name,
+		Description: d.Description,
+		Default:     d.Default,
+		CardCount:   int32(d.CardCount),
+		Cards:       protoCards(d.Cards),
+	}
+}
+
+// protoCards converts cards of the JSON API to their gRPC messages
+func protoCards(cards []apiCard) []*cartomancerv1.Card {
+	var out []*cartomancerv1.Card
+	for _, c := range cards {
+		out = append(out, protoCard(c))
+	}
+	return out
+}
+
+// protoCard converts a card of the JSON API to its gRPC message
+func protoCard(c apiCard) *cartomancerv1.Card {
+	return &cartomancerv1.Card{
+		Id:       c.ID,
+		Name:     c.Name,
+		Type:     c.Type,
+		Suit:     c.Suit,
+		AltText:  c.AltText,
+		Image:    c.Image,
+		Position: c.Position,
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/pkg/client"
+	"github.com/arcanaland/cartomancer/pkg/client/cartomancerv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestProtoMirrorsJSONAPI checks that the messages of the gRPC API have the
+// fields of the JSON API, so changes to one are made to the other
+func TestProtoMirrorsJSONAPI(t *testing.T) {
+	tests := []struct {
+		json  interface{}
+		proto proto.Message
+	}{
+		{apiDeck{}, &cartomancerv1.Deck{}},
+		{apiCard{}, &cartomancerv1.Card{}},
+		{apiSpread{}, &cartomancerv1.Spread{}},
+		{apiDraw{}, &cartomancerv1.DrawResponse{}},
+	}
+
+	for _, tt := range tests {
+		var jsonFields []string
+		typ := reflect.TypeOf(tt.json)
+		for i := range typ.NumField() {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			jsonFields = append(jsonFields, name)
+		}
+
+		var protoFields []string
+		fields := tt.proto.ProtoReflect().Descriptor().Fields()
+		for i := range fields.Len() {
+			protoFields = append(protoFields, string(fields.Get(i).Name()))
+		}
+
+		slices.Sort(jsonFields)
+		slices.Sort(protoFields)
+		if !slices.Equal(jsonFields, protoFields) {
+			t.Errorf("%s has fields %v, but %s has %v", typ.Name(), jsonFields,
+				tt.proto.ProtoReflect().Descriptor().Name(), protoFields)
+		}
+	}
+}
+
+// TestGRPCMatchesHTTP checks that both APIs answer alike for the fixture deck
+func TestGRPCMatchesHTTP(t *testing.T) {
+	testLibrary(t)
+
+	s := newServer(1<<20, &accessPolicy{tokens: []string{"s3cret"}})
+	httpSrv := httptest.NewServer(s.routes())
+	defer httpSrv.Close()
+
+	lis := bufconn.Listen(1 << 20)
+	grpcSrv := newGRPCServer(s)
+	go grpcSrv.Serve(lis)
+	defer grpcSrv.Stop()
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+
+	get := func(path string, v interface{}) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, httpSrv.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: %s: %s", path, resp.Status, data)
+		}
+		if s, ok := v.(*[]byte); ok {
+			*s = data
+		} else if err := json.Unmarshal(data, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := client.New("passthrough:///bufnet", "s3cret", dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	var deckJSON apiDeck
+	get("/decks/fixture", &deckJSON)
+	deckGRPC, err := c.GetDeck(ctx, &cartomancerv1.GetDeckRequest{Deck: "fixture"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(deckGRPC, protoDeck(deckJSON)) {
+		t.Errorf("GetDeck = %v, want %v", deckGRPC, protoDeck(deckJSON))
+	}
+
+	var drawJSON apiDraw
+	get("/decks/fixture/draw?spread=three-card&seed=42", &drawJSON)
+	drawGRPC, err := c.Draw(ctx, &cartomancerv1.DrawRequest{Deck: "fixture", Spread: "three-card", Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &cartomancerv1.DrawResponse{Deck: drawJSON.Deck, Pool: drawJSON.Pool, Spread: drawJSON.Spread,
+		Seed: drawJSON.Seed, Cards: protoCards(drawJSON.Cards)}
+	if !proto.Equal(drawGRPC, want) {
+		t.Errorf("Draw = %v, want %v", drawGRPC, want)
+	}
+
+	var imageHTTP []byte
+	get("/decks/fixture/cards/major_arcana.00/image?h=10", &imageHTTP)
+	imageGRPC, err := c.GetCardImage(ctx, &cartomancerv1.GetCardImageRequest{Deck: "fixture", CardId: "major_arcana.00", Height: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(imageGRPC.Png, imageHTTP) {
+		t.Errorf("GetCardImage returned %d bytes, HTTP returned %d", len(imageGRPC.Png), len(imageHTTP))
+	}
+
+	for _, id := range []string{"missing", "../../../private", "fixture/../../../../private", ".."} {
+		if _, err := c.GetDeck(ctx, &cartomancerv1.GetDeckRequest{Deck: id}); status.Code(err) != codes.NotFound {
+			t.Errorf("GetDeck(%q): got %v, want NotFound", id, err)
+		}
+		if _, err := c.Validate(ctx, &cartomancerv1.ValidateRequest{Deck: id}); status.Code(err) != codes.NotFound {
+			t.Errorf("Validate(%q): got %v, want NotFound", id, err)
+		}
+		if _, err := c.GetCardImage(ctx, &cartomancerv1.GetCardImageRequest{Deck: id, CardId: "major_arcana.00"}); status.Code(err) != codes.NotFound {
+			t.Errorf("GetCardImage(%q): got %v, want NotFound", id, err)
+		}
+	}
+
+	anonymous, err := client.New("passthrough:///bufnet", "", dialer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer anonymous.Close()
+	if _, err := anonymous.ListDecks(ctx, &cartomancerv1.ListDecksRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListDecks without a token: got %v, want Unauthenticated", err)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+	"github.com/arcanaland/cartomancer/internal/spread"
+)
+
+// drawPayload is the hook payload describing a draw. Draws from several
+// decks name them all in Decks instead of Deck.
+type drawPayload struct {
+	Spread string          `json:"spread"`
+	Deck   string          `json:"deck,omitempty"`
+	Decks  []string        `json:"decks,omitempty"`
+	Seed   int64           `json:"seed,omitempty"`
+	Cards  []drawnCardData `json:"cards"`
+}
+
+// drawnCardData describes a card in a draw payload. Cards drawn without a
+// spread have no position, and Deck is only set for draws from several decks.
+type drawnCardData struct {
+	Position string `json:"position,omitempty"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Deck     string `json:"deck,omitempty"`
+}
+
+// runHooks runs the commands configured for an event. Hooks for pre-events
+// can abort the command by failing; failures of other hooks are reported as
+// warnings so they never change the outcome of the command itself. Kiosk
+// mode runs no hooks, as they are other programs.
+func runHooks(event string, data interface{}) error {
+	if kiosk {
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+
+	if err := hooks.Validate(cfg.Hooks); err != nil {
+		return err
+	}
+
+	err = hooks.Run(event, cfg.Hooks[event], data)
+	if err != nil && event != hooks.PreValidate {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+
+	return err
+}
+
+// newDrawPayload builds the hook payload for cards dealt into a spread
+func newDrawPayload(spreadID, deckPath string, seed int64, draws []spread.Draw) drawPayload {
+	payload := drawPayload{Spread: spreadID, Deck: deckPath, Seed: seed}
+	for _, draw := range draws {
+		payload.Cards = append(payload.Cards, drawnCardData{
+			Position: draw.Position.Name,
+			ID:       draw.Card.ID,
+			Name:     draw.Card.Name,
+		})
+	}
+	return payload
+}
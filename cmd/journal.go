@@ -0,0 +1,391 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/journal"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+// journalCmd represents the journal command group
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Browse readings recorded with spread --journal",
+}
+
+// journalSearchCmd represents the journal search command
+var journalSearchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search recorded readings",
+	Long: `Search finds journal entries whose question, note, spread or cards contain the
+query, ignoring case. Omit the query to list every entry in the date range.
+
+Examples:
+  cartomancer journal search tower
+  cartomancer journal search "new job" --since 2024-01-01
+  cartomancer journal search --since 2024-03-01 --until 2024-04-01 --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format: %s (supported: text, json)", format)
+		}
+
+		filter := journal.Filter{}
+		if len(args) > 0 {
+			filter.Query = args[0]
+		}
+
+		var err error
+		since, _ := cmd.Flags().GetString("since")
+		if filter.Since, err = parseJournalDate(since); err != nil {
+			return err
+		}
+		until, _ := cmd.Flags().GetString("until")
+		if filter.Until, err = parseJournalDate(until); err != nil {
+			return err
+		}
+		// The until date is inclusive, so stop at the end of that day
+		if !filter.Until.IsZero() {
+			filter.Until = filter.Until.AddDate(0, 0, 1)
+		}
+
+		entries, err := journal.NewStore(config.GetJournalDir()).Search(filter)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			if entries == nil {
+				entries = []*journal.Entry{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(entries)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No matching journal entries.")
+			return nil
+		}
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			printJournalEntry(entry, t)
+		}
+		return nil
+	},
+}
+
+// journalListCmd represents the journal ls command
+var journalListCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List recorded readings with their short IDs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := journal.NewStore(config.GetJournalDir()).List()
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("Your journal is empty. Record readings with 'cartomancer spread --journal'.")
+			return nil
+		}
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			summary := entry.Question
+			if summary == "" {
+				summary = entry.Note
+			}
+			fmt.Printf("%s  %s  %-24s %s\n",
+				t.Label.Sprint(entry.ShortID()),
+				entry.Date.Local().Format("2006-01-02 15:04"),
+				t.Value.Sprint(entry.Spread),
+				t.Muted.Sprint(truncateText(summary, 40)))
+		}
+		return nil
+	},
+}
+
+// journalEditCmd represents the journal edit command
+var journalEditCmd = &cobra.Command{
+	Use:   "edit [id]",
+	Short: "Edit the note of a recorded reading in $EDITOR",
+	Long: `Edit opens the note of a journal entry in $VISUAL or $EDITOR (falling back to vi)
+and saves the result. The entry is identified by its short ID from 'journal ls'
+or any unique prefix of its full ID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := journal.NewStore(config.GetJournalDir())
+		entry, err := store.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		note, err := editText(entry.Note)
+		if err != nil {
+			return err
+		}
+		if note == entry.Note {
+			fmt.Println("Note unchanged.")
+			return nil
+		}
+
+		entry.Note = note
+		if err := store.Save(entry); err != nil {
+			return err
+		}
+		fmt.Printf("Updated note for %s\n", entry.ShortID())
+		return nil
+	},
+}
+
+// journalRemoveCmd represents the journal rm command
+var journalRemoveCmd = &cobra.Command{
+	Use:   "rm [id]",
+	Short: "Delete a recorded reading",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := journal.NewStore(config.GetJournalDir())
+		entry, err := store.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+			fmt.Printf("Delete %s (%s, %s)? [y/N] ",
+				entry.ShortID(), entry.Date.Local().Format("2006-01-02 15:04"), entry.Spread)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Println("Aborted.")
+				return nil
+			}
+		}
+
+		if err := store.Delete(entry.ID); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %s\n", entry.ShortID())
+		return nil
+	},
+}
+
+// journalImportCmd represents the journal import command
+var journalImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import reading history exported by other tarot apps",
+	Long: `Import adds readings exported by other apps to your journal, so you keep your
+history when moving to cartomancer. Card names such as "The Tower", "Ace of
+Coins" or "Three of Cups (Reversed)" are mapped to canonical card IDs.
+
+Formats:
+  labyrinthos     Labyrinthos reading history CSV
+  golden-thread   Golden Thread Tarot journal CSV
+  csv             Any CSV with a date column and either a cards column or
+                  numbered "Card 1", "Card 2" columns; question, spread,
+                  notes, positions, deck and numbered "Position 1" and
+                  "Reversed 1" columns are read when present
+  json            An array of readings:
+                  [{"date": "2024-03-01T09:30:00Z", "question": "...",
+                    "note": "...", "spread": "Past, Present, Future",
+                    "deck": "...", "cards": [{"position": "Past",
+                    "card": "The Tower", "reversed": true}]}]
+
+The format defaults to json for .json files and csv otherwise. Readings that
+are already in the journal are skipped, so importing the same file twice is
+safe.
+
+Examples:
+  cartomancer journal import readings.csv --format golden-thread
+  cartomancer journal import history.json --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if format == "" {
+			format = journal.FormatCSV
+			if strings.EqualFold(filepath.Ext(args[0]), ".json") {
+				format = journal.FormatJSON
+			}
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("error opening %s: %v", args[0], err)
+		}
+		defer file.Close()
+
+		entries, problems, err := journal.Import(file, format)
+		if err != nil {
+			return err
+		}
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "Warning: skipped %v\n", problem)
+		}
+
+		store := journal.NewStore(config.GetJournalDir())
+		imported, existing := 0, 0
+		for _, entry := range entries {
+			if store.Exists(entry.ID) {
+				existing++
+				continue
+			}
+			if !dryRun {
+				if err := store.Save(entry); err != nil {
+					return err
+				}
+			}
+			imported++
+		}
+
+		verb := "Imported"
+		if dryRun {
+			verb = "Would import"
+		}
+		fmt.Printf("%s %d readings", verb, imported)
+		if existing > 0 {
+			fmt.Printf(", %d already in the journal", existing)
+		}
+		if len(problems) > 0 {
+			fmt.Printf(", %d skipped", len(problems))
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// editText opens text in the user's editor and returns the edited text
+// without its trailing newline
+func editText(text string) (string, error) {
+	file, err := os.CreateTemp("", "cartomancer-note-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text + "\n"); err != nil {
+		file.Close()
+		return "", fmt.Errorf("error writing temporary file: %v", err)
+	}
+	file.Close()
+
+	if err := runEditor(file.Name()); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("error reading edited note: %v", err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// runEditor opens a file in the user's editor, $VISUAL or $EDITOR (falling
+// back to vi), and waits for it to exit
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor setting may include arguments, such as "code --wait"
+	parts := strings.Fields(editor)
+	editCmd := exec.Command(parts[0], append(parts[1:], path)...)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("error running editor %s: %v", editor, err)
+	}
+	return nil
+}
+
+// parseJournalDate parses a YYYY-MM-DD date in local time; an empty string yields the zero time
+func parseJournalDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", s)
+	}
+	return date, nil
+}
+
+// printJournalEntry prints a journal entry as a short block of text
+func printJournalEntry(entry *journal.Entry, t *theme.Theme) {
+	line := t.Label.Sprint(entry.ShortID()) + "  " +
+		t.Heading.Sprint(entry.Date.Local().Format("2006-01-02 15:04")) + "  " +
+		t.Value.Sprint(entry.Spread)
+	if entry.Deck != "" {
+		line += t.Muted.Sprintf("  (%s)", entry.Deck)
+	}
+	fmt.Println(line)
+
+	if entry.Question != "" {
+		fmt.Println("  " + t.Label.Sprint("Question: ") + entry.Question)
+	}
+	if timing := timingLine(entry.Moon, entry.SunSign); timing != "" {
+		fmt.Println("  " + t.Label.Sprint("Timing:   ") + timing)
+	}
+
+	cards := make([]string, 0, len(entry.Cards))
+	for _, c := range entry.Cards {
+		name := c.Name
+		if c.Reversed {
+			name += " (reversed)"
+		}
+		for _, clarifier := range c.Clarifiers {
+			name += " (clarified by " + clarifier.Name + ")"
+		}
+		cards = append(cards, fmt.Sprintf("%s: %s", c.Position, name))
+	}
+	fmt.Println("  " + t.Label.Sprint("Cards:    ") + strings.Join(cards, ", "))
+
+	if len(entry.Patterns) > 0 {
+		fmt.Println("  " + t.Label.Sprint("Patterns: ") + strings.Join(entry.Patterns, "; "))
+	}
+	if entry.Note != "" {
+		fmt.Println("  " + t.Label.Sprint("Note:     ") + entry.Note)
+	}
+	fmt.Println()
+}
+
+func init() {
+	RootCmd.AddCommand(journalCmd)
+	journalCmd.AddCommand(journalListCmd)
+	journalCmd.AddCommand(journalSearchCmd)
+	journalCmd.AddCommand(journalEditCmd)
+	journalCmd.AddCommand(journalRemoveCmd)
+	journalCmd.AddCommand(journalImportCmd)
+
+	journalRemoveCmd.Flags().BoolP("yes", "y", false, "Delete without asking for confirmation")
+
+	journalSearchCmd.Flags().String("since", "", "Only include entries on or after this date (YYYY-MM-DD)")
+	journalSearchCmd.Flags().String("until", "", "Only include entries on or before this date (YYYY-MM-DD)")
+	journalSearchCmd.Flags().String("format", "text", "Output format: text or json")
+
+	journalImportCmd.Flags().String("format", "", "Import format: "+strings.Join(journal.ImportFormats, ", ")+" (default from the file extension)")
+	journalImportCmd.Flags().Bool("dry-run", false, "Report what would be imported without changing the journal")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// kioskCommands are the commands available in kiosk mode: browsing the cards
+// and drawing them
+var kioskCommands = map[string]bool{
+	"show":   true,
+	"draw":   true,
+	"spread": true,
+	"daily":  true,
+	"combo":  true,
+	"yesno":  true,
+	"altar":  true,
+	"help":   true,
+}
+
+// kioskFlags are the flags of kiosk commands that read or write files, record
+// readings or run other programs, which kiosk mode refuses
+var kioskFlags = []string{"journal", "record", "export-animation", "export-image", "copy", "template"}
+
+// kiosk is whether kiosk mode is on, set by checkKiosk before any command runs
+var kiosk bool
+
+// checkKiosk turns on kiosk mode when --kiosk or kiosk in config.toml asks
+// for it, and then refuses commands and flags that kiosk mode disables:
+// everything but browsing and drawing cards, so journaling, changes to the
+// config and deck library, editors, plugins and other programs are out of
+// reach. The flag cannot turn off kiosk mode set in config.toml.
+func checkKiosk(cmd *cobra.Command) error {
+	kiosk, _ = cmd.Flags().GetBool("kiosk")
+	if cfg, err := config.LoadConfig(); err == nil && cfg.Kiosk {
+		kiosk = true
+	}
+	if !kiosk || cmd == cmd.Root() {
+		return nil
+	}
+
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if !kioskCommands[name] {
+		return fmt.Errorf("%s is disabled in kiosk mode", name)
+	}
+	for _, flag := range kioskFlags {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s is disabled in kiosk mode", flag)
+		}
+	}
+	return nil
+}
+
+// checkKioskDeck refuses, in kiosk mode, a deck reference that resolves to a
+// path on the filesystem rather than a deck in the library, so --deck cannot
+// be used to read files outside of it
+func checkKioskDeck(deckName string) error {
+	if !kiosk {
+		return nil
+	}
+	res, err := config.ResolveDeck(deckName)
+	if err != nil {
+		return err
+	}
+	if res.Source == "path" || filepath.Base(deckName) != deckName || deckName == ".." {
+		return fmt.Errorf("deck %q is not in the deck library; only library decks can be used in kiosk mode", deckName)
+	}
+	return nil
+}
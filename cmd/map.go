@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/arcanaland/cartomancer/internal/idmap"
+	"github.com/spf13/cobra"
+)
+
+// mapCmd represents the map command
+var mapCmd = &cobra.Command{
+	Use:   "map [id...]",
+	Short: "Translate card identifiers of other tarot apps to canonical IDs",
+	Long: `Map translates card identifiers used by other apps, APIs and datasets, such as
+deck indexes 0-77, name slugs or tarot-api short codes, to canonical card IDs.
+With --to, canonical IDs (or identifiers in --scheme) are translated to another
+scheme instead. List the schemes with --list.
+
+Examples:
+  cartomancer map 48
+  cartomancer map queen-of-cups-meaning-tarot-card-meanings --scheme labyrinthos
+  cartomancer map "Princess of Disks" cuqu
+  cartomancer map major_arcana.17 --to tarot-api`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scheme, _ := cmd.Flags().GetString("scheme")
+		to, _ := cmd.Flags().GetString("to")
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SCHEME\tQUEEN OF CUPS\tDESCRIPTION")
+			for _, s := range idmap.Schemes() {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, s.Example, s.Description)
+			}
+			return w.Flush()
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("no identifiers given")
+		}
+
+		var target *idmap.Scheme
+		if to != "" {
+			var err error
+			if target, err = idmap.Get(to); err != nil {
+				return err
+			}
+		}
+
+		var failed []string
+		for _, foreign := range args {
+			id, err := idmap.Canonical(foreign, scheme)
+			if err == nil && target != nil {
+				id, err = target.Foreign(id)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				failed = append(failed, foreign)
+				continue
+			}
+
+			if len(args) == 1 {
+				fmt.Println(id)
+			} else {
+				fmt.Printf("%s\t%s\n", foreign, id)
+			}
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("could not map: %s", strings.Join(failed, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(mapCmd)
+
+	mapCmd.Flags().String("scheme", idmap.Auto, "Scheme of the identifiers: auto or "+strings.Join(idmap.Names(), ", "))
+	mapCmd.Flags().String("to", "", "Translate to this scheme instead of canonical IDs")
+	mapCmd.Flags().Bool("list", false, "List the supported schemes")
+}
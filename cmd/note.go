@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/notes"
+	"github.com/spf13/cobra"
+)
+
+// noteCmd represents the note command
+var noteCmd = &cobra.Command{
+	Use:   "note [card_id] [text...]",
+	Short: "Keep personal study notes on cards",
+	Long: `Note attaches your own study notes to cards. Notes are global by default and
+shown for the card in every deck; with --this-deck the note is kept for the
+deck alone and takes the place of the global note when showing its cards.
+
+Give the text after the card to replace the note, or use --edit to write it in
+$VISUAL or $EDITOR (falling back to vi). Without text the card's notes are
+printed, and without a card every card with a note in the deck is listed.
+
+Notes appear in show's info panel beneath the deck author's meanings (the note
+field), and export --notes adds them to CSV and SQLite exports.
+
+Examples:
+  cartomancer note XVII "Hope after the storm; the first card I ever drew"
+  cartomancer note XVII --edit
+  cartomancer note XVII --deck thoth --this-deck "Nuit pouring the waters"
+  cartomancer note XVII --clear
+  cartomancer note`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		edit, _ := cmd.Flags().GetBool("edit")
+		clear, _ := cmd.Flags().GetBool("clear")
+		thisDeck, _ := cmd.Flags().GetBool("this-deck")
+
+		d, err := flagDeck(cmd)
+		if err != nil {
+			return err
+		}
+		store := notes.NewStore(config.GetNotesDir())
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			if edit || clear {
+				return fmt.Errorf("name the card whose note to change")
+			}
+			all, err := store.ForDeck(d.ID)
+			if err != nil {
+				return err
+			}
+			if len(all) == 0 {
+				fmt.Println("No notes yet; add one with 'cartomancer note <card_id> <text>'")
+				return nil
+			}
+			fmt.Println()
+			for _, c := range d.Cards() {
+				if note, ok := all[c.ID]; ok {
+					fmt.Println("  " + t.Label.Sprint(c.Name))
+					for _, line := range wrapText(note, 72) {
+						fmt.Println("    " + t.Value.Sprint(line))
+					}
+				}
+			}
+			fmt.Println()
+			return nil
+		}
+
+		c, err := d.GetCard(args[0])
+		if err != nil {
+			return err
+		}
+		text := strings.Join(args[1:], " ")
+
+		scope := ""
+		if thisDeck {
+			scope = d.ID
+		}
+		book, err := store.Load(scope)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case clear:
+			if text != "" || edit {
+				return fmt.Errorf("--clear takes no text")
+			}
+			if _, ok := book.Cards[c.ID]; !ok {
+				return fmt.Errorf("%s has no %s", c.Name, noteScope(thisDeck, d.Name))
+			}
+			book.Set(c.ID, "")
+		case edit:
+			if text != "" {
+				return fmt.Errorf("give the text or use --edit, not both")
+			}
+			if text, err = editText(book.Cards[c.ID]); err != nil {
+				return err
+			}
+			if strings.TrimSpace(text) == book.Cards[c.ID] {
+				fmt.Println("Note unchanged.")
+				return nil
+			}
+			book.Set(c.ID, text)
+		case text != "":
+			book.Set(c.ID, text)
+		default:
+			// Print the card's notes in both scopes
+			global, err := store.Load("")
+			if err != nil {
+				return err
+			}
+			own, err := store.Load(d.ID)
+			if err != nil {
+				return err
+			}
+			if global.Cards[c.ID] == "" && own.Cards[c.ID] == "" {
+				fmt.Printf("%s has no notes\n", c.Name)
+				return nil
+			}
+			fmt.Println()
+			for _, note := range []struct{ label, text string }{
+				{"Note", global.Cards[c.ID]},
+				{"Note for " + d.Name, own.Cards[c.ID]},
+			} {
+				if note.text == "" {
+					continue
+				}
+				fmt.Println("  " + t.Label.Sprintf("%s:", note.label))
+				for _, line := range wrapText(note.text, 72) {
+					fmt.Println("    " + t.Value.Sprint(line))
+				}
+			}
+			fmt.Println()
+			return nil
+		}
+
+		if err := store.Save(scope, book); err != nil {
+			return err
+		}
+		if clear {
+			fmt.Printf("Removed the %s on %s\n", noteScope(thisDeck, d.Name), c.Name)
+		} else {
+			fmt.Printf("Saved the %s on %s\n", noteScope(thisDeck, d.Name), c.Name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(noteCmd)
+
+	noteCmd.Flags().StringP("deck", "d", "", "Deck used to look up the card, and whose notes --this-deck changes (default from config)")
+	noteCmd.Flags().Bool("this-deck", false, "Keep the note for this deck only instead of every deck")
+	noteCmd.Flags().BoolP("edit", "e", false, "Write the note in $EDITOR")
+	noteCmd.Flags().Bool("clear", false, "Remove the note")
+}
+
+// noteScope describes which notebook a note is kept in
+func noteScope(thisDeck bool, deckName string) string {
+	if thisDeck {
+		return "note for " + deckName
+	}
+	return "note"
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// openAPIVersion is the version of the HTTP API described by the OpenAPI
+// document, raised when endpoints or their responses change
+const openAPIVersion = "1.0.0"
+
+// genCmd represents the gen command
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate files describing cartomancer's interfaces",
+	Long:  `Commands that write out descriptions of cartomancer's interfaces for other tools.`,
+}
+
+// genOpenAPICmd represents the gen openapi command
+var genOpenAPICmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Write the OpenAPI document of the serve HTTP API",
+	Long: `Openapi writes an OpenAPI 3 document describing the HTTP API of the serve
+command: the deck, card image, draw and spread endpoints, their parameters and
+the JSON they answer with. Client SDKs can be generated from it with any
+OpenAPI generator. A running server also serves it at /openapi.json.
+
+Examples:
+  cartomancer gen openapi -o openapi.json
+  cartomancer gen openapi | jq '.paths | keys'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := json.MarshalIndent(openAPIDocument(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding OpenAPI document: %v", err)
+		}
+		data = append(data, '\n')
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return fmt.Errorf("error writing OpenAPI document: %v", err)
+		}
+		fmt.Printf("OpenAPI document saved to %s\n", output)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(genCmd)
+	genCmd.AddCommand(genOpenAPICmd)
+
+	genOpenAPICmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+}
+
+// handleOpenAPI writes the server's OpenAPI document
+func (s *server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, openAPIDocument())
+}
+
+// object is a JSON object of the OpenAPI document
+type object = map[string]interface{}
+
+// openAPIDocument describes the serve HTTP API as an OpenAPI 3 document. The
+// response schemas are derived from the types the handlers encode, so they
+// follow changes to them.
+func openAPIDocument() object {
+	deckID := pathParameter("id", "Deck library directory name or deck ID")
+	failures := func(codes ...string) object {
+		responses := object{}
+		for _, code := range codes {
+			responses[code] = object{
+				"description": http.StatusText(statusCode(code)),
+				"content":     object{"text/plain": object{"schema": object{"type": "string"}}},
+			}
+		}
+		return responses
+	}
+	jsonResponse := func(description string, schema object, failures object) object {
+		return mergeResponses(object{"200": object{
+			"description": description,
+			"content":     object{"application/json": object{"schema": schema}},
+		}}, failures)
+	}
+
+	schemas := object{}
+	for t, name := range apiSchemaNames {
+		schemas[name] = schemaOf(t)
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":       "Cartomancer",
+			"version":     openAPIVersion,
+			"description": "Decks of the deck library served by cartomancer serve.",
+		},
+		"paths": object{
+			"/decks": object{"get": object{
+				"operationId": "listDecks",
+				"summary":     "List the decks in the deck library",
+				"responses":   jsonResponse("The decks", arrayOf(schemaRef("Deck")), failures("401", "429", "500")),
+			}},
+			"/decks/{id}": object{"get": object{
+				"operationId": "getDeck",
+				"summary":     "Describe a deck and its cards",
+				"parameters":  []object{deckID},
+				"responses":   jsonResponse("The deck with its cards", schemaRef("Deck"), failures("401", "404", "429", "500")),
+			}},
+			"/decks/{id}/draw": object{"get": object{
+				"operationId": "draw",
+				"summary":     "Shuffle a deck and draw cards or deal a spread",
+				"description": "Draws are not stored, so the same seed gives the same draw.",
+				"parameters": []object{
+					deckID,
+					queryParameter("count", "Cards to draw, ignored with spread", object{"type": "integer", "minimum": 1, "maximum": maxDrawCount, "default": 1}),
+					queryParameter("pool", "Pool of the deck's cards to draw from, e.g. majors", object{"type": "string", "default": "full"}),
+					queryParameter("spread", "Spread to deal, as listed by /spreads", object{"type": "string"}),
+					queryParameter("seed", "Seed of the shuffle, random by default", object{"type": "integer", "format": "int64"}),
+				},
+				"responses": jsonResponse("The cards drawn", schemaRef("Draw"), failures("400", "401", "404", "429", "500")),
+			}},
+			"/decks/{id}/cards/{card_id}/image": object{"get": object{
+				"operationId": "getCardImage",
+				"summary":     "Get a card image as PNG",
+				"parameters": []object{
+					deckID,
+					pathParameter("card_id", "Card ID in any notation cartomancer accepts, e.g. major_arcana.00"),
+					queryParameter("h", "Height to resize the image to, never upscaled", object{"type": "integer", "minimum": 1, "maximum": maxImageHeight}),
+				},
+				"responses": mergeResponses(object{
+					"200": object{
+						"description": "The card image",
+						"content":     object{"image/png": object{"schema": object{"type": "string", "format": "binary"}}},
+					},
+					"304": object{"description": "The image matches If-None-Match"},
+				}, failures("400", "401", "404", "429", "500")),
+			}},
+			"/decks/{id}/sessions/{session_id}": object{"get": object{
+				"operationId": "joinSession",
+				"summary":     "Join a live reading session over a WebSocket",
+				"description": "See cartomancer serve --help for the messages exchanged.",
+				"parameters": []object{
+					deckID,
+					pathParameter("session_id", "Session to join or start"),
+					queryParameter("name", "Participant name shown to the others", object{"type": "string", "default": "guest", "maxLength": session.MaxNameLength}),
+				},
+				"responses": mergeResponses(object{
+					"101": object{"description": "Switched to the WebSocket protocol"},
+				}, failures("401", "403", "404", "429")),
+				// Browsers cannot set headers on WebSocket requests
+				"security": []object{{"bearer": []string{}}, {"token": []string{}}, {}},
+			}},
+			"/spreads": object{"get": object{
+				"operationId": "listSpreads",
+				"summary":     "List the built-in and custom spreads",
+				"responses":   jsonResponse("The spreads", arrayOf(schemaRef("Spread")), failures("401", "429")),
+			}},
+			"/metrics": object{"get": object{
+				"operationId": "getMetrics",
+				"summary":     "Server metrics in the Prometheus text format",
+				"responses": mergeResponses(object{
+					"200": object{
+						"description": "The metrics",
+						"content":     object{"text/plain": object{"schema": object{"type": "string"}}},
+					},
+				}, failures("401", "429")),
+			}},
+			"/openapi.json": object{"get": object{
+				"operationId": "getOpenAPI",
+				"summary":     "This OpenAPI document",
+				"responses":   jsonResponse("The OpenAPI document", object{"type": "object"}, failures("401", "429")),
+			}},
+		},
+		"components": object{
+			"schemas": schemas,
+			"securitySchemes": object{
+				"bearer": object{"type": "http", "scheme": "bearer"},
+				"token":  object{"type": "apiKey", "in": "query", "name": "token"},
+			},
+		},
+		// Tokens are only required when the server is configured with them
+		"security": []object{{"bearer": []string{}}, {}},
+	}
+}
+
+// apiSchemaNames names the API types that have a schema of their own, so
+// that other schemas refer to them
+var apiSchemaNames = map[reflect.Type]string{
+	reflect.TypeOf(apiDeck{}):   "Deck",
+	reflect.TypeOf(apiCard{}):   "Card",
+	reflect.TypeOf(apiSpread{}): "Spread",
+	reflect.TypeOf(apiDraw{}):   "Draw",
+}
+
+// schemaOf derives the JSON schema of a struct from its fields and their json
+// tags. Fields without omitempty are required.
+func schemaOf(t reflect.Type) object {
+	properties := object{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// typeSchema returns the JSON schema of a Go type
+func typeSchema(t reflect.Type) object {
+	if name, ok := apiSchemaNames[t]; ok {
+		return schemaRef(name)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int32:
+		return object{"type": "integer"}
+	case reflect.Int64:
+		return object{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	case reflect.Slice:
+		return arrayOf(typeSchema(t.Elem()))
+	case reflect.Struct:
+		return schemaOf(t)
+	}
+	return object{}
+}
+
+// schemaRef refers to a schema of the document's components
+func schemaRef(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+// arrayOf returns the schema of an array of items
+func arrayOf(items object) object {
+	return object{"type": "array", "items": items}
+}
+
+// pathParameter describes a required string path parameter
+func pathParameter(name, description string) object {
+	return object{"name": name, "in": "path", "required": true, "description": description, "schema": object{"type": "string"}}
+}
+
+// queryParameter describes an optional query parameter
+func queryParameter(name, description string, schema object) object {
+	return object{"name": name, "in": "query", "description": description, "schema": schema}
+}
+
+// mergeResponses adds the responses of more to responses
+func mergeResponses(responses, more object) object {
+	for code, response := range more {
+		responses[code] = response
+	}
+	return responses
+}
+
+// statusCode parses an HTTP status code of the document's responses
+func statusCode(code string) int {
+	status, _ := strconv.Atoi(code)
+	return status
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"golang.org/x/term"
+)
+
+// picker lets the user choose a card from a deck by typing part of its name
+type picker struct {
+	items    []pickerItem
+	matches  []int // Indexes of the items matching the query, in deck order
+	query    []rune
+	selected int // Index into matches
+	theme    *theme.Theme
+}
+
+// pickerItem is a card with the names it can be found by
+type pickerItem struct {
+	card  *card.Card
+	group string
+	names []string // Lowercase deck, localized and default names and the ID
+}
+
+// newPicker creates a picker over the cards of a deck, searchable by every
+// name the deck's language files give them
+func newPicker(d *deck.Deck, t *theme.Theme) *picker {
+	localized, _ := d.LocalizedNames()
+
+	p := &picker{theme: t}
+	for _, c := range d.Cards() {
+		item := pickerItem{card: c, group: pickerGroup(c)}
+		seen := map[string]bool{}
+		add := func(name string) {
+			name = strings.ToLower(name)
+			if name != "" && !seen[name] {
+				seen[name] = true
+				item.names = append(item.names, name)
+			}
+		}
+		add(c.Name)
+		add(card.DefaultName(c.ID))
+		for _, names := range localized {
+			add(names[c.ID])
+		}
+		add(c.ID)
+		p.items = append(p.items, item)
+	}
+	p.filter()
+	return p
+}
+
+// pickerGroup returns the heading a card is listed under
+func pickerGroup(c *card.Card) string {
+	if c.Suit == "" {
+		return "Major Arcana"
+	}
+	return titleCase(c.Suit)
+}
+
+// titleCase capitalizes the first letter of a word
+func titleCase(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// pickCard opens the picker and returns the chosen card, or nil if the user
+// cancelled. Keys: type to filter, arrows or Ctrl-P/Ctrl-N move, Enter
+// chooses and Esc or Ctrl-C cancels.
+func pickCard(d *deck.Deck, t *theme.Theme) (*card.Card, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("picking a card requires a terminal; pass a card ID instead")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("error entering raw mode: %v", err)
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Print("\033[?1049h")
+	defer fmt.Print("\033[?1049l")
+
+	p := newPicker(d, t)
+	buf := make([]byte, 64)
+	for {
+		if err := p.draw(); err != nil {
+			return nil, err
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, nil
+		}
+
+		switch key := string(buf[:n]); key {
+		case "\033", "\003":
+			return nil, nil
+		case "\r", "\n":
+			if len(p.matches) > 0 {
+				return p.items[p.matches[p.selected]].card, nil
+			}
+		case "\033[A", "\020":
+			p.selected = max(p.selected-1, 0)
+		case "\033[B", "\016":
+			p.selected = min(p.selected+1, max(len(p.matches)-1, 0))
+		case "\177", "\b":
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case "\025": // Ctrl-U clears the query
+			p.query = nil
+			p.filter()
+		default:
+			if strings.HasPrefix(key, "\033") {
+				continue
+			}
+			for _, r := range key {
+				if unicode.IsPrint(r) {
+					p.query = append(p.query, r)
+				}
+			}
+			p.filter()
+		}
+	}
+}
+
+// filter keeps the items matching every word of the query and resets the selection
+func (p *picker) filter() {
+	words := strings.Fields(strings.ToLower(string(p.query)))
+	p.matches = p.matches[:0]
+	for i, item := range p.items {
+		if item.matches(words) {
+			p.matches = append(p.matches, i)
+		}
+	}
+	p.selected = 0
+}
+
+// matches reports whether one of the item's names matches every word of the
+// query, so "q cups" and "qcups" both find the Queen of Cups
+func (item pickerItem) matches(words []string) bool {
+	for _, name := range item.names {
+		all := true
+		for _, word := range words {
+			if !fuzzyMatch(word, name) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyMatch reports whether the runes of word appear in order in s, starting
+// at the beginning of one of its words
+func fuzzyMatch(word, s string) bool {
+	first, size := utf8.DecodeRuneInString(word)
+	start := true
+	for i, r := range s {
+		if start && r == first && isSubsequence(word[size:], s[i+size:]) {
+			return true
+		}
+		start = !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+	return false
+}
+
+// isSubsequence reports whether the runes of sub appear in s in order
+func isSubsequence(sub, s string) bool {
+	for _, r := range sub {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// pickerLine is a row of the list: a group heading or a matching item
+type pickerLine struct {
+	heading string
+	match   int // Index into matches, for item rows
+}
+
+// draw lists the matching cards under their group headings, scrolled to keep
+// the selection visible, below the query prompt
+func (p *picker) draw() error {
+	_, rows, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || rows <= 2 {
+		rows = 24
+	}
+	height := rows - 2 // Prompt and status lines
+
+	var lines []pickerLine
+	selectedLine, group := 0, ""
+	for m, i := range p.matches {
+		if item := p.items[i]; item.group != group {
+			group = item.group
+			lines = append(lines, pickerLine{heading: group})
+		}
+		if m == p.selected {
+			selectedLine = len(lines)
+		}
+		lines = append(lines, pickerLine{match: m})
+	}
+
+	// Scroll so the selection stays in view
+	top := 0
+	if selectedLine >= height {
+		top = selectedLine - height + 1
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("\033[H\033[2J")
+	out.WriteString(p.theme.Label.Sprint("> ") + string(p.query) + "\r\n")
+	for _, line := range lines[top:min(top+height, len(lines))] {
+		if line.heading != "" {
+			out.WriteString(p.theme.Heading.Sprint(line.heading) + "\r\n")
+			continue
+		}
+		c := p.items[p.matches[line.match]].card
+		if line.match == p.selected {
+			out.WriteString(p.theme.Value.Sprint("› "+c.Name) + p.theme.Muted.Sprintf("  %s", c.ID) + "\r\n")
+		} else {
+			out.WriteString("  " + c.Name + "\r\n")
+		}
+	}
+	out.WriteString(fmt.Sprintf("\033[%d;1H", rows))
+	out.WriteString(p.theme.Muted.Sprintf("%d/%d cards  type to search  arrows move  Enter choose  Esc cancel",
+		len(p.matches), len(p.items)))
+	out.WriteString(fmt.Sprintf("\033[1;%dH", 3+len(p.query))) // Cursor after the query
+	return out.Flush()
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/composite"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+// placeholderWidth and placeholderHeight size the placeholder art when there is
+// no other card image to match
+const (
+	placeholderWidth  = 300
+	placeholderHeight = 525
+)
+
+// suitColors are the placeholder block colors for each suit, after its element
+var suitColors = map[string]color.RGBA{
+	"wands":     {R: 196, G: 84, B: 44, A: 255},
+	"cups":      {R: 52, G: 110, B: 186, A: 255},
+	"swords":    {R: 150, G: 160, B: 176, A: 255},
+	"pentacles": {R: 70, G: 140, B: 78, A: 255},
+}
+
+// majorArcanaColor is the placeholder block color for the major arcana
+var majorArcanaColor = color.RGBA{R: 184, G: 148, B: 60, A: 255}
+
+// placeholders stands in generated art for cards whose images are missing when
+// best-effort mode is on, and remembers which cards it substituted
+type placeholders struct {
+	enabled bool
+	light   bool // Draw pale art for a light terminal background
+	cards   []*card.Card
+}
+
+// addBestEffortFlag registers the --best-effort flag on a command
+func addBestEffortFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("best-effort", false, "Draw placeholder art for cards with missing images instead of failing")
+}
+
+// newPlaceholders returns the placeholders for a command, enabled by its
+// --best-effort flag, drawn for the terminal's background
+func newPlaceholders(cmd *cobra.Command) *placeholders {
+	enabled, _ := cmd.Flags().GetBool("best-effort")
+	return &placeholders{enabled: enabled, light: enabled && terminalBackground() == theme.BackgroundLight}
+}
+
+// image returns placeholder art for a card, or false when best-effort mode is off
+func (p *placeholders) image(c *card.Card, width, height int) (image.Image, bool) {
+	if p == nil || !p.enabled {
+		return nil, false
+	}
+
+	p.record(c)
+
+	accent, ok := suitColors[c.Suit]
+	if !ok {
+		accent = majorArcanaColor
+	}
+	return placeholderArt(c, accent, p.light).Draw(width, height), true
+}
+
+// placeholderArt describes generated art for a card in the given accent
+// color, pale for a light background when light is set
+func placeholderArt(c *card.Card, accent color.Color, light bool) composite.Placeholder {
+	layout := composite.DefaultLayout(placeholderWidth, placeholderHeight)
+	if light {
+		layout = layout.Light()
+	}
+	symbols, _ := theme.LookupSymbols(theme.SymbolsUnicode)
+
+	p := composite.Placeholder{
+		Title:      c.Name,
+		Background: layout.BlankCard,
+		Accent:     accent,
+		Foreground: layout.Foreground,
+	}
+
+	switch {
+	case c.Type == "major_arcana":
+		p.Index = card.ToRoman(c.Value)
+		p.Symbol = symbols.Arcana(false)
+	case c.Suit != "":
+		p.Index = rankIndex(c)
+		p.Symbol = symbols.Suit(c.Suit)
+	}
+	return p
+}
+
+// rankIndexes are the corner indexes of the ace and court cards
+var rankIndexes = map[string]string{"ace": "A", "page": "P", "knight": "Kn", "queen": "Q", "king": "K"}
+
+// rankIndex returns the corner index of a minor arcana card: A, 2-10 or the
+// court initial
+func rankIndex(c *card.Card) string {
+	if index, ok := rankIndexes[c.Rank]; ok {
+		return index
+	}
+	if c.Value > 0 {
+		return strconv.Itoa(c.Value)
+	}
+	return ""
+}
+
+// record adds a card to the substituted cards, once
+func (p *placeholders) record(c *card.Card) {
+	for _, seen := range p.cards {
+		if seen.ID == c.ID {
+			return
+		}
+	}
+	p.cards = append(p.cards, c)
+}
+
+// printSummary lists the cards that were given placeholder art on stderr
+func (p *placeholders) printSummary() {
+	if p == nil || len(p.cards) == 0 {
+		return
+	}
+
+	names := make([]string, len(p.cards))
+	for i, c := range p.cards {
+		names[i] = c.Name
+	}
+	noun := "cards"
+	if len(names) == 1 {
+		noun = "card"
+	}
+	fmt.Fprintf(os.Stderr, "Warning: placeholder art used for %d %s with missing images: %s\n",
+		len(names), noun, strings.Join(names, ", "))
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/plugin"
+	"github.com/spf13/cobra"
+)
+
+// pluginsCmd represents the plugins command
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List plugins found on your PATH",
+	Long: `Plugins are executables named cartomancer-<name> on your PATH. Each one is
+available as 'cartomancer <name>', with any arguments passed through unchanged.
+
+Plugins receive a JSON object in the ` + plugin.ContextEnv + ` environment
+variable describing the resolved config file, deck library, cache directory,
+default deck and theme, so they can work with the same decks as cartomancer.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		plugins := plugin.Discover()
+		if len(plugins) == 0 {
+			fmt.Println("No plugins found on your PATH.")
+			return
+		}
+
+		for _, p := range plugins {
+			if isBuiltinCommand(p.Name) {
+				fmt.Printf("  %s (%s) [shadowed by built-in command]\n", p.Name, p.Path)
+			} else {
+				fmt.Printf("  %s (%s)\n", p.Name, p.Path)
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(pluginsCmd)
+}
+
+// registerPlugins adds a subcommand for every plugin on the PATH that does not
+// clash with a built-in command
+func registerPlugins() {
+	for _, p := range plugin.Discover() {
+		if isBuiltinCommand(p.Name) {
+			continue
+		}
+
+		p := p
+		RootCmd.AddCommand(&cobra.Command{
+			Use:                p.Name,
+			Short:              fmt.Sprintf("Plugin provided by %s", p.Path),
+			DisableFlagParsing: true,
+			SilenceUsage:       true,
+			Annotations:        map[string]string{"plugin": p.Path},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return plugin.Run(p, args, pluginContext())
+			},
+		})
+	}
+}
+
+// pluginsNeeded reports whether a command line may run or list plugins, so
+// the PATH is only searched for them when needed: for commands that are not
+// built in, for help, completion and the plugins command, and when no command
+// is given.
+func pluginsNeeded(args []string) bool {
+	var name string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			name = arg
+			break
+		}
+	}
+
+	switch name {
+	case "", "help", "completion", "plugins", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return !isBuiltinCommand(name)
+}
+
+// isBuiltinCommand reports whether a command name is provided by cartomancer itself
+func isBuiltinCommand(name string) bool {
+	for _, c := range RootCmd.Commands() {
+		if _, isPlugin := c.Annotations["plugin"]; isPlugin {
+			continue
+		}
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help" || name == "completion"
+}
+
+// pluginContext describes the resolved configuration for plugins
+func pluginContext() *plugin.Context {
+	ctx := &plugin.Context{
+		ConfigFile:  config.GetConfigFilePath(),
+		DeckLibrary: config.GetDeckLibraryPath(),
+		CacheDir:    config.GetCacheDir(),
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		ctx.Executable = executable
+	}
+
+	if cfg, err := config.LoadConfig(); err == nil {
+		ctx.DefaultDeck = cfg.DefaultDeck
+		ctx.Theme = cfg.Theme
+		ctx.Symbols = cfg.Symbols
+		if path, err := config.GetDeckPath(cfg.DefaultDeck); err == nil {
+			ctx.DefaultDeckPath = path
+		}
+	}
+
+	return ctx
+}
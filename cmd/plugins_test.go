@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+func TestPluginsNeeded(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"--help"}, true},
+		{[]string{"help"}, true},
+		{[]string{"plugins"}, true},
+		{[]string{"__complete", "dr"}, true},
+		{[]string{"tarot-sync", "--all"}, true},
+		{[]string{"draw", "3"}, false},
+		{[]string{"--symbols", "ascii", "show"}, true}, // The flag value is taken for a plugin
+		{[]string{"--symbols=ascii", "show"}, false},
+	}
+	for _, tt := range tests {
+		if got := pluginsNeeded(tt.args); got != tt.want {
+			t.Errorf("pluginsNeeded(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
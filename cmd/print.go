@@ -0,0 +1,477 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/imageload"
+	"github.com/arcanaland/cartomancer/internal/pdf"
+	"github.com/nfnt/resize"
+	"github.com/spf13/cobra"
+)
+
+// Layout of printed pages, in points
+const (
+	printMargin    = 36.0
+	printHeader    = 24.0
+	printGap       = 12.0
+	proofLabel     = 22.0
+	tarotCardRatio = 70.0 / 120.0 // Width to height of a standard tarot card
+)
+
+// paperSize returns the size in points of a paper name
+func paperSize(name string) (float64, float64, error) {
+	switch strings.ToLower(name) {
+	case "a4":
+		return pdf.A4Width, pdf.A4Height, nil
+	case "letter":
+		return pdf.LetterWidth, pdf.LetterHeight, nil
+	}
+	return 0, 0, fmt.Errorf("unknown paper size: %s (supported: a4, letter)", name)
+}
+
+// deckProofsheetCmd represents the deck proofsheet command
+var deckProofsheetCmd = &cobra.Command{
+	Use:   "proofsheet [deck]",
+	Short: "Lay out every card of a deck on a printable PDF contact sheet",
+	Long: `Proofsheet writes a PDF contact sheet of a deck's cards in deck order, each
+labelled with its ID and name, for proofing artwork on paper. Cards without
+art are drawn as empty frames so gaps are easy to spot. The default deck is
+used when none is given.
+
+Examples:
+  cartomancer deck proofsheet -o proof.pdf
+  cartomancer deck proofsheet rider-waite-smith --per-page 20 --paper letter`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		output, _ := cmd.Flags().GetString("output")
+		perPage, _ := cmd.Flags().GetInt("per-page")
+		paper, _ := cmd.Flags().GetString("paper")
+		dpi, _ := cmd.Flags().GetInt("dpi")
+
+		if perPage < 1 {
+			return fmt.Errorf("--per-page must be at least 1")
+		}
+		if dpi < 36 || dpi > 1200 {
+			return fmt.Errorf("--dpi must be between 36 and 1200")
+		}
+		width, height, err := paperSize(paper)
+		if err != nil {
+			return err
+		}
+
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		if output == "" {
+			output = d.ID + "-proof.pdf"
+		}
+
+		doc := proofSheet(d, width, height, perPage, float64(dpi))
+		if err := doc.Save(output); err != nil {
+			return fmt.Errorf("error writing %s: %v", output, err)
+		}
+
+		fmt.Printf("Proof sheet saved to %s\n", output)
+		return nil
+	},
+}
+
+func init() {
+	deckCmd.AddCommand(deckProofsheetCmd)
+
+	deckProofsheetCmd.Flags().StringP("output", "o", "", "Output PDF path (default: <deck-id>-proof.pdf)")
+	deckProofsheetCmd.Flags().Int("per-page", 9, "Cards per page")
+	deckProofsheetCmd.Flags().String("paper", "a4", "Paper size: a4 or letter")
+	deckProofsheetCmd.Flags().Int("dpi", 150, "Resolution of the card images")
+}
+
+// proofGrid returns the columns and rows that fit perPage cards of the given
+// ratio on a page area as large as possible, and the resulting card size
+func proofGrid(areaW, areaH float64, perPage int, ratio float64) (cols, rows int, cardW, cardH float64) {
+	for c := 1; c <= perPage; c++ {
+		r := (perPage + c - 1) / c
+		cellW := (areaW - float64(c-1)*printGap) / float64(c)
+		cellH := (areaH-float64(r-1)*printGap)/float64(r) - proofLabel
+		if cellW <= 0 || cellH <= 0 {
+			continue
+		}
+		h := math.Min(cellH, cellW/ratio)
+		if h > cardH {
+			cols, rows, cardW, cardH = c, r, h*ratio, h
+		}
+	}
+	return cols, rows, cardW, cardH
+}
+
+// proofSheet lays out a deck's cards on pages of the given size
+func proofSheet(d *deck.Deck, pageW, pageH float64, perPage int, dpi float64) *pdf.Document {
+	doc := pdf.New()
+	doc.Title = d.Name + " proof sheet"
+
+	areaW := pageW - 2*printMargin
+	areaH := pageH - 2*printMargin - printHeader
+	cols, rows, cardW, cardH := proofGrid(areaW, areaH, perPage, tarotCardRatio)
+	cellH := cardH + proofLabel
+
+	// Center the grid in the area below the header
+	gridW := float64(cols)*cardW + float64(cols-1)*printGap
+	gridH := float64(rows)*cellH + float64(rows-1)*printGap
+	left := printMargin + (areaW-gridW)/2
+	top := printMargin + printHeader + (areaH-gridH)/2
+
+	title := d.Name
+	if d.Version != "" {
+		title += " v" + strings.TrimPrefix(d.Version, "v")
+	}
+
+	cards := d.Cards()
+	pages := (len(cards) + perPage - 1) / perPage
+	var page *pdf.Page
+	for i, c := range cards {
+		slot := i % perPage
+		if slot == 0 {
+			page = doc.AddPage(pageW, pageH)
+			page.Gray(0)
+			page.Text(pdf.HelveticaBold, 12, printMargin, printMargin+12, pdf.Fit(pdf.HelveticaBold, 12, areaW*0.75, title))
+			number := fmt.Sprintf("%d / %d", i/perPage+1, pages)
+			page.Text(pdf.Helvetica, 10, pageW-printMargin-pdf.TextWidth(pdf.Helvetica, 10, number), printMargin+12, number)
+		}
+
+		x := left + float64(slot%cols)*(cardW+printGap)
+		y := top + float64(slot/cols)*(cellH+printGap)
+
+		img, err := loadHighestResImage(d.AssetRoots(), c)
+		var tooLarge *imageload.TooLargeError
+		if errors.As(err, &tooLarge) {
+			fmt.Fprintf(os.Stderr, "Warning: %v (raise max_image_pixels in config.toml)\n", err)
+		}
+		if err == nil {
+			// Fit the art in the card frame, keeping its own proportions
+			b := img.Bounds()
+			w, h := cardW, cardW*float64(b.Dy())/float64(b.Dx())
+			if h > cardH {
+				w, h = cardH*float64(b.Dx())/float64(b.Dy()), cardH
+			}
+			if pixels := int(h / pdf.PointsPerInch * dpi); pixels < b.Dy() {
+				img = resize.Resize(0, uint(pixels), img, resize.Lanczos3)
+			}
+			page.Image(img, x+(cardW-w)/2, y+(cardH-h)/2, w, h)
+		} else {
+			page.Gray(0.6)
+			const missing = "no art"
+			page.Text(pdf.Helvetica, 9, x+(cardW-pdf.TextWidth(pdf.Helvetica, 9, missing))/2, y+cardH/2, missing)
+		}
+		page.Gray(0.6)
+		page.Rect(x, y, cardW, cardH, 0.5)
+
+		name := pdf.Fit(pdf.HelveticaBold, 8, cardW, c.Name)
+		page.Gray(0)
+		page.Text(pdf.HelveticaBold, 8, x+(cardW-pdf.TextWidth(pdf.HelveticaBold, 8, name))/2, y+cardH+10, name)
+		id := pdf.Fit(pdf.Helvetica, 7, cardW, c.ID)
+		page.Gray(0.4)
+		page.Text(pdf.Helvetica, 7, x+(cardW-pdf.TextWidth(pdf.Helvetica, 7, id))/2, y+cardH+19, id)
+	}
+
+	return doc
+}
+
+// Duplex modes of the print-and-play export
+const (
+	duplexLong  = "long"  // Backs mirrored left to right, for printers flipping on the long edge
+	duplexShort = "short" // Backs mirrored top to bottom, for printers flipping on the short edge
+	duplexNone  = "none"  // No back pages
+)
+
+// Layout of print-and-play pages, in points
+const (
+	cropMarkOffset = 2 * pdf.PointsPerMM
+	cropMarkLength = 5 * pdf.PointsPerMM
+	printPageEdge  = cropMarkOffset + cropMarkLength + 3*pdf.PointsPerMM
+)
+
+// deckExportPrintCmd represents the deck export-print command
+var deckExportPrintCmd = &cobra.Command{
+	Use:   "export-print [deck]",
+	Short: "Lay out a deck at physical size on a print-and-play PDF",
+	Long: `Export-print writes a PDF of a deck's cards at true physical size, ready to
+print, cut and play. Each card is printed with bleed, the art extended past the
+cut line so trimming stays clean, and crop marks in the page margins show
+where to cut.
+
+Cards are --card-width wide, and as tall as the deck's aspect_ratio (width
+divided by height) makes them, or the shape of its art when the deck declares
+none. Lengths take mm, cm, in or pt units.
+
+Every page of fronts is followed by a page of backs laid out for duplex
+printing: mirrored left to right for printers that flip on the long edge, or
+top to bottom with --duplex short. Use --duplex none for fronts only.
+
+Examples:
+  cartomancer deck export-print -o tarot.pdf
+  cartomancer deck export-print rider-waite-smith --card-width 2.75in --paper letter
+  cartomancer deck export-print --bleed 0 --duplex none --best-effort`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var deckFlag string
+		if len(args) > 0 {
+			deckFlag = args[0]
+		}
+		output, _ := cmd.Flags().GetString("output")
+		paper, _ := cmd.Flags().GetString("paper")
+		dpi, _ := cmd.Flags().GetInt("dpi")
+		duplex, _ := cmd.Flags().GetString("duplex")
+		cardWidthFlag, _ := cmd.Flags().GetString("card-width")
+		bleedFlag, _ := cmd.Flags().GetString("bleed")
+
+		if dpi < 72 || dpi > 1200 {
+			return fmt.Errorf("--dpi must be between 72 and 1200")
+		}
+		if duplex != duplexLong && duplex != duplexShort && duplex != duplexNone {
+			return fmt.Errorf("unknown duplex mode: %s (supported: %s, %s, %s)", duplex, duplexLong, duplexShort, duplexNone)
+		}
+		pageW, pageH, err := paperSize(paper)
+		if err != nil {
+			return err
+		}
+		cardW, err := parseLength(cardWidthFlag)
+		if err != nil || cardW <= 0 {
+			return fmt.Errorf("invalid card width: %s", cardWidthFlag)
+		}
+		bleed, err := parseLength(bleedFlag)
+		if err != nil || bleed < 0 {
+			return fmt.Errorf("invalid bleed: %s", bleedFlag)
+		}
+
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		ratio := d.AspectRatio
+		if ratio <= 0 {
+			ratio = tarotCardRatio
+			if w, h, ok := cardAspect(d); ok {
+				ratio = float64(w) / float64(h)
+			}
+		}
+		cardH := cardW / ratio
+
+		cols := int((pageW - 2*printPageEdge + 2*bleed) / (cardW + 2*bleed))
+		rows := int((pageH - 2*printPageEdge + 2*bleed) / (cardH + 2*bleed))
+		if cols < 1 || rows < 1 {
+			return fmt.Errorf("a %.1fx%.1fmm card does not fit on %s paper", cardW/pdf.PointsPerMM, cardH/pdf.PointsPerMM, paper)
+		}
+
+		if output == "" {
+			output = d.ID + "-print.pdf"
+		}
+
+		layout := printLayout{
+			pageW: pageW, pageH: pageH,
+			cardW: cardW, cardH: cardH, bleed: bleed,
+			cols: cols, rows: rows,
+			dpi:    float64(dpi),
+			duplex: duplex,
+		}
+		p := newPlaceholders(cmd)
+		p.light = false // Printed on paper, not shown on the terminal
+		doc, err := layout.document(d, p)
+		if err != nil {
+			return err
+		}
+		if err := doc.Save(output); err != nil {
+			return fmt.Errorf("error writing %s: %v", output, err)
+		}
+
+		p.printSummary()
+		fmt.Printf("Print-and-play file saved to %s (%d cards per page, %.1fx%.1fmm)\n",
+			output, cols*rows, cardW/pdf.PointsPerMM, cardH/pdf.PointsPerMM)
+		return nil
+	},
+}
+
+func init() {
+	deckCmd.AddCommand(deckExportPrintCmd)
+
+	deckExportPrintCmd.Flags().StringP("output", "o", "", "Output PDF path (default: <deck-id>-print.pdf)")
+	deckExportPrintCmd.Flags().String("paper", "a4", "Paper size: a4 or letter")
+	deckExportPrintCmd.Flags().String("card-width", "70mm", "Width of the cut cards")
+	deckExportPrintCmd.Flags().String("bleed", "3mm", "Art extending past the cut line on each side")
+	deckExportPrintCmd.Flags().Int("dpi", 300, "Resolution of the card images")
+	deckExportPrintCmd.Flags().String("duplex", duplexLong, "Back pages for duplex printing: long, short or none")
+	addBestEffortFlag(deckExportPrintCmd)
+}
+
+// parseLength parses a length such as 70mm, 2.75in or 200pt into points
+func parseLength(s string) (float64, error) {
+	units := []struct {
+		suffix string
+		points float64
+	}{
+		{"mm", pdf.PointsPerMM},
+		{"cm", 10 * pdf.PointsPerMM},
+		{"in", pdf.PointsPerInch},
+		{"pt", 1},
+	}
+	s = strings.TrimSpace(strings.ToLower(s))
+	for _, u := range units {
+		if number, ok := strings.CutSuffix(s, u.suffix); ok {
+			v, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil {
+				return 0, err
+			}
+			return v * u.points, nil
+		}
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil && v == 0 {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("length %q needs a unit: mm, cm, in or pt", s)
+}
+
+// printLayout places cards at physical size on print-and-play pages
+type printLayout struct {
+	pageW, pageH float64
+	cardW, cardH float64 // Cut size
+	bleed        float64
+	cols, rows   int
+	dpi          float64
+	duplex       string
+}
+
+// slot returns the top left corner of the cut area of a card slot, mirrored
+// for the backs of duplex pages
+func (l printLayout) slot(i int, back bool) (float64, float64) {
+	col, row := i%l.cols, i/l.cols
+	if back && l.duplex == duplexLong {
+		col = l.cols - 1 - col
+	}
+	if back && l.duplex == duplexShort {
+		row = l.rows - 1 - row
+	}
+
+	// Neighbouring cards share the space of their bleeds
+	pitchX, pitchY := l.cardW+2*l.bleed, l.cardH+2*l.bleed
+	gridW := float64(l.cols)*pitchX - 2*l.bleed
+	gridH := float64(l.rows)*pitchY - 2*l.bleed
+	return (l.pageW-gridW)/2 + float64(col)*pitchX, (l.pageH-gridH)/2 + float64(row)*pitchY
+}
+
+// document lays out the cards, each front page followed by its backs
+func (l printLayout) document(d *deck.Deck, p *placeholders) (*pdf.Document, error) {
+	doc := pdf.New()
+	doc.Title = d.Name
+
+	perPage := l.cols * l.rows
+	cards := d.Cards()
+
+	var back pdf.Image
+	if l.duplex != duplexNone {
+		back = doc.AddImage(l.scale(deckBackImage(d)))
+	}
+
+	for start := 0; start < len(cards); start += perPage {
+		end := min(start+perPage, len(cards))
+
+		page := doc.AddPage(l.pageW, l.pageH)
+		for i, c := range cards[start:end] {
+			img, err := loadHighestResImage(d.AssetRoots(), c)
+			if err != nil {
+				var ok bool
+				if img, ok = p.image(c, placeholderWidth, int(placeholderWidth/(l.cardW/l.cardH))); !ok {
+					return nil, fmt.Errorf("%v (use --best-effort to print placeholders)", err)
+				}
+			}
+			x, y := l.slot(i, false)
+			page.Image(l.scale(img), x-l.bleed, y-l.bleed, l.cardW+2*l.bleed, l.cardH+2*l.bleed)
+		}
+		l.cropMarks(page, end-start, false)
+
+		if l.duplex != duplexNone {
+			page := doc.AddPage(l.pageW, l.pageH)
+			for i := range cards[start:end] {
+				x, y := l.slot(i, true)
+				page.DrawImage(back, x-l.bleed, y-l.bleed, l.cardW+2*l.bleed, l.cardH+2*l.bleed)
+			}
+			l.cropMarks(page, end-start, true)
+		}
+	}
+
+	return doc, nil
+}
+
+// scale crops an image to the shape of the bleed area, cutting the overflow
+// evenly from both sides, and resizes it to the print resolution
+func (l printLayout) scale(img image.Image) image.Image {
+	w, h := l.cardW+2*l.bleed, l.cardH+2*l.bleed
+	b := img.Bounds()
+	crop := b
+	if float64(b.Dx())/float64(b.Dy()) > w/h {
+		cw := int(math.Round(float64(b.Dy()) * w / h))
+		crop.Min.X += (b.Dx() - cw) / 2
+		crop.Max.X = crop.Min.X + cw
+	} else {
+		ch := int(math.Round(float64(b.Dx()) * h / w))
+		crop.Min.Y += (b.Dy() - ch) / 2
+		crop.Max.Y = crop.Min.Y + ch
+	}
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		img = sub.SubImage(crop)
+	}
+
+	pixelsW, pixelsH := int(w/pdf.PointsPerInch*l.dpi), int(h/pdf.PointsPerInch*l.dpi)
+	if pixelsH < img.Bounds().Dy() {
+		return resize.Resize(uint(pixelsW), uint(pixelsH), img, resize.Lanczos3)
+	}
+	return img
+}
+
+// cropMarks draws short lines in the page margins along every cut line of
+// the first n slots
+func (l printLayout) cropMarks(page *pdf.Page, n int, back bool) {
+	top, bottom := l.pageH, 0.0
+	left, right := l.pageW, 0.0
+	xs, ys := map[float64]bool{}, map[float64]bool{}
+	for i := 0; i < n; i++ {
+		x, y := l.slot(i, back)
+		xs[x], xs[x+l.cardW] = true, true
+		ys[y], ys[y+l.cardH] = true, true
+		left, right = math.Min(left, x-l.bleed), math.Max(right, x+l.cardW+l.bleed)
+		top, bottom = math.Min(top, y-l.bleed), math.Max(bottom, y+l.cardH+l.bleed)
+	}
+
+	page.Gray(0)
+	for _, x := range slices.Sorted(maps.Keys(xs)) {
+		page.Line(x, top-cropMarkOffset, x, top-cropMarkOffset-cropMarkLength, 0.25)
+		page.Line(x, bottom+cropMarkOffset, x, bottom+cropMarkOffset+cropMarkLength, 0.25)
+	}
+	for _, y := range slices.Sorted(maps.Keys(ys)) {
+		page.Line(left-cropMarkOffset, y, left-cropMarkOffset-cropMarkLength, y, 0.25)
+		page.Line(right+cropMarkOffset, y, right+cropMarkOffset+cropMarkLength, y, 0.25)
+	}
+}
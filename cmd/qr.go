@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/qr"
+)
+
+// qrQuietZone is the light border around terminal QR codes, in modules. The
+// standard asks for four, but two is plenty for phone cameras and saves room.
+const qrQuietZone = 2
+
+// qrColors draws black modules on white whatever the terminal's colors, as
+// scanners look for dark modules on a light background
+const qrColors = "\033[30;107m"
+
+// cardPageURL returns the address of a card's page on the deck's website,
+// from card_url in deck.toml, or the website itself for decks without a
+// pattern
+func cardPageURL(d *deck.Deck, c *card.Card) (string, error) {
+	if address, ok := d.CardPage(c); ok {
+		return address, nil
+	}
+	if d.Website != "" {
+		return d.Website, nil
+	}
+	return "", fmt.Errorf("%s has no website for --qr to link to: set card_url or website in the [deck] table of deck.toml", d.Name)
+}
+
+// printQR prints a QR code of an address, with the address beneath for
+// those who cannot scan it
+func printQR(address string) error {
+	code, err := qr.Encode([]byte(address), qr.M)
+	if err != nil {
+		return fmt.Errorf("error encoding QR code: %v", err)
+	}
+	fmt.Println()
+	for _, line := range qrLines(code) {
+		fmt.Println(line)
+	}
+	fmt.Println(address)
+	return nil
+}
+
+// qrLines draws a QR code with half blocks, two rows of modules per line
+func qrLines(code *qr.Code) []string {
+	var lines []string
+	for y := -qrQuietZone; y < code.Size+qrQuietZone; y += 2 {
+		var b strings.Builder
+		b.WriteString(qrColors)
+		for x := -qrQuietZone; x < code.Size+qrQuietZone; x++ {
+			top, bottom := code.Dark(x, y), code.Dark(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\033[0m")
+		lines = append(lines, b.String())
+	}
+	return lines
+}
@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"github.com/nfnt/resize"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the render command
+const (
+	renderFormatANSI    = "ansi"    // ANSI art
+	renderFormatCompact = "compact" // The show --compact line
+	renderFormatPNG     = "png"     // Base64-encoded PNG
+)
+
+// renderDelimiter ends each plain-text response in --stdin mode
+const renderDelimiter = "\x00"
+
+// maxRenderImages is how many decoded full-resolution card images the render
+// service keeps, evicting the least recently used first
+const maxRenderImages = 8
+
+// maxANSISize bounds the width and height of ANSI renders, in columns and rows
+const maxANSISize = 1000
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render [card_id...]",
+	Short: "Render cards for other programs, optionally as a long-running service",
+	Long: `Render writes the ANSI art of each card to stdout, or another format chosen
+with --format: ansi, compact (the show --compact line) or png (base64).
+
+With --stdin, render reads requests line by line until end of input, keeping
+decks, decoded images and rendered output in memory (up to --cache-size MiB of
+output and the last few decoded images), so other programs can get fast
+repeated renders from one process without the HTTP server. A request is either
+a card ID, answered with the rendered output followed by a line holding a
+single NUL byte, or a JSON object answered with a single JSON line:
+
+  {"id": "r1", "card": "XVII", "deck": "rider-waite-smith", "format": "ansi", "width": 60}
+  {"id": "r1", "card": "major_arcana.17", "name": "The Star", "format": "ansi", "output": "..."}
+
+Every field but card is optional; id is echoed back to match responses to
+requests, and width and height are in columns and rows for ansi (up to 1000)
+or pixels for png (up to 4096). Failed requests are answered with an "error"
+field (or a line starting with "error:") and the service keeps running.
+
+Examples:
+  cartomancer render XVII --width 60
+  cartomancer render 0 1 2 --format compact
+  printf 'XVII\n{"card": "cups/queen", "format": "png", "height": 300}\n' | cartomancer render --stdin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		opts, err := loadDisplayOptions(cmd, "")
+		if err != nil {
+			return err
+		}
+
+		cacheSize, _ := cmd.Flags().GetInt("cache-size")
+		if cacheSize < 0 {
+			return fmt.Errorf("--cache-size cannot be negative")
+		}
+
+		s := &renderService{
+			defaultDeck: deckFlag,
+			opts:        opts,
+			decks:       map[string]*deck.Deck{},
+			images:      map[string]*list.Element{},
+			imageOrder:  list.New(),
+			outputs:     newByteCache(int64(cacheSize) << 20),
+		}
+		defaults := renderRequest{}
+		defaults.Format, _ = cmd.Flags().GetString("format")
+		defaults.Width, _ = cmd.Flags().GetInt("width")
+		defaults.Height, _ = cmd.Flags().GetInt("height")
+
+		if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+			if len(args) > 0 {
+				return fmt.Errorf("card IDs cannot be combined with --stdin")
+			}
+			return s.serve(os.Stdin, os.Stdout, defaults)
+		}
+
+		if len(args) == 0 {
+			return fmt.Errorf("requires at least one card ID, or --stdin")
+		}
+		for _, arg := range args {
+			req := defaults
+			req.Card = arg
+			resp := s.render(req)
+			if resp.Error != "" {
+				return fmt.Errorf("%s", resp.Error)
+			}
+			fmt.Print(resp.Output)
+			if !strings.HasSuffix(resp.Output, "\n") {
+				fmt.Println()
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(renderCmd)
+
+	renderCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	renderCmd.Flags().String("format", renderFormatANSI, "Output format: ansi, compact or png")
+	renderCmd.Flags().Int("width", 0, "Width in columns (ansi) or pixels (png) (default: the cached art size)")
+	renderCmd.Flags().Int("height", 0, "Height in rows (ansi) or pixels (png) (default: from the image aspect ratio)")
+	renderCmd.Flags().Int("cache-size", 64, "Memory for cached rendered output in --stdin mode, in MiB")
+	renderCmd.Flags().Bool("stdin", false, "Read requests line by line from stdin and keep rendering until end of input")
+	renderCmd.Flags().String("numbering", "", "Major arcana numbering style for compact output: arabic, padded or roman")
+	renderCmd.Flags().StringSlice("fields", nil, "Fields for compact output, in order (default from config)")
+}
+
+// renderRequest is a render request read in --stdin mode
+type renderRequest struct {
+	ID     string `json:"id,omitempty"`
+	Card   string `json:"card"`
+	Deck   string `json:"deck,omitempty"`
+	Format string `json:"format,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// renderResponse answers a render request
+type renderResponse struct {
+	ID     string `json:"id,omitempty"`
+	Card   string `json:"card,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Format string `json:"format,omitempty"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// renderService renders cards, caching loaded decks, decoded images and
+// rendered output across requests
+type renderService struct {
+	defaultDeck string
+	opts        displayOptions
+
+	decks      map[string]*deck.Deck
+	images     map[string]*list.Element // Keyed by card ID within a deck path
+	imageOrder *list.List               // Of *renderImage, most recently used at the front
+	outputs    *byteCache               // Keyed by deck path, card ID, format and size
+}
+
+// renderImage is a decoded card image held by the render service
+type renderImage struct {
+	key string
+	img image.Image
+}
+
+// serve answers requests read line by line from r, flushing each response
+func (s *renderService) serve(r io.Reader, w io.Writer, defaults renderRequest) error {
+	out := bufio.NewWriter(w)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		req := defaults
+		if strings.HasPrefix(line, "{") {
+			if err := json.Unmarshal([]byte(line), &req); err != nil {
+				writeJSONLine(out, renderResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+			} else {
+				writeJSONLine(out, s.render(req))
+			}
+		} else {
+			req.Card = line
+			resp := s.render(req)
+			if resp.Error != "" {
+				fmt.Fprintf(out, "error: %s\n", resp.Error)
+			} else {
+				out.WriteString(resp.Output)
+				if !strings.HasSuffix(resp.Output, "\n") {
+					out.WriteString("\n")
+				}
+			}
+			out.WriteString(renderDelimiter + "\n")
+		}
+
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// writeJSONLine writes v as a single line of JSON
+func writeJSONLine(w io.Writer, v interface{}) {
+	data, _ := json.Marshal(v)
+	w.Write(append(data, '\n'))
+}
+
+// render answers a request, reporting failures in the response's Error field
+func (s *renderService) render(req renderRequest) renderResponse {
+	resp := renderResponse{ID: req.ID, Format: req.Format}
+	if resp.Format == "" {
+		resp.Format = renderFormatANSI
+	}
+
+	d, err := s.deck(req.Deck)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	c, err := d.GetCard(req.Card)
+	if err != nil {
+		resp.Error = fmt.Sprintf("error getting card: %v", err)
+		return resp
+	}
+	resp.Card, resp.Name = c.ID, c.Name
+
+	if err := checkRenderSize(resp.Format, req.Width, req.Height); err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	key := fmt.Sprintf("%s|%s|%s|%dx%d", d.Path, c.ID, resp.Format, req.Width, req.Height)
+	if output, ok := s.outputs.get(key); ok {
+		resp.Output = string(output.data)
+		return resp
+	}
+
+	switch resp.Format {
+	case renderFormatANSI:
+		resp.Output, err = s.renderANSI(d, c, req.Width, req.Height)
+	case renderFormatCompact:
+		opts := s.opts
+		opts.DeckName, opts.DeckLicense = d.Name, d.License
+		resp.Output, err = compactLine(c, opts)
+	case renderFormatPNG:
+		resp.Output, err = s.renderPNG(d, c, req.Width, req.Height)
+	default:
+		err = fmt.Errorf("unknown format: %s (supported: %s, %s, %s)",
+			resp.Format, renderFormatANSI, renderFormatCompact, renderFormatPNG)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	s.outputs.add(key, &cacheEntry{data: []byte(resp.Output)})
+	return resp
+}
+
+// checkRenderSize refuses a width or height too large to render in memory
+func checkRenderSize(format string, width, height int) error {
+	limit, unit := maxImageHeight, "pixels"
+	if format == renderFormatANSI {
+		limit, unit = maxANSISize, "columns and rows"
+	}
+	if width > limit || height > limit {
+		return fmt.Errorf("width and height must be at most %d %s", limit, unit)
+	}
+	return nil
+}
+
+// deck returns a loaded deck by name or path, or the default deck when name is empty
+func (s *renderService) deck(name string) (*deck.Deck, error) {
+	if name == "" {
+		name = s.defaultDeck
+	}
+	if d, ok := s.decks[name]; ok {
+		return d, nil
+	}
+
+	deckPath, err := resolveDeckPath(name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := deck.LoadDeck(deckPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading deck: %w", err)
+	}
+
+	s.decks[name] = d
+	return d, nil
+}
+
+// image returns the decoded highest resolution image of a card
+func (s *renderService) image(d *deck.Deck, c *card.Card) (image.Image, error) {
+	key := d.Path + "|" + c.ID
+	if el, ok := s.images[key]; ok {
+		s.imageOrder.MoveToFront(el)
+		return el.Value.(*renderImage).img, nil
+	}
+
+	img, err := loadViewImage(d.AssetRoots(), c, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.images[key] = s.imageOrder.PushFront(&renderImage{key: key, img: img})
+	for s.imageOrder.Len() > maxRenderImages {
+		evicted := s.imageOrder.Remove(s.imageOrder.Back()).(*renderImage)
+		delete(s.images, evicted.key)
+	}
+	return img, nil
+}
+
+// renderANSI renders a card as ANSI art. Without a size the cached art used by
+// show is returned; a width alone keeps the image's aspect ratio.
+func (s *renderService) renderANSI(d *deck.Deck, c *card.Card, width, height int) (string, error) {
+	if width <= 0 && height <= 0 {
+		return cardAnsiArt(d.AssetRoots(), c)
+	}
+
+	img, err := s.image(d, c)
+	if err != nil {
+		return "", err
+	}
+
+	// Each row holds two pixels
+	bounds := img.Bounds()
+	if width <= 0 {
+		width = max(height*2*bounds.Dx()/bounds.Dy(), 1)
+	}
+	if height <= 0 {
+		height = max(width*bounds.Dy()/bounds.Dx()/2, 1)
+	}
+	return render.RenderANSI(img, viewOptions(render.Options{Width: width, Height: height, TrueColor: true}))
+}
+
+// renderPNG encodes a card image as a base64 PNG, resized when a width or
+// height is given
+func (s *renderService) renderPNG(d *deck.Deck, c *card.Card, width, height int) (string, error) {
+	img, err := s.image(d, c)
+	if err != nil {
+		return "", err
+	}
+	if width > 0 || height > 0 {
+		img = resize.Resize(uint(max(width, 0)), uint(max(height, 0)), img, resize.Lanczos3)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("error encoding image: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/cast"
+	"github.com/spf13/cobra"
+)
+
+// terminalOut is the process's stdout, which stays the terminal while a
+// recording captures os.Stdout
+var terminalOut = os.Stdout
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file.cast>",
+	Short: "Play back a recorded reading in the terminal",
+	Long: `Replay plays back a reading recorded with spread --record, with the same
+pauses between cards as when it was read, so it can be shared or reviewed
+exactly as it unfolded.
+
+Recordings are asciicast v2 files and can also be played with asciinema or
+embedded in web pages with its player. Each card revealed is marked in the
+recording with its position; use --markers to list them instead of playing.
+
+Long pauses are shortened to --idle-limit, and --speed plays the recording
+faster or slower.
+
+Examples:
+  cartomancer spread celtic-cross --step --record reading.cast
+  cartomancer replay reading.cast
+  cartomancer replay reading.cast --speed 2 --idle-limit 1s`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		speed, _ := cmd.Flags().GetFloat64("speed")
+		if speed <= 0 {
+			return fmt.Errorf("--speed must be greater than 0")
+		}
+		idleLimit, _ := cmd.Flags().GetDuration("idle-limit")
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("error opening recording: %v", err)
+		}
+		defer f.Close()
+
+		h, events, err := cast.Read(f)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", filepath.Base(args[0]), err)
+		}
+
+		if markers, _ := cmd.Flags().GetBool("markers"); markers {
+			for _, e := range events {
+				if e.Type == cast.Marker {
+					fmt.Printf("%7.1fs  %s\n", e.Time, e.Data)
+				}
+			}
+			return nil
+		}
+
+		if cols, rows := terminalSize(); cols < h.Width || rows < h.Height {
+			fmt.Fprintf(os.Stderr, "Recorded at %dx%d; the playback may wrap in this %dx%d terminal\n", h.Width, h.Height, cols, rows)
+		}
+		return replay(os.Stdout, events, speed, idleLimit)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().Float64("speed", 1, "Playback speed, e.g. 2 for twice as fast")
+	replayCmd.Flags().Duration("idle-limit", 3*time.Second, "Longest pause between events, 0 to keep pauses as recorded")
+	replayCmd.Flags().Bool("markers", false, "List the marked moments of the recording, such as cards revealed, without playing it")
+}
+
+// replay writes the output events of a recording with their recorded timing
+func replay(w io.Writer, events []cast.Event, speed float64, idleLimit time.Duration) error {
+	var last float64
+	for _, e := range events {
+		if e.Type != cast.Output {
+			continue
+		}
+		pause := time.Duration((e.Time - last) / speed * float64(time.Second))
+		if idleLimit > 0 {
+			pause = min(pause, idleLimit)
+		}
+		time.Sleep(pause)
+		last = e.Time
+
+		if _, err := io.WriteString(w, e.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recording captures a command's session in an asciicast file: everything
+// written to os.Stdout, keys read through input and the moments marked. The
+// output still reaches the terminal as it is written.
+type recording struct {
+	file *os.File
+	cast *cast.Writer
+	pipe *os.File
+	done chan struct{}
+}
+
+// startRecording redirects os.Stdout into a new recording at path until stop
+// is called
+func startRecording(path, title string) (*recording, error) {
+	cols, rows := terminalSize()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating recording: %v", err)
+	}
+	w, err := cast.NewWriter(file, cast.Header{
+		Width:     cols,
+		Height:    rows,
+		Timestamp: time.Now().Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": os.Getenv("TERM")},
+	})
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing recording: %v", err)
+	}
+
+	r, pipe, err := os.Pipe()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error starting recording: %v", err)
+	}
+
+	rec := &recording{file: file, cast: w, pipe: pipe, done: make(chan struct{})}
+	go func() {
+		io.Copy(io.MultiWriter(terminalOut, w), r)
+		r.Close()
+		close(rec.done)
+	}()
+	os.Stdout = pipe
+	return rec, nil
+}
+
+// input returns a reader recording the keys read from in. A nil recording
+// returns in unchanged.
+func (r *recording) input(in io.Reader) io.Reader {
+	if r == nil {
+		return in
+	}
+	return &recordedInput{in: in, cast: r.cast}
+}
+
+// mark records a labelled moment, such as a card being revealed. It does
+// nothing on a nil recording.
+func (r *recording) mark(label string) {
+	if r != nil {
+		r.cast.Mark(label)
+	}
+}
+
+// stop restores os.Stdout and finishes the recording file. Stopping again
+// does nothing.
+func (r *recording) stop() error {
+	if os.Stdout != r.pipe {
+		return nil
+	}
+	os.Stdout = terminalOut
+	r.pipe.Close()
+	<-r.done
+
+	if err := r.cast.Close(); err != nil {
+		r.file.Close()
+		return fmt.Errorf("error writing recording: %v", err)
+	}
+	return r.file.Close()
+}
+
+// recordedInput records what is read from a reader as input events
+type recordedInput struct {
+	in   io.Reader
+	cast *cast.Writer
+}
+
+func (r *recordedInput) Read(p []byte) (int, error) {
+	n, err := r.in.Read(p)
+	if n > 0 {
+		r.cast.Input(string(p[:n]))
+	}
+	return n, err
+}
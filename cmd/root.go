@@ -1,6 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/pkg/render"
 	"github.com/spf13/cobra"
 )
 
@@ -10,13 +18,98 @@ var RootCmd = &cobra.Command{
 	Short: "Tool for validating and managing tarot decks",
 	Long: `Cartomancer is a command-line tool for validating, and managing tarot decks and esoterica.
 It helps ensure that decks conform to the Tarot Deck Specification v1.0 maintained by Arcana Land.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkKiosk(cmd); err != nil {
+			return err
+		}
+		switch background, _ := cmd.Flags().GetString("background"); background {
+		case "", theme.BackgroundAuto, theme.BackgroundDark, theme.BackgroundLight:
+		default:
+			return fmt.Errorf("invalid --background: %s (supported: auto, dark, light)", background)
+		}
+		if profile, _ := cmd.Flags().GetString("cb-profile"); profile != "" {
+			if _, err := render.CVDTransform(profile); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
 }
 
 func init() {
 	RootCmd.AddCommand(validateCmd)
+
+	RootCmd.PersistentFlags().String("theme", "",
+		"Output theme: "+strings.Join(theme.Names(), ", ")+" (default from config)")
+	RootCmd.PersistentFlags().String("symbols", "",
+		"Symbol set for suits and arcana: auto, nerd, unicode or ascii (default from config)")
+	RootCmd.PersistentFlags().String("background", "",
+		"Terminal background to adapt colors to: auto, dark or light (default from config, else auto)")
+	RootCmd.PersistentFlags().Bool("safe-mode", false,
+		"Never show the art of cards with content warnings, only their details (default from config)")
+	RootCmd.PersistentFlags().Bool("kiosk", false,
+		"Only allow browsing and drawing cards, for public terminals (default from config)")
+	RootCmd.PersistentFlags().String("cb-profile", "",
+		"Color vision profile for card art: "+strings.Join(render.CVDProfiles(), ", ")+" (default from config)")
+}
+
+// loadTheme returns the theme selected by the --theme and --symbols flags,
+// falling back to the settings from config
+func loadTheme(cmd *cobra.Command) (*theme.Theme, error) {
+	configTheme, configSymbols, err := config.GetTheme()
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %v", err)
+	}
+
+	name, _ := cmd.Flags().GetString("theme")
+	if name == "" {
+		name = configTheme
+	}
+
+	symbols, _ := cmd.Flags().GetString("symbols")
+	if symbols == "" {
+		symbols = configSymbols
+	}
+
+	t, err := theme.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.UseSymbols(symbols); err != nil {
+		return nil, err
+	}
+
+	return t.ForBackground(terminalBackground()), nil
+}
+
+var (
+	backgroundOnce sync.Once
+	background     string
+)
+
+// terminalBackground returns the terminal background set by --background or
+// background in config.toml, detecting it when neither is set or it is auto.
+// Detection asks the terminal, so it happens at most once.
+func terminalBackground() string {
+	backgroundOnce.Do(func() {
+		background, _ = RootCmd.PersistentFlags().GetString("background")
+		if background == "" {
+			if cfg, err := config.LoadConfig(); err == nil {
+				background = cfg.Background
+			}
+		}
+		if background != theme.BackgroundDark && background != theme.BackgroundLight {
+			background = theme.DetectBackground()
+		}
+	})
+	return background
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
+	if pluginsNeeded(os.Args[1:]) {
+		registerPlugins()
+	}
 	return RootCmd.Execute()
 }
@@ -0,0 +1,673 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/imageload"
+	"github.com/arcanaland/cartomancer/internal/metrics"
+	"github.com/arcanaland/cartomancer/internal/pool"
+	"github.com/arcanaland/cartomancer/internal/session"
+	"github.com/arcanaland/cartomancer/internal/singleflight"
+	"github.com/arcanaland/cartomancer/internal/spread"
+	"github.com/arcanaland/cartomancer/internal/websocket"
+	"github.com/arcanaland/cartomancer/internal/webui"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// maxImageHeight bounds the h parameter of the card image endpoint
+const maxImageHeight = 4096
+
+// imageMaxAge is how long clients may reuse a card image without revalidating
+const imageMaxAge = time.Hour
+
+// Limits on how long clients may take to send requests and keep idle
+// connections open, so slow or stalled clients cannot hold connections
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	idleTimeout       = 2 * time.Minute
+)
+
+// sessionRoute is the route of live reading sessions
+const sessionRoute = "GET /decks/{id}/sessions/{session_id}"
+
+// Session connections are pinged when idle and closed when nothing, not even
+// a pong, arrives from the participant for sessionReadTimeout
+const (
+	sessionPingInterval = 30 * time.Second
+	sessionReadTimeout  = 75 * time.Second
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve decks in your deck library over HTTP",
+	Long: `Serve starts an HTTP server for the decks in your deck library, so web
+front-ends can show cards without shipping the full-resolution assets.
+
+Endpoints:
+  GET /decks
+  GET /decks/{id}
+  GET /decks/{id}/draw?count=3&pool=majors&seed=42
+  GET /decks/{id}/draw?spread=three-card
+  GET /decks/{id}/cards/{card_id}/image?h=600
+  GET /decks/{id}/sessions/{session_id}?name=Ana   (WebSocket)
+  GET /spreads
+  GET /metrics
+  GET /openapi.json
+
+The deck endpoints answer in JSON: /decks lists the library's decks, and
+/decks/{id} describes a deck with its cards, their alt text and the paths of
+their images. The draw endpoint shuffles the deck's cards, or a pool of them,
+and draws count cards or deals a spread (listed by /spreads). Draws are not
+stored, so the same seed gives the same draw.
+
+With --static, a deck browser web UI is served at /, for looking through the
+decks and making draws in a browser. Its pages are served without a token;
+open it as /?token=<token> and it sends the token with its requests.
+
+The image endpoint returns the card's highest resolution image as a PNG,
+resized server-side to the requested height in pixels (never upscaled) and
+cached in memory. Responses carry an ETag and Cache-Control header, and
+requests with a matching If-None-Match are answered with 304 Not Modified.
+Decks are looked up by library directory name or deck ID; filesystem paths
+are not served.
+
+The session endpoint runs live readings shared by everyone who joins the same
+session ID. A session starts with a freshly shuffled deck and lives in memory
+until its last participant leaves. Clients send JSON messages:
+
+  {"type": "shuffle", "pool": "majors", "seed": 42}   (pool and seed optional)
+  {"type": "draw", "count": 3}
+  {"type": "draw", "spread": "celtic-cross"}
+
+and every participant receives JSON events: "state" on joining (including the
+cards revealed so far), "joined" and "left" as participants come and go,
+"shuffled", one "reveal" per card drawn, and "error" for failed requests.
+A draw reveals at most 22 cards, spreads included. Sessions take up to 32
+participants with names of up to 64 characters. The server pings idle
+participants and closes connections that stop answering.
+
+The OpenAPI endpoint describes the endpoints and their responses in an
+OpenAPI 3 document, for generating clients; gen openapi writes it to a file.
+
+With --grpc-addr, the same API is also served over gRPC on a second address,
+as described by api/cartomancer/v1/cartomancer.proto: listing decks and
+spreads, describing decks and cards, card images, draws and validation. Go
+programs can call it with the client in pkg/client. Calls are admitted like
+HTTP requests: tokens go in the "authorization" metadata as "Bearer <token>",
+and each client's rate limit is shared across both APIs.
+
+The metrics endpoint reports request counts and durations, image render
+durations, image cache hits, misses and size, deck load times and active
+sessions in the Prometheus text format.
+
+Before exposing the server publicly, restrict access in config.toml:
+
+  [serve]
+  token_env = "CARTOMANCER_TOKENS"  # Variable holding accepted tokens, comma-separated
+  rate_limit = 5                    # Requests per second per client
+  burst = 20                        # Requests allowed at once (default: rate_limit)
+  read_only = true                  # Refuse live sessions
+
+With tokens configured, clients must send "Authorization: Bearer <token>"
+(WebSocket clients may pass ?token=<token> to the session endpoint instead,
+which no other endpoint accepts). Clients are rate limited
+by token, or by address when they have none, and are answered with 429 Too
+Many Requests and a Retry-After header when over the limit. The flags below
+override the config.
+
+The server shuts down gracefully on SIGINT or SIGTERM, finishing requests in
+flight and ending live sessions. On SIGHUP it reloads config.toml and forgets
+the decks and images it has loaded, so updated decks are read afresh; custom
+pools and spreads are read at startup only.
+
+Examples:
+  cartomancer serve
+  cartomancer serve --static
+  cartomancer serve --addr 127.0.0.1:9000 --cache-size 128
+  cartomancer serve --grpc-addr 127.0.0.1:9090
+  CARTOMANCER_TOKENS=s3cret cartomancer serve --addr :8080 --token-env CARTOMANCER_TOKENS --rate-limit 5 --read-only`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		cacheSize, _ := cmd.Flags().GetInt("cache-size")
+
+		if err := pool.LoadDir(config.GetPoolsDir()); err != nil {
+			return err
+		}
+		if err := spread.LoadDir(config.GetSpreadsDir()); err != nil {
+			return err
+		}
+
+		access, err := loadAccessPolicy(cmd)
+		if err != nil {
+			return err
+		}
+
+		s := newServer(int64(cacheSize)<<20, access)
+		s.static, _ = cmd.Flags().GetBool("static")
+		srv := &http.Server{
+			Addr:              addr,
+			Handler:           s.routes(),
+			ReadHeaderTimeout: readHeaderTimeout,
+			ReadTimeout:       readTimeout,
+			IdleTimeout:       idleTimeout,
+		}
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(signals)
+
+		errs := make(chan error, 2)
+		go func() {
+			errs <- srv.ListenAndServe()
+		}()
+		fmt.Fprintf(os.Stderr, "Serving %s on http://%s\n", config.GetDeckLibraryPath(), addr)
+		if s.static {
+			fmt.Fprintf(os.Stderr, "Deck browser at http://%s/\n", addr)
+		}
+
+		var grpcSrv *grpc.Server
+		if grpcAddr, _ := cmd.Flags().GetString("grpc-addr"); grpcAddr != "" {
+			lis, err := net.Listen("tcp", grpcAddr)
+			if err != nil {
+				srv.Close()
+				return err
+			}
+			grpcSrv = newGRPCServer(s)
+			go func() {
+				errs <- grpcSrv.Serve(lis)
+			}()
+			fmt.Fprintf(os.Stderr, "Serving the gRPC API on %s\n", lis.Addr())
+		}
+
+		for {
+			select {
+			case err := <-errs:
+				return err
+			case sig := <-signals:
+				if sig == syscall.SIGHUP {
+					if err := s.reload(cmd); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: reload failed, keeping the previous configuration: %v\n", err)
+					} else {
+						fmt.Fprintln(os.Stderr, "Reloaded configuration and deck library")
+					}
+					continue
+				}
+
+				timeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+				fmt.Fprintf(os.Stderr, "Shutting down (waiting up to %s for requests to finish)\n", timeout)
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+				if grpcSrv != nil {
+					stopGRPC(ctx, grpcSrv)
+				}
+				if err := srv.Shutdown(ctx); err != nil {
+					return fmt.Errorf("error shutting down: %v", err)
+				}
+				return s.endSessions(ctx)
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().String("grpc-addr", "", "Address to serve the gRPC API on, if any")
+	serveCmd.Flags().Int("cache-size", 64, "Memory for cached resized images, in MiB")
+	serveCmd.Flags().String("token-env", "", "Environment variable holding accepted tokens, comma-separated (default from config)")
+	serveCmd.Flags().Float64("rate-limit", 0, "Requests per second allowed per client, 0 for no limit (default from config)")
+	serveCmd.Flags().Int("burst", 0, "Requests a client may make at once (default from config, or the rate limit)")
+	serveCmd.Flags().Bool("read-only", false, "Refuse live sessions and other requests that change state")
+	serveCmd.Flags().Bool("static", false, "Serve the deck browser web UI at /")
+	serveCmd.Flags().Duration("shutdown-timeout", 10*time.Second, "How long to wait for requests in flight when shutting down")
+}
+
+// server serves decks from the deck library over HTTP
+type server struct {
+	mu       sync.Mutex
+	decks    map[string]*deck.Deck
+	images   *byteCache
+	renders  singleflight.Group[*cacheEntry] // Image renders in flight, by cache key
+	sessions *session.Hub
+	metrics  *serverMetrics
+	access   atomic.Pointer[accessPolicy]
+	static   bool // Serve the deck browser web UI
+
+	// WebSocket connections are hijacked, so the HTTP server does not wait
+	// for them on shutdown
+	sessionConns sync.WaitGroup
+}
+
+// serverMetrics are the metrics reported on /metrics
+type serverMetrics struct {
+	registry        *metrics.Registry
+	requests        *metrics.Counter
+	requestDuration *metrics.Histogram
+	renderDuration  *metrics.Histogram
+	cacheHits       *metrics.Counter
+	cacheMisses     *metrics.Counter
+	deckLoad        *metrics.Histogram
+}
+
+// newServerMetrics registers the server's metrics
+func newServerMetrics(s *server) *serverMetrics {
+	r := metrics.NewRegistry()
+	m := &serverMetrics{
+		registry: r,
+		requests: r.NewCounter("cartomancer_http_requests_total",
+			"HTTP requests handled, by route and status code.", "route", "code"),
+		requestDuration: r.NewHistogram("cartomancer_http_request_duration_seconds",
+			"Time taken to handle HTTP requests, by route.", metrics.DefaultBuckets, "route"),
+		renderDuration: r.NewHistogram("cartomancer_image_render_duration_seconds",
+			"Time taken to load, resize and encode card images.", metrics.DefaultBuckets),
+		cacheHits: r.NewCounter("cartomancer_image_cache_hits_total",
+			"Card image requests answered from the image cache."),
+		cacheMisses: r.NewCounter("cartomancer_image_cache_misses_total",
+			"Card image requests that had to render the image."),
+		deckLoad: r.NewHistogram("cartomancer_deck_load_duration_seconds",
+			"Time taken to load decks from the deck library.", metrics.DefaultBuckets),
+	}
+	r.NewGaugeFunc("cartomancer_image_cache_bytes", "Size of the encoded images in the image cache.",
+		func() float64 { return float64(s.images.bytes()) })
+	r.NewGaugeFunc("cartomancer_decks_loaded", "Decks loaded into memory.",
+		func() float64 {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return float64(len(s.decks))
+		})
+	r.NewGaugeFunc("cartomancer_sessions_active", "Live reading sessions.",
+		func() float64 { return float64(s.sessions.Len()) })
+	return m
+}
+
+// newServer returns a server caching up to cacheBytes of encoded images and
+// accepting the requests allowed by access
+func newServer(cacheBytes int64, access *accessPolicy) *server {
+	s := &server{
+		decks:    map[string]*deck.Deck{},
+		images:   newByteCache(cacheBytes),
+		sessions: session.NewHub(),
+	}
+	s.access.Store(access)
+	s.metrics = newServerMetrics(s)
+	return s
+}
+
+// routes returns the server's request handler
+func (s *server) routes() http.Handler {
+	api := http.NewServeMux()
+	api.HandleFunc("GET /decks", s.handleDecks)
+	api.HandleFunc("GET /decks/{id}", s.handleDeck)
+	api.HandleFunc("GET /decks/{id}/draw", s.handleDraw)
+	api.HandleFunc("GET /decks/{id}/cards/{card_id}/image", s.handleCardImage)
+	api.HandleFunc(sessionRoute, s.handleSession)
+	api.HandleFunc("GET /spreads", s.handleSpreads)
+	api.HandleFunc("GET /metrics", s.handleMetrics)
+	api.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	protected := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := api.Handler(r)
+		if s.access.Load().allow(w, r, route == sessionRoute) {
+			api.ServeHTTP(w, r)
+		}
+	})
+	if !s.static {
+		return s.instrument(protected)
+	}
+
+	// The web UI's files hold no deck data, so they are served to anyone and
+	// the page asks for a token when the API needs one
+	mux := http.NewServeMux()
+	mux.Handle("/", protected)
+	mux.Handle("GET /{$}", webui.Handler())
+	mux.Handle("GET /app.js", webui.Handler())
+	mux.Handle("GET /style.css", webui.Handler())
+	return s.instrument(mux)
+}
+
+// stopGRPC stops a gRPC server after the calls in flight finish, cutting
+// them off when ctx is done
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+}
+
+// endSessions ends the live sessions and waits for their connections to close
+func (s *server) endSessions(ctx context.Context) error {
+	s.sessions.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.sessionConns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error shutting down: timed out waiting for sessions to end")
+	}
+}
+
+// reload rereads config.toml and drops the loaded decks and cached images,
+// so later requests see the current deck library. Live sessions keep the
+// deck they started with.
+func (s *server) reload(cmd *cobra.Command) error {
+	access, err := loadAccessPolicy(cmd)
+	if err != nil {
+		return err
+	}
+	s.access.Store(access)
+
+	s.mu.Lock()
+	s.decks = map[string]*deck.Deck{}
+	s.mu.Unlock()
+	s.images.clear()
+	return nil
+}
+
+// instrument counts and times the requests handled by h
+func (s *server) instrument(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		// The mux records the matched pattern, which keeps label values bounded
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		s.metrics.requests.Inc(route, strconv.Itoa(rec.status))
+		s.metrics.requestDuration.Observe(time.Since(start).Seconds(), route)
+	})
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hands the connection over for WebSocket upgrades
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
+
+// handleMetrics writes the server's metrics in the Prometheus text format
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.registry.Write(w)
+}
+
+// deck returns a library deck by directory name or deck ID, loading it on first use
+func (s *server) deck(id string) (*deck.Deck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if d, ok := s.decks[id]; ok {
+		return d, nil
+	}
+
+	// IDs come from URLs, where %2F decodes to a slash, so only single path
+	// elements naming decks inside the library are looked up
+	if id == "" || deck.CheckID(id) != nil {
+		return nil, fmt.Errorf("%w: %s", deck.ErrDeckNotFound, id)
+	}
+	res, err := config.ResolveDeck(id)
+	if err != nil || res.Source == "path" || !deck.Contains(config.GetDeckLibraryPath(), res.Path) {
+		return nil, fmt.Errorf("%w: %s", deck.ErrDeckNotFound, id)
+	}
+	start := time.Now()
+	d, err := deck.LoadDeck(res.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading deck: %w", err)
+	}
+	s.metrics.deckLoad.Observe(time.Since(start).Seconds())
+
+	s.decks[id] = d
+	return d, nil
+}
+
+// handleCardImage streams a card image, resized to the height given by the h parameter
+func (s *server) handleCardImage(w http.ResponseWriter, r *http.Request) {
+	height := 0
+	if h := r.URL.Query().Get("h"); h != "" {
+		var err error
+		if height, err = strconv.Atoi(h); err != nil || height < 1 {
+			writeError(w, errImageHeight)
+			return
+		}
+	}
+
+	entry, err := s.cardImage(r.PathValue("id"), r.PathValue("card_id"), height)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("ETag", entry.etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(imageMaxAge.Seconds())))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(entry.data))
+}
+
+// errImageHeight refuses card images of unsupported heights
+var errImageHeight = &apiError{http.StatusBadRequest,
+	fmt.Sprintf("h must be a height between 1 and %d", maxImageHeight)}
+
+// cardImage returns a card of a library deck as an encoded image, resized
+// to a height in pixels or at full size for 0, from the image cache when it
+// was rendered before
+func (s *server) cardImage(id, cardID string, height int) (*cacheEntry, error) {
+	if height < 0 || height > maxImageHeight {
+		return nil, errImageHeight
+	}
+	d, err := s.deck(id)
+	if err != nil {
+		return nil, err
+	}
+	c, err := d.GetCard(cardID)
+	if err != nil {
+		return nil, &apiError{http.StatusNotFound, fmt.Sprintf("card not found: %s", cardID)}
+	}
+
+	key := fmt.Sprintf("%s|%s|%d", d.Path, c.ID, height)
+	if entry, ok := s.images.get(key); ok {
+		s.metrics.cacheHits.Inc()
+		return entry, nil
+	}
+	s.metrics.cacheMisses.Inc()
+	entry, err, _ := s.renders.Do(key, func() (*cacheEntry, error) {
+		return s.renderImage(d, c, height, key)
+	})
+	var tooLarge *imageload.TooLargeError
+	if errors.As(err, &tooLarge) {
+		return nil, &apiError{http.StatusInternalServerError, err.Error()}
+	} else if err != nil {
+		return nil, &apiError{http.StatusNotFound, fmt.Sprintf("no image for card: %s", c.ID)}
+	}
+	return entry, nil
+}
+
+// deckErrorStatus returns the HTTP status for an error loading a library
+// deck: decks that exist but cannot be loaded are the server's problem
+func deckErrorStatus(err error) int {
+	if errors.Is(err, deck.ErrDeckNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// renderImage renders and caches a card image at a height, 0 for full size.
+// Concurrent requests for the same image share one render through s.renders.
+func (s *server) renderImage(d *deck.Deck, c *card.Card, height int, key string) (*cacheEntry, error) {
+	start := time.Now()
+	img, err := loadViewImage(d.AssetRoots(), c, height)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := newImageEntry(img)
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.renderDuration.Observe(time.Since(start).Seconds())
+	s.images.add(key, entry)
+	return entry, nil
+}
+
+// sessionRequest is a message from a session participant
+type sessionRequest struct {
+	Type   string `json:"type"`
+	Pool   string `json:"pool"`
+	Seed   int64  `json:"seed"`
+	Count  int    `json:"count"`
+	Spread string `json:"spread"`
+}
+
+// handleSession joins a live reading session over a WebSocket connection
+func (s *server) handleSession(w http.ResponseWriter, r *http.Request) {
+	if s.access.Load().readOnly {
+		http.Error(w, "server is read-only: live sessions are disabled", http.StatusForbidden)
+		return
+	}
+
+	d, err := s.deck(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.sessionConns.Add(1)
+	defer s.sessionConns.Done()
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "guest"
+	}
+	sess, p, err := s.sessions.Join(r.PathValue("session_id"), d, name)
+	if err != nil {
+		writeSessionEvent(conn, session.Event{Type: session.EventError, Error: err.Error()})
+		return
+	}
+	defer sess.Leave(p)
+
+	// Deliver events until the participant leaves or is dropped, pinging
+	// them in between so the read timeout only closes dead connections
+	conn.SetReadTimeout(sessionReadTimeout)
+	go func() {
+		defer conn.Close()
+		ping := time.NewTicker(sessionPingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case e, ok := <-p.Events:
+				if !ok || writeSessionEvent(conn, e) != nil {
+					return
+				}
+			case <-ping.C:
+				if conn.Ping() != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req sessionRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			writeSessionEvent(conn, session.Event{Type: session.EventError, Error: fmt.Sprintf("invalid request: %v", err)})
+			continue
+		}
+
+		switch req.Type {
+		case "shuffle":
+			sess.Shuffle(p, req.Pool, req.Seed)
+		case "draw":
+			sess.Draw(p, req.Count, req.Spread)
+		default:
+			writeSessionEvent(conn, session.Event{Type: session.EventError,
+				Error: fmt.Sprintf("unknown request type: %q (expected shuffle or draw)", req.Type)})
+		}
+	}
+}
+
+// writeSessionEvent sends an event as a JSON message
+func writeSessionEvent(conn *websocket.Conn, e session.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(data)
+}
+
+// newImageEntry encodes an image as PNG for the image cache, tagging it with a
+// hash of its content
+func newImageEntry(img image.Image) (*cacheEntry, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error encoding image: %v", err)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return &cacheEntry{
+		data: buf.Bytes(),
+		etag: `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+// testLibrary sets up a deck library holding the fixture deck as "fixture",
+// and a copy of it outside the library at ../../../private relative to the
+// library, returning the library's path
+func testLibrary(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	library := filepath.Join(root, "data", "tarot", "decks")
+	if err := os.MkdirAll(library, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(testutil.FixtureDeck(t), filepath.Join(library, "fixture")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(testutil.FixtureDeck(t), filepath.Join(root, "private")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	return library
+}
+
+func TestServeRefusesDecksOutsideLibrary(t *testing.T) {
+	testLibrary(t)
+	srv := httptest.NewServer(newServer(1<<20, &accessPolicy{}).routes())
+	defer srv.Close()
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/decks/fixture", http.StatusOK},
+		{"/decks/fixture/cards/major_arcana.00/image?h=10", http.StatusOK},
+		{"/decks/..%2F..%2F..%2Fprivate", http.StatusNotFound},
+		{"/decks/..%2F..%2F..%2Fprivate/cards/major_arcana.00/image", http.StatusNotFound},
+		{"/decks/..%2F..%2F..%2Fprivate/draw", http.StatusNotFound},
+		{"/decks/fixture%2F..%2F..%2F..%2F..%2Fprivate", http.StatusNotFound},
+		{"/decks/..", http.StatusNotFound},
+		{"/decks/%2E%2E", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestServeQueryTokenOnlyForSessions(t *testing.T) {
+	testLibrary(t)
+	srv := httptest.NewServer(newServer(1<<20, &accessPolicy{tokens: []string{"s3cret"}}).routes())
+	defer srv.Close()
+
+	tests := []struct {
+		path   string
+		header string
+		want   int
+	}{
+		{"/decks", "Bearer s3cret", http.StatusOK},
+		{"/decks?token=s3cret", "", http.StatusUnauthorized},
+		{"/decks/fixture/cards/major_arcana.00/image?token=s3cret", "", http.StatusUnauthorized},
+		// Past the token check, the session endpoint wants a WebSocket upgrade
+		{"/decks/fixture/sessions/s1?token=s3cret", "", http.StatusBadRequest},
+		{"/decks/fixture/sessions/s1?token=wrong", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"crypto/md5"
+	"errors"
 	"fmt"
-	"image"
-	"image/color" // This is the standard library color package
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
@@ -12,92 +11,244 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/lucasb-eyer/go-colorful"
-	"github.com/nfnt/resize"
 	"golang.org/x/term"
 
+	"github.com/arcanaland/cartomancer/internal/atomicfile"
 	"github.com/arcanaland/cartomancer/internal/card"
 	"github.com/arcanaland/cartomancer/internal/config"
 	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/notes"
+	"github.com/arcanaland/cartomancer/internal/singleflight"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/pkg/render"
 
-	colorize "github.com/fatih/color" // Rename this import to avoid the conflict
 	"github.com/spf13/cobra"
 )
 
 var showCmd = &cobra.Command{
-	Use:   "show [card_id]",
-	Short: "Display information about a specific card with ANSI art",
+	Use:   "show [card_id...]",
+	Short: "Display information about one or more cards with ANSI art",
 	Long: `Show displays detailed information about a tarot card with ANSI terminal art.
-Use canonical card IDs like 'major_arcana.00' or 'minor_arcana.wands.ace'.
+Use canonical card IDs like 'major_arcana.00' or 'minor_arcana.wands.ace', or a
+shorthand such as '0', 'XVII', 'wands.1' or 'cups/queen' (case-insensitive).
+Run without a card ID in a terminal to pick a card from a list grouped by
+suit, filtered as you type any of its names in the deck's languages.
 
 You can specify a deck using the --deck flag, which will look for the deck
 in your deck library (XDG_DATA_HOME/tarot/decks) or as a relative path.
 If no deck is specified, the default deck from your config will be used.
 
+The info panel fields and their order can be chosen with --fields or the
+show_fields setting in config.toml. Available fields: name, deck, id, type,
+number, suit, rank, element, keywords, meanings, correspondences, description,
+upright, reversed, note, warnings, artist, source and license. Keywords,
+upright and reversed are the deck author's own interpretations from the deck's
+readings/<lang>.toml, left out for decks without one, and meanings shows both
+orientations together. Correspondences gives the card's element and, for the
+major arcana, its Golden Dawn attribution. Note is your own note on the card
+(see note --help). Use --credits to add the artist, source and license of the
+card art, as declared in the deck's [credits] table. Use --compact to print the fields on a single line
+without art, for embedding in prompts and status bars.
+
+Use --copy to place the fields on the clipboard as plain text, ready to paste
+into chats and journals, or --copy=image for the card image. Text is copied
+with the system's clipboard tool or, over SSH and where there is none, through
+the terminal (OSC 52); images need wl-copy or xclip on Linux, or macOS.
+
+Use --qr to print a QR code beneath the card linking to its page on the deck's
+website, for the publisher's full artwork or store page. Decks set the page
+address with a card_url pattern in the [deck] table of deck.toml, using the
+placeholders {id}, {slug}, {number}, {suit} and {rank}:
+
+  card_url = "https://example.com/cards/{slug}"
+
+Decks without one link to their website instead.
+
+Several cards can be shown at once; their art is laid out in a grid with the
+card names beneath, as many per row as fit the terminal or as set by --columns.
+The deck is only loaded once, which makes this the fast way to build galleries.
+
+Use --best-effort while a deck's art is still in progress: cards without an
+image are drawn as a placeholder block in the suit color with the card name,
+and the substituted cards are listed afterwards.
+
+Use --flip for a little ceremony: the card is dealt face down and turns over to
+reveal its face before the info panel is shown. Spreads revealed with --step
+flip each card the same way.
+
+Cards the deck flags with content warnings can have their art blurred or
+covered (see 'cartomancer deck info'). In safe mode, set with --safe-mode or
+safe_mode = true in config.toml, their art is never drawn: a single card shows
+its details only, and grids, spreads and boards show a blank card in its place.
+
 Examples:
+  cartomancer show
   cartomancer show major_arcana.00
+  cartomancer show XVII
+  cartomancer show cups/queen
   cartomancer show --deck rider-waite-smith minor_arcana.wands.ace
-  cartomancer show --deck ./custom-deck major_arcana.01`,
-	Args: cobra.ExactArgs(1),
+  cartomancer show --deck ./custom-deck major_arcana.01
+  cartomancer show --fields name,number,description XVII
+  cartomancer show --compact --fields name,id 0
+  cartomancer show XVII --copy
+  cartomancer show cups/queen --copy=image
+  cartomancer show XVII --qr
+  cartomancer show 0 1 2 cups/queen --columns 2
+  cartomancer show --flip XVI`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cardID := args[0]
+		// IDs the standard notation rejects are left for the deck to resolve,
+		// as they may name a card of one of its custom suits
+		cardIDs := make([]string, len(args))
+		for i, arg := range args {
+			cardIDs[i] = arg
+			if id, err := card.ParseID(arg); err == nil {
+				cardIDs[i] = id
+			}
+		}
 
 		// Get deck flag value
 		deckFlag, _ := cmd.Flags().GetString("deck")
 
-		var deckPath string
-		var err error
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
 
-		if deckFlag != "" {
-			// User specified a deck
-			deckPath, err = config.GetDeckPath(deckFlag)
+		// Load the deck
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		variant, _ := cmd.Flags().GetString("variant")
+		if err := d.UseVariant(variant); err != nil {
+			return err
+		}
+
+		// Without a card ID, let the user pick one
+		if len(cardIDs) == 0 {
+			t, err := loadTheme(cmd)
 			if err != nil {
 				return err
 			}
-		} else {
-			// Use default deck from config
-			defaultDeck, err := config.GetDefaultDeck()
-			if err != nil {
-				return fmt.Errorf("error getting default deck: %v", err)
+			c, err := pickCard(d, t)
+			if err != nil || c == nil {
+				return err
 			}
+			cardIDs = []string{c.ID}
+		}
 
-			deckPath, err = config.GetDeckPath(defaultDeck)
-			if err != nil {
-				return fmt.Errorf("error loading default deck: %v", err)
-			}
+		cards, err := cardsByID(d, cardIDs)
+		if err != nil {
+			return err
 		}
 
-		// Check if path exists
-		if _, err := os.Stat(deckPath); os.IsNotExist(err) {
-			return fmt.Errorf("deck directory not found: %s", deckPath)
+		var pageURL string
+		if showQR, _ := cmd.Flags().GetBool("qr"); showQR {
+			if len(cards) > 1 {
+				return fmt.Errorf("--qr links to a single card")
+			}
+			if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+				return fmt.Errorf("--qr cannot be combined with --interactive")
+			}
+			if pageURL, err = cardPageURL(d, cards[0]); err != nil {
+				return err
+			}
 		}
 
-		// Load the deck
-		d, err := deck.LoadDeck(deckPath)
+		opts, err := loadDisplayOptions(cmd, d.Name)
 		if err != nil {
-			return fmt.Errorf("error loading deck: %v", err)
+			return err
+		}
+		opts.DeckLicense = d.License
+		if opts.Readings, err = d.Readings(); err != nil {
+			return err
+		}
+		if opts.Notes, err = notes.NewStore(config.GetNotesDir()).ForDeck(d.ID); err != nil {
+			return err
 		}
 
-		// Get the card
-		c, err := d.GetCard(cardID)
-		if err != nil {
-			return fmt.Errorf("error getting card: %v", err)
+		if credits, _ := cmd.Flags().GetBool("credits"); credits {
+			for _, field := range creditFields {
+				if !contains(opts.Fields, field) {
+					opts.Fields = append(opts.Fields, field)
+				}
+			}
 		}
 
-		// Get the ANSI art
-		ansiPath, err := findAnsiFile(deckPath, cardID)
+		copying, err := copyMode(cmd)
 		if err != nil {
-			return fmt.Errorf("error finding ANSI art: %v", err)
+			return err
+		}
+		if copying != "" {
+			summary, err := plainSummary(cards, opts)
+			if err != nil {
+				return err
+			}
+			if err := copyResult(copying, summary, d.AssetRoots(), cards); err != nil {
+				return err
+			}
 		}
 
-		ansiArt, err := loadAnsiArt(ansiPath)
-		if err != nil {
-			return fmt.Errorf("error loading ANSI art: %v", err)
+		// Compact mode prints the fields on one line per card without art
+		if compact, _ := cmd.Flags().GetBool("compact"); compact {
+			for _, c := range cards {
+				line, err := compactLine(c, opts)
+				if err != nil {
+					return err
+				}
+				fmt.Println(line)
+			}
+			if pageURL != "" {
+				return printQR(pageURL)
+			}
+			return nil
+		}
+
+		defer opts.Placeholders.printSummary()
+
+		if len(cards) > 1 {
+			if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+				return fmt.Errorf("--interactive shows a single card")
+			}
+			if flip, _ := cmd.Flags().GetBool("flip"); flip {
+				return fmt.Errorf("--flip shows a single card")
+			}
+			columns, _ := cmd.Flags().GetInt("columns")
+			return showCardGrid(d, cards, opts, columns)
 		}
 
-		// Display the card info with ANSI art
-		displayCard(c, ansiArt, d.Name)
+		c := cards[0]
+		opts.Theme = styledTheme(opts.Theme, d.StyleFor(c))
 
+		if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+			if withholdsArt(c) {
+				return fmt.Errorf("the art of %s is withheld in safe mode (%s)", c.Name, strings.Join(c.ContentWarnings, ", "))
+			}
+			img, err := loadViewImage(d.AssetRoots(), c, 0)
+			if err != nil {
+				placeholder, ok := opts.Placeholders.image(c, placeholderWidth, placeholderHeight)
+				if !ok {
+					return err
+				}
+				img = placeholder
+			}
+			return newViewer(img, c.Name, opts.Theme).run()
+		}
+
+		if flip, _ := cmd.Flags().GetBool("flip"); flip {
+			if err := playFlip(d, c, opts.Placeholders); err != nil {
+				return err
+			}
+		}
+
+		if err := showCardArt(d.AssetRoots(), c, opts); err != nil {
+			return err
+		}
+		if pageURL != "" {
+			return printQR(pageURL)
+		}
 		return nil
 	},
 }
@@ -106,56 +257,297 @@ func init() {
 	RootCmd.AddCommand(showCmd)
 
 	showCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	showCmd.Flags().String("numbering", "", "Major arcana numbering style: arabic, padded or roman (default from config)")
+	showCmd.Flags().StringSlice("fields", nil, "Comma-separated info panel fields in display order (default from config)")
+	showCmd.Flags().Bool("compact", false, "Print the selected fields on a single line without art")
+	showCmd.Flags().Bool("credits", false, "Show the artist, source and license of the card art")
+	addFrameFlag(showCmd)
+	showCmd.Flags().BoolP("interactive", "i", false, "Open the card's highest resolution image in a zoom and pan viewer")
+	showCmd.Flags().Bool("flip", false, "Turn the card over from its back with a short animation before showing it")
+	showCmd.Flags().String("variant", "", "Use a deck variant whose overlay images replace the base art")
+	addCopyFlag(showCmd)
+	showCmd.Flags().Bool("qr", false, "Print a QR code linking to the card's page on the deck's website")
+	addBestEffortFlag(showCmd)
+	showCmd.Flags().Int("columns", 0, "Cards per row when showing several cards (default: as many as fit)")
 }
 
-// findAnsiFile finds the path to the ANSI art file for a card
-func findAnsiFile(deckPath, cardID string) (string, error) {
-	// Parse the card ID
-	parts := strings.Split(cardID, ".")
-	if len(parts) < 2 {
-		return "", fmt.Errorf("invalid card ID format: %s", cardID)
+// loadDisplayOptions resolves the numbering style, info panel fields and theme
+// from the command flags, falling back to config
+func loadDisplayOptions(cmd *cobra.Command, deckName string) (displayOptions, error) {
+	opts := displayOptions{DeckName: deckName}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return opts, fmt.Errorf("error loading config: %v", err)
 	}
 
-	// First try to find existing ANSI art
-	var ansiPath string
-	ansiFound := false
+	opts.Numbering, _ = cmd.Flags().GetString("numbering")
+	if opts.Numbering == "" {
+		opts.Numbering = cfg.Numbering
+	}
 
-	// Check for ansi32 directory first
-	ansiDir := filepath.Join(deckPath, "ansi32")
-	if _, err := os.Stat(ansiDir); !os.IsNotExist(err) {
-		if path, err := buildCardPath(ansiDir, parts, ".ansi"); err == nil {
-			if _, err := os.Stat(path); !os.IsNotExist(err) {
-				ansiPath = path
-				ansiFound = true
+	opts.Fields, _ = cmd.Flags().GetStringSlice("fields")
+	if len(opts.Fields) == 0 {
+		opts.Fields = cfg.ShowFields
+	}
+	if len(opts.Fields) == 0 {
+		opts.Fields = defaultFields
+	}
+	if err := validateFields(opts.Fields); err != nil {
+		return opts, err
+	}
+
+	opts.Theme, err = loadTheme(cmd)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Frame, err = frameStyle(cmd)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Placeholders = newPlaceholders(cmd)
+
+	return opts, nil
+}
+
+// styledTheme applies a deck's accent color and border style for a card to the theme
+func styledTheme(t *theme.Theme, style deck.CardStyle) *theme.Theme {
+	if accent, ok := style.AccentColor(); ok {
+		t = t.WithAccent(accent.R, accent.G, accent.B)
+	}
+	if style.Border != "" {
+		t = t.WithBorder(style.Border)
+	}
+	return t
+}
+
+// showCardArt displays a card's ANSI art, looked up in the given asset roots,
+// alongside its info panel. In best-effort mode a card without art is shown
+// with placeholder art.
+func showCardArt(roots []string, c *card.Card, opts displayOptions) error {
+	// Safe mode shows only the details of cards with content warnings
+	if withholdsArt(c) {
+		opts.Frame = ""
+		return displayCard(c, "", opts)
+	}
+
+	ansiArt, err := cardAnsiArt(roots, c)
+	if err != nil {
+		placeholder, ok := opts.Placeholders.image(c, placeholderWidth, placeholderHeight)
+		if !ok {
+			return err
+		}
+		if ansiArt, err = render.RenderANSI(placeholder, viewOptions(render.DefaultOptions())); err != nil {
+			return fmt.Errorf("error rendering placeholder art: %v", err)
+		}
+	}
+
+	// Display the card info with ANSI art
+	return displayCard(c, ansiArt, opts)
+}
+
+// showCardGrid displays the ANSI art of several cards in rows, each captioned
+// with the card name in the card's accent color
+func showCardGrid(d *deck.Deck, cards []*card.Card, opts displayOptions, columns int) error {
+	decks := make([]*deck.Deck, len(cards))
+	for i := range decks {
+		decks[i] = d
+	}
+	return showMixedGrid(decks, cards, opts, columns)
+}
+
+// showMixedGrid displays a grid like showCardGrid of cards from several decks,
+// each drawn with the art and style of the deck at the same index in decks
+func showMixedGrid(decks []*deck.Deck, cards []*card.Card, opts displayOptions, columns int) error {
+	cells := make([][]string, len(cards))
+	cellWidth := 0
+	for i, c := range cards {
+		d := decks[i]
+		t := styledTheme(opts.Theme, d.StyleFor(c))
+
+		art, err := cardAnsiArt(d.AssetRoots(), c)
+		if err != nil {
+			placeholder, ok := opts.Placeholders.image(c, placeholderWidth, placeholderHeight)
+			if !ok {
+				return fmt.Errorf("%s: %v", c.Name, err)
+			}
+			if art, err = render.RenderANSI(placeholder, viewOptions(render.DefaultOptions())); err != nil {
+				return fmt.Errorf("error rendering placeholder art: %v", err)
 			}
 		}
+
+		lines := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+		if opts.Frame != "" {
+			lines = frameArt(lines, c.Name, opts.Frame, t)
+		}
+		for _, line := range lines {
+			cellWidth = max(cellWidth, visibleWidth(line))
+		}
+		cells[i] = lines
 	}
 
-	// Check for ansi256 directory if ansi32 wasn't found
-	if !ansiFound {
-		ansiDir = filepath.Join(deckPath, "ansi256")
-		if _, err := os.Stat(ansiDir); !os.IsNotExist(err) {
-			if path, err := buildCardPath(ansiDir, parts, ".ansi"); err == nil {
-				if _, err := os.Stat(path); !os.IsNotExist(err) {
-					ansiPath = path
-					ansiFound = true
-				}
+	// Caption each cell with the card name, truncated to the cell width
+	for i, c := range cards {
+		marker := favoriteMarker(opts.Theme, c)
+		width := cellWidth - visibleWidth(marker)
+		name := []rune(c.Name)
+		if len(name) > width {
+			name = append(name[:max(width-1, 0)], '…')
+		}
+		t := styledTheme(opts.Theme, decks[i].StyleFor(c))
+		cells[i] = append(cells[i], t.Value.Sprint(string(name))+marker)
+	}
+
+	const gap = "  "
+	if columns <= 0 {
+		width, _, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil || width <= 0 {
+			width = 80
+		}
+		columns = max((width-2+len(gap))/(cellWidth+len(gap)), 1)
+	}
+
+	fmt.Println()
+	for start := 0; start < len(cells); start += columns {
+		row := cells[start:min(start+columns, len(cells))]
+		for _, line := range joinColumns(row, gap) {
+			fmt.Println("  " + line)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// cardAnsiArt loads a card's ANSI art, looked up in the given asset roots.
+// Cards hidden by the content filter get their cover art instead.
+func cardAnsiArt(roots []string, c *card.Card) (string, error) {
+	var ansiArt string
+	if hidesArt(c) {
+		art, err := hiddenAnsiArt(roots, c)
+		if err != nil {
+			return "", err
+		}
+		ansiArt = art
+	} else {
+		ansiPath, err := findAnsiFile(roots, c.ID)
+		if err != nil {
+			return "", fmt.Errorf("error finding ANSI art: %v", err)
+		}
+		if ansiArt, err = loadAnsiArt(ansiPath); err != nil {
+			return "", fmt.Errorf("error loading ANSI art: %v", err)
+		}
+	}
+
+	// Shipped and cached art is recolored for the color vision profile
+	if transform := colorTransform(); transform != nil {
+		ansiArt = render.TransformANSI(ansiArt, transform)
+	}
+	return ansiArt, nil
+}
+
+// deckLoadError explains an error from deck.LoadDeck, with a hint for the
+// kinds of problems users can fix themselves
+func deckLoadError(deckPath string, err error) error {
+	switch {
+	case errors.Is(err, deck.ErrDeckNotFound):
+		return fmt.Errorf("error loading deck: %w\nlist installed decks with 'cartomancer deck list'", err)
+	case errors.Is(err, deck.ErrInvalidDeck):
+		return fmt.Errorf("error loading deck: %w\nrun 'cartomancer validate %s' for details", err, deckPath)
+	case errors.Is(err, deck.ErrNoNames):
+		return fmt.Errorf("error loading deck: %w\nfix the language files in %s", err, filepath.Join(deckPath, "names"))
+	}
+	return fmt.Errorf("error loading deck: %w", err)
+}
+
+// resolveDeckPath returns the path of the deck named by the --deck flag, falling
+// back to the default deck from config when the flag is empty
+func resolveDeckPath(deckFlag string) (string, error) {
+	var deckPath string
+	var err error
+
+	if deckFlag != "" {
+		// User specified a deck
+		if err := checkKioskDeck(deckFlag); err != nil {
+			return "", err
+		}
+		deckPath, err = config.GetDeckPath(deckFlag)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		// Use default deck from config
+		defaultDeck, err := config.GetDefaultDeck()
+		if err != nil {
+			return "", fmt.Errorf("error getting default deck: %v", err)
+		}
+
+		deckPath, err = config.GetDeckPath(defaultDeck)
+		if err != nil {
+			if suggestion := config.SuggestDeck(defaultDeck); suggestion != "" {
+				return "", fmt.Errorf("default_deck %q in %s was not found (did you mean %q?)",
+					defaultDeck, config.GetConfigFilePath(), suggestion)
 			}
+			return "", fmt.Errorf("default_deck %q in %s was not found; set it with 'cartomancer deck set-default'",
+				defaultDeck, config.GetConfigFilePath())
 		}
 	}
 
-	// If ANSI art was found, return it
-	if ansiFound {
-		return ansiPath, nil
+	// Check if path exists
+	if _, err := os.Stat(deckPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("deck directory not found: %s", deckPath)
 	}
 
-	// No ANSI art found, look for image files to convert
-	imagePath, err := findCardImage(deckPath, parts)
-	if err != nil {
-		return "", fmt.Errorf("no ANSI art or convertible images found for card: %s", cardID)
+	return deckPath, nil
+}
+
+// findAnsiFile finds the path to the ANSI art file for a card, searching each
+// asset root in order. Within a root, prebuilt ANSI art is preferred over
+// converting an image, so a variant overlay image wins over base deck ANSI art.
+func findAnsiFile(roots []string, cardID string) (string, error) {
+	// Parse the card ID
+	parts := strings.Split(cardID, ".")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("invalid card ID format: %s", cardID)
+	}
+
+	for _, root := range roots {
+		if ansiPath, ok := findPrebuiltAnsi(root, parts); ok {
+			return ansiPath, nil
+		}
+
+		// No ANSI art found, look for image files to convert
+		if imagePath, err := findCardImage(root, parts); err == nil {
+			return cachedAnsiArt(imagePath)
+		}
 	}
 
-	// Generate ANSI art from the image
+	return "", fmt.Errorf("no ANSI art or convertible images found for card: %s", cardID)
+}
+
+// findPrebuiltAnsi looks for ANSI art shipped with the deck in ansi32 or ansi256
+func findPrebuiltAnsi(deckPath string, parts []string) (string, bool) {
+	for _, dir := range []string{"ansi32", "ansi256"} {
+		ansiDir := filepath.Join(deckPath, dir)
+		if _, err := os.Stat(ansiDir); os.IsNotExist(err) {
+			continue
+		}
+		if path, err := buildCardPath(ansiDir, parts, ".ansi"); err == nil {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ansiRenders deduplicates concurrent renders of the same cached ANSI art
+var ansiRenders singleflight.Group[struct{}]
+
+// cachedAnsiArt returns the cached ANSI art for an image, generating it on first use
+func cachedAnsiArt(imagePath string) (string, error) {
 	cacheDir := filepath.Join(config.GetCacheDir(), "ansi_cache")
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create ANSI cache directory: %v", err)
@@ -170,8 +562,11 @@ func findAnsiFile(deckPath, cardID string) (string, error) {
 		return cachePath, nil
 	}
 
-	// Generate new ANSI art
-	if err := generateAnsiArt(imagePath, cachePath); err != nil {
+	// Generate new ANSI art, once however many goroutines want the same card
+	_, err, _ := ansiRenders.Do(cachePath, func() (struct{}, error) {
+		return struct{}{}, generateAnsiArt(imagePath, cachePath)
+	})
+	if err != nil {
 		return "", fmt.Errorf("failed to generate ANSI art: %v", err)
 	}
 
@@ -197,8 +592,25 @@ func buildCardPath(baseDir string, parts []string, extension string) (string, er
 	return "", fmt.Errorf("invalid card ID format: %s", strings.Join(parts, "."))
 }
 
-// findCardImage searches for an image file for the given card in various directories
+// findCardImage searches for an image file for the given card in various
+// directories, falling back to generated placeholder art
 func findCardImage(deckPath string, parts []string) (string, error) {
+	if path, err := findCardArt(deckPath, parts); err == nil {
+		return path, nil
+	}
+
+	if path, err := buildCardPath(filepath.Join(deckPath, deck.PlaceholderDir), parts, ".png"); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("no image found for card")
+}
+
+// findCardArt searches for an image file for the given card in various
+// directories, ignoring placeholder art
+func findCardArt(deckPath string, parts []string) (string, error) {
 	// Priority order: scalable (SVG), h2400, h1200, h750, any other directories with images
 	imageDirs := []string{
 		"scalable",
@@ -240,7 +652,7 @@ func findCardImage(deckPath string, parts []string) (string, error) {
 		// Skip already checked directories
 		dirName := entry.Name()
 		if dirName == "ansi32" || dirName == "ansi256" || dirName == "card_backs" ||
-			dirName == "names" || contains(imageDirs, dirName) {
+			dirName == "names" || dirName == deck.PlaceholderDir || contains(imageDirs, dirName) {
 			continue
 		}
 
@@ -268,126 +680,32 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+// ansiSourceHeight is the height images are scaled down to before rendering
+// ANSI art, well above the resolution of any terminal art
+const ansiSourceHeight = 512
+
 // generateAnsiArt converts an image file to ANSI art and saves it to the specified output path
 func generateAnsiArt(imagePath, outputPath string) error {
-	// Open the image file
-	file, err := os.Open(imagePath)
-	if err != nil {
-		return fmt.Errorf("failed to open image: %v", err)
-	}
-	defer file.Close()
-
-	// Decode the image
-	img, _, err := image.Decode(file)
+	// Decode the image, scaled down to what the art can show
+	img, err := decodeScaledImageFile(imagePath, ansiSourceHeight)
 	if err != nil {
 		return fmt.Errorf("failed to decode image: %v", err)
 	}
 
 	// Generate ANSI art
-	ansiArt, err := imageToAnsi(img, 40, 32, true)
+	ansiArt, err := render.RenderANSI(img, render.DefaultOptions())
 	if err != nil {
 		return fmt.Errorf("failed to convert image to ANSI: %v", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(outputPath, []byte(ansiArt), 0644); err != nil {
+	// Write to file atomically, as other processes may render the same card
+	if err := atomicfile.WriteFile(outputPath, []byte(ansiArt), 0644); err != nil {
 		return fmt.Errorf("failed to write ANSI art to file: %v", err)
 	}
 
 	return nil
 }
 
-// imageToAnsi converts an image to ANSI art
-func imageToAnsi(img image.Image, width, height int, use256Colors bool) (string, error) {
-	// Resize image to desired dimensions (doubled for half-block characters)
-	resized := resize.Resize(uint(width*2), uint(height*2), img, resize.Lanczos3)
-
-	// Create a buffer for the ANSI output
-	var buffer strings.Builder
-
-	// Process the image
-	for y := 0; y < height*2; y += 2 {
-		for x := 0; x < width*2; x += 2 {
-			// Get the four pixels that will make up one character cell
-			c1 := getColorAt(resized, x, y)
-			c2 := getColorAt(resized, x+1, y)
-			c3 := getColorAt(resized, x, y+1)
-			c4 := getColorAt(resized, x+1, y+1)
-
-			// Use the upper half block character for simplicity and reliability
-			// Top pixels as foreground, bottom pixels as background
-			col1, _ := colorful.MakeColor(c1)
-			col2, _ := colorful.MakeColor(c2)
-			col3, _ := colorful.MakeColor(c3)
-			col4, _ := colorful.MakeColor(c4)
-
-			// Calculate average colors
-			upperHalfFg := averageColor(col1, col2)
-			lowerHalfBg := averageColor(col3, col4)
-
-			// Convert to standard colors
-			fg := colorfulToColor(upperHalfFg)
-			bg := colorfulToColor(lowerHalfBg)
-
-			// Append to buffer with the upper half block character
-			buffer.WriteString(ansiColorString('▀', fg, bg, use256Colors))
-		}
-		buffer.WriteString("\n")
-	}
-
-	return buffer.String(), nil
-}
-
-// getColorAt returns the color at a specific coordinate
-func getColorAt(img image.Image, x, y int) color.Color {
-	bounds := img.Bounds()
-	if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-		return img.At(x, y)
-	}
-	return color.RGBA{0, 0, 0, 255} // Return black for out-of-bounds
-}
-
-// averageColor calculates the average of multiple colors
-func averageColor(colors ...colorful.Color) colorful.Color {
-	var r, g, b float64
-	for _, c := range colors {
-		r += c.R
-		g += c.G
-		b += c.B
-	}
-	count := float64(len(colors))
-	return colorful.Color{R: r / count, G: g / count, B: b / count}
-}
-
-// colorfulToColor converts a colorful.Color to a standard color.Color
-func colorfulToColor(c colorful.Color) color.Color {
-	// Always return direct RGB values rather than mapping
-	r := uint8(c.R * 255)
-	g := uint8(c.G * 255)
-	b := uint8(c.B * 255)
-
-	return color.RGBA{R: r, G: g, B: b, A: 255}
-}
-
-// ansiColorString formats a character with ANSI color codes
-func ansiColorString(char rune, fg, bg color.Color, use256Colors bool) string {
-	// Get RGB values for foreground and background
-	r1, g1, b1, _ := fg.RGBA()
-	r2, g2, b2, _ := bg.RGBA()
-
-	// Convert from uint32 to uint8 (RGBA() returns values in range 0-65535)
-	r1, g1, b1 = r1>>8, g1>>8, b1>>8
-	r2, g2, b2 = r2>>8, g2>>8, b2>>8
-
-	if use256Colors {
-		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm%c\x1b[0m",
-			r1, g1, b1, r2, g2, b2, char)
-	}
-
-	// Simplified 16-color version as fallback
-	return string(char)
-}
-
 // loadAnsiArt loads the ANSI art from a file
 func loadAnsiArt(path string) (string, error) {
 	data, err := os.ReadFile(path)
@@ -397,29 +715,6 @@ func loadAnsiArt(path string) (string, error) {
 	return string(data), nil
 }
 
-func getSuitSymbol(suit string) string {
-	switch suit {
-	case "wands":
-		return ""
-	case "cups":
-		return ""
-	case "swords":
-		return "󰞇"
-	case "pentacles":
-		return "󱙧"
-	default:
-		return "•"
-	}
-}
-
-// getArcanaSymbol returns a symbol for the arcana type
-func getArcanaSymbol(isMinor bool) string {
-	if isMinor {
-		return "󱀝"
-	}
-	return ""
-}
-
 // wrapText wraps text to a specified width
 func wrapText(text string, width int) []string {
 	// Ensure width is reasonable
@@ -459,15 +754,17 @@ func wrapText(text string, width int) []string {
 }
 
 // displayCard displays the card information with ANSI art
-func displayCard(c *card.Card, ansiArt, deckName string) {
+func displayCard(c *card.Card, ansiArt string, opts displayOptions) error {
 	// Split the ANSI art into lines
 	ansiLines := strings.Split(ansiArt, "\n")
+	if opts.Frame != "" {
+		ansiLines = frameArt(ansiLines, c.Name, opts.Frame, opts.Theme)
+	}
 	maxAnsiWidth := 0
 	for _, line := range ansiLines {
 		// Calculate the visible width (excluding ANSI escape sequences)
-		visibleWidth := len(stripAnsi(line))
-		if visibleWidth > maxAnsiWidth {
-			maxAnsiWidth = visibleWidth
+		if width := visibleWidth(line); width > maxAnsiWidth {
+			maxAnsiWidth = width
 		}
 	}
 
@@ -477,34 +774,6 @@ func displayCard(c *card.Card, ansiArt, deckName string) {
 		width = 80 // Default if we can't get terminal width
 	}
 
-	// Prepare the info lines
-	var infoLines []string
-
-	// Get symbols
-	var arcanaSymbol, suitSymbol string
-	isMinor := c.Type == "minor_arcana"
-
-	arcanaSymbol = getArcanaSymbol(isMinor)
-	if isMinor {
-		suitSymbol = getSuitSymbol(c.Suit)
-	}
-
-	infoLines = append(infoLines, colorize.CyanString("Card: ")+colorize.HiWhiteString("%s", c.Name))
-
-	infoLines = append(infoLines, colorize.CyanString("Deck: ")+colorize.HiWhiteString(deckName))
-	infoLines = append(infoLines, colorize.CyanString("ID:   ")+colorize.HiWhiteString(c.ID))
-
-	if c.Type == "major_arcana" {
-		infoLines = append(infoLines, colorize.CyanString("Type: ")+
-			colorize.HiWhiteString("Major Arcana · %s", arcanaSymbol))
-	} else {
-		infoLines = append(infoLines, colorize.CyanString("Type: ")+
-			colorize.HiWhiteString("Minor Arcana · %s", arcanaSymbol))
-		infoLines = append(infoLines, colorize.CyanString("Suit: ")+
-			colorize.HiWhiteString("%s · %s", c.Suit, suitSymbol))
-		infoLines = append(infoLines, colorize.CyanString("Rank: ")+colorize.HiWhiteString(c.Rank))
-	}
-
 	// Calculate layout
 	// We'll display the ANSI art on the left and info on the right
 	spacing := 4
@@ -516,13 +785,45 @@ func displayCard(c *card.Card, ansiArt, deckName string) {
 		infoWidth = 20 // Minimum width for text
 	}
 
-	// Add description with word wrapping
-	if c.AltText != "" {
-		infoLines = append(infoLines, "")
-		infoLines = append(infoLines, colorize.CyanString("Description:"))
-		// Wrap the description text to fit in the available width
-		descLines := wrapText(c.AltText, infoWidth)
-		infoLines = append(infoLines, descLines...)
+	// Prepare the info lines
+	var infoLines []string
+	t := opts.Theme
+
+	values := make([]string, len(opts.Fields))
+	for i, field := range opts.Fields {
+		value, err := fieldValue(field, c, opts)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+
+	// Align values after the longest label shown, leaving at least one space
+	labelWidth := 0
+	for i, field := range opts.Fields {
+		if values[i] != "" && !isParagraph(field) {
+			labelWidth = max(labelWidth, len(fieldLabels[field])+2)
+		}
+	}
+
+	for i, field := range opts.Fields {
+		value := values[i]
+		if value == "" {
+			continue
+		}
+
+		if isParagraph(field) {
+			// Add running text with word wrapping
+			infoLines = append(infoLines, "")
+			infoLines = append(infoLines, t.Label.Sprint(fieldLabels[field]+":"))
+			for _, line := range strings.Split(value, "\n") {
+				infoLines = append(infoLines, wrapText(line, infoWidth)...)
+			}
+			continue
+		}
+
+		label := fmt.Sprintf("%-*s", labelWidth, fieldLabels[field]+":")
+		infoLines = append(infoLines, t.Label.Sprint(label)+t.Value.Sprint(value))
 	}
 
 	// Print the header
@@ -537,8 +838,7 @@ func displayCard(c *card.Card, ansiArt, deckName string) {
 		if i < len(ansiLines) {
 			fmt.Print(ansiLines[i])
 			// Pad to infoStartCol
-			visibleWidth := len(stripAnsi(ansiLines[i]))
-			fmt.Print(strings.Repeat(" ", infoStartCol-visibleWidth))
+			fmt.Print(strings.Repeat(" ", infoStartCol-visibleWidth(ansiLines[i])))
 		} else {
 			fmt.Print(strings.Repeat(" ", infoStartCol))
 		}
@@ -552,6 +852,8 @@ func displayCard(c *card.Card, ansiArt, deckName string) {
 	}
 
 	fmt.Println()
+
+	return nil
 }
 
 // stripAnsi removes ANSI escape sequences from a string
@@ -0,0 +1,724 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/astro"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/combo"
+	"github.com/arcanaland/cartomancer/internal/composite"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/correspondence"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+	"github.com/arcanaland/cartomancer/internal/imageload"
+	"github.com/arcanaland/cartomancer/internal/journal"
+	"github.com/arcanaland/cartomancer/internal/pattern"
+	"github.com/arcanaland/cartomancer/internal/report"
+	"github.com/arcanaland/cartomancer/internal/spread"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+var spreadCmd = &cobra.Command{
+	Use:   "spread [spread_id]",
+	Short: "Draw a tarot reading using a spread layout",
+	Long: `Spread shuffles the deck and deals a card into each position of a spread.
+
+Built-in spreads: ` + strings.Join(spread.Names(), ", ") + `
+
+Custom spreads are loaded from XDG_DATA_HOME/cartomancer/spreads. A .toml file
+lists fixed positions, and can keep dealing until a condition, written as a
+Starlark expression, holds:
+
+  id = "until-major"
+  name = "Until a Major Arcana"
+
+  [deal]
+  until = 'type == "major_arcana" or drawn >= 7'
+  label = "Card"
+
+A .star file is a Starlark script (a small dialect of Python) whose deal
+function draws the cards and names their positions. draw() returns the next
+card, or None once the cards run out. Cards have the fields id, name, type,
+number, suit, rank, alt_text, value, index, element, is_court and is_major:
+
+  name = "Until a Court Card"
+
+  def deal(draw):
+      cards = [("Question", draw())]
+      card = draw()
+      while card:
+          cards.append(card)
+          if card.is_court:
+              break
+          card = draw()
+      return cards
+
+Scripts cannot read files, reach the network or load modules, and are stopped
+after a million steps.
+
+Use --export-image to save the reading as a single PNG with the cards laid out
+according to the spread, using the deck's highest resolution images.
+
+Use --template to render the reading through a Go text/template file instead of
+the built-in output. The template receives .Spread, .Deck, .Date, .Seed and
+.Cards, where each card has .Position, .Order, .Name, .ID, .Suit, .Rank,
+.Element, .AltText, .Upright, .Credit (with .Artist, .Source and .License) and
+the other card fields. Helper functions: upper, lower, title, join, roman, add, wrap, a
+and date.
+
+Use --best-effort while a deck's art is still in progress: cards without an
+image get a placeholder in the suit color with the card name, in the terminal
+and in exports, and the substituted cards are listed afterwards.
+
+Use --export-animation to save an animated reveal of the reading, with the cards
+flipping from their backs to their faces in order. The format is chosen from the
+file extension: .gif is encoded directly, .webm requires ffmpeg on your PATH.
+
+Use --clarify to draw clarifier cards for positions, by number: each clarifier
+is dealt from the rest of the deck after the reading, tucked over the card it
+clarifies in layouts and revealed last with --step, and recorded in the journal
+under its position.
+
+Cards dealt into touching positions that form a known pair are listed with the
+pair's meaning after the reading (see combo --help). Use --dignities to also
+weigh the elements of touching cards: the same or friendly elements (fire and
+air, water and earth) strengthen each other, contrary ones (fire and water, air
+and earth) weaken each other. Major arcana take the element of their Golden
+Dawn attribution.
+
+Decks can carry their author's own interpretations in readings/<lang>.toml:
+each card is then listed with the author's upright meaning, and their note on
+the spread, if they suggest it for the deck, is shown beneath the heading.
+
+Use --record to save the reading, as it unfolds in the terminal, to an asciicast
+file: with --step the pauses and key presses between cards are kept and each
+reveal is marked. Play it back with 'cartomancer replay' or asciinema.
+
+Readings of five or more cards are followed by the patterns across them: suit
+and major arcana majorities, court card clusters, runs of consecutive pips and
+all-major spreads. They are recorded in journal entries too, and given to
+templates as .Patterns.
+
+Examples:
+  cartomancer spread
+  cartomancer spread celtic-cross --deck rider-waite-smith
+  cartomancer spread three-card --export-image reading.png
+  cartomancer spread three-card --export-animation reading.gif
+  cartomancer spread celtic-cross --template my-reading.tmpl
+  cartomancer spread celtic-cross --step --record reading.cast`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spreadID := "three-card"
+		if len(args) > 0 {
+			spreadID = args[0]
+		}
+
+		if err := spread.LoadDir(config.GetSpreadsDir()); err != nil {
+			return err
+		}
+
+		s, err := spread.Get(spreadID)
+		if err != nil {
+			return err
+		}
+
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		variant, _ := cmd.Flags().GetString("variant")
+		if err := d.UseVariant(variant); err != nil {
+			return err
+		}
+
+		seed, _ := cmd.Flags().GetInt64("seed")
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		rng := rand.New(rand.NewSource(seed))
+
+		var draws []spread.Draw
+		source := d.Cards()
+		if poolFlag, _ := cmd.Flags().GetString("pool"); poolFlag != "" {
+			if source, _, err = shufflePool(cmd, d, rng); err != nil {
+				return err
+			}
+			draws, err = s.DealFrom(source)
+			if err != nil {
+				return err
+			}
+		} else {
+			draws, err = s.Deal(source, rng)
+			if err != nil {
+				return err
+			}
+		}
+
+		if clarify, _ := cmd.Flags().GetIntSlice("clarify"); len(clarify) > 0 {
+			if draws, err = spread.Clarify(draws, source, clarify, rng); err != nil {
+				return err
+			}
+		}
+
+		cards := make([]*card.Card, len(draws))
+		for i, draw := range draws {
+			cards[i] = draw.Card
+		}
+		patterns := pattern.Detect(cards, nil)
+
+		now := time.Now()
+		moon, sunSign, err := readingTiming(now)
+		if err != nil {
+			return err
+		}
+
+		if record, _ := cmd.Flags().GetBool("journal"); record {
+			question, _ := cmd.Flags().GetString("question")
+			note, _ := cmd.Flags().GetString("note")
+			entry := &journal.Entry{
+				Question: question,
+				Note:     note,
+				Spread:   s.Name,
+				SpreadID: s.ID,
+				Deck:     d.Name,
+				Date:     now,
+				Seed:     seed,
+				Moon:     moon,
+				SunSign:  sunSign,
+				Patterns: patterns,
+			}
+			for _, draw := range draws {
+				c := journal.Card{
+					Position: draw.Position.Name,
+					ID:       draw.Card.ID,
+					Name:     draw.Card.Name,
+				}
+				// Clarifiers are recorded under the card they clarify
+				if draw.Clarifies > 0 {
+					parent := &entry.Cards[draw.Clarifies-1]
+					parent.Clarifiers = append(parent.Clarifiers, c)
+					continue
+				}
+				entry.Cards = append(entry.Cards, c)
+			}
+			if err := journal.NewStore(config.GetJournalDir()).Save(entry); err != nil {
+				return err
+			}
+		}
+
+		ph := newPlaceholders(cmd)
+		defer ph.printSummary()
+
+		recordPath, _ := cmd.Flags().GetString("record")
+		var rec *recording
+		if recordPath != "" {
+			if rec, err = startRecording(recordPath, fmt.Sprintf("%s with %s", s.Name, d.Name)); err != nil {
+				return err
+			}
+			defer rec.stop()
+		}
+
+		preview, _ := cmd.Flags().GetBool("preview")
+		step, _ := cmd.Flags().GetBool("step")
+		if preview || step {
+			t, err := loadTheme(cmd)
+			if err != nil {
+				return err
+			}
+
+			b, err := newBoard(d, draws, t, ph)
+			if err != nil {
+				return err
+			}
+
+			// A preview shows the layout only, so nothing is revealed
+			if preview {
+				printBoard(b.render(0))
+				return runHooks(hooks.PostDraw, newDrawPayload(s.ID, deckPath, seed, draws))
+			}
+			stepThrough(b, os.Stdin, rec)
+		}
+
+		templatePath, _ := cmd.Flags().GetString("template")
+		if templatePath != "" {
+			reading := &report.Reading{
+				Spread:   s.Name,
+				SpreadID: s.ID,
+				Deck:     d.Name,
+				Date:     now,
+				Seed:     seed,
+				Moon:     moon,
+				SunSign:  sunSign,
+				Patterns: patterns,
+			}
+			readings, err := d.Readings()
+			if err != nil {
+				return err
+			}
+			for i, draw := range draws {
+				meaning, _ := readings.For(draw.Card)
+				reading.Cards = append(reading.Cards, report.Card{
+					Position: draw.Position.Name,
+					Order:    i + 1,
+					Upright:  meaning.Upright,
+					Card:     draw.Card,
+				})
+			}
+
+			if err := renderTemplate(templatePath, reading); err != nil {
+				return err
+			}
+		} else {
+			t, err := loadTheme(cmd)
+			if err != nil {
+				return err
+			}
+
+			combos, err := loadCombos(d)
+			if err != nil {
+				return err
+			}
+
+			readings, err := d.Readings()
+			if err != nil {
+				return err
+			}
+
+			displayReading(s, draws, d, t, timingLine(moon, sunSign), combos, readings)
+			if dignities, _ := cmd.Flags().GetBool("dignities"); dignities {
+				displayDignities(draws, t)
+			}
+			if numerology, _ := cmd.Flags().GetBool("numerology"); numerology {
+				displayNumerology(cards, d, t)
+			}
+			displayPatterns(patterns, t)
+		}
+
+		if rec != nil {
+			if err := rec.stop(); err != nil {
+				return err
+			}
+			fmt.Printf("Reading recorded to %s\n", recordPath)
+		}
+
+		exportPath, _ := cmd.Flags().GetString("export-image")
+		if exportPath != "" {
+			if err := exportReadingImage(exportPath, d, draws, ph); err != nil {
+				return fmt.Errorf("error exporting image: %v", err)
+			}
+			fmt.Printf("Reading saved to %s\n", exportPath)
+		}
+
+		animationPath, _ := cmd.Flags().GetString("export-animation")
+		if animationPath != "" {
+			if err := exportReadingAnimation(animationPath, d, draws, ph); err != nil {
+				return fmt.Errorf("error exporting animation: %v", err)
+			}
+			fmt.Printf("Animation saved to %s\n", animationPath)
+		}
+
+		return runHooks(hooks.PostDraw, newDrawPayload(s.ID, deckPath, seed, draws))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(spreadCmd)
+
+	spreadCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	spreadCmd.Flags().String("variant", "", "Use a deck variant whose overlay images replace the base art")
+	spreadCmd.Flags().String("pool", "", "Deal from a subset of the deck: a built-in or custom pool (see draw --help)")
+	spreadCmd.Flags().Int64("seed", 0, "Seed for the shuffle, for reproducible readings (default random)")
+	spreadCmd.Flags().String("export-image", "", "Save the reading as a composited PNG image")
+	spreadCmd.Flags().String("export-animation", "", "Save an animated reveal of the reading (.gif or .webm)")
+	spreadCmd.Flags().Bool("journal", false, "Record the reading in your journal")
+	spreadCmd.Flags().String("question", "", "Question asked of the reading, recorded with --journal")
+	spreadCmd.Flags().String("note", "", "Note recorded with the reading when using --journal")
+	spreadCmd.Flags().Bool("preview", false, "Show the spread layout with every card face down")
+	spreadCmd.Flags().Bool("step", false, "Reveal the cards one at a time, pressing Enter between cards")
+	spreadCmd.Flags().String("record", "", "Record the reading as it unfolds to an asciicast file for replay")
+	spreadCmd.Flags().IntSlice("clarify", nil, "Draw a clarifier for these 1-based positions, e.g. --clarify 3 or --clarify 1,3")
+	spreadCmd.Flags().String("template", "", "Render the reading with a Go text/template file")
+	spreadCmd.Flags().Bool("dignities", false, "Analyze the elemental dignities between cards in touching positions")
+	spreadCmd.Flags().Bool("numerology", false, "Sum the card values and show the quintessence card and repeated ranks and suits (see draw --help)")
+	addBestEffortFlag(spreadCmd)
+}
+
+// displayReading prints the cards dealt into each position of a spread,
+// followed by the meanings of known pairs dealt into touching positions. When
+// the deck has readings, each card is followed by the author's upright
+// meaning and their note on the spread is shown beneath the heading.
+func displayReading(s *spread.Spread, draws []spread.Draw, d *deck.Deck, t *theme.Theme, timing string, combos *combo.Set, readings *deck.Readings) {
+	fmt.Println()
+	fmt.Println(t.Label.Sprint("Spread: ") + t.Value.Sprint(s.Name))
+	fmt.Println(t.Label.Sprint("Deck:   ") + t.Value.Sprint(d.Name))
+	if timing != "" {
+		fmt.Println(t.Label.Sprint("Timing: ") + t.Value.Sprint(timing))
+	}
+	if note, ok := readings.SpreadNote(s.ID); ok && note != "" {
+		for _, line := range wrapText(note, 72) {
+			fmt.Println("  " + t.Muted.Sprint(line))
+		}
+	}
+	fmt.Println()
+
+	for i, draw := range draws {
+		if draw.Clarifies > 0 {
+			continue
+		}
+		// Position labels take the accent color of the card drawn into them
+		ct := styledTheme(t, d.StyleFor(draw.Card))
+		fmt.Printf("  %2d. %s %s%s\n", i+1,
+			ct.Label.Sprintf("%s:", draw.Position.Name),
+			ct.Value.Sprint(draw.Card.Name), favoriteMarker(t, draw.Card))
+		if meaning, ok := readings.For(draw.Card); ok && meaning.Upright != "" {
+			for _, line := range wrapText(meaning.Upright, 68) {
+				fmt.Println("      " + t.Muted.Sprint(line))
+			}
+		}
+
+		// Clarifiers are listed under the card they clarify
+		for _, clarifier := range draws {
+			if clarifier.Clarifies == i+1 {
+				fmt.Printf("      %s %s\n", t.Muted.Sprint("clarified by"), t.Value.Sprint(clarifier.Card.Name))
+			}
+		}
+	}
+
+	fmt.Println()
+
+	var noted bool
+	for _, pair := range spread.Adjacent(draws) {
+		a, b := draws[pair[0]], draws[pair[1]]
+		p, ok := combos.Lookup(a.Card.ID, b.Card.ID)
+		if !ok {
+			continue
+		}
+		if !noted {
+			fmt.Println(t.Heading.Sprint("Combinations"))
+			noted = true
+		}
+		fmt.Printf("  %s %s\n", t.Label.Sprintf("%s + %s:", a.Position.Name, b.Position.Name),
+			t.Value.Sprintf("%s and %s", a.Card.Name, b.Card.Name))
+		fmt.Println("    " + t.Muted.Sprint(p.Meaning))
+	}
+	if noted {
+		fmt.Println()
+	}
+}
+
+// displayDignities prints the elemental dignity between each pair of cards in
+// touching positions, then whether each card is well or ill dignified by its
+// neighbours as a whole
+func displayDignities(draws []spread.Draw, t *theme.Theme) {
+	elements := make([]string, len(draws))
+	for i, draw := range draws {
+		elements[i] = correspondence.Element(draw.Card)
+	}
+
+	fmt.Println(t.Heading.Sprint("Elemental dignities"))
+	neighbours := make([][]correspondence.Dignity, len(draws))
+	for _, pair := range spread.Adjacent(draws) {
+		a, b := pair[0], pair[1]
+		if elements[a] == "" || elements[b] == "" {
+			continue
+		}
+		dignity := correspondence.Between(elements[a], elements[b])
+		neighbours[a] = append(neighbours[a], dignity)
+		neighbours[b] = append(neighbours[b], dignity)
+		fmt.Printf("  %s %s\n", t.Label.Sprintf("%s + %s:", draws[a].Position.Name, draws[b].Position.Name),
+			t.Value.Sprintf("%s and %s, %s", elements[a], elements[b], dignity))
+	}
+
+	fmt.Println()
+	for i, draw := range draws {
+		if len(neighbours[i]) == 0 {
+			continue
+		}
+		summary := "neutral"
+		switch balance := correspondence.Balance(neighbours[i]); {
+		case balance > 0:
+			summary = "well dignified"
+		case balance < 0:
+			summary = "ill dignified"
+		}
+		fmt.Printf("  %s %s\n", t.Label.Sprintf("%s:", draw.Position.Name),
+			t.Value.Sprint(draw.Card.Name)+t.Muted.Sprintf(" (%s) is %s", elements[i], summary))
+	}
+	fmt.Println()
+}
+
+// displayPatterns prints the patterns found across the cards of a reading
+func displayPatterns(patterns []string, t *theme.Theme) {
+	if len(patterns) == 0 {
+		return
+	}
+	fmt.Println(t.Heading.Sprint("Patterns"))
+	for _, p := range patterns {
+		fmt.Println("  " + t.Value.Sprint(p))
+	}
+	fmt.Println()
+}
+
+// readingTiming returns the moon phase and sun sign at t when astro_timing is
+// enabled in config, or empty strings otherwise
+func readingTiming(t time.Time) (string, string, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return "", "", fmt.Errorf("error loading config: %v", err)
+	}
+	if !cfg.AstroTiming {
+		return "", "", nil
+	}
+
+	return astro.Moon(t).Name, astro.SunSign(t), nil
+}
+
+// timingLine formats a moon phase and sun sign for display, or "" if unset
+func timingLine(moon, sunSign string) string {
+	if moon == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s, Sun in %s", moon, sunSign)
+}
+
+// renderTemplate renders a reading through a template file to stdout
+func renderTemplate(templatePath string, reading *report.Reading) error {
+	tmpl, err := report.Load(templatePath)
+	if err != nil {
+		return err
+	}
+
+	return report.Render(os.Stdout, tmpl, reading)
+}
+
+// exportReadingImage composites the card images of a reading into a PNG file
+func exportReadingImage(outputPath string, d *deck.Deck, draws []spread.Draw, ph *placeholders) error {
+	placements, layout, err := readingPlacements(d.AssetRoots(), draws, ph)
+	if err != nil {
+		return err
+	}
+
+	canvas := composite.Compose(placements, layout)
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return png.Encode(file, canvas)
+}
+
+// exportReadingAnimation renders the cards of a reading flipping face up in order
+// and saves the animation as a GIF or WebM depending on the file extension
+func exportReadingAnimation(outputPath string, d *deck.Deck, draws []spread.Draw, ph *placeholders) error {
+	ext := strings.ToLower(filepath.Ext(outputPath))
+	if ext != ".gif" && ext != ".webm" {
+		return fmt.Errorf("unsupported animation format: %s (supported: .gif, .webm)", ext)
+	}
+
+	placements, layout, err := readingPlacements(d.AssetRoots(), draws, ph)
+	if err != nil {
+		return err
+	}
+
+	// Animations are re-rendered for every frame, so keep the cards to a modest size
+	if layout.CardHeight > animationCardHeight {
+		width := layout.CardWidth * animationCardHeight / layout.CardHeight
+		layout = composite.DefaultLayout(width, animationCardHeight)
+	}
+
+	var back image.Image
+	if backPath, err := d.CardBackPath(); err == nil {
+		back, _ = decodeImageFile(backPath)
+	}
+	if back == nil {
+		back = composite.PlaceholderBack(layout.CardWidth, layout.CardHeight, layout.BlankCard, layout.Foreground)
+	}
+
+	frames := composite.Reveal(placements, back, layout)
+
+	if ext == ".webm" {
+		return composite.EncodeWebM(outputPath, frames)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return composite.EncodeGIF(file, frames)
+}
+
+// animationCardHeight is the maximum card height in pixels used for animations
+const animationCardHeight = 360
+
+// readingPlacements loads the card images of a reading and positions them on
+// the layout grid, sizing the layout to the first decodable image. In
+// best-effort mode cards without an image get placeholder art.
+func readingPlacements(roots []string, draws []spread.Draw, ph *placeholders) ([]composite.Placement, composite.Layout, error) {
+	var placements []composite.Placement
+	cardWidth, cardHeight := 0, 0
+
+	for _, draw := range draws {
+		img, err := loadViewImage(roots, draw.Card, 0)
+		if err == nil && cardWidth == 0 {
+			cardWidth, cardHeight = img.Bounds().Dx(), img.Bounds().Dy()
+		}
+
+		placement := composite.Placement{
+			X:       draw.Position.X,
+			Y:       draw.Position.Y,
+			Rotated: draw.Position.Rotated,
+			Label:   []string{draw.Position.Name, draw.Card.Name},
+		}
+		if err == nil {
+			placement.Image = img
+		}
+		placements = append(placements, placement)
+	}
+
+	if cardWidth == 0 && ph.enabled {
+		cardWidth, cardHeight = placeholderWidth, placeholderHeight
+	}
+	if cardWidth == 0 {
+		return nil, composite.Layout{}, fmt.Errorf("no decodable card images found in %s", strings.Join(roots, ", "))
+	}
+
+	for i := range placements {
+		if placements[i].Image != nil {
+			continue
+		}
+		if img, ok := ph.image(draws[i].Card, cardWidth, cardHeight); ok {
+			placements[i].Image = img
+		}
+	}
+
+	return placements, composite.DefaultLayout(cardWidth, cardHeight), nil
+}
+
+// maxImagePixels is the max_image_pixels setting, read from config on first use
+var maxImagePixels = sync.OnceValue(func() int {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return 0
+	}
+	return cfg.MaxImagePixels
+})
+
+// decodeImageFile opens and decodes an image file at full size
+func decodeImageFile(path string) (image.Image, error) {
+	return decodeScaledImageFile(path, 0)
+}
+
+// decodeScaledImageFile decodes an image file, scaling it down to maxHeight
+// when taller, within the configured pixel limit
+func decodeScaledImageFile(path string, maxHeight int) (image.Image, error) {
+	return imageload.Decode(path, imageload.Limits{MaxPixels: maxImagePixels(), MaxHeight: maxHeight})
+}
+
+// loadHighestResImage decodes a card image from the first asset root that has one
+func loadHighestResImage(roots []string, c *card.Card) (image.Image, error) {
+	return loadScaledImage(roots, c, 0)
+}
+
+// loadScaledImage decodes a card image from the first asset root that has
+// one, scaling it down to maxHeight when taller. Images over the pixel limit
+// are reported rather than skipped for a lower tier.
+func loadScaledImage(roots []string, c *card.Card, maxHeight int) (image.Image, error) {
+	for _, root := range roots {
+		img, err := loadTierImage(root, c, maxHeight)
+		if err == nil {
+			return img, nil
+		}
+		var tooLarge *imageload.TooLargeError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("no raster image found for card: %s", c.ID)
+}
+
+// rasterExtensions are the extensions of the card images that can be decoded
+var rasterExtensions = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+// rasterTiers returns the deck's raster tier directories (h2400, h1200, ...),
+// highest resolution first
+func rasterTiers(deckPath string) ([]string, error) {
+	entries, err := os.ReadDir(deckPath)
+	if err != nil {
+		return nil, err
+	}
+
+	heights := make(map[string]int)
+	var tiers []string
+	for _, entry := range entries {
+		var height int
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "h") {
+			if _, err := fmt.Sscanf(entry.Name(), "h%d", &height); err == nil {
+				tiers = append(tiers, entry.Name())
+				heights[entry.Name()] = height
+			}
+		}
+	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		return heights[tiers[i]] > heights[tiers[j]]
+	})
+	return tiers, nil
+}
+
+// loadTierImage decodes a card image from the deck's highest resolution
+// raster tier (h2400, h1200, ...) that contains the card, falling back to
+// generated placeholder art
+func loadTierImage(deckPath string, c *card.Card, maxHeight int) (image.Image, error) {
+	tiers, err := rasterTiers(deckPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Placeholder art is only used when no tier has the card
+	tiers = append(tiers, deck.PlaceholderDir)
+
+	parts := strings.Split(c.ID, ".")
+	for _, t := range tiers {
+		for _, ext := range rasterExtensions {
+			path, err := buildCardPath(filepath.Join(deckPath, t), parts, ext)
+			if err != nil {
+				return nil, err
+			}
+
+			img, err := decodeScaledImageFile(path, maxHeight)
+			if err == nil {
+				return img, nil
+			}
+			var tooLarge *imageload.TooLargeError
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no raster image found for card: %s", c.ID)
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/study"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/spf13/cobra"
+)
+
+var studyCmd = &cobra.Command{
+	Use:   "study",
+	Short: "Learn the cards with flashcards",
+	Long: `Study quizzes you on the cards of a deck. In art mode the card's art is shown and
+you name the card; in describe mode the card's description is shown instead.
+Answer with the card's name or any card ID accepted by show (e.g. XVII or
+cups/queen), type ? to pick it from a searchable list, press Enter to reveal
+the answer, or type q to stop.
+
+Cards are scheduled with spaced repetition: a correct answer moves a card to a
+longer review interval (1, 3, 7, 14 and then 30 days), a wrong answer makes it
+due again right away. Progress is kept per deck in
+XDG_DATA_HOME/cartomancer/study.
+
+Examples:
+  cartomancer study
+  cartomancer study --mode describe --count 20
+  cartomancer study --progress`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mode, _ := cmd.Flags().GetString("mode")
+		if mode != "art" && mode != "describe" {
+			return fmt.Errorf("unknown mode: %s (supported: art, describe)", mode)
+		}
+
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		t, err := loadTheme(cmd)
+		if err != nil {
+			return err
+		}
+
+		progress, err := study.Load(config.GetStudyDir(), d.ID)
+		if err != nil {
+			return err
+		}
+
+		cards := d.Cards()
+		if mode == "describe" {
+			// Only cards with a description can be recognized from it
+			var described []*card.Card
+			for _, c := range cards {
+				if c.AltText != "" {
+					described = append(described, c)
+				}
+			}
+			if len(described) == 0 {
+				return fmt.Errorf("deck %s has no card descriptions to study", d.Name)
+			}
+			cards = described
+		}
+
+		if showProgress, _ := cmd.Flags().GetBool("progress"); showProgress {
+			printStudyStats(d.Name, progress.Stats(cards, time.Now()), t)
+			return nil
+		}
+
+		count, _ := cmd.Flags().GetInt("count")
+		queue := progress.Next(cards, count, time.Now())
+		if len(queue) == 0 {
+			fmt.Println("Nothing to review right now. Come back later!")
+			printStudyStats(d.Name, progress.Stats(cards, time.Now()), t)
+			return nil
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		right, asked := 0, 0
+		for i, c := range queue {
+			fmt.Println()
+			fmt.Println(t.Heading.Sprintf("Card %d of %d", i+1, len(queue)))
+
+			if err := showFlashcard(d, c, mode, t); err != nil {
+				return err
+			}
+
+			fmt.Print(t.Label.Sprint("Which card is this? "))
+			answer, err := reader.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if (err == io.EOF && answer == "") || strings.EqualFold(answer, "q") {
+				break
+			} else if err != nil && err != io.EOF {
+				return fmt.Errorf("error reading answer: %v", err)
+			}
+
+			if answer == "?" {
+				picked, err := pickCard(d, t)
+				if err != nil {
+					return err
+				}
+				answer = ""
+				if picked != nil {
+					answer = picked.ID
+					fmt.Println(t.Muted.Sprint("You picked ") + t.Value.Sprint(picked.Name))
+				}
+			}
+
+			correct := answerMatches(answer, c)
+			record := progress.Answer(c.ID, correct, time.Now())
+			asked++
+			if correct {
+				right++
+				fmt.Println(t.Value.Sprint("Correct!") + " " +
+					t.Muted.Sprintf("Next review %s", record.Due.Format("2006-01-02")))
+			} else {
+				fmt.Println(t.Muted.Sprint("It was ") + t.Value.Sprint(c.Name) + t.Muted.Sprintf(" (%s)", c.ID))
+			}
+		}
+
+		if err := progress.Save(config.GetStudyDir()); err != nil {
+			return err
+		}
+
+		fmt.Println()
+		fmt.Println(t.Label.Sprint("Session: ") + t.Value.Sprintf("%d of %d correct", right, asked))
+		printStudyStats(d.Name, progress.Stats(cards, time.Now()), t)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(studyCmd)
+
+	studyCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	studyCmd.Flags().String("mode", "art", "Quiz mode: art (name the card from its art) or describe (from its description)")
+	studyCmd.Flags().IntP("count", "n", 10, "Maximum number of cards to review (0 for all due cards)")
+	studyCmd.Flags().Bool("progress", false, "Show study progress for the deck without quizzing")
+}
+
+// showFlashcard prints the prompt side of a flashcard
+func showFlashcard(d *deck.Deck, c *card.Card, mode string, t *theme.Theme) error {
+	if mode == "describe" {
+		fmt.Println()
+		for _, line := range wrapText(c.AltText, 70) {
+			fmt.Println("  " + line)
+		}
+		fmt.Println()
+		return nil
+	}
+
+	ansiArt, err := cardAnsiArt(d.AssetRoots(), c)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for _, line := range strings.Split(ansiArt, "\n") {
+		fmt.Println("  " + line)
+	}
+	return nil
+}
+
+// answerMatches reports whether an answer names the card, either by name
+// (ignoring case and a leading "the") or by any accepted card ID notation
+func answerMatches(answer string, c *card.Card) bool {
+	if answer == "" {
+		return false
+	}
+
+	normalize := func(s string) string {
+		s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+		return strings.TrimPrefix(s, "the ")
+	}
+	if normalize(answer) == normalize(c.Name) {
+		return true
+	}
+
+	id, err := card.ParseID(answer)
+	return err == nil && id == c.ID
+}
+
+// printStudyStats prints a summary of a deck's study progress
+func printStudyStats(deckName string, s study.Stats, t *theme.Theme) {
+	fmt.Println(t.Label.Sprint("Deck:     ") + t.Value.Sprint(deckName))
+	fmt.Println(t.Label.Sprint("Mastered: ") + t.Value.Sprintf("%d/%d", s.Mastered, s.Total))
+	fmt.Println(t.Label.Sprint("Learning: ") + t.Value.Sprintf("%d", s.Learning) +
+		t.Muted.Sprintf(" (%d due)", s.Due))
+	fmt.Println(t.Label.Sprint("New:      ") + t.Value.Sprintf("%d", s.New))
+	if total := s.Right + s.Wrong; total > 0 {
+		fmt.Println(t.Label.Sprint("Accuracy: ") + t.Value.Sprintf("%d%%", s.Right*100/total))
+	}
+	fmt.Println()
+}
@@ -1,9 +1,21 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+	"github.com/arcanaland/cartomancer/internal/script"
+	"github.com/arcanaland/cartomancer/internal/theme"
 	"github.com/arcanaland/cartomancer/internal/validator"
 	"github.com/spf13/cobra"
 )
@@ -13,7 +25,59 @@ var validateCmd = &cobra.Command{
 	Use:   "validate [path]",
 	Short: "Validate a tarot deck directory",
 	Long: `Validate checks if a tarot deck directory conforms to the Tarot Deck Specification v1.0.
-It verifies the structure, required files, and conformity to the specification.`,
+It verifies the structure, required files, and conformity to the specification.
+
+Custom rules can be added as .toml files in XDG_DATA_HOME/cartomancer/rules:
+
+  [[rule]]
+  name = "major-alt-text"
+  severity = "warning"
+  cards = 'type == "major_arcana"'
+  require = 'alt_text != ""'
+  message = "{id} ({name}) has no alt text"
+
+The cards and require conditions are Starlark expressions. Rules can also be
+Starlark scripts (.star files) defining check(card), which returns a message
+for a card that fails the rule and None otherwise; cards have the fields
+listed in spread --help:
+
+  severity = "warning"
+
+  def check(card):
+      if card.is_court and card.rank not in card.alt_text.lower():
+          return "%s does not mention its rank in its alt text" % card.id
+
+Every raster tier (h750, h2400 and so on) should hold the same cards, and a
+scalable tier should cover every card the raster tiers do; gaps are warnings.
+
+Files in the deck's directories that are not part of the deck, such as layered
+sources or "00 copy.png", are warned about unless they are named in a
+.deckignore file, which also leaves them out of packages.
+
+Image and ANSI art files are checked for valid content. Results are cached per
+file by content hash, so re-running validate only reads the files that
+changed; --no-cache checks every file. With --watch, validate re-runs whenever
+a file in the deck changes, until interrupted.
+
+A registry or CI pipeline can enforce its hosting constraints with --policy and
+a policy file; any violation is a validation error:
+
+  max_archive_mib = 50                   # size of the packaged .tar.gz
+  max_image_mib = 2                      # size of any one image file
+  required_tiers = ["scalable", "h750"]  # tiers with art for every card
+
+With --report, validate prints a report card scoring the deck from 0 to 100
+with a letter grade: art coverage per asset tier with a tier-by-card matrix,
+alt text coverage, name coverage per language, metadata completeness and
+validation results. Reports are available as text, JSON or a standalone HTML
+page.
+
+Examples:
+  cartomancer validate ./my-deck
+  cartomancer validate ./my-deck --watch
+  cartomancer validate ./my-deck --policy registry-policy.toml
+  cartomancer validate ./my-deck --report
+  cartomancer validate ./my-deck --report --format html > report.html`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		deckPath := args[0]
@@ -23,34 +87,310 @@ It verifies the structure, required files, and conformity to the specification.`
 			return fmt.Errorf("deck directory not found: %s", deckPath)
 		}
 
-		// Create validator and run validation
-		v := validator.NewValidator(deckPath)
-		results, err := v.Validate()
+		var cache *validator.Cache
+		if noCache, _ := cmd.Flags().GetBool("no-cache"); !noCache {
+			cache = validator.LoadCache(validator.CachePath(config.GetCacheDir(), deckPath))
+		}
+
+		if watch, _ := cmd.Flags().GetBool("watch"); watch {
+			return watchValidation(cmd, deckPath, cache)
+		}
+		return runValidation(cmd, deckPath, cache)
+	},
+}
+
+// runValidation validates a deck once and prints the results or report card
+func runValidation(cmd *cobra.Command, deckPath string, cache *validator.Cache) error {
+	if err := runHooks(hooks.PreValidate, validatePayload{Deck: deckPath}); err != nil {
+		return err
+	}
+
+	// Create validator and run validation
+	v := validator.NewValidator(deckPath)
+	v.Public, _ = cmd.Flags().GetBool("public")
+	v.Cache = cache
+	results, err := v.Validate()
+	if err != nil {
+		return fmt.Errorf("validation error: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	// Check custom rules from the data directory
+	rules, err := script.LoadRules(config.GetRulesDir())
+	if err != nil {
+		return err
+	}
+	results, err = v.ValidateRules(rules)
+	if err != nil {
+		return fmt.Errorf("validation error: %v", err)
+	}
+
+	if policyPath, _ := cmd.Flags().GetString("policy"); policyPath != "" {
+		policy, err := validator.LoadPolicy(policyPath)
 		if err != nil {
+			return err
+		}
+		if results, err = v.ValidatePolicy(policy); err != nil {
 			return fmt.Errorf("validation error: %v", err)
 		}
+	}
 
-		// Display validation results
-		fmt.Println("Validation Results:")
-		fmt.Println("-------------------")
-		
-		if len(results.Errors) == 0 {
-			fmt.Printf("✅ Deck '%s' is valid according to the specification.\n", deckPath)
-		} else {
-			fmt.Printf("❌ Deck '%s' has %d validation errors:\n", deckPath, len(results.Errors))
-			for i, err := range results.Errors {
-				fmt.Printf("%d. %s\n", i+1, err)
-			}
+	if err := runHooks(hooks.PostValidate, validatePayload{
+		Deck:     deckPath,
+		Valid:    len(results.Errors) == 0,
+		Errors:   results.Errors,
+		Warnings: results.Warnings,
+	}); err != nil {
+		return err
+	}
+
+	// Display validation results
+	t, err := loadTheme(cmd)
+	if err != nil {
+		return err
+	}
+
+	if report, _ := cmd.Flags().GetBool("report"); report {
+		format, _ := cmd.Flags().GetString("format")
+		if err := writeHealthReport(os.Stdout, v.Report(results), format, t); err != nil {
+			return err
+		}
+		if len(results.Errors) > 0 {
 			return fmt.Errorf("validation failed")
 		}
+		return nil
+	}
+
+	fmt.Println(t.Heading.Sprint("Validation Results:"))
+	fmt.Println(strings.Repeat(t.Border.Horizontal, 19))
+
+	if len(results.Errors) == 0 {
+		fmt.Printf("✅ Deck '%s' is valid according to the specification.\n", deckPath)
+	} else {
+		fmt.Printf("❌ Deck '%s' has %d validation errors:\n", deckPath, len(results.Errors))
+		for i, err := range results.Errors {
+			fmt.Printf("%d. %s\n", i+1, err)
+		}
+		return fmt.Errorf("validation failed")
+	}
 
-		if len(results.Warnings) > 0 {
-			fmt.Println("\nWarnings:")
-			for i, warn := range results.Warnings {
-				fmt.Printf("%d. %s\n", i+1, warn)
+	if len(results.Warnings) > 0 {
+		fmt.Println("\nWarnings:")
+		for i, warn := range results.Warnings {
+			fmt.Printf("%d. %s\n", i+1, warn)
+		}
+	}
+
+	return nil
+}
+
+// watchInterval is how often --watch polls the deck for changes
+const watchInterval = time.Second
+
+// watchValidation re-validates a deck whenever one of its files changes,
+// until interrupted. The cache keeps each run to the changed files.
+func watchValidation(cmd *cobra.Command, deckPath string, cache *validator.Cache) error {
+	last := ""
+	for {
+		if state := deckState(deckPath); state != last {
+			last = state
+			fmt.Printf("\n[%s] validating %s\n", time.Now().Format("15:04:05"), deckPath)
+			if err := runValidation(cmd, deckPath, cache); err != nil && err.Error() != "validation failed" {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			}
 		}
+		time.Sleep(watchInterval)
+	}
+}
 
+// deckState fingerprints the names, sizes and modification times of a deck's
+// files, changing whenever a file is added, removed or written
+func deckState(deckPath string) string {
+	h := fnv.New64a()
+	filepath.WalkDir(deckPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\x00", path, info.Size(), info.ModTime().UnixNano())
 		return nil
-	},
-}
\ No newline at end of file
+	})
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// validatePayload is the hook payload for validation events
+type validatePayload struct {
+	Deck     string   `json:"deck"`
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func init() {
+	validateCmd.Flags().Bool("public", false, "Validate for a public registry, warning about proprietary licenses")
+	validateCmd.Flags().Bool("report", false, "Print a scored report card of the deck's quality")
+	validateCmd.Flags().String("format", "text", "Report format: text, json or html")
+	validateCmd.Flags().Bool("watch", false, "Re-validate whenever a file in the deck changes")
+	validateCmd.Flags().Bool("no-cache", false, "Check every file instead of reusing cached results")
+	validateCmd.Flags().String("policy", "", "Enforce a registry policy file of size limits and required tiers")
+}
+
+// writeHealthReport writes a deck report card in a format
+func writeHealthReport(w io.Writer, r *validator.Report, format string, t *theme.Theme) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case "html":
+		return healthReportHTML.Execute(w, r)
+	case "text":
+	default:
+		return fmt.Errorf("unknown format: %s (supported: text, json, html)", format)
+	}
+
+	fmt.Fprintf(w, "%s %s\n", t.Heading.Sprint("Report card:"), r.Deck)
+	fmt.Fprintln(w, strings.Repeat(t.Border.Horizontal, 12+len(r.Deck)))
+	fmt.Fprintf(w, "%s %d/100 (grade %s)\n\n", t.Label.Sprint("Score:"), r.Score, t.Value.Sprint(r.Grade))
+
+	for _, c := range r.Categories {
+		fmt.Fprintf(w, "%-12s %3d  %s  %s\n", c.Name, c.Score, scoreBar(c.Score), t.Muted.Sprintf("(%d%%) %s", c.Weight, c.Detail))
+	}
+
+	if len(r.Tiers) > 0 {
+		fmt.Fprintln(w, "\n"+t.Heading.Sprint("Asset tiers:"))
+		for _, c := range r.Tiers {
+			fmt.Fprintf(w, "  %-12s %3d/%d\n", c.Name, c.Present, c.Total)
+		}
+	}
+	if r.Matrix != nil && len(r.Matrix.Tiers) > 0 {
+		writeTierMatrix(w, r.Matrix, t)
+	}
+	if len(r.Languages) > 0 {
+		fmt.Fprintln(w, "\n"+t.Heading.Sprint("Languages:"))
+		for _, c := range r.Languages {
+			fmt.Fprintf(w, "  %-12s %3d/%d\n", c.Name, c.Present, c.Total)
+		}
+	}
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(w, "\n"+t.Heading.Sprint("Errors:"))
+		for _, e := range r.Errors {
+			fmt.Fprintf(w, "  %s\n", e)
+		}
+	}
+	if len(r.Warnings) > 0 {
+		fmt.Fprintln(w, "\n"+t.Heading.Sprint("Warnings:"))
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(w, "  %s\n", warning)
+		}
+	}
+	return nil
+}
+
+// writeTierMatrix prints the cards missing from some image tier, with a mark
+// for each tier that has them. Cards in every tier are only counted.
+func writeTierMatrix(w io.Writer, m *validator.TierMatrix, t *theme.Theme) {
+	var gaps []validator.CardTiers
+	width := len("card")
+	for _, row := range m.Cards {
+		if !row.Complete() {
+			gaps = append(gaps, row)
+			width = max(width, len(row.ID))
+		}
+	}
+
+	fmt.Fprintln(w, "\n"+t.Heading.Sprint("Tier matrix:"))
+	if len(gaps) == 0 {
+		fmt.Fprintf(w, "  all %d cards have art in every image tier\n", len(m.Cards))
+		return
+	}
+
+	header := fmt.Sprintf("  %-*s", width, "card")
+	for _, tier := range m.Tiers {
+		header += "  " + tier
+	}
+	fmt.Fprintln(w, t.Label.Sprint(header))
+	for _, row := range gaps {
+		line := fmt.Sprintf("  %-*s", width, row.ID)
+		for i, tier := range m.Tiers {
+			pad := strings.Repeat(" ", len(tier)-1)
+			if row.Present[i] {
+				line += "  " + t.Value.Sprint("✓") + pad
+			} else {
+				line += "  " + t.Muted.Sprint("·") + pad
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	if complete := len(m.Cards) - len(gaps); complete > 0 {
+		fmt.Fprintf(w, "  %s\n", t.Muted.Sprintf("%d more cards have art in every image tier", complete))
+	}
+}
+
+// scoreBar draws a score as a 20-cell bar
+func scoreBar(score int) string {
+	filled := score / 5
+	return strings.Repeat("█", filled) + strings.Repeat("░", 20-filled)
+}
+
+// healthReportHTML renders a report card as a standalone page
+var healthReportHTML = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Deck}} report card</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 2em auto; color: #222; }
+.grade { font-size: 3em; font-weight: bold; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 0.3em 0.6em; border-bottom: 1px solid #ddd; }
+meter { width: 10em; }
+.missing { color: #a33; }
+</style>
+</head>
+<body>
+<h1>{{.Deck}}</h1>
+<p><span class="grade">{{.Grade}}</span> {{.Score}}/100</p>
+
+<table>
+<tr><th>Category</th><th>Weight</th><th>Score</th><th></th><th>Detail</th></tr>
+{{range .Categories}}<tr><td>{{.Name}}</td><td>{{.Weight}}%</td><td>{{.Score}}</td><td><meter min="0" max="100" value="{{.Score}}"></meter></td><td>{{.Detail}}</td></tr>
+{{end}}</table>
+
+{{if .Tiers}}<h2>Asset tiers</h2>
+<table>
+<tr><th>Tier</th><th>Cards</th></tr>
+{{range .Tiers}}<tr><td>{{.Name}}</td><td>{{.Present}}/{{.Total}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .Matrix}}{{if .Tiers}}<h2>Tier matrix</h2>
+<table>
+<tr><th>Card</th>{{range .Tiers}}<th>{{.}}</th>{{end}}</tr>
+{{range .Cards}}<tr><td>{{.Name}}</td>{{range .Present}}<td{{if not .}} class="missing"{{end}}>{{if .}}✓{{else}}missing{{end}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}{{end}}
+{{if .Languages}}<h2>Languages</h2>
+<table>
+<tr><th>Language</th><th>Names</th></tr>
+{{range .Languages}}<tr><td>{{.Name}}</td><td>{{.Present}}/{{.Total}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Metadata}}<h2>Metadata</h2>
+<ul>
+{{range .Metadata}}<li{{if not .Present}} class="missing"{{end}}>{{.Name}}: {{if .Present}}present{{else}}missing{{end}}</li>
+{{end}}</ul>
+{{end}}
+{{if .Errors}}<h2>Errors</h2>
+<ul>{{range .Errors}}<li>{{.}}</li>{{end}}</ul>
+{{end}}
+{{if .Warnings}}<h2>Warnings</h2>
+<ul>{{range .Warnings}}<li>{{.}}</li>{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
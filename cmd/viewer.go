@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"golang.org/x/term"
+)
+
+// Zoom limits and step sizes for the interactive viewer
+const (
+	viewerMaxZoom  = 16.0
+	viewerZoomStep = 1.5
+	viewerPanStep  = 0.1 // Fraction of the visible region moved per key press
+)
+
+// viewer shows a region of a card image in the terminal and lets the user zoom and pan
+type viewer struct {
+	img     image.Image
+	title   string
+	theme   *theme.Theme
+	zoom    float64
+	centerX float64 // Centre of the visible region, as a fraction of the image width
+	centerY float64 // Centre of the visible region, as a fraction of the image height
+}
+
+// newViewer creates a viewer showing the whole image
+func newViewer(img image.Image, title string, t *theme.Theme) *viewer {
+	return &viewer{img: img, title: title, theme: t, zoom: 1, centerX: 0.5, centerY: 0.5}
+}
+
+// run takes over the terminal until the user quits. Keys: + and - zoom,
+// arrows or h/j/k/l pan, 0 resets the view and q or Esc quits.
+func (v *viewer) run() error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("interactive mode requires a terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("error entering raw mode: %v", err)
+	}
+	defer term.Restore(fd, state)
+
+	// Use the alternate screen and hide the cursor while viewing
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	buf := make([]byte, 8)
+	for {
+		if err := v.draw(); err != nil {
+			return err
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil
+		}
+
+		switch key := string(buf[:n]); key {
+		case "q", "Q", "\033", "\003":
+			return nil
+		case "+", "=":
+			v.zoom = math.Min(v.zoom*viewerZoomStep, viewerMaxZoom)
+		case "-", "_":
+			v.zoom = math.Max(v.zoom/viewerZoomStep, 1)
+		case "0":
+			v.zoom, v.centerX, v.centerY = 1, 0.5, 0.5
+		case "h", "\033[D":
+			v.centerX -= viewerPanStep / v.zoom
+		case "l", "\033[C":
+			v.centerX += viewerPanStep / v.zoom
+		case "k", "\033[A":
+			v.centerY -= viewerPanStep / v.zoom
+		case "j", "\033[B":
+			v.centerY += viewerPanStep / v.zoom
+		}
+		v.clamp()
+	}
+}
+
+// clamp keeps the visible region inside the image
+func (v *viewer) clamp() {
+	half := 0.5 / v.zoom
+	v.centerX = math.Max(half, math.Min(1-half, v.centerX))
+	v.centerY = math.Max(half, math.Min(1-half, v.centerY))
+}
+
+// draw renders the visible region to fill the terminal, leaving a status line
+func (v *viewer) draw() error {
+	cols, rows, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || cols <= 0 || rows <= 1 {
+		cols, rows = 80, 24
+	}
+	rows-- // Status line
+
+	frame := v.region(cols, rows)
+
+	// Stream the frame line by line; raw mode needs explicit carriage returns
+	out := bufio.NewWriter(os.Stdout)
+	out.WriteString("\033[H")
+	err = render.RenderLines(frame, viewOptions(render.Options{Width: cols, Height: rows, TrueColor: true}), func(line []byte) error {
+		out.Write(line)
+		_, err := out.WriteString("\r\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	out.WriteString("\033[K")
+	out.WriteString(v.theme.Muted.Sprintf("%s  %.1fx  +/- zoom  arrows/hjkl pan  0 reset  q quit",
+		v.title, v.zoom))
+	return out.Flush()
+}
+
+// region crops the visible part of the image onto a canvas matching the
+// terminal's aspect ratio, letterboxing where the image does not reach
+func (v *viewer) region(cols, rows int) image.Image {
+	bounds := v.img.Bounds()
+	imgW, imgH := float64(bounds.Dx()), float64(bounds.Dy())
+
+	// Each terminal cell shows two vertically stacked pixels
+	screenAspect := float64(cols) / float64(rows*2)
+
+	regionH := imgH / v.zoom
+	regionW := regionH * screenAspect
+	if regionW < imgW/v.zoom {
+		regionW = imgW / v.zoom
+		regionH = regionW / screenAspect
+	}
+
+	x0 := v.centerX*imgW - regionW/2
+	y0 := v.centerY*imgH - regionH/2
+
+	canvas := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(regionW)), int(math.Ceil(regionH))))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(canvas, canvas.Bounds(), v.img,
+		image.Pt(bounds.Min.X+int(math.Round(x0)), bounds.Min.Y+int(math.Round(y0))), draw.Src)
+
+	return canvas
+}
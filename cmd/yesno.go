@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/yesno"
+	"github.com/spf13/cobra"
+)
+
+var yesnoCmd = &cobra.Command{
+	Use:   "yesno [question]",
+	Short: "Ask the cards a yes or no question",
+	Long: `Yesno answers a yes or no question with one of the common card methods:
+
+` + yesnoMethodList() + `
+The verdict comes with a confidence from 0 to 100%: the margin of upright over
+reversed cards, or how soon the ace turned up. The cards the answer rests on are
+shown with their art unless --no-art is given.
+
+The default method and each method's settings can be set in config.toml:
+
+  [yesno]
+  method = "aces"
+
+  [yesno.reversals]
+  cards = 5              # cards drawn
+  reversal_chance = 0.5  # chance of a card being dealt reversed
+
+  [yesno.aces]
+  cards = 13             # most cards dealt looking for an ace
+
+Examples:
+  cartomancer yesno "Should I take the job?"
+  cartomancer yesno --method aces "Will it rain tomorrow?"
+  cartomancer yesno --cards 5 --seed 42`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("error loading config: %v", err)
+		}
+		settings := cfg.YesNo
+		if settings == nil {
+			settings = &config.YesNo{}
+		}
+
+		methodName, _ := cmd.Flags().GetString("method")
+		if methodName == "" {
+			methodName = settings.Method
+		}
+		if methodName == "" {
+			methodName = yesno.DefaultMethod
+		}
+		method, err := yesno.Get(methodName)
+		if err != nil {
+			return err
+		}
+
+		var options yesno.Options
+		if m := settings.MethodOptions(method.Name); m != nil {
+			options = yesno.Options{Cards: m.Cards, ReversalChance: m.ReversalChance}
+		}
+		if cmd.Flags().Changed("cards") {
+			options.Cards, _ = cmd.Flags().GetInt("cards")
+		}
+
+		deckFlag, _ := cmd.Flags().GetString("deck")
+		deckPath, err := resolveDeckPath(deckFlag)
+		if err != nil {
+			return err
+		}
+
+		d, err := deck.LoadDeck(deckPath)
+		if err != nil {
+			return deckLoadError(deckPath, err)
+		}
+
+		seed, _ := cmd.Flags().GetInt64("seed")
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		result, err := method.Read(d.Cards(), options, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			return err
+		}
+
+		opts, err := loadDisplayOptions(cmd, d.Name)
+		if err != nil {
+			return err
+		}
+		defer opts.Placeholders.printSummary()
+		t := opts.Theme
+
+		fmt.Println()
+		if len(args) > 0 {
+			fmt.Println(t.Label.Sprint("Question:   ") + t.Value.Sprint(args[0]))
+		}
+		fmt.Println(t.Label.Sprint("Method:     ") + t.Value.Sprint(method.Name))
+
+		var dealt []string
+		for _, drawn := range result.Dealt {
+			dealt = append(dealt, drawnName(drawn))
+		}
+		fmt.Println(t.Label.Sprint("Cards:      ") + t.Value.Sprint(strings.Join(dealt, ", ")))
+		fmt.Println(t.Label.Sprint("Answer:     ") + t.Heading.Sprint(strings.ToUpper(result.Answer)) +
+			t.Muted.Sprintf(" (%s)", result.Reason))
+		fmt.Println(t.Label.Sprint("Confidence: ") + t.Value.Sprintf("%.0f%%", result.Confidence*100))
+
+		if noArt, _ := cmd.Flags().GetBool("no-art"); noArt || len(result.Supporting) == 0 {
+			fmt.Println()
+			return nil
+		}
+
+		// Caption the art of reversed cards as such
+		cards := make([]*card.Card, len(result.Supporting))
+		for i, drawn := range result.Supporting {
+			c := *drawn.Card
+			c.Name = drawnName(drawn)
+			cards[i] = &c
+		}
+		return showCardGrid(d, cards, opts, 0)
+	},
+}
+
+// drawnName returns the name of a dealt card, marked when reversed
+func drawnName(drawn yesno.Drawn) string {
+	if drawn.Reversed {
+		return drawn.Card.Name + " (reversed)"
+	}
+	return drawn.Card.Name
+}
+
+// yesnoMethodList describes the yes/no methods for the command help
+func yesnoMethodList() string {
+	var b strings.Builder
+	for _, m := range yesno.Methods() {
+		fmt.Fprintf(&b, "  %-10s %s (default %d cards)\n", m.Name, m.Description, m.DefaultCards)
+	}
+	return b.String()
+}
+
+func init() {
+	RootCmd.AddCommand(yesnoCmd)
+
+	yesnoCmd.Flags().StringP("deck", "d", "", "Specify a deck from your deck library or a path to a deck")
+	yesnoCmd.Flags().String("method", "", "Method: "+strings.Join(yesno.Names(), " or ")+" (default from config, or reversals)")
+	yesnoCmd.Flags().Int("cards", 0, "Cards drawn, or most cards dealt looking for an ace (default from config or the method)")
+	yesnoCmd.Flags().Int64("seed", 0, "Seed for the shuffle, for reproducible readings (default random)")
+	yesnoCmd.Flags().Bool("no-art", false, "Print the answer without the card art")
+	addBestEffortFlag(yesnoCmd)
+}
@@ -0,0 +1,135 @@
+package alttext
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// defaultSystemPrompt instructs the model how to write alt text
+const defaultSystemPrompt = `You write alt text for tarot card images, for people using screen readers.
+Describe what is depicted in one or two plain sentences of at most 40 words:
+the figures, objects, setting and colors. Do not interpret the card's meaning,
+do not start with "An image of" and do not repeat the card's name.`
+
+// client is the HTTP client used for language model requests
+var client = &http.Client{Timeout: 5 * time.Minute}
+
+// LLM drafts alt text with an OpenAI-compatible chat completions endpoint
+type LLM struct {
+	cfg   config.LLM
+	token string
+	image func(c *card.Card) string // Path of a card's image, or ""
+}
+
+// NewLLM returns a drafter for the configured endpoint. When the endpoint
+// accepts images, image returns the path of the image to send for a card.
+func NewLLM(cfg config.LLM, image func(c *card.Card) string) (*LLM, error) {
+	if cfg.URL == "" || cfg.Model == "" {
+		return nil, fmt.Errorf("[llm] in config.toml needs a url and a model")
+	}
+
+	l := &LLM{cfg: cfg, image: image}
+	if cfg.TokenEnv != "" {
+		if l.token = os.Getenv(cfg.TokenEnv); l.token == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", cfg.TokenEnv)
+		}
+	}
+	return l, nil
+}
+
+// Draft implements Drafter
+func (l *LLM) Draft(c *card.Card) (string, error) {
+	system := l.cfg.Prompt
+	if system == "" {
+		system = defaultSystemPrompt
+	}
+
+	text := fmt.Sprintf("Write alt text for the tarot card %s.", c.Name)
+	if keywords := Keywords(c); keywords != "" {
+		text += fmt.Sprintf(" Traditional imagery: %s.", keywords)
+	}
+	var content interface{} = text
+
+	if l.cfg.Vision && l.image != nil {
+		if path := l.image(c); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("error reading card image: %v", err)
+			}
+			dataURL := fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data))
+			content = []map[string]interface{}{
+				{"type": "text", "text": text + " Describe the attached card art, which may differ from the traditional imagery."},
+				{"type": "image_url", "image_url": map[string]string{"url": dataURL}},
+			}
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model": l.cfg.Model,
+		"messages": []map[string]interface{}{
+			{"role": "system", "content": system},
+			{"role": "user", "content": content},
+		},
+		"temperature": 0.2,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(l.cfg.URL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.token != "" {
+		req.Header.Set("Authorization", "Bearer "+l.token)
+	}
+	for key, value := range l.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := strings.TrimSpace(string(data))
+		if len(message) > 200 {
+			message = message[:200] + "..."
+		}
+		return "", fmt.Errorf("%s: %s", resp.Status, message)
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return "", fmt.Errorf("error decoding response: %v", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("response has no choices")
+	}
+
+	draft := strings.Join(strings.Fields(completion.Choices[0].Message.Content), " ")
+	return strings.Trim(draft, `"`), nil
+}
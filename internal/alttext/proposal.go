@@ -0,0 +1,49 @@
+package alttext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Proposal is drafted alt text for a card
+type Proposal struct {
+	Card *card.Card
+	Text string
+}
+
+// WriteProposals writes proposals as TOML in the layout of a names file's
+// alt_text tables, so reviewed entries can be copied into names/<lang>.toml.
+// Each line of header is written as a leading comment, and each entry is
+// preceded by a comment naming the card.
+func WriteProposals(w io.Writer, header string, proposals []Proposal) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range strings.Split(strings.TrimSpace(header), "\n") {
+		fmt.Fprintf(bw, "# %s\n", line)
+	}
+
+	table := ""
+	for _, p := range proposals {
+		var section, key string
+		switch {
+		case p.Card.Type == "major_arcana":
+			section, key = "alt_text.major_arcana", strconv.Quote(p.Card.Number)
+		case p.Card.Suit != "":
+			section, key = "alt_text.minor_arcana."+p.Card.Suit, p.Card.Rank
+		default:
+			continue
+		}
+
+		if section != table {
+			fmt.Fprintf(bw, "\n[%s]\n", section)
+			table = section
+		}
+		fmt.Fprintf(bw, "# %s\n%s = %s\n", p.Card.Name, key, strconv.Quote(p.Text))
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,131 @@
+// Package alttext drafts alt text for cards that lack it, from templates or
+// with a language model, for deck authors to review.
+package alttext
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// majorImagery describes the traditional imagery of each major arcana card
+var majorImagery = map[string]string{
+	"00": "A young traveller steps toward the edge of a cliff, carrying a small bundle, with a white dog at their heels",
+	"01": "A figure stands at a table holding a wand raised to the sky, with a cup, sword and pentacle laid before them",
+	"02": "A robed priestess sits between a dark and a light pillar, holding a scroll, with a crescent moon at her feet",
+	"03": "A crowned woman sits on a cushioned throne in a field of ripening wheat beside a flowing stream",
+	"04": "A bearded ruler in armour sits on a stone throne carved with rams' heads, holding a sceptre and orb",
+	"05": "A religious figure in a triple crown sits between two pillars, raising a hand in blessing over two kneeling followers",
+	"06": "Two lovers stand beneath a winged angel, a tree of fruit and a tree of flames behind them",
+	"07": "An armoured charioteer rides a chariot drawn by a black and a white sphinx",
+	"08": "A woman gently closes the jaws of a lion, an infinity symbol above her head",
+	"09": "A cloaked old man stands alone on a snowy peak, holding up a lantern with a star inside",
+	"10": "A great wheel marked with symbols turns in the clouds, surrounded by a sphinx, a serpent and winged creatures",
+	"11": "A crowned figure sits between two pillars, holding an upright sword in one hand and scales in the other",
+	"12": "A man hangs upside down by one foot from a living tree, a halo of light around his calm face",
+	"13": "A skeleton in black armour rides a white horse, carrying a banner with a white rose, as figures fall before it",
+	"14": "A winged angel pours water between two cups, one foot on land and one in a pool",
+	"15": "A horned devil crouches on a pedestal above a chained man and woman",
+	"16": "Lightning strikes a tall tower, blowing off its crown as flames burst out and figures fall",
+	"17": "A woman kneels by a pool pouring water from two jugs beneath a large bright star and seven smaller stars",
+	"18": "A full moon with a face shines between two towers, as a dog and a wolf howl and a crayfish crawls from a pool",
+	"19": "A smiling child rides a white horse beneath a radiant sun, sunflowers growing behind a wall",
+	"20": "An angel blows a trumpet from the clouds as people rise from their coffins with open arms",
+	"21": "A dancer wrapped in a sash floats inside a laurel wreath, with a figure in each corner",
+}
+
+// suitImagery describes the object of each suit and the scene it suggests
+var suitImagery = map[string]struct {
+	one   string // A single suit object
+	many  string // Several suit objects, after a count
+	scene string
+}{
+	"wands":     {"a wooden staff sprouting green leaves", "wooden staffs sprouting green leaves", "in a warm, sunlit landscape"},
+	"cups":      {"a golden cup", "golden cups", "near water"},
+	"swords":    {"a sword with a silver blade", "swords with silver blades", "under a windswept sky"},
+	"pentacles": {"a gold coin engraved with a pentacle", "gold coins engraved with pentacles", "among gardens and fields"},
+}
+
+// courtFigures describes the figure on each court card
+var courtFigures = map[string]string{
+	"page":   "A young page stands holding",
+	"knight": "A knight on horseback carries",
+	"queen":  "A crowned queen sits on a throne holding",
+	"king":   "A crowned king sits on a throne holding",
+}
+
+// countWords spells out the number of suit objects on the pip cards
+var countWords = []string{"", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten"}
+
+// Keywords returns the traditional imagery for a card, used by the built-in
+// template and available to custom templates
+func Keywords(c *card.Card) string {
+	if c.Type == "major_arcana" {
+		return majorImagery[c.Number]
+	}
+
+	suit, ok := suitImagery[c.Suit]
+	switch {
+	case !ok:
+		return ""
+	case courtFigures[c.Rank] != "":
+		return fmt.Sprintf("%s %s", courtFigures[c.Rank], suit.one)
+	case c.Value == 1:
+		return fmt.Sprintf("A hand emerges from a cloud holding %s, %s", suit.one, suit.scene)
+	case c.Value > 1 && c.Value < len(countWords):
+		return fmt.Sprintf("%s %s %s", countWords[c.Value], suit.many, suit.scene)
+	}
+	return ""
+}
+
+// TemplateData is passed to alt text templates
+type TemplateData struct {
+	*card.Card
+	Keywords string // Traditional imagery of the card
+}
+
+// defaultTemplate drafts alt text from the card's traditional imagery
+const defaultTemplate = `{{if .Keywords}}{{.Keywords}}.{{else}}The {{.Name}} card.{{end}}`
+
+// Template drafts alt text from a Go text/template
+type Template struct {
+	tmpl *template.Template
+}
+
+// DefaultTemplate returns the built-in template, which describes the
+// traditional imagery of each card
+func DefaultTemplate() *Template {
+	return &Template{tmpl: template.Must(template.New("alt_text").Parse(defaultTemplate))}
+}
+
+// LoadTemplate reads a template file. Templates receive the card fields
+// (.Name, .Suit, .Rank, .Number, .Element, ...) and .Keywords.
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template: %v", err)
+	}
+	tmpl, err := template.New("alt_text").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %v", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Draft implements Drafter
+func (t *Template) Draft(c *card.Card) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, TemplateData{Card: c, Keywords: Keywords(c)}); err != nil {
+		return "", fmt.Errorf("error executing template: %v", err)
+	}
+	return strings.Join(strings.Fields(buf.String()), " "), nil
+}
+
+// Drafter drafts alt text for a card
+type Drafter interface {
+	Draft(c *card.Card) (string, error)
+}
@@ -0,0 +1,328 @@
+// Package anki writes Anki deck packages (.apkg): a zip archive holding a
+// collection database in the Anki 2.1 legacy schema (version 11), which every
+// current Anki release imports, and the media files the notes refer to.
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/sqlite"
+)
+
+// fieldSeparator separates note fields in the notes table
+const fieldSeparator = "\x1f"
+
+// Package is an Anki deck with a single note type and one card per note
+type Package struct {
+	DeckName  string   // Anki deck name; "::" separates subdecks
+	ModelName string   // Note type name
+	Fields    []string // Note type field names; the first is the sort field
+	Front     string   // Front template, in Anki template syntax
+	Back      string   // Back template
+	CSS       string   // Styling shared by both sides
+	Notes     []Note
+	Media     []Media
+}
+
+// Note is a note with one value per field of the package's note type
+type Note struct {
+	GUID   string // Stable identifier, so importing again updates the note
+	Fields []string
+	Tags   []string
+}
+
+// Media is a file notes refer to by name, e.g. <img src="name">
+type Media struct {
+	Name string // File name used in fields
+	Path string // File to read the content from
+}
+
+// GUID returns a stable note identifier derived from the given parts
+func GUID(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:10])
+}
+
+// stableID derives a positive ID below 2^52 from a name, keeping IDs stable
+// across exports and within the range Anki handles as JSON numbers
+func stableID(parts ...string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(parts, "\x00")))
+	return int64(h.Sum64()%(1<<51)) + 1<<51
+}
+
+// Save writes the package to a file
+func (p *Package) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := p.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the package as a zip archive
+func (p *Package) Write(w io.Writer) error {
+	collection, err := os.CreateTemp("", "cartomancer-*.anki2")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(collection.Name())
+	collection.Close()
+
+	db, err := p.collection(time.Now())
+	if err != nil {
+		return err
+	}
+	if err := db.Save(collection.Name()); err != nil {
+		return fmt.Errorf("error writing collection: %v", err)
+	}
+
+	zw := zip.NewWriter(w)
+	if err := addFile(zw, "collection.anki2", collection.Name()); err != nil {
+		return err
+	}
+
+	// Media files are stored under their index, with a JSON map to their names
+	index := map[string]string{}
+	for i, m := range p.Media {
+		name := strconv.Itoa(i)
+		index[name] = m.Name
+		if err := addFile(zw, name, m.Path); err != nil {
+			return fmt.Errorf("error adding media %s: %v", m.Name, err)
+		}
+	}
+	mw, err := zw.Create("media")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(mw).Encode(index); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// addFile copies a file into the archive
+func addFile(zw *zip.Writer, name, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// collection builds the collection database
+func (p *Package) collection(now time.Time) (*sqlite.DB, error) {
+	if len(p.Fields) == 0 {
+		return nil, fmt.Errorf("note type %s has no fields", p.ModelName)
+	}
+
+	modelID := stableID("model", p.ModelName)
+	deckID := stableID("deck", p.DeckName)
+	secs, millis := now.Unix(), now.UnixMilli()
+
+	models, decks, err := p.collectionJSON(modelID, deckID, secs)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sqlite.New()
+	col := db.CreateTable("col", `CREATE TABLE col (
+    id              integer primary key,
+    crt             integer not null,
+    mod             integer not null,
+    scm             integer not null,
+    ver             integer not null,
+    dty             integer not null,
+    usn             integer not null,
+    ls              integer not null,
+    conf            text not null,
+    models          text not null,
+    decks           text not null,
+    dconf           text not null,
+    tags            text not null
+)`)
+	notes := db.CreateTable("notes", `CREATE TABLE notes (
+    id              integer primary key,
+    guid            text not null,
+    mid             integer not null,
+    mod             integer not null,
+    usn             integer not null,
+    tags            text not null,
+    flds            text not null,
+    sfld            integer not null,
+    csum            integer not null,
+    flags           integer not null,
+    data            text not null
+)`)
+	cards := db.CreateTable("cards", `CREATE TABLE cards (
+    id              integer primary key,
+    nid             integer not null,
+    did             integer not null,
+    ord             integer not null,
+    mod             integer not null,
+    usn             integer not null,
+    type            integer not null,
+    queue           integer not null,
+    due             integer not null,
+    ivl             integer not null,
+    factor          integer not null,
+    reps            integer not null,
+    lapses          integer not null,
+    left            integer not null,
+    odue            integer not null,
+    odid            integer not null,
+    flags           integer not null,
+    data            text not null
+)`)
+	db.CreateTable("revlog", `CREATE TABLE revlog (
+    id              integer primary key,
+    cid             integer not null,
+    usn             integer not null,
+    ivl             integer not null,
+    lastIvl         integer not null,
+    factor          integer not null,
+    time            integer not null,
+    type            integer not null
+)`)
+	db.CreateTable("graves", `CREATE TABLE graves (
+    usn             integer not null,
+    oid             integer not null,
+    type            integer not null
+)`)
+
+	conf := fmt.Sprintf(`{"activeDecks":[%d],"curDeck":%d,"curModel":"%d","nextPos":%d,"newSpread":0,"collapseTime":1200,"timeLim":0,"estTimes":true,"dueCounts":true,"sortType":"noteFld","sortBackwards":false,"addToCur":true}`,
+		deckID, deckID, modelID, len(p.Notes)+1)
+	if err := col.Insert(1, nil, secs, millis, millis, int64(11), int64(0), int64(0), int64(0),
+		conf, models, decks, defaultDeckConfig, "{}"); err != nil {
+		return nil, err
+	}
+
+	for i, n := range p.Notes {
+		if len(n.Fields) != len(p.Fields) {
+			return nil, fmt.Errorf("note %s has %d fields, note type %s has %d", n.GUID, len(n.Fields), p.ModelName, len(p.Fields))
+		}
+
+		noteID := stableID("note", n.GUID)
+		tags := ""
+		if len(n.Tags) > 0 {
+			tags = " " + strings.Join(n.Tags, " ") + " "
+		}
+		sum := sha1.Sum([]byte(n.Fields[0]))
+		checksum := int64(binary.BigEndian.Uint32(sum[:4]))
+
+		if err := notes.Insert(noteID, nil, n.GUID, modelID, secs, int64(-1), tags,
+			strings.Join(n.Fields, fieldSeparator), n.Fields[0], checksum, int64(0), ""); err != nil {
+			return nil, err
+		}
+
+		// New cards are shown in note order
+		if err := cards.Insert(stableID("card", n.GUID), nil, noteID, deckID, int64(0), secs, int64(-1),
+			int64(0), int64(0), int64(i+1), int64(0), int64(0), int64(0), int64(0), int64(0),
+			int64(0), int64(0), int64(0), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return db, nil
+}
+
+// collectionJSON returns the note type and deck definitions stored in the col table
+func (p *Package) collectionJSON(modelID, deckID, mod int64) (string, string, error) {
+	type field struct {
+		Name   string        `json:"name"`
+		Ord    int           `json:"ord"`
+		Sticky bool          `json:"sticky"`
+		RTL    bool          `json:"rtl"`
+		Font   string        `json:"font"`
+		Size   int           `json:"size"`
+		Media  []interface{} `json:"media"`
+	}
+	fields := make([]field, len(p.Fields))
+	fieldOrds := make([]int, len(p.Fields))
+	for i, name := range p.Fields {
+		fields[i] = field{Name: name, Ord: i, Font: "Arial", Size: 20, Media: []interface{}{}}
+		fieldOrds[i] = i
+	}
+
+	models := map[string]interface{}{
+		strconv.FormatInt(modelID, 10): map[string]interface{}{
+			"id":    modelID,
+			"name":  p.ModelName,
+			"type":  0,
+			"mod":   mod,
+			"usn":   -1,
+			"sortf": 0,
+			"did":   deckID,
+			"tmpls": []interface{}{map[string]interface{}{
+				"name": "Card 1", "ord": 0, "qfmt": p.Front, "afmt": p.Back,
+				"did": nil, "bqfmt": "", "bafmt": "",
+			}},
+			"flds":      fields,
+			"css":       p.CSS,
+			"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+			"latexPost": "\\end{document}",
+			"latexsvg":  false,
+			"req":       []interface{}{[]interface{}{0, "any", fieldOrds}},
+			"tags":      []string{},
+			"vers":      []interface{}{},
+		},
+	}
+
+	deck := func(id int64, name string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "name": name, "desc": "", "mod": mod, "usn": -1, "conf": 1, "dyn": 0,
+			"collapsed": false, "browserCollapsed": false, "extendNew": 0, "extendRev": 0,
+			"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+		}
+	}
+	decks := map[string]interface{}{
+		"1":                           deck(1, "Default"),
+		strconv.FormatInt(deckID, 10): deck(deckID, p.DeckName),
+	}
+
+	modelsJSON, err := json.Marshal(models)
+	if err != nil {
+		return "", "", err
+	}
+	decksJSON, err := json.Marshal(decks)
+	if err != nil {
+		return "", "", err
+	}
+	return string(modelsJSON), string(decksJSON), nil
+}
+
+// defaultDeckConfig is Anki's default deck options group
+const defaultDeckConfig = `{"1":{"id":1,"name":"Default","mod":0,"usn":0,"maxTaken":60,"autoplay":true,"timer":0,"replayq":true,"dyn":false,` +
+	`"new":{"bury":true,"delays":[1,10],"initialFactor":2500,"ints":[1,4,7],"order":1,"perDay":20,"separate":true},` +
+	`"lapse":{"delays":[10],"leechAction":0,"leechFails":8,"minInt":1,"mult":0},` +
+	`"rev":{"bury":true,"ease4":1.3,"fuzz":0.05,"ivlFct":1,"maxIvl":36500,"minSpace":1,"perDay":100}}}`
+
+// MediaName returns a media file name, prefixed to keep it apart from media
+// of other decks in the user's collection
+func MediaName(prefix, filename string) string {
+	return prefix + "-" + path.Base(strings.ReplaceAll(filename, "\\", "/"))
+}
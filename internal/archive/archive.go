@@ -0,0 +1,226 @@
+// Package archive creates deck archives and extracts them while guarding against hostile entries.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Limits applied while extracting untrusted archives
+const (
+	MaxEntries   = 10000     // Maximum number of entries in an archive
+	MaxFileSize  = 256 << 20 // Maximum size of a single extracted file
+	MaxTotalSize = 2 << 30   // Maximum combined size of all extracted files
+)
+
+// Permissions applied to extracted entries regardless of the archive's mode bits
+const (
+	DirMode  = 0755
+	FileMode = 0644
+)
+
+// Extract unpacks a .zip, .tar, .tar.gz or .tgz archive into dest, which must
+// not already contain files. Absolute paths, parent references, links, device
+// files and oversized entries are rejected.
+func Extract(src, dest string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %v", err)
+	}
+	defer f.Close()
+
+	if err := os.MkdirAll(dest, DirMode); err != nil {
+		return fmt.Errorf("error creating destination: %v", err)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return fmt.Errorf("error reading archive: %v", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading archive: %v", err)
+	}
+
+	e := &extractor{dest: dest}
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		info, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("error reading archive: %v", err)
+		}
+		return e.extractZip(f, info.Size())
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(bufio.NewReader(f))
+		if err != nil {
+			return fmt.Errorf("error reading gzip stream: %v", err)
+		}
+		defer gz.Close()
+		return e.extractTar(gz)
+	default:
+		return e.extractTar(f)
+	}
+}
+
+// extractor tracks state shared across the entries of one archive
+type extractor struct {
+	dest    string
+	entries int
+	total   int64
+}
+
+// extractZip extracts every entry of a zip archive
+func (e *extractor) extractZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("error reading zip archive: %v", err)
+	}
+
+	for _, file := range zr.File {
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			if err := e.makeDir(file.Name); err != nil {
+				return err
+			}
+		case mode.IsRegular():
+			rc, err := file.Open()
+			if err != nil {
+				return fmt.Errorf("error reading %s: %v", file.Name, err)
+			}
+			err = e.writeFile(file.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry type for %s: %v", file.Name, mode.Type())
+		}
+	}
+	return nil
+}
+
+// extractTar extracts every entry of a tar stream
+func (e *extractor) extractTar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading tar archive: %v", err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := e.makeDir(hdr.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			// Old tools write regular files as TypeRegA, and directories
+			// with it and a trailing slash
+			if hdr.Typeflag == tar.TypeRegA && strings.HasSuffix(hdr.Name, "/") {
+				if err := e.makeDir(hdr.Name); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := e.writeFile(hdr.Name, tr); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			// PAX metadata carries no file content
+		default:
+			return fmt.Errorf("unsupported entry type for %s: %c", hdr.Name, hdr.Typeflag)
+		}
+	}
+}
+
+// makeDir creates a directory entry inside the destination
+func (e *extractor) makeDir(name string) error {
+	// Many tarballs include an explicit entry for the root directory
+	if path.Clean(strings.ReplaceAll(name, `\`, "/")) == "." {
+		return nil
+	}
+
+	target, err := e.target(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(target, DirMode)
+}
+
+// writeFile copies a regular file entry into the destination, enforcing size limits
+func (e *extractor) writeFile(name string, r io.Reader) error {
+	target, err := e.target(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), DirMode); err != nil {
+		return fmt.Errorf("error creating directory for %s: %v", name, err)
+	}
+
+	// O_EXCL refuses to follow or overwrite anything already at the target
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, FileMode)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", name, err)
+	}
+
+	n, err := io.Copy(out, io.LimitReader(r, MaxFileSize+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error extracting %s: %v", name, err)
+	}
+	if n > MaxFileSize {
+		return fmt.Errorf("%s exceeds the %d byte file size limit", name, MaxFileSize)
+	}
+
+	e.total += n
+	if e.total > MaxTotalSize {
+		return fmt.Errorf("archive exceeds the %d byte total size limit", MaxTotalSize)
+	}
+	return nil
+}
+
+// target validates an entry name and returns its path inside the destination
+func (e *extractor) target(name string) (string, error) {
+	e.entries++
+	if e.entries > MaxEntries {
+		return "", fmt.Errorf("archive has more than %d entries", MaxEntries)
+	}
+
+	rel, err := CleanEntryName(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(e.dest, filepath.FromSlash(rel)), nil
+}
+
+// CleanEntryName normalizes an archive entry name to a slash-separated relative
+// path, rejecting absolute paths and references outside the archive root
+func CleanEntryName(name string) (string, error) {
+	normalized := strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(normalized, "/") || (len(normalized) >= 2 && normalized[1] == ':') {
+		return "", fmt.Errorf("absolute path in archive: %s", name)
+	}
+
+	cleaned := path.Clean(normalized)
+	if cleaned == "." || cleaned == "" {
+		return "", fmt.Errorf("empty path in archive: %q", name)
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("path escapes archive root: %s", name)
+	}
+	return cleaned, nil
+}
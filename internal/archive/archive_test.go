@@ -0,0 +1,228 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type entry struct {
+	name     string
+	body     string
+	typeflag byte
+	linkname string
+	mode     int64
+}
+
+func writeTar(t *testing.T, entries []entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: e.mode, Size: int64(len(e.body))}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0600
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size > 0 {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "deck.tar")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeZip(t *testing.T, names ...string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("data"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "deck.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractTar(t *testing.T) {
+	src := writeTar(t, []entry{
+		{name: "./", typeflag: tar.TypeDir},
+		{name: "deck/", typeflag: tar.TypeDir, mode: 0777},
+		{name: "deck/deck.toml", body: "[deck]\n", mode: 04777},
+	})
+	dest := t.TempDir()
+	if err := Extract(src, dest); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dest, "deck", "deck.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode() != FileMode {
+		t.Errorf("file mode = %v, want %v", info.Mode(), os.FileMode(FileMode))
+	}
+}
+
+func TestExtractTarLegacyRegularFiles(t *testing.T) {
+	src := writeTar(t, []entry{{name: "deck/deck.toml", body: "[deck]\n"}})
+
+	// tar.Writer always writes TypeReg, so set the legacy flag in the raw
+	// header and recompute its checksum
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := data[:512]
+	block[156] = tar.TypeRegA
+	copy(block[148:156], "        ")
+	var sum int64
+	for _, b := range block {
+		sum += int64(b)
+	}
+	copy(block[148:156], fmt.Sprintf("%06o\x00 ", sum))
+	if err := os.WriteFile(src, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := t.TempDir()
+	if err := Extract(src, dest); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "deck", "deck.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "[deck]\n" {
+		t.Errorf("deck.toml = %q, want %q", got, "[deck]\n")
+	}
+}
+
+func TestExtractRejectsHostileTar(t *testing.T) {
+	tests := map[string]entry{
+		"parent reference": {name: "../evil.txt", body: "x"},
+		"nested parent":    {name: "deck/../../evil.txt", body: "x"},
+		"absolute path":    {name: "/tmp/evil.txt", body: "x"},
+		"windows path":     {name: `C:\evil.txt`, body: "x"},
+		"symlink":          {name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"},
+		"hard link":        {name: "link", typeflag: tar.TypeLink, linkname: "/etc/passwd"},
+		"char device":      {name: "dev", typeflag: tar.TypeChar},
+		"fifo":             {name: "fifo", typeflag: tar.TypeFifo},
+	}
+	for name, e := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := Extract(writeTar(t, []entry{e}), t.TempDir()); err == nil {
+				t.Fatal("expected extraction to fail")
+			}
+		})
+	}
+}
+
+func TestExtractRejectsDuplicateEntries(t *testing.T) {
+	src := writeTar(t, []entry{{name: "a.txt", body: "1"}, {name: "a.txt", body: "2"}})
+	if err := Extract(src, t.TempDir()); err == nil {
+		t.Fatal("expected duplicate entry to fail")
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	dest := t.TempDir()
+	if err := Extract(writeZip(t, "deck/deck.toml", `deck\names\en.toml`), dest); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "deck", "names", "en.toml")); err != nil {
+		t.Errorf("backslash entry not normalized: %v", err)
+	}
+
+	if err := Extract(writeZip(t, "../../zip-slip.txt"), t.TempDir()); err == nil {
+		t.Fatal("expected zip-slip entry to fail")
+	}
+}
+
+func TestCreateTarGzRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "names"), 0755)
+	os.MkdirAll(filepath.Join(src, ".git"), 0755)
+	os.WriteFile(filepath.Join(src, "deck.toml"), []byte("[deck]"), 0600)
+	os.WriteFile(filepath.Join(src, "names", "en.toml"), []byte("names"), 0600)
+	os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref"), 0600)
+
+	var first, second bytes.Buffer
+	if err := CreateTarGz(&first, src, "deck"); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateTarGz(&second, src, "deck"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("archives of the same files differ")
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "deck.tar.gz")
+	if err := os.WriteFile(archivePath, first.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(t.TempDir(), "out")
+	if err := Extract(archivePath, dest); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dest, "deck", "names", "en.toml")); err != nil || string(data) != "names" {
+		t.Errorf("names/en.toml = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "deck", ".git")); !os.IsNotExist(err) {
+		t.Error("hidden directory was archived")
+	}
+}
+
+func TestCreateTarGzIgnoresFileMetadata(t *testing.T) {
+	build := func(mode os.FileMode, mtime time.Time) []byte {
+		src := t.TempDir()
+		os.MkdirAll(filepath.Join(src, "major_arcana"), 0700)
+		os.WriteFile(filepath.Join(src, "deck.toml"), []byte("[deck]"), mode)
+		os.WriteFile(filepath.Join(src, "major_arcana", "00.png"), []byte("png"), mode)
+		os.Chtimes(filepath.Join(src, "deck.toml"), mtime, mtime)
+
+		var buf bytes.Buffer
+		if err := CreateTarGz(&buf, src, "deck"); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	a := build(0600, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := build(0755, time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC))
+	if !bytes.Equal(a, b) {
+		t.Error("archives differ when only modes and timestamps differ")
+	}
+}
@@ -0,0 +1,108 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Walk calls fn, in lexical order, for every directory and file under root that
+// belongs in a deck package. Hidden files and directories and those named in the
+// deck's .deckignore are skipped, and rel is the slash-separated path relative
+// to root.
+func Walk(root string, fn func(rel string, entry fs.DirEntry) error) error {
+	ig, err := LoadIgnore(root)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || ig.Match(filepath.ToSlash(rel), entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			// Links and special files would be rejected by Extract
+			return fmt.Errorf("unsupported file type for %s: %v", rel, entry.Type())
+		}
+		return fn(filepath.ToSlash(rel), entry)
+	})
+}
+
+// CreateTarGz writes the regular files under root to a gzipped tar stream, with
+// entry names under prefix. Entries are written in lexical order with fixed
+// timestamps, modes and owners, and without a gzip name or timestamp, so that
+// packaging the same files on any machine produces identical archives.
+func CreateTarGz(w io.Writer, root, prefix string) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return fmt.Errorf("error creating archive: %v", err)
+	}
+	tw := tar.NewWriter(gz)
+
+	err = Walk(root, func(rel string, entry fs.DirEntry) error {
+		hdr := &tar.Header{Name: path.Join(prefix, rel), ModTime: time.Unix(0, 0), Format: tar.FormatPAX}
+
+		if entry.IsDir() {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+			hdr.Mode = DirMode
+			return tw.WriteHeader(hdr)
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		hdr.Typeflag = tar.TypeReg
+		hdr.Mode = FileMode
+		hdr.Size = info.Size()
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		return copyFile(tw, filepath.Join(root, filepath.FromSlash(rel)))
+	})
+	if err != nil {
+		return fmt.Errorf("error creating archive: %v", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error creating archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error creating archive: %v", err)
+	}
+	return nil
+}
+
+// copyFile copies the contents of the file at p to w
+func copyFile(w io.Writer, p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,210 @@
+package archive
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile is the file at a deck's root listing the working files, such as
+// layered sources and editor leftovers, that are left out of its packages
+const IgnoreFile = ".deckignore"
+
+// Ignore holds the patterns of a .deckignore file, which follow the rules of
+// .gitignore:
+//
+//	# Layered sources, anywhere in the deck
+//	*.psd
+//	# A directory and everything in it
+//	sources/
+//	# Paths from the deck root, at any depth below h750
+//	/h750/**/drafts
+//	# Re-included after an earlier pattern
+//	!card_backs/print.psd
+//
+// A pattern without a slash, other than a trailing one, matches a name in any
+// directory; one with a slash matches the path from the deck root. "*" and "?"
+// match within a name, "**" matches any number of directories and a trailing
+// slash matches directories only. "!" re-includes what an earlier pattern
+// ignored, unless a directory containing it is ignored, and the last matching
+// pattern decides. Comments and blank lines are skipped, and "\#" and "\!"
+// match a leading "#" or "!".
+type Ignore struct {
+	patterns []ignorePattern
+}
+
+// ignorePattern is one line of a .deckignore file
+type ignorePattern struct {
+	re      *regexp.Regexp // Matches the slash-separated path from the deck root
+	negate  bool           // Re-includes matching paths
+	dirOnly bool           // Matches directories only
+}
+
+// LoadIgnore reads the .deckignore file at root. A deck without one ignores
+// nothing.
+func LoadIgnore(root string) (*Ignore, error) {
+	f, err := os.Open(filepath.Join(root, IgnoreFile))
+	if os.IsNotExist(err) {
+		return &Ignore{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", IgnoreFile, err)
+	}
+	defer f.Close()
+
+	ig := &Ignore{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		p, ok, err := parseIgnorePattern(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid pattern %q: %v", IgnoreFile, line, scanner.Text(), err)
+		}
+		if ok {
+			ig.patterns = append(ig.patterns, p)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", IgnoreFile, err)
+	}
+	return ig, nil
+}
+
+// parseIgnorePattern parses a line of a .deckignore file, reporting false
+// for comments and blank lines
+func parseIgnorePattern(line string) (ignorePattern, bool, error) {
+	// Trailing spaces are dropped unless escaped
+	text := strings.TrimLeft(line, " \t")
+	for strings.HasSuffix(text, " ") && !strings.HasSuffix(text, "\\ ") {
+		text = text[:len(text)-1]
+	}
+	if text == "" || strings.HasPrefix(text, "#") {
+		return ignorePattern{}, false, nil
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(text, "!") {
+		p.negate, text = true, text[1:]
+	} else if strings.HasPrefix(text, "\\!") || strings.HasPrefix(text, "\\#") {
+		text = text[1:]
+	}
+	if strings.HasSuffix(text, "/") {
+		p.dirOnly, text = true, strings.TrimRight(text, "/")
+	}
+	if text == "" {
+		return ignorePattern{}, false, fmt.Errorf("empty pattern")
+	}
+
+	anchored := strings.Contains(text, "/")
+	text = strings.TrimPrefix(text, "/")
+	expr, err := globToRegexp(text)
+	if err != nil {
+		return ignorePattern{}, false, err
+	}
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	p.re, err = regexp.Compile("^" + expr + "$")
+	return p, true, err
+}
+
+// globToRegexp translates a gitignore glob to a regular expression
+func globToRegexp(glob string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**") && i > 0 && glob[i-1] == '/' && i+2 == len(glob):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String(), nil
+}
+
+// Match reports whether a slash-separated path relative to the deck root is
+// ignored, either itself or because a directory containing it is. The
+// receiver may be nil.
+func (ig *Ignore) Match(rel string, dir bool) bool {
+	if ig == nil {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		if ig.matchOne(strings.Join(parts[:i+1], "/"), dir || i < len(parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOne applies the patterns to a path, without its parents. The last
+// matching pattern decides.
+func (ig *Ignore) matchOne(rel string, dir bool) bool {
+	ignored := false
+	for _, p := range ig.patterns {
+		if (!p.dirOnly || dir) && p.re.MatchString(rel) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// RemoveIgnored deletes the files and directories a deck's .deckignore names,
+// for decks unpacked from archives that were not built by package
+func RemoveIgnored(root string) error {
+	ig, err := LoadIgnore(root)
+	if err != nil || len(ig.patterns) == 0 {
+		return err
+	}
+
+	var remove []string
+	err = filepath.WalkDir(root, func(p string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if ig.Match(filepath.ToSlash(rel), entry.IsDir()) {
+			remove = append(remove, p)
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error applying %s: %v", IgnoreFile, err)
+	}
+	for _, p := range remove {
+		if err := os.RemoveAll(p); err != nil {
+			return fmt.Errorf("error applying %s: %v", IgnoreFile, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package archive
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIgnore(t *testing.T) {
+	root := t.TempDir()
+	ignore := `# Working files
+*.psd
+!card_backs/print.psd
+sources/
+h750/*/drafts
+/scalable/**/wip
+**/old/*.png
+\#notes.txt
+exports/**
+
+`
+	if err := os.WriteFile(filepath.Join(root, IgnoreFile), []byte(ignore), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ig, err := LoadIgnore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel  string
+		dir  bool
+		want bool
+	}{
+		{"h750/major_arcana/00.psd", false, true},
+		{"h750/major_arcana/00.png", false, false},
+		{"sources", true, true},
+		{"sources", false, false},
+		{"sources/fool.kra", false, true},
+		{"h750/sources/fool.kra", false, true},
+		{"h750/major_arcana/drafts/00.png", false, true},
+		{"scalable/major_arcana/drafts/00.svg", false, false},
+		{"card_backs/print.psd", false, false},
+		{"scalable/wip", true, true},
+		{"scalable/major_arcana/wip/00.svg", false, true},
+		{"h750/scalable/wip", true, false},
+		{"old/00.png", false, true},
+		{"h750/major_arcana/old/00.png", false, true},
+		{"h750/major_arcana/old/00.svg", false, false},
+		{"#notes.txt", false, true},
+		{"exports/a/b.png", false, true},
+		{"exports", true, false},
+	}
+	for _, tt := range tests {
+		if got := ig.Match(tt.rel, tt.dir); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.rel, tt.dir, got, tt.want)
+		}
+	}
+
+	var none *Ignore
+	if none.Match("00.psd", false) {
+		t.Error("nil Ignore matched a path")
+	}
+}
+
+func TestWalkHonorsIgnore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		IgnoreFile:         "*.psd\nsources/\n",
+		"deck.toml":        "",
+		"h750/00.png":      "",
+		"h750/00.psd":      "",
+		"sources/fool.kra": "",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	err := Walk(root, func(rel string, entry fs.DirEntry) error {
+		got = append(got, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"deck.toml", "h750", "h750/00.png"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk visited %v, want %v", got, want)
+	}
+
+	if err := RemoveIgnored(root); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"h750/00.psd", "sources"} {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", name)
+		}
+	}
+}
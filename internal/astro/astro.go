@@ -0,0 +1,74 @@
+// Package astro computes lunar phase and sun sign for annotating readings.
+// The calculations are approximate but accurate to within a few hours, which
+// is sufficient for timing notes and needs no network or ephemeris data.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+// SynodicMonth is the mean length of a lunar cycle in days
+const SynodicMonth = 29.530588853
+
+// referenceNewMoon is a known new moon used as the epoch for phase calculations
+var referenceNewMoon = time.Date(2000, time.January, 6, 18, 14, 0, 0, time.UTC)
+
+// phaseNames lists the eight named lunar phases starting at the new moon
+var phaseNames = []string{
+	"New Moon", "Waxing Crescent", "First Quarter", "Waxing Gibbous",
+	"Full Moon", "Waning Gibbous", "Last Quarter", "Waning Crescent",
+}
+
+// signNames lists the tropical zodiac signs starting at 0° ecliptic longitude
+var signNames = []string{
+	"Aries", "Taurus", "Gemini", "Cancer", "Leo", "Virgo",
+	"Libra", "Scorpio", "Sagittarius", "Capricorn", "Aquarius", "Pisces",
+}
+
+// MoonPhase describes the moon at a moment in time
+type MoonPhase struct {
+	Name         string  // One of the eight named phases
+	Age          float64 // Days since the last new moon
+	Illumination float64 // Illuminated fraction of the disc, from 0 to 1
+}
+
+// Moon returns the lunar phase at t
+func Moon(t time.Time) MoonPhase {
+	days := t.Sub(referenceNewMoon).Hours() / 24
+	age := math.Mod(days, SynodicMonth)
+	if age < 0 {
+		age += SynodicMonth
+	}
+
+	// Each named phase spans an eighth of the cycle, centred on its moment
+	index := int(math.Floor(age/SynodicMonth*8+0.5)) % 8
+
+	return MoonPhase{
+		Name:         phaseNames[index],
+		Age:          age,
+		Illumination: (1 - math.Cos(2*math.Pi*age/SynodicMonth)) / 2,
+	}
+}
+
+// SunSign returns the tropical zodiac sign the sun occupies at t
+func SunSign(t time.Time) string {
+	return signNames[int(SunLongitude(t)/30)%12]
+}
+
+// SunLongitude returns the sun's apparent ecliptic longitude at t in degrees,
+// using the low-precision formula from the Astronomical Almanac
+func SunLongitude(t time.Time) float64 {
+	// Days since the J2000.0 epoch
+	n := t.Sub(time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)).Hours() / 24
+
+	meanLongitude := 280.460 + 0.9856474*n
+	meanAnomaly := (357.528 + 0.9856003*n) * math.Pi / 180
+
+	longitude := meanLongitude + 1.915*math.Sin(meanAnomaly) + 0.020*math.Sin(2*meanAnomaly)
+	longitude = math.Mod(longitude, 360)
+	if longitude < 0 {
+		longitude += 360
+	}
+	return longitude
+}
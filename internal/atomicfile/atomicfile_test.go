@@ -0,0 +1,97 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileReplaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.ansi")
+	if err := WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("content = %q, want %q", data, "new")
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestWriteFailureKeepsOldContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daily.toml")
+	if err := WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("encoding failed")
+	err := Write(path, 0644, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return failed
+	})
+	if err != failed {
+		t.Fatalf("Write error = %v, want %v", err, failed)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "old" {
+		t.Errorf("content = %q after a failed write, want %q", data, "old")
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestConcurrentWritersLeaveOneCompleteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "card.ansi")
+	contents := []string{"aaaaaaaa", "bbbbbbbb", "cccccccc", "dddddddd"}
+
+	var wg sync.WaitGroup
+	for _, content := range contents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if err := WriteFile(path, []byte(content), 0644); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(path)
+	complete := false
+	for _, content := range contents {
+		complete = complete || string(data) == content
+	}
+	if !complete {
+		t.Errorf("content = %q, want one writer's complete content", data)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+// assertNoTempFiles fails if a directory holds anything but its one file
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("directory holds %v, want only the written file", names)
+	}
+}
@@ -0,0 +1,107 @@
+// Package bake keeps the manifest of the ANSI art baked into a deck, so that
+// rebaking only renders the cards whose source image or render settings
+// changed since the last bake.
+package bake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/arcanaland/cartomancer/internal/atomicfile"
+)
+
+// ManifestFile is the manifest's name in the directory of baked art. It is
+// hidden, so it is left out of deck packages.
+const ManifestFile = ".bake.json"
+
+// manifestVersion changes whenever baked output does, forcing a full rebake
+const manifestVersion = 1
+
+// Manifest records how each card's baked art was made. A missing or outdated
+// manifest is empty, so every card is baked again.
+type Manifest struct {
+	path  string
+	cards map[string]Entry
+}
+
+// Entry is the source and settings a card's art was baked from
+type Entry struct {
+	Source string `json:"source"` // Source image, relative to the deck root
+	SHA256 string `json:"sha256"` // Checksum of the source image
+	Params string `json:"params"` // Render settings
+}
+
+// manifestFile is the on-disk form of a Manifest
+type manifestFile struct {
+	Version int              `json:"version"`
+	Cards   map[string]Entry `json:"cards"`
+}
+
+// Load reads the manifest in a directory of baked art
+func Load(dir string) *Manifest {
+	m := &Manifest{path: filepath.Join(dir, ManifestFile), cards: map[string]Entry{}}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		return m
+	}
+	var file manifestFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != manifestVersion || file.Cards == nil {
+		return m
+	}
+	m.cards = file.Cards
+	return m
+}
+
+// Fresh reports whether a card was last baked from the same source and
+// settings
+func (m *Manifest) Fresh(id string, e Entry) bool {
+	last, ok := m.cards[id]
+	return ok && last == e
+}
+
+// Record notes that a card was baked from a source and settings
+func (m *Manifest) Record(id string, e Entry) {
+	m.cards[id] = e
+}
+
+// Forget removes a card, so that it is baked again next time
+func (m *Manifest) Forget(id string) {
+	delete(m.cards, id)
+}
+
+// Save writes the manifest
+func (m *Manifest) Save() error {
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("error creating bake directory: %v", err)
+	}
+	err := atomicfile.Write(m.path, 0644, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifestFile{Version: manifestVersion, Cards: m.cards})
+	})
+	if err != nil {
+		return fmt.Errorf("error writing bake manifest: %v", err)
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 checksum of a file
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
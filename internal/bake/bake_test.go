@@ -0,0 +1,58 @@
+package bake
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "00.png")
+	if err := os.WriteFile(source, []byte("fool"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := HashFile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := Entry{Source: "h750/major_arcana/00.png", SHA256: sum, Params: "40x32"}
+
+	m := Load(dir)
+	if m.Fresh("major_arcana.00", entry) {
+		t.Fatal("empty manifest reported a card as fresh")
+	}
+	m.Record("major_arcana.00", entry)
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	m = Load(dir)
+	if !m.Fresh("major_arcana.00", entry) {
+		t.Error("recorded card is not fresh after reloading")
+	}
+	changed := entry
+	changed.Params = "60x48"
+	if m.Fresh("major_arcana.00", changed) {
+		t.Error("card is fresh after its settings changed")
+	}
+
+	if err := os.WriteFile(source, []byte("the fool"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if sum2, _ := HashFile(source); sum2 == sum {
+		t.Error("checksum did not change with the file")
+	}
+
+	m.Forget("major_arcana.00")
+	if m.Fresh("major_arcana.00", entry) {
+		t.Error("forgotten card is still fresh")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(`{"version": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if Load(dir).Fresh("major_arcana.00", entry) {
+		t.Error("outdated manifest was used")
+	}
+}
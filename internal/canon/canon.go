@@ -0,0 +1,118 @@
+// Package canon holds the tables of the standard 78-card tarot deck: its
+// suits, ranks and major arcana numbers in spec order and the position of
+// every card. Every module that iterates the standard deck goes through it,
+// so draw order, validation and exports cannot drift apart.
+package canon
+
+import "fmt"
+
+// Sizes of the standard deck
+const (
+	MajorCount   = 22                                  // Major arcana, numbered 0-21
+	RanksPerSuit = 14                                  // Ace to ten, then four courts
+	SuitCount    = 4                                   // Wands, cups, swords and pentacles
+	DeckSize     = MajorCount + SuitCount*RanksPerSuit // 78
+)
+
+// suits and ranks are the minor arcana suits and ranks in spec order
+var (
+	suits = [SuitCount]string{"wands", "cups", "swords", "pentacles"}
+	ranks = [RanksPerSuit]string{
+		"ace", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"page", "knight", "queen", "king",
+	}
+)
+
+// firstCourt is the index of the page, the lowest court rank
+const firstCourt = 10
+
+// Suits returns the standard suits in spec order
+func Suits() []string {
+	return append([]string(nil), suits[:]...)
+}
+
+// Ranks returns the standard ranks in spec order, ace to king
+func Ranks() []string {
+	return append([]string(nil), ranks[:]...)
+}
+
+// Courts returns the court ranks in spec order, page to king
+func Courts() []string {
+	return append([]string(nil), ranks[firstCourt:]...)
+}
+
+// MajorNumbers returns the two-digit numbers of the major arcana, "00" to "21"
+func MajorNumbers() []string {
+	numbers := make([]string, MajorCount)
+	for i := range numbers {
+		numbers[i] = fmt.Sprintf("%02d", i)
+	}
+	return numbers
+}
+
+// SuitIndex returns the position of a standard suit in spec order, or -1
+func SuitIndex(suit string) int {
+	for i, s := range suits {
+		if s == suit {
+			return i
+		}
+	}
+	return -1
+}
+
+// RankIndex returns the position of a standard rank in spec order, or -1
+func RankIndex(rank string) int {
+	for i, r := range ranks {
+		if r == rank {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsSuit reports whether s is a standard suit
+func IsSuit(s string) bool {
+	return SuitIndex(s) >= 0
+}
+
+// IsCourt reports whether a rank is one of the court ranks
+func IsCourt(rank string) bool {
+	return RankIndex(rank) >= firstCourt
+}
+
+// MinorIndex returns the position in the standard deck of the card with the
+// given suit and rank positions
+func MinorIndex(suit, rank int) int {
+	return MajorCount + suit*RanksPerSuit + rank
+}
+
+// MajorID returns the canonical ID of a major arcana card by number
+func MajorID(number int) string {
+	return fmt.Sprintf("major_arcana.%02d", number)
+}
+
+// MinorID returns the canonical ID of a minor arcana card
+func MinorID(suit, rank string) string {
+	return "minor_arcana." + suit + "." + rank
+}
+
+// At returns the canonical ID of the card at a position of the standard deck
+func At(index int) (string, bool) {
+	switch {
+	case index < 0 || index >= DeckSize:
+		return "", false
+	case index < MajorCount:
+		return MajorID(index), true
+	}
+	minor := index - MajorCount
+	return MinorID(suits[minor/RanksPerSuit], ranks[minor%RanksPerSuit]), true
+}
+
+// Each calls fn with the position and canonical ID of every card of the
+// standard deck, in spec order
+func Each(fn func(index int, id string)) {
+	for i := 0; i < DeckSize; i++ {
+		id, _ := At(i)
+		fn(i, id)
+	}
+}
@@ -0,0 +1,63 @@
+package canon
+
+import "testing"
+
+func TestAt(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "major_arcana.00"},
+		{21, "major_arcana.21"},
+		{22, "minor_arcana.wands.ace"},
+		{35, "minor_arcana.wands.king"},
+		{36, "minor_arcana.cups.ace"},
+		{77, "minor_arcana.pentacles.king"},
+	}
+	for _, tt := range tests {
+		if got, ok := At(tt.index); !ok || got != tt.want {
+			t.Errorf("At(%d) = %q, %v, want %q", tt.index, got, ok, tt.want)
+		}
+	}
+	for _, index := range []int{-1, DeckSize} {
+		if got, ok := At(index); ok {
+			t.Errorf("At(%d) = %q, want out of range", index, got)
+		}
+	}
+}
+
+func TestEachMatchesIndexes(t *testing.T) {
+	seen := make(map[string]bool)
+	count := 0
+	Each(func(index int, id string) {
+		if index != count {
+			t.Errorf("Each visited index %d out of order", index)
+		}
+		if seen[id] {
+			t.Errorf("Each visited %s twice", id)
+		}
+		seen[id] = true
+		count++
+	})
+	if count != DeckSize {
+		t.Errorf("Each visited %d cards, want %d", count, DeckSize)
+	}
+
+	for s, suit := range Suits() {
+		for r, rank := range Ranks() {
+			if id, _ := At(MinorIndex(s, r)); id != MinorID(suit, rank) {
+				t.Errorf("At(MinorIndex(%d, %d)) = %s, want %s", s, r, id, MinorID(suit, rank))
+			}
+		}
+	}
+}
+
+func TestCourts(t *testing.T) {
+	courts := Courts()
+	if len(courts) != 4 || courts[0] != "page" || courts[3] != "king" {
+		t.Errorf("Courts() = %v", courts)
+	}
+	if !IsCourt("queen") || IsCourt("ten") || IsCourt("princess") {
+		t.Error("IsCourt misclassifies ranks")
+	}
+}
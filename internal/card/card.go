@@ -1,5 +1,13 @@
 package card
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/canon"
+)
+
 // Card represents a tarot card
 type Card struct {
 	ID      string // Canonical ID (e.g., major_arcana.00, minor_arcana.wands.ace)
@@ -9,4 +17,117 @@ type Card struct {
 	Suit    string // For minor arcana (wands, cups, swords, pentacles)
 	Rank    string // For minor arcana (ace, two, ..., king)
 	AltText string // Descriptive alt text
-}
\ No newline at end of file
+	Credit  Credit // Artist, source and license of the card art, when the deck declares them
+
+	// Content warnings for the card's imagery, such as "nudity", including
+	// those given for the whole deck
+	ContentWarnings []string
+
+	// Derived fields, populated by NewMajorArcana and NewMinorArcana
+	Value   int    // Major arcana number, 1-10 for pips, 11-14 for courts
+	Index   int    // Position in a standard 78-card deck (0-77)
+	Element string // For minor arcana (fire, water, air, earth)
+	IsCourt bool   // For minor arcana page, knight, queen and king
+}
+
+// Credit records who made a card's art, where it came from and its license
+type Credit struct {
+	Artist  string
+	Source  string // URL or description of the original work
+	License string // SPDX license expression
+}
+
+// suitElements maps each suit to its classical element
+var suitElements = map[string]string{
+	"wands":     "fire",
+	"cups":      "water",
+	"swords":    "air",
+	"pentacles": "earth",
+}
+
+// NewMajorArcana creates a major arcana card with its derived fields populated
+func NewMajorArcana(number int) *Card {
+	if number >= 0 && number < len(majorIDs) {
+		return &Card{
+			ID:     majorIDs[number],
+			Type:   "major_arcana",
+			Number: majorNumbers[number],
+			Value:  number,
+			Index:  number,
+		}
+	}
+	return &Card{
+		ID:     canon.MajorID(number),
+		Type:   "major_arcana",
+		Number: fmt.Sprintf("%02d", number),
+		Value:  number,
+		Index:  number,
+	}
+}
+
+// NewMinorArcana creates a minor arcana card with its derived fields populated
+func NewMinorArcana(suit, rank string) *Card {
+	s, r, ok := standardMinor(suit, rank)
+	if !ok {
+		c := &Card{
+			ID:      canon.MinorID(suit, rank),
+			Type:    "minor_arcana",
+			Suit:    suit,
+			Rank:    rank,
+			Element: suitElements[suit],
+		}
+		if r >= 0 {
+			c.Value = r + 1
+			c.IsCourt = c.Value > 10
+		}
+		if s >= 0 {
+			c.Index = canon.MinorIndex(s, r)
+		}
+		return c
+	}
+
+	value := r + 1
+	return &Card{
+		ID:      minorIDs[s][r],
+		Type:    "minor_arcana",
+		Suit:    suits[s],
+		Rank:    ranks[r],
+		Element: suitElements[suits[s]],
+		Value:   value,
+		IsCourt: value > 10,
+		Index:   canon.MinorIndex(s, r),
+	}
+}
+
+// IsPip reports whether the card is a numbered minor arcana card (ace to ten)
+func (c *Card) IsPip() bool {
+	return c.Type == "minor_arcana" && !c.IsCourt && c.Value > 0
+}
+
+// Numbering styles for displaying major arcana numbers
+const (
+	NumberingArabic = "arabic" // 7, 17
+	NumberingPadded = "padded" // 07, 17
+	NumberingRoman  = "roman"  // VII, XVII
+)
+
+// FormatNumber formats a major arcana number using the given numbering style.
+// An empty style selects the padded form used by canonical IDs.
+func FormatNumber(number string, style string) (string, error) {
+	num, err := strconv.Atoi(number)
+	if err != nil {
+		return "", fmt.Errorf("invalid major arcana number: %s", number)
+	}
+
+	switch strings.ToLower(style) {
+	case NumberingArabic:
+		return strconv.Itoa(num), nil
+	case NumberingPadded, "":
+		return fmt.Sprintf("%02d", num), nil
+	case NumberingRoman:
+		return ToRoman(num), nil
+	}
+
+	return "", fmt.Errorf("unknown numbering style: %s (supported: %s, %s, %s)",
+		style, NumberingArabic, NumberingPadded, NumberingRoman)
+}
@@ -0,0 +1,200 @@
+package card
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/canon"
+	"github.com/arcanaland/cartomancer/internal/suggest"
+)
+
+// suits and ranks list the canonical minor arcana suits and ranks in deck order
+var (
+	suits = canon.Suits()
+	ranks = canon.Ranks()
+)
+
+// AcceptedIDFormats describes the card ID notations understood by ParseID
+const AcceptedIDFormats = `accepted formats:
+  major_arcana.17, 17, XVII            (major arcana by number or Roman numeral)
+  minor_arcana.wands.ace, wands.ace    (minor arcana by suit and rank)
+  wands.1, cups/queen, CUPS.Queen      (numeric ranks 1-14, '/' separators, any case)
+  custom_cards.major_arcana.<id>       (custom cards declared in deck.toml)
+  stars.ace, custom_cards.minor_arcana.stars.ace
+                                       (cards of custom suits declared in deck.toml)`
+
+// ErrInvalidCardID is wrapped by the errors of ParseID
+var ErrInvalidCardID = errors.New("invalid card ID")
+
+// ParseID normalizes a card ID given in any accepted notation to its canonical form
+func ParseID(input string) (string, error) {
+	id := strings.ToLower(strings.TrimSpace(input))
+	id = strings.ReplaceAll(id, "/", ".")
+
+	if id == "" {
+		return "", fmt.Errorf("%w: empty\n%s", ErrInvalidCardID, AcceptedIDFormats)
+	}
+
+	parts := strings.Split(id, ".")
+
+	switch {
+	case len(parts) == 1:
+		// Bare number or Roman numeral refers to the major arcana
+		if num, ok := parseMajorNumber(parts[0]); ok {
+			return fmt.Sprintf("major_arcana.%02d", num), nil
+		}
+	case parts[0] == "major_arcana" && len(parts) == 2:
+		if num, ok := parseMajorNumber(parts[1]); ok {
+			return fmt.Sprintf("major_arcana.%02d", num), nil
+		}
+	case parts[0] == "minor_arcana" && len(parts) == 3:
+		if rank, ok := parseRank(parts[2]); ok && isSuit(parts[1]) {
+			return fmt.Sprintf("minor_arcana.%s.%s", parts[1], rank), nil
+		}
+	case len(parts) == 2 && isSuit(parts[0]):
+		if rank, ok := parseRank(parts[1]); ok {
+			return fmt.Sprintf("minor_arcana.%s.%s", parts[0], rank), nil
+		}
+	case parts[0] == "custom_cards" && (len(parts) == 3 || len(parts) == 4):
+		return id, nil
+	}
+
+	return "", fmt.Errorf("%w: %s\n%s", ErrInvalidCardID, input, AcceptedIDFormats)
+}
+
+// parseMajorNumber parses an Arabic or Roman major arcana number in the range 0-21
+func parseMajorNumber(s string) (int, bool) {
+	if num, err := strconv.Atoi(s); err == nil {
+		return num, num >= 0 && num < canon.MajorCount
+	}
+
+	num, ok := ParseRoman(s)
+	return num, ok && num < canon.MajorCount
+}
+
+// parseRank parses a rank name or a number from 1 (ace) to 14 (king)
+func parseRank(s string) (string, bool) {
+	if num, err := strconv.Atoi(s); err == nil {
+		if num < 1 || num > len(ranks) {
+			return "", false
+		}
+		return ranks[num-1], true
+	}
+
+	for _, rank := range ranks {
+		if rank == s {
+			return rank, true
+		}
+	}
+	return "", false
+}
+
+// isSuit reports whether s is a canonical suit name
+func isSuit(s string) bool {
+	return canon.IsSuit(s)
+}
+
+// ParseRoman parses a Roman numeral (case-insensitive). "0" and "nulla" are accepted for zero.
+func ParseRoman(s string) (int, bool) {
+	s = strings.ToUpper(s)
+	if s == "0" || s == "NULLA" {
+		return 0, true
+	}
+	if s == "" {
+		return 0, false
+	}
+
+	values := map[byte]int{'I': 1, 'V': 5, 'X': 10, 'L': 50, 'C': 100, 'D': 500, 'M': 1000}
+
+	total := 0
+	for i := 0; i < len(s); i++ {
+		v, ok := values[s[i]]
+		if !ok {
+			return 0, false
+		}
+		if i+1 < len(s) && values[s[i+1]] > v {
+			total -= v
+		} else {
+			total += v
+		}
+	}
+
+	// Reject non-canonical forms such as IIII or VX
+	if ToRoman(total) != s {
+		return 0, false
+	}
+
+	return total, true
+}
+
+// ToRoman formats a non-negative integer as a Roman numeral. Zero is rendered as "0".
+func ToRoman(n int) string {
+	if n <= 0 {
+		return "0"
+	}
+
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+		{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+		{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+	}
+
+	var b strings.Builder
+	for _, numeral := range numerals {
+		for n >= numeral.value {
+			b.WriteString(numeral.symbol)
+			n -= numeral.value
+		}
+	}
+	return b.String()
+}
+
+// WithSuggestion adds the card SuggestID finds for input to an error from
+// ParseID, as in "invalid card ID: pentacle.ace (did you mean ...?)"
+func WithSuggestion(input string, err error) error {
+	suggestion := SuggestID(input)
+	if suggestion == "" || !errors.Is(err, ErrInvalidCardID) {
+		return err
+	}
+	return fmt.Errorf("%w: %s (did you mean %s?)\n%s", ErrInvalidCardID, input, suggestion, AcceptedIDFormats)
+}
+
+// SuggestID returns the canonical ID of the card a mistyped ID most likely
+// meant, or "" when none is close. Card names and slugs resolve directly;
+// anything else is matched against the canonical IDs, the short suit.rank
+// forms and the English names of the standard deck.
+func SuggestID(input string) string {
+	if id, _, err := ParseName(input); err == nil {
+		return id
+	}
+
+	var forms, ids []string
+	add := func(form, id string) {
+		forms = append(forms, form)
+		ids = append(ids, id)
+	}
+	for i, id := range majorIDs {
+		add(id, id)
+		add(majorArcanaNames[i], id)
+	}
+	for s, suit := range suits {
+		for r, rank := range ranks {
+			add(minorIDs[s][r], minorIDs[s][r])
+			add(suit+"."+rank, minorIDs[s][r])
+			add(minorNames[s][r], minorIDs[s][r])
+		}
+	}
+
+	closest := suggest.Closest(strings.ReplaceAll(strings.TrimSpace(input), "/", "."), forms)
+	for i, form := range forms {
+		if form == closest && closest != "" {
+			return ids[i]
+		}
+	}
+	return ""
+}
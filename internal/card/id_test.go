@@ -0,0 +1,124 @@
+package card
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseID(t *testing.T) {
+	tests := map[string]string{
+		// Major arcana by ID, number or Roman numeral
+		"major_arcana.17":  "major_arcana.17",
+		"major_arcana.7":   "major_arcana.07",
+		"major_arcana.xxi": "major_arcana.21",
+		"MAJOR_ARCANA/03":  "major_arcana.03",
+		"17":               "major_arcana.17",
+		"00":               "major_arcana.00",
+		" 0 ":              "major_arcana.00",
+		"21":               "major_arcana.21",
+		"XVII":             "major_arcana.17",
+		"xvii":             "major_arcana.17",
+		"nulla":            "major_arcana.00",
+
+		// Minor arcana by suit and rank name or number
+		"minor_arcana.wands.ace":  "minor_arcana.wands.ace",
+		"minor_arcana/swords/10":  "minor_arcana.swords.ten",
+		"minor_arcana.cups.12":    "minor_arcana.cups.knight",
+		"wands.ace":               "minor_arcana.wands.ace",
+		"wands.1":                 "minor_arcana.wands.ace",
+		"pentacles.11":            "minor_arcana.pentacles.page",
+		"wands.14":                "minor_arcana.wands.king",
+		"cups/queen":              "minor_arcana.cups.queen",
+		"CUPS.Queen":              "minor_arcana.cups.queen",
+		"\tswords.three\n":        "minor_arcana.swords.three",
+		"minor_arcana.CUPS.Queen": "minor_arcana.cups.queen",
+
+		// Custom cards are passed through for decks to resolve
+		"custom_cards.major_arcana.the_void":     "custom_cards.major_arcana.the_void",
+		"Custom_Cards/Minor_Arcana/Stars/Ace":    "custom_cards.minor_arcana.stars.ace",
+		"custom_cards.minor_arcana.stars.ace":    "custom_cards.minor_arcana.stars.ace",
+		"custom_cards.major_arcana.the-wanderer": "custom_cards.major_arcana.the-wanderer",
+	}
+	for input, want := range tests {
+		if got, err := ParseID(input); err != nil || got != want {
+			t.Errorf("ParseID(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestParseIDErrors(t *testing.T) {
+	for _, input := range []string{
+		// Out of range
+		"22", "-1", "100", "XXII", "major_arcana.22", "major_arcana.XXII",
+		"wands.15", "wands.0", "wands.-1", "minor_arcana.cups.15",
+		// Not canonical or not a card
+		"IIII", "VX", "IL", "the fool", "major_arcana", "minor_arcana.wands",
+		"minor_arcana.wands.ace.extra", "wands", "ace.wands", "wand.ace",
+		"custom_cards.major_arcana", "custom_cards.a.b.c.d",
+		// Custom suits are resolved by the deck declaring them
+		"stars.ace", "minor_arcana.stars.ace",
+	} {
+		got, err := ParseID(input)
+		if err == nil {
+			t.Errorf("ParseID(%q) = %q, want an error", input, got)
+			continue
+		}
+		if !errors.Is(err, ErrInvalidCardID) {
+			t.Errorf("ParseID(%q) error %v does not wrap ErrInvalidCardID", input, err)
+		}
+		want := "invalid card ID: " + input + "\n" + AcceptedIDFormats
+		if err.Error() != want {
+			t.Errorf("ParseID(%q) error =\n%s\nwant\n%s", input, err, want)
+		}
+	}
+
+	if _, err := ParseID("  "); err == nil || err.Error() != "invalid card ID: empty\n"+AcceptedIDFormats {
+		t.Errorf("ParseID of a blank ID: got %v", err)
+	}
+}
+
+func TestAcceptedIDFormats(t *testing.T) {
+	// Every example in the help text parses, but custom suits, which need a deck
+	for _, example := range []string{
+		"major_arcana.17", "17", "XVII", "minor_arcana.wands.ace", "wands.ace",
+		"wands.1", "cups/queen", "CUPS.Queen", "custom_cards.minor_arcana.stars.ace",
+	} {
+		if !strings.Contains(AcceptedIDFormats, example) {
+			t.Errorf("AcceptedIDFormats does not mention %s", example)
+		}
+		if _, err := ParseID(example); err != nil {
+			t.Errorf("ParseID(%q): %v", example, err)
+		}
+	}
+}
+
+func TestParseRoman(t *testing.T) {
+	tests := map[string]int{
+		"0": 0, "nulla": 0, "NULLA": 0,
+		"I": 1, "iv": 4, "IX": 9, "XIV": 14, "XL": 40, "XXI": 21, "XXII": 22,
+		"XC": 90, "CD": 400, "CM": 900, "MCMXCIX": 1999, "MMXXIV": 2024,
+	}
+	for input, want := range tests {
+		if got, ok := ParseRoman(input); !ok || got != want {
+			t.Errorf("ParseRoman(%q) = %d, %t, want %d", input, got, ok, want)
+		}
+	}
+
+	for _, input := range []string{"", "IIII", "VX", "IL", "IC", "XM", "VV", "ABC", "X I", "-I", "1"} {
+		if got, ok := ParseRoman(input); ok {
+			t.Errorf("ParseRoman(%q) = %d, want it rejected", input, got)
+		}
+	}
+}
+
+func TestToRomanRoundTrip(t *testing.T) {
+	if ToRoman(0) != "0" || ToRoman(-3) != "0" {
+		t.Errorf("ToRoman(0) = %q, ToRoman(-3) = %q, want 0", ToRoman(0), ToRoman(-3))
+	}
+	for n := 1; n < 4000; n++ {
+		if got, ok := ParseRoman(ToRoman(n)); !ok || got != n {
+			t.Fatalf("ParseRoman(ToRoman(%d)) = %d, %t", n, got, ok)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package card
+
+import (
+	"fmt"
+	"strings"
+)
+
+// majorArcanaNames are the traditional English names of the major arcana, by number
+var majorArcanaNames = []string{
+	"The Fool", "The Magician", "The High Priestess", "The Empress", "The Emperor",
+	"The Hierophant", "The Lovers", "The Chariot", "Strength", "The Hermit",
+	"Wheel of Fortune", "Justice", "The Hanged Man", "Death", "Temperance",
+	"The Devil", "The Tower", "The Star", "The Moon", "The Sun",
+	"Judgement", "The World",
+}
+
+// majorArcanaAliases maps other names of the major arcana, from the Thoth deck
+// and older traditions, to their numbers
+var majorArcanaAliases = map[string]int{
+	"magus": 1, "priestess": 2, "pope": 5, "wheel": 10, "fortune": 10,
+	"adjustment": 11, "hanged": 12, "art": 14, "judgment": 20, "aeon": 20,
+	"universe": 21, "lust": 8,
+}
+
+// suitAliases maps suit names used by other decks and apps to canonical suits
+var suitAliases = map[string]string{
+	"wands": "wands", "wand": "wands", "batons": "wands", "rods": "wands", "staves": "wands", "staffs": "wands", "clubs": "wands",
+	"cups": "cups", "cup": "cups", "chalices": "cups", "hearts": "cups",
+	"swords": "swords", "sword": "swords", "blades": "swords", "spades": "swords",
+	"pentacles": "pentacles", "pentacle": "pentacles", "coins": "pentacles", "disks": "pentacles", "discs": "pentacles", "diamonds": "pentacles",
+}
+
+// rankAliases maps rank names used by other decks and apps to canonical ranks
+var rankAliases = map[string]string{
+	"one": "ace", "1": "ace", "2": "two", "3": "three", "4": "four", "5": "five",
+	"6": "six", "7": "seven", "8": "eight", "9": "nine", "10": "ten",
+	"princess": "page", "knave": "page", "jack": "page", "prince": "knight",
+}
+
+// RankAlias returns the canonical rank a rank name used by other decks and
+// apps stands for, such as page for knave
+func RankAlias(name string) (string, bool) {
+	rank, ok := rankAliases[name]
+	return rank, ok
+}
+
+// reversedMarkers are the ways apps mark a reversed card after its name
+var reversedMarkers = []string{"(reversed)", "[reversed]", "reversed", "(rev)", "(rx)", "(r)", "rx"}
+
+// MajorArcanaName returns the traditional English name of a major arcana
+// card by number, such as "07"
+func MajorArcanaName(number string) string {
+	for i, n := range majorNumbers {
+		if n == number {
+			return majorArcanaNames[i]
+		}
+	}
+	var num int
+	if _, err := fmt.Sscanf(number, "%d", &num); err == nil && num >= 0 && num < len(majorArcanaNames) {
+		return majorArcanaNames[num]
+	}
+	return fmt.Sprintf("Major Arcana %s", number)
+}
+
+// MinorArcanaName returns the English name of a minor arcana card, such as
+// "Queen of Cups"
+func MinorArcanaName(rank, suit string) string {
+	if s, r, ok := standardMinor(suit, rank); ok {
+		return minorNames[s][r]
+	}
+	return titleWord(rank) + " of " + titleWord(suit)
+}
+
+// DefaultName returns the English name of a card by canonical ID
+func DefaultName(id string) string {
+	parts := strings.Split(id, ".")
+	switch {
+	case len(parts) == 2 && parts[0] == "major_arcana":
+		return MajorArcanaName(parts[1])
+	case len(parts) == 3 && parts[0] == "minor_arcana":
+		return MinorArcanaName(parts[2], parts[1])
+	case len(parts) == 4 && parts[0] == "custom_cards" && parts[1] == "minor_arcana":
+		return MinorArcanaName(parts[3], parts[2])
+	}
+	return id
+}
+
+// titleWord capitalizes the first letter of a lowercase word
+func titleWord(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// ParseName resolves a card name as other tarot apps write it, such as "The
+// Tower", "Ace of Coins" or "three-of-cups (Reversed)", to its canonical ID,
+// reporting whether the name marked the card as reversed. Canonical IDs and
+// the other notations accepted by ParseID are resolved too.
+func ParseName(input string) (string, bool, error) {
+	name := strings.ToLower(strings.TrimSpace(input))
+
+	reversed := false
+	for _, marker := range reversedMarkers {
+		if trimmed, ok := strings.CutSuffix(name, marker); ok && trimmed != "" {
+			name, reversed = strings.TrimSpace(trimmed), true
+			break
+		}
+	}
+	if trimmed, ok := strings.CutPrefix(name, "reversed "); ok {
+		name, reversed = trimmed, true
+	}
+
+	if id, err := ParseID(name); err == nil {
+		return id, reversed, nil
+	}
+
+	// Slugs such as ace-of-cups or the_high_priestess
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), " ")
+	name = strings.TrimPrefix(name, "the ")
+
+	if rank, suit, ok := strings.Cut(name, " of "); ok {
+		if canonical, ok := rankAliases[rank]; ok {
+			rank = canonical
+		}
+		suit, okSuit := suitAliases[suit]
+		if _, okRank := parseRank(rank); okRank && okSuit {
+			return fmt.Sprintf("minor_arcana.%s.%s", suit, rank), reversed, nil
+		}
+	}
+
+	for num, major := range majorArcanaNames {
+		if name == strings.TrimPrefix(strings.ToLower(major), "the ") {
+			return fmt.Sprintf("major_arcana.%02d", num), reversed, nil
+		}
+	}
+	if num, ok := majorArcanaAliases[name]; ok {
+		return fmt.Sprintf("major_arcana.%02d", num), reversed, nil
+	}
+
+	return "", false, fmt.Errorf("unknown card name: %s", input)
+}
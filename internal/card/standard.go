@@ -0,0 +1,41 @@
+package card
+
+import "github.com/arcanaland/cartomancer/internal/canon"
+
+// The IDs and default names of the standard deck are built once and shared
+// by every deck, so a library of decks holds one copy of each string rather
+// than one per deck and language
+var (
+	majorNumbers [canon.MajorCount]string
+	majorIDs     [canon.MajorCount]string
+	minorIDs     [canon.SuitCount][canon.RanksPerSuit]string
+	minorNames   [canon.SuitCount][canon.RanksPerSuit]string
+)
+
+func init() {
+	copy(majorNumbers[:], canon.MajorNumbers())
+	for i := range majorIDs {
+		majorIDs[i] = canon.MajorID(i)
+	}
+	for s, suit := range suits {
+		for r, rank := range ranks {
+			minorIDs[s][r] = canon.MinorID(suit, rank)
+			minorNames[s][r] = titleWord(rank) + " of " + titleWord(suit)
+		}
+	}
+}
+
+// standardMinor returns the positions of a suit and rank in deck order
+func standardMinor(suit, rank string) (s, r int, ok bool) {
+	s, r = canon.SuitIndex(suit), canon.RankIndex(rank)
+	return s, r, s >= 0 && r >= 0
+}
+
+// Intern returns the shared copy of a card's default name when name equals
+// it, so decks that spell out the standard names do not each keep a copy
+func Intern(id, name string) string {
+	if def := DefaultName(id); def == name {
+		return def
+	}
+	return name
+}
@@ -0,0 +1,189 @@
+// Package cast reads and writes terminal session recordings in the asciicast
+// v2 format used by asciinema: a JSON header line followed by one JSON array
+// per event, [seconds, type, data].
+package cast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event types
+const (
+	Output = "o" // Data written to the terminal
+	Input  = "i" // Keys read from the terminal
+	Marker = "m" // A labelled moment, such as a card being revealed
+)
+
+// Header is the first line of a recording
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"` // Unix time the recording started
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is something that happened during a recording
+type Event struct {
+	Time float64 // Seconds since the start of the recording
+	Type string
+	Data string
+}
+
+// MarshalJSON encodes the event as a [time, type, data] array
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Type, e.Data})
+}
+
+// UnmarshalJSON decodes a [time, type, data] array
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("event has %d fields, want 3", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return fmt.Errorf("event time: %v", err)
+	}
+	if err := json.Unmarshal(fields[1], &e.Type); err != nil {
+		return fmt.Errorf("event type: %v", err)
+	}
+	if err := json.Unmarshal(fields[2], &e.Data); err != nil {
+		return fmt.Errorf("event data: %v", err)
+	}
+	return nil
+}
+
+// Writer records events as they happen. Writes to it are recorded as output,
+// so it can be placed alongside a terminal in an io.MultiWriter. It is safe
+// for concurrent use.
+type Writer struct {
+	mu      sync.Mutex
+	w       *bufio.Writer
+	start   time.Time
+	pending []byte // Trailing bytes of an incomplete UTF-8 character
+}
+
+// NewWriter writes the header and starts the recording clock. The header's
+// version is filled in.
+func NewWriter(w io.Writer, h Header) (*Writer, error) {
+	h.Version = 2
+	line, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw, start: time.Now()}, nil
+}
+
+// Write records terminal output. A character split across writes is held back
+// until the rest of it arrives.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := append(w.pending, p...)
+	end := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[end:]...)
+	if end == 0 {
+		return len(p), nil
+	}
+	if err := w.event(Output, string(data[:end])); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Input records keys read from the terminal
+func (w *Writer) Input(data string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.event(Input, data)
+}
+
+// Mark records a labelled moment
+func (w *Writer) Mark(label string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.event(Marker, label)
+}
+
+// Close writes any held back output and flushes the recording. It does not
+// close the underlying writer.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) > 0 {
+		if err := w.event(Output, string(w.pending)); err != nil {
+			return err
+		}
+		w.pending = nil
+	}
+	return w.w.Flush()
+}
+
+// event writes an event timestamped now. The caller holds w.mu.
+func (w *Writer) event(typ, data string) error {
+	line, err := json.Marshal(Event{Time: time.Since(w.start).Seconds(), Type: typ, Data: data})
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(append(line, '\n'))
+	return err
+}
+
+// Read parses a recording. Only version 2 recordings are supported.
+func Read(r io.Reader) (*Header, []Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("recording is empty")
+	}
+	var h Header
+	if err := json.Unmarshal(scanner.Bytes(), &h); err != nil {
+		return nil, nil, fmt.Errorf("error parsing header: %v", err)
+	}
+	if h.Version != 2 {
+		return nil, nil, fmt.Errorf("unsupported asciicast version %d", h.Version)
+	}
+
+	var events []Event
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return &h, events, nil
+}
@@ -0,0 +1,60 @@
+package cast
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, Header{Width: 80, Height: 24, Title: "three-card"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A character split across writes is recorded whole
+	sun := []byte("☉ Sun\n")
+	w.Write(sun[:1])
+	w.Write(sun[1:])
+	w.Input("\r")
+	w.Mark("Past")
+	w.Write([]byte{0xe2, 0x98})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, events, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Version != 2 || h.Width != 80 || h.Title != "three-card" {
+		t.Errorf("header = %+v", h)
+	}
+
+	want := []Event{{Type: Output, Data: "☉ Sun\n"}, {Type: Input, Data: "\r"}, {Type: Marker, Data: "Past"}, {Type: Output, Data: "��"}}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events %+v, want %d", len(events), events, len(want))
+	}
+	for i, e := range events {
+		if e.Type != want[i].Type || e.Data != want[i].Data {
+			t.Errorf("event %d = %+v, want %+v", i, e, want[i])
+		}
+		if i > 0 && e.Time < events[i-1].Time {
+			t.Errorf("event %d at %v is before the one before it", i, e.Time)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                               "empty",
+		`{"version": 1}`:                 "unsupported asciicast version 1",
+		"{\"version\": 2}\n[0.5, \"o\"]": "line 2",
+	}
+	for input, want := range tests {
+		if _, _, err := Read(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Read(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
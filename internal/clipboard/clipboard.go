@@ -0,0 +1,160 @@
+// Package clipboard places text and images on the system clipboard, through
+// the platform's clipboard tools or, for text, the terminal's OSC 52 escape
+// sequence, which also reaches the local clipboard from an SSH session.
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// Ways of reaching the clipboard, as reported by CopyText and CopyImage
+const (
+	MethodOSC52  = "the terminal (OSC 52)"
+	MethodNative = "the system clipboard tool"
+)
+
+// maxOSC52 is the most text sent in an OSC 52 sequence; terminals ignore
+// longer ones, such as xterm's limit of about 100 kB of base64
+const maxOSC52 = 74994
+
+// tool is a clipboard program and its arguments
+type tool struct {
+	name string
+	args []string
+}
+
+// textTools returns the programs that can copy text on this platform, in
+// order of preference
+func textTools() []tool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []tool{{"pbcopy", nil}}
+	case "windows":
+		return []tool{{"clip.exe", nil}}
+	}
+
+	var tools []tool
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, tool{"wl-copy", nil})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		tools = append(tools, tool{"xclip", []string{"-selection", "clipboard"}}, tool{"xsel", []string{"--clipboard", "--input"}})
+	}
+	return tools
+}
+
+// imageTools returns the programs that can copy a PNG image on this platform,
+// in order of preference
+func imageTools() []tool {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return nil
+	}
+
+	var tools []tool
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, tool{"wl-copy", []string{"--type", "image/png"}})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		tools = append(tools, tool{"xclip", []string{"-selection", "clipboard", "-t", "image/png"}})
+	}
+	return tools
+}
+
+// CopyText places text on the clipboard and returns how it got there. Over
+// SSH the terminal's OSC 52 sequence is tried first, as it reaches the
+// clipboard of the machine the user is sitting at; otherwise the platform's
+// clipboard tools are, with OSC 52 as the fallback.
+func CopyText(text string) (string, error) {
+	if os.Getenv("SSH_TTY") != "" {
+		if err := copyOSC52(text); err == nil {
+			return MethodOSC52, nil
+		}
+	}
+	if err := runTool(textTools(), []byte(text)); err == nil {
+		return MethodNative, nil
+	}
+	if err := copyOSC52(text); err != nil {
+		return "", fmt.Errorf("error copying to the clipboard: no clipboard tool found and %v", err)
+	}
+	return MethodOSC52, nil
+}
+
+// CopyImage places a PNG image on the clipboard. Terminals do not take
+// images over OSC 52, so it needs the platform's clipboard tools: wl-copy or
+// xclip on Linux and the BSDs, and AppleScript on macOS.
+func CopyImage(png []byte) (string, error) {
+	if runtime.GOOS == "darwin" {
+		if err := copyImageDarwin(png); err != nil {
+			return "", err
+		}
+		return MethodNative, nil
+	}
+
+	tools := imageTools()
+	if len(tools) == 0 {
+		return "", fmt.Errorf("copying images is not supported here: it needs wl-copy or xclip on Linux, or macOS")
+	}
+	if err := runTool(tools, png); err != nil {
+		return "", fmt.Errorf("error copying image to the clipboard: %v", err)
+	}
+	return MethodNative, nil
+}
+
+// runTool feeds data to the first of the tools that is installed
+func runTool(tools []tool, data []byte) error {
+	for _, t := range tools {
+		path, err := exec.LookPath(t.name)
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, t.args...)
+		cmd.Stdin = bytes.NewReader(data)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found")
+}
+
+// copyOSC52 asks the terminal to set the clipboard. Terminals do not answer,
+// so success only means the request was sent. Inside tmux it takes effect
+// with set-clipboard on.
+func copyOSC52(text string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(text))
+	if len(encoded) > maxOSC52 {
+		return fmt.Errorf("text is too long for the terminal clipboard")
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no terminal to copy through")
+	}
+	defer tty.Close()
+
+	_, err = tty.WriteString("\033]52;c;" + encoded + "\a")
+	return err
+}
+
+// copyImageDarwin copies a PNG image with AppleScript, which reads it from a
+// temporary file
+func copyImageDarwin(png []byte) error {
+	dir, err := os.MkdirTemp("", "cartomancer-clipboard")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "card.png")
+	if err := os.WriteFile(path, png, 0600); err != nil {
+		return err
+	}
+	script := fmt.Sprintf(`set the clipboard to (read (POSIX file %q) as «class PNGf»)`, path)
+	if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("error copying image to the clipboard: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Package combo holds the meanings of card pairs read together: the
+// combinations of Lenormand readings and the traditional tarot pairings.
+package combo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Pair is the meaning of two cards read together. The order of the cards
+// does not matter.
+type Pair struct {
+	Cards   [2]string // Canonical IDs, sorted
+	Meaning string
+	Source  string // "built-in", or the file the pair was loaded from
+}
+
+// Set is a collection of pair meanings, keyed by their cards
+type Set struct {
+	pairs map[[2]string]*Pair
+}
+
+// builtinPairs are well-known tarot pairings
+var builtinPairs = []struct {
+	a, b, meaning string
+}{
+	{"major_arcana.06", "minor_arcana.cups.two", "A committed partnership; attraction that is chosen and returned"},
+	{"major_arcana.16", "major_arcana.13", "Upheaval that closes a chapter for good; rebuild rather than repair"},
+	{"major_arcana.17", "major_arcana.19", "Hope fulfilled; healing that turns into joy"},
+	{"major_arcana.10", "major_arcana.13", "A turning point that cannot be undone"},
+	{"major_arcana.15", "major_arcana.06", "Attraction bound up with dependence or temptation"},
+	{"major_arcana.01", "minor_arcana.pentacles.ace", "Skill meeting a concrete opportunity; a venture worth starting"},
+	{"major_arcana.03", "major_arcana.04", "Nurture and structure in balance; a stable home or partnership"},
+	{"major_arcana.02", "major_arcana.18", "Heightened intuition; hidden matters not yet ready to surface"},
+	{"major_arcana.00", "minor_arcana.wands.ace", "A bold beginning taken on impulse and enthusiasm"},
+	{"major_arcana.09", "minor_arcana.swords.four", "A deliberate retreat for rest and reflection"},
+	{"major_arcana.11", "major_arcana.20", "A reckoning: decisions and their consequences come due"},
+	{"major_arcana.21", "minor_arcana.pentacles.ten", "Lasting security; a cycle completed with material reward"},
+	{"major_arcana.12", "minor_arcana.swords.eight", "Feeling stuck; release comes from a change of perspective"},
+	{"major_arcana.14", "minor_arcana.cups.two", "Harmony restored between two people through patience"},
+	{"major_arcana.07", "minor_arcana.wands.six", "Victory won through determination and publicly recognized"},
+	{"major_arcana.08", "minor_arcana.wands.nine", "Resilience; holding steady through the last stretch"},
+	{"minor_arcana.cups.three", "minor_arcana.cups.ten", "Celebration with family and friends"},
+	{"minor_arcana.swords.three", "minor_arcana.cups.five", "Grief and heartbreak that need time to be mourned"},
+	{"major_arcana.05", "minor_arcana.pentacles.eight", "Learning a craft through study and apprenticeship"},
+	{"minor_arcana.swords.ace", "major_arcana.11", "Clarity and truth cutting through to a fair decision"},
+}
+
+// Builtin returns a set holding the built-in tarot pairings
+func Builtin() *Set {
+	s := &Set{pairs: make(map[[2]string]*Pair, len(builtinPairs))}
+	for _, p := range builtinPairs {
+		s.add(p.a, p.b, p.meaning, "built-in")
+	}
+	return s
+}
+
+// key returns the map key of a pair of canonical IDs
+func key(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// add records a pair meaning, replacing any earlier meaning of the same cards
+func (s *Set) add(a, b, meaning, source string) {
+	k := key(a, b)
+	s.pairs[k] = &Pair{Cards: k, Meaning: meaning, Source: source}
+}
+
+// Lookup returns the meaning of two cards, given by canonical ID, read together
+func (s *Set) Lookup(a, b string) (*Pair, bool) {
+	p, ok := s.pairs[key(a, b)]
+	return p, ok
+}
+
+// With returns the pairs that include a card, sorted by the other card
+func (s *Set) With(id string) []*Pair {
+	var pairs []*Pair
+	for k, p := range s.pairs {
+		if k[0] == id || k[1] == id {
+			pairs = append(pairs, p)
+		}
+	}
+	other := func(p *Pair) string {
+		if p.Cards[0] == id {
+			return p.Cards[1]
+		}
+		return p.Cards[0]
+	}
+	sort.Slice(pairs, func(i, j int) bool { return other(pairs[i]) < other(pairs[j]) })
+	return pairs
+}
+
+// Len returns the number of pairs in the set
+func (s *Set) Len() int {
+	return len(s.pairs)
+}
+
+// pairsFile is the on-disk format of a combinations file
+type pairsFile struct {
+	Pairs []struct {
+		Cards   []string `toml:"cards"`
+		Meaning string   `toml:"meaning"`
+	} `toml:"pair"`
+}
+
+// LoadFile adds the pairs of a combinations file, replacing the meanings
+// already in the set for the same cards. Cards are given by name or in any
+// notation accepted by card.ParseID. A missing file is not an error.
+func (s *Set) LoadFile(path string) error {
+	var f pairsFile
+	if _, err := toml.DecodeFile(path, &f); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error loading combinations %s: %v", path, err)
+	}
+
+	for i, p := range f.Pairs {
+		if len(p.Cards) != 2 {
+			return fmt.Errorf("%s: pair %d: cards must list exactly two cards", path, i+1)
+		}
+		if p.Meaning == "" {
+			return fmt.Errorf("%s: pair %d: meaning is required", path, i+1)
+		}
+
+		var ids [2]string
+		for j, name := range p.Cards {
+			id, _, err := card.ParseName(name)
+			if err != nil {
+				return fmt.Errorf("%s: pair %d: %v", path, i+1, err)
+			}
+			ids[j] = id
+		}
+		if ids[0] == ids[1] {
+			return fmt.Errorf("%s: pair %d: a card cannot pair with itself", path, i+1)
+		}
+		s.add(ids[0], ids[1], p.Meaning, path)
+	}
+	return nil
+}
+
+// LoadDir adds the pairs of the .toml files in a directory, in name order. A
+// missing directory is not an error.
+func (s *Set) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.toml"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := s.LoadFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
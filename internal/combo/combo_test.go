@@ -0,0 +1,75 @@
+package combo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinLookupIgnoresOrder(t *testing.T) {
+	s := Builtin()
+	a, okA := s.Lookup("major_arcana.06", "minor_arcana.cups.two")
+	b, okB := s.Lookup("minor_arcana.cups.two", "major_arcana.06")
+	if !okA || !okB || a != b {
+		t.Fatalf("Lookup = %v, %v; want the same pair both ways", a, b)
+	}
+	if _, ok := s.Lookup("major_arcana.06", "major_arcana.07"); ok {
+		t.Error("Lookup found a pair that is not defined")
+	}
+}
+
+func TestLoadFileOverridesBuiltin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mine.toml")
+	data := `
+[[pair]]
+cards = ["Two of Cups", "VI"]
+meaning = "Mine"
+
+[[pair]]
+cards = ["custom_cards.major_arcana.rider", "custom_cards.major_arcana.clover"]
+meaning = "Good news arrives quickly"
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Builtin()
+	before := s.Len()
+	if err := s.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != before+1 {
+		t.Errorf("Len = %d, want %d", s.Len(), before+1)
+	}
+	if p, _ := s.Lookup("major_arcana.06", "minor_arcana.cups.two"); p.Meaning != "Mine" || p.Source != path {
+		t.Errorf("Lookup = %+v, want the loaded meaning", p)
+	}
+	if _, ok := s.Lookup("custom_cards.major_arcana.clover", "custom_cards.major_arcana.rider"); !ok {
+		t.Error("custom card pair not loaded")
+	}
+	if pairs := s.With("custom_cards.major_arcana.rider"); len(pairs) != 1 {
+		t.Errorf("With = %d pairs, want 1", len(pairs))
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"one card":     "[[pair]]\ncards = [\"0\"]\nmeaning = \"x\"\n",
+		"no meaning":   "[[pair]]\ncards = [\"0\", \"1\"]\n",
+		"unknown card": "[[pair]]\ncards = [\"0\", \"nope\"]\nmeaning = \"x\"\n",
+		"same card":    "[[pair]]\ncards = [\"0\", \"The Fool\"]\nmeaning = \"x\"\n",
+	}
+	for name, data := range tests {
+		path := filepath.Join(t.TempDir(), "pairs.toml")
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Builtin().LoadFile(path); err == nil {
+			t.Errorf("%s: LoadFile succeeded, want an error", name)
+		}
+	}
+
+	if err := Builtin().LoadFile(filepath.Join(t.TempDir(), "missing.toml")); err != nil {
+		t.Errorf("missing file: %v", err)
+	}
+}
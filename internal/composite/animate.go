@@ -0,0 +1,129 @@
+package composite
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Frame is a single frame of an animation
+type Frame struct {
+	Image *image.RGBA
+	Delay time.Duration
+}
+
+// Timing of the reveal animation
+const (
+	flipSteps      = 4
+	flipFrameDelay = 40 * time.Millisecond
+	openingDelay   = 800 * time.Millisecond
+	revealedDelay  = 400 * time.Millisecond
+	closingDelay   = 4 * time.Second
+)
+
+// Reveal builds an animation that starts with every card face down and flips
+// the cards face up one at a time, in placement order
+func Reveal(placements []Placement, back image.Image, layout Layout) []Frame {
+	current := make([]Placement, len(placements))
+	for i, p := range placements {
+		current[i] = p
+		current[i].Image = back
+	}
+
+	frames := []Frame{{Image: Compose(current, layout), Delay: openingDelay}}
+
+	for i, p := range placements {
+		// Turn the back edge on
+		for step := 1; step <= flipSteps; step++ {
+			current[i].Squeeze = float64(step) / flipSteps
+			frames = append(frames, Frame{Image: Compose(current, layout), Delay: flipFrameDelay})
+		}
+
+		// Open up the face
+		current[i].Image = p.Image
+		for step := flipSteps - 1; step >= 0; step-- {
+			current[i].Squeeze = float64(step) / flipSteps
+			frames = append(frames, Frame{Image: Compose(current, layout), Delay: flipFrameDelay})
+		}
+
+		frames[len(frames)-1].Delay = revealedDelay
+	}
+
+	frames[len(frames)-1].Delay = closingDelay
+
+	return frames
+}
+
+// EncodeGIF writes frames as a looping animated GIF
+func EncodeGIF(w io.Writer, frames []Frame) error {
+	anim := &gif.GIF{LoopCount: 0}
+
+	for _, frame := range frames {
+		bounds := frame.Image.Bounds()
+		paletted := image.NewPaletted(bounds, palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, bounds, frame.Image, bounds.Min)
+
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, int(frame.Delay/(10*time.Millisecond)))
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// EncodeWebM writes frames as a WebM video. Encoding is delegated to ffmpeg,
+// which must be available on the PATH.
+func EncodeWebM(outputPath string, frames []Frame) error {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("WebM export requires ffmpeg on your PATH; export a .gif instead")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "cartomancer-webm")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Write the frames and list each one with its display time for the concat demuxer
+	var concat []byte
+	for i, frame := range frames {
+		framePath := filepath.Join(tmpDir, fmt.Sprintf("frame%04d.png", i))
+		file, err := os.Create(framePath)
+		if err != nil {
+			return err
+		}
+		err = png.Encode(file, frame.Image)
+		file.Close()
+		if err != nil {
+			return err
+		}
+
+		concat = append(concat, fmt.Sprintf("file '%s'\nduration %.3f\n", framePath, frame.Delay.Seconds())...)
+	}
+
+	// The concat demuxer ignores the duration of the last entry unless it is repeated
+	concat = append(concat, fmt.Sprintf("file '%s'\n", filepath.Join(tmpDir, fmt.Sprintf("frame%04d.png", len(frames)-1)))...)
+
+	listPath := filepath.Join(tmpDir, "frames.txt")
+	if err := os.WriteFile(listPath, concat, 0644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(ffmpeg, "-y", "-loglevel", "error",
+		"-f", "concat", "-safe", "0", "-i", listPath,
+		"-vf", "fps=25,pad=ceil(iw/2)*2:ceil(ih/2)*2",
+		"-c:v", "libvpx-vp9", "-pix_fmt", "yuv420p", outputPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %v: %s", err, output)
+	}
+
+	return nil
+}
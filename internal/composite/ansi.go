@@ -0,0 +1,73 @@
+package composite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/arcanaland/cartomancer/pkg/render"
+)
+
+// Size of a character cell of rasterized ANSI art, in font pixels. Cells are
+// twice as tall as they are wide, like a terminal's, so half blocks are square.
+const (
+	cellWidth  = glyphWidth + glyphSpacing
+	cellHeight = 2 * cellWidth
+)
+
+// shades are the fraction of a cell the shade characters fill with the
+// foreground color
+var shades = map[rune]float64{'░': 0.25, '▒': 0.5, '▓': 0.75}
+
+// RasterizeANSI draws ANSI art as a terminal would show it, each font pixel
+// scale image pixels wide. Block elements fill their part of the cell, so art
+// of half blocks comes out pixel for pixel; other characters are drawn in the
+// built-in bitmap font.
+func RasterizeANSI(art string, scale int) *image.RGBA {
+	scale = max(scale, 1)
+	rows := render.ParseANSI(art)
+	width := 0
+	if len(rows) > 0 {
+		width = len(rows[0])
+	}
+
+	w, h := cellWidth*scale, cellHeight*scale
+	img := image.NewRGBA(image.Rect(0, 0, max(width*w, 1), max(len(rows)*h, 1)))
+	for y, row := range rows {
+		for x, c := range row {
+			cell := image.Rect(x*w, y*h, (x+1)*w, (y+1)*h)
+			fill := func(r image.Rectangle, col color.Color) {
+				draw.Draw(img, r, image.NewUniform(col), image.Point{}, draw.Src)
+			}
+
+			fill(cell, c.BG)
+			switch c.Char {
+			case ' ':
+			case '█':
+				fill(cell, c.FG)
+			case '▀':
+				fill(image.Rect(cell.Min.X, cell.Min.Y, cell.Max.X, cell.Min.Y+h/2), c.FG)
+			case '▄':
+				fill(image.Rect(cell.Min.X, cell.Min.Y+h/2, cell.Max.X, cell.Max.Y), c.FG)
+			case '▌':
+				fill(image.Rect(cell.Min.X, cell.Min.Y, cell.Min.X+w/2, cell.Max.Y), c.FG)
+			case '▐':
+				fill(image.Rect(cell.Min.X+w/2, cell.Min.Y, cell.Max.X, cell.Max.Y), c.FG)
+			case '░', '▒', '▓':
+				fill(cell, blend(c.FG, c.BG, shades[c.Char]))
+			default:
+				// The glyph sits in the middle of the cell's height
+				DrawText(img, cell.Min.X, cell.Min.Y+(cellHeight-glyphHeight)/2*scale, string(c.Char), scale, c.FG)
+			}
+		}
+	}
+	return img
+}
+
+// blend mixes a fraction of fg into bg
+func blend(fg, bg color.RGBA, fraction float64) color.RGBA {
+	mix := func(a, b uint8) uint8 {
+		return uint8(float64(a)*fraction + float64(b)*(1-fraction) + 0.5)
+	}
+	return color.RGBA{R: mix(fg.R, bg.R), G: mix(fg.G, bg.G), B: mix(fg.B, bg.B), A: 255}
+}
@@ -0,0 +1,133 @@
+package composite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// PlaceholderBack draws a generic card back for decks that do not ship one: a
+// framed field with a diamond lattice in the foreground color
+func PlaceholderBack(width, height int, background, foreground color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	border := max(width/16, 1)
+	spacing := max(width/6, 4)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := background
+
+			inset := x < border || y < border || x >= width-border || y >= height-border
+			inner := x >= 2*border && y >= 2*border && x < width-2*border && y < height-2*border
+
+			// Lines running in both diagonal directions form the lattice
+			lattice := inner && ((x+y)%spacing == 0 || (x-y+height*spacing)%spacing == 0)
+
+			if inset || lattice {
+				c = foreground
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+// Placeholder describes generated stand-in art for a card whose image is missing
+type Placeholder struct {
+	Title      string // Card name, lettered across the accent block
+	Index      string // Number or rank, shown in opposite corners
+	Symbol     string // Suit or arcana glyph, drawn above the title
+	Background color.Color
+	Accent     color.Color // Block and frame color, typically the suit color
+	Foreground color.Color
+}
+
+// Draw renders the placeholder: a framed card with the index in opposite
+// corners and a block of the accent color holding the symbol and the title,
+// lettered as large as fits
+func (p Placeholder) Draw(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(p.Background), image.Point{}, draw.Src)
+
+	border := max(width/16, 1)
+	frame := max(border/4, 1)
+	outer := image.Rect(border/2, border/2, width-border/2, height-border/2)
+	for _, edge := range []image.Rectangle{
+		{outer.Min, image.Pt(outer.Max.X, outer.Min.Y+frame)},
+		{image.Pt(outer.Min.X, outer.Max.Y-frame), outer.Max},
+		{outer.Min, image.Pt(outer.Min.X+frame, outer.Max.Y)},
+		{image.Pt(outer.Max.X-frame, outer.Min.Y), outer.Max},
+	} {
+		draw.Draw(img, edge, image.NewUniform(p.Accent), image.Point{}, draw.Src)
+	}
+
+	if p.Index != "" {
+		scale := max(width/100, 1)
+		DrawText(img, border+frame, border+frame, p.Index, scale, p.Foreground)
+		DrawText(img, width-border-frame-TextWidth(p.Index, scale), height-border-frame-TextHeight(scale), p.Index, scale, p.Foreground)
+	}
+
+	block := image.Rect(border, height/4, width-border, height*3/4)
+	draw.Draw(img, block, image.NewUniform(p.Accent), image.Point{}, draw.Src)
+	textWidth := block.Dx() - 2*border
+
+	// Use the largest scale at which the symbol and the wrapped title fit the block
+	scale := max(width/40, 1)
+	lines := wrapWords(p.Title, textWidth, scale)
+	for scale > 1 && (p.contentHeight(len(lines), scale) > block.Dy()-2*border || !wordsFit(p.Title, textWidth, scale)) {
+		scale--
+		lines = wrapWords(p.Title, textWidth, scale)
+	}
+
+	lineHeight := TextHeight(scale) + 2*scale
+	symbolScale := scale * 2
+	contentHeight := p.contentHeight(len(lines), scale)
+
+	y := block.Min.Y + (block.Dy()-contentHeight)/2
+	if p.Symbol != "" {
+		DrawText(img, (width-TextWidth(p.Symbol, symbolScale))/2, y, p.Symbol, symbolScale, p.Foreground)
+		y += TextHeight(symbolScale) + lineHeight
+	}
+	for i, line := range lines {
+		DrawText(img, (width-TextWidth(line, scale))/2, y+i*lineHeight, line, scale, p.Foreground)
+	}
+
+	return img
+}
+
+// contentHeight returns the height of the symbol and title lines at a scale
+func (p Placeholder) contentHeight(lines, scale int) int {
+	lineHeight := TextHeight(scale) + 2*scale
+	height := lines*lineHeight - 2*scale
+	if p.Symbol != "" {
+		height += TextHeight(scale*2) + lineHeight
+	}
+	return height
+}
+
+// wrapWords wraps text into lines no wider than width at the given scale.
+// Words that are too long on their own are truncated.
+func wrapWords(text string, width, scale int) []string {
+	var lines []string
+	for _, word := range strings.Fields(text) {
+		if n := len(lines); n > 0 && TextWidth(lines[n-1]+" "+word, scale) <= width {
+			lines[n-1] += " " + word
+		} else {
+			lines = append(lines, fitText(word, width, scale))
+		}
+	}
+	return lines
+}
+
+// wordsFit reports whether every word of text fits within width at the given scale
+func wordsFit(text string, width, scale int) bool {
+	for _, word := range strings.Fields(text) {
+		if TextWidth(word, scale) > width {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+// Package composite lays out card images onto a single canvas.
+package composite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/nfnt/resize"
+)
+
+// Placement is a card image positioned on the layout grid
+type Placement struct {
+	Image   image.Image // Card face; nil draws a blank card
+	X       float64     // Centre of the card in grid units (card widths)
+	Y       float64     // Centre of the card in grid units (card heights)
+	Rotated bool        // Draw the card turned a quarter turn
+	Squeeze float64     // Narrow the card for flip animations, from 0 (full width) to 1 (edge on)
+	Label   []string    // Lines of text drawn beneath the card
+}
+
+// Layout describes the geometry and colors of the canvas
+type Layout struct {
+	CardWidth  int
+	CardHeight int
+	Gap        int // Space between cards and around the canvas edge
+	LabelLines int // Lines reserved beneath each card for labels
+	LabelScale int // Pixel size of each font pixel
+	Background color.Color
+	Foreground color.Color // Label color
+	BlankCard  color.Color // Fill for placements without an image
+}
+
+// DefaultLayout returns a layout for cards of the given size
+func DefaultLayout(cardWidth, cardHeight int) Layout {
+	scale := cardHeight / 150
+	if scale < 1 {
+		scale = 1
+	}
+
+	return Layout{
+		CardWidth:  cardWidth,
+		CardHeight: cardHeight,
+		Gap:        cardWidth / 8,
+		LabelLines: 2,
+		LabelScale: scale,
+		Background: color.RGBA{R: 24, G: 20, B: 36, A: 255},
+		Foreground: color.RGBA{R: 230, G: 224, B: 240, A: 255},
+		BlankCard:  color.RGBA{R: 80, G: 72, B: 96, A: 255},
+	}
+}
+
+// Light returns the layout with colors for a light background: pale cards
+// with dark labels on a near-white canvas
+func (l Layout) Light() Layout {
+	l.Background = color.RGBA{R: 246, G: 243, B: 250, A: 255}
+	l.Foreground = color.RGBA{R: 48, G: 40, B: 64, A: 255}
+	l.BlankCard = color.RGBA{R: 222, G: 214, B: 234, A: 255}
+	return l
+}
+
+// unitSize returns the size in pixels of one grid unit
+func (l Layout) unitSize() (int, int) {
+	lineHeight := TextHeight(l.LabelScale) + 2*l.LabelScale
+	return l.CardWidth + l.Gap, l.CardHeight + l.Gap + l.LabelLines*lineHeight
+}
+
+// Compose draws every placement onto a new canvas sized to fit them. Placements
+// are drawn in order, so crosswise cards should follow the card they cover.
+func Compose(placements []Placement, layout Layout) *image.RGBA {
+	unitW, unitH := layout.unitSize()
+
+	var gridW, gridH float64
+	for _, p := range placements {
+		if p.X+0.5 > gridW {
+			gridW = p.X + 0.5
+		}
+		if p.Y+0.5 > gridH {
+			gridH = p.Y + 0.5
+		}
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0,
+		layout.Gap+int(gridW*float64(unitW)),
+		layout.Gap+int(gridH*float64(unitH))))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(layout.Background), image.Point{}, draw.Src)
+
+	for _, p := range placements {
+		left := layout.Gap + int((p.X-0.5)*float64(unitW))
+		top := layout.Gap + int((p.Y-0.5)*float64(unitH))
+		centreX := left + layout.CardWidth/2
+		centreY := top + layout.CardHeight/2
+
+		w, h := layout.CardWidth, layout.CardHeight
+		if p.Rotated {
+			w, h = h, w
+		}
+		if p.Squeeze > 0 {
+			w = int(float64(w) * (1 - p.Squeeze))
+		}
+		rect := image.Rect(centreX-w/2, centreY-h/2, centreX-w/2+w, centreY-h/2+h)
+
+		// Cards turned edge on during a flip are not drawn at all
+		if w > 0 && p.Image == nil {
+			draw.Draw(canvas, rect, image.NewUniform(layout.BlankCard), image.Point{}, draw.Src)
+		} else if w > 0 {
+			img := resize.Resize(uint(layout.CardWidth), uint(layout.CardHeight), p.Image, resize.Lanczos3)
+			if p.Rotated {
+				img = rotate90(img)
+			}
+			if p.Squeeze > 0 {
+				img = resize.Resize(uint(w), uint(h), img, resize.Bilinear)
+			}
+			draw.Draw(canvas, rect, img, img.Bounds().Min, draw.Over)
+		}
+
+		// Labels of crosswise cards would cover the card beneath them
+		if p.Rotated {
+			continue
+		}
+
+		lineHeight := TextHeight(layout.LabelScale) + 2*layout.LabelScale
+		y := top + layout.CardHeight + layout.Gap/2
+		for i, line := range p.Label {
+			if i >= layout.LabelLines {
+				break
+			}
+			line = fitText(line, layout.CardWidth+layout.Gap, layout.LabelScale)
+			x := centreX - TextWidth(line, layout.LabelScale)/2
+			DrawText(canvas, x, y+i*lineHeight, line, layout.LabelScale, layout.Foreground)
+		}
+	}
+
+	return canvas
+}
+
+// fitText truncates text so it fits within width pixels at the given scale
+func fitText(text string, width, scale int) string {
+	runes := []rune(text)
+	for len(runes) > 0 && TextWidth(string(runes), scale) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// rotate90 returns a copy of img turned a quarter turn clockwise
+func rotate90(img image.Image) image.Image {
+	b := img.Bounds()
+	rotated := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			rotated.Set(b.Max.Y-1-y, x-b.Min.X, img.At(x, y))
+		}
+	}
+	return rotated
+}
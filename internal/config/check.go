@@ -0,0 +1,312 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+	"github.com/arcanaland/cartomancer/internal/prompts"
+	"github.com/arcanaland/cartomancer/internal/suggest"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/internal/yesno"
+	"github.com/arcanaland/cartomancer/pkg/render"
+)
+
+// configKeys lists the top-level keys understood in config.toml
+var configKeys = []string{"default_deck", "numbering", "theme", "symbols", "background", "show_fields", "astro_timing", "safe_mode", "cb_profile", "kiosk", "max_image_pixels", "hooks", "registries", "image_backends", "llm", "serve", "yesno", "content_filter", "prompts", "bake_profiles"}
+
+// Diagnostic describes a problem found in config.toml
+type Diagnostic struct {
+	Line       int // 1-based line number, 0 when unknown
+	Key        string
+	Message    string
+	Suggestion string
+}
+
+// String formats the diagnostic as "config.toml:LINE: message (did you mean ...?)"
+func (d Diagnostic) String() string {
+	location := "config.toml"
+	if d.Line > 0 {
+		location = fmt.Sprintf("config.toml:%d", d.Line)
+	}
+
+	message := fmt.Sprintf("%s: %s", location, d.Message)
+	if d.Suggestion != "" {
+		message += fmt.Sprintf(" (did you mean %q?)", d.Suggestion)
+	}
+	return message
+}
+
+// CheckResult holds the parsed config and any diagnostics found while checking it
+type CheckResult struct {
+	Path        string
+	Config      *Config
+	Diagnostics []Diagnostic
+
+	lines []string
+}
+
+// Add records a diagnostic for key, locating the line where it is defined
+func (r *CheckResult) Add(key, message, suggestion string) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{
+		Line:       r.keyLine(key),
+		Key:        key,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+// keyLine returns the line defining a dotted key or table header, or 0 if not found
+func (r *CheckResult) keyLine(key string) int {
+	parts := strings.Split(key, ".")
+	last := regexp.QuoteMeta(parts[len(parts)-1])
+	table := regexp.QuoteMeta(key)
+
+	assign := regexp.MustCompile(`^\s*["']?` + last + `["']?\s*=`)
+	header := regexp.MustCompile(`^\s*\[\[?\s*` + table + `\s*\]`)
+
+	// Nested keys are only matched after their parent table header
+	inTable := len(parts) == 1
+	parent := regexp.MustCompile(`^\s*\[\[?\s*` + regexp.QuoteMeta(strings.Join(parts[:len(parts)-1], ".")) + `\s*\]`)
+	for i, line := range r.lines {
+		if header.MatchString(line) {
+			return i + 1
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			inTable = len(parts) > 1 && parent.MatchString(line)
+			continue
+		}
+		if inTable && assign.MatchString(line) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Check parses config.toml and reports syntax errors, unknown keys, values of
+// the wrong type, unsupported settings and a default deck that does not exist
+func Check() (*CheckResult, error) {
+	path := GetConfigFilePath()
+	result := &CheckResult{Path: path, Config: &Config{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %v", err)
+	}
+	result.lines = strings.Split(string(data), "\n")
+
+	md, err := toml.Decode(string(data), result.Config)
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, decodeDiagnostic(err))
+		return result, nil
+	}
+
+	for _, key := range md.Undecoded() {
+		// Hook commands are checked against the event list below
+		if len(key) > 0 && key[0] == "hooks" {
+			continue
+		}
+		name := key.String()
+		result.Add(name, fmt.Sprintf("unknown key %q", name), suggest.Closest(key[len(key)-1], configKeys))
+	}
+
+	result.checkValues()
+	return result, nil
+}
+
+// decodeDiagnostic converts a TOML decode error into a diagnostic with its line number
+func decodeDiagnostic(err error) Diagnostic {
+	d := Diagnostic{Message: strings.TrimPrefix(err.Error(), "toml: ")}
+
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		d.Line = parseErr.Position.Line
+		d.Key = parseErr.LastKey
+	} else {
+		// Type mismatches are reported as "toml: line N (last key "x"): ..."
+		fmt.Sscanf(d.Message, "line %d", &d.Line)
+	}
+
+	// The line number is part of the diagnostic location already
+	if d.Line > 0 && strings.HasPrefix(d.Message, "line ") {
+		if i := strings.Index(d.Message, ": "); i >= 0 {
+			d.Message = d.Message[i+2:]
+		}
+	}
+	return d
+}
+
+// checkValues validates settings that are limited to a fixed set of values
+func (r *CheckResult) checkValues() {
+	cfg := r.Config
+
+	numbering := []string{card.NumberingArabic, card.NumberingPadded, card.NumberingRoman}
+	if cfg.Numbering != "" && !contains(numbering, cfg.Numbering) {
+		r.Add("numbering", fmt.Sprintf("unsupported numbering %q (supported: %s)",
+			cfg.Numbering, strings.Join(numbering, ", ")), suggest.Closest(cfg.Numbering, numbering))
+	}
+
+	if cfg.Theme != "" && !contains(theme.Names(), cfg.Theme) {
+		r.Add("theme", fmt.Sprintf("unknown theme %q (available: %s)",
+			cfg.Theme, strings.Join(theme.Names(), ", ")), suggest.Closest(cfg.Theme, theme.Names()))
+	}
+
+	symbols := []string{theme.SymbolsAuto, theme.SymbolsNerd, theme.SymbolsUnicode, theme.SymbolsASCII}
+	if cfg.Symbols != "" && !contains(symbols, cfg.Symbols) {
+		r.Add("symbols", fmt.Sprintf("unknown symbol set %q (supported: %s)",
+			cfg.Symbols, strings.Join(symbols, ", ")), suggest.Closest(cfg.Symbols, symbols))
+	}
+
+	backgrounds := []string{theme.BackgroundAuto, theme.BackgroundDark, theme.BackgroundLight}
+	if cfg.Background != "" && !contains(backgrounds, cfg.Background) {
+		r.Add("background", fmt.Sprintf("unknown background %q (supported: %s)",
+			cfg.Background, strings.Join(backgrounds, ", ")), suggest.Closest(cfg.Background, backgrounds))
+	}
+
+	if cfg.CBProfile != "" && !contains(render.CVDProfiles(), cfg.CBProfile) {
+		r.Add("cb_profile", fmt.Sprintf("unknown color vision profile %q (supported: %s)",
+			cfg.CBProfile, strings.Join(render.CVDProfiles(), ", ")), suggest.Closest(cfg.CBProfile, render.CVDProfiles()))
+	}
+
+	for event := range cfg.Hooks {
+		if err := hooks.Validate(map[string][]string{event: nil}); err != nil {
+			r.Add("hooks."+event, err.Error(), suggest.Closest(event, hooks.Events))
+		}
+	}
+
+	backends := []string{RegistryGitHub, RegistryS3, RegistryHTTP}
+	for name, registry := range cfg.Registries {
+		key := "registries." + name + ".backend"
+		if !contains(backends, registry.Backend) {
+			r.Add(key, fmt.Sprintf("unknown registry backend %q (supported: %s)",
+				registry.Backend, strings.Join(backends, ", ")), suggest.Closest(registry.Backend, backends))
+		}
+	}
+
+	imageBackends := []string{ImageBackendA1111, ImageBackendComfyUI, ImageBackendCommand}
+	for name, backend := range cfg.ImageBackends {
+		key := "image_backends." + name + ".backend"
+		if !contains(imageBackends, backend.Backend) {
+			r.Add(key, fmt.Sprintf("unknown image backend %q (supported: %s)",
+				backend.Backend, strings.Join(imageBackends, ", ")), suggest.Closest(backend.Backend, imageBackends))
+		}
+	}
+
+	if cfg.MaxImagePixels < 0 {
+		r.Add("max_image_pixels", "max_image_pixels cannot be negative", "")
+	}
+
+	if cfg.Serve != nil {
+		if cfg.Serve.RateLimit < 0 {
+			r.Add("serve.rate_limit", "rate_limit cannot be negative", "")
+		}
+		if cfg.Serve.Burst < 0 {
+			r.Add("serve.burst", "burst cannot be negative", "")
+		}
+	}
+
+	if cfg.YesNo != nil {
+		methods := yesno.Names()
+		if cfg.YesNo.Method != "" && !contains(methods, cfg.YesNo.Method) {
+			r.Add("yesno.method", fmt.Sprintf("unknown yes/no method %q (supported: %s)",
+				cfg.YesNo.Method, strings.Join(methods, ", ")), suggest.Closest(cfg.YesNo.Method, methods))
+		}
+		for _, name := range methods {
+			m := cfg.YesNo.MethodOptions(name)
+			if m == nil {
+				continue
+			}
+			if m.Cards < 0 {
+				r.Add("yesno."+name+".cards", "cards cannot be negative", "")
+			}
+			if m.ReversalChance < 0 || m.ReversalChance > 1 {
+				r.Add("yesno."+name+".reversal_chance", "reversal_chance must be between 0 and 1", "")
+			}
+		}
+	}
+
+	if cfg.ContentFilter != nil && cfg.ContentFilter.Mode != "" && !contains(HideModes(), cfg.ContentFilter.Mode) {
+		r.Add("content_filter.mode", fmt.Sprintf("unknown content filter mode %q (supported: %s)",
+			cfg.ContentFilter.Mode, strings.Join(HideModes(), ", ")), suggest.Closest(cfg.ContentFilter.Mode, HideModes()))
+	}
+
+	hemispheres := []string{prompts.North, prompts.South}
+	if cfg.Prompts != nil && cfg.Prompts.Hemisphere != "" && !contains(hemispheres, cfg.Prompts.Hemisphere) {
+		r.Add("prompts.hemisphere", fmt.Sprintf("unknown hemisphere %q (supported: %s)",
+			cfg.Prompts.Hemisphere, strings.Join(hemispheres, ", ")), suggest.Closest(cfg.Prompts.Hemisphere, hemispheres))
+	}
+
+	renderers := []string{render.RendererHalfBlock, render.RendererASCII}
+	for name, profile := range cfg.BakeProfiles {
+		key := "bake_profiles." + name
+		if profile.Width < 0 {
+			r.Add(key+".width", "width cannot be negative", "")
+		}
+		if profile.Height < 0 {
+			r.Add(key+".height", "height cannot be negative", "")
+		}
+		if profile.Colors != "" && !contains(BakeColors(), profile.Colors) {
+			r.Add(key+".colors", fmt.Sprintf("unsupported colors %q (supported: %s)",
+				profile.Colors, strings.Join(BakeColors(), ", ")), suggest.Closest(profile.Colors, BakeColors()))
+		}
+		if profile.Renderer != "" && !contains(renderers, profile.Renderer) {
+			r.Add(key+".renderer", fmt.Sprintf("unknown renderer %q (supported: %s)",
+				profile.Renderer, strings.Join(renderers, ", ")), suggest.Closest(profile.Renderer, renderers))
+		}
+		if profile.Dir != "" && (!strings.HasPrefix(profile.Dir, "ansi") || strings.ContainsAny(profile.Dir, `/\`)) {
+			r.Add(key+".dir", fmt.Sprintf("dir %q must be a directory name starting with \"ansi\"", profile.Dir), "")
+		}
+	}
+
+	if cfg.DefaultDeck == "" {
+		r.Add("default_deck", "default_deck is not set", "")
+	} else if _, err := ResolveDeck(cfg.DefaultDeck); err != nil {
+		r.Add("default_deck", fmt.Sprintf("default deck %q not found in %s",
+			cfg.DefaultDeck, GetDeckLibraryPath()), SuggestDeck(cfg.DefaultDeck))
+	}
+}
+
+// SuggestDeck returns the library deck name or ID closest to name, or "" if none is close
+func SuggestDeck(name string) string {
+	return suggest.Closest(name, libraryDeckNames())
+}
+
+// libraryDeckNames returns the directory names and deck IDs of decks in the library
+func libraryDeckNames() []string {
+	libraryPath := GetDeckLibraryPath()
+	entries, _ := os.ReadDir(libraryPath)
+
+	var names []string
+	for _, entry := range entries {
+		var header deckHeader
+		if _, err := toml.DecodeFile(filepath.Join(libraryPath, entry.Name(), "deck.toml"), &header); err != nil {
+			continue
+		}
+		names = append(names, entry.Name())
+		if header.Deck.ID != "" && header.Deck.ID != entry.Name() {
+			names = append(names, header.Deck.ID)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// contains reports whether value is in list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -4,13 +4,192 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/deck"
 )
 
+// warnUnknownKeys ensures the unknown key warning is printed at most once
+var warnUnknownKeys sync.Once
+
 // Config represents the application configuration
 type Config struct {
-	DefaultDeck string `toml:"default_deck"`
+	DefaultDeck string   `toml:"default_deck"`
+	Numbering   string   `toml:"numbering,omitempty"`    // arabic, padded or roman
+	Theme       string   `toml:"theme,omitempty"`        // default, mono, solarized, mystic or high-contrast
+	Symbols     string   `toml:"symbols,omitempty"`      // auto, nerd, unicode or ascii
+	Background  string   `toml:"background,omitempty"`   // Terminal background: auto, dark or light
+	ShowFields  []string `toml:"show_fields,omitempty"`  // Info panel fields for show, in order
+	AstroTiming bool     `toml:"astro_timing,omitempty"` // Annotate readings with moon phase and sun sign
+	SafeMode    bool     `toml:"safe_mode,omitempty"`    // Never show the art of cards with content warnings
+	CBProfile   string   `toml:"cb_profile,omitempty"`   // Color vision profile applied to card art, e.g. deuteranopia
+	Kiosk       bool     `toml:"kiosk,omitempty"`        // Only allow browsing and drawing cards, for public terminals
+
+	// Largest card image decoded, width times height; 0 for the default of 100 megapixels
+	MaxImagePixels int `toml:"max_image_pixels,omitempty"`
+
+	// Commands to run on events, keyed by event name (e.g. post-draw)
+	Hooks map[string][]string `toml:"hooks,omitempty"`
+
+	// Registries that deck publish can upload to, keyed by registry name
+	Registries map[string]Registry `toml:"registries,omitempty"`
+
+	// Image generation services that deck gen-art can use, keyed by name
+	ImageBackends map[string]ImageBackend `toml:"image_backends,omitempty"`
+
+	// Language model endpoint that deck gen-alt-text can draft alt text with
+	LLM *LLM `toml:"llm,omitempty"`
+
+	// Access control for the serve command's HTTP server
+	Serve *Serve `toml:"serve,omitempty"`
+
+	// Default method and per-method settings of the yesno command
+	YesNo *YesNo `toml:"yesno,omitempty"`
+
+	// Card art to hide by the deck's content warnings
+	ContentFilter *ContentFilter `toml:"content_filter,omitempty"`
+
+	// Journaling prompts shown with the card of the day
+	Prompts *Prompts `toml:"prompts,omitempty"`
+
+	// ANSI art variants that deck bake --profile renders, keyed by name
+	BakeProfiles map[string]BakeProfile `toml:"bake_profiles,omitempty"`
+}
+
+// Registry backends supported by deck publish
+const (
+	RegistryGitHub = "github"
+	RegistryS3     = "s3"
+	RegistryHTTP   = "http"
+)
+
+// Registry configures where deck publish uploads packaged decks
+type Registry struct {
+	Backend  string            `toml:"backend"`             // github, s3 or http
+	URL      string            `toml:"url,omitempty"`       // http: base URL; github: API URL for GitHub Enterprise
+	Repo     string            `toml:"repo,omitempty"`      // github: owner/name
+	Bucket   string            `toml:"bucket,omitempty"`    // s3
+	Region   string            `toml:"region,omitempty"`    // s3
+	Endpoint string            `toml:"endpoint,omitempty"`  // s3: endpoint of S3-compatible storage
+	Prefix   string            `toml:"prefix,omitempty"`    // s3 and http: path prepended to uploaded names
+	TokenEnv string            `toml:"token_env,omitempty"` // github and http: environment variable holding a token
+	Headers  map[string]string `toml:"headers,omitempty"`   // http: extra request headers
+}
+
+// Image generation backends supported by deck gen-art
+const (
+	ImageBackendA1111   = "automatic1111" // Stable Diffusion web UI API
+	ImageBackendComfyUI = "comfyui"
+	ImageBackendCommand = "command"
+)
+
+// ImageBackend configures an image generation service for deck gen-art
+type ImageBackend struct {
+	Backend        string            `toml:"backend"`                   // automatic1111, comfyui or command
+	URL            string            `toml:"url,omitempty"`             // automatic1111 and comfyui: server URL
+	Workflow       string            `toml:"workflow,omitempty"`        // comfyui: API-format workflow JSON file
+	Command        string            `toml:"command,omitempty"`         // command: shell command writing $CARTOMANCER_OUTPUT
+	Style          string            `toml:"style,omitempty"`           // Appended to every card prompt
+	NegativePrompt string            `toml:"negative_prompt,omitempty"` // What the images should avoid
+	Width          int               `toml:"width,omitempty"`
+	Height         int               `toml:"height,omitempty"`
+	Steps          int               `toml:"steps,omitempty"`
+	TokenEnv       string            `toml:"token_env,omitempty"` // Environment variable holding a bearer token
+	Headers        map[string]string `toml:"headers,omitempty"`   // Extra request headers
+}
+
+// LLM configures an OpenAI-compatible chat completions endpoint, such as a
+// local Ollama or llama.cpp server
+type LLM struct {
+	URL      string            `toml:"url"`                 // Base URL, e.g. http://localhost:11434/v1
+	Model    string            `toml:"model"`               // Model name passed to the endpoint
+	Vision   bool              `toml:"vision,omitempty"`    // Send the card image with the prompt
+	Prompt   string            `toml:"prompt,omitempty"`    // System prompt replacing the built-in one
+	TokenEnv string            `toml:"token_env,omitempty"` // Environment variable holding a bearer token
+	Headers  map[string]string `toml:"headers,omitempty"`   // Extra request headers
+}
+
+// Serve configures access to the HTTP server of the serve command
+type Serve struct {
+	TokenEnv  string  `toml:"token_env,omitempty"`  // Environment variable holding accepted tokens, comma-separated
+	RateLimit float64 `toml:"rate_limit,omitempty"` // Requests per second allowed per client, 0 for no limit
+	Burst     int     `toml:"burst,omitempty"`      // Requests a client may make at once above the rate limit
+	ReadOnly  bool    `toml:"read_only,omitempty"`  // Refuse live sessions and anything else that changes state
+}
+
+// YesNo configures the yesno command
+type YesNo struct {
+	Method    string       `toml:"method,omitempty"` // reversals or aces
+	Reversals *YesNoMethod `toml:"reversals,omitempty"`
+	Aces      *YesNoMethod `toml:"aces,omitempty"`
+}
+
+// YesNoMethod tunes a yes/no method
+type YesNoMethod struct {
+	Cards          int     `toml:"cards,omitempty"`           // Cards drawn, or most cards dealt looking for an ace
+	ReversalChance float64 `toml:"reversal_chance,omitempty"` // Chance of a card being dealt reversed, default 0.5
+}
+
+// Ways of hiding the art of cards caught by the content filter
+const (
+	HideBlur        = "blur"        // Blur the art beyond recognition
+	HideBack        = "back"        // Show the deck's card back instead
+	HidePlaceholder = "placeholder" // Show generated art with the card name
+)
+
+// ContentFilter hides the art of cards carrying chosen content warnings
+type ContentFilter struct {
+	Hide []string `toml:"hide,omitempty"` // Content warnings to hide, or "*" for any
+	Mode string   `toml:"mode,omitempty"` // blur, back or placeholder; default blur
+}
+
+// Prompts configures the journaling prompts of the daily command
+type Prompts struct {
+	Daily      bool   `toml:"daily,omitempty"`      // Show a prompt with the card of the day
+	Hemisphere string `toml:"hemisphere,omitempty"` // north or south, for the sabbats and seasons; default north
+}
+
+// Color depths of bake profiles
+const (
+	BakeColorsTrue = "truecolor" // 24-bit color
+	BakeColors256  = "256"       // The xterm 256-color palette
+	BakeColors16   = "16"        // The 16 standard terminal colors
+	BakeColorsNone = "none"      // Glyphs only
+)
+
+// BakeProfile configures a variant of ANSI art baked by deck bake, such as
+// art sized for 80x24 terminals
+type BakeProfile struct {
+	Width    int    `toml:"width,omitempty"`    // Columns, default 40
+	Height   int    `toml:"height,omitempty"`   // Rows, default 32
+	Colors   string `toml:"colors,omitempty"`   // truecolor, 256, 16 or none; default truecolor
+	Dither   bool   `toml:"dither,omitempty"`   // Dither the colors of 256 and 16 color art
+	Renderer string `toml:"renderer,omitempty"` // halfblock or ascii; default halfblock
+	Dir      string `toml:"dir,omitempty"`      // Directory in the deck, starting with "ansi"; default ansi-<name>
+}
+
+// BakeColors lists the supported color depths of bake profiles
+func BakeColors() []string {
+	return []string{BakeColorsTrue, BakeColors256, BakeColors16, BakeColorsNone}
+}
+
+// HideModes lists the supported content filter modes
+func HideModes() []string {
+	return []string{HideBlur, HideBack, HidePlaceholder}
+}
+
+// MethodOptions returns the settings of a yes/no method, or nil if unset
+func (y *YesNo) MethodOptions(method string) *YesNoMethod {
+	switch method {
+	case "reversals":
+		return y.Reversals
+	case "aces":
+		return y.Aces
+	}
+	return nil
 }
 
 // GetXDGDataHome returns XDG_DATA_HOME or default path
@@ -42,6 +221,57 @@ func GetDeckLibraryPath() string {
 	return filepath.Join(GetXDGDataHome(), "tarot", "decks")
 }
 
+// GetSpreadsDir returns the directory holding custom spread definitions
+func GetSpreadsDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "spreads")
+}
+
+// GetPoolsDir returns the directory holding custom draw pool definitions
+func GetPoolsDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "pools")
+}
+
+// GetSocialTemplatesDir returns the directory holding custom social image templates
+func GetSocialTemplatesDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "social")
+}
+
+// GetCombinationsDir returns the directory holding card pair meanings
+func GetCombinationsDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "combinations")
+}
+
+// GetRulesDir returns the directory holding custom validation rules
+func GetRulesDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "rules")
+}
+
+// GetJournalDir returns the directory holding recorded readings
+func GetJournalDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "journal")
+}
+
+// GetStudyDir returns the directory holding flashcard study progress
+func GetStudyDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "study")
+}
+
+// GetNotesDir returns the directory holding personal notes on cards
+func GetNotesDir() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "notes")
+}
+
+// GetPromptsPath returns the path to the user's journaling prompts, which
+// replace the built-in prompts of the occasions they define
+func GetPromptsPath() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "prompts.toml")
+}
+
+// GetFavoritesPath returns the path to the user's list of favorite cards
+func GetFavoritesPath() string {
+	return filepath.Join(GetXDGDataHome(), "cartomancer", "favorites.toml")
+}
+
 // GetConfigFilePath returns the path to the config file
 func GetConfigFilePath() string {
 	return filepath.Join(GetXDGConfigHome(), "cartomancer", "config.toml")
@@ -72,9 +302,16 @@ func LoadConfig() (*Config, error) {
 	}
 
 	var config Config
-	_, err := toml.DecodeFile(configPath, &config)
+	md, err := toml.DecodeFile(configPath, &config)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding config file: %v", err)
+		return nil, fmt.Errorf("error decoding config file %s: %v\nrun 'cartomancer config check' for details", configPath, err)
+	}
+
+	// Warn once per run about settings that would otherwise be silently ignored
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		warnUnknownKeys.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring unknown keys in %s: %v (run 'cartomancer config check')\n", configPath, undecoded)
+		})
 	}
 
 	return &config, nil
@@ -111,22 +348,164 @@ func createDefaultConfig() (*Config, error) {
 	return config, nil
 }
 
-// GetDeckPath returns the path to a deck, either in the deck library or a relative path
-func GetDeckPath(deckName string) (string, error) {
+// DeckCandidate describes a location considered while resolving a deck reference
+type DeckCandidate struct {
+	Source  string // library, path or id
+	Path    string
+	ID      string
+	Version string
+	Skipped string // Reason the candidate was not selected, empty if selected
+}
+
+// DeckResolution records how a deck reference was resolved to a path
+type DeckResolution struct {
+	Query      string
+	Path       string
+	Source     string
+	Candidates []DeckCandidate
+}
+
+// deckHeader holds the subset of deck.toml needed for resolving decks by ID
+type deckHeader struct {
+	Deck struct {
+		ID      string `toml:"id"`
+		Version string `toml:"version"`
+	} `toml:"deck"`
+}
+
+// ResolveDeck resolves a deck reference and records every candidate that was considered.
+// References are tried as a library directory name, then as a relative path, and finally
+// as a deck ID declared in the deck.toml of decks in the library. When several library
+// decks share the same ID, the one with the highest version is selected.
+func ResolveDeck(deckName string) (*DeckResolution, error) {
+	res := &DeckResolution{Query: deckName}
+
 	// First, try to find the deck in the deck library
 	libraryPath := GetDeckLibraryPath()
 	deckPath := filepath.Join(libraryPath, deckName)
-
 	if _, err := os.Stat(deckPath); err == nil {
-		return deckPath, nil
+		res.selectCandidate(DeckCandidate{Source: "library", Path: deckPath})
+	} else {
+		res.Candidates = append(res.Candidates,
+			DeckCandidate{Source: "library", Path: deckPath, Skipped: "not found"})
 	}
 
 	// If not found in the library, treat as a relative path
 	if _, err := os.Stat(deckName); err == nil {
-		return deckName, nil
+		candidate := DeckCandidate{Source: "path", Path: deckName}
+		if res.Path != "" {
+			candidate.Skipped = "shadowed by library deck"
+			res.Candidates = append(res.Candidates, candidate)
+		} else {
+			res.selectCandidate(candidate)
+		}
+	} else {
+		res.Candidates = append(res.Candidates,
+			DeckCandidate{Source: "path", Path: deckName, Skipped: "not found"})
+	}
+
+	// Finally, look for decks in the library declaring a matching ID
+	entries, _ := os.ReadDir(libraryPath)
+	var idMatches []DeckCandidate
+	for _, entry := range entries {
+		entryPath := filepath.Join(libraryPath, entry.Name())
+		var header deckHeader
+		if _, err := toml.DecodeFile(filepath.Join(entryPath, "deck.toml"), &header); err != nil {
+			continue
+		}
+		if header.Deck.ID != deckName {
+			continue
+		}
+		idMatches = append(idMatches, DeckCandidate{
+			Source:  "id",
+			Path:    entryPath,
+			ID:      header.Deck.ID,
+			Version: header.Deck.Version,
+		})
 	}
 
-	return "", fmt.Errorf("deck not found: %s", deckName)
+	best := -1
+	for i, candidate := range idMatches {
+		if best < 0 || compareVersions(candidate.Version, idMatches[best].Version) > 0 {
+			best = i
+		}
+	}
+
+	for i, candidate := range idMatches {
+		switch {
+		case res.Path != "" && candidate.Path == res.Path:
+			candidate.Skipped = "already selected"
+		case res.Path != "":
+			candidate.Skipped = fmt.Sprintf("shadowed by %s match", res.Source)
+		case i != best:
+			candidate.Skipped = fmt.Sprintf("older version than %s", idMatches[best].Version)
+		}
+		if candidate.Skipped == "" {
+			res.selectCandidate(candidate)
+		} else {
+			res.Candidates = append(res.Candidates, candidate)
+		}
+	}
+
+	if res.Path == "" {
+		if suggestion := SuggestDeck(deckName); suggestion != "" {
+			return res, fmt.Errorf("%w: %s (did you mean %q?)", deck.ErrDeckNotFound, deckName, suggestion)
+		}
+		return res, fmt.Errorf("%w: %s", deck.ErrDeckNotFound, deckName)
+	}
+
+	return res, nil
+}
+
+// selectCandidate records a candidate as the resolved deck
+func (r *DeckResolution) selectCandidate(candidate DeckCandidate) {
+	r.Path = candidate.Path
+	r.Source = candidate.Source
+	r.Candidates = append(r.Candidates, candidate)
+}
+
+// compareVersions compares two dotted version strings numerically where possible
+func compareVersions(a, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+
+		aNum, aErr := strconv.Atoi(aPart)
+		bNum, bErr := strconv.Atoi(bPart)
+		if aErr == nil && bErr == nil {
+			if aNum != bNum {
+				if aNum < bNum {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+
+		if c := strings.Compare(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// GetDeckPath returns the path to a deck, either in the deck library or a relative path
+func GetDeckPath(deckName string) (string, error) {
+	res, err := ResolveDeck(deckName)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Path, nil
 }
 
 // GetDefaultDeck returns the default deck name from config
@@ -139,6 +518,16 @@ func GetDefaultDeck() (string, error) {
 	return config.DefaultDeck, nil
 }
 
+// GetTheme returns the configured theme name and symbol set
+func GetTheme() (string, string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return "", "", err
+	}
+
+	return config.Theme, config.Symbols, nil
+}
+
 // SetDefaultDeck sets the default deck in the config
 func SetDefaultDeck(deckName string) error {
 	config, err := LoadConfig()
@@ -0,0 +1,53 @@
+// Package correspondence maps cards to their traditional correspondences:
+// the classical elements and the Golden Dawn astrological attributions, and
+// the elemental dignities between cards that follow from them.
+package correspondence
+
+import "github.com/arcanaland/cartomancer/internal/card"
+
+// Classical elements
+const (
+	Fire  = "fire"
+	Water = "water"
+	Air   = "air"
+	Earth = "earth"
+)
+
+// majorAttributions are the Golden Dawn attributions of the major arcana, by
+// number: a planet, a zodiac sign or, for three cards, an element
+var majorAttributions = []string{
+	"Air", "Mercury", "Moon", "Venus", "Aries", "Taurus", "Gemini", "Cancer",
+	"Leo", "Virgo", "Jupiter", "Libra", "Water", "Scorpio", "Sagittarius",
+	"Capricorn", "Mars", "Aquarius", "Pisces", "Sun", "Fire", "Saturn",
+}
+
+// attributionElements maps each attribution to its element. Signs take their
+// triplicity; planets the element of the Golden Dawn's elemental tablets.
+var attributionElements = map[string]string{
+	"Aries": Fire, "Leo": Fire, "Sagittarius": Fire,
+	"Taurus": Earth, "Virgo": Earth, "Capricorn": Earth,
+	"Gemini": Air, "Libra": Air, "Aquarius": Air,
+	"Cancer": Water, "Scorpio": Water, "Pisces": Water,
+	"Sun": Fire, "Mars": Fire, "Jupiter": Fire,
+	"Moon": Water, "Venus": Earth, "Mercury": Air, "Saturn": Earth,
+	"Fire": Fire, "Water": Water, "Air": Air, "Earth": Earth,
+}
+
+// Attribution returns the Golden Dawn attribution of a major arcana card, such
+// as "Aries" for the Emperor, or "" for other cards
+func Attribution(c *card.Card) string {
+	if c.Type != "major_arcana" || c.Number == "" || c.Value < 0 || c.Value >= len(majorAttributions) {
+		return ""
+	}
+	return majorAttributions[c.Value]
+}
+
+// Element returns the classical element of a card: its suit's for the minor
+// arcana and its attribution's for the major arcana, or "" for cards outside
+// the standard deck
+func Element(c *card.Card) string {
+	if c.Element != "" {
+		return c.Element
+	}
+	return attributionElements[Attribution(c)]
+}
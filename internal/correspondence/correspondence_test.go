@@ -0,0 +1,57 @@
+package correspondence
+
+import (
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+func TestElement(t *testing.T) {
+	tests := []struct {
+		card *card.Card
+		want string
+	}{
+		{card.NewMajorArcana(0), Air},
+		{card.NewMajorArcana(4), Fire},
+		{card.NewMajorArcana(13), Water},
+		{card.NewMajorArcana(21), Earth},
+		{card.NewMinorArcana("cups", "queen"), Water},
+		{card.NewMinorArcana("pentacles", "ace"), Earth},
+		{&card.Card{ID: "custom_cards.major_arcana.x", Type: "major_arcana"}, ""},
+	}
+	for _, tt := range tests {
+		if got := Element(tt.card); got != tt.want {
+			t.Errorf("Element(%s) = %q, want %q", tt.card.ID, got, tt.want)
+		}
+	}
+
+	for i := 0; i < 22; i++ {
+		if Element(card.NewMajorArcana(i)) == "" {
+			t.Errorf("major arcana %d has no element", i)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want Dignity
+	}{
+		{Fire, Fire, Strengthening},
+		{Fire, Air, Strengthening},
+		{Earth, Water, Strengthening},
+		{Fire, Water, Weakening},
+		{Earth, Air, Weakening},
+		{Fire, Earth, Neutral},
+		{Air, Water, Neutral},
+		{Fire, "", Neutral},
+	}
+	for _, tt := range tests {
+		if got := Between(tt.a, tt.b); got != tt.want {
+			t.Errorf("Between(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := Between(tt.b, tt.a); got != tt.want {
+			t.Errorf("Between(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package correspondence
+
+// Dignity is the effect two cards have on each other through their elements
+type Dignity int
+
+const (
+	Neutral       Dignity = iota // Fire and earth, air and water, or no element
+	Strengthening                // The same element, or fire and air, or water and earth
+	Weakening                    // Contrary elements: fire and water, air and earth
+)
+
+// String returns the name of the dignity
+func (d Dignity) String() string {
+	switch d {
+	case Strengthening:
+		return "strengthening"
+	case Weakening:
+		return "weakening"
+	}
+	return "neutral"
+}
+
+// friendly are the pairs of elements that support each other, keyed in both orders
+var friendly = map[[2]string]bool{
+	{Fire, Air}: true, {Air, Fire}: true,
+	{Water, Earth}: true, {Earth, Water}: true,
+}
+
+// contrary are the pairs of elements that weaken each other, keyed in both orders
+var contrary = map[[2]string]bool{
+	{Fire, Water}: true, {Water, Fire}: true,
+	{Air, Earth}: true, {Earth, Air}: true,
+}
+
+// Between returns the elemental dignity between two elements
+func Between(a, b string) Dignity {
+	switch {
+	case a == "" || b == "":
+		return Neutral
+	case a == b || friendly[[2]string{a, b}]:
+		return Strengthening
+	case contrary[[2]string{a, b}]:
+		return Weakening
+	}
+	return Neutral
+}
+
+// Balance sums the dignities of a card with its neighbours: a positive balance
+// leaves the card well dignified, a negative one ill dignified
+func Balance(dignities []Dignity) int {
+	balance := 0
+	for _, d := range dignities {
+		switch d {
+		case Strengthening:
+			balance++
+		case Weakening:
+			balance--
+		}
+	}
+	return balance
+}
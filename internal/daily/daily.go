@@ -0,0 +1,82 @@
+// Package daily selects and caches the card of the day.
+package daily
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/atomicfile"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Entry is the cached card of the day for a deck
+type Entry struct {
+	Date     string `toml:"date"` // YYYY-MM-DD in local time
+	DeckPath string `toml:"deck_path"`
+	CardID   string `toml:"card_id"`
+	Name     string `toml:"name"`
+	Type     string `toml:"type"`
+	Suit     string `toml:"suit"`
+	AltText  string `toml:"alt_text,omitempty"`
+}
+
+// DateString formats a time as the date key used for daily entries
+func DateString(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// Pick deterministically chooses the card of the day, so every invocation on
+// the same date with the same deck selects the same card
+func Pick(date, deckID string, cards []*card.Card) (*card.Card, error) {
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("deck has no cards")
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(date + "/" + deckID))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+
+	return cards[rng.Intn(len(cards))], nil
+}
+
+// cachePath returns the path of the daily cache file
+func cachePath(cacheDir string) string {
+	return filepath.Join(cacheDir, "daily.toml")
+}
+
+// Load returns the cached entry if it was recorded for the given date and deck
+func Load(cacheDir, date, deckPath string) (*Entry, bool) {
+	var entry Entry
+	if _, err := toml.DecodeFile(cachePath(cacheDir), &entry); err != nil {
+		return nil, false
+	}
+
+	if entry.Date != date || entry.DeckPath != deckPath {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// Save records the card of the day in the cache
+func Save(cacheDir string, entry *Entry) error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+
+	// Replace the file atomically, as prompts and widgets read it concurrently
+	err := atomicfile.Write(cachePath(cacheDir), 0644, func(w io.Writer) error {
+		return toml.NewEncoder(w).Encode(entry)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing daily cache: %v", err)
+	}
+
+	return nil
+}
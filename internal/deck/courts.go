@@ -0,0 +1,139 @@
+package deck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/canon"
+)
+
+// Ranks returns the ranks of the standard suits in deck order: the standard
+// ranks less any court the deck leaves out by aliasing it to an empty name
+func (c *DeckConfig) Ranks() []string {
+	var ranks []string
+	for _, rank := range canon.Ranks() {
+		if name, ok := c.courtAliases()[rank]; ok && name == "" {
+			continue
+		}
+		ranks = append(ranks, rank)
+	}
+	return ranks
+}
+
+// RankName returns the name the deck uses for a standard rank, such as
+// "princess" for page in a Thoth deck
+func (c *DeckConfig) RankName(rank string) string {
+	if name := c.courtAliases()[rank]; name != "" {
+		return name
+	}
+	return rank
+}
+
+// SuitAlias returns the name the deck uses for a standard suit, such as
+// "disks" for pentacles
+func (c *DeckConfig) SuitAlias(suit string) string {
+	if c.Aliases != nil && c.Aliases.Suits[suit] != "" {
+		return c.Aliases.Suits[suit]
+	}
+	return suit
+}
+
+// courtAliases returns the [aliases.courts] table, which may be nil
+func (c *DeckConfig) courtAliases() map[string]string {
+	if c.Aliases == nil {
+		return nil
+	}
+	return c.Aliases.Courts
+}
+
+// resolveAliases rewrites a minor arcana ID written with the deck's own suit
+// and court names, such as "disks.princess", to the standard notation. IDs
+// without deck names are returned unchanged.
+func (c *DeckConfig) resolveAliases(id string) string {
+	if c.Aliases == nil {
+		return id
+	}
+
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(id, "/", ".")), ".")
+	if len(parts) == 3 && parts[0] == "minor_arcana" {
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return id
+	}
+
+	suit, rank, aliased := parts[0], parts[1], false
+	for canonical, name := range c.Aliases.Suits {
+		if name == suit && canonical != suit {
+			suit, aliased = canonical, true
+			break
+		}
+	}
+	for canonical, name := range c.Aliases.Courts {
+		if name == rank && canonical != rank {
+			rank, aliased = canonical, true
+			break
+		}
+	}
+	if !aliased {
+		return id
+	}
+	return "minor_arcana." + suit + "." + rank
+}
+
+// CheckAliases reports problems with the [aliases] table: keys that are not
+// standard suits or court ranks, names that are not valid words and names
+// that are used twice or clash with a standard name the deck still uses
+func CheckAliases(config *DeckConfig) []error {
+	if config.Aliases == nil {
+		return nil
+	}
+
+	var errs []error
+	check := func(table string, aliases map[string]string, standard, names []string, allowEmpty bool) {
+		keys := make([]string, 0, len(aliases))
+		for key := range aliases {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		// Standard names the deck keeps, which aliases must not reuse
+		taken := make(map[string]string)
+		for _, name := range names {
+			if _, ok := aliases[name]; !ok {
+				taken[name] = name
+			}
+		}
+
+		for _, key := range keys {
+			name := aliases[key]
+			field := fmt.Sprintf("aliases.%s.%s", table, key)
+			switch {
+			case !contains(standard, key):
+				errs = append(errs, fmt.Errorf("%s: not a standard %s (%s)", field, strings.TrimSuffix(table, "s"), strings.Join(standard, ", ")))
+			case name == "" && allowEmpty:
+				// The deck leaves this court out
+			case !customKeyPattern.MatchString(name):
+				errs = append(errs, fmt.Errorf("%s: names must be lowercase letters, digits, '_' and '-'", field))
+			case taken[name] != "":
+				errs = append(errs, fmt.Errorf("%s: %q is already the name of %s", field, name, taken[name]))
+			default:
+				taken[name] = key
+			}
+		}
+	}
+	check("suits", config.Aliases.Suits, canon.Suits(), canon.Suits(), false)
+	check("courts", config.Aliases.Courts, canon.Courts(), canon.Ranks(), true)
+	return errs
+}
+
+// contains reports whether a string is in a slice
+func contains(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package deck
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+// thothAliases renames the courts and a suit as the Thoth deck does
+const thothAliases = `
+[aliases.suits]
+pentacles = "disks"
+
+[aliases.courts]
+page = "princess"
+knight = "prince"
+king = "knight"
+`
+
+func TestLoadDeckCourtAliases(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	os.RemoveAll(filepath.Join(root, "names"))
+	f, err := os.OpenFile(filepath.Join(root, "deck.toml"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(thothAliases); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id, wantID, wantName string
+	}{
+		{"disks.princess", "minor_arcana.pentacles.page", "Princess of Disks"},
+		{"wands/prince", "minor_arcana.wands.knight", "Prince of Wands"},
+		{"minor_arcana.cups.knight", "minor_arcana.cups.king", "Knight of Cups"},
+		{"cups.queen", "minor_arcana.cups.queen", "Queen of Cups"},
+		{"minor_arcana.swords.page", "minor_arcana.swords.page", "Princess of Swords"},
+	}
+	for _, tt := range tests {
+		c, err := d.GetCard(tt.id)
+		if err != nil {
+			t.Errorf("GetCard(%s): %v", tt.id, err)
+			continue
+		}
+		if c.ID != tt.wantID || c.Name != tt.wantName {
+			t.Errorf("GetCard(%s) = %s %q, want %s %q", tt.id, c.ID, c.Name, tt.wantID, tt.wantName)
+		}
+	}
+	if got := d.SuitName("pentacles"); got != "Disks" {
+		t.Errorf("SuitName(pentacles) = %q, want Disks", got)
+	}
+}
+
+func TestLoadDeckDroppedCourt(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	f, err := os.OpenFile(filepath.Join(root, "deck.toml"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("\n[aliases.courts]\nknight = \"\"\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(d.Cards()); n != 74 {
+		t.Errorf("deck has %d cards, want 74", n)
+	}
+	if _, err := d.GetCard("wands.knight"); !errors.Is(err, ErrCardNotFound) {
+		t.Errorf("GetCard(wands.knight) = %v, want a card not found error", err)
+	}
+}
+
+func TestCheckAliases(t *testing.T) {
+	tests := []struct {
+		toml string
+		want string // Substring of the first error, empty for none
+	}{
+		{thothAliases, ""},
+		{"[aliases.courts]\nknight = \"\"\n", ""},
+		{"[aliases.courts]\nace = \"one\"\n", "not a standard court"},
+		{"[aliases.suits]\ncoins = \"disks\"\n", "not a standard suit"},
+		{"[aliases.suits]\nwands = \"\"\n", "names must be"},
+		{"[aliases.courts]\npage = \"Princess\"\n", "names must be"},
+		{"[aliases.courts]\npage = \"queen\"\n", "already the name of queen"},
+		{"[aliases.courts]\npage = \"ten\"\n", "already the name of ten"},
+		{"[aliases.courts]\npage = \"prince\"\nknight = \"prince\"\n", "already the name of"},
+	}
+	for _, tt := range tests {
+		var config DeckConfig
+		if _, err := toml.Decode(tt.toml, &config); err != nil {
+			t.Fatal(err)
+		}
+		errs := CheckAliases(&config)
+		switch {
+		case tt.want == "" && len(errs) > 0:
+			t.Errorf("%q: unexpected error %v", tt.toml, errs[0])
+		case tt.want != "" && len(errs) == 0:
+			t.Errorf("%q: no error, want %q", tt.toml, tt.want)
+		case tt.want != "" && !strings.Contains(errs[0].Error(), tt.want):
+			t.Errorf("%q: error %v, want %q", tt.toml, errs[0], tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package deck
+
+import (
+	"fmt"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// CreditsSection holds the [credits] table of deck.toml, crediting the art of
+// collaborative decks. Like [style], values cascade from the deck to the major
+// arcana or a suit, and then to individual cards.
+type CreditsSection struct {
+	CreditEntry
+	MajorArcana *CreditEntry           `toml:"major_arcana"`
+	Suits       map[string]CreditEntry `toml:"suits"`
+	Cards       map[string]CreditEntry `toml:"cards"` // Keyed by card ID
+}
+
+// CreditEntry declares the artist, source and license of card art
+type CreditEntry struct {
+	Artist  string `toml:"artist"`
+	Source  string `toml:"source"`  // URL or description of the original work
+	License string `toml:"license"` // SPDX license expression
+}
+
+// mergeCredit overrides the credit with the non-empty fields of an entry
+func mergeCredit(c *card.Credit, e *CreditEntry) {
+	if e == nil {
+		return
+	}
+	if e.Artist != "" {
+		c.Artist = e.Artist
+	}
+	if e.Source != "" {
+		c.Source = e.Source
+	}
+	if e.License != "" {
+		c.License = e.License
+	}
+}
+
+// applyCredits resolves the [credits] table onto every card
+func (d *Deck) applyCredits() {
+	section := d.config.Credits
+	if section == nil {
+		return
+	}
+
+	// Card keys may use any notation accepted by card.ParseID
+	cards := make(map[string]CreditEntry, len(section.Cards))
+	for id, entry := range section.Cards {
+		if canonical, err := card.ParseID(id); err == nil {
+			cards[canonical] = entry
+		}
+	}
+
+	for _, c := range d.Cards() {
+		mergeCredit(&c.Credit, &section.CreditEntry)
+		if c.Type == "major_arcana" {
+			mergeCredit(&c.Credit, section.MajorArcana)
+		} else if entry, ok := section.Suits[c.Suit]; ok {
+			mergeCredit(&c.Credit, &entry)
+		}
+		if entry, ok := cards[c.ID]; ok {
+			mergeCredit(&c.Credit, &entry)
+		}
+	}
+}
+
+// CreditEntries returns every credit entry in the section keyed by its TOML
+// path, for validation
+func (s *CreditsSection) CreditEntries() map[string]CreditEntry {
+	entries := map[string]CreditEntry{"credits": s.CreditEntry}
+	if s.MajorArcana != nil {
+		entries["credits.major_arcana"] = *s.MajorArcana
+	}
+	for suit, entry := range s.Suits {
+		entries["credits.suits."+suit] = entry
+	}
+	for id, entry := range s.Cards {
+		entries[fmt.Sprintf("credits.cards.%q", id)] = entry
+	}
+	return entries
+}
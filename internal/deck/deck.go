@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/canon"
 	"github.com/arcanaland/cartomancer/internal/card"
 )
 
+// PlaceholderDir is the deck directory holding generated placeholder art, used
+// only for cards without art of their own
+const PlaceholderDir = "placeholders"
+
 // Deck represents a tarot deck
 type Deck struct {
 	ID          string
@@ -17,7 +22,20 @@ type Deck struct {
 	Version     string
 	Author      string
 	Description string
+	License     string
+	Tags        []string
+	Publisher   string
+	Website     string
+	CardURL     string  // Pattern of the address of each card's page, see CardPage
+	AspectRatio float64 // Card width divided by height, 0 when the deck does not declare it
 	Path        string
+	Variant     string // Selected variant key, empty for the base deck
+
+	// Content warnings for the whole deck; cards carry these and their own
+	ContentWarnings []string
+
+	// Credit for the card descriptions, from the names file metadata
+	AltTextAttribution string
 
 	// Card maps for lookup
 	MajorArcana map[string]*card.Card
@@ -32,13 +50,21 @@ func LoadDeck(deckPath string) (*Deck, error) {
 	// Check if deck.toml exists
 	deckTomlPath := filepath.Join(deckPath, "deck.toml")
 	if _, err := os.Stat(deckTomlPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("deck.toml not found in %s", deckPath)
+		return nil, newError(ErrDeckNotFound, err, "deck.toml not found in %s", deckPath)
 	}
 
 	// Decode deck.toml
 	var config DeckConfig
-	if _, err := toml.DecodeFile(deckTomlPath, &config); err != nil {
-		return nil, fmt.Errorf("error parsing deck.toml: %v", err)
+	if _, err := DecodeTomlFile(deckTomlPath, &config); err != nil {
+		return nil, newError(ErrInvalidDeck, err, "error parsing deck.toml: %v", err)
+	}
+
+	// Reject decks that exceed limits or reference files outside the deck
+	if errs := CheckLimits(deckPath, &config); len(errs) > 0 {
+		return nil, newError(ErrInvalidDeck, errs[0], "invalid deck.toml: %v", errs[0])
+	}
+	if errs := append(append(CheckAliases(&config), CheckCustomSuits(&config)...), CheckCardURL(&config)...); len(errs) > 0 {
+		return nil, newError(ErrInvalidDeck, errs[0], "invalid deck.toml: %v", errs[0])
 	}
 
 	// Create deck
@@ -48,6 +74,12 @@ func LoadDeck(deckPath string) (*Deck, error) {
 		Version:     config.Deck.Version,
 		Author:      config.Deck.Author,
 		Description: config.Deck.Description,
+		License:     config.Deck.License,
+		Tags:        config.Deck.Tags,
+		Publisher:   config.Deck.Publisher,
+		Website:     config.Deck.Website,
+		CardURL:     config.Deck.CardURL,
+		AspectRatio: config.Deck.AspectRatio,
 		Path:        deckPath,
 		MajorArcana: make(map[string]*card.Card),
 		MinorArcana: make(map[string]map[string]*card.Card),
@@ -56,52 +88,36 @@ func LoadDeck(deckPath string) (*Deck, error) {
 
 	// Load card names and alt text
 	if err := deck.loadCardInfo(); err != nil {
-		return nil, fmt.Errorf("error loading card info: %v", err)
+		return nil, fmt.Errorf("error loading card info: %w", err)
 	}
 
+	deck.applyCredits()
+	deck.applyContentWarnings()
+
 	return deck, nil
 }
 
 // loadCardInfo loads card names and alt text from the names directory
 func (d *Deck) loadCardInfo() error {
 	// Create cards for major arcana (00-21)
-	for i := 0; i <= 21; i++ {
-		cardNumber := fmt.Sprintf("%02d", i)
-		cardID := fmt.Sprintf("major_arcana.%s", cardNumber)
-
-		c := &card.Card{
-			ID:     cardID,
-			Type:   "major_arcana",
-			Number: cardNumber,
-		}
-
-		d.MajorArcana[cardNumber] = c
+	for i := 0; i < canon.MajorCount; i++ {
+		c := card.NewMajorArcana(i)
+		d.MajorArcana[c.Number] = c
 	}
 
 	// Create cards for minor arcana
-	suits := []string{"wands", "cups", "swords", "pentacles"}
-	ranks := []string{
-		"ace", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
-		"page", "knight", "queen", "king",
-	}
-
-	for _, suit := range suits {
+	for _, suit := range canon.Suits() {
 		d.MinorArcana[suit] = make(map[string]*card.Card)
 
-		for _, rank := range ranks {
-			cardID := fmt.Sprintf("minor_arcana.%s.%s", suit, rank)
-
-			c := &card.Card{
-				ID:   cardID,
-				Type: "minor_arcana",
-				Suit: suit,
-				Rank: rank,
-			}
-
-			d.MinorArcana[suit][rank] = c
+		for _, rank := range d.config.Ranks() {
+			d.MinorArcana[suit][rank] = card.NewMinorArcana(suit, rank)
 		}
 	}
 
+	// Custom suits from deck.toml are named there rather than in the names
+	// directory
+	d.loadCustomSuits()
+
 	// Try to load names and alt text
 	namesDir := filepath.Join(d.Path, "names")
 	if _, err := os.Stat(namesDir); os.IsNotExist(err) {
@@ -130,68 +146,46 @@ func (d *Deck) loadCardInfo() error {
 		}
 	}
 
-	// First read the raw TOML file to get the complete structure
-	var rawData map[string]interface{}
-	_, err := toml.DecodeFile(enTomlPath, &rawData)
-	if err != nil {
-		d.setDefaultNames()
-		return fmt.Errorf("error parsing language file: %v", err)
-	}
-
 	// Decode language file for standard sections
 	var langConfig NameConfig
-	if _, err := toml.DecodeFile(enTomlPath, &langConfig); err != nil {
+	if _, err := DecodeTomlFile(enTomlPath, &langConfig); err != nil {
 		// Error parsing language file, use default names
 		d.setDefaultNames()
-		return fmt.Errorf("error parsing language file: %v", err)
+		return newError(ErrNoNames, err, "error parsing language file: %v", err)
 	}
 
-	// Set names from language file
-	if langConfig.MajorArcana != nil {
-		for num, name := range langConfig.MajorArcana {
-			if card, ok := d.MajorArcana[num]; ok {
-				card.Name = name
-			}
-		}
+	if langConfig.Metadata != nil {
+		d.AltTextAttribution = langConfig.Metadata.AltTextAttribution
 	}
 
-	if langConfig.MinorArcana != nil {
-		for suit, ranks := range langConfig.MinorArcana {
-			if suitMap, ok := d.MinorArcana[suit]; ok {
-				for rank, name := range ranks {
-					if card, ok := suitMap[rank]; ok {
-						card.Name = name
-					}
+	// Set names from language file, sharing the default names between decks
+	for num, name := range langConfig.MajorArcana {
+		if c, ok := d.MajorArcana[num]; ok {
+			c.Name = card.Intern(c.ID, name)
+		}
+	}
+	for suit, ranks := range langConfig.MinorArcana {
+		if suitMap, ok := d.MinorArcana[suit]; ok {
+			for rank, name := range ranks {
+				if c, ok := suitMap[rank]; ok {
+					c.Name = card.Intern(c.ID, name)
 				}
 			}
 		}
 	}
 
-	// Check if alt_text section exists in the raw data
-	if altTextData, ok := rawData["alt_text"].(map[string]interface{}); ok {
-		// Major arcana alt text
-		if majorArcanaAltText, ok := altTextData["major_arcana"].(map[string]interface{}); ok {
-			for numStr, altTextVal := range majorArcanaAltText {
-				if altText, ok := altTextVal.(string); ok {
-					if card, ok := d.MajorArcana[numStr]; ok {
-						card.AltText = altText
-					}
-				}
+	// Set alt text from the [alt_text] tables
+	if langConfig.AltText != nil {
+		for num, altText := range langConfig.AltText.MajorArcana {
+			if c, ok := d.MajorArcana[num]; ok {
+				c.AltText = altText
 			}
 		}
-
-		// Minor arcana alt text
-		if minorArcanaAltText, ok := altTextData["minor_arcana"].(map[string]interface{}); ok {
-			for suitStr, ranksVal := range minorArcanaAltText {
-				if ranks, ok := ranksVal.(map[string]interface{}); ok {
-					if suitMap, ok := d.MinorArcana[suitStr]; ok {
-						for rankStr, altTextVal := range ranks {
-							if altText, ok := altTextVal.(string); ok {
-								if card, ok := suitMap[rankStr]; ok {
-									card.AltText = altText
-								}
-							}
-						}
+		for suit, ranks := range langConfig.AltText.MinorArcana {
+			if suitMap, ok := d.MinorArcana[suit]; ok {
+				for rank, altText := range ranks {
+					if c, ok := suitMap[rank]; ok {
+						c.AltText = altText
 					}
 				}
 			}
@@ -208,7 +202,7 @@ func (d *Deck) loadCardInfo() error {
 	for _, suitMap := range d.MinorArcana {
 		for _, card := range suitMap {
 			if card.Name == "" {
-				card.Name = getDefaultMinorArcanaName(card.Rank, card.Suit)
+				card.Name = d.defaultMinorName(card.Rank, card.Suit)
 			}
 		}
 	}
@@ -216,6 +210,64 @@ func (d *Deck) loadCardInfo() error {
 	return nil
 }
 
+// LocalizedNames returns the card names from every language file in the
+// names directory, keyed by language code (the file name without .toml) and
+// card ID
+func (d *Deck) LocalizedNames() (map[string]map[string]string, error) {
+	names := map[string]map[string]string{}
+
+	entries, err := os.ReadDir(filepath.Join(d.Path, "names"))
+	if os.IsNotExist(err) {
+		return names, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		var langConfig NameConfig
+		if _, err := DecodeTomlFile(filepath.Join(d.Path, "names", entry.Name()), &langConfig); err != nil {
+			return nil, newError(ErrNoNames, err, "error parsing language file %s: %v", entry.Name(), err)
+		}
+
+		lang := strings.TrimSuffix(entry.Name(), ".toml")
+		byID := make(map[string]string, len(langConfig.MajorArcana)+14*len(langConfig.MinorArcana))
+		for num, name := range langConfig.MajorArcana {
+			id := "major_arcana." + num
+			if c, ok := d.MajorArcana[num]; ok {
+				id = c.ID
+			}
+			byID[id] = card.Intern(id, name)
+		}
+		for suit, ranks := range langConfig.MinorArcana {
+			for rank, name := range ranks {
+				id := "minor_arcana." + suit + "." + rank
+				if c, ok := d.MinorArcana[suit][rank]; ok {
+					id = c.ID
+				}
+				byID[id] = card.Intern(id, name)
+			}
+		}
+		names[lang] = byID
+	}
+
+	return names, nil
+}
+
+// MissingAltText returns the cards without alt text, in deck order
+func (d *Deck) MissingAltText() []*card.Card {
+	var missing []*card.Card
+	for _, c := range d.Cards() {
+		if strings.TrimSpace(c.AltText) == "" {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
 // setDefaultNames sets default names for all cards
 func (d *Deck) setDefaultNames() {
 	// Set default names for major arcana
@@ -225,40 +277,157 @@ func (d *Deck) setDefaultNames() {
 
 	// Set default names for minor arcana
 	for suit, suitMap := range d.MinorArcana {
+		if d.IsCustomSuit(suit) {
+			continue
+		}
 		for rank, card := range suitMap {
-			card.Name = getDefaultMinorArcanaName(rank, suit)
+			card.Name = d.defaultMinorName(rank, suit)
 		}
 	}
 }
 
-// GetCard gets a card by its canonical ID
+// GetCard gets a card by its canonical ID or any notation accepted by card.ParseID
 func (d *Deck) GetCard(cardID string) (*card.Card, error) {
+	if c, ok := d.customSuitCard(cardID); ok {
+		return c, nil
+	}
+	cardID = d.config.resolveAliases(cardID)
+
+	input := cardID
+	cardID, err := card.ParseID(cardID)
+	if err != nil {
+		return nil, card.WithSuggestion(input, err)
+	}
+
 	parts := splitCardID(cardID)
 	if len(parts) < 2 {
-		return nil, fmt.Errorf("invalid card ID format: %s", cardID)
+		return nil, newError(ErrInvalidCardID, nil, "invalid card ID format: %s", cardID)
 	}
 
 	if parts[0] == "major_arcana" && len(parts) == 2 {
 		// Major arcana card
 		card, ok := d.MajorArcana[parts[1]]
 		if !ok {
-			return nil, fmt.Errorf("card not found: %s", cardID)
+			return nil, newError(ErrCardNotFound, nil, "card not found: %s", cardID)
 		}
 		return card, nil
 	} else if parts[0] == "minor_arcana" && len(parts) == 3 {
 		// Minor arcana card
 		suitMap, ok := d.MinorArcana[parts[1]]
 		if !ok {
-			return nil, fmt.Errorf("suit not found: %s", parts[1])
+			return nil, newError(ErrCardNotFound, nil, "suit not found: %s", parts[1])
 		}
 		card, ok := suitMap[parts[2]]
 		if !ok {
-			return nil, fmt.Errorf("card not found: %s", cardID)
+			return nil, newError(ErrCardNotFound, nil, "card not found: %s", cardID)
 		}
 		return card, nil
 	}
 
-	return nil, fmt.Errorf("invalid card ID format: %s", cardID)
+	return nil, newError(ErrCardNotFound, nil, "card not found: %s", cardID)
+}
+
+// CardBackPath returns the path to the default card back image. The card back of
+// the selected variant is preferred, then the default card back variant from
+// deck.toml, falling back to the first file in card_backs.
+func (d *Deck) CardBackPath() (string, error) {
+	if backs := d.config.CardBacks; backs != nil {
+		if v, ok := d.config.Variants[d.Variant]; ok && v.CardBack != "" {
+			if variant, ok := backs.Variants[v.CardBack]; ok && variant.Image != "" {
+				return SafeJoin(d.Path, variant.Image)
+			}
+		}
+		if variant, ok := backs.Variants[backs.Default]; ok && variant.Image != "" {
+			return SafeJoin(d.Path, variant.Image)
+		}
+		for _, variant := range backs.Variants {
+			if variant.Image != "" {
+				return SafeJoin(d.Path, variant.Image)
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(d.Path, "card_backs"))
+	if err != nil {
+		return "", newError(ErrNoCardBack, err, "no card back found: %v", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return filepath.Join(d.Path, "card_backs", entry.Name()), nil
+		}
+	}
+
+	return "", newError(ErrNoCardBack, nil, "no card back found in %s", d.Path)
+}
+
+// IconPath returns the path to the deck icon declared in deck.toml
+func (d *Deck) IconPath() (string, error) {
+	if d.config.Deck.Icon == "" {
+		return "", fmt.Errorf("deck %s declares no icon", d.Name)
+	}
+	return SafeJoin(d.Path, d.config.Deck.Icon)
+}
+
+// Variants returns the variant table from deck.toml, keyed by variant name
+func (d *Deck) Variants() map[string]VariantSection {
+	return d.config.Variants
+}
+
+// UseVariant selects a variant by its key or ID so that asset lookups prefer
+// the variant's overlay directory. An empty name selects the base deck.
+func (d *Deck) UseVariant(name string) error {
+	if name == "" {
+		d.Variant = ""
+		return nil
+	}
+
+	for key, variant := range d.config.Variants {
+		if key == name || variant.ID == name {
+			d.Variant = key
+			return nil
+		}
+	}
+
+	names := make([]string, 0, len(d.config.Variants))
+	for key := range d.config.Variants {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return newError(ErrVariantNotFound, nil, "variant not found: %s (available: %s)", name, strings.Join(names, ", "))
+}
+
+// AssetRoots returns the directories to search for card assets in priority
+// order: the selected variant's overlay directory, then the deck itself
+func (d *Deck) AssetRoots() []string {
+	variant, ok := d.config.Variants[d.Variant]
+	if !ok || variant.Directory == "" {
+		return []string{d.Path}
+	}
+
+	overlay, err := SafeJoin(d.Path, variant.Directory)
+	if err != nil {
+		return []string{d.Path}
+	}
+	return []string{overlay, d.Path}
+}
+
+// Cards returns every card in the deck in standard deck order
+func (d *Deck) Cards() []*card.Card {
+	cards := make([]*card.Card, 0, canon.DeckSize)
+	for _, c := range d.MajorArcana {
+		cards = append(cards, c)
+	}
+	for _, suitMap := range d.MinorArcana {
+		for _, c := range suitMap {
+			cards = append(cards, c)
+		}
+	}
+
+	sort.Slice(cards, func(i, j int) bool {
+		return cards[i].Index < cards[j].Index
+	})
+
+	return cards
 }
 
 // Helper functions
@@ -270,53 +439,13 @@ func splitCardID(cardID string) []string {
 
 // getDefaultMajorArcanaName returns the default name for a major arcana card
 func getDefaultMajorArcanaName(number string) string {
-	names := map[string]string{
-		"00": "The Fool",
-		"01": "The Magician",
-		"02": "The High Priestess",
-		"03": "The Empress",
-		"04": "The Emperor",
-		"05": "The Hierophant",
-		"06": "The Lovers",
-		"07": "The Chariot",
-		"08": "Strength",
-		"09": "The Hermit",
-		"10": "Wheel of Fortune",
-		"11": "Justice",
-		"12": "The Hanged Man",
-		"13": "Death",
-		"14": "Temperance",
-		"15": "The Devil",
-		"16": "The Tower",
-		"17": "The Star",
-		"18": "The Moon",
-		"19": "The Sun",
-		"20": "Judgement",
-		"21": "The World",
-	}
-
-	if name, ok := names[number]; ok {
-		return name
-	}
-
-	return fmt.Sprintf("Major Arcana %s", number)
+	return card.MajorArcanaName(number)
 }
 
-// getDefaultMinorArcanaName returns the default name for a minor arcana card
-func getDefaultMinorArcanaName(rank, suit string) string {
-	// Capitalize the first letter of suit
-	capitalizedSuit := strings.ToUpper(suit[:1]) + suit[1:]
-
-	// Get rank name
-	var rankName string
-	switch rank {
-	case "ace", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten":
-		rankName = strings.Title(rank)
-	default:
-		rankName = strings.Title(rank)
-	}
-
-	return fmt.Sprintf("%s of %s", rankName, capitalizedSuit)
+// defaultMinorName returns the default name for a minor arcana card, in the
+// deck's own suit and court names when it declares them in [aliases]
+func (d *Deck) defaultMinorName(rank, suit string) string {
+	return card.MinorArcanaName(d.config.RankName(rank), d.config.SuitAlias(suit))
 }
 
 // Deck configuration structures
@@ -327,6 +456,9 @@ type DeckConfig struct {
 	RemapMajorArcana map[string]string         `toml:"remap_major_arcana"`
 	CustomCards      *CustomCardSection        `toml:"custom_cards"`
 	Variants         map[string]VariantSection `toml:"variants"`
+	Style            *StyleSection             `toml:"style"`
+	Credits          *CreditsSection           `toml:"credits"`
+	ContentWarnings  *ContentWarningSection    `toml:"content_warnings"`
 }
 
 type DeckSection struct {
@@ -343,6 +475,7 @@ type DeckSection struct {
 	UpdatedDate   string               `toml:"updated_date"`
 	Publisher     string               `toml:"publisher"`
 	Website       string               `toml:"website"`
+	CardURL       string               `toml:"card_url"`
 	Tags          []string             `toml:"tags"`
 	ExcludedCards *ExcludedCardSection `toml:"excluded_cards"`
 }
@@ -390,6 +523,7 @@ type CustomMinorArcanaSection struct {
 type VariantSection struct {
 	ID          string `toml:"id"`
 	Name        string `toml:"name"`
+	Directory   string `toml:"directory"` // Overlay directory with images that replace the base deck's
 	CardBack    string `toml:"card_back"`
 	Publisher   string `toml:"publisher"`
 	CreatedDate string `toml:"created_date"`
@@ -400,6 +534,13 @@ type NameConfig struct {
 	Metadata    *MetadataSection             `toml:"metadata"`
 	MajorArcana map[string]string            `toml:"major_arcana"`
 	MinorArcana map[string]map[string]string `toml:"minor_arcana"`
+	AltText     *AltTextSection              `toml:"alt_text"`
+}
+
+// AltTextSection holds card descriptions, keyed like the name tables
+type AltTextSection struct {
+	MajorArcana map[string]string            `toml:"major_arcana"`
+	MinorArcana map[string]map[string]string `toml:"minor_arcana"`
 }
 
 type MetadataSection struct {
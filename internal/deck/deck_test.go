@@ -0,0 +1,109 @@
+package deck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+// writeFullNames writes a language file naming every card with its default
+// name, as most English decks do
+func writeFullNames(tb testing.TB, deckPath, lang string) {
+	tb.Helper()
+
+	var b strings.Builder
+	b.WriteString("[major_arcana]\n")
+	for i := 0; i <= 21; i++ {
+		fmt.Fprintf(&b, "%q = %q\n", fmt.Sprintf("%02d", i), card.MajorArcanaName(fmt.Sprintf("%02d", i)))
+	}
+	for _, suit := range []string{"wands", "cups", "swords", "pentacles"} {
+		fmt.Fprintf(&b, "\n[minor_arcana.%s]\n", suit)
+		for _, rank := range []string{"ace", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "page", "knight", "queen", "king"} {
+			fmt.Fprintf(&b, "%s = %q\n", rank, card.MinorArcanaName(rank, suit))
+		}
+	}
+	if err := os.WriteFile(filepath.Join(deckPath, "names", lang+".toml"), []byte(b.String()), 0644); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestLoadDeckSharesDefaultNames(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	writeFullNames(t, root, "en")
+
+	a, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, c := range a.Cards() {
+		other := b.Cards()[i]
+		if c == other {
+			t.Fatalf("%s: decks share a mutable card", c.ID)
+		}
+		if c.Name != card.DefaultName(c.ID) {
+			t.Errorf("%s: name = %q, want %q", c.ID, c.Name, card.DefaultName(c.ID))
+		}
+		if !sameString(c.ID, other.ID) || !sameString(c.Name, other.Name) {
+			t.Errorf("%s: ID and default name are not shared between decks", c.ID)
+		}
+	}
+}
+
+// sameString reports whether two strings share their backing memory
+func sameString(a, b string) bool {
+	return len(a) == len(b) && (len(a) == 0 || unsafe.StringData(a) == unsafe.StringData(b))
+}
+
+// BenchmarkLoadLibrary loads a library of 100 English and French decks, as
+// serve does once every deck has been requested, and reports the heap each
+// deck keeps alive
+func BenchmarkLoadLibrary(b *testing.B) {
+	const size = 100
+	paths := make([]string, size)
+	for i := range paths {
+		paths[i] = testutil.FixtureDeck(b)
+		writeFullNames(b, paths[i], "en")
+		writeFullNames(b, paths[i], "fr")
+	}
+
+	var retained uint64
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		library := make([]*Deck, size)
+		names := make([]map[string]map[string]string, size)
+		for i, path := range paths {
+			d, err := LoadDeck(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			library[i] = d
+			if names[i], err = d.LocalizedNames(); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		retained += after.HeapAlloc - before.HeapAlloc
+		runtime.KeepAlive(library)
+		runtime.KeepAlive(names)
+	}
+	b.ReportMetric(float64(retained)/float64(b.N*size), "retained-B/deck")
+}
@@ -0,0 +1,45 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Kinds of deck errors, matched with errors.Is. Errors returned by this
+// package keep their descriptive messages and wrap one of these, along with
+// the underlying cause where there is one.
+var (
+	ErrDeckNotFound    = errors.New("deck not found")         // No deck.toml at the path, or no library deck by that name
+	ErrInvalidDeck     = errors.New("invalid deck")           // deck.toml cannot be parsed or exceeds limits
+	ErrNoNames         = errors.New("card names unavailable") // A language file cannot be parsed
+	ErrInvalidCardID   = card.ErrInvalidCardID                // A card ID in no accepted notation
+	ErrCardNotFound    = errors.New("card not found")         // A valid card ID the deck does not have
+	ErrNoCardBack      = errors.New("no card back found")     // The deck has no card back image
+	ErrVariantNotFound = errors.New("variant not found")      // No variant with that key or ID
+)
+
+// Error is a deck error of a kind, with the message shown to users
+type Error struct {
+	Kind error // One of the Err* kinds
+	Err  error // Underlying cause, if any
+	msg  string
+}
+
+// newError returns an error of a kind with a formatted message, wrapping cause
+func newError(kind, cause error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Err: cause, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the kind and the cause, so errors.Is matches either
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
@@ -0,0 +1,73 @@
+package deck
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+func TestLoadDeckErrorKinds(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(root string) error
+		kind  error
+	}{
+		{"missing deck.toml", func(root string) error {
+			return os.Remove(filepath.Join(root, "deck.toml"))
+		}, ErrDeckNotFound},
+		{"unparseable deck.toml", func(root string) error {
+			return os.WriteFile(filepath.Join(root, "deck.toml"), []byte("[deck\n"), 0644)
+		}, ErrInvalidDeck},
+		{"path outside the deck", func(root string) error {
+			return os.WriteFile(filepath.Join(root, "deck.toml"), []byte("[deck]\nicon = \"../icon.png\"\n"), 0644)
+		}, ErrInvalidDeck},
+		{"unparseable names", func(root string) error {
+			return os.WriteFile(filepath.Join(root, "names", "en.toml"), []byte("[major_arcana\n"), 0644)
+		}, ErrNoNames},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testutil.FixtureDeck(t)
+			if err := tt.setup(root); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadDeck(root)
+			if !errors.Is(err, tt.kind) {
+				t.Errorf("LoadDeck error = %v, want kind %v", err, tt.kind)
+			}
+		})
+	}
+}
+
+func TestLoadDeckErrorKeepsCause(t *testing.T) {
+	_, err := LoadDeck(t.TempDir())
+	if !errors.Is(err, ErrDeckNotFound) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadDeck error = %v, want ErrDeckNotFound wrapping fs.ErrNotExist", err)
+	}
+}
+
+func TestDeckLookupErrorKinds(t *testing.T) {
+	d, err := LoadDeck(testutil.FixtureDeck(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.GetCard("the-moon"); !errors.Is(err, ErrInvalidCardID) {
+		t.Errorf("GetCard(the-moon) error = %v, want ErrInvalidCardID", err)
+	}
+	if _, err := d.GetCard("cups.quen"); err == nil || !strings.Contains(err.Error(), "did you mean minor_arcana.cups.queen?") {
+		t.Errorf("GetCard(cups.quen) error = %v, want a suggestion of minor_arcana.cups.queen", err)
+	}
+	if _, err := d.GetCard("custom_cards.major_arcana.x"); !errors.Is(err, ErrCardNotFound) {
+		t.Errorf("GetCard(custom card) error = %v, want ErrCardNotFound", err)
+	}
+	if err := d.UseVariant("missing"); !errors.Is(err, ErrVariantNotFound) {
+		t.Errorf("UseVariant error = %v, want ErrVariantNotFound", err)
+	}
+}
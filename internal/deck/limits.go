@@ -0,0 +1,192 @@
+package deck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Limits applied when loading decks, which may come from untrusted sources
+const (
+	MaxTomlSize         = 1 << 20 // Maximum size of deck.toml and language files in bytes
+	MaxCustomCards      = 256     // Maximum number of custom cards across all sections
+	MaxCardBackVariants = 64      // Maximum number of card back variants
+	MaxVariants         = 64      // Maximum number of deck variants
+)
+
+// DecodeTomlFile decodes a TOML file into v after checking it does not exceed MaxTomlSize
+func DecodeTomlFile(path string, v interface{}) (toml.MetaData, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return toml.MetaData{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return toml.MetaData{}, fmt.Errorf("%s is not a regular file", filepath.Base(path))
+	}
+	if info.Size() > MaxTomlSize {
+		return toml.MetaData{}, fmt.Errorf("%s is too large (%d bytes, limit %d)", filepath.Base(path), info.Size(), MaxTomlSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return toml.MetaData{}, err
+	}
+	return toml.Decode(string(data), v)
+}
+
+// SafeJoin joins a deck-relative path onto root, rejecting absolute paths and
+// references that escape the deck root directly or through symlinks
+func SafeJoin(root, rel string) (string, error) {
+	if rel == "" {
+		return "", fmt.Errorf("empty path")
+	}
+	if filepath.IsAbs(rel) || strings.HasPrefix(rel, "/") || strings.HasPrefix(rel, `\`) || filepath.VolumeName(rel) != "" {
+		return "", fmt.Errorf("absolute path not allowed: %s", rel)
+	}
+
+	cleaned := filepath.Clean(filepath.FromSlash(rel))
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path escapes deck root: %s", rel)
+	}
+
+	path := filepath.Join(root, cleaned)
+
+	// Resolve symlinks for files that exist so links cannot point outside the deck
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		return "", fmt.Errorf("error resolving %s: %v", rel, err)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("error resolving deck root: %v", err)
+	}
+	if !withinDir(resolvedRoot, resolved) {
+		return "", fmt.Errorf("path escapes deck root via symlink: %s", rel)
+	}
+
+	return path, nil
+}
+
+// Contains reports whether path is the deck root or located beneath it, with
+// symlinks resolved in the parts of both paths that exist, so destinations
+// that are yet to be created can be checked too
+func Contains(root, path string) bool {
+	return withinDir(resolveExisting(root), resolveExisting(path))
+}
+
+// resolveExisting returns the absolute form of p with symlinks resolved in
+// its longest existing prefix
+func resolveExisting(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	rest := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		if filepath.Dir(dir) == dir {
+			return abs
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
+
+// withinDir reports whether path is dir or located beneath it
+func withinDir(dir, path string) bool {
+	relPath, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) && !filepath.IsAbs(relPath)
+}
+
+// CheckID checks that a deck ID can be used as a file or directory name, as
+// it is for the deck library, study progress and notes: a single path element
+// that is not . or .. and holds no separators. An empty ID passes.
+func CheckID(id string) error {
+	if id == "" {
+		return nil
+	}
+	if id == "." || id == ".." || strings.ContainsAny(id, "/\\\x00") || filepath.Base(id) != id {
+		return fmt.Errorf("%q cannot be used as a file name", id)
+	}
+	return nil
+}
+
+// CheckLimits validates a decoded deck.toml against the loader limits and
+// ensures every referenced file stays inside the deck root
+func CheckLimits(deckPath string, config *DeckConfig) []error {
+	var errs []error
+
+	if err := CheckID(config.Deck.ID); err != nil {
+		errs = append(errs, fmt.Errorf("deck.id: %v", err))
+	}
+
+	if backs := config.CardBacks; backs != nil {
+		if len(backs.Variants) > MaxCardBackVariants {
+			errs = append(errs, fmt.Errorf("too many card back variants: %d (limit %d)", len(backs.Variants), MaxCardBackVariants))
+		}
+		for name, variant := range backs.Variants {
+			if variant.Image == "" {
+				continue
+			}
+			if _, err := SafeJoin(deckPath, variant.Image); err != nil {
+				errs = append(errs, fmt.Errorf("card_backs.variants.%s.image: %v", name, err))
+			}
+		}
+	}
+
+	if len(config.Variants) > MaxVariants {
+		errs = append(errs, fmt.Errorf("too many variants: %d (limit %d)", len(config.Variants), MaxVariants))
+	}
+	for name, variant := range config.Variants {
+		if variant.Directory == "" {
+			continue
+		}
+		if _, err := SafeJoin(deckPath, variant.Directory); err != nil {
+			errs = append(errs, fmt.Errorf("variants.%s.directory: %v", name, err))
+		}
+	}
+
+	if config.Deck.Icon != "" {
+		if _, err := SafeJoin(deckPath, config.Deck.Icon); err != nil {
+			errs = append(errs, fmt.Errorf("deck.icon: %v", err))
+		}
+	}
+
+	if custom := config.CustomCards; custom != nil {
+		count := len(custom.MajorArcana)
+		for key, c := range custom.MajorArcana {
+			if c.Image == "" {
+				continue
+			}
+			if _, err := SafeJoin(deckPath, c.Image); err != nil {
+				errs = append(errs, fmt.Errorf("custom_cards.major_arcana.%s.image: %v", key, err))
+			}
+		}
+		for suit, section := range custom.MinorArcana {
+			count += len(section.Cards)
+			for _, c := range section.Cards {
+				if c.Image == "" {
+					continue
+				}
+				if _, err := SafeJoin(deckPath, c.Image); err != nil {
+					errs = append(errs, fmt.Errorf("custom_cards.minor_arcana.%s.%s.image: %v", suit, c.ID, err))
+				}
+			}
+		}
+		if count > MaxCustomCards {
+			errs = append(errs, fmt.Errorf("too many custom cards: %d (limit %d)", count, MaxCustomCards))
+		}
+	}
+
+	return errs
+}
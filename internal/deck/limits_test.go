@@ -0,0 +1,133 @@
+package deck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+func TestSafeJoin(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	if err := os.Symlink("/etc", filepath.Join(root, "escape")); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+
+	tests := []struct {
+		rel string
+		ok  bool
+	}{
+		{"card_backs/default.png", true},
+		{"card_backs/../card_backs/default.png", true},
+		{"missing.png", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../../etc/passwd", false},
+		{"card_backs/../../outside.png", false},
+		{"escape/passwd", false},
+	}
+	for _, tt := range tests {
+		_, err := SafeJoin(root, tt.rel)
+		if (err == nil) != tt.ok {
+			t.Errorf("SafeJoin(%q) error = %v, want ok %t", tt.rel, err, tt.ok)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	outside := t.TempDir()
+	link := filepath.Join(outside, "link")
+	if err := os.Symlink(root, link); err != nil {
+		t.Fatalf("creating symlink: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{root, true},
+		{filepath.Join(root, "names"), true},
+		{filepath.Join(root, "not", "yet", "created"), true},
+		{filepath.Join(link, "copy"), true},
+		{filepath.Join(root, ".."), false},
+		{filepath.Join(outside, "copy"), false},
+	}
+	for _, tt := range tests {
+		if got := Contains(root, tt.path); got != tt.want {
+			t.Errorf("Contains(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCheckID(t *testing.T) {
+	tests := []struct {
+		id string
+		ok bool
+	}{
+		{"", true},
+		{"rider-waite-smith", true},
+		{"thoth_1944.v2", true},
+		{".", false},
+		{"..", false},
+		{"../../x", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{"a\x00b", false},
+	}
+	for _, tt := range tests {
+		if err := CheckID(tt.id); (err == nil) != tt.ok {
+			t.Errorf("CheckID(%q) error = %v, want ok %t", tt.id, err, tt.ok)
+		}
+	}
+}
+
+func TestLoadDeckRejectsEscapingID(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	data, err := os.ReadFile(filepath.Join(root, "deck.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), `id = "fixture-deck"`, `id = "../../x"`, 1))
+	if err := os.WriteFile(filepath.Join(root, "deck.toml"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDeck(root); err == nil {
+		t.Fatal("expected a deck id escaping the directory to be rejected")
+	}
+}
+
+func TestLoadDeckRejectsOversizedToml(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	big := make([]byte, MaxTomlSize+1)
+	for i := range big {
+		big[i] = '#'
+	}
+	if err := os.WriteFile(filepath.Join(root, "deck.toml"), big, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDeck(root); err == nil {
+		t.Fatal("expected oversized deck.toml to be rejected")
+	}
+}
+
+func FuzzLoadDeck(f *testing.F) {
+	f.Add([]byte("[deck]\nid = \"x\"\n[card_backs.variants.a]\nimage = \"../a.png\"\n"))
+	f.Add([]byte("[custom_cards.major_arcana.x]\nimage = \"/abs.png\"\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		root := testutil.FixtureDeck(t)
+		if err := os.WriteFile(filepath.Join(root, "deck.toml"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		d, err := LoadDeck(root)
+		if err != nil {
+			return
+		}
+		if path, err := d.CardBackPath(); err == nil && !withinDir(root, path) {
+			t.Fatalf("card back path %s escapes deck root", path)
+		}
+	})
+}
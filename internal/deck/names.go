@@ -0,0 +1,140 @@
+package deck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/suggest"
+)
+
+// CheckNames reports keys in the names files that are not cards of the deck,
+// which the loader would otherwise ignore: major arcana keys that are not
+// numbers "00" to "21", unknown suits and unknown ranks under a suit, in both
+// the name and alt text tables. Each problem suggests the key most likely
+// meant.
+func (d *Deck) CheckNames() []error {
+	dir := filepath.Join(d.Path, "names")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil // The names directory is optional
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+		var langConfig NameConfig
+		if _, err := DecodeTomlFile(filepath.Join(dir, entry.Name()), &langConfig); err != nil {
+			continue // Reported when the names are validated
+		}
+
+		report := func(err error) {
+			errs = append(errs, fmt.Errorf("%s: %v", entry.Name(), err))
+		}
+		for _, err := range d.checkNameTables("", langConfig.MajorArcana, langConfig.MinorArcana) {
+			report(err)
+		}
+		if langConfig.AltText != nil {
+			for _, err := range d.checkNameTables("alt_text.", langConfig.AltText.MajorArcana, langConfig.AltText.MinorArcana) {
+				report(err)
+			}
+		}
+	}
+	return errs
+}
+
+// checkNameTables checks the keys of a pair of major and minor arcana tables
+func (d *Deck) checkNameTables(prefix string, major map[string]string, minor map[string]map[string]string) []error {
+	var errs []error
+	for _, num := range sortedKeys(major) {
+		if _, ok := d.MajorArcana[num]; !ok {
+			errs = append(errs, withKeySuggestion(
+				fmt.Errorf("%smajor_arcana.%q is not a major arcana number", prefix, num), d.suggestMajor(num)))
+		}
+	}
+
+	for _, suit := range sortedKeys(minor) {
+		cards, ok := d.MinorArcana[suit]
+		if !ok {
+			errs = append(errs, withKeySuggestion(
+				fmt.Errorf("%sminor_arcana.%s is not a suit of this deck", prefix, suit), d.suggestSuit(suit)))
+			continue
+		}
+		for _, rank := range sortedKeys(minor[suit]) {
+			if _, ok := cards[rank]; !ok {
+				errs = append(errs, withKeySuggestion(
+					fmt.Errorf("%sminor_arcana.%s.%s is not a card of this deck", prefix, suit, rank), d.suggestRank(suit, rank)))
+			}
+		}
+	}
+	return errs
+}
+
+// suggestMajor returns the major arcana number a key most likely meant, from
+// an unpadded or Roman number or the name of the card
+func (d *Deck) suggestMajor(key string) string {
+	id, err := card.ParseID(key)
+	if err != nil {
+		id = card.SuggestID(key)
+	}
+	if num, ok := strings.CutPrefix(id, "major_arcana."); ok {
+		if _, ok := d.MajorArcana[num]; ok {
+			return num
+		}
+	}
+	return ""
+}
+
+// suggestSuit returns the suit a key most likely meant, from the deck's own
+// name for a suit or a near spelling
+func (d *Deck) suggestSuit(key string) string {
+	for _, suit := range d.Suits() {
+		if d.config.SuitAlias(suit) == key {
+			return suit
+		}
+	}
+	return suggest.Closest(key, d.Suits())
+}
+
+// suggestRank returns the rank of a suit a key most likely meant, from a
+// number, the deck's own court names, a traditional court name such as knave
+// or a near spelling
+func (d *Deck) suggestRank(suit, key string) string {
+	candidates := []string{d.config.resolveAliases(suit + "." + key)}
+	if id, err := card.ParseID(suit + "." + key); err == nil {
+		candidates = append(candidates, id)
+	}
+	if rank, ok := card.RankAlias(key); ok {
+		candidates = append(candidates, suit+"."+rank)
+	}
+	for _, candidate := range candidates {
+		parts := splitCardID(candidate)
+		if _, ok := d.MinorArcana[suit][parts[len(parts)-1]]; ok && len(parts) > 1 {
+			return parts[len(parts)-1]
+		}
+	}
+	return suggest.Closest(key, sortedKeys(d.MinorArcana[suit]))
+}
+
+// withKeySuggestion adds a suggested key to an error, when there is one
+func withKeySuggestion(err error, suggestion string) error {
+	if suggestion == "" {
+		return err
+	}
+	return fmt.Errorf("%v (did you mean %q?)", err, suggestion)
+}
+
+// sortedKeys returns the keys of a map in order, for stable messages
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+package deck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+func TestCheckNames(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	appendDeckToml(t, root, `
+[aliases.suits]
+pentacles = "disks"
+`)
+	content := `
+[major_arcana]
+"00" = "The Fool"
+"7" = "The Chariot"
+"judgment" = "Judgement"
+"XVII" = "The Star"
+
+[minor_arcana.cups]
+queen = "Queen of Cups"
+knave = "Knave of Cups"
+quen = "Queen of Cups"
+
+[minor_arcana.disks]
+ace = "Ace of Disks"
+
+[minor_arcana.wans]
+ace = "Ace of Wands"
+
+[alt_text.major_arcana]
+"22" = "Beyond the deck"
+`
+	if err := os.WriteFile(filepath.Join(root, "names", "fr.toml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`fr.toml: major_arcana."7" is not a major arcana number (did you mean "07"?)`,
+		`fr.toml: major_arcana."XVII" is not a major arcana number (did you mean "17"?)`,
+		`fr.toml: major_arcana."judgment" is not a major arcana number (did you mean "20"?)`,
+		`fr.toml: minor_arcana.cups.knave is not a card of this deck (did you mean "page"?)`,
+		`fr.toml: minor_arcana.cups.quen is not a card of this deck (did you mean "queen"?)`,
+		`fr.toml: minor_arcana.disks is not a suit of this deck (did you mean "pentacles"?)`,
+		`fr.toml: minor_arcana.wans is not a suit of this deck (did you mean "wands"?)`,
+		`fr.toml: alt_text.major_arcana."22" is not a major arcana number`,
+	}
+	errs := d.CheckNames()
+	if len(errs) != len(want) {
+		t.Fatalf("got %d problems %v, want %d", len(errs), errs, len(want))
+	}
+	for i, w := range want {
+		if errs[i].Error() != w {
+			t.Errorf("problem %d = %v, want %s", i, errs[i], w)
+		}
+	}
+}
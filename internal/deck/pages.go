@@ -0,0 +1,82 @@
+package deck
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// cardURLPlaceholder matches the placeholders of a card_url pattern
+var cardURLPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// cardURLFields are the placeholders card_url patterns may use, filled in
+// for each card
+var cardURLFields = map[string]func(c *card.Card) string{
+	"id":     func(c *card.Card) string { return c.ID },
+	"slug":   cardSlug,
+	"number": func(c *card.Card) string { return c.Number },
+	"suit":   func(c *card.Card) string { return c.Suit },
+	"rank":   func(c *card.Card) string { return c.Rank },
+}
+
+// CardPage returns the address of a card's page on the deck's website, from
+// the card_url pattern in deck.toml, such as
+// "https://example.com/cards/{slug}". The pattern may use {id}, {slug} (the
+// card name in lowercase with hyphens), {number} (major arcana), {suit} and
+// {rank} (minor arcana). It returns false for decks without a pattern.
+func (d *Deck) CardPage(c *card.Card) (string, bool) {
+	if d.CardURL == "" {
+		return "", false
+	}
+	return cardURLPlaceholder.ReplaceAllStringFunc(d.CardURL, func(placeholder string) string {
+		field := cardURLFields[strings.Trim(placeholder, "{}")]
+		if field == nil {
+			return placeholder
+		}
+		return url.PathEscape(field(c))
+	}), true
+}
+
+// cardSlug returns the name of a card in lowercase, with runs of other
+// characters than letters and digits replaced by a hyphen
+func cardSlug(c *card.Card) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(c.Name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// CheckCardURL reports a card_url pattern that is not an http or https
+// address or uses unknown placeholders
+func CheckCardURL(config *DeckConfig) []error {
+	pattern := config.Deck.CardURL
+	if pattern == "" {
+		return nil
+	}
+
+	var errs []error
+	for _, placeholder := range cardURLPlaceholder.FindAllString(pattern, -1) {
+		if cardURLFields[strings.Trim(placeholder, "{}")] == nil {
+			errs = append(errs, fmt.Errorf("deck.card_url: unknown placeholder %s (supported: {id}, {slug}, {number}, {suit}, {rank})", placeholder))
+		}
+	}
+	u, err := url.Parse(cardURLPlaceholder.ReplaceAllString(pattern, "x"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("deck.card_url: must be an http or https address"))
+	}
+	return errs
+}
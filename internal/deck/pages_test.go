@@ -0,0 +1,63 @@
+package deck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+func TestCardPage(t *testing.T) {
+	queen := card.NewMinorArcana("cups", "queen")
+	queen.Name = "Queen of Cups"
+	fool := card.NewMajorArcana(0)
+	fool.Name = "The Fool (Le Mat)"
+
+	tests := []struct {
+		pattern string
+		card    *card.Card
+		want    string
+	}{
+		{"https://example.com/cards/{slug}", queen, "https://example.com/cards/queen-of-cups"},
+		{"https://example.com/cards/{slug}", fool, "https://example.com/cards/the-fool-le-mat"},
+		{"https://example.com/{suit}/{rank}", queen, "https://example.com/cups/queen"},
+		{"https://example.com/major/{number}?id={id}", fool, "https://example.com/major/00?id=major_arcana.00"},
+	}
+	for _, tt := range tests {
+		d := &Deck{CardURL: tt.pattern}
+		if got, ok := d.CardPage(tt.card); !ok || got != tt.want {
+			t.Errorf("CardPage(%s) with %s = %q, %v, want %q", tt.card.ID, tt.pattern, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := (&Deck{}).CardPage(queen); ok {
+		t.Error("CardPage without a card_url pattern succeeded")
+	}
+}
+
+func TestCheckCardURL(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string // Substring of the first error, empty for none
+	}{
+		{"", ""},
+		{"https://example.com/cards/{slug}", ""},
+		{"http://example.com/{suit}/{rank}.html", ""},
+		{"https://example.com/cards/{name}", "unknown placeholder {name}"},
+		{"ftp://example.com/{id}", "must be an http or https address"},
+		{"/cards/{id}", "must be an http or https address"},
+	}
+	for _, tt := range tests {
+		var config DeckConfig
+		config.Deck.CardURL = tt.pattern
+		errs := CheckCardURL(&config)
+		switch {
+		case tt.want == "" && len(errs) > 0:
+			t.Errorf("%q: unexpected error %v", tt.pattern, errs[0])
+		case tt.want != "" && len(errs) == 0:
+			t.Errorf("%q: no error, want %q", tt.pattern, tt.want)
+		case tt.want != "" && !strings.Contains(errs[0].Error(), tt.want):
+			t.Errorf("%q: error %v, want %q", tt.pattern, errs[0], tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package deck
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// ReadingsConfig is a readings/<lang>.toml file, in which a deck's author
+// gives their own interpretations of the cards and suggests spreads suited
+// to the deck:
+//
+//	[spreads]
+//	celtic-cross = "The court cards read best in the crossing position"
+//
+//	[cards."00"]
+//	keywords = ["beginnings", "spontaneity", "faith"]
+//	upright = "A leap taken with open eyes"
+//	reversed = "Hesitation at the edge"
+//
+// Card keys may use any notation accepted by GetCard.
+type ReadingsConfig struct {
+	Spreads map[string]string         `toml:"spreads"` // Spread IDs with the author's note on using them
+	Cards   map[string]Interpretation `toml:"cards"`
+}
+
+// Interpretation is the author's meaning of a card in each orientation, with
+// keywords summing it up
+type Interpretation struct {
+	Keywords []string `toml:"keywords"`
+	Upright  string   `toml:"upright"`
+	Reversed string   `toml:"reversed"`
+}
+
+// Readings is a deck's loaded readings file, with cards keyed by canonical ID
+type Readings struct {
+	Lang    string
+	Spreads map[string]string
+	cards   map[string]Interpretation
+}
+
+// Readings loads the deck's readings in English, or the first language
+// provided when there is no English file. It returns nil without an error
+// when the deck has no readings directory.
+func (d *Deck) Readings() (*Readings, error) {
+	dir := filepath.Join(d.Path, "readings")
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading readings directory: %v", err)
+	}
+
+	var langs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".toml" {
+			langs = append(langs, strings.TrimSuffix(entry.Name(), ".toml"))
+		}
+	}
+	if len(langs) == 0 {
+		return nil, nil
+	}
+
+	lang := langs[0]
+	if contains(langs, "en") {
+		lang = "en"
+	}
+	r, _, err := d.LoadReadings(filepath.Join(dir, lang+".toml"))
+	return r, err
+}
+
+// LoadReadings reads a readings file. Cards that are not in the deck are left
+// out of the readings and reported, along with entries giving no meaning, as
+// problems for validation to show.
+func (d *Deck) LoadReadings(path string) (*Readings, []error, error) {
+	var config ReadingsConfig
+	md, err := DecodeTomlFile(path, &config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing readings file %s: %v", filepath.Base(path), err)
+	}
+
+	r := &Readings{
+		Lang:    strings.TrimSuffix(filepath.Base(path), ".toml"),
+		Spreads: config.Spreads,
+		cards:   make(map[string]Interpretation, len(config.Cards)),
+	}
+
+	var problems []error
+	for _, key := range md.Undecoded() {
+		problems = append(problems, fmt.Errorf("unknown key %s", key))
+	}
+
+	keys := make([]string, 0, len(config.Cards))
+	for key := range config.Cards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		meaning := config.Cards[key]
+		c, err := d.GetCard(key)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("cards.%s: not a card of this deck", key))
+			continue
+		}
+		if _, ok := r.cards[c.ID]; ok {
+			problems = append(problems, fmt.Errorf("cards.%s: %s is interpreted more than once", key, c.ID))
+		}
+		if strings.TrimSpace(meaning.Upright) == "" && strings.TrimSpace(meaning.Reversed) == "" && len(meaning.Keywords) == 0 {
+			problems = append(problems, fmt.Errorf("cards.%s: no upright or reversed meaning", key))
+		}
+		r.cards[c.ID] = meaning
+	}
+
+	return r, problems, nil
+}
+
+// For returns the author's interpretation of a card. Readings may be nil.
+func (r *Readings) For(c *card.Card) (Interpretation, bool) {
+	if r == nil {
+		return Interpretation{}, false
+	}
+	meaning, ok := r.cards[c.ID]
+	return meaning, ok
+}
+
+// SpreadNote returns the author's note on a spread they suggest for the deck.
+// Readings may be nil.
+func (r *Readings) SpreadNote(spreadID string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	note, ok := r.Spreads[spreadID]
+	return note, ok
+}
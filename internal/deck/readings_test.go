@@ -0,0 +1,119 @@
+package deck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+func TestReadings(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r, err := d.Readings(); err != nil || r != nil {
+		t.Fatalf("Readings() without a readings directory = %v, %v, want nil", r, err)
+	}
+
+	dir := filepath.Join(root, "readings")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"de.toml": "[cards.\"00\"]\nupright = \"Ein Sprung\"\n",
+		"en.toml": `
+[spreads]
+three-card = "Read the middle card first"
+
+[cards."00"]
+upright = "A leap taken with open eyes"
+reversed = "Hesitation at the edge"
+
+[cards."cups/queen"]
+upright = "Tenderness that listens"
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := d.Readings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Lang != "en" {
+		t.Errorf("Readings() loaded %s, want en to be preferred", r.Lang)
+	}
+
+	fool, _ := d.GetCard("00")
+	if meaning, ok := r.For(fool); !ok || meaning.Reversed != "Hesitation at the edge" {
+		t.Errorf("For(The Fool) = %+v, %v", meaning, ok)
+	}
+	queen, _ := d.GetCard("minor_arcana.cups.queen")
+	if meaning, ok := r.For(queen); !ok || meaning.Upright != "Tenderness that listens" {
+		t.Errorf("For(Queen of Cups) = %+v, %v", meaning, ok)
+	}
+	if note, ok := r.SpreadNote("three-card"); !ok || note != "Read the middle card first" {
+		t.Errorf("SpreadNote(three-card) = %q, %v", note, ok)
+	}
+
+	var none *Readings
+	if _, ok := none.For(fool); ok {
+		t.Error("For on nil readings found a meaning")
+	}
+}
+
+func TestLoadReadingsProblems(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(root, "en.toml")
+	content := `
+[cards."00"]
+upright = "A leap"
+[cards."major_arcana.00"]
+upright = "Again"
+[cards."stars.ace"]
+upright = "No such suit"
+[cards."wands.two"]
+colour = "red"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, problems, err := d.LoadReadings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`unknown key cards."wands.two".colour`,
+		"interpreted more than once",
+		"cards.stars.ace: not a card of this deck",
+		"cards.wands.two: no upright or reversed meaning",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("got %d problems %v, want %d", len(problems), problems, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(problems[i].Error(), w) {
+			t.Errorf("problem %d = %v, want %q", i, problems[i], w)
+		}
+	}
+
+	fool, _ := d.GetCard("00")
+	if _, ok := r.For(fool); !ok {
+		t.Error("valid entries should still be loaded alongside problems")
+	}
+}
@@ -0,0 +1,114 @@
+package deck
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// StyleSection holds the [style] table of deck.toml. Values cascade from the
+// deck to the major arcana or a suit, and then to individual cards.
+type StyleSection struct {
+	StyleEntry
+	MajorArcana *StyleEntry           `toml:"major_arcana"`
+	Suits       map[string]StyleEntry `toml:"suits"`
+	Cards       map[string]StyleEntry `toml:"cards"` // Keyed by card ID
+}
+
+// StyleEntry declares an accent color and border style
+type StyleEntry struct {
+	Accent string `toml:"accent"` // Hex color such as "#c9a227"
+	Border string `toml:"border"` // Border style name such as "rounded"
+}
+
+// CardStyle is the resolved style for a card. Empty fields mean the renderer's default.
+type CardStyle struct {
+	Accent string
+	Border string
+}
+
+// merge overrides the style with the non-empty fields of an entry
+func (s *CardStyle) merge(e *StyleEntry) {
+	if e == nil {
+		return
+	}
+	if e.Accent != "" {
+		s.Accent = e.Accent
+	}
+	if e.Border != "" {
+		s.Border = e.Border
+	}
+}
+
+// StyleFor resolves the style for a card from the deck, group and card entries
+func (d *Deck) StyleFor(c *card.Card) CardStyle {
+	var style CardStyle
+
+	section := d.config.Style
+	if section == nil {
+		return style
+	}
+
+	style.merge(&section.StyleEntry)
+	if c.Type == "major_arcana" {
+		style.merge(section.MajorArcana)
+	} else if entry, ok := section.Suits[c.Suit]; ok {
+		style.merge(&entry)
+	}
+	for id, entry := range section.Cards {
+		// Card keys may use any notation accepted by card.ParseID
+		if canonical, err := card.ParseID(id); err == nil && canonical == c.ID {
+			style.merge(&entry)
+			break
+		}
+	}
+
+	return style
+}
+
+// AccentColor returns the style's accent color, reporting false when none is set or it is invalid
+func (s CardStyle) AccentColor() (color.RGBA, bool) {
+	if s.Accent == "" {
+		return color.RGBA{}, false
+	}
+	c, err := ParseHexColor(s.Accent)
+	return c, err == nil
+}
+
+// ParseHexColor parses a "#rgb" or "#rrggbb" color
+func ParseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if !strings.HasPrefix(s, "#") || (len(hex) != 3 && len(hex) != 6) {
+		return color.RGBA{}, fmt.Errorf("invalid color %q (expected #rgb or #rrggbb)", s)
+	}
+
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q (expected #rgb or #rrggbb)", s)
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}, nil
+}
+
+// StyleEntries returns every style entry in the section keyed by its TOML path,
+// for validation
+func (s *StyleSection) StyleEntries() map[string]StyleEntry {
+	entries := map[string]StyleEntry{"style": s.StyleEntry}
+	if s.MajorArcana != nil {
+		entries["style.major_arcana"] = *s.MajorArcana
+	}
+	for suit, entry := range s.Suits {
+		entries["style.suits."+suit] = entry
+	}
+	for id, entry := range s.Cards {
+		entries[fmt.Sprintf("style.cards.%q", id)] = entry
+	}
+	return entries
+}
@@ -0,0 +1,163 @@
+package deck
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/canon"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// customKeyPattern matches the suit keys and card IDs allowed in
+// [custom_cards.minor_arcana], which become path components of card art
+var customKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Suits returns the suits of the deck: the four standard suits in deck order,
+// then the custom suits declared in deck.toml sorted by key
+func (d *Deck) Suits() []string {
+	return append(canon.Suits(), d.customSuits()...)
+}
+
+// customSuits returns the keys of [custom_cards.minor_arcana], sorted
+func (d *Deck) customSuits() []string {
+	if d.config == nil || d.config.CustomCards == nil {
+		return nil
+	}
+	suits := make([]string, 0, len(d.config.CustomCards.MinorArcana))
+	for suit := range d.config.CustomCards.MinorArcana {
+		suits = append(suits, suit)
+	}
+	sort.Strings(suits)
+	return suits
+}
+
+// IsCustomSuit reports whether a suit is declared in [custom_cards.minor_arcana]
+func (d *Deck) IsCustomSuit(suit string) bool {
+	if d.config == nil || d.config.CustomCards == nil {
+		return false
+	}
+	_, ok := d.config.CustomCards.MinorArcana[suit]
+	return ok
+}
+
+// SuitName returns the display name of a suit: the name declared for a custom
+// suit, or the capitalized suit key or the deck's alias for it
+func (d *Deck) SuitName(suit string) string {
+	if d.IsCustomSuit(suit) {
+		if name := d.config.CustomCards.MinorArcana[suit].Name; name != "" {
+			return name
+		}
+	}
+	if d.config != nil {
+		suit = d.config.SuitAlias(suit)
+	}
+	if suit == "" {
+		return suit
+	}
+	return strings.ToUpper(suit[:1]) + suit[1:]
+}
+
+// SuitNames returns the display name of every suit of the deck, keyed by suit
+func (d *Deck) SuitNames() map[string]string {
+	names := make(map[string]string)
+	for _, suit := range d.Suits() {
+		names[suit] = d.SuitName(suit)
+	}
+	return names
+}
+
+// loadCustomSuits creates the cards of the custom minor arcana suits. They
+// follow the standard 78 cards in deck order, suit by suit, each suit's cards
+// ordered by position and then as listed.
+func (d *Deck) loadCustomSuits() {
+	index := canon.DeckSize
+	for _, suit := range d.customSuits() {
+		section := d.config.CustomCards.MinorArcana[suit]
+		cards := append([]CustomCard(nil), section.Cards...)
+		sort.SliceStable(cards, func(i, j int) bool { return cards[i].Position < cards[j].Position })
+
+		d.MinorArcana[suit] = make(map[string]*card.Card, len(cards))
+		for _, custom := range cards {
+			// Standard rank names keep their value, so aces and courts of a
+			// custom suit count as such in numerology and patterns
+			c := card.NewMinorArcana(suit, custom.ID)
+			c.ID = "custom_cards.minor_arcana." + suit + "." + custom.ID
+			c.Index = index
+			c.Name = custom.Name
+			c.AltText = custom.AltText
+			if c.Name == "" {
+				c.Name = card.DefaultName(c.ID)
+			}
+			d.MinorArcana[suit][custom.ID] = c
+			index++
+		}
+	}
+}
+
+// customSuitCard looks up a card of a custom suit by its canonical ID or the
+// shorter <suit>.<id> and minor_arcana.<suit>.<id> forms
+func (d *Deck) customSuitCard(id string) (*card.Card, bool) {
+	parts := splitCardID(strings.ToLower(id))
+	switch {
+	case len(parts) == 4 && parts[0] == "custom_cards" && parts[1] == "minor_arcana":
+		parts = parts[2:]
+	case len(parts) == 3 && parts[0] == "minor_arcana":
+		parts = parts[1:]
+	case len(parts) != 2:
+		return nil, false
+	}
+	if !d.IsCustomSuit(parts[0]) {
+		return nil, false
+	}
+	c, ok := d.MinorArcana[parts[0]][parts[1]]
+	return c, ok
+}
+
+// CheckCustomSuits reports problems with the custom suits declared in
+// [custom_cards.minor_arcana]: keys that are not valid path components or
+// that clash with a standard suit, suits without cards and missing or
+// duplicate card IDs
+func CheckCustomSuits(config *DeckConfig) []error {
+	if config.CustomCards == nil {
+		return nil
+	}
+
+	suits := make([]string, 0, len(config.CustomCards.MinorArcana))
+	for suit := range config.CustomCards.MinorArcana {
+		suits = append(suits, suit)
+	}
+	sort.Strings(suits)
+
+	var errs []error
+	for _, suit := range suits {
+		key := "custom_cards.minor_arcana." + suit
+		if !customKeyPattern.MatchString(suit) {
+			errs = append(errs, fmt.Errorf("%s: suit keys must be lowercase letters, digits, '_' and '-'", key))
+			continue
+		}
+		if _, err := card.ParseID(suit + ".ace"); err == nil {
+			errs = append(errs, fmt.Errorf("%s: clashes with the standard suit %s", key, suit))
+			continue
+		}
+
+		section := config.CustomCards.MinorArcana[suit]
+		if len(section.Cards) == 0 {
+			errs = append(errs, fmt.Errorf("%s: suit has no cards", key))
+		}
+		seen := make(map[string]bool, len(section.Cards))
+		for i, c := range section.Cards {
+			switch {
+			case c.ID == "":
+				errs = append(errs, fmt.Errorf("%s: card %d has no id", key, i+1))
+			case !customKeyPattern.MatchString(c.ID):
+				errs = append(errs, fmt.Errorf("%s.%s: card IDs must be lowercase letters, digits, '_' and '-'", key, c.ID))
+			case seen[c.ID]:
+				errs = append(errs, fmt.Errorf("%s.%s: duplicate card id", key, c.ID))
+			}
+			seen[c.ID] = true
+		}
+	}
+	return errs
+}
@@ -0,0 +1,104 @@
+package deck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+const starsSuit = `
+[custom_cards.minor_arcana.stars]
+name = "Stars"
+cards = [
+  { id = "queen", name = "Queen of Stars", position = 2 },
+  { id = "ace", alt_text = "A single star", position = 1 },
+  { id = "comet" },
+]
+`
+
+func TestLoadDeckCustomSuits(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	f, err := os.OpenFile(filepath.Join(root, "deck.toml"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(starsSuit); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(d.Suits(), ","); got != "wands,cups,swords,pentacles,stars" {
+		t.Errorf("Suits() = %s", got)
+	}
+	if got := d.SuitName("stars"); got != "Stars" {
+		t.Errorf("SuitName(stars) = %q", got)
+	}
+
+	cards := d.Cards()
+	if len(cards) != 81 {
+		t.Fatalf("deck has %d cards, want 81", len(cards))
+	}
+	var order []string
+	for _, c := range cards[78:] {
+		order = append(order, c.ID)
+	}
+	if got := strings.Join(order, " "); got != "custom_cards.minor_arcana.stars.comet custom_cards.minor_arcana.stars.ace custom_cards.minor_arcana.stars.queen" {
+		t.Errorf("custom suit order = %s", got)
+	}
+
+	for _, id := range []string{"stars.ace", "minor_arcana.stars.ace", "custom_cards.minor_arcana.stars.ace", "Stars.Ace"} {
+		c, err := d.GetCard(id)
+		if err != nil {
+			t.Errorf("GetCard(%s): %v", id, err)
+			continue
+		}
+		if c.Name != "Ace of Stars" || c.AltText != "A single star" || c.Value != 1 || c.Suit != "stars" {
+			t.Errorf("GetCard(%s) = %+v", id, c)
+		}
+	}
+	if c, err := d.GetCard("stars.queen"); err != nil || !c.IsCourt {
+		t.Errorf("GetCard(stars.queen) = %+v, %v; want a court card", c, err)
+	}
+	if _, err := d.GetCard("stars.king"); err == nil {
+		t.Error("GetCard(stars.king) succeeded for a card the suit does not have")
+	}
+}
+
+func TestCheckCustomSuits(t *testing.T) {
+	tests := []struct {
+		toml string
+		want string // Substring of the first error, empty for none
+	}{
+		{starsSuit, ""},
+		{"[custom_cards.minor_arcana.cups]\ncards = [{ id = \"ace\" }]\n", "clashes with the standard suit"},
+		{"[custom_cards.minor_arcana.Stars]\ncards = [{ id = \"ace\" }]\n", "suit keys must be"},
+		{"[custom_cards.minor_arcana.stars]\nname = \"Stars\"\n", "suit has no cards"},
+		{"[custom_cards.minor_arcana.stars]\ncards = [{ name = \"Ace\" }]\n", "has no id"},
+		{"[custom_cards.minor_arcana.stars]\ncards = [{ id = \"../ace\" }]\n", "card IDs must be"},
+		{"[custom_cards.minor_arcana.stars]\ncards = [{ id = \"ace\" }, { id = \"ace\" }]\n", "duplicate card id"},
+	}
+	for _, tt := range tests {
+		var config DeckConfig
+		if _, err := toml.Decode(tt.toml, &config); err != nil {
+			t.Fatal(err)
+		}
+		errs := CheckCustomSuits(&config)
+		switch {
+		case tt.want == "" && len(errs) > 0:
+			t.Errorf("%q: unexpected error %v", tt.toml, errs[0])
+		case tt.want != "" && len(errs) == 0:
+			t.Errorf("%q: no error, want %q", tt.toml, tt.want)
+		case tt.want != "" && !strings.Contains(errs[0].Error(), tt.want):
+			t.Errorf("%q: error %v, want %q", tt.toml, errs[0], tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package deck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ContentWarningSection holds the [content_warnings] table of deck.toml,
+// flagging imagery some readers may want to avoid, for the whole deck or for
+// individual cards:
+//
+//	[content_warnings]
+//	deck = ["nudity"]
+//
+//	[content_warnings.cards]
+//	"13" = ["death imagery"]
+type ContentWarningSection struct {
+	Deck  []string            `toml:"deck"`
+	Cards map[string][]string `toml:"cards"` // Keyed by card ID
+}
+
+// normalizeWarnings lowercases and trims warnings, dropping duplicates and
+// empty entries, and sorts them
+func normalizeWarnings(warnings []string) []string {
+	seen := make(map[string]bool, len(warnings))
+	var normalized []string
+	for _, w := range warnings {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w == "" || seen[w] {
+			continue
+		}
+		seen[w] = true
+		normalized = append(normalized, w)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// applyContentWarnings resolves the [content_warnings] table onto every card.
+// Deck-wide warnings apply to every card.
+func (d *Deck) applyContentWarnings() {
+	section := d.config.ContentWarnings
+	if section == nil {
+		return
+	}
+	d.ContentWarnings = normalizeWarnings(section.Deck)
+
+	// Card keys may use any notation accepted by GetCard
+	cards := make(map[string][]string, len(section.Cards))
+	for id, warnings := range section.Cards {
+		if c, err := d.GetCard(id); err == nil {
+			cards[c.ID] = append(cards[c.ID], warnings...)
+		}
+	}
+
+	for _, c := range d.Cards() {
+		c.ContentWarnings = normalizeWarnings(append(append([]string(nil), section.Deck...), cards[c.ID]...))
+	}
+}
+
+// AllContentWarnings returns every warning given for the deck or any of its
+// cards, for listings such as registry metadata
+func (d *Deck) AllContentWarnings() []string {
+	var all []string
+	for _, c := range d.Cards() {
+		all = append(all, c.ContentWarnings...)
+	}
+	return normalizeWarnings(append(all, d.ContentWarnings...))
+}
+
+// CheckContentWarnings reports card keys in [content_warnings] that are not
+// cards of the deck and warnings left empty
+func (d *Deck) CheckContentWarnings() []error {
+	section := d.config.ContentWarnings
+	if section == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, w := range section.Deck {
+		if strings.TrimSpace(w) == "" {
+			errs = append(errs, fmt.Errorf("content_warnings.deck: warnings must not be empty"))
+		}
+	}
+
+	ids := make([]string, 0, len(section.Cards))
+	for id := range section.Cards {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		field := fmt.Sprintf("content_warnings.cards.%q", id)
+		if _, err := d.GetCard(id); err != nil {
+			errs = append(errs, fmt.Errorf("%s: not a card of this deck", field))
+			continue
+		}
+		if len(section.Cards[id]) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no warnings given", field))
+		}
+		for _, w := range section.Cards[id] {
+			if strings.TrimSpace(w) == "" {
+				errs = append(errs, fmt.Errorf("%s: warnings must not be empty", field))
+			}
+		}
+	}
+	return errs
+}
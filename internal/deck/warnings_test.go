@@ -0,0 +1,93 @@
+package deck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+func TestContentWarnings(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	appendDeckToml(t, root, `
+[content_warnings]
+deck = ["Occult imagery"]
+
+[content_warnings.cards]
+"13" = ["death imagery", " Death imagery "]
+"swords.ten" = ["blood", "death imagery"]
+`)
+
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"occult imagery"}; !reflect.DeepEqual(d.ContentWarnings, want) {
+		t.Errorf("ContentWarnings = %v, want %v", d.ContentWarnings, want)
+	}
+
+	death, _ := d.GetCard("13")
+	if want := []string{"death imagery", "occult imagery"}; !reflect.DeepEqual(death.ContentWarnings, want) {
+		t.Errorf("Death warnings = %v, want %v", death.ContentWarnings, want)
+	}
+	fool, _ := d.GetCard("00")
+	if want := []string{"occult imagery"}; !reflect.DeepEqual(fool.ContentWarnings, want) {
+		t.Errorf("The Fool warnings = %v, want %v", fool.ContentWarnings, want)
+	}
+
+	if want := []string{"blood", "death imagery", "occult imagery"}; !reflect.DeepEqual(d.AllContentWarnings(), want) {
+		t.Errorf("AllContentWarnings() = %v, want %v", d.AllContentWarnings(), want)
+	}
+	if errs := d.CheckContentWarnings(); len(errs) != 0 {
+		t.Errorf("CheckContentWarnings() = %v, want none", errs)
+	}
+}
+
+func TestCheckContentWarnings(t *testing.T) {
+	root := testutil.FixtureDeck(t)
+	appendDeckToml(t, root, `
+[content_warnings]
+deck = [""]
+
+[content_warnings.cards]
+"13" = []
+"stars.ace" = ["nudity"]
+`)
+
+	d, err := LoadDeck(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"content_warnings.deck: warnings must not be empty",
+		`content_warnings.cards."13": no warnings given`,
+		`content_warnings.cards."stars.ace": not a card of this deck`,
+	}
+	errs := d.CheckContentWarnings()
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors %v, want %d", len(errs), errs, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(errs[i].Error(), w) {
+			t.Errorf("error %d = %v, want %q", i, errs[i], w)
+		}
+	}
+}
+
+// appendDeckToml adds tables to the end of a deck's deck.toml
+func appendDeckToml(t *testing.T, root, content string) {
+	t.Helper()
+	path := filepath.Join(root, "deck.toml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(data, content...), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
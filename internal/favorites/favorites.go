@@ -0,0 +1,73 @@
+// Package favorites keeps the user's list of favorite cards, for quick access
+// to the cards they are studying.
+package favorites
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/atomicfile"
+)
+
+// List is the user's favorite cards by canonical card ID, in the order they
+// were added. Favorites are shared by every deck.
+type List struct {
+	Cards []string `toml:"cards"`
+}
+
+// Load reads the favorites list. A missing file is an empty list.
+func Load(path string) (*List, error) {
+	l := &List{}
+	if _, err := toml.DecodeFile(path, l); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading favorites: %v", err)
+	}
+	return l, nil
+}
+
+// Save writes the favorites list
+func (l *List) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating favorites directory: %v", err)
+	}
+	err := atomicfile.Write(path, 0644, func(w io.Writer) error {
+		return toml.NewEncoder(w).Encode(l)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing favorites: %v", err)
+	}
+	return nil
+}
+
+// Has reports whether a card is a favorite
+func (l *List) Has(id string) bool {
+	for _, fav := range l.Cards {
+		if fav == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds a card to the end of the list, reporting false when it is
+// already a favorite
+func (l *List) Add(id string) bool {
+	if l.Has(id) {
+		return false
+	}
+	l.Cards = append(l.Cards, id)
+	return true
+}
+
+// Remove takes a card off the list, reporting false when it was not a favorite
+func (l *List) Remove(id string) bool {
+	for i, fav := range l.Cards {
+		if fav == id {
+			l.Cards = append(l.Cards[:i], l.Cards[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
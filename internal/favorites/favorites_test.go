@@ -0,0 +1,45 @@
+package favorites
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cartomancer", "favorites.toml")
+
+	l, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing file = %v", err)
+	}
+	if len(l.Cards) != 0 {
+		t.Fatalf("missing file loaded %v, want an empty list", l.Cards)
+	}
+
+	for _, id := range []string{"major_arcana.17", "minor_arcana.cups.queen", "major_arcana.00"} {
+		if !l.Add(id) {
+			t.Errorf("Add(%s) reported a duplicate", id)
+		}
+	}
+	if l.Add("major_arcana.17") {
+		t.Error("Add of an existing favorite reported it as new")
+	}
+	if !l.Remove("minor_arcana.cups.queen") || l.Remove("minor_arcana.cups.queen") {
+		t.Error("Remove should succeed once")
+	}
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"major_arcana.17", "major_arcana.00"}; !reflect.DeepEqual(loaded.Cards, want) {
+		t.Errorf("loaded %v, want %v in the order added", loaded.Cards, want)
+	}
+	if !loaded.Has("major_arcana.00") || loaded.Has("minor_arcana.cups.queen") {
+		t.Errorf("Has() disagrees with %v", loaded.Cards)
+	}
+}
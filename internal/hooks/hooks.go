@@ -0,0 +1,85 @@
+// Package hooks runs user-configured commands when cartomancer events occur.
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Events that hooks can be attached to
+const (
+	PreValidate  = "pre-validate"
+	PostValidate = "post-validate"
+	PostDraw     = "post-draw"
+	PostInstall  = "post-install"
+)
+
+// Events lists every supported event name
+var Events = []string{PreValidate, PostValidate, PostDraw, PostInstall}
+
+// Payload is written as JSON to the standard input of every hook command
+type Payload struct {
+	Event string      `json:"event"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+// Run executes the commands configured for an event in order, passing the
+// payload on stdin. Commands are run through the system shell, with their
+// output sent to stderr so it never mixes with the output of cartomancer
+// itself, which may be read by other programs. The first failing command
+// stops the remaining ones.
+func Run(event string, commands []string, data interface{}) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(Payload{Event: event, Time: time.Now(), Data: data})
+	if err != nil {
+		return fmt.Errorf("error encoding %s hook payload: %v", event, err)
+	}
+
+	for _, command := range commands {
+		cmd := ShellCommand(command)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), "CARTOMANCER_EVENT="+event)
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %q failed: %v", event, command, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that every configured event name is known
+func Validate(hooks map[string][]string) error {
+	for event := range hooks {
+		known := false
+		for _, e := range Events {
+			if e == event {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown hook event: %s (supported: %v)", event, Events)
+		}
+	}
+	return nil
+}
+
+// ShellCommand wraps a command line for the platform shell
+func ShellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
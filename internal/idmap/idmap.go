@@ -0,0 +1,229 @@
+// Package idmap translates the card identifiers of other tarot apps, APIs and
+// datasets to canonical card IDs and back.
+package idmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/canon"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Scheme is a third-party card identifier scheme
+type Scheme struct {
+	Name        string
+	Description string
+	Example     string // Identifier of the Queen of Cups
+
+	canonical func(string) (string, error)
+	foreign   func(*card.Card) string
+}
+
+// Auto is the scheme name that accepts an identifier in any scheme
+const Auto = "auto"
+
+// schemes lists the supported schemes
+var schemes = []*Scheme{
+	{
+		Name:        "index",
+		Description: "Position 0-77 in a standard deck: major arcana, then wands, cups, swords and pentacles from ace to king",
+		Example:     "48",
+		canonical:   fromIndex,
+		foreign:     func(c *card.Card) string { return strconv.Itoa(c.Index) },
+	},
+	{
+		Name:        "name",
+		Description: "English card name, including Thoth and playing card suit names",
+		Example:     "Queen of Cups",
+		canonical:   fromName,
+		foreign:     func(c *card.Card) string { return card.DefaultName(c.ID) },
+	},
+	{
+		Name:        "slug",
+		Description: "Lowercase hyphenated name, as in URLs and community datasets",
+		Example:     "queen-of-cups",
+		canonical:   fromName,
+		foreign:     slug,
+	},
+	{
+		Name:        "labyrinthos",
+		Description: "Labyrinthos card meaning page slugs",
+		Example:     "queen-of-cups-meaning-tarot-card-meanings",
+		canonical:   fromLabyrinthos,
+		foreign:     labyrinthos,
+	},
+	{
+		Name:        "tarot-api",
+		Description: "Short codes of the open tarot-api dataset: ar00-ar21, and suit prefix wa, cu, sw or pe with ac, 02-10, pa, kn, qu or ki",
+		Example:     "cuqu",
+		canonical:   fromShortCode,
+		foreign:     shortCode,
+	},
+}
+
+// Schemes returns the supported schemes
+func Schemes() []*Scheme {
+	return schemes
+}
+
+// Get returns a scheme by name
+func Get(name string) (*Scheme, error) {
+	for _, s := range schemes {
+		if s.Name == strings.ToLower(name) {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown ID scheme: %s (supported: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Names returns the names of the supported schemes
+func Names() []string {
+	names := make([]string, len(schemes))
+	for i, s := range schemes {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Canonical translates an identifier in the scheme to a canonical card ID
+func (s *Scheme) Canonical(foreign string) (string, error) {
+	id, err := s.canonical(strings.TrimSpace(foreign))
+	if err != nil {
+		return "", fmt.Errorf("not a %s identifier: %s", s.Name, foreign)
+	}
+	return id, nil
+}
+
+// Foreign translates a canonical card ID to the scheme's identifier
+func (s *Scheme) Foreign(id string) (string, error) {
+	c, err := standardCard(id)
+	if err != nil {
+		return "", err
+	}
+	return s.foreign(c), nil
+}
+
+// Canonical translates an identifier in the named scheme, or in any scheme
+// for Auto, to a canonical card ID. Names and the notations of card.ParseID
+// are tried before indexes, which agree with major arcana numbers for 0-21.
+func Canonical(foreign, scheme string) (string, error) {
+	if scheme != Auto {
+		s, err := Get(scheme)
+		if err != nil {
+			return "", err
+		}
+		return s.Canonical(foreign)
+	}
+
+	for _, name := range []string{"name", "index", "labyrinthos", "tarot-api"} {
+		s, _ := Get(name)
+		if id, err := s.Canonical(foreign); err == nil {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("unknown card identifier: %s", foreign)
+}
+
+// standardCard returns the card of a canonical ID in the standard 78-card deck
+func standardCard(id string) (*card.Card, error) {
+	canonical, err := card.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(canonical, ".")
+	switch parts[0] {
+	case "major_arcana":
+		num, _ := strconv.Atoi(parts[1])
+		return card.NewMajorArcana(num), nil
+	case "minor_arcana":
+		return card.NewMinorArcana(parts[1], parts[2]), nil
+	}
+	return nil, fmt.Errorf("%s is not a card of the standard deck", id)
+}
+
+// fromIndex resolves a position in the standard deck
+func fromIndex(s string) (string, error) {
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return "", fmt.Errorf("index out of range")
+	}
+	id, ok := canon.At(index)
+	if !ok {
+		return "", fmt.Errorf("index out of range")
+	}
+	return id, nil
+}
+
+// fromName resolves names and slugs, ignoring reversal markers
+func fromName(s string) (string, error) {
+	id, _, err := card.ParseName(s)
+	return id, err
+}
+
+// slug returns the lowercase hyphenated name of a card
+func slug(c *card.Card) string {
+	return strings.ReplaceAll(strings.ToLower(card.DefaultName(c.ID)), " ", "-")
+}
+
+// labyrinthosSuffixes end the card meaning page slugs of Labyrinthos
+var labyrinthosSuffixes = []string{"-meaning-major-arcana-tarot-card-meanings", "-meaning-tarot-card-meanings"}
+
+// labyrinthos returns the Labyrinthos page slug of a card
+func labyrinthos(c *card.Card) string {
+	if c.Type == "major_arcana" {
+		return slug(c) + labyrinthosSuffixes[0]
+	}
+	return slug(c) + labyrinthosSuffixes[1]
+}
+
+// fromLabyrinthos resolves Labyrinthos page slugs, with or without their suffix
+func fromLabyrinthos(s string) (string, error) {
+	s = strings.ToLower(s)
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		s = s[i+1:]
+	}
+	for _, suffix := range labyrinthosSuffixes {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	return fromName(s)
+}
+
+// shortSuits and shortRanks are the suit prefixes and rank suffixes of tarot-api codes
+var (
+	shortSuits = map[string]string{"wands": "wa", "cups": "cu", "swords": "sw", "pentacles": "pe"}
+	shortRanks = map[string]string{
+		"ace": "ac", "two": "02", "three": "03", "four": "04", "five": "05", "six": "06", "seven": "07",
+		"eight": "08", "nine": "09", "ten": "10", "page": "pa", "knight": "kn", "queen": "qu", "king": "ki",
+	}
+)
+
+// shortCode returns the tarot-api short code of a card
+func shortCode(c *card.Card) string {
+	if c.Type == "major_arcana" {
+		return "ar" + c.Number
+	}
+	return shortSuits[c.Suit] + shortRanks[c.Rank]
+}
+
+// fromShortCode resolves tarot-api short codes
+func fromShortCode(s string) (string, error) {
+	s = strings.ToLower(s)
+	if len(s) != 4 {
+		return "", fmt.Errorf("short codes have four characters")
+	}
+	if num, ok := strings.CutPrefix(s, "ar"); ok {
+		if n, err := strconv.Atoi(num); err == nil && n >= 0 && n < canon.MajorCount {
+			return card.NewMajorArcana(n).ID, nil
+		}
+	}
+	for suit, prefix := range shortSuits {
+		for rank, suffix := range shortRanks {
+			if s == prefix+suffix {
+				return card.NewMinorArcana(suit, rank).ID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown short code")
+}
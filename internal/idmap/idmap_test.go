@@ -0,0 +1,55 @@
+package idmap
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, s := range Schemes() {
+		for index := 0; index < 78; index++ {
+			id, err := Canonical(strconv.Itoa(index), "index")
+			if err != nil {
+				t.Fatal(err)
+			}
+			foreign, err := s.Foreign(id)
+			if err != nil {
+				t.Fatalf("%s.Foreign(%s): %v", s.Name, id, err)
+			}
+			back, err := s.Canonical(foreign)
+			if err != nil || back != id {
+				t.Errorf("%s: %s -> %s -> %s (%v)", s.Name, id, foreign, back, err)
+			}
+		}
+	}
+}
+
+func TestExamples(t *testing.T) {
+	for _, s := range Schemes() {
+		if id, err := s.Canonical(s.Example); err != nil || id != "minor_arcana.cups.queen" {
+			t.Errorf("%s example %s = %s (%v)", s.Name, s.Example, id, err)
+		}
+	}
+}
+
+func TestAuto(t *testing.T) {
+	tests := map[string]string{
+		"0":                 "major_arcana.00",
+		"XVII":              "major_arcana.17",
+		"77":                "minor_arcana.pentacles.king",
+		"The Magus":         "major_arcana.01",
+		"Princess of Disks": "minor_arcana.pentacles.page",
+		"ar20":              "major_arcana.20",
+		"wa02":              "minor_arcana.wands.two",
+		"three-of-swords":   "minor_arcana.swords.three",
+		"the-star-meaning-major-arcana-tarot-card-meanings": "major_arcana.17",
+	}
+	for foreign, want := range tests {
+		if got, err := Canonical(foreign, Auto); err != nil || got != want {
+			t.Errorf("Canonical(%q) = %q, %v; want %q", foreign, got, err, want)
+		}
+	}
+	if _, err := Canonical("78", Auto); err == nil {
+		t.Error("Canonical accepted index 78")
+	}
+}
@@ -0,0 +1,66 @@
+package imagegen
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// a1111Backend generates images through the API of the AUTOMATIC1111 Stable
+// Diffusion web UI (started with --api)
+type a1111Backend struct {
+	httpBackend
+}
+
+func newA1111Backend(cfg config.ImageBackend) (*a1111Backend, error) {
+	b, err := newHTTPBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &a1111Backend{b}, nil
+}
+
+// Generate implements Backend
+func (b *a1111Backend) Generate(req Request) ([]byte, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"prompt":          req.Prompt,
+		"negative_prompt": req.NegativePrompt,
+		"width":           req.Width,
+		"height":          req.Height,
+		"steps":           req.Steps,
+		"seed":            req.Seed,
+		"batch_size":      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := b.do("POST", "/sdapi/v1/txt2img", "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Images []string `json:"images"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("error decoding txt2img response: %v", err)
+	}
+	if len(resp.Images) == 0 {
+		return nil, fmt.Errorf("txt2img returned no images")
+	}
+
+	// Images may be returned as data URLs
+	encoded := resp.Images[0]
+	if i := strings.Index(encoded, ","); strings.HasPrefix(encoded, "data:") && i >= 0 {
+		encoded = encoded[i+1:]
+	}
+	img, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding txt2img image: %v", err)
+	}
+	return img, nil
+}
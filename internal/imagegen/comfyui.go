@@ -0,0 +1,161 @@
+package imagegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// comfyUIPollInterval is how often a queued ComfyUI prompt is checked for completion
+const comfyUIPollInterval = time.Second
+
+// comfyUIBackend queues a user-supplied workflow on a ComfyUI server. The
+// workflow is exported from ComfyUI in API format; string values containing
+// %prompt% or %negative_prompt% have the card's prompts substituted, and values
+// that are exactly %seed%, %width%, %height% or %steps% are replaced by numbers.
+type comfyUIBackend struct {
+	httpBackend
+	workflow interface{}
+}
+
+func newComfyUIBackend(cfg config.ImageBackend) (*comfyUIBackend, error) {
+	b, err := newHTTPBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Workflow == "" {
+		return nil, fmt.Errorf("comfyui backend has no workflow setting")
+	}
+
+	// Relative workflow paths are resolved from the directory of config.toml
+	path := cfg.Workflow
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(config.GetConfigFilePath()), path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading workflow: %v", err)
+	}
+	var workflow interface{}
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("error parsing workflow %s: %v", cfg.Workflow, err)
+	}
+
+	return &comfyUIBackend{httpBackend: b, workflow: workflow}, nil
+}
+
+// Generate implements Backend
+func (b *comfyUIBackend) Generate(req Request) ([]byte, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"prompt":    substitute(b.workflow, req),
+		"client_id": "cartomancer",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := b.do("POST", "/prompt", "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	var queued struct {
+		PromptID string `json:"prompt_id"`
+	}
+	if err := json.Unmarshal(data, &queued); err != nil || queued.PromptID == "" {
+		return nil, fmt.Errorf("unexpected response queueing workflow: %s", strings.TrimSpace(string(data)))
+	}
+
+	image, err := b.wait(queued.PromptID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"filename": {image.Filename}, "subfolder": {image.Subfolder}, "type": {image.Type}}
+	return b.do("GET", "/view?"+query.Encode(), "", nil)
+}
+
+// comfyUIImage identifies an output image on a ComfyUI server
+type comfyUIImage struct {
+	Filename  string `json:"filename"`
+	Subfolder string `json:"subfolder"`
+	Type      string `json:"type"`
+}
+
+// wait polls the history of a queued prompt until it has an output image
+func (b *comfyUIBackend) wait(promptID string) (comfyUIImage, error) {
+	deadline := time.Now().Add(client.Timeout)
+	for time.Now().Before(deadline) {
+		data, err := b.do("GET", "/history/"+url.PathEscape(promptID), "", nil)
+		if err != nil {
+			return comfyUIImage{}, err
+		}
+
+		var history map[string]struct {
+			Outputs map[string]struct {
+				Images []comfyUIImage `json:"images"`
+			} `json:"outputs"`
+			Status struct {
+				StatusStr string `json:"status_str"`
+				Completed bool   `json:"completed"`
+			} `json:"status"`
+		}
+		if err := json.Unmarshal(data, &history); err != nil {
+			return comfyUIImage{}, fmt.Errorf("error decoding history: %v", err)
+		}
+
+		if entry, ok := history[promptID]; ok {
+			if entry.Status.StatusStr == "error" {
+				return comfyUIImage{}, fmt.Errorf("workflow failed on the server")
+			}
+			for _, output := range entry.Outputs {
+				if len(output.Images) > 0 {
+					return output.Images[0], nil
+				}
+			}
+			if entry.Status.Completed {
+				return comfyUIImage{}, fmt.Errorf("workflow completed without an output image")
+			}
+		}
+
+		time.Sleep(comfyUIPollInterval)
+	}
+	return comfyUIImage{}, fmt.Errorf("timed out waiting for the workflow to finish")
+}
+
+// substitute returns a copy of a workflow value with the request's values
+// filled in for the placeholders
+func substitute(value interface{}, req Request) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = substitute(item, req)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = substitute(item, req)
+		}
+		return out
+	case string:
+		switch v {
+		case "%seed%":
+			return req.Seed
+		case "%width%":
+			return req.Width
+		case "%height%":
+			return req.Height
+		case "%steps%":
+			return req.Steps
+		}
+		return strings.NewReplacer("%prompt%", req.Prompt, "%negative_prompt%", req.NegativePrompt).Replace(v)
+	}
+	return value
+}
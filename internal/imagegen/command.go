@@ -0,0 +1,60 @@
+package imagegen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+	"github.com/arcanaland/cartomancer/internal/hooks"
+)
+
+// commandBackend runs a shell command for each image, so any other service or
+// local tool can be plugged in. The request is passed in CARTOMANCER_*
+// environment variables and the command writes the image to the path in
+// CARTOMANCER_OUTPUT.
+type commandBackend struct {
+	command string
+}
+
+func newCommandBackend(cfg config.ImageBackend) (*commandBackend, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("command backend has no command setting")
+	}
+	return &commandBackend{command: cfg.Command}, nil
+}
+
+// Generate implements Backend
+func (b *commandBackend) Generate(req Request) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "cartomancer-gen-art-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "image")
+
+	cmd := hooks.ShellCommand(b.command)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"CARTOMANCER_CARD="+req.CardID,
+		"CARTOMANCER_PROMPT="+req.Prompt,
+		"CARTOMANCER_NEGATIVE_PROMPT="+req.NegativePrompt,
+		"CARTOMANCER_WIDTH="+strconv.Itoa(req.Width),
+		"CARTOMANCER_HEIGHT="+strconv.Itoa(req.Height),
+		"CARTOMANCER_STEPS="+strconv.Itoa(req.Steps),
+		"CARTOMANCER_SEED="+strconv.FormatInt(req.Seed, 10),
+		"CARTOMANCER_OUTPUT="+output,
+	)
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("command %q failed: %v", b.command, err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("command %q wrote no image to $CARTOMANCER_OUTPUT", b.command)
+	}
+	return data, nil
+}
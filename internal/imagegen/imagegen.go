@@ -0,0 +1,200 @@
+// Package imagegen generates card art through user-configured image
+// generation services, such as a local Stable Diffusion or ComfyUI server.
+package imagegen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// Default image size and sampling steps, for backends that do not set them.
+// The size keeps the usual 4:7 card proportions in multiples of 64 pixels.
+const (
+	DefaultWidth  = 512
+	DefaultHeight = 896
+	DefaultSteps  = 30
+)
+
+// maxImageSize bounds the size of a generated image read from a backend
+const maxImageSize = 64 << 20
+
+// Request describes one image to generate
+type Request struct {
+	CardID         string `json:"card_id"`
+	Prompt         string `json:"prompt"`
+	NegativePrompt string `json:"negative_prompt,omitempty"`
+	Width          int    `json:"width"`
+	Height         int    `json:"height"`
+	Steps          int    `json:"steps"`
+	Seed           int64  `json:"seed"`
+}
+
+// Backend generates images
+type Backend interface {
+	// Generate returns the encoded image (PNG, JPEG or WebP) for a request
+	Generate(req Request) ([]byte, error)
+}
+
+// client is the HTTP client shared by the backends. Generation can be slow on
+// modest hardware, so the timeout is generous.
+var client = &http.Client{Timeout: 10 * time.Minute}
+
+// New returns the backend for an image generation service
+func New(cfg config.ImageBackend) (Backend, error) {
+	switch cfg.Backend {
+	case config.ImageBackendA1111:
+		return newA1111Backend(cfg)
+	case config.ImageBackendComfyUI:
+		return newComfyUIBackend(cfg)
+	case config.ImageBackendCommand:
+		return newCommandBackend(cfg)
+	}
+	return nil, fmt.Errorf("unknown image backend: %q (supported: %s, %s, %s)", cfg.Backend,
+		config.ImageBackendA1111, config.ImageBackendComfyUI, config.ImageBackendCommand)
+}
+
+// NewRequest builds the request for a card from the backend settings, using
+// style in place of the backend's style when it is not empty
+func NewRequest(cfg config.ImageBackend, c *card.Card, style string, seed int64) Request {
+	if style == "" {
+		style = cfg.Style
+	}
+
+	req := Request{
+		CardID:         c.ID,
+		Prompt:         Prompt(c, style),
+		NegativePrompt: cfg.NegativePrompt,
+		Width:          cfg.Width,
+		Height:         cfg.Height,
+		Steps:          cfg.Steps,
+		Seed:           seed,
+	}
+	if req.Width <= 0 {
+		req.Width = DefaultWidth
+	}
+	if req.Height <= 0 {
+		req.Height = DefaultHeight
+	}
+	if req.Steps <= 0 {
+		req.Steps = DefaultSteps
+	}
+	return req
+}
+
+// Prompt describes a card for an image generation model: the card's name and
+// place in the deck, its description and element, then the style
+func Prompt(c *card.Card, style string) string {
+	var parts []string
+
+	subject := fmt.Sprintf("Tarot card illustration of %s", c.Name)
+	switch {
+	case c.Type == "major_arcana":
+		subject += fmt.Sprintf(", major arcana card %s", card.ToRoman(c.Value))
+	case c.Suit != "":
+		subject += fmt.Sprintf(", %s of the suit of %s", c.Rank, c.Suit)
+	}
+	parts = append(parts, subject)
+
+	if c.AltText != "" {
+		parts = append(parts, strings.TrimRight(c.AltText, ". "))
+	}
+	if c.Element != "" {
+		parts = append(parts, fmt.Sprintf("element of %s", c.Element))
+	}
+	if style != "" {
+		parts = append(parts, style)
+	}
+
+	return strings.Join(parts, ". ")
+}
+
+// Extension returns the file extension for encoded image data
+func Extension(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".png"
+}
+
+// token reads the token named by the backend's token_env setting
+func token(cfg config.ImageBackend) (string, error) {
+	if cfg.TokenEnv == "" {
+		return "", nil
+	}
+	value := os.Getenv(cfg.TokenEnv)
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", cfg.TokenEnv)
+	}
+	return value, nil
+}
+
+// httpBackend holds the connection settings shared by the HTTP backends
+type httpBackend struct {
+	url     string
+	token   string
+	headers map[string]string
+}
+
+// newHTTPBackend reads the connection settings of an HTTP backend
+func newHTTPBackend(cfg config.ImageBackend) (httpBackend, error) {
+	if cfg.URL == "" {
+		return httpBackend{}, fmt.Errorf("%s backend has no url setting", cfg.Backend)
+	}
+	t, err := token(cfg)
+	if err != nil {
+		return httpBackend{}, err
+	}
+	return httpBackend{url: strings.TrimRight(cfg.URL, "/"), token: t, headers: cfg.Headers}, nil
+}
+
+// do sends a request to the backend and returns the response body, treating
+// any status outside 2xx as an error
+func (b httpBackend) do(method, path, contentType string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, b.url+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	for key, value := range b.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := strings.TrimSpace(string(data))
+		if len(message) > 200 {
+			message = message[:200] + "..."
+		}
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, message)
+	}
+	return data, nil
+}
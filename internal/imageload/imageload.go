@@ -0,0 +1,128 @@
+// Package imageload decodes card images with bounded memory. Image headers
+// are probed before decoding, so oversized scans are refused without
+// allocating their pixels, and a shared pixel budget keeps concurrent decodes
+// from holding more than one budget's worth of full-size images at once.
+package imageload
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/nfnt/resize"
+)
+
+// DefaultMaxPixels is the largest image decoded by default: 100 megapixels,
+// such as a 7000x14000 scan, which takes 400 MB as RGBA
+const DefaultMaxPixels = 100_000_000
+
+// Limits bound the memory used to decode an image
+type Limits struct {
+	MaxPixels int // Largest width times height decoded, 0 for DefaultMaxPixels
+	MaxHeight int // Height taller images are scaled down to, 0 to keep the full size
+}
+
+// maxPixels returns the pixel limit, applying the default
+func (l Limits) maxPixels() int {
+	if l.MaxPixels <= 0 {
+		return DefaultMaxPixels
+	}
+	return l.MaxPixels
+}
+
+// TooLargeError reports an image with more pixels than the limit allows
+type TooLargeError struct {
+	Path          string
+	Width, Height int
+	MaxPixels     int
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("%s is %dx%d, more than the limit of %d pixels", e.Path, e.Width, e.Height, e.MaxPixels)
+}
+
+// Probe returns the dimensions and format of an image from its header
+// without decoding its pixels
+func Probe(path string) (image.Config, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	defer file.Close()
+	return image.DecodeConfig(file)
+}
+
+// Decode decodes an image file within the limits. The standard decoders
+// cannot decode at a reduced resolution, so images taller than MaxHeight are
+// decoded at full size and scaled down before Decode returns, releasing the
+// full-size pixels to the next decode.
+func Decode(path string, limits Limits) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, err
+	}
+	pixels := config.Width * config.Height
+	if pixels > limits.maxPixels() {
+		return nil, &TooLargeError{Path: path, Width: config.Width, Height: config.Height, MaxPixels: limits.maxPixels()}
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	budget.acquire(pixels, limits.maxPixels())
+	defer budget.release(pixels)
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	if limits.MaxHeight > 0 && img.Bounds().Dy() > limits.MaxHeight {
+		img = resize.Resize(0, uint(limits.MaxHeight), img, resize.Lanczos3)
+	}
+	return img, nil
+}
+
+// pixelBudget counts the pixels of full-size images being decoded
+type pixelBudget struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	inFlight int
+}
+
+var budget = newPixelBudget()
+
+func newPixelBudget() *pixelBudget {
+	b := &pixelBudget{}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// acquire waits until pixels more fit within max. A decode always proceeds
+// when nothing else is in flight, so no image waits forever.
+func (b *pixelBudget) acquire(pixels, max int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.inFlight > 0 && b.inFlight+pixels > max {
+		b.cond.Wait()
+	}
+	b.inFlight += pixels
+}
+
+// release returns pixels to the budget
+func (b *pixelBudget) release(pixels int) {
+	b.mu.Lock()
+	b.inFlight -= pixels
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
@@ -0,0 +1,85 @@
+package imageload
+
+import (
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+// writeFixture writes a gradient PNG and returns its path
+func writeFixture(t *testing.T, width, height int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "card.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, testutil.FixtureImage(width, height)); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDecodeRefusesLargeImages(t *testing.T) {
+	path := writeFixture(t, 40, 70)
+
+	_, err := Decode(path, Limits{MaxPixels: 40*70 - 1})
+	var tooLarge *TooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Decode error = %v, want TooLargeError", err)
+	}
+	if tooLarge.Width != 40 || tooLarge.Height != 70 {
+		t.Errorf("TooLargeError size = %dx%d, want 40x70", tooLarge.Width, tooLarge.Height)
+	}
+
+	if _, err := Decode(path, Limits{MaxPixels: 40 * 70}); err != nil {
+		t.Errorf("Decode at the limit: %v", err)
+	}
+}
+
+func TestDecodeScalesDown(t *testing.T) {
+	path := writeFixture(t, 40, 70)
+
+	img, err := Decode(path, Limits{MaxHeight: 35})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Size(); got.X != 20 || got.Y != 35 {
+		t.Errorf("size = %v, want 20x35", got)
+	}
+
+	img, err = Decode(path, Limits{MaxHeight: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Bounds().Dy(); got != 70 {
+		t.Errorf("height = %d, want 70 when shorter than MaxHeight", got)
+	}
+}
+
+func TestDecodeBudgetAllowsConcurrentDecodes(t *testing.T) {
+	path := writeFixture(t, 40, 70)
+
+	// Each image fills the budget, so the decodes run one at a time
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Decode(path, Limits{MaxPixels: 40 * 70}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if budget.inFlight != 0 {
+		t.Errorf("inFlight = %d after all decodes, want 0", budget.inFlight)
+	}
+}
@@ -0,0 +1,372 @@
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/idmap"
+)
+
+// Import formats
+const (
+	FormatLabyrinthos  = "labyrinthos"   // Labyrinthos reading history CSV
+	FormatGoldenThread = "golden-thread" // Golden Thread Tarot journal CSV
+	FormatCSV          = "csv"           // Other CSV with date and cards columns
+	FormatJSON         = "json"          // The generic JSON schema described by ImportedReading
+)
+
+// ImportFormats lists the formats Import reads
+var ImportFormats = []string{FormatLabyrinthos, FormatGoldenThread, FormatCSV, FormatJSON}
+
+// ImportedReading is a reading in the generic JSON import schema. Files hold
+// an array of readings, or an object with a "readings" array.
+type ImportedReading struct {
+	Date     string         `json:"date"` // RFC 3339 or YYYY-MM-DD, optionally with a time
+	Question string         `json:"question"`
+	Note     string         `json:"note"`
+	Spread   string         `json:"spread"`
+	Deck     string         `json:"deck"`
+	Cards    []ImportedCard `json:"cards"`
+}
+
+// ImportedCard is a card of a reading in the generic JSON import schema
+type ImportedCard struct {
+	Position string `json:"position"`
+	Card     string `json:"card"` // Card name, canonical ID or identifier in any idmap scheme
+	Reversed bool   `json:"reversed"`
+}
+
+// csvLayout describes the columns of a CSV format
+type csvLayout struct {
+	name     string            // Shown in errors
+	columns  map[string]string // Normalized headers to entry fields
+	numbered bool              // Cards may be in numbered "Card 1", "Position 1" and "Reversed 1" columns
+}
+
+// csvLayouts holds the column layouts of the CSV formats. Exports of apps
+// are read by their own headers only, so a file exported by another app is
+// refused rather than half read; the generic format takes the headers of
+// all of them and common variants.
+var csvLayouts = map[string]csvLayout{
+	// Date,Spread,Question,Cards,Notes with positions given in the cards
+	// column, such as "Past: The Tower (Reversed) | Present: Three of Cups"
+	FormatLabyrinthos: {
+		name: "Labyrinthos",
+		columns: map[string]string{
+			"date": "date", "spread": "spread", "question": "question", "cards": "cards", "notes": "note",
+		},
+	},
+	// Date Created,Title,Spread,Card 1,Position 1,Reversed 1,...,Journal
+	// Entry with one column triple per card
+	FormatGoldenThread: {
+		name: "Golden Thread",
+		columns: map[string]string{
+			"date created": "date", "title": "question", "spread": "spread", "journal entry": "note",
+		},
+		numbered: true,
+	},
+	FormatCSV: {
+		name: "CSV",
+		columns: map[string]string{
+			"date": "date", "datetime": "date", "date time": "date", "created": "date", "created at": "date",
+			"date created": "date", "timestamp": "date", "reading date": "date",
+			"question": "question", "title": "question", "reading title": "question", "intention": "question", "query": "question",
+			"spread": "spread", "spread name": "spread", "layout": "spread",
+			"notes": "note", "note": "note", "reflection": "note", "reflections": "note", "journal": "note",
+			"journal entry": "note", "interpretation": "note", "entry": "note",
+			"cards": "cards", "cards drawn": "cards", "cards pulled": "cards", "card names": "cards",
+			"positions": "positions", "position names": "positions",
+			"deck": "deck", "deck name": "deck",
+		},
+		numbered: true,
+	},
+}
+
+// importDateLayouts are the date formats exports write, tried in order
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"1/2/2006 3:04 PM",
+	"1/2/2006 15:04",
+	"1/2/2006",
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006",
+	"Jan 2, 2006 3:04 PM",
+	"Jan 2, 2006",
+}
+
+// Import reads readings exported by another app. Readings that cannot be
+// read, such as rows with unknown card names, are skipped and reported as
+// problems; the entries are ready to save, with IDs derived from their
+// content so importing the same file again yields the same IDs.
+func Import(r io.Reader, format string) ([]*Entry, []error, error) {
+	var readings []ImportedReading
+	var problems []error
+	var err error
+
+	switch format {
+	case FormatJSON:
+		readings, err = readJSONReadings(r)
+	case FormatLabyrinthos, FormatGoldenThread, FormatCSV:
+		readings, problems, err = readCSVReadings(r, csvLayouts[format])
+	default:
+		return nil, nil, fmt.Errorf("unknown import format: %s (supported: %s)", format, strings.Join(ImportFormats, ", "))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []*Entry
+	for i, reading := range readings {
+		entry, err := reading.entry()
+		if err != nil {
+			problems = append(problems, fmt.Errorf("reading %d: %v", i+1, err))
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, problems, nil
+}
+
+// entry converts an imported reading to a journal entry. The deck is left
+// empty when the export does not name it.
+func (r ImportedReading) entry() (*Entry, error) {
+	date, err := parseImportDate(r.Date)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Cards) == 0 {
+		return nil, fmt.Errorf("no cards")
+	}
+
+	entry := &Entry{
+		Date:     date,
+		Question: strings.TrimSpace(r.Question),
+		Note:     strings.TrimSpace(r.Note),
+		Spread:   strings.TrimSpace(r.Spread),
+		Deck:     strings.TrimSpace(r.Deck),
+	}
+	if entry.Spread == "" {
+		entry.Spread = fmt.Sprintf("%d-card reading", len(r.Cards))
+	}
+
+	for i, c := range r.Cards {
+		// Names carry reversal markers; other apps' codes and indexes are mapped after
+		id, reversed, err := card.ParseName(c.Card)
+		if err != nil {
+			if id, err = idmap.Canonical(c.Card, idmap.Auto); err != nil {
+				return nil, err
+			}
+		}
+		position := strings.TrimSpace(c.Position)
+		if position == "" {
+			position = strconv.Itoa(i + 1)
+		}
+		entry.Cards = append(entry.Cards, Card{
+			Position: position,
+			ID:       id,
+			Name:     card.DefaultName(id),
+			Reversed: reversed || c.Reversed,
+		})
+	}
+
+	entry.ID = importID(entry)
+	return entry, nil
+}
+
+// importID derives an entry ID from the date, question and cards of a reading
+func importID(e *Entry) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", e.Date.UTC().Format(time.RFC3339), e.Question)
+	for _, c := range e.Cards {
+		fmt.Fprintf(h, "%s\x00%s\x00%t\x00", c.Position, c.ID, c.Reversed)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// parseImportDate parses a date in any of the layouts exports use, in local
+// time unless it names a zone
+func parseImportDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("missing date")
+	}
+	for _, layout := range importDateLayouts {
+		if date, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date: %s", s)
+}
+
+// readJSONReadings decodes readings in the generic JSON schema
+func readJSONReadings(r io.Reader) ([]ImportedReading, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var readings []ImportedReading
+	if err := json.Unmarshal(data, &readings); err == nil {
+		return readings, nil
+	}
+	var wrapped struct {
+		Readings []ImportedReading `json:"readings"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+	return wrapped.Readings, nil
+}
+
+// readCSVReadings reads one reading per CSV row, finding the columns by their
+// headers in the layout of the format. Cards are either listed in one column,
+// separated by newlines, semicolons, pipes or commas and optionally prefixed
+// with "Position:", or, in layouts with numbered columns, spread over "Card
+// 1", "Card 2" columns with optional matching "Position 1" and "Reversed 1"
+// columns.
+func readCSVReadings(r io.Reader, layout csvLayout) ([]ImportedReading, []error, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading CSV header: %v", err)
+	}
+
+	columns := map[string]int{}
+	cardColumns := map[int]int{}     // Card number to column
+	positionColumns := map[int]int{} // Card number to column
+	reversedColumns := map[int]int{} // Card number to column
+	for i, name := range header {
+		name = normalizeHeader(name)
+		if field, ok := layout.columns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+			continue
+		}
+		if !layout.numbered {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(name, "card %d", &n); err == nil {
+			cardColumns[n] = i
+		} else if _, err := fmt.Sscanf(name, "position %d", &n); err == nil {
+			positionColumns[n] = i
+		} else if _, err := fmt.Sscanf(name, "reversed %d", &n); err == nil {
+			reversedColumns[n] = i
+		}
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, nil, fmt.Errorf("%s export has no date column (found: %s)", layout.name, strings.Join(header, ", "))
+	}
+	if _, ok := columns["cards"]; !ok && len(cardColumns) == 0 {
+		return nil, nil, fmt.Errorf("%s export has no cards column (found: %s)", layout.name, strings.Join(header, ", "))
+	}
+
+	var readings []ImportedReading
+	var problems []error
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			problems = append(problems, fmt.Errorf("row %d: %v", row, err))
+			continue
+		}
+
+		value := func(i int, ok bool) string {
+			if ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			return value(i, ok)
+		}
+		numbered := func(columns map[int]int, n int) string {
+			i, ok := columns[n]
+			return value(i, ok)
+		}
+		reading := ImportedReading{
+			Date:     field("date"),
+			Question: field("question"),
+			Note:     field("note"),
+			Spread:   field("spread"),
+			Deck:     field("deck"),
+		}
+
+		positions := splitList(field("positions"))
+		for i, item := range splitList(field("cards")) {
+			c := ImportedCard{Card: item}
+			if position, name, ok := strings.Cut(item, ":"); ok {
+				c = ImportedCard{Position: strings.TrimSpace(position), Card: strings.TrimSpace(name)}
+			} else if i < len(positions) {
+				c.Position = positions[i]
+			}
+			reading.Cards = append(reading.Cards, c)
+		}
+		for n := 1; n <= len(record); n++ {
+			name := numbered(cardColumns, n)
+			if name == "" {
+				continue
+			}
+			reversed := strings.ToLower(numbered(reversedColumns, n))
+			reading.Cards = append(reading.Cards, ImportedCard{
+				Card:     name,
+				Position: numbered(positionColumns, n),
+				Reversed: reversed == "yes" || reversed == "true" || reversed == "1",
+			})
+		}
+
+		readings = append(readings, reading)
+	}
+	return readings, problems, nil
+}
+
+// normalizeHeader lowercases a CSV header and reduces punctuation to single spaces
+func normalizeHeader(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// splitList splits a list of cards or positions on the first separator it uses
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	sep := ","
+	for _, candidate := range []string{"\n", ";", "|"} {
+		if strings.Contains(s, candidate) {
+			sep = candidate
+			break
+		}
+	}
+
+	var items []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
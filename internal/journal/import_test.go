@@ -0,0 +1,176 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// importFile imports a file from testdata
+func importFile(t *testing.T, name, format string) ([]*Entry, []error) {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	entries, problems, err := Import(f, format)
+	if err != nil {
+		t.Fatalf("Import(%s, %s): %v", name, format, err)
+	}
+	return entries, problems
+}
+
+// checkEntry compares an imported entry with the expected one, ignoring its ID
+func checkEntry(t *testing.T, got, want *Entry) {
+	t.Helper()
+	if !got.Date.Equal(want.Date) || got.Question != want.Question || got.Note != want.Note ||
+		got.Spread != want.Spread || got.Deck != want.Deck {
+		t.Errorf("got %s %q %q %q deck %q, want %s %q %q %q deck %q",
+			got.Date, got.Question, got.Note, got.Spread, got.Deck,
+			want.Date, want.Question, want.Note, want.Spread, want.Deck)
+	}
+	if len(got.Cards) != len(want.Cards) {
+		t.Fatalf("got cards %+v, want %+v", got.Cards, want.Cards)
+	}
+	for i, c := range want.Cards {
+		g := got.Cards[i]
+		if g.Position != c.Position || g.ID != c.ID || g.Reversed != c.Reversed {
+			t.Errorf("card %d = %s %s reversed=%t, want %s %s reversed=%t",
+				i+1, g.Position, g.ID, g.Reversed, c.Position, c.ID, c.Reversed)
+		}
+	}
+}
+
+func TestImportLabyrinthos(t *testing.T) {
+	entries, problems := importFile(t, "labyrinthos.csv", FormatLabyrinthos)
+	if len(entries) != 2 {
+		t.Fatalf("imported %d readings, want 2", len(entries))
+	}
+	checkEntry(t, entries[0], &Entry{
+		Date:     time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local),
+		Question: "What should I focus on this month?",
+		Note:     "New projects, again.",
+		Spread:   "Past Present Future",
+		Cards: []Card{
+			{Position: "Past", ID: "major_arcana.16", Reversed: true},
+			{Position: "Present", ID: "minor_arcana.cups.three"},
+			{Position: "Future", ID: "minor_arcana.wands.ace"},
+		},
+	})
+	checkEntry(t, entries[1], &Entry{
+		Date:   time.Date(2024, 3, 8, 21, 15, 0, 0, time.Local),
+		Spread: "Daily Card",
+		Cards:  []Card{{Position: "1", ID: "minor_arcana.pentacles.queen"}},
+	})
+	if entries[0].Cards[0].Name != "The Tower" {
+		t.Errorf("card name = %q, want The Tower", entries[0].Cards[0].Name)
+	}
+
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "reading 3") {
+		t.Errorf("problems = %v, want the unknown card of reading 3", problems)
+	}
+}
+
+func TestImportGoldenThread(t *testing.T) {
+	entries, problems := importFile(t, "golden-thread.csv", FormatGoldenThread)
+	if len(problems) != 0 {
+		t.Errorf("problems = %v", problems)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("imported %d readings, want 2", len(entries))
+	}
+	checkEntry(t, entries[0], &Entry{
+		Date:     time.Date(2024, 3, 1, 9, 30, 0, 0, time.Local),
+		Question: "Career",
+		Note:     "Trust the process.\nTalk to Sam on Friday.",
+		Spread:   "Three Card",
+		Cards: []Card{
+			{Position: "Situation", ID: "major_arcana.17"},
+			{Position: "Challenge", ID: "minor_arcana.swords.five", Reversed: true},
+			{Position: "Advice", ID: "minor_arcana.cups.page"},
+		},
+	})
+	checkEntry(t, entries[1], &Entry{
+		Date:   time.Date(2024, 3, 2, 19, 5, 0, 0, time.Local),
+		Spread: "One Card",
+		Cards:  []Card{{Position: "1", ID: "major_arcana.10", Reversed: true}},
+	})
+}
+
+func TestImportCSV(t *testing.T) {
+	entries, problems := importFile(t, "readings.csv", FormatCSV)
+	if len(problems) != 0 || len(entries) != 1 {
+		t.Fatalf("imported %d readings with problems %v, want 1", len(entries), problems)
+	}
+	checkEntry(t, entries[0], &Entry{
+		Date:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local),
+		Question: "Where am I headed?",
+		Note:     "Hazy.",
+		Spread:   "Two card",
+		Deck:     "Rider-Waite-Smith",
+		Cards: []Card{
+			{Position: "Now", ID: "major_arcana.18"},
+			{Position: "Next", ID: "minor_arcana.wands.knight", Reversed: true},
+		},
+	})
+
+	// The generic format also reads the exports of apps
+	for _, name := range []string{"labyrinthos.csv", "golden-thread.csv"} {
+		if entries, _ := importFile(t, name, FormatCSV); len(entries) != 2 {
+			t.Errorf("%s as csv: imported %d readings, want 2", name, len(entries))
+		}
+	}
+}
+
+func TestImportRefusesOtherAppsExports(t *testing.T) {
+	tests := []struct {
+		name, format, want string
+	}{
+		{"golden-thread.csv", FormatLabyrinthos, "Labyrinthos export has no date column"},
+		{"labyrinthos.csv", FormatGoldenThread, "Golden Thread export has no date column"},
+		{"readings.csv", FormatLabyrinthos, "Labyrinthos export has no date column"},
+	}
+	for _, tt := range tests {
+		f, err := os.Open(filepath.Join("testdata", tt.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = Import(f, tt.format)
+		f.Close()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s as %s: got %v, want an error containing %q", tt.name, tt.format, err, tt.want)
+		}
+	}
+}
+
+func TestImportIDsAreStable(t *testing.T) {
+	first, _ := importFile(t, "golden-thread.csv", FormatGoldenThread)
+	again, _ := importFile(t, "golden-thread.csv", FormatGoldenThread)
+	for i := range first {
+		if first[i].ID == "" || first[i].ID != again[i].ID {
+			t.Errorf("reading %d imported as %q, then %q", i+1, first[i].ID, again[i].ID)
+		}
+	}
+	if first[0].ID == first[1].ID {
+		t.Error("different readings got the same ID")
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	input := `{"readings": [{"date": "2024-03-01T09:30:00Z", "question": "Q", "spread": "Single",
+		"cards": [{"card": "major_arcana.00", "reversed": true}]}]}`
+	entries, problems, err := Import(strings.NewReader(input), FormatJSON)
+	if err != nil || len(problems) != 0 || len(entries) != 1 {
+		t.Fatalf("Import() = %d entries, %v, %v", len(entries), problems, err)
+	}
+	checkEntry(t, entries[0], &Entry{
+		Date:     time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		Question: "Q",
+		Spread:   "Single",
+		Cards:    []Card{{Position: "1", ID: "major_arcana.00", Reversed: true}},
+	})
+}
@@ -0,0 +1,234 @@
+// Package journal stores recorded readings with their questions and notes.
+package journal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Entry is a recorded reading
+type Entry struct {
+	ID       string    `toml:"id" json:"id"`
+	Date     time.Time `toml:"date" json:"date"`
+	Question string    `toml:"question,omitempty" json:"question,omitempty"`
+	Note     string    `toml:"note,omitempty" json:"note,omitempty"`
+	Spread   string    `toml:"spread" json:"spread"`
+	SpreadID string    `toml:"spread_id" json:"spread_id"`
+	Deck     string    `toml:"deck" json:"deck"`
+	Seed     int64     `toml:"seed,omitempty" json:"seed,omitempty"`
+	Moon     string    `toml:"moon,omitempty" json:"moon,omitempty"`         // Lunar phase, with astro_timing
+	SunSign  string    `toml:"sun_sign,omitempty" json:"sun_sign,omitempty"` // Sun sign, with astro_timing
+	Cards    []Card    `toml:"cards" json:"cards"`
+	Patterns []string  `toml:"patterns,omitempty" json:"patterns,omitempty"` // Patterns found across the cards when recorded
+}
+
+// Card is a card drawn into a position of a recorded reading
+type Card struct {
+	Position string `toml:"position" json:"position"`
+	ID       string `toml:"id" json:"id"`
+	Name     string `toml:"name" json:"name"`
+	Reversed bool   `toml:"reversed,omitempty" json:"reversed,omitempty"` // Only recorded for readings imported from apps that draw reversals
+
+	// Cards drawn to clarify this one
+	Clarifiers []Card `toml:"clarifiers,omitempty" json:"clarifiers,omitempty"`
+}
+
+// ShortIDLength is the number of ID characters shown in listings
+const ShortIDLength = 7
+
+// ShortID returns the abbreviated entry ID shown in listings. Any unique
+// prefix of an ID, including the short ID, identifies the entry.
+func (e *Entry) ShortID() string {
+	if len(e.ID) <= ShortIDLength {
+		return e.ID
+	}
+	return e.ID[:ShortIDLength]
+}
+
+// Store is a directory holding one TOML file per journal entry
+type Store struct {
+	Dir string
+}
+
+// NewStore returns a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// NewID returns a random entry ID
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to the clock, which is unique enough for a personal journal
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// path returns the file holding an entry
+func (s *Store) path(id string) string {
+	return filepath.Join(s.Dir, id+".toml")
+}
+
+// Save writes an entry, assigning an ID and date if they are unset
+func (s *Store) Save(entry *Entry) error {
+	if entry.ID == "" {
+		entry.ID = NewID()
+	}
+	if entry.Date.IsZero() {
+		entry.Date = time.Now()
+	}
+
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("error creating journal directory: %v", err)
+	}
+
+	file, err := os.Create(s.path(entry.ID))
+	if err != nil {
+		return fmt.Errorf("error creating journal entry: %v", err)
+	}
+	defer file.Close()
+
+	if err := toml.NewEncoder(file).Encode(entry); err != nil {
+		return fmt.Errorf("error encoding journal entry: %v", err)
+	}
+
+	return nil
+}
+
+// List returns every entry, oldest first
+func (s *Store) List() ([]*Entry, error) {
+	files, err := os.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading journal: %v", err)
+	}
+
+	var entries []*Entry
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".toml" {
+			continue
+		}
+
+		var entry Entry
+		if _, err := toml.DecodeFile(filepath.Join(s.Dir, file.Name()), &entry); err != nil {
+			return nil, fmt.Errorf("error parsing journal entry %s: %v", file.Name(), err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	return entries, nil
+}
+
+// Get returns the entry whose ID starts with prefix
+func (s *Store) Get(prefix string) (*Entry, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return nil, fmt.Errorf("empty journal entry ID")
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Entry
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.ID, prefix) {
+			matches = append(matches, entry)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("journal entry not found: %s", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("journal entry ID %s is ambiguous (%d matches); use more characters", prefix, len(matches))
+	}
+}
+
+// Exists reports whether an entry with the full ID is stored
+func (s *Store) Exists(id string) bool {
+	_, err := os.Stat(s.path(id))
+	return err == nil
+}
+
+// Delete removes an entry by its full ID
+func (s *Store) Delete(id string) error {
+	if err := os.Remove(s.path(id)); err != nil {
+		return fmt.Errorf("error deleting journal entry: %v", err)
+	}
+	return nil
+}
+
+// Filter selects entries by text and date range. Zero values match everything.
+type Filter struct {
+	Query string    // Case-insensitive text matched against notes, questions, cards and spreads
+	Since time.Time // Earliest date, inclusive
+	Until time.Time // Latest date, exclusive
+}
+
+// Matches reports whether an entry satisfies the filter
+func (f Filter) Matches(e *Entry) bool {
+	if !f.Since.IsZero() && e.Date.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Date.Before(f.Until) {
+		return false
+	}
+
+	if f.Query == "" {
+		return true
+	}
+	query := strings.ToLower(f.Query)
+	for _, field := range e.searchText() {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchText returns the entry fields that text queries are matched against
+func (e *Entry) searchText() []string {
+	fields := []string{e.Question, e.Note, e.Spread, e.SpreadID}
+	for _, c := range e.Cards {
+		fields = append(fields, c.Name, c.ID, c.Position)
+		for _, clarifier := range c.Clarifiers {
+			fields = append(fields, clarifier.Name, clarifier.ID)
+		}
+	}
+	return fields
+}
+
+// Search returns the entries matching a filter, oldest first
+func (s *Store) Search(f Filter) ([]*Entry, error) {
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Entry
+	for _, entry := range entries {
+		if f.Matches(entry) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches, nil
+}
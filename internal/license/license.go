@@ -0,0 +1,303 @@
+// Package license parses SPDX license expressions declared by decks and
+// classifies the licenses they name.
+package license
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// licenses maps the lower-cased IDs of the SPDX licenses commonly used for
+// artwork, fonts and code to their canonical IDs and full names
+var licenses = map[string][2]string{}
+
+// knownLicenses lists canonical SPDX IDs and names
+var knownLicenses = [][2]string{
+	{"CC0-1.0", "Creative Commons Zero v1.0 Universal"},
+	{"CC-BY-2.0", "Creative Commons Attribution 2.0 Generic"},
+	{"CC-BY-3.0", "Creative Commons Attribution 3.0 Unported"},
+	{"CC-BY-4.0", "Creative Commons Attribution 4.0 International"},
+	{"CC-BY-SA-2.0", "Creative Commons Attribution Share Alike 2.0 Generic"},
+	{"CC-BY-SA-3.0", "Creative Commons Attribution Share Alike 3.0 Unported"},
+	{"CC-BY-SA-4.0", "Creative Commons Attribution Share Alike 4.0 International"},
+	{"CC-BY-ND-3.0", "Creative Commons Attribution No Derivatives 3.0 Unported"},
+	{"CC-BY-ND-4.0", "Creative Commons Attribution No Derivatives 4.0 International"},
+	{"CC-BY-NC-3.0", "Creative Commons Attribution Non Commercial 3.0 Unported"},
+	{"CC-BY-NC-4.0", "Creative Commons Attribution Non Commercial 4.0 International"},
+	{"CC-BY-NC-SA-3.0", "Creative Commons Attribution Non Commercial Share Alike 3.0 Unported"},
+	{"CC-BY-NC-SA-4.0", "Creative Commons Attribution Non Commercial Share Alike 4.0 International"},
+	{"CC-BY-NC-ND-3.0", "Creative Commons Attribution Non Commercial No Derivatives 3.0 Unported"},
+	{"CC-BY-NC-ND-4.0", "Creative Commons Attribution Non Commercial No Derivatives 4.0 International"},
+	{"CC-PDDC", "Creative Commons Public Domain Dedication and Certification"},
+	{"PDDL-1.0", "Open Data Commons Public Domain Dedication & License 1.0"},
+	{"ODbL-1.0", "Open Data Commons Open Database License v1.0"},
+	{"OFL-1.1", "SIL Open Font License 1.1"},
+	{"FreeArt-1.3", "Free Art License 1.3"},
+	{"GFDL-1.3-only", "GNU Free Documentation License v1.3 only"},
+	{"GFDL-1.3-or-later", "GNU Free Documentation License v1.3 or later"},
+	{"MIT", "MIT License"},
+	{"MIT-0", "MIT No Attribution"},
+	{"ISC", "ISC License"},
+	{"0BSD", "BSD Zero Clause License"},
+	{"BSD-2-Clause", "BSD 2-Clause \"Simplified\" License"},
+	{"BSD-3-Clause", "BSD 3-Clause \"New\" or \"Revised\" License"},
+	{"Apache-2.0", "Apache License 2.0"},
+	{"MPL-2.0", "Mozilla Public License 2.0"},
+	{"GPL-2.0-only", "GNU General Public License v2.0 only"},
+	{"GPL-2.0-or-later", "GNU General Public License v2.0 or later"},
+	{"GPL-3.0-only", "GNU General Public License v3.0 only"},
+	{"GPL-3.0-or-later", "GNU General Public License v3.0 or later"},
+	{"LGPL-2.1-only", "GNU Lesser General Public License v2.1 only"},
+	{"LGPL-2.1-or-later", "GNU Lesser General Public License v2.1 or later"},
+	{"LGPL-3.0-only", "GNU Lesser General Public License v3.0 only"},
+	{"LGPL-3.0-or-later", "GNU Lesser General Public License v3.0 or later"},
+	{"AGPL-3.0-only", "GNU Affero General Public License v3.0 only"},
+	{"AGPL-3.0-or-later", "GNU Affero General Public License v3.0 or later"},
+	{"Artistic-2.0", "Artistic License 2.0"},
+	{"Zlib", "zlib License"},
+	{"Unlicense", "The Unlicense"},
+	{"WTFPL", "Do What The F*ck You Want To Public License"},
+}
+
+// exceptions lists the SPDX license exceptions accepted after WITH
+var exceptions = map[string]string{
+	"classpath-exception-2.0": "Classpath-exception-2.0",
+	"font-exception-2.0":      "Font-exception-2.0",
+	"llvm-exception":          "LLVM-exception",
+}
+
+func init() {
+	for _, l := range knownLicenses {
+		licenses[strings.ToLower(l[0])] = l
+	}
+}
+
+// Name returns the full name of a license ID, or the ID itself if it is unknown
+func Name(id string) string {
+	if l, ok := licenses[strings.ToLower(id)]; ok {
+		return l[1]
+	}
+	return id
+}
+
+// URL returns the SPDX page of a known license, or "" for other licenses
+func URL(id string) string {
+	l, ok := licenses[strings.ToLower(id)]
+	if !ok {
+		return ""
+	}
+	return "https://spdx.org/licenses/" + l[0] + ".html"
+}
+
+// Expression is a parsed SPDX license expression
+type Expression struct {
+	Text     string   // Normalized expression with canonical IDs
+	Licenses []string // Canonical IDs of the licenses named, sorted and deduplicated
+	Unknown  []string // IDs that are well formed but not in the known license list
+}
+
+// Proprietary reports whether the expression names a license outside the
+// SPDX list (LicenseRef-*), which cannot be checked for redistribution terms
+func (e *Expression) Proprietary() bool {
+	for _, id := range e.Licenses {
+		if strings.HasPrefix(id, "LicenseRef-") || strings.HasPrefix(id, "DocumentRef-") {
+			return true
+		}
+	}
+	return false
+}
+
+// NonCommercial reports whether the expression names a Creative Commons
+// license that forbids commercial use
+func (e *Expression) NonCommercial() bool {
+	for _, id := range e.Licenses {
+		if strings.Contains(id, "-NC") {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses an SPDX license expression such as "CC-BY-4.0",
+// "MIT OR Apache-2.0" or "(CC-BY-SA-4.0 AND OFL-1.1)"
+func Parse(text string) (*Expression, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("empty license expression")
+	}
+
+	p := &parser{tokens: tokenize(text), expr: &Expression{}}
+	normalized, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPDX expression %q: %v", text, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid SPDX expression %q: unexpected %q", text, p.tokens[p.pos])
+	}
+
+	p.expr.Text = normalized
+	p.expr.Licenses = dedupe(p.expr.Licenses)
+	p.expr.Unknown = dedupe(p.expr.Unknown)
+	return p.expr, nil
+}
+
+// tokenize splits an expression into parentheses and words
+func tokenize(text string) []string {
+	text = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(text)
+	return strings.Fields(text)
+}
+
+// maxDepth bounds the nesting of parentheses, so a hostile expression
+// cannot exhaust the stack of the recursive parser
+const maxDepth = 16
+
+// parser is a recursive descent parser over expression tokens. OR binds
+// more loosely than AND, which binds more loosely than WITH.
+type parser struct {
+	tokens []string
+	pos    int
+	depth  int // Open parentheses
+	expr   *Expression
+}
+
+// peek returns the next token, or "" at the end
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// parseOr parses alternatives separated by OR
+func (p *parser) parseOr() (string, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	for strings.EqualFold(p.peek(), "OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		left += " OR " + right
+	}
+	return left, nil
+}
+
+// parseAnd parses terms separated by AND
+func (p *parser) parseAnd() (string, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return "", err
+	}
+	for strings.EqualFold(p.peek(), "AND") {
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return "", err
+		}
+		left += " AND " + right
+	}
+	return left, nil
+}
+
+// parseTerm parses a parenthesized expression or a license with an optional exception
+func (p *parser) parseTerm() (string, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return "", fmt.Errorf("unexpected end of expression")
+	case token == "(":
+		if p.depth == maxDepth {
+			return "", fmt.Errorf("parentheses nested more than %d deep", maxDepth)
+		}
+		p.pos++
+		p.depth++
+		inner, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if p.peek() != ")" {
+			return "", fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		p.depth--
+		return "(" + inner + ")", nil
+	case token == ")" || isOperator(token):
+		return "", fmt.Errorf("unexpected %q", token)
+	}
+
+	p.pos++
+	id, err := p.license(token)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.EqualFold(p.peek(), "WITH") {
+		p.pos++
+		exception, ok := exceptions[strings.ToLower(p.peek())]
+		if !ok {
+			return "", fmt.Errorf("unknown license exception %q", p.peek())
+		}
+		p.pos++
+		id += " WITH " + exception
+	}
+	return id, nil
+}
+
+// license validates a license ID, recording it in the expression
+func (p *parser) license(token string) (string, error) {
+	orLater := strings.HasSuffix(token, "+")
+	id := strings.TrimSuffix(token, "+")
+
+	if !validID(id) {
+		return "", fmt.Errorf("malformed license ID %q", token)
+	}
+
+	if l, ok := licenses[strings.ToLower(id)]; ok {
+		id = l[0]
+	} else if !strings.HasPrefix(id, "LicenseRef-") && !strings.HasPrefix(id, "DocumentRef-") {
+		p.expr.Unknown = append(p.expr.Unknown, id)
+	}
+
+	p.expr.Licenses = append(p.expr.Licenses, id)
+	if orLater {
+		return id + "+", nil
+	}
+	return id, nil
+}
+
+// validID reports whether s uses only the characters allowed in SPDX IDs
+func validID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// isOperator reports whether a token is an expression operator
+func isOperator(token string) bool {
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "WITH":
+		return true
+	}
+	return false
+}
+
+// dedupe sorts and removes duplicates from a list of IDs
+func dedupe(ids []string) []string {
+	sort.Strings(ids)
+	out := ids[:0]
+	for i, id := range ids {
+		if i == 0 || id != ids[i-1] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
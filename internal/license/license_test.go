@@ -0,0 +1,144 @@
+package license
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text     string
+		want     string
+		licenses []string
+		unknown  []string
+	}{
+		{"CC-BY-4.0", "CC-BY-4.0", []string{"CC-BY-4.0"}, nil},
+		{" cc-by-sa-4.0 ", "CC-BY-SA-4.0", []string{"CC-BY-SA-4.0"}, nil},
+		{"mit or apache-2.0", "MIT OR Apache-2.0", []string{"Apache-2.0", "MIT"}, nil},
+		{"(CC-BY-SA-4.0 AND OFL-1.1)", "(CC-BY-SA-4.0 AND OFL-1.1)", []string{"CC-BY-SA-4.0", "OFL-1.1"}, nil},
+		{"(cc0-1.0)and(mit)", "(CC0-1.0) AND (MIT)", []string{"CC0-1.0", "MIT"}, nil},
+		{"mit OR cc0-1.0 AND ofl-1.1", "MIT OR CC0-1.0 AND OFL-1.1", []string{"CC0-1.0", "MIT", "OFL-1.1"}, nil},
+		{"((MIT))", "((MIT))", []string{"MIT"}, nil},
+		{"MIT AND (MIT OR MIT)", "MIT AND (MIT OR MIT)", []string{"MIT"}, nil},
+		{"GPL-3.0-or-later with font-exception-2.0", "GPL-3.0-or-later WITH Font-exception-2.0", []string{"GPL-3.0-or-later"}, nil},
+		{"GPL-2.0+", "GPL-2.0+", []string{"GPL-2.0"}, []string{"GPL-2.0"}},
+		{"Foo-1.0 OR Bar", "Foo-1.0 OR Bar", []string{"Bar", "Foo-1.0"}, []string{"Bar", "Foo-1.0"}},
+		{"LicenseRef-Artist-Terms", "LicenseRef-Artist-Terms", []string{"LicenseRef-Artist-Terms"}, nil},
+		{"DocumentRef-spdx:LicenseRef-X", "DocumentRef-spdx:LicenseRef-X", []string{"DocumentRef-spdx:LicenseRef-X"}, nil},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if expr.Text != tt.want {
+			t.Errorf("Parse(%q).Text = %q, want %q", tt.text, expr.Text, tt.want)
+		}
+		if !slices.Equal(expr.Licenses, tt.licenses) {
+			t.Errorf("Parse(%q).Licenses = %q, want %q", tt.text, expr.Licenses, tt.licenses)
+		}
+		if !slices.Equal(expr.Unknown, tt.unknown) {
+			t.Errorf("Parse(%q).Unknown = %q, want %q", tt.text, expr.Unknown, tt.unknown)
+		}
+	}
+}
+
+func TestParseNormalizedIsStable(t *testing.T) {
+	for _, text := range []string{"mit or (cc-by-4.0 and ofl-1.1)", "gpl-2.0-only WITH classpath-exception-2.0", "MIT+"} {
+		first, err := Parse(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := Parse(first.Text)
+		if err != nil || again.Text != first.Text {
+			t.Errorf("Parse(%q) = %q, which parses to %q, %v", text, first.Text, again.Text, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", "empty license expression"},
+		{"   ", "empty license expression"},
+		{"MIT OR", "unexpected end of expression"},
+		{"AND MIT", `unexpected "AND"`},
+		{"MIT AND OR Apache-2.0", `unexpected "OR"`},
+		{"(MIT", "missing closing parenthesis"},
+		{"MIT)", `unexpected ")"`},
+		{"()", `unexpected ")"`},
+		{"MIT Apache-2.0", `unexpected "Apache-2.0"`},
+		{"MIT WITH nope", `unknown license exception "nope"`},
+		{"MIT WITH", `unknown license exception ""`},
+		{"M!T", `malformed license ID "M!T"`},
+		{"CC BY 4.0", `unexpected "BY"`},
+		{"+", `malformed license ID "+"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.text)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q): got %v, want an error containing %q", tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestParseDepthLimit(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("(", depth) + "MIT" + strings.Repeat(")", depth)
+	}
+
+	if _, err := Parse(nested(maxDepth)); err != nil {
+		t.Errorf("nesting %d deep: %v", maxDepth, err)
+	}
+	// Siblings do not add up
+	if _, err := Parse(nested(maxDepth) + " OR " + nested(maxDepth)); err != nil {
+		t.Errorf("two expressions nested %d deep: %v", maxDepth, err)
+	}
+	for _, depth := range []int{maxDepth + 1, 1 << 20} {
+		if _, err := Parse(nested(depth)); err == nil || !strings.Contains(err.Error(), "nested more than") {
+			t.Errorf("nesting %d deep: got %v, want the depth limit", depth, err)
+		}
+	}
+}
+
+func TestExpressionClassification(t *testing.T) {
+	tests := []struct {
+		text                       string
+		proprietary, nonCommercial bool
+	}{
+		{"CC-BY-4.0", false, false},
+		{"CC-BY-NC-SA-4.0", false, true},
+		{"MIT OR CC-BY-NC-4.0", false, true},
+		{"LicenseRef-Artist-Terms", true, false},
+		{"CC0-1.0 AND DocumentRef-x:LicenseRef-y", true, false},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expr.Proprietary() != tt.proprietary || expr.NonCommercial() != tt.nonCommercial {
+			t.Errorf("%s: Proprietary() = %t, NonCommercial() = %t, want %t, %t",
+				tt.text, expr.Proprietary(), expr.NonCommercial(), tt.proprietary, tt.nonCommercial)
+		}
+	}
+}
+
+func TestNameAndURL(t *testing.T) {
+	if got := Name("ofl-1.1"); got != "SIL Open Font License 1.1" {
+		t.Errorf("Name(ofl-1.1) = %q", got)
+	}
+	if got := Name("LicenseRef-X"); got != "LicenseRef-X" {
+		t.Errorf("Name of an unknown license = %q, want the ID", got)
+	}
+	if got := URL("cc-by-4.0"); got != "https://spdx.org/licenses/CC-BY-4.0.html" {
+		t.Errorf("URL(cc-by-4.0) = %q", got)
+	}
+	if got := URL("LicenseRef-X"); got != "" {
+		t.Errorf("URL of an unknown license = %q, want none", got)
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests.", "route", "code")
+	r.NewCounter("hits_total", "Hits.")
+	duration := r.NewHistogram("duration_seconds", "Durations.", []float64{0.1, 1})
+	r.NewGaugeFunc("sessions", "Sessions.", func() float64 { return 2 })
+
+	requests.Inc("/b", "200")
+	requests.Inc("/a", "404")
+	requests.Add(2, "/b", "200")
+	duration.Observe(0.05)
+	duration.Observe(0.5)
+	duration.Observe(5)
+
+	var buf bytes.Buffer
+	r.Write(&buf)
+
+	want := `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{route="/a",code="404"} 1
+requests_total{route="/b",code="200"} 3
+# HELP hits_total Hits.
+# TYPE hits_total counter
+hits_total 0
+# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.1"} 1
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 5.55
+duration_seconds_count 3
+# HELP sessions Sessions.
+# TYPE sessions gauge
+sessions 2
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+// Package notes keeps the user's personal study notes on cards, either for
+// every deck or for a single deck.
+package notes
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/atomicfile"
+	"github.com/arcanaland/cartomancer/internal/deck"
+)
+
+// Book holds notes keyed by canonical card ID
+type Book struct {
+	Cards map[string]string `toml:"cards"`
+}
+
+// Store reads and writes notebooks in a directory: notes.toml for notes on
+// every deck and decks/<deck-id>.toml for the notes on one deck
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store of the notebooks in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// path returns the file of a deck's notebook, or of the global notebook when
+// deckID is "", refusing deck IDs that would place it outside the store
+func (s *Store) path(deckID string) (string, error) {
+	if deckID == "" {
+		return filepath.Join(s.dir, "notes.toml"), nil
+	}
+	if err := deck.CheckID(deckID); err != nil {
+		return "", fmt.Errorf("invalid deck id: %v", err)
+	}
+	return filepath.Join(s.dir, "decks", deckID+".toml"), nil
+}
+
+// Load reads a deck's notebook, or the global one when deckID is "". A
+// notebook that was never written is empty.
+func (s *Store) Load(deckID string) (*Book, error) {
+	path, err := s.path(deckID)
+	if err != nil {
+		return nil, err
+	}
+	b := &Book{}
+	if _, err := toml.DecodeFile(path, b); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading notes: %v", err)
+	}
+	if b.Cards == nil {
+		b.Cards = map[string]string{}
+	}
+	return b, nil
+}
+
+// Save writes a deck's notebook, or the global one when deckID is ""
+func (s *Store) Save(deckID string, b *Book) error {
+	path, err := s.path(deckID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating notes directory: %v", err)
+	}
+	err = atomicfile.Write(path, 0644, func(w io.Writer) error {
+		return toml.NewEncoder(w).Encode(b)
+	})
+	if err != nil {
+		return fmt.Errorf("error writing notes: %v", err)
+	}
+	return nil
+}
+
+// ForDeck returns the notes on the cards of a deck keyed by card ID: the
+// deck's own notes, and the global notes on the cards it has none for
+func (s *Store) ForDeck(deckID string) (map[string]string, error) {
+	merged := map[string]string{}
+	for _, id := range []string{"", deckID} {
+		b, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		for cardID, note := range b.Cards {
+			merged[cardID] = note
+		}
+	}
+	return merged, nil
+}
+
+// Set replaces the note on a card. An empty note removes it.
+func (b *Book) Set(cardID, note string) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		delete(b.Cards, cardID)
+		return
+	}
+	b.Cards[cardID] = note
+}
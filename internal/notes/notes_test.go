@@ -0,0 +1,49 @@
+package notes
+
+import "testing"
+
+func TestStore(t *testing.T) {
+	s := NewStore(t.TempDir())
+
+	global, err := s.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	global.Set("major_arcana.17", "  Hope after the storm\n")
+	global.Set("major_arcana.00", "A leap")
+	if err := s.Save("", global); err != nil {
+		t.Fatal(err)
+	}
+
+	thoth, err := s.Load("thoth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	thoth.Set("major_arcana.17", "Nuit pouring the waters")
+	if err := s.Save("thoth", thoth); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		deck, card, want string
+	}{
+		{"thoth", "major_arcana.17", "Nuit pouring the waters"},
+		{"thoth", "major_arcana.00", "A leap"},
+		{"rider-waite-smith", "major_arcana.17", "Hope after the storm"},
+		{"rider-waite-smith", "major_arcana.01", ""},
+	}
+	for _, tt := range tests {
+		notes, err := s.ForDeck(tt.deck)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := notes[tt.card]; got != tt.want {
+			t.Errorf("ForDeck(%s)[%s] = %q, want %q", tt.deck, tt.card, got, tt.want)
+		}
+	}
+
+	global.Set("major_arcana.00", " ")
+	if _, ok := global.Cards["major_arcana.00"]; ok {
+		t.Error("setting an empty note should remove it")
+	}
+}
@@ -0,0 +1,107 @@
+// Package numerology computes the aggregate number signals of a reading: the
+// sum of the card values, its reductions and the repeated ranks and suits.
+package numerology
+
+import (
+	"sort"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Summary is the numerology of a set of cards
+type Summary struct {
+	Sum          int     // Sum of the card values
+	Reduced      int     // Sum reduced to a single digit by adding its digits
+	Quintessence int     // Major arcana number the sum reduces to, 0-21
+	Ranks        []Count // Ranks, or major arcana, drawn more than once
+	Suits        []Count // Suits, or the major arcana, with more than one card
+}
+
+// Count is how often a rank or suit occurs
+type Count struct {
+	Name  string
+	Count int
+}
+
+// MajorArcana is the Count name for the major arcana among the suits
+const MajorArcana = "major_arcana"
+
+// Value returns the number a card adds to a reading: the major arcana their
+// number and the pips theirs. Court cards and cards outside the standard deck
+// add nothing, as in the Golden Dawn practice.
+func Value(c *card.Card) int {
+	switch {
+	case c.Type == "major_arcana" && c.Number != "":
+		return c.Value
+	case c.IsPip():
+		return c.Value
+	}
+	return 0
+}
+
+// Reduce adds the digits of n until a single digit remains
+func Reduce(n int) int {
+	for n > 9 {
+		sum := 0
+		for ; n > 0; n /= 10 {
+			sum += n % 10
+		}
+		n = sum
+	}
+	return n
+}
+
+// Quintessence reduces n to a major arcana number by adding its digits while
+// it exceeds 22. A sum of 22 is the Fool, which is also numbered 0.
+func Quintessence(n int) int {
+	for n > 22 {
+		sum := 0
+		for ; n > 0; n /= 10 {
+			sum += n % 10
+		}
+		n = sum
+	}
+	if n == 22 {
+		return 0
+	}
+	return n
+}
+
+// Analyze computes the numerology of the cards of a reading
+func Analyze(cards []*card.Card) Summary {
+	var s Summary
+	ranks := map[string]int{}
+	suits := map[string]int{}
+	for _, c := range cards {
+		s.Sum += Value(c)
+		switch c.Type {
+		case "major_arcana":
+			suits[MajorArcana]++
+		case "minor_arcana":
+			ranks[c.Rank]++
+			suits[c.Suit]++
+		}
+	}
+	s.Reduced = Reduce(s.Sum)
+	s.Quintessence = Quintessence(s.Sum)
+	s.Ranks = repeated(ranks)
+	s.Suits = repeated(suits)
+	return s
+}
+
+// repeated returns the counts above one, most frequent first
+func repeated(counts map[string]int) []Count {
+	var result []Count
+	for name, n := range counts {
+		if n > 1 {
+			result = append(result, Count{name, n})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,38 @@
+package numerology
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+func TestQuintessence(t *testing.T) {
+	tests := map[int]int{0: 0, 7: 7, 21: 21, 22: 0, 23: 5, 34: 7, 99: 18, 199: 19}
+	for sum, want := range tests {
+		if got := Quintessence(sum); got != want {
+			t.Errorf("Quintessence(%d) = %d, want %d", sum, got, want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	cards := []*card.Card{
+		card.NewMajorArcana(16),
+		card.NewMinorArcana("cups", "five"),
+		card.NewMinorArcana("swords", "five"),
+		card.NewMinorArcana("cups", "queen"),
+		card.NewMajorArcana(19),
+	}
+	s := Analyze(cards)
+
+	if s.Sum != 45 || s.Reduced != 9 || s.Quintessence != 9 {
+		t.Errorf("Sum, Reduced, Quintessence = %d, %d, %d, want 45, 9, 9", s.Sum, s.Reduced, s.Quintessence)
+	}
+	if want := []Count{{"five", 2}}; !reflect.DeepEqual(s.Ranks, want) {
+		t.Errorf("Ranks = %v, want %v", s.Ranks, want)
+	}
+	if want := []Count{{"cups", 2}, {MajorArcana, 2}}; !reflect.DeepEqual(s.Suits, want) {
+		t.Errorf("Suits = %v, want %v", s.Suits, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Package pattern finds the patterns readers look for across the cards of a
+// large spread: suit majorities, court clusters, runs of pips, all-major
+// spreads and the share of reversed cards.
+package pattern
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/canon"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// MinCards is the smallest reading Detect looks for patterns in
+const MinCards = 5
+
+// minRun is the shortest run of consecutive pip values worth noting
+const minRun = 3
+
+// minCourts is the number of court cards that makes a cluster
+const minCourts = 3
+
+// suitThemes are the areas of life each suit speaks to
+var suitThemes = map[string]string{
+	"wands":     "action and ambition",
+	"cups":      "emotions and relationships",
+	"swords":    "thought and conflict",
+	"pentacles": "work and material matters",
+}
+
+// Detect returns a sentence for each pattern in the cards of a reading, or
+// nothing for readings of fewer than MinCards cards. reversed holds whether
+// each card was drawn reversed, and may be nil when reversals are not drawn.
+func Detect(cards []*card.Card, reversed []bool) []string {
+	if len(cards) < MinCards {
+		return nil
+	}
+	total := len(cards)
+
+	var patterns []string
+	majors, courts := 0, 0
+	suits := map[string]int{}
+	pips := map[int]bool{}
+	for _, c := range cards {
+		switch {
+		case c.Type == "major_arcana":
+			majors++
+		case c.IsCourt:
+			courts++
+		}
+		if c.Suit != "" {
+			suits[c.Suit]++
+		}
+		if c.IsPip() {
+			pips[c.Value] = true
+		}
+	}
+
+	switch {
+	case majors == total:
+		patterns = append(patterns, "All major arcana: forces larger than the querent are at work")
+	case majors*2 > total:
+		patterns = append(patterns, fmt.Sprintf("Major arcana majority (%d of %d): a significant period", majors, total))
+	}
+
+	for _, suit := range canon.Suits() {
+		if n := suits[suit]; n*2 >= total {
+			patterns = append(patterns, fmt.Sprintf("%s majority (%d of %d): %s", strings.ToUpper(suit[:1])+suit[1:], n, total, suitThemes[suit]))
+		}
+	}
+
+	if courts >= minCourts {
+		patterns = append(patterns, fmt.Sprintf("Court card cluster (%d of %d): other people play a large part", courts, total))
+	}
+
+	if run := longestRun(pips); len(run) >= minRun {
+		values := make([]string, len(run))
+		for i, v := range run {
+			values[i] = strconv.Itoa(v)
+		}
+		patterns = append(patterns, fmt.Sprintf("Consecutive pips (%s): a situation unfolding step by step", strings.Join(values, ", ")))
+	}
+
+	n := 0
+	for _, r := range reversed {
+		if r {
+			n++
+		}
+	}
+	switch {
+	case n*2 > total:
+		patterns = append(patterns, fmt.Sprintf("Mostly reversed (%d of %d): energy that is blocked or turned inward", n, total))
+	case n > 0:
+		patterns = append(patterns, fmt.Sprintf("Reversed: %d of %d (%d%%)", n, total, n*100/total))
+	}
+
+	return patterns
+}
+
+// longestRun returns the longest run of consecutive values in a set, the
+// lowest one first when runs tie
+func longestRun(values map[int]bool) []int {
+	sorted := make([]int, 0, len(values))
+	for v := range values {
+		sorted = append(sorted, v)
+	}
+	sort.Ints(sorted)
+
+	var best, run []int
+	for _, v := range sorted {
+		if len(run) > 0 && v != run[len(run)-1]+1 {
+			run = nil
+		}
+		run = append(run, v)
+		if len(run) > len(best) {
+			best = append([]int(nil), run...)
+		}
+	}
+	return best
+}
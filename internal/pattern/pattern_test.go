@@ -0,0 +1,42 @@
+package pattern
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+func TestDetect(t *testing.T) {
+	cards := []*card.Card{
+		card.NewMinorArcana("cups", "three"),
+		card.NewMinorArcana("cups", "four"),
+		card.NewMinorArcana("swords", "five"),
+		card.NewMinorArcana("cups", "queen"),
+		card.NewMinorArcana("wands", "king"),
+		card.NewMinorArcana("pentacles", "page"),
+	}
+	got := Detect(cards, []bool{true, false, false, false, false, false})
+	want := []string{
+		"Cups majority (3 of 6): emotions and relationships",
+		"Court card cluster (3 of 6): other people play a large part",
+		"Consecutive pips (3, 4, 5): a situation unfolding step by step",
+		"Reversed: 1 of 6 (16%)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect = %q, want %q", got, want)
+	}
+}
+
+func TestDetectAllMajors(t *testing.T) {
+	var cards []*card.Card
+	for i := 0; i < MinCards; i++ {
+		cards = append(cards, card.NewMajorArcana(i))
+	}
+	if got := Detect(cards, nil); len(got) != 1 || got[0] != "All major arcana: forces larger than the querent are at work" {
+		t.Errorf("Detect = %q", got)
+	}
+	if got := Detect(cards[:MinCards-1], nil); got != nil {
+		t.Errorf("Detect on %d cards = %q, want nothing", MinCards-1, got)
+	}
+}
@@ -0,0 +1,316 @@
+// Package pdf writes simple PDF documents: pages of raster images, lines,
+// rectangles and single-line text in the standard Helvetica fonts, which PDF
+// viewers provide without embedding. That is enough for proof sheets and
+// printable card layouts without a PDF library.
+//
+// Coordinates are in points (1/72 inch) from the top left corner of the page.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page sizes in points
+const (
+	A4Width      = 595.28
+	A4Height     = 841.89
+	LetterWidth  = 612.0
+	LetterHeight = 792.0
+)
+
+// Fonts available to Text
+const (
+	Helvetica     = "F1"
+	HelveticaBold = "F2"
+)
+
+// PointsPerInch converts inches and millimetres to points
+const (
+	PointsPerInch = 72.0
+	PointsPerMM   = PointsPerInch / 25.4
+)
+
+// Document is a PDF document under construction
+type Document struct {
+	Title string
+
+	objects [][]byte // Object bodies; object n is objects[n-1]
+	pages   []*Page
+}
+
+// Page is a page of a document
+type Page struct {
+	Width, Height float64
+
+	doc     *Document
+	content bytes.Buffer
+	images  []Image // Images drawn on the page
+}
+
+// Object numbers reserved for the document structure
+const (
+	catalogObject = 1
+	pagesObject   = 2
+	fontObject    = 3
+	boldObject    = 4
+)
+
+// New returns an empty document
+func New() *Document {
+	return &Document{objects: make([][]byte, boldObject)}
+}
+
+// AddPage appends a page of the given size in points
+func (d *Document) AddPage(width, height float64) *Page {
+	p := &Page{Width: width, Height: height, doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// addObject appends an object and returns its number
+func (d *Document) addObject(body []byte) int {
+	d.objects = append(d.objects, body)
+	return len(d.objects)
+}
+
+// Image is an image embedded in a document, which pages can draw any number
+// of times while the document stores it once
+type Image int
+
+// AddImage embeds an image. Images are embedded at their pixel size, so
+// callers scale them to the print resolution they need first. Transparency is
+// flattened on white.
+func (d *Document) AddImage(img image.Image) Image {
+	return Image(d.addObject(imageObject(img)))
+}
+
+// Image embeds an image and draws it scaled to the rectangle with its top left
+// corner at x, y
+func (p *Page) Image(img image.Image, x, y, w, h float64) {
+	p.DrawImage(p.doc.AddImage(img), x, y, w, h)
+}
+
+// DrawImage draws an embedded image scaled to the rectangle with its top left
+// corner at x, y
+func (p *Page) DrawImage(img Image, x, y, w, h float64) {
+	if !slices.Contains(p.images, img) {
+		p.images = append(p.images, img)
+	}
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+		num(w), num(h), num(x), num(p.Height-y-h), img)
+}
+
+// Text draws a line of text with its baseline starting at x, y. Characters
+// outside Latin-1 are replaced with question marks.
+func (p *Page) Text(font string, size, x, y float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		font, num(size), num(x), num(p.Height-y), escape(encode(s)))
+}
+
+// Line strokes a line between two points
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		num(width), num(x1), num(p.Height-y1), num(x2), num(p.Height-y2))
+}
+
+// Rect strokes the outline of a rectangle
+func (p *Page) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s %s %s re S\n",
+		num(width), num(x), num(p.Height-y-h), num(w), num(h))
+}
+
+// Gray sets the gray level, from 0 (black) to 1 (white), of the lines and
+// text drawn after it
+func (p *Page) Gray(level float64) {
+	fmt.Fprintf(&p.content, "%s G %s g\n", num(level), num(level))
+}
+
+// Save writes the document to a file
+func (d *Document) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := d.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the document. It can be called once.
+func (d *Document) Write(w io.Writer) error {
+	var kids []string
+	for _, p := range d.pages {
+		content := d.addObject(stream("", deflate(p.content.Bytes())))
+
+		var xobjects strings.Builder
+		for _, obj := range p.images {
+			fmt.Fprintf(&xobjects, "/Im%d %d 0 R ", obj, obj)
+		}
+		page := d.addObject([]byte(fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject << %s>> >> >>",
+			pagesObject, num(p.Width), num(p.Height), content, fontObject, boldObject, xobjects.String())))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+
+	d.objects[catalogObject-1] = []byte(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject))
+	d.objects[pagesObject-1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	d.objects[fontObject-1] = []byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	d.objects[boldObject-1] = []byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	info := d.addObject([]byte(fmt.Sprintf("<< /Title (%s) /Producer (cartomancer) /CreationDate (D:%s) >>",
+		escape(encode(d.Title)), time.Now().UTC().Format("20060102150405Z"))))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		buf.Write(body)
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(d.objects)+1, catalogObject, info, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// imageObject encodes an image as an RGB image XObject
+func imageObject(img image.Image) []byte {
+	b := img.Bounds()
+	pixels := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			// Composite premultiplied colors on white
+			white := 0xFFFF - a
+			pixels = append(pixels, byte((r+white)>>8), byte((g+white)>>8), byte((bl+white)>>8))
+		}
+	}
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 ",
+		b.Dx(), b.Dy())
+	return stream(dict, deflate(pixels))
+}
+
+// stream returns a Flate compressed stream object
+func stream(dict string, data []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<< %s/Filter /FlateDecode /Length %d >>\nstream\n", dict, len(data))
+	buf.Write(data)
+	buf.WriteString("\nendstream")
+	return buf.Bytes()
+}
+
+// deflate compresses data with zlib, as the FlateDecode filter expects
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// num formats a number with at most two decimals
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// encode converts text to WinAnsiEncoding, which matches Latin-1 outside
+// the range 128-159
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 32 || (r >= 127 && r < 160) || r > 255 {
+			r = '?'
+		}
+		out = append(out, byte(r))
+	}
+	return out
+}
+
+// escape escapes a PDF string literal
+func escape(b []byte) string {
+	var sb strings.Builder
+	for _, c := range b {
+		if c == '(' || c == ')' || c == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// TextWidth returns the width in points of text set in a font
+func TextWidth(font string, size float64, s string) float64 {
+	widths := helveticaWidths
+	if font == HelveticaBold {
+		widths = helveticaBoldWidths
+	}
+	total := 0
+	for _, c := range encode(s) {
+		if c >= 32 && int(c-32) < len(widths) {
+			total += widths[c-32]
+		} else {
+			total += 556 // Most accented letters are as wide as their base letter
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Fit shortens text with an ellipsis until it fits the width
+func Fit(font string, size, width float64, s string) string {
+	if TextWidth(font, size, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if short := strings.TrimSpace(string(runes)) + "..."; TextWidth(font, size, short) <= width {
+			return short
+		}
+	}
+	return ""
+}
+
+// Character widths of the standard fonts for codes 32-126, in thousandths of
+// the font size, from the Adobe font metrics
+var (
+	helveticaWidths = []int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = []int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
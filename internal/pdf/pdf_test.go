@@ -0,0 +1,77 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestNum(t *testing.T) {
+	tests := map[float64]string{0: "0", 1: "1", 1.5: "1.5", 595.28: "595.28", 2.0 / 3: "0.67", -0.001: "0", 100: "100"}
+	for v, want := range tests {
+		if got := num(v); got != want {
+			t.Errorf("num(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	if got := escape(encode("Le Pendu (XII) \\ 愚")); got != `Le Pendu \(XII\) \\ ?` {
+		t.Errorf("escaped text = %q", got)
+	}
+	if got := TextWidth(Helvetica, 10, "Ace"); got != (667+500+556)*10.0/1000 {
+		t.Errorf("TextWidth() = %v", got)
+	}
+	if got := Fit(Helvetica, 10, 40, "The Hierophant"); got != "The Hi..." {
+		t.Errorf("Fit() = %q", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	doc := New()
+	doc.Title = "Proof"
+	page := doc.AddPage(A4Width, A4Height)
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	page.Image(img, 10, 10, 100, 100)
+	page.Text(Helvetica, 12, 10, 130, "The Fool")
+	page.Rect(10, 10, 100, 100, 0.5)
+	doc.AddPage(LetterWidth, LetterHeight)
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	if !bytes.Contains(data, []byte("/Count 2")) {
+		t.Error("page tree should count 2 pages")
+	}
+
+	// Every cross-reference entry must point at its object
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != len(doc.objects) {
+		t.Fatalf("xref has %d entries, want %d", len(entries), len(doc.objects))
+	}
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[offset:offset+10])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package plugin discovers and runs external cartomancer-<name> subcommands.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix is the executable name prefix that marks a plugin
+const Prefix = "cartomancer-"
+
+// ContextEnv is the environment variable carrying the JSON handshake
+const ContextEnv = "CARTOMANCER_PLUGIN_CONTEXT"
+
+// ProtocolVersion is incremented when the handshake changes incompatibly
+const ProtocolVersion = 1
+
+// Plugin is an external subcommand found on the PATH
+type Plugin struct {
+	Name string // Subcommand name, without the prefix
+	Path string // Absolute path to the executable
+}
+
+// Context is passed to plugins as JSON in the CARTOMANCER_PLUGIN_CONTEXT
+// environment variable so they can find the same decks and config
+type Context struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Executable      string `json:"executable"`
+	ConfigFile      string `json:"config_file"`
+	DeckLibrary     string `json:"deck_library"`
+	CacheDir        string `json:"cache_dir"`
+	DefaultDeck     string `json:"default_deck"`
+	DefaultDeckPath string `json:"default_deck_path,omitempty"`
+	Theme           string `json:"theme,omitempty"`
+	Symbols         string `json:"symbols,omitempty"`
+}
+
+// Discover finds plugins on the PATH. When several directories provide the
+// same plugin, the first one on the PATH wins, as it would in a shell.
+func Discover() []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] || entry.IsDir() {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+
+	return plugins
+}
+
+// pluginName extracts the subcommand name from an executable file name
+func pluginName(fileName string) (string, bool) {
+	if !strings.HasPrefix(fileName, Prefix) {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(fileName, Prefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	return name, name != ""
+}
+
+// isExecutable reports whether path is a regular file the user may execute
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+
+	return info.Mode().Perm()&0111 != 0
+}
+
+// Run executes a plugin with the given arguments, connecting it to the
+// terminal and passing the handshake context in its environment
+func Run(p Plugin, args []string, ctx *Context) error {
+	ctx.ProtocolVersion = ProtocolVersion
+
+	handshake, err := json.Marshal(ctx)
+	if err != nil {
+		return fmt.Errorf("error encoding plugin context: %v", err)
+	}
+
+	cmd := exec.Command(p.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), ContextEnv+"="+string(handshake))
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s failed: %v", p.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+// Package pool selects subsets of a deck to draw from, optionally weighted.
+package pool
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Pool is a named subset of the deck. Members are built-in pool names or card
+// IDs in any notation accepted by card.ParseID.
+type Pool struct {
+	ID      string
+	Name    string
+	Members []string
+	Weights map[string]float64 // Relative draw weights keyed by card ID; unlisted cards weigh 1
+	match   func(c *card.Card) bool
+}
+
+// builtinPools holds the predefined pools, selected by card attributes
+var builtinPools = map[string]*Pool{
+	"full":      {ID: "full", Name: "Full deck", match: func(c *card.Card) bool { return true }},
+	"majors":    {ID: "majors", Name: "Major arcana", match: func(c *card.Card) bool { return c.Type == "major_arcana" }},
+	"minors":    {ID: "minors", Name: "Minor arcana", match: func(c *card.Card) bool { return c.Type == "minor_arcana" }},
+	"courts":    {ID: "courts", Name: "Court cards", match: func(c *card.Card) bool { return c.IsCourt }},
+	"pips":      {ID: "pips", Name: "Pip cards", match: func(c *card.Card) bool { return c.IsPip() }},
+	"wands":     Suit("wands", "Wands"),
+	"cups":      Suit("cups", "Cups"),
+	"swords":    Suit("swords", "Swords"),
+	"pentacles": Suit("pentacles", "Pentacles"),
+}
+
+// customPools holds pools loaded from the user's pools directory
+var customPools = map[string]*Pool{}
+
+// suitMatcher selects the minor arcana cards of a suit
+func suitMatcher(suit string) func(c *card.Card) bool {
+	return func(c *card.Card) bool { return c.Type == "minor_arcana" && c.Suit == suit }
+}
+
+// Suit returns a pool of the minor arcana cards of a suit
+func Suit(suit, name string) *Pool {
+	return &Pool{ID: suit, Name: "Suit of " + name, match: suitMatcher(suit)}
+}
+
+// Get returns a built-in or custom pool by ID
+func Get(id string) (*Pool, error) {
+	return Lookup(id, nil)
+}
+
+// Lookup returns a built-in or custom pool by ID, or failing that the pool of
+// one of a deck's suits, given as display names keyed by suit. This lets decks
+// with custom suits draw from them by suit like the standard four.
+func Lookup(id string, suits map[string]string) (*Pool, error) {
+	if p, ok := builtinPools[id]; ok {
+		return p, nil
+	}
+	if p, ok := customPools[id]; ok {
+		return p, nil
+	}
+	if name, ok := suits[id]; ok {
+		return Suit(id, name), nil
+	}
+
+	names := Names()
+	for suit := range suits {
+		if _, ok := builtinPools[suit]; !ok {
+			names = append(names, suit)
+		}
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown pool: %s (available: %s)", id, strings.Join(names, ", "))
+}
+
+// Names returns the IDs of all built-in and custom pools, sorted
+func Names() []string {
+	names := make([]string, 0, len(builtinPools)+len(customPools))
+	for name := range builtinPools {
+		names = append(names, name)
+	}
+	for name := range customPools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// poolFile is the on-disk format of a custom pool
+type poolFile struct {
+	ID      string             `toml:"id"`
+	Name    string             `toml:"name"`
+	Cards   []string           `toml:"cards"`
+	Weights map[string]float64 `toml:"weights"`
+}
+
+// LoadDir loads custom pools from the .toml files in a directory. A missing
+// directory is not an error. Custom pools cannot replace built-in ones.
+func LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading pools directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		p, err := loadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error loading pool %s: %v", entry.Name(), err)
+		}
+
+		if _, ok := builtinPools[p.ID]; ok {
+			return fmt.Errorf("pool %s in %s clashes with a built-in pool", p.ID, entry.Name())
+		}
+		customPools[p.ID] = p
+	}
+
+	return nil
+}
+
+// loadFile parses and validates a custom pool file
+func loadFile(path string) (*Pool, error) {
+	var f poolFile
+	if _, err := toml.DecodeFile(path, &f); err != nil {
+		return nil, err
+	}
+
+	if f.ID == "" {
+		f.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if f.Name == "" {
+		f.Name = f.ID
+	}
+	if len(f.Cards) == 0 {
+		f.Cards = []string{"full"}
+	}
+
+	for _, member := range f.Cards {
+		if _, ok := builtinPools[member]; ok {
+			continue
+		}
+		if _, err := card.ParseID(member); err != nil {
+			return nil, fmt.Errorf("cards: %q is neither a pool name nor a card ID", member)
+		}
+	}
+
+	weights := make(map[string]float64, len(f.Weights))
+	for id, weight := range f.Weights {
+		canonical, err := card.ParseID(id)
+		if err != nil {
+			return nil, fmt.Errorf("weights: %v", err)
+		}
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("weights: %s must be a non-negative number", id)
+		}
+		weights[canonical] = weight
+	}
+
+	return &Pool{ID: f.ID, Name: f.Name, Members: f.Cards, Weights: weights}, nil
+}
+
+// Select returns the cards of the deck that belong to the pool, in deck
+// order. Cards weighted 0 are left out, so a pool that weighs all of its cards
+// 0 is an error like a pool matching none, rather than an empty draw.
+func (p *Pool) Select(cards []*card.Card) ([]*card.Card, error) {
+	ids := make(map[string]bool)
+	var matchers []func(c *card.Card) bool
+	if p.match != nil {
+		matchers = append(matchers, p.match)
+	}
+	for _, member := range p.Members {
+		if builtin, ok := builtinPools[member]; ok {
+			matchers = append(matchers, builtin.match)
+			continue
+		}
+		id, err := card.ParseID(member)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	var selected []*card.Card
+	weightless := 0
+	for _, c := range cards {
+		member := ids[c.ID]
+		for i := 0; i < len(matchers) && !member; i++ {
+			member = matchers[i](c)
+		}
+		if !member {
+			continue
+		}
+		if w, ok := p.Weights[c.ID]; ok && w == 0 {
+			weightless++
+			continue
+		}
+		selected = append(selected, c)
+	}
+
+	if len(selected) == 0 && weightless > 0 {
+		return nil, fmt.Errorf("pool %s weighs all of its cards in this deck 0", p.ID)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("pool %s matches no cards in this deck", p.ID)
+	}
+	return selected, nil
+}
+
+// Weighted reports whether the pool assigns any card a weight other than 1
+func (p *Pool) Weighted() bool {
+	for _, weight := range p.Weights {
+		if weight != 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Shuffle returns the cards in a random order in which cards with larger
+// weights tend to come first. Cards with weight 0 are left out.
+func (p *Pool) Shuffle(cards []*card.Card, rng *rand.Rand) []*card.Card {
+	type keyed struct {
+		card *card.Card
+		key  float64
+	}
+
+	// Weighted sampling without replacement (Efraimidis and Spirakis):
+	// sorting by u^(1/w) yields the order of successive weighted draws
+	items := make([]keyed, 0, len(cards))
+	for _, c := range cards {
+		weight := 1.0
+		if w, ok := p.Weights[c.ID]; ok {
+			weight = w
+		}
+		if weight == 0 {
+			continue
+		}
+		items = append(items, keyed{c, math.Pow(rng.Float64(), 1/weight)})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].key > items[j].key
+	})
+
+	shuffled := make([]*card.Card, len(items))
+	for i, item := range items {
+		shuffled[i] = item.card
+	}
+	return shuffled
+}
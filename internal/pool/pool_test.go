@@ -0,0 +1,242 @@
+package pool
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/canon"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// standardCards returns the 78 cards of a standard deck in deck order
+func standardCards() []*card.Card {
+	var cards []*card.Card
+	for i := range canon.MajorCount {
+		cards = append(cards, card.NewMajorArcana(i))
+	}
+	for _, suit := range canon.Suits() {
+		for _, rank := range canon.Ranks() {
+			cards = append(cards, card.NewMinorArcana(suit, rank))
+		}
+	}
+	return cards
+}
+
+// ids returns the IDs of cards
+func ids(cards []*card.Card) []string {
+	out := make([]string, len(cards))
+	for i, c := range cards {
+		out[i] = c.ID
+	}
+	return out
+}
+
+// writePool writes a pool file to dir
+func writePool(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSelectBuiltin(t *testing.T) {
+	cards := standardCards()
+	tests := map[string]int{"full": 78, "majors": 22, "minors": 56, "courts": 16, "pips": 40, "cups": 14}
+	for id, want := range tests {
+		p, err := Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		selected, err := p.Select(cards)
+		if err != nil || len(selected) != want {
+			t.Errorf("pool %s selected %d cards (%v), want %d", id, len(selected), err, want)
+		}
+	}
+
+	if _, err := Get("nope"); err == nil || !strings.Contains(err.Error(), "available: courts, cups, full") {
+		t.Errorf("Get(nope) = %v, want the available pools", err)
+	}
+}
+
+func TestSelectMembers(t *testing.T) {
+	p := &Pool{ID: "mixed", Members: []string{"cups/ace", "XVII", "swords", "major_arcana.17"}}
+	selected, err := p.Select(standardCards())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Deck order, without duplicates
+	got := ids(selected)
+	if len(got) != 16 || got[0] != "major_arcana.17" || got[1] != "minor_arcana.cups.ace" || got[2] != "minor_arcana.swords.ace" {
+		t.Errorf("selected %v", got)
+	}
+
+	p = &Pool{ID: "stars", Members: []string{"majors"}}
+	if _, err := p.Select([]*card.Card{card.NewMinorArcana("cups", "two")}); err == nil || !strings.Contains(err.Error(), "matches no cards") {
+		t.Errorf("pool matching nothing: got %v", err)
+	}
+}
+
+func TestSelectLeavesOutZeroWeights(t *testing.T) {
+	p := &Pool{ID: "no-tower", Members: []string{"majors"}, Weights: map[string]float64{"major_arcana.16": 0, "major_arcana.13": 2}}
+	selected, err := p.Select(standardCards())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 21 || slices.Contains(ids(selected), "major_arcana.16") {
+		t.Errorf("selected %v, want the majors without the Tower", ids(selected))
+	}
+
+	// All weights 0 fails like an empty pool rather than yielding no cards
+	weights := map[string]float64{}
+	for _, id := range ids(standardCards()[:22]) {
+		weights[id] = 0
+	}
+	p = &Pool{ID: "zero", Members: []string{"majors"}, Weights: weights}
+	selected, err = p.Select(standardCards())
+	if err == nil || !strings.Contains(err.Error(), "weighs all of its cards in this deck 0") {
+		t.Errorf("all weights 0: selected %d cards, %v", len(selected), err)
+	}
+}
+
+func TestShuffleDistribution(t *testing.T) {
+	cards := standardCards()[:5]
+	p := &Pool{ID: "weighted", Weights: map[string]float64{cards[0].ID: 4, cards[4].ID: 0.5}}
+	if !p.Weighted() {
+		t.Fatal("pool with weights other than 1 is not weighted")
+	}
+
+	// The chance of a card coming first is its share of the total weight:
+	// 4/7.5 for the first card, 1/7.5 for the ones that weigh 1
+	const trials = 20000
+	first := map[string]int{}
+	rng := rand.New(rand.NewSource(1))
+	for range trials {
+		shuffled := p.Shuffle(cards, rng)
+		if len(shuffled) != len(cards) {
+			t.Fatalf("shuffled %d cards, want %d", len(shuffled), len(cards))
+		}
+		first[shuffled[0].ID]++
+	}
+	for id, want := range map[string]float64{cards[0].ID: 4 / 7.5, cards[1].ID: 1 / 7.5, cards[4].ID: 0.5 / 7.5} {
+		if got := float64(first[id]) / trials; got < want-0.02 || got > want+0.02 {
+			t.Errorf("%s came first %.3f of the time, want %.3f", id, got, want)
+		}
+	}
+
+	// The same seed gives the same order
+	a := p.Shuffle(cards, rand.New(rand.NewSource(42)))
+	b := p.Shuffle(cards, rand.New(rand.NewSource(42)))
+	if !slices.Equal(ids(a), ids(b)) {
+		t.Errorf("seed 42 gave %v, then %v", ids(a), ids(b))
+	}
+}
+
+func TestShuffleLeavesOutZeroWeights(t *testing.T) {
+	cards := standardCards()[:3]
+	p := &Pool{ID: "weighted", Weights: map[string]float64{cards[1].ID: 0}}
+	shuffled := p.Shuffle(cards, rand.New(rand.NewSource(1)))
+	if len(shuffled) != 2 || slices.Contains(ids(shuffled), cards[1].ID) {
+		t.Errorf("shuffled %v, want the cards weighted above 0", ids(shuffled))
+	}
+	if (&Pool{Weights: map[string]float64{cards[0].ID: 1}}).Weighted() {
+		t.Error("pool with weights of 1 is weighted")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	p, err := loadFile(writePool(t, dir, "career.toml", `
+name = "Career questions"
+cards = ["pentacles", "wands", "major_arcana.10", "XIX"]
+
+[weights]
+"pentacles.ace" = 2.5
+"10" = 0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != "career" || p.Name != "Career questions" {
+		t.Errorf("loaded pool %q named %q", p.ID, p.Name)
+	}
+	if p.Weights["minor_arcana.pentacles.ace"] != 2.5 || p.Weights["major_arcana.10"] != 0 || len(p.Weights) != 2 {
+		t.Errorf("weights = %v, keyed by canonical IDs", p.Weights)
+	}
+	selected, err := p.Select(standardCards())
+	if err != nil || len(selected) != 29 {
+		t.Errorf("selected %d cards (%v), want 28 suit cards and the Sun", len(selected), err)
+	}
+
+	// Pools default to the full deck, named by their ID
+	p, err = loadFile(writePool(t, dir, "any.toml", `id = "anything"`))
+	if err != nil || p.ID != "anything" || p.Name != "anything" || !slices.Equal(p.Members, []string{"full"}) {
+		t.Errorf("loaded %+v, %v", p, err)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{`cards = ["majors", "the fool"]`, `"the fool" is neither a pool name nor a card ID`},
+		{"[weights]\n\"wands.15\" = 2", "weights: invalid card ID: wands.15"},
+		{"[weights]\n\"17\" = -1", "weights: 17 must be a non-negative number"},
+		{"[weights]\n\"17\" = nan", "weights: 17 must be a non-negative number"},
+		{"[weights]\n\"17\" = inf", "weights: 17 must be a non-negative number"},
+		{`cards = "majors"`, "incompatible types"},
+	}
+	for _, tt := range tests {
+		_, err := loadFile(writePool(t, t.TempDir(), "bad.toml", tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want an error containing %q", tt.content, err, tt.want)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Cleanup(func() { customPools = map[string]*Pool{} })
+
+	if err := LoadDir(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("missing directory: %v", err)
+	}
+
+	dir := t.TempDir()
+	writePool(t, dir, "love.toml", `cards = ["cups", "major_arcana.06"]`)
+	writePool(t, dir, "notes.txt", "not a pool")
+	if err := LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := Get("love"); err != nil || p.ID != "love" {
+		t.Errorf("Get(love) = %v, %v", p, err)
+	}
+	if !slices.Contains(Names(), "love") {
+		t.Errorf("Names() = %v, want the custom pool", Names())
+	}
+
+	writePool(t, dir, "majors.toml", `cards = ["cups"]`)
+	if err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), "clashes with a built-in pool") {
+		t.Errorf("pool named like a built-in one: got %v", err)
+	}
+}
+
+func TestLookupCustomSuit(t *testing.T) {
+	p, err := Lookup("stars", map[string]string{"stars": "Stars"})
+	if err != nil || p.Name != "Suit of Stars" {
+		t.Fatalf("Lookup(stars) = %v, %v", p, err)
+	}
+	star := card.NewMinorArcana("stars", "ace")
+	selected, err := p.Select([]*card.Card{card.NewMajorArcana(0), star})
+	if err != nil || len(selected) != 1 || selected[0] != star {
+		t.Errorf("selected %v, %v", selected, err)
+	}
+
+	if _, err := Lookup("moons", map[string]string{"stars": "Stars"}); err == nil || !strings.Contains(err.Error(), "stars") {
+		t.Errorf("Lookup(moons) = %v, want the deck's suits among the available pools", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package prompts chooses journaling prompts for the card of the day by the
+// occasion: the sabbats of the Wheel of the Year, the new and full moon, and
+// otherwise the season.
+package prompts
+
+import (
+	_ "embed"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/astro"
+)
+
+// Hemispheres, which turn the Wheel of the Year and the seasons by half a year
+const (
+	North = "north"
+	South = "south"
+)
+
+//go:embed prompts.toml
+var builtin string
+
+// Occasion is a named occasion with its prompts. {card} in a prompt stands
+// for the name of the card drawn.
+type Occasion struct {
+	Name    string   `toml:"name"`
+	Prompts []string `toml:"prompts"`
+}
+
+// Set holds occasions keyed by ID, such as "samhain" or "full-moon"
+type Set map[string]Occasion
+
+// sabbats are the sabbats by the sun's ecliptic longitude on the day they
+// fall in the northern hemisphere
+var sabbats = []struct {
+	id        string
+	longitude float64
+}{
+	{"ostara", 0}, {"beltane", 45}, {"litha", 90}, {"lughnasadh", 135},
+	{"mabon", 180}, {"samhain", 225}, {"yule", 270}, {"imbolc", 315},
+}
+
+// seasons are the northern hemisphere seasons, each starting 90° of solar
+// longitude after the one before
+var seasons = []string{"spring", "summer", "autumn", "winter"}
+
+// Load returns the built-in prompts with the occasions defined in the file at
+// path replacing the built-in ones. A missing file is not an error.
+func Load(path string) (Set, error) {
+	set := Set{}
+	if _, err := toml.Decode(builtin, &set); err != nil {
+		return nil, fmt.Errorf("error parsing built-in prompts: %v", err)
+	}
+
+	var overrides Set
+	if _, err := toml.DecodeFile(path, &overrides); err != nil {
+		if os.IsNotExist(err) {
+			return set, nil
+		}
+		return nil, fmt.Errorf("error reading prompts: %v", err)
+	}
+	for id, occasion := range overrides {
+		if occasion.Name == "" {
+			occasion.Name = set[id].Name
+		}
+		set[id] = occasion
+	}
+	return set, nil
+}
+
+// Occasions returns the IDs of the occasions on the local date of t, most
+// particular first: a sabbat, then the new or full moon, then the season
+func Occasions(t time.Time, hemisphere string) []string {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	end := start.AddDate(0, 0, 1)
+
+	// The wheel and the seasons are half a year apart in the south
+	offset := 0.0
+	if hemisphere == South {
+		offset = 180
+	}
+
+	var ids []string
+	from, to := astro.SunLongitude(start), astro.SunLongitude(end)
+	for _, sabbat := range sabbats {
+		if crosses(from, to, math.Mod(sabbat.longitude+offset, 360), 360) {
+			ids = append(ids, sabbat.id)
+		}
+	}
+
+	from, to = astro.Moon(start).Age, astro.Moon(end).Age
+	if crosses(from, to, 0, astro.SynodicMonth) {
+		ids = append(ids, "new-moon")
+	}
+	if crosses(from, to, astro.SynodicMonth/2, astro.SynodicMonth) {
+		ids = append(ids, "full-moon")
+	}
+
+	noon := math.Mod(astro.SunLongitude(start.Add(12*time.Hour))+360-offset, 360)
+	return append(ids, seasons[int(noon/90)%4])
+}
+
+// crosses reports whether a cyclic value moving forward from one value to
+// another passes the target, within a cycle of the given length
+func crosses(from, to, target, cycle float64) bool {
+	return math.Mod(target-from+cycle, cycle) < math.Mod(to-from+cycle, cycle)
+}
+
+// For returns the most particular occasion on the date of t that has prompts,
+// and one of its prompts for a card. The prompt is chosen from the date so it
+// stays the same all day.
+func (s Set) For(t time.Time, hemisphere, cardName string) (Occasion, string, bool) {
+	for _, id := range Occasions(t, hemisphere) {
+		occasion, ok := s[id]
+		if !ok || len(occasion.Prompts) == 0 {
+			continue
+		}
+
+		h := fnv.New32a()
+		h.Write([]byte(t.Format("2006-01-02") + "|" + id))
+		prompt := occasion.Prompts[int(h.Sum32()%uint32(len(occasion.Prompts)))]
+		return occasion, strings.ReplaceAll(prompt, "{card}", cardName), true
+	}
+	return Occasion{}, "", false
+}
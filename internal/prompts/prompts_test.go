@@ -0,0 +1,62 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOccasions(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		date       time.Time
+		hemisphere string
+		want       []string
+	}{
+		{day(2024, time.December, 21), North, []string{"yule", "winter"}},
+		{day(2024, time.December, 21), South, []string{"litha", "summer"}},
+		{day(2024, time.March, 20), North, []string{"ostara", "spring"}},
+		{day(2024, time.March, 25), North, []string{"full-moon", "spring"}},
+		{day(2024, time.January, 11), North, []string{"new-moon", "winter"}},
+		{day(2024, time.July, 3), North, []string{"summer"}},
+		{day(2024, time.July, 3), South, []string{"winter"}},
+	}
+	for _, tt := range tests {
+		if got := Occasions(tt.date, tt.hemisphere); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Occasions(%s, %s) = %v, want %v", tt.date.Format("2006-01-02"), tt.hemisphere, got, tt.want)
+		}
+	}
+}
+
+func TestLoadOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.toml")
+	content := `
+[yule]
+prompts = ["Light a candle for {card}"]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	set, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	occasion, prompt, ok := set.For(time.Date(2024, time.December, 21, 9, 0, 0, 0, time.UTC), North, "The Star")
+	if !ok || occasion.Name != "Yule" || prompt != "Light a candle for The Star" {
+		t.Errorf("For(Yule) = %+v, %q, %v", occasion, prompt, ok)
+	}
+
+	// Built-in occasions that are not overridden are kept
+	occasion, prompt, ok = set.For(time.Date(2024, time.July, 3, 9, 0, 0, 0, time.UTC), North, "The Sun")
+	if !ok || occasion.Name != "Summer" || !strings.Contains(prompt, "The Sun") {
+		t.Errorf("For(summer) = %+v, %q, %v", occasion, prompt, ok)
+	}
+}
@@ -0,0 +1,513 @@
+// Package qr encodes short texts such as URLs as QR codes (ISO/IEC 18004) in
+// byte mode, small enough to be drawn in a terminal.
+package qr
+
+import "fmt"
+
+// MaxVersion is the largest symbol Encode produces, 57 modules across, which
+// holds URLs of up to 213 bytes at level M and still fits a terminal
+const MaxVersion = 10
+
+// Level is an error correction level, trading capacity for robustness
+type Level int
+
+// Error correction levels, recovering about 7%, 15%, 25% and 30% of the symbol
+const (
+	L Level = iota
+	M
+	Q
+	H
+)
+
+// formatBits are the levels' bits in the format information
+var formatBits = [...]int{L: 1, M: 0, Q: 3, H: 2}
+
+// eccPerBlock is the number of error correction codewords in each block, by
+// level and version
+var eccPerBlock = [...][MaxVersion + 1]int{
+	L: {0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18},
+	M: {0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26},
+	Q: {0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24},
+	H: {0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28},
+}
+
+// eccBlocks is the number of error correction blocks, by level and version
+var eccBlocks = [...][MaxVersion + 1]int{
+	L: {0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4},
+	M: {0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5},
+	Q: {0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8},
+	H: {0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8},
+}
+
+// Code is an encoded QR code symbol
+type Code struct {
+	Version int
+	Size    int // Modules across and down, without the quiet zone
+
+	modules  [][]bool // Dark modules, by row and column
+	function [][]bool // Modules of the function patterns, left out of data and masking
+}
+
+// Dark reports whether the module at column x and row y is dark. Modules
+// outside the symbol are light, like the quiet zone around it.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y][x]
+}
+
+// Encode encodes data as the smallest QR code that holds it at the given
+// error correction level, choosing the mask that is easiest to scan
+func Encode(data []byte, level Level) (*Code, error) {
+	if level < L || level > H {
+		return nil, fmt.Errorf("invalid error correction level %d", level)
+	}
+
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*dataCodewords(v, level) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%d bytes is too long for a QR code of up to version %d (at most %d)",
+			len(data), MaxVersion, dataCodewords(MaxVersion, level)-3)
+	}
+
+	c := newCode(version)
+	c.drawFunctionPatterns()
+	c.drawCodewords(interleave(encodeData(data, version, level), version, level))
+
+	best, minPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormatBits(level, mask)
+		if p := c.penalty(); minPenalty < 0 || p < minPenalty {
+			best, minPenalty = mask, p
+		}
+		c.applyMask(mask) // Masks are their own inverse
+	}
+	c.applyMask(best)
+	c.drawFormatBits(level, best)
+	return c, nil
+}
+
+// newCode returns an empty symbol of a version
+func newCode(version int) *Code {
+	size := 4*version + 17
+	c := &Code{Version: version, Size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range c.modules {
+		c.modules[y] = make([]bool, size)
+		c.function[y] = make([]bool, size)
+	}
+	return c
+}
+
+// countBits is the length of the character count in byte mode
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// rawCodewords is the number of codewords a version holds, data and error
+// correction together
+func rawCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		modules -= (25*align-10)*align - 55
+		if version >= 7 {
+			modules -= 36 // Version information
+		}
+	}
+	return modules / 8
+}
+
+// dataCodewords is the number of data codewords a version holds at a level
+func dataCodewords(version int, level Level) int {
+	return rawCodewords(version) - eccPerBlock[level][version]*eccBlocks[level][version]
+}
+
+// encodeData returns the data codewords: the byte mode segment, terminator
+// and padding
+func encodeData(data []byte, version int, level Level) []byte {
+	var bits bitBuffer
+	bits.append(0x4, 4) // Byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := 8 * dataCodewords(version, level)
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+// interleave splits the data codewords into blocks, adds each block's error
+// correction codewords and interleaves them in the order they are placed
+func interleave(data []byte, version int, level Level) []byte {
+	numBlocks := eccBlocks[level][version]
+	eccLen := eccPerBlock[level][version]
+	raw := rawCodewords(version)
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw/numBlocks - eccLen
+
+	divisor := rsDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	eccs := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortLen
+		if i >= numShort {
+			n++
+		}
+		blocks[i] = data[k : k+n]
+		eccs[i] = rsRemainder(blocks[i], divisor)
+		k += n
+	}
+
+	result := make([]byte, 0, raw)
+	for i := 0; i <= shortLen; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for _, ecc := range eccs {
+			result = append(result, ecc[i])
+		}
+	}
+	return result
+}
+
+// set sets a function module
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder and alignment patterns and
+// the version information, and reserves the format information
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	positions := alignmentPositions(c.Version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// The corners with finder patterns have no alignment pattern
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormatBits(L, 0) // Reserved, drawn for real once the mask is chosen
+	c.drawVersion()
+}
+
+// drawFinder draws a finder pattern and its separator around a center
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.set(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// alignmentPositions returns the centers of the alignment patterns along
+// either axis, nothing for version 1
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*4 + n*2 + 1) / (n*2 - 2) * 2
+	positions := make([]int, n)
+	positions[0] = 6
+	for i, pos := n-1, 4*version+10; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// formatInfo returns the 15 format information bits of a level and mask
+func formatInfo(level Level, mask int) int {
+	data := formatBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionInfo returns the 18 version information bits of versions 7 and up
+func versionInfo(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+// drawFormatBits draws both copies of the format information
+func (c *Code) drawFormatBits(level Level, mask int) {
+	bits := formatInfo(level, mask)
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true) // Always dark
+}
+
+// drawVersion draws both copies of the version information
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionInfo(c.Version)
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.set(a, b, dark)
+		c.set(b, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag of two-module columns,
+// from the bottom right corner up and down, skipping function modules
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y][x] = codewords[i/8]>>(7-i%8)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.function[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// finderLike is the 1:1:3:1:1 finder pattern preceded by four light modules,
+// which scanners could mistake for a finder
+var finderLike = []bool{false, false, false, false, true, false, true, true, true, false, true}
+
+// penalty scores how hard the symbol is to scan, following the four rules of
+// the standard: runs of one color, 2x2 blocks, finder-like patterns and an
+// unbalanced share of dark modules
+func (c *Code) penalty() int {
+	p := 0
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < c.Size; i++ {
+			for j := range line {
+				if vertical {
+					line[j] = c.modules[j][i]
+				} else {
+					line[j] = c.modules[i][j]
+				}
+			}
+
+			run := 1
+			for j := 1; j <= c.Size; j++ {
+				if j < c.Size && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					p += 3 + run - 5
+				}
+				run = 1
+			}
+
+			for j := 0; j+len(finderLike) <= c.Size; j++ {
+				if matches(line[j:], finderLike, false) || matches(line[j:], finderLike, true) {
+					p += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x > 0 && y > 0 {
+				m := c.modules[y][x]
+				if c.modules[y-1][x] == m && c.modules[y][x-1] == m && c.modules[y-1][x-1] == m {
+					p += 3
+				}
+			}
+		}
+	}
+	total := c.Size * c.Size
+	p += ((abs(dark*20-total*10)+total-1)/total - 1) * 10
+	return p
+}
+
+// matches reports whether line starts with pattern, read backwards if reverse
+func matches(line, pattern []bool, reverse bool) bool {
+	for i, want := range pattern {
+		if reverse {
+			want = pattern[len(pattern)-1-i]
+		}
+		if line[i] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of a degree, its
+// coefficients from the highest power down without the leading 1
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords of data
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMul(coef, factor)
+		}
+	}
+	return result
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bitBuffer is a sequence of bits, most significant first
+type bitBuffer []bool
+
+// append adds the n low bits of v
+func (b *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 != 0)
+	}
+}
+
+// bytes packs the bits into bytes, their length being a multiple of 8
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
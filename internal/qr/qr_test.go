@@ -0,0 +1,205 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// The 1-M example of the Thonky QR code tutorial
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(len(want))); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestFormatInfo(t *testing.T) {
+	tests := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{L, 4, 0b110011000101111},
+		{M, 0, 0b101010000010010},
+		{H, 7, 0b000100000111011},
+	}
+	for _, tt := range tests {
+		if got := formatInfo(tt.level, tt.mask); got != tt.want {
+			t.Errorf("formatInfo(%d, %d) = %015b, want %015b", tt.level, tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	if got, want := versionInfo(7), 0b000111110010010100; got != want {
+		t.Errorf("versionInfo(7) = %018b, want %018b", got, want)
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := map[int][]int{1: nil, 2: {6, 18}, 7: {6, 22, 38}, 10: {6, 28, 50}}
+	for version, want := range tests {
+		got := alignmentPositions(version)
+		if len(got) != len(want) {
+			t.Errorf("alignmentPositions(%d) = %v, want %v", version, got, want)
+			continue
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("alignmentPositions(%d) = %v, want %v", version, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestDataCodewords(t *testing.T) {
+	tests := []struct {
+		version int
+		level   Level
+		want    int
+	}{
+		{1, L, 19}, {1, H, 9}, {5, M, 86}, {7, Q, 88}, {10, M, 216},
+	}
+	for _, tt := range tests {
+		if got := dataCodewords(tt.version, tt.level); got != tt.want {
+			t.Errorf("dataCodewords(%d, %d) = %d, want %d", tt.version, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		text    string
+		level   Level
+		version int
+	}{
+		{"https://example.com", M, 2},
+		{"https://example.com/cards/major_arcana.00", M, 3},
+		{"https://tarot.example.org/decks/rider-waite-smith/cards/minor_arcana.pentacles.queen", M, 5},
+		{strings.Repeat("a", 213), M, 10},
+	}
+	for _, tt := range tests {
+		c, err := Encode([]byte(tt.text), tt.level)
+		if err != nil {
+			t.Fatalf("Encode(%q): %v", tt.text, err)
+		}
+		if c.Version != tt.version {
+			t.Errorf("Encode(%q) version = %d, want %d", tt.text, c.Version, tt.version)
+		}
+		if got := readBack(t, c); !strings.HasPrefix(string(got), tt.text) {
+			t.Errorf("Encode(%q) reads back as %q", tt.text, got)
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(bytes.Repeat([]byte("a"), 214), M); err == nil {
+		t.Error("Encode of 214 bytes at level M succeeded, want an error")
+	}
+}
+
+// readBack decodes an undamaged symbol: it reads the format information,
+// unmasks the data modules, checks every block's error correction and
+// returns the byte mode segment
+func readBack(t *testing.T, c *Code) []byte {
+	t.Helper()
+
+	var format int
+	for i := 0; i <= 5; i++ {
+		format |= bit(c.Dark(8, i)) << i
+	}
+	format |= bit(c.Dark(8, 7))<<6 | bit(c.Dark(8, 8))<<7 | bit(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		format |= bit(c.Dark(14-i, 8)) << i
+	}
+	var level Level
+	mask := -1
+	for l := L; l <= H; l++ {
+		for m := 0; m < 8; m++ {
+			if formatInfo(l, m) == format {
+				level, mask = l, m
+			}
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("unknown format information %015b", format)
+	}
+
+	c.applyMask(mask)
+	defer c.applyMask(mask)
+	var codewords bitBuffer
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if x := right - j; !c.function[y][x] {
+					codewords = append(codewords, c.modules[y][x])
+				}
+			}
+		}
+	}
+	raw := codewords[:rawCodewords(c.Version)*8].bytes()
+
+	// Undo the interleaving
+	numBlocks := eccBlocks[level][c.Version]
+	eccLen := eccPerBlock[level][c.Version]
+	numShort := numBlocks - len(raw)%numBlocks
+	shortLen := len(raw)/numBlocks - eccLen
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortLen; i++ {
+		for b := range blocks {
+			if i < shortLen || b >= numShort {
+				blocks[b] = append(blocks[b], raw[k])
+				k++
+			}
+		}
+	}
+	var data []byte
+	for b, block := range blocks {
+		ecc := make([]byte, eccLen)
+		for i := range ecc {
+			ecc[i] = raw[k+i*numBlocks+b]
+		}
+		if want := rsRemainder(block, rsDivisor(eccLen)); !bytes.Equal(ecc, want) {
+			t.Errorf("block %d error correction = %v, want %v", b, ecc, want)
+		}
+		data = append(data, block...)
+	}
+
+	var bits bitBuffer
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	if mode := readBits(bits[:4]); mode != 0x4 {
+		t.Fatalf("mode = %x, want byte mode", mode)
+	}
+	n := countBits(c.Version)
+	length := readBits(bits[4 : 4+n])
+	return bits[4+n : 4+n+8*length].bytes()
+}
+
+func bit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
+
+func readBits(bits bitBuffer) int {
+	v := 0
+	for _, b := range bits {
+		v = v<<1 | bit(b)
+	}
+	return v
+}
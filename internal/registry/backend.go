@@ -0,0 +1,143 @@
+package registry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// Backend uploads published artifacts to a registry
+type Backend interface {
+	// Upload stores an artifact and returns the URL it can be downloaded from
+	Upload(a *Artifact) (string, error)
+}
+
+// client is the HTTP client shared by the backends
+var client = &http.Client{Timeout: 10 * time.Minute}
+
+// NewBackend returns the backend configured for a registry. Release names the
+// set of artifacts being published together, such as a GitHub release tag.
+func NewBackend(cfg config.Registry, release string) (Backend, error) {
+	switch cfg.Backend {
+	case config.RegistryGitHub:
+		return newGitHubBackend(cfg, release)
+	case config.RegistryS3:
+		return newS3Backend(cfg)
+	case config.RegistryHTTP:
+		return newHTTPBackend(cfg)
+	}
+	return nil, fmt.Errorf("unknown registry backend: %q (supported: github, s3, http)", cfg.Backend)
+}
+
+// token reads the token named by the registry's token_env setting
+func token(cfg config.Registry, required bool) (string, error) {
+	if cfg.TokenEnv == "" {
+		if required {
+			return "", fmt.Errorf("registry has no token_env setting")
+		}
+		return "", nil
+	}
+
+	value := os.Getenv(cfg.TokenEnv)
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", cfg.TokenEnv)
+	}
+	return value, nil
+}
+
+// joinURL joins a base URL and path segments with single slashes
+func joinURL(base string, segments ...string) string {
+	url := strings.TrimRight(base, "/")
+	for _, segment := range segments {
+		if segment = strings.Trim(segment, "/"); segment != "" {
+			url += "/" + segment
+		}
+	}
+	return url
+}
+
+// send performs a request and returns the response body, treating any
+// status outside 2xx as an error
+func send(req *http.Request) ([]byte, int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := strings.TrimSpace(string(body))
+		if len(message) > 200 {
+			message = message[:200] + "..."
+		}
+		return body, resp.StatusCode, fmt.Errorf("%s %s: %s %s", req.Method, req.URL.Redacted(), resp.Status, message)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// newUploadRequest builds a request whose body is the contents of an artifact
+func newUploadRequest(method, url string, a *Artifact) (*http.Request, error) {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", a.Name, err)
+	}
+
+	req, err := http.NewRequest(method, url, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	req.ContentLength = a.Size
+	req.Header.Set("Content-Type", a.ContentType)
+	return req, nil
+}
+
+// httpBackend PUTs artifacts under a base URL, as supported by WebDAV servers,
+// object stores with presigned prefixes and simple upload endpoints
+type httpBackend struct {
+	url     string
+	prefix  string
+	token   string
+	headers map[string]string
+}
+
+// newHTTPBackend configures a plain HTTP PUT backend
+func newHTTPBackend(cfg config.Registry) (*httpBackend, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("http registry requires a url")
+	}
+	t, err := token(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	return &httpBackend{url: cfg.URL, prefix: cfg.Prefix, token: t, headers: cfg.Headers}, nil
+}
+
+// Upload PUTs the artifact to <url>/<prefix>/<name>
+func (b *httpBackend) Upload(a *Artifact) (string, error) {
+	url := joinURL(b.url, b.prefix, a.Name)
+	req, err := newUploadRequest(http.MethodPut, url, a)
+	if err != nil {
+		return "", err
+	}
+	for key, value := range b.headers {
+		req.Header.Set(key, value)
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	if _, _, err := send(req); err != nil {
+		return "", err
+	}
+	return url, nil
+}
@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// testArtifact writes an artifact with the given content to a temporary directory
+func testArtifact(t *testing.T, name, content string) *Artifact {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewArtifact(path, "application/gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &a
+}
+
+func TestHTTPUpload(t *testing.T) {
+	var gotMethod, gotPath, gotAuth, gotHeader, gotType, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotPath, gotBody = r.Method, r.URL.Path, string(body)
+		gotAuth, gotHeader, gotType = r.Header.Get("Authorization"), r.Header.Get("X-Team"), r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	t.Setenv("REGISTRY_TOKEN", "s3cret")
+	b, err := NewBackend(config.Registry{Backend: config.RegistryHTTP, URL: srv.URL + "/uploads/", Prefix: "tarot",
+		TokenEnv: "REGISTRY_TOKEN", Headers: map[string]string{"X-Team": "arcana"}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url, err := b.Upload(testArtifact(t, "deck-1.0.tar.gz", "archive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := srv.URL + "/uploads/tarot/deck-1.0.tar.gz"; url != want {
+		t.Errorf("Upload() = %s, want %s", url, want)
+	}
+	if gotMethod != http.MethodPut || gotPath != "/uploads/tarot/deck-1.0.tar.gz" {
+		t.Errorf("got %s %s", gotMethod, gotPath)
+	}
+	if gotAuth != "Bearer s3cret" || gotHeader != "arcana" || gotType != "application/gzip" {
+		t.Errorf("got Authorization %q, X-Team %q, Content-Type %q", gotAuth, gotHeader, gotType)
+	}
+	if gotBody != "archive" {
+		t.Errorf("uploaded %q, want the artifact", gotBody)
+	}
+}
+
+func TestHTTPUploadError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusInsufficientStorage)
+	}))
+	defer srv.Close()
+
+	b, err := NewBackend(config.Registry{Backend: config.RegistryHTTP, URL: srv.URL}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = b.Upload(testArtifact(t, "deck-1.0.tar.gz", "archive"))
+	if err == nil || !strings.Contains(err.Error(), "507 Insufficient Storage quota exceeded") {
+		t.Errorf("Upload() error = %v, want the server's status and message", err)
+	}
+}
+
+func TestNewBackendErrors(t *testing.T) {
+	tests := []struct {
+		cfg  config.Registry
+		want string
+	}{
+		{config.Registry{Backend: "ftp"}, "unknown registry backend"},
+		{config.Registry{Backend: config.RegistryHTTP}, "requires a url"},
+		{config.Registry{Backend: config.RegistryS3}, "requires a bucket"},
+		{config.Registry{Backend: config.RegistryGitHub, Repo: "tarot"}, "owner/name"},
+		{config.Registry{Backend: config.RegistryGitHub, Repo: "arcana/tarot"}, "no token_env"},
+		{config.Registry{Backend: config.RegistryGitHub, Repo: "arcana/tarot", TokenEnv: "UNSET_REGISTRY_TOKEN"}, "is not set"},
+	}
+	for _, tt := range tests {
+		if _, err := NewBackend(tt.cfg, "v1"); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: got %v, want an error containing %q", tt.cfg, err, tt.want)
+		}
+	}
+}
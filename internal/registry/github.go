@@ -0,0 +1,126 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// githubAPI is the default GitHub REST API endpoint
+const githubAPI = "https://api.github.com"
+
+// githubBackend uploads artifacts as assets of a GitHub release, creating the
+// release for the tag if it does not exist yet
+type githubBackend struct {
+	api       string
+	repo      string
+	tag       string
+	token     string
+	uploadURL string // Asset upload endpoint of the release, resolved on first upload
+}
+
+// githubRelease holds the fields used from the GitHub release API
+type githubRelease struct {
+	ID        int64  `json:"id"`
+	UploadURL string `json:"upload_url"`
+}
+
+// newGitHubBackend configures a GitHub release backend
+func newGitHubBackend(cfg config.Registry, tag string) (*githubBackend, error) {
+	if cfg.Repo == "" || !strings.Contains(cfg.Repo, "/") {
+		return nil, fmt.Errorf("github registry requires repo = \"owner/name\"")
+	}
+	t, err := token(cfg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	api := cfg.URL
+	if api == "" {
+		api = githubAPI
+	}
+	return &githubBackend{api: api, repo: cfg.Repo, tag: tag, token: t}, nil
+}
+
+// request builds an authenticated GitHub API request
+func (b *githubBackend) request(method, url string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// release finds the release for the tag, creating it when it does not exist
+func (b *githubBackend) release() (*githubRelease, error) {
+	req, err := b.request(http.MethodGet, joinURL(b.api, "repos", b.repo, "releases", "tags", url.PathEscape(b.tag)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, status, err := send(req)
+	if status == http.StatusNotFound {
+		payload, _ := json.Marshal(map[string]string{"tag_name": b.tag, "name": b.tag})
+		req, err = b.request(http.MethodPost, joinURL(b.api, "repos", b.repo, "releases"), payload)
+		if err != nil {
+			return nil, err
+		}
+		body, _, err = send(req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding release %s: %v", b.tag, err)
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("error decoding release: %v", err)
+	}
+	return &release, nil
+}
+
+// Upload adds the artifact as a release asset
+func (b *githubBackend) Upload(a *Artifact) (string, error) {
+	if b.uploadURL == "" {
+		release, err := b.release()
+		if err != nil {
+			return "", err
+		}
+		// The upload URL is a template such as .../assets{?name,label}
+		b.uploadURL, _, _ = strings.Cut(release.UploadURL, "{")
+	}
+
+	query := url.Values{"name": {a.Name}}
+	if a.Label != "" {
+		query.Set("label", a.Label)
+	}
+
+	req, err := newUploadRequest(http.MethodPost, b.uploadURL+"?"+query.Encode(), a)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+b.token)
+
+	body, _, err := send(req)
+	if err != nil {
+		return "", err
+	}
+
+	var asset struct {
+		BrowserDownloadURL string `json:"browser_download_url"`
+	}
+	if err := json.Unmarshal(body, &asset); err != nil {
+		return "", fmt.Errorf("error decoding uploaded asset: %v", err)
+	}
+	return asset.BrowserDownloadURL, nil
+}
@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+func TestGitHubUpload(t *testing.T) {
+	var requests []string
+	uploads := map[string]string{}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer ghp_test" {
+			http.Error(w, "bad credentials", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/arcana/tarot/releases/tags/v1.0":
+			http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+		case "POST /repos/arcana/tarot/releases":
+			var release map[string]string
+			json.NewDecoder(r.Body).Decode(&release)
+			if release["tag_name"] != "v1.0" {
+				t.Errorf("created release %v, want tag v1.0", release)
+			}
+			json.NewEncoder(w).Encode(githubRelease{ID: 1, UploadURL: srv.URL + "/uploads/releases/1/assets{?name,label}"})
+		case "POST /uploads/releases/1/assets":
+			body, _ := io.ReadAll(r.Body)
+			name := r.URL.Query().Get("name")
+			uploads[name] = string(body) + " " + r.URL.Query().Get("label")
+			json.NewEncoder(w).Encode(map[string]string{"browser_download_url": "https://example.com/download/" + name})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	b, err := NewBackend(config.Registry{Backend: config.RegistryGitHub, URL: srv.URL, Repo: "arcana/tarot",
+		TokenEnv: "GITHUB_TOKEN"}, "v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive := testArtifact(t, "tarot-1.0.tar.gz", "archive")
+	url, err := b.Upload(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://example.com/download/tarot-1.0.tar.gz" {
+		t.Errorf("Upload() = %s", url)
+	}
+
+	preview := testArtifact(t, "fool.png", "png")
+	preview.Label = "major_arcana.00"
+	if _, err := b.Upload(preview); err != nil {
+		t.Fatal(err)
+	}
+
+	// The release is created once and its upload URL reused
+	want := []string{
+		"GET /repos/arcana/tarot/releases/tags/v1.0",
+		"POST /repos/arcana/tarot/releases",
+		"POST /uploads/releases/1/assets",
+		"POST /uploads/releases/1/assets",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %s, want %s", i, requests[i], want[i])
+		}
+	}
+	if uploads["tarot-1.0.tar.gz"] != "archive " || uploads["fool.png"] != "png major_arcana.00" {
+		t.Errorf("uploads = %q", uploads)
+	}
+}
+
+func TestGitHubUploadExistingRelease(t *testing.T) {
+	var created bool
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/arcana/tarot/releases/tags/v1.0":
+			json.NewEncoder(w).Encode(githubRelease{ID: 7, UploadURL: srv.URL + "/assets{?name,label}"})
+		case "POST /repos/arcana/tarot/releases":
+			created = true
+		case "POST /assets":
+			json.NewEncoder(w).Encode(map[string]string{"browser_download_url": "https://example.com/asset"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	b, err := NewBackend(config.Registry{Backend: config.RegistryGitHub, URL: srv.URL, Repo: "arcana/tarot",
+		TokenEnv: "GITHUB_TOKEN"}, "v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Upload(testArtifact(t, "tarot-1.0.tar.gz", "archive")); err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Error("created a release that already existed")
+	}
+}
@@ -0,0 +1,94 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/arcanaland/cartomancer/internal/archive"
+	"github.com/arcanaland/cartomancer/internal/deck"
+)
+
+// ManifestVersion is the version of the manifest format written by WriteManifest
+const ManifestVersion = 1
+
+// Manifest lists every file in a deck package with its checksum, so the
+// contents of an archive can be audited and compared across builds
+type Manifest struct {
+	ManifestVersion int            `json:"manifest_version"`
+	ID              string         `json:"id"`
+	Version         string         `json:"version"`
+	License         string         `json:"license,omitempty"`
+	Files           []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file in a deck package. Path is relative to the deck root.
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BuildManifest hashes the files that Package would archive, in archive order
+func BuildManifest(d *deck.Deck) (*Manifest, error) {
+	m := &Manifest{
+		ManifestVersion: ManifestVersion,
+		ID:              d.ID,
+		Version:         d.Version,
+		License:         d.License,
+		Files:           []ManifestFile{},
+	}
+
+	err := archive.Walk(d.Path, func(rel string, entry fs.DirEntry) error {
+		if entry.IsDir() {
+			return nil
+		}
+		size, sum, err := hashFile(filepath.Join(d.Path, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		m.Files = append(m.Files, ManifestFile{Path: rel, Size: size, SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error building manifest: %v", err)
+	}
+
+	return m, nil
+}
+
+// WriteManifest writes the manifest as indented JSON and returns it as an artifact
+func WriteManifest(m *Manifest, outDir string) (Artifact, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Artifact{}, fmt.Errorf("error encoding manifest: %v", err)
+	}
+
+	path := filepath.Join(outDir, fmt.Sprintf("%s-%s.manifest.json", m.ID, m.Version))
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return Artifact{}, fmt.Errorf("error writing manifest: %v", err)
+	}
+
+	return NewArtifact(path, "application/json")
+}
+
+// hashFile returns the size and hex SHA-256 checksum of a file
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
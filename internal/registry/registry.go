@@ -0,0 +1,148 @@
+// Package registry packages decks and publishes them, with metadata describing
+// the package, to a deck registry.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/archive"
+	"github.com/arcanaland/cartomancer/internal/deck"
+)
+
+// MetadataVersion is the version of the metadata format written by WriteMetadata
+const MetadataVersion = 1
+
+// Metadata describes a published deck for registry listings
+type Metadata struct {
+	MetadataVersion int        `json:"metadata_version"`
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Version         string     `json:"version"`
+	Author          string     `json:"author,omitempty"`
+	License         string     `json:"license,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	ContentWarnings []string   `json:"content_warnings,omitempty"` // Given for the deck or any of its cards
+	Cards           int        `json:"cards"`
+	Published       time.Time  `json:"published"`
+	Archive         Artifact   `json:"archive"`
+	Manifest        Artifact   `json:"manifest"`
+	Previews        []Artifact `json:"previews,omitempty"`
+}
+
+// Artifact is a file published alongside a deck
+type Artifact struct {
+	Name        string `json:"name"`
+	URL         string `json:"url,omitempty"` // Set once uploaded
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"content_type"`
+	Label       string `json:"label,omitempty"` // What a preview shows, e.g. a card ID
+
+	path string
+}
+
+// Path returns the local path of the artifact
+func (a *Artifact) Path() string {
+	return a.path
+}
+
+// NewArtifact describes a local file, computing its size and checksum
+func NewArtifact(path, contentType string) (Artifact, error) {
+	a := Artifact{Name: filepath.Base(path), ContentType: contentType, path: path}
+
+	var err error
+	if a.Size, a.SHA256, err = hashFile(path); err != nil {
+		return a, fmt.Errorf("error reading %s: %v", a.Name, err)
+	}
+	return a, nil
+}
+
+// BaseName returns the file name stem shared by a deck's published artifacts
+func BaseName(d *deck.Deck) string {
+	return fmt.Sprintf("%s-%s", d.ID, d.Version)
+}
+
+// Package writes a deck's files to a reproducible .tar.gz archive in outDir,
+// along with a manifest of their checksums, and returns metadata describing the
+// deck, the archive and the manifest
+func Package(d *deck.Deck, outDir string) (*Metadata, error) {
+	if d.ID == "" || d.Version == "" {
+		return nil, fmt.Errorf("deck.toml must declare an id and version to be packaged")
+	}
+	if err := CheckOutDir(d, outDir); err != nil {
+		return nil, err
+	}
+
+	archivePath := filepath.Join(outDir, BaseName(d)+".tar.gz")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("error creating package: %v", err)
+	}
+
+	err = archive.CreateTarGz(file, d.Path, d.ID)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, err := NewArtifact(archivePath, "application/gzip")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := BuildManifest(d)
+	if err != nil {
+		return nil, err
+	}
+	manifestArtifact, err := WriteManifest(manifest, outDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Metadata{
+		MetadataVersion: MetadataVersion,
+		ID:              d.ID,
+		Name:            d.Name,
+		Version:         d.Version,
+		Author:          d.Author,
+		License:         d.License,
+		Description:     d.Description,
+		Tags:            d.Tags,
+		ContentWarnings: d.AllContentWarnings(),
+		Cards:           len(d.Cards()),
+		Published:       time.Now().UTC(),
+		Archive:         artifact,
+		Manifest:        manifestArtifact,
+	}, nil
+}
+
+// WriteMetadata writes the metadata as indented JSON and returns it as an artifact
+func WriteMetadata(m *Metadata, outDir string) (Artifact, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return Artifact{}, fmt.Errorf("error encoding metadata: %v", err)
+	}
+
+	path := filepath.Join(outDir, fmt.Sprintf("%s-%s.json", m.ID, m.Version))
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return Artifact{}, fmt.Errorf("error writing metadata: %v", err)
+	}
+
+	return NewArtifact(path, "application/json")
+}
+
+// CheckOutDir refuses an output directory that is the deck's root or inside
+// it, where the artifacts would be packaged along with the deck the next time
+func CheckOutDir(d *deck.Deck, outDir string) error {
+	if deck.Contains(d.Path, outDir) {
+		return fmt.Errorf("output directory %s is inside the deck; choose one outside it with -o", outDir)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package registry
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/config"
+)
+
+// s3Backend PUTs artifacts into an S3 bucket, signing requests with AWS
+// Signature Version 4. Credentials are read from the standard AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+type s3Backend struct {
+	endpoint     string // Bucket URL without a trailing slash
+	region       string
+	prefix       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+// newS3Backend configures an S3 backend. Buckets on AWS are addressed
+// virtual-host style; custom endpoints use path style, as most S3-compatible
+// services expect.
+func newS3Backend(cfg config.Registry) (*s3Backend, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 registry requires a bucket")
+	}
+
+	b := &s3Backend{
+		region:       cfg.Region,
+		prefix:       cfg.Prefix,
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if b.accessKey == "" || b.secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for s3 registries")
+	}
+	if b.region == "" {
+		b.region = "us-east-1"
+	}
+
+	if cfg.Endpoint != "" {
+		b.endpoint = joinURL(cfg.Endpoint, cfg.Bucket)
+	} else {
+		b.endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", cfg.Bucket, b.region)
+	}
+	return b, nil
+}
+
+// Upload PUTs the artifact to <bucket>/<prefix>/<name>
+func (b *s3Backend) Upload(a *Artifact) (string, error) {
+	key := strings.Trim(joinURL(b.prefix, a.Name), "/")
+	objectURL := b.endpoint + "/" + escapePath(key)
+
+	req, err := newUploadRequest(http.MethodPut, objectURL, a)
+	if err != nil {
+		return "", err
+	}
+	b.sign(req, a.SHA256, time.Now().UTC())
+
+	if _, _, err := send(req); err != nil {
+		return "", err
+	}
+	return objectURL, nil
+}
+
+// sign adds Signature Version 4 authentication headers to a request. The
+// host and every header set on the request are signed.
+func (b *s3Backend) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if b.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.sessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, v := range req.Header {
+		values[strings.ToLower(name)] = strings.Join(v, ",")
+	}
+	headers := make([]string, 0, len(values))
+	for h := range values {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		canonicalHeaders.WriteString(h + ":" + strings.Join(strings.Fields(values[h]), " ") + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + b.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+b.secretKey), date)
+	key = hmacSHA256(key, b.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters sorted by name, as signed
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// escapePath escapes an object key as S3 expects, leaving its slashes
+func escapePath(key string) string {
+	return uriEncode(key, false)
+}
+
+// uriEncode percent-encodes every byte of s but the unreserved characters
+// A-Z, a-z, 0-9, '-', '.', '_' and '~', as Signature Version 4 requires.
+// Slashes are kept unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 computes an HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,116 @@
+// Package report renders readings through user-defined text templates.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Reading is the data made available to reading templates
+type Reading struct {
+	Spread   string // Spread name, e.g. "Celtic Cross"
+	SpreadID string
+	Deck     string
+	Date     time.Time
+	Seed     int64
+	Moon     string // Lunar phase, set when astro_timing is enabled
+	SunSign  string // Sun sign, set when astro_timing is enabled
+	Cards    []Card
+	Patterns []string // Patterns across the cards of readings of five or more
+}
+
+// Card is a card in a reading, together with its position
+type Card struct {
+	Position string
+	Order    int    // 1-based position in the reading
+	Upright  string // The deck author's upright meaning, from the deck's readings
+	*card.Card
+}
+
+// Load parses a template file, making the helper functions available to it
+func Load(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template: %v", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(Funcs()).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %v", err)
+	}
+
+	return tmpl, nil
+}
+
+// Render executes a template with the reading data
+func Render(w io.Writer, tmpl *template.Template, reading *Reading) error {
+	if err := tmpl.Execute(w, reading); err != nil {
+		return fmt.Errorf("error rendering template: %v", err)
+	}
+	return nil
+}
+
+// Funcs returns the helper functions available to reading templates
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"title": title,
+		"join":  strings.Join,
+		"roman": card.ToRoman,
+		"add":   func(a, b int) int { return a + b },
+		"wrap":  wrap,
+		"a":     indefinite,
+		"date":  func(layout string, t time.Time) string { return t.Format(layout) },
+	}
+}
+
+// title capitalizes the first letter of each word
+func title(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// indefinite prefixes a phrase with "a" or "an"
+func indefinite(s string) string {
+	if s == "" {
+		return s
+	}
+	if strings.ContainsRune("aeiouAEIOU", []rune(s)[0]) {
+		return "an " + s
+	}
+	return "a " + s
+}
+
+// wrap wraps text to lines of at most width characters
+func wrap(width int, s string) string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+		} else if line != "" {
+			line += " " + word
+		} else {
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
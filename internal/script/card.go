@@ -0,0 +1,66 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"go.starlark.net/starlark"
+)
+
+// CardEnv exposes a card's fields to expressions
+func CardEnv(c *card.Card) Env {
+	return Env{
+		"id":       c.ID,
+		"name":     c.Name,
+		"type":     c.Type,
+		"number":   c.Number,
+		"suit":     c.Suit,
+		"rank":     c.Rank,
+		"alt_text": c.AltText,
+		"value":    c.Value,
+		"index":    c.Index,
+		"element":  c.Element,
+		"is_court": c.IsCourt,
+		"is_major": c.Type == "major_arcana",
+	}
+}
+
+// cardValue is a card handed to a script, with the fields of CardEnv as
+// read-only attributes
+type cardValue struct {
+	card  *card.Card
+	attrs starlark.StringDict
+}
+
+var _ starlark.HasAttrs = (*cardValue)(nil)
+
+// newCardValue wraps a card for scripts
+func newCardValue(c *card.Card) (*cardValue, error) {
+	attrs, err := CardEnv(c).toStarlark()
+	if err != nil {
+		return nil, err
+	}
+	return &cardValue{card: c, attrs: attrs}, nil
+}
+
+func (v *cardValue) String() string        { return fmt.Sprintf("card(%q)", v.card.ID) }
+func (v *cardValue) Type() string          { return "card" }
+func (v *cardValue) Freeze()               {}
+func (v *cardValue) Truth() starlark.Bool  { return true }
+func (v *cardValue) Hash() (uint32, error) { return starlark.String(v.card.ID).Hash() }
+
+// Attr returns a field of the card, or nil for unknown fields
+func (v *cardValue) Attr(name string) (starlark.Value, error) {
+	return v.attrs[name], nil
+}
+
+// AttrNames lists the fields of the card
+func (v *cardValue) AttrNames() []string {
+	names := make([]string, 0, len(v.attrs))
+	for name := range v.attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,139 @@
+package script
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"go.starlark.net/starlark"
+)
+
+// Program is a .star script, run once when loaded to define its globals
+type Program struct {
+	name    string // File name, for errors
+	globals starlark.StringDict
+}
+
+// Load runs a script file and keeps the globals it defines
+func Load(path string) (*Program, error) {
+	name := filepath.Base(path)
+	globals, err := starlark.ExecFileOptions(fileOptions, newThread(name), path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Program{name: name, globals: globals}, nil
+}
+
+// String returns a string global of the script, or def when it is not set
+func (p *Program) String(name, def string) (string, error) {
+	v, ok := p.globals[name]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(starlark.String)
+	if !ok {
+		return "", fmt.Errorf("%s: %s must be a string, not %s", p.name, name, v.Type())
+	}
+	return string(s), nil
+}
+
+// Defines reports whether the script defines a function
+func (p *Program) Defines(name string) bool {
+	_, ok := p.globals[name].(starlark.Callable)
+	return ok
+}
+
+// call calls a function defined by the script
+func (p *Program) call(name string, args ...starlark.Value) (starlark.Value, error) {
+	fn, ok := p.globals[name].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("%s does not define %s", p.name, name)
+	}
+	v, err := starlark.Call(newThread(p.name), fn, args, nil)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return nil, fmt.Errorf("%s: %s", p.name, evalErr.Backtrace())
+		}
+		return nil, fmt.Errorf("%s: %v", p.name, err)
+	}
+	return v, nil
+}
+
+// Dealt is a card dealt by a script, with the position it was dealt into.
+// Position is empty when the script did not name one.
+type Dealt struct {
+	Position string
+	Card     *card.Card
+}
+
+// Deal calls the script's deal function with a draw function handing out
+// the shuffled cards in order, and None once they run out. deal returns the
+// cards to lay out, each either a card or a (position name, card) pair.
+func (p *Program) Deal(shuffled []*card.Card) ([]Dealt, error) {
+	next := 0
+	draw := starlark.NewBuiltin("draw", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		if next >= len(shuffled) {
+			return starlark.None, nil
+		}
+		next++
+		return newCardValue(shuffled[next-1])
+	})
+
+	v, err := p.call("deal", draw)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := v.(*starlark.List)
+	if !ok {
+		return nil, fmt.Errorf("%s: deal must return a list, not %s", p.name, v.Type())
+	}
+
+	var dealt []Dealt
+	seen := map[*card.Card]bool{}
+	for i := range items.Len() {
+		item := items.Index(i)
+		var d Dealt
+		if pair, ok := item.(starlark.Tuple); ok && len(pair) == 2 {
+			position, ok := pair[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("%s: deal returned a position name that is a %s, not a string", p.name, pair[0].Type())
+			}
+			d.Position = string(position)
+			item = pair[1]
+		}
+		c, ok := item.(*cardValue)
+		if !ok {
+			return nil, fmt.Errorf("%s: deal returned a %s where a card was expected", p.name, item.Type())
+		}
+		if seen[c.card] {
+			return nil, fmt.Errorf("%s: deal returned %s twice", p.name, c.card.ID)
+		}
+		seen[c.card] = true
+		d.Card = c.card
+		dealt = append(dealt, d)
+	}
+	return dealt, nil
+}
+
+// Check calls the script's check function for a card. check returns a
+// message describing the problem with the card, or None when it passes.
+func (p *Program) Check(c *card.Card) (string, error) {
+	arg, err := newCardValue(c)
+	if err != nil {
+		return "", err
+	}
+	v, err := p.call("check", arg)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return "", nil
+	case starlark.String:
+		return string(v), nil
+	}
+	return "", fmt.Errorf("%s: check must return a message or None, not %s", p.name, v.Type())
+}
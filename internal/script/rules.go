@@ -0,0 +1,156 @@
+package script
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Rule is a custom validation rule checked against every card in a deck,
+// defined by expressions in a .toml file or by the check function of a .star
+// script
+type Rule struct {
+	Name     string
+	Severity string // error or warning
+	Cards    *Expr  // Selects the cards the rule applies to; nil selects all
+	Require  *Expr  // Must hold for every selected card
+	Message  string // Reported for failing cards; {field} is replaced by card fields
+
+	script *Program // Checks cards instead of Cards and Require, for .star rules
+}
+
+// rulesFile is the on-disk format of a rules file
+type rulesFile struct {
+	Rules []struct {
+		Name     string `toml:"name"`
+		Severity string `toml:"severity"`
+		Cards    string `toml:"cards"`
+		Require  string `toml:"require"`
+		Message  string `toml:"message"`
+	} `toml:"rule"`
+}
+
+// LoadRules loads validation rules from the .toml and .star files in a
+// directory. A missing directory is not an error.
+func LoadRules(dir string) ([]*Rule, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading rules directory: %v", err)
+	}
+
+	var rules []*Rule
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if filepath.Ext(entry.Name()) == ".star" {
+			rule, err := loadScriptRule(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("error loading rules script %s: %v", entry.Name(), err)
+			}
+			rules = append(rules, rule)
+			continue
+		}
+		if filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		var file rulesFile
+		if _, err := toml.DecodeFile(filepath.Join(dir, entry.Name()), &file); err != nil {
+			return nil, fmt.Errorf("error parsing rules file %s: %v", entry.Name(), err)
+		}
+
+		for i, r := range file.Rules {
+			rule := &Rule{Name: r.Name, Severity: r.Severity, Message: r.Message}
+			if rule.Name == "" {
+				rule.Name = fmt.Sprintf("%s#%d", entry.Name(), i+1)
+			}
+			if rule.Severity == "" {
+				rule.Severity = "error"
+			}
+			if rule.Severity != "error" && rule.Severity != "warning" {
+				return nil, fmt.Errorf("rule %s: severity must be error or warning", rule.Name)
+			}
+			if r.Require == "" {
+				return nil, fmt.Errorf("rule %s: require is missing", rule.Name)
+			}
+			if rule.Require, err = Parse(r.Require); err != nil {
+				return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+			}
+			if r.Cards != "" {
+				if rule.Cards, err = Parse(r.Cards); err != nil {
+					return nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// loadScriptRule loads a rule from a script defining check(card), and
+// optionally the name and severity globals
+func loadScriptRule(path string) (*Rule, error) {
+	p, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Defines("check") {
+		return nil, fmt.Errorf("script does not define check(card)")
+	}
+
+	rule := &Rule{script: p}
+	if rule.Name, err = p.String("name", strings.TrimSuffix(filepath.Base(path), ".star")); err != nil {
+		return nil, err
+	}
+	if rule.Severity, err = p.String("severity", "error"); err != nil {
+		return nil, err
+	}
+	if rule.Severity != "error" && rule.Severity != "warning" {
+		return nil, fmt.Errorf("rule %s: severity must be error or warning", rule.Name)
+	}
+	return rule, nil
+}
+
+// Check evaluates the rule for a card. It returns a message when the card is
+// selected by the rule but does not satisfy it, and "" otherwise.
+func (r *Rule) Check(c *card.Card) (string, error) {
+	if r.script != nil {
+		message, err := r.script.Check(c)
+		if err != nil || message == "" {
+			return "", err
+		}
+		return fmt.Sprintf("rule %s: %s", r.Name, message), nil
+	}
+
+	env := CardEnv(c)
+
+	if r.Cards != nil {
+		selected, err := r.Cards.EvalBool(env)
+		if err != nil || !selected {
+			return "", err
+		}
+	}
+
+	ok, err := r.Require.EvalBool(env)
+	if err != nil || ok {
+		return "", err
+	}
+
+	message := r.Message
+	if message == "" {
+		message = fmt.Sprintf("{id} does not satisfy %s", r.Require)
+	}
+	for key, value := range env {
+		message = strings.ReplaceAll(message, "{"+key+"}", fmt.Sprint(value))
+	}
+
+	return fmt.Sprintf("rule %s: %s", r.Name, message), nil
+}
@@ -0,0 +1,101 @@
+// Package script runs the Starlark code of custom spreads and validation
+// rules: single expressions written in their .toml files, and .star scripts
+// defining functions. Starlark is a small dialect of Python. Code runs in a
+// sandbox: it can only use the values it is given and Starlark's built-in
+// functions, cannot load modules or reach files, the network or the clock,
+// and is stopped after maxSteps computation steps.
+package script
+
+import (
+	"fmt"
+	"os"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// maxSteps bounds the computation of a single expression or function call,
+// so scripts that loop forever are stopped
+const maxSteps = 1_000_000
+
+// fileOptions are the Starlark dialect accepted: while loops and top-level
+// statements are allowed, as runaway loops are caught by maxSteps
+var fileOptions = &syntax.FileOptions{
+	Set:             true,
+	While:           true,
+	TopLevelControl: true,
+}
+
+// Env holds the variables visible to an expression. Values must be strings,
+// ints, float64 numbers, booleans or cards.
+type Env map[string]interface{}
+
+// newThread returns a thread for running code, with output from print sent
+// to stderr and no way to load other modules
+func newThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(thread *starlark.Thread, msg string) {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", thread.Name, msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxSteps)
+	return thread
+}
+
+// toStarlark converts an environment to Starlark values
+func (env Env) toStarlark() (starlark.StringDict, error) {
+	dict := make(starlark.StringDict, len(env))
+	for name, v := range env {
+		switch v := v.(type) {
+		case string:
+			dict[name] = starlark.String(v)
+		case int:
+			dict[name] = starlark.MakeInt(v)
+		case float64:
+			dict[name] = starlark.Float(v)
+		case bool:
+			dict[name] = starlark.Bool(v)
+		case starlark.Value:
+			dict[name] = v
+		default:
+			return nil, fmt.Errorf("variable %s has unsupported type %T", name, v)
+		}
+	}
+	return dict, nil
+}
+
+// Expr is a parsed expression
+type Expr struct {
+	src string
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Parse parses an expression such as: type == "major_arcana" or value > 10
+func Parse(src string) (*Expr, error) {
+	if _, err := fileOptions.ParseExpr("expression", src, 0); err != nil {
+		return nil, fmt.Errorf("error parsing %q: %v", src, err)
+	}
+	return &Expr{src: src}, nil
+}
+
+// EvalBool evaluates an expression that must produce a boolean
+func (e *Expr) EvalBool(env Env) (bool, error) {
+	globals, err := env.toStarlark()
+	if err != nil {
+		return false, err
+	}
+	v, err := starlark.EvalOptions(fileOptions, newThread("expression"), "expression", e.src, globals)
+	if err != nil {
+		return false, fmt.Errorf("error evaluating %q: %v", e.src, err)
+	}
+	b, ok := v.(starlark.Bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q is not a condition (got %s)", e.src, v.Type())
+	}
+	return bool(b), nil
+}
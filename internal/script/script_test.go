@@ -0,0 +1,285 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"type ==",
+		"(is_major",
+		"is_major && is_court",
+		"value = 3",
+		"def f(): pass",
+	} {
+		if _, err := Parse(src); err == nil || !strings.Contains(err.Error(), "error parsing") {
+			t.Errorf("Parse(%q) = %v, want a parse error", src, err)
+		}
+	}
+}
+
+func TestEvalBool(t *testing.T) {
+	queen := card.NewMinorArcana("cups", "queen")
+	queen.Name = "Queen of Cups"
+	queen.AltText = "A queen on a throne by the sea"
+	fool := card.NewMajorArcana(0)
+
+	tests := []struct {
+		src  string
+		card *card.Card
+		want bool
+	}{
+		{`type == "major_arcana"`, fool, true},
+		{`is_court and value > 10`, queen, true},
+		{`is_court and value > 10`, fool, false},
+		// not binds tighter than and, which binds tighter than or
+		{`not is_major or suit == "wands"`, fool, false},
+		{`is_major or is_court and suit == "wands"`, queen, false},
+		{`(is_major or is_court) and suit == "cups"`, queen, true},
+		{`value + 1 * 2 == 15`, queen, true},
+		{`len(alt_text) > 0`, queen, true},
+		{`len(alt_text) == 0`, fool, true},
+		{`name.startswith("Queen")`, queen, true},
+		{`"throne" in alt_text.lower()`, queen, true},
+		{`rank in ("king", "queen")`, queen, true},
+		{`index == 0 and number == "00"`, fool, true},
+		{`drawn >= 7`, fool, true},
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		env := CardEnv(tt.card)
+		env["drawn"] = 7
+		got, err := e.EvalBool(env)
+		if err != nil {
+			t.Errorf("%q on %s: %v", tt.src, tt.card.ID, err)
+		} else if got != tt.want {
+			t.Errorf("%q on %s = %v, want %v", tt.src, tt.card.ID, got, tt.want)
+		}
+	}
+}
+
+func TestEvalBoolErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`name`, "not a condition"},
+		{`value`, "not a condition"},
+		{`name + value`, "unknown binary op"},
+		{`unknown == 1`, "undefined: unknown"},
+		{`name.nope()`, "has no .nope field or method"},
+		{`len([x for x in range(10000000)]) > 0`, "too many steps"},
+	}
+	env := CardEnv(card.NewMajorArcana(1))
+	for _, tt := range tests {
+		e, err := Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		if _, err := e.EvalBool(env); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want an error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// writeScript writes a script to a temporary directory and returns its path
+func writeScript(t *testing.T, dir, name, src string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSandbox(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`load("other.star", "x")`, "load not implemented"},
+		{`data = open("/etc/passwd")`, "undefined: open"},
+		{"while True:\n    pass", "too many steps"},
+		{"def f(n):\n    return f(n)\nf(1)", "called recursively"},
+	}
+	for i, tt := range tests {
+		_, err := Load(writeScript(t, dir, "s.star", tt.src))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("script %d: got %v, want an error containing %q", i, err, tt.want)
+		}
+	}
+}
+
+func TestDeal(t *testing.T) {
+	cards := []*card.Card{
+		card.NewMajorArcana(3),
+		card.NewMinorArcana("cups", "two"),
+		card.NewMinorArcana("wands", "knight"),
+		card.NewMinorArcana("swords", "ace"),
+	}
+	p, err := Load(writeScript(t, t.TempDir(), "court.star", `
+def deal(draw):
+    cards = [("Question", draw())]
+    card = draw()
+    while card:
+        cards.append(card)
+        if card.is_court:
+            break
+        card = draw()
+    return cards
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dealt, err := p.Deal(cards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Dealt{{"Question", cards[0]}, {"", cards[1]}, {"", cards[2]}}
+	if len(dealt) != len(want) {
+		t.Fatalf("dealt %v, want %v", dealt, want)
+	}
+	for i := range want {
+		if dealt[i] != want[i] {
+			t.Errorf("card %d = %v, want %v", i, dealt[i], want[i])
+		}
+	}
+
+	// Running out of cards gives None rather than failing
+	p, err = Load(writeScript(t, t.TempDir(), "all.star", `
+def deal(draw):
+    cards = []
+    for i in range(100):
+        card = draw()
+        if card == None:
+            break
+        cards.append(card)
+    return cards
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dealt, err := p.Deal(cards); err != nil || len(dealt) != len(cards) {
+		t.Errorf("dealt %d cards (%v), want %d", len(dealt), err, len(cards))
+	}
+}
+
+func TestDealErrors(t *testing.T) {
+	cards := []*card.Card{card.NewMajorArcana(0), card.NewMajorArcana(1)}
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"def deal(draw):\n    return draw()", "must return a list"},
+		{"def deal(draw):\n    return [draw().name]", "where a card was expected"},
+		{"def deal(draw):\n    return [(1, draw())]", "not a string"},
+		{"def deal(draw):\n    c = draw()\n    return [c, c]", "major_arcana.00 twice"},
+		{"def deal(draw):\n    return [draw(1)]", "got 1 arguments, want 0"},
+		{"def deal(draw):\n    return [draw().name.upper() + 1]", "unknown binary op"},
+		{"x = 1", "does not define deal"},
+	}
+	for _, tt := range tests {
+		p, err := Load(writeScript(t, t.TempDir(), "bad.star", tt.src))
+		if err != nil {
+			t.Fatalf("Load(%q): %v", tt.src, err)
+		}
+		if _, err := p.Deal(cards); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %v, want an error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, dir, "alt.toml", `
+[[rule]]
+name = "major-alt-text"
+severity = "warning"
+cards = 'type == "major_arcana"'
+require = 'alt_text != ""'
+message = "{id} ({name}) has no alt text"
+`)
+	writeScript(t, dir, "courts.star", `
+name = "court-rank"
+
+def check(card):
+    if card.is_court and card.rank not in card.alt_text.lower():
+        return "%s does not mention its rank" % card.id
+`)
+	writeScript(t, dir, "notes.txt", "not a rule")
+
+	rules, err := LoadRules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("loaded %d rules, want 2", len(rules))
+	}
+
+	fool := card.NewMajorArcana(0)
+	fool.Name = "The Fool"
+	queen := card.NewMinorArcana("cups", "queen")
+	queen.AltText = "A woman holding a cup"
+	tests := []struct {
+		card *card.Card
+		want []string
+	}{
+		{fool, []string{"rule major-alt-text: major_arcana.00 (The Fool) has no alt text"}},
+		{queen, []string{"rule court-rank: minor_arcana.cups.queen does not mention its rank"}},
+		{card.NewMinorArcana("cups", "two"), nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, rule := range rules {
+			message, err := rule.Check(tt.card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if message != "" {
+				got = append(got, message)
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got %q, want %q", tt.card.ID, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRulesErrors(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"a.star", "x = 1", "does not define check"},
+		{"a.star", "severity = \"fatal\"\ndef check(card):\n    pass", "severity must be error or warning"},
+		{"a.star", "name = 3\ndef check(card):\n    pass", "name must be a string"},
+		{"a.toml", "[[rule]]\ncards = 'is_major'", "require is missing"},
+		{"a.toml", "[[rule]]\nrequire = 'alt_text !='", "error parsing"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeScript(t, dir, tt.name, tt.src)
+		if _, err := LoadRules(dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s %q: got %v, want an error containing %q", tt.name, tt.src, err, tt.want)
+		}
+	}
+
+	dir := t.TempDir()
+	writeScript(t, dir, "a.star", "def check(card):\n    return 1")
+	rules, err := LoadRules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rules[0].Check(card.NewMajorArcana(0)); err == nil || !strings.Contains(err.Error(), "must return a message or None") {
+		t.Errorf("check returning an int: got %v", err)
+	}
+}
@@ -0,0 +1,348 @@
+// Package session holds the state of live reading sessions shared by several
+// participants, such as remote readings over the serve command's WebSocket
+// endpoint. Sessions live in memory and end when their last participant leaves.
+package session
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/pool"
+	"github.com/arcanaland/cartomancer/internal/spread"
+)
+
+// eventBuffer is the number of events queued for a participant before they
+// are dropped from the session as too slow
+const eventBuffer = 64
+
+// MaxDraw bounds the cards revealed by a single draw, spreads included, well
+// below eventBuffer so a draw never fills a participant's queue on its own
+const MaxDraw = 22
+
+// MaxParticipants bounds the participants of a session, each of whom costs
+// the server an event queue and a connection
+const MaxParticipants = 32
+
+// MaxNameLength bounds the length of participant names, in characters
+const MaxNameLength = 64
+
+// Event types sent to participants
+const (
+	EventState    = "state"    // The session state, sent on joining
+	EventJoined   = "joined"   // A participant joined
+	EventLeft     = "left"     // A participant left
+	EventShuffled = "shuffled" // The cards were shuffled
+	EventReveal   = "reveal"   // A card was drawn
+	EventError    = "error"    // A request from this participant failed
+	EventEnded    = "ended"    // The server is shutting down
+)
+
+// Reveal is a card drawn in a session
+type Reveal struct {
+	Index    int    `json:"index"`
+	Position string `json:"position,omitempty"`
+	Card     string `json:"card"`
+	Name     string `json:"name"`
+	By       string `json:"by,omitempty"`
+}
+
+// Event is a message sent to the participants of a session
+type Event struct {
+	Type         string   `json:"type"`
+	Session      string   `json:"session,omitempty"`
+	Deck         string   `json:"deck,omitempty"`
+	By           string   `json:"by,omitempty"`
+	Participants []string `json:"participants,omitempty"`
+	Pool         string   `json:"pool,omitempty"`
+	Seed         int64    `json:"seed,omitempty"`
+	Remaining    *int     `json:"remaining,omitempty"`
+	Reveal       *Reveal  `json:"reveal,omitempty"`
+	Reveals      []Reveal `json:"reveals,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// Participant is a member of a session. Events for the participant are
+// delivered on Events, which is closed when the participant leaves.
+type Participant struct {
+	Name   string
+	Events chan Event
+}
+
+// Session is a shared reading: one shuffled pool of cards that every
+// participant draws from and sees revealed
+type Session struct {
+	ID   string
+	Deck *deck.Deck
+
+	hub          *Hub
+	mu           sync.Mutex
+	participants []*Participant
+	ended        bool
+	pool         string
+	seed         int64
+	remaining    []*card.Card
+	reveals      []Reveal
+}
+
+// Hub tracks the live sessions
+type Hub struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewHub returns a hub with no sessions
+func NewHub() *Hub {
+	return &Hub{sessions: map[string]*Session{}}
+}
+
+// Len returns the number of live sessions
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.sessions)
+}
+
+// Close ends every session, telling participants the server is going away.
+// Their event channels are closed after the final event.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	sessions := make([]*Session, 0, len(h.sessions))
+	for _, s := range h.sessions {
+		sessions = append(sessions, s)
+	}
+	h.mu.Unlock()
+
+	for _, s := range sessions {
+		s.mu.Lock()
+		s.broadcast(Event{Type: EventEnded})
+		for len(s.participants) > 0 {
+			s.remove(s.participants[0])
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Join adds a participant to a session, starting the session with a freshly
+// shuffled deck if it does not exist yet. Joining an existing session with a
+// different deck, joining a full session and names longer than
+// MaxNameLength are errors.
+func (h *Hub) Join(id string, d *deck.Deck, name string) (*Session, *Participant, error) {
+	if utf8.RuneCountInString(name) > MaxNameLength {
+		return nil, nil, fmt.Errorf("name is longer than %d characters", MaxNameLength)
+	}
+
+	for {
+		h.mu.Lock()
+		s, ok := h.sessions[id]
+		if !ok {
+			s = &Session{ID: id, Deck: d, hub: h}
+			if err := s.shuffle("", time.Now().UnixNano()); err != nil {
+				h.mu.Unlock()
+				return nil, nil, err
+			}
+			h.sessions[id] = s
+		}
+		h.mu.Unlock()
+
+		if s.Deck.Path != d.Path {
+			return nil, nil, fmt.Errorf("session %s uses deck %s", id, s.Deck.ID)
+		}
+
+		s.mu.Lock()
+		if s.ended {
+			// The last participant left after the lookup; start over
+			s.mu.Unlock()
+			continue
+		}
+		if len(s.participants) >= MaxParticipants {
+			s.mu.Unlock()
+			return nil, nil, fmt.Errorf("session %s is full (limit %d participants)", id, MaxParticipants)
+		}
+
+		p := &Participant{Name: name, Events: make(chan Event, eventBuffer)}
+		s.participants = append(s.participants, p)
+		s.send(p, s.state())
+		s.broadcast(Event{Type: EventJoined, By: name, Participants: s.names()})
+		s.mu.Unlock()
+		return s, p, nil
+	}
+}
+
+// Leave removes a participant, ending the session when nobody is left
+func (s *Session) Leave(p *Participant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.remove(p) {
+		s.broadcast(Event{Type: EventLeft, By: p.Name, Participants: s.names()})
+	}
+}
+
+// Shuffle gathers all cards of a pool (the full deck by default) and shuffles
+// them, clearing the cards drawn so far. A zero seed picks a random one.
+func (s *Session) Shuffle(p *Participant, poolName string, seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.shuffle(poolName, seed); err != nil {
+		s.send(p, Event{Type: EventError, Error: err.Error()})
+		return
+	}
+
+	remaining := len(s.remaining)
+	s.broadcast(Event{Type: EventShuffled, By: p.Name, Pool: s.pool, Seed: seed, Remaining: &remaining})
+}
+
+// Draw reveals the next cards to every participant: count cards, or one
+// card for each position of a spread when spreadID is given, up to MaxDraw
+func (s *Session) Draw(p *Participant, count int, spreadID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var positions []string
+	if spreadID != "" {
+		sp, err := spread.Get(spreadID)
+		if err != nil {
+			s.send(p, Event{Type: EventError, Error: err.Error()})
+			return
+		}
+		draws, err := sp.DealFrom(s.remaining)
+		if err != nil {
+			s.send(p, Event{Type: EventError, Error: err.Error()})
+			return
+		}
+		for _, d := range draws {
+			positions = append(positions, d.Position.Name)
+		}
+		count = len(draws)
+	}
+
+	if count < 1 {
+		count = 1
+	}
+	if count > MaxDraw {
+		s.send(p, Event{Type: EventError, Error: fmt.Sprintf("cannot draw %d cards at once (limit %d)", count, MaxDraw)})
+		return
+	}
+	if count > len(s.remaining) {
+		s.send(p, Event{Type: EventError,
+			Error: fmt.Sprintf("cannot draw %d cards, %d remain (shuffle to start again)", count, len(s.remaining))})
+		return
+	}
+
+	for i, c := range s.remaining[:count] {
+		r := Reveal{Index: len(s.reveals) + 1, Card: c.ID, Name: c.Name, By: p.Name}
+		if positions != nil {
+			r.Position = positions[i]
+		}
+		s.reveals = append(s.reveals, r)
+
+		remaining := len(s.remaining) - i - 1
+		s.broadcast(Event{Type: EventReveal, By: p.Name, Reveal: &r, Remaining: &remaining})
+	}
+	s.remaining = s.remaining[count:]
+}
+
+// shuffle replaces the remaining cards with a shuffled pool
+func (s *Session) shuffle(poolName string, seed int64) error {
+	if poolName == "" {
+		poolName = "full"
+	}
+	pl, err := pool.Lookup(poolName, s.Deck.SuitNames())
+	if err != nil {
+		return err
+	}
+	cards, err := pl.Select(s.Deck.Cards())
+	if err != nil {
+		return err
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	if pl.Weighted() {
+		cards = pl.Shuffle(cards, rng)
+	} else {
+		rng.Shuffle(len(cards), func(i, j int) {
+			cards[i], cards[j] = cards[j], cards[i]
+		})
+	}
+
+	s.pool, s.seed = pl.ID, seed
+	s.remaining = cards
+	s.reveals = nil
+	return nil
+}
+
+// state describes the session for a participant who just joined
+func (s *Session) state() Event {
+	remaining := len(s.remaining)
+	return Event{
+		Type:         EventState,
+		Session:      s.ID,
+		Deck:         s.Deck.ID,
+		Participants: s.names(),
+		Pool:         s.pool,
+		Seed:         s.seed,
+		Remaining:    &remaining,
+		Reveals:      append([]Reveal(nil), s.reveals...),
+	}
+}
+
+// names returns the sorted names of the participants
+func (s *Session) names() []string {
+	names := make([]string, len(s.participants))
+	for i, p := range s.participants {
+		names[i] = p.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// broadcast sends an event to every participant
+func (s *Session) broadcast(e Event) {
+	for _, p := range append([]*Participant(nil), s.participants...) {
+		s.send(p, e)
+	}
+}
+
+// send queues an event for a participant, dropping participants whose queue
+// is full rather than stalling the session
+func (s *Session) send(p *Participant, e Event) {
+	select {
+	case p.Events <- e:
+	default:
+		s.remove(p)
+	}
+}
+
+// remove takes a participant out of the session, reporting whether it was in
+// it. The session is ended when it becomes empty.
+func (s *Session) remove(p *Participant) bool {
+	for i, other := range s.participants {
+		if other != p {
+			continue
+		}
+		s.participants = append(s.participants[:i], s.participants[i+1:]...)
+		close(p.Events)
+
+		if len(s.participants) == 0 {
+			s.ended = true
+			s.hub.mu.Lock()
+			if s.hub.sessions[s.ID] == s {
+				delete(s.hub.sessions, s.ID)
+			}
+			s.hub.mu.Unlock()
+		}
+		return true
+	}
+	return false
+}
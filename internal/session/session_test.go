@@ -0,0 +1,253 @@
+package session
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/testutil"
+)
+
+// fixtureDeck loads the fixture deck
+func fixtureDeck(t *testing.T) *deck.Deck {
+	t.Helper()
+	d, err := deck.LoadDeck(testutil.FixtureDeck(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// next returns the next queued event of a participant
+func next(t *testing.T, p *Participant) Event {
+	t.Helper()
+	select {
+	case e, ok := <-p.Events:
+		if !ok {
+			t.Fatalf("events of %s closed", p.Name)
+		}
+		return e
+	default:
+		t.Fatalf("no event queued for %s", p.Name)
+		return Event{}
+	}
+}
+
+// drain discards the queued events of a participant, reporting whether its
+// channel was closed
+func drain(p *Participant) bool {
+	for {
+		select {
+		case _, ok := <-p.Events:
+			if !ok {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+}
+
+func TestJoinAndDraw(t *testing.T) {
+	d := fixtureDeck(t)
+	h := NewHub()
+
+	sess, ana, err := h.Join("table", d, "ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, ana); e.Type != EventState || e.Session != "table" || *e.Remaining != len(d.Cards()) {
+		t.Errorf("first event = %+v, want the session state", e)
+	}
+	drain(ana)
+
+	_, ben, err := h.Join("table", d, "ben")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, ana); e.Type != EventJoined || e.By != "ben" || !slices.Equal(e.Participants, []string{"ana", "ben"}) {
+		t.Errorf("ana got %+v, want ben joining", e)
+	}
+	drain(ben)
+
+	sess.Shuffle(ben, "", 42)
+	sess.Draw(ben, 2, "")
+	for _, p := range []*Participant{ana, ben} {
+		if e := next(t, p); e.Type != EventShuffled || e.Seed != 42 {
+			t.Errorf("%s got %+v, want a shuffle", p.Name, e)
+		}
+		for i := 1; i <= 2; i++ {
+			if e := next(t, p); e.Type != EventReveal || e.Reveal.Index != i || e.Reveal.By != "ben" {
+				t.Errorf("%s got %+v, want reveal %d", p.Name, e, i)
+			}
+		}
+	}
+
+	// Later participants see the cards revealed so far
+	_, cleo, err := h.Join("table", d, "cleo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, cleo); len(e.Reveals) != 2 || *e.Remaining != len(d.Cards())-2 {
+		t.Errorf("cleo joined with %d reveals and %d cards remaining", len(e.Reveals), *e.Remaining)
+	}
+	drain(cleo)
+
+	sess.Draw(cleo, MaxDraw+1, "")
+	if e := next(t, cleo); e.Type != EventError || !strings.Contains(e.Error, "limit 22") {
+		t.Errorf("drawing too many cards: got %+v", e)
+	}
+}
+
+func TestJoinLimits(t *testing.T) {
+	d := fixtureDeck(t)
+	h := NewHub()
+
+	if _, _, err := h.Join("table", d, strings.Repeat("é", MaxNameLength+1)); err == nil {
+		t.Error("joined with a name longer than MaxNameLength")
+	}
+	if _, _, err := h.Join("table", d, strings.Repeat("é", MaxNameLength)); err != nil {
+		t.Errorf("joining with a name of MaxNameLength: %v", err)
+	}
+
+	for i := 1; i < MaxParticipants; i++ {
+		if _, _, err := h.Join("table", d, fmt.Sprint("guest", i)); err != nil {
+			t.Fatalf("participant %d: %v", i+1, err)
+		}
+	}
+	if _, _, err := h.Join("table", d, "late"); err == nil || !strings.Contains(err.Error(), "is full") {
+		t.Errorf("joining a full session: got %v", err)
+	}
+
+	other := fixtureDeck(t)
+	if _, _, err := h.Join("table", other, "ana"); err == nil || !strings.Contains(err.Error(), "uses deck") {
+		t.Errorf("joining with another deck: got %v", err)
+	}
+}
+
+func TestSlowParticipantDropped(t *testing.T) {
+	d := fixtureDeck(t)
+	h := NewHub()
+
+	sess, ana, err := h.Join("table", d, "ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, slow, err := h.Join("table", d, "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ana keeps up with the events while slow never reads any
+	for range eventBuffer {
+		sess.Shuffle(ana, "", 1)
+		drain(ana)
+	}
+
+	if !drain(slow) {
+		t.Fatal("slow participant was not dropped")
+	}
+	if names := sess.names(); !slices.Equal(names, []string{"ana"}) {
+		t.Errorf("participants = %v, want [ana]", names)
+	}
+
+	// Leaving after being dropped is harmless
+	sess.Leave(slow)
+	if h.Len() != 1 {
+		t.Errorf("hub has %d sessions, want 1", h.Len())
+	}
+}
+
+func TestLeaveEndsSession(t *testing.T) {
+	d := fixtureDeck(t)
+	h := NewHub()
+
+	sess, ana, err := h.Join("table", d, "ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess.Draw(ana, 3, "")
+	sess.Leave(ana)
+	if !drain(ana) {
+		t.Error("events of a participant who left are still open")
+	}
+	if h.Len() != 0 {
+		t.Fatalf("hub has %d sessions after the last participant left, want 0", h.Len())
+	}
+
+	// Joining again starts a fresh session
+	again, ben, err := h.Join("table", d, "ben")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == sess {
+		t.Error("joined the ended session")
+	}
+	if e := next(t, ben); len(e.Reveals) != 0 {
+		t.Errorf("fresh session has %d reveals", len(e.Reveals))
+	}
+}
+
+func TestJoinLeaveRace(t *testing.T) {
+	d := fixtureDeck(t)
+	h := NewHub()
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				sess, p, err := h.Join(fmt.Sprint("table", j%3), d, fmt.Sprint("guest", i))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				sess.Draw(p, 1, "")
+				if j%10 == 0 {
+					sess.Shuffle(p, "", int64(j))
+				}
+				drain(p)
+				sess.Leave(p)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if h.Len() != 0 {
+		t.Errorf("hub has %d sessions after everyone left, want 0", h.Len())
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	d := fixtureDeck(t)
+	h := NewHub()
+
+	var participants []*Participant
+	for _, id := range []string{"one", "one", "two"} {
+		_, p, err := h.Join(id, d, "guest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		participants = append(participants, p)
+	}
+	for _, p := range participants {
+		drain(p)
+	}
+
+	h.Close()
+	for i, p := range participants {
+		if e := next(t, p); e.Type != EventEnded {
+			t.Errorf("participant %d got %+v, want the session ending", i, e)
+		}
+		if !drain(p) {
+			t.Errorf("events of participant %d are still open", i)
+		}
+	}
+	if h.Len() != 0 {
+		t.Errorf("hub has %d sessions after closing, want 0", h.Len())
+	}
+}
@@ -0,0 +1,46 @@
+// Package singleflight collapses concurrent calls for the same key into one,
+// so goroutines asking for the same card render at once share a single render.
+package singleflight
+
+import "sync"
+
+// call is an in-flight or completed call
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Group runs functions by key, at most one per key at a time. The zero value
+// is ready to use.
+type Group[V any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[V]
+}
+
+// Do runs fn for key and returns its result. Callers that arrive while fn is
+// running for the same key wait for it and share its result; shared reports
+// whether the result came from another caller's run.
+func (g *Group[V]) Do(key string, fn func() (V, error)) (value V, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call[V]{}
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.value, c.err, true
+	}
+	c := &call[V]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = fn()
+	return c.value, c.err, false
+}
@@ -0,0 +1,70 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoSharesConcurrentCalls(t *testing.T) {
+	var g Group[string]
+	var runs atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make([]string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, _ := g.Do("major_arcana.00", func() (string, error) {
+				runs.Add(1)
+				<-release
+				return "art", nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}()
+	}
+
+	// Give every caller time to join the in-flight call
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := runs.Load(); n != 1 {
+		t.Errorf("fn ran %d times, want 1", n)
+	}
+	for i, v := range results {
+		if v != "art" {
+			t.Errorf("caller %d got %q, want %q", i, v, "art")
+		}
+	}
+}
+
+func TestDoRunsAgainAfterCompletion(t *testing.T) {
+	var g Group[int]
+	runs := 0
+	for i := 0; i < 3; i++ {
+		v, err, shared := g.Do("key", func() (int, error) {
+			runs++
+			return runs, nil
+		})
+		if err != nil || shared || v != i+1 {
+			t.Errorf("Do #%d = %d, %v, shared %t", i+1, v, err, shared)
+		}
+	}
+}
+
+func TestDoSharesErrors(t *testing.T) {
+	var g Group[int]
+	failed := errors.New("decode failed")
+	if _, err, _ := g.Do("key", func() (int, error) { return 0, failed }); err != failed {
+		t.Errorf("error = %v, want %v", err, failed)
+	}
+}
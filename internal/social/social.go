@@ -0,0 +1,321 @@
+// Package social renders banner images of decks for registry listings, social
+// previews and repository READMEs.
+package social
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/composite"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/nfnt/resize"
+)
+
+// Template describes the size, colors and card fan of a banner
+type Template struct {
+	ID         string   `toml:"id"`
+	Name       string   `toml:"name"`
+	Width      int      `toml:"width"`
+	Height     int      `toml:"height"`
+	Background string   `toml:"background"` // Hex colors such as "#181424"
+	Foreground string   `toml:"foreground"`
+	Accent     string   `toml:"accent"` // Defaults to the deck's style accent
+	Cards      []string `toml:"cards"`  // Sample cards in any accepted ID notation
+	Fan        float64  `toml:"fan"`    // Total fan angle in degrees
+}
+
+// defaultCards is the sample fanned out when a template does not list cards
+var defaultCards = []string{"0", "1", "2", "17", "19", "21"}
+
+// builtinTemplates holds the predefined templates, sized for common destinations
+var builtinTemplates = map[string]*Template{
+	"og":     {ID: "og", Name: "OpenGraph preview", Width: 1200, Height: 630},
+	"github": {ID: "github", Name: "GitHub social preview", Width: 1280, Height: 640},
+	"banner": {ID: "banner", Name: "Wide README banner", Width: 1500, Height: 500},
+}
+
+// customTemplates holds templates loaded from the user's templates directory
+var customTemplates = map[string]*Template{}
+
+// Get returns a built-in or custom template by ID
+func Get(id string) (*Template, error) {
+	if t, ok := builtinTemplates[id]; ok {
+		return t, nil
+	}
+	if t, ok := customTemplates[id]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown template: %s (available: %s)", id, strings.Join(Names(), ", "))
+}
+
+// Names returns the IDs of all built-in and custom templates, sorted
+func Names() []string {
+	names := make([]string, 0, len(builtinTemplates)+len(customTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	for name := range customTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadDir loads custom templates from the .toml files in a directory. A missing
+// directory is not an error. Custom templates cannot replace built-in ones.
+func LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading social templates directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+
+		t, err := loadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error loading social template %s: %v", entry.Name(), err)
+		}
+
+		if _, ok := builtinTemplates[t.ID]; ok {
+			return fmt.Errorf("template %s in %s clashes with a built-in template", t.ID, entry.Name())
+		}
+		customTemplates[t.ID] = t
+	}
+
+	return nil
+}
+
+// loadFile parses and validates a custom template file
+func loadFile(path string) (*Template, error) {
+	var t Template
+	if _, err := toml.DecodeFile(path, &t); err != nil {
+		return nil, err
+	}
+
+	if t.ID == "" {
+		t.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if t.Name == "" {
+		t.Name = t.ID
+	}
+	if t.Width <= 0 || t.Height <= 0 || t.Width > 4096 || t.Height > 4096 {
+		return nil, fmt.Errorf("width and height must be between 1 and 4096")
+	}
+
+	for key, value := range map[string]string{"background": t.Background, "foreground": t.Foreground, "accent": t.Accent} {
+		if value == "" {
+			continue
+		}
+		if _, err := deck.ParseHexColor(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	for _, id := range t.Cards {
+		if _, err := card.ParseID(id); err != nil {
+			return nil, fmt.Errorf("cards: %v", err)
+		}
+	}
+
+	return &t, nil
+}
+
+// Content is what a banner shows
+type Content struct {
+	Title   string
+	Details []string      // Short lines drawn beneath the title
+	Icon    image.Image   // Optional
+	Cards   []image.Image // Fanned out across the right of the banner
+	Accent  color.Color   // Optional; overridden by the template's accent
+}
+
+// colorOr parses a hex color, returning fallback when it is empty
+func colorOr(hex string, fallback color.Color) color.Color {
+	if c, err := deck.ParseHexColor(hex); err == nil {
+		return c
+	}
+	return fallback
+}
+
+// Render draws a banner for the content with the template
+func (t *Template) Render(content Content) *image.RGBA {
+	background := colorOr(t.Background, color.RGBA{R: 24, G: 20, B: 36, A: 255})
+	foreground := colorOr(t.Foreground, color.RGBA{R: 230, G: 224, B: 240, A: 255})
+	accent := content.Accent
+	if accent == nil {
+		accent = color.RGBA{R: 186, G: 140, B: 255, A: 255}
+	}
+	accent = colorOr(t.Accent, accent)
+
+	canvas := image.NewRGBA(image.Rect(0, 0, t.Width, t.Height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	margin := t.Height / 10
+	textWidth := t.Width/2 - margin
+
+	// Accent rule along the left edge
+	draw.Draw(canvas, image.Rect(0, 0, max(t.Height/60, 2), t.Height), image.NewUniform(accent), image.Point{}, draw.Src)
+
+	titleScale := max(t.Height/70, 1)
+	for titleScale > 1 && composite.TextWidth(content.Title, titleScale) > textWidth {
+		titleScale--
+	}
+	detailScale := max(titleScale/2, 1)
+	lineGap := composite.TextHeight(detailScale)
+
+	var icon image.Image
+	iconHeight := 0
+	if content.Icon != nil {
+		size := t.Height / 5
+		icon = resize.Thumbnail(uint(size), uint(size), content.Icon, resize.Lanczos3)
+		iconHeight = icon.Bounds().Dy() + margin/2
+	}
+
+	// Centre the icon, title and details vertically
+	blockHeight := iconHeight + composite.TextHeight(titleScale) +
+		len(content.Details)*(lineGap+composite.TextHeight(detailScale))
+	y := max((t.Height-blockHeight)/2, margin)
+
+	if icon != nil {
+		draw.Draw(canvas, icon.Bounds().Add(image.Pt(margin, y)), icon, icon.Bounds().Min, draw.Over)
+		y += iconHeight
+	}
+
+	composite.DrawText(canvas, margin, y, fitText(content.Title, textWidth, titleScale), titleScale, foreground)
+	y += composite.TextHeight(titleScale)
+
+	for _, line := range content.Details {
+		y += lineGap
+		composite.DrawText(canvas, margin, y, fitText(line, textWidth, detailScale), detailScale, accent)
+		y += composite.TextHeight(detailScale)
+	}
+
+	t.drawFan(canvas, content.Cards)
+
+	return canvas
+}
+
+// drawFan fans the cards out around a pivot below the right half of the banner
+func (t *Template) drawFan(canvas *image.RGBA, cards []image.Image) {
+	if len(cards) == 0 {
+		return
+	}
+
+	fan := t.Fan
+	if fan == 0 {
+		fan = 40
+	}
+
+	// Fit the fan, including the corners of its outer cards, into the right half
+	half := fan / 2 * math.Pi / 180
+	b := cards[0].Bounds()
+	aspect := float64(b.Dx()) / float64(b.Dy())
+	extent := func(h float64) (float64, float64) {
+		radius := h * 1.3
+		w := h * aspect
+		x := radius*math.Sin(half) + w/2*math.Cos(half) + h/2*math.Sin(half)
+		y := radius*(1-math.Cos(half)) + h/2*math.Cos(half) + w/2*math.Sin(half) + h/2
+		return x, y
+	}
+
+	regionW, regionH := float64(t.Width)/2, float64(t.Height)
+	cardHeight := regionH * 0.75
+	for cardHeight > 1 {
+		x, y := extent(cardHeight)
+		if 2*x <= regionW*0.9 && y <= regionH*0.9 {
+			break
+		}
+		cardHeight *= 0.95
+	}
+	_, fanHeight := extent(cardHeight)
+
+	radius := cardHeight * 1.3
+	pivotX := float64(t.Width) * 0.75
+	pivotY := (regionH-fanHeight)/2 + cardHeight/2 + radius
+
+	for i, img := range cards {
+		angle := 0.0
+		if len(cards) > 1 {
+			angle = -fan/2 + fan*float64(i)/float64(len(cards)-1)
+		}
+		rad := angle * math.Pi / 180
+
+		b := img.Bounds()
+		width := cardHeight * float64(b.Dx()) / float64(b.Dy())
+		scaled := resize.Resize(uint(width), uint(cardHeight), img, resize.Lanczos3)
+
+		centreX := pivotX + radius*math.Sin(rad)
+		centreY := pivotY - radius*math.Cos(rad)
+		drawRotated(canvas, scaled, centreX, centreY, rad)
+	}
+}
+
+// drawRotated draws img centred on (cx, cy), turned clockwise by rad radians
+func drawRotated(dst *image.RGBA, img image.Image, cx, cy, rad float64) {
+	b := img.Bounds()
+	halfW, halfH := float64(b.Dx())/2, float64(b.Dy())/2
+	sin, cos := math.Sin(rad), math.Cos(rad)
+
+	// Bounding box of the rotated card
+	extentX := math.Abs(halfW*cos) + math.Abs(halfH*sin)
+	extentY := math.Abs(halfW*sin) + math.Abs(halfH*cos)
+	area := image.Rect(int(cx-extentX), int(cy-extentY), int(cx+extentX)+1, int(cy+extentY)+1).Intersect(dst.Bounds())
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			// Map the destination pixel back onto the unrotated card
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			sx := dx*cos + dy*sin + halfW
+			sy := -dx*sin + dy*cos + halfH
+			if sx < 0 || sy < 0 || sx >= float64(b.Dx()) || sy >= float64(b.Dy()) {
+				continue
+			}
+
+			r, g, bl, a := img.At(b.Min.X+int(sx), b.Min.Y+int(sy)).RGBA()
+			if a == 0 {
+				continue
+			}
+			if a == 0xffff {
+				dst.SetRGBA(x, y, color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(bl >> 8), A: 255})
+				continue
+			}
+
+			// Blend translucent pixels over what is already drawn
+			under := dst.RGBAAt(x, y)
+			blend := func(over uint32, under uint8) uint8 {
+				return uint8((over + uint32(under)*257*(0xffff-a)/0xffff) >> 8)
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: blend(r, under.R), G: blend(g, under.G), B: blend(bl, under.B), A: 255})
+		}
+	}
+}
+
+// fitText truncates text so it fits within width pixels at the given scale
+func fitText(text string, width, scale int) string {
+	runes := []rune(text)
+	for len(runes) > 0 && composite.TextWidth(string(runes), scale) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// SampleCards returns the IDs of the cards fanned out by the template
+func (t *Template) SampleCards() []string {
+	if len(t.Cards) > 0 {
+		return t.Cards
+	}
+	return defaultCards
+}
@@ -0,0 +1,140 @@
+package spread
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/canon"
+	"github.com/arcanaland/cartomancer/internal/script"
+)
+
+// customSpreads holds spreads loaded from the user's spreads directory
+var customSpreads = map[string]*Spread{}
+
+// spreadFile is the on-disk format of a custom spread
+type spreadFile struct {
+	ID        string `toml:"id"`
+	Name      string `toml:"name"`
+	Positions []struct {
+		Name    string  `toml:"name"`
+		X       float64 `toml:"x"`
+		Y       float64 `toml:"y"`
+		Rotated bool    `toml:"rotated"`
+	} `toml:"positions"`
+	Deal *struct {
+		Until string `toml:"until"`
+		Max   int    `toml:"max"`
+		Label string `toml:"label"`
+	} `toml:"deal"`
+}
+
+// LoadDir loads custom spreads from the .toml files and .star scripts in a
+// directory. A missing directory is not an error. Custom spreads cannot
+// replace built-in ones.
+func LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading spreads directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		var load func(string) (*Spread, error)
+		switch {
+		case entry.IsDir():
+			continue
+		case filepath.Ext(entry.Name()) == ".toml":
+			load = loadFile
+		case filepath.Ext(entry.Name()) == ".star":
+			load = loadScript
+		default:
+			continue
+		}
+
+		s, err := load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error loading spread %s: %v", entry.Name(), err)
+		}
+
+		if _, ok := builtinSpreads[s.ID]; ok {
+			return fmt.Errorf("spread %s in %s clashes with a built-in spread", s.ID, entry.Name())
+		}
+		customSpreads[s.ID] = s
+	}
+
+	return nil
+}
+
+// loadFile parses a single custom spread file
+func loadFile(path string) (*Spread, error) {
+	var file spreadFile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	s := &Spread{
+		ID:   file.ID,
+		Name: file.Name,
+	}
+	if s.ID == "" {
+		s.ID = strings.TrimSuffix(filepath.Base(path), ".toml")
+	}
+	if s.Name == "" {
+		s.Name = s.ID
+	}
+
+	for _, p := range file.Positions {
+		s.Positions = append(s.Positions, Position{Name: p.Name, X: p.X, Y: p.Y, Rotated: p.Rotated})
+	}
+
+	if file.Deal != nil {
+		if file.Deal.Until == "" {
+			return nil, fmt.Errorf("deal.until is required")
+		}
+		until, err := script.Parse(file.Deal.Until)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Until = until
+		s.MaxCards = file.Deal.Max
+		if s.MaxCards <= 0 {
+			s.MaxCards = canon.DeckSize
+		}
+		s.Label = file.Deal.Label
+		if s.Label == "" {
+			s.Label = "Card"
+		}
+	}
+
+	if len(s.Positions) == 0 && s.Until == nil {
+		return nil, fmt.Errorf("spread has no positions and no deal rule")
+	}
+
+	return s, nil
+}
+
+// loadScript loads a spread from a script defining deal(draw), and optionally
+// the name and label globals
+func loadScript(path string) (*Spread, error) {
+	p, err := script.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Defines("deal") {
+		return nil, fmt.Errorf("script does not define deal(draw)")
+	}
+
+	s := &Spread{ID: strings.TrimSuffix(filepath.Base(path), ".star"), Script: p}
+	if s.Name, err = p.String("name", s.ID); err != nil {
+		return nil, err
+	}
+	if s.Label, err = p.String("label", "Card"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,279 @@
+package spread
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/script"
+)
+
+// Position is a slot in a spread layout. X and Y give the centre of the card in
+// grid units, where one unit is one card width (X) or one card height (Y).
+type Position struct {
+	Name    string
+	X       float64
+	Y       float64
+	Rotated bool // Laid crosswise over another position
+}
+
+// Spread describes a named layout of positions
+type Spread struct {
+	ID        string
+	Name      string
+	Positions []Position
+
+	// Dynamic spreads keep dealing cards after the fixed positions until a
+	// dealt card satisfies Until, or MaxCards extra cards have been dealt
+	Until    *script.Expr
+	MaxCards int
+	Label    string // Position name prefix for dynamically dealt cards
+
+	// Scripted spreads have no positions: the deal function of the script
+	// draws the cards and names their positions
+	Script *script.Program
+}
+
+// Draw is a card placed in a spread position
+type Draw struct {
+	Position  Position
+	Card      *card.Card
+	Clarifies int // 1-based index of the draw this card clarifies, 0 for the spread's own cards
+}
+
+// builtinSpreads holds the spreads shipped with cartomancer
+var builtinSpreads = map[string]*Spread{
+	"single": {
+		ID:   "single",
+		Name: "Single Card",
+		Positions: []Position{
+			{Name: "Card", X: 0.5, Y: 0.5},
+		},
+	},
+	"three-card": {
+		ID:   "three-card",
+		Name: "Past, Present, Future",
+		Positions: []Position{
+			{Name: "Past", X: 0.5, Y: 0.5},
+			{Name: "Present", X: 1.5, Y: 0.5},
+			{Name: "Future", X: 2.5, Y: 0.5},
+		},
+	},
+	"celtic-cross": {
+		ID:   "celtic-cross",
+		Name: "Celtic Cross",
+		Positions: []Position{
+			{Name: "Present", X: 1.5, Y: 2},
+			{Name: "Challenge", X: 1.5, Y: 2, Rotated: true},
+			{Name: "Foundation", X: 1.5, Y: 3},
+			{Name: "Past", X: 0.5, Y: 2},
+			{Name: "Crown", X: 1.5, Y: 1},
+			{Name: "Future", X: 2.5, Y: 2},
+			{Name: "Self", X: 4, Y: 3.5},
+			{Name: "Environment", X: 4, Y: 2.5},
+			{Name: "Hopes and Fears", X: 4, Y: 1.5},
+			{Name: "Outcome", X: 4, Y: 0.5},
+		},
+	},
+}
+
+// Get returns a built-in or custom spread by ID
+func Get(id string) (*Spread, error) {
+	if s, ok := builtinSpreads[id]; ok {
+		return s, nil
+	}
+	if s, ok := customSpreads[id]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown spread: %s (available: %v)", id, Names())
+}
+
+// Names returns the IDs of all built-in and loaded custom spreads
+func Names() []string {
+	names := make([]string, 0, len(builtinSpreads)+len(customSpreads))
+	for name := range builtinSpreads {
+		names = append(names, name)
+	}
+	for name := range customSpreads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Size returns the width and height of the spread in grid units
+func (s *Spread) Size() (float64, float64) {
+	var width, height float64
+	for _, p := range s.Positions {
+		if p.X+0.5 > width {
+			width = p.X + 0.5
+		}
+		if p.Y+0.5 > height {
+			height = p.Y + 0.5
+		}
+	}
+	return width, height
+}
+
+// Deal shuffles the given cards and deals one into each position of the spread.
+// Dynamic spreads then continue dealing in a row beneath the fixed positions.
+func (s *Spread) Deal(cards []*card.Card, rng *rand.Rand) ([]Draw, error) {
+	shuffled := make([]*card.Card, len(cards))
+	copy(shuffled, cards)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return s.DealFrom(shuffled)
+}
+
+// DealFrom deals already shuffled cards into the spread in the order given
+func (s *Spread) DealFrom(shuffled []*card.Card) ([]Draw, error) {
+	if s.Script != nil {
+		return s.dealScript(shuffled)
+	}
+
+	if len(shuffled) < len(s.Positions) {
+		return nil, fmt.Errorf("spread %s needs %d cards but only %d are available",
+			s.ID, len(s.Positions), len(shuffled))
+	}
+
+	draws := make([]Draw, len(s.Positions))
+	for i, p := range s.Positions {
+		draws[i] = Draw{Position: p, Card: shuffled[i]}
+	}
+
+	if s.Until == nil {
+		return draws, nil
+	}
+
+	_, rowY := s.Size()
+	for i, c := range shuffled[len(s.Positions):] {
+		if i >= s.MaxCards {
+			break
+		}
+
+		draws = append(draws, Draw{
+			Position: Position{Name: fmt.Sprintf("%s %d", s.Label, i+1), X: float64(i) + 0.5, Y: rowY + 0.5},
+			Card:     c,
+		})
+
+		env := script.CardEnv(c)
+		env["drawn"] = i + 1
+		done, err := s.Until.EvalBool(env)
+		if err != nil {
+			return nil, fmt.Errorf("spread %s: %v", s.ID, err)
+		}
+		if done {
+			break
+		}
+	}
+
+	return draws, nil
+}
+
+// dealScript deals the cards chosen by the spread's script in a row, naming
+// positions the script leaves unnamed after their order
+func (s *Spread) dealScript(shuffled []*card.Card) ([]Draw, error) {
+	dealt, err := s.Script.Deal(shuffled)
+	if err != nil {
+		return nil, fmt.Errorf("spread %s: %v", s.ID, err)
+	}
+	if len(dealt) == 0 {
+		return nil, fmt.Errorf("spread %s: deal returned no cards", s.ID)
+	}
+
+	draws := make([]Draw, len(dealt))
+	for i, d := range dealt {
+		name := d.Position
+		if name == "" {
+			name = fmt.Sprintf("%s %d", s.Label, i+1)
+		}
+		draws[i] = Draw{Position: Position{Name: name, X: float64(i) + 0.5, Y: 0.5}, Card: d.Card}
+	}
+	return draws, nil
+}
+
+// clarifierOffset is how far each clarifier is tucked below and to the right
+// of the card it clarifies, in grid units
+const clarifierOffset = 0.25
+
+// Clarify draws a clarifier for each of the 1-based positions from the cards
+// not already dealt, shuffled with rng. Clarifiers follow the dealt cards and
+// overlap the card they clarify; a position can be clarified more than once.
+func Clarify(draws []Draw, cards []*card.Card, positions []int, rng *rand.Rand) ([]Draw, error) {
+	dealt := make(map[string]bool, len(draws))
+	for _, draw := range draws {
+		dealt[draw.Card.ID] = true
+	}
+	var rest []*card.Card
+	for _, c := range cards {
+		if !dealt[c.ID] {
+			rest = append(rest, c)
+		}
+	}
+	if len(positions) > len(rest) {
+		return nil, fmt.Errorf("cannot draw %d clarifiers from the %d cards left", len(positions), len(rest))
+	}
+	rng.Shuffle(len(rest), func(i, j int) {
+		rest[i], rest[j] = rest[j], rest[i]
+	})
+
+	// Clarifiers already drawn, per position, and the cards they can clarify
+	counts := map[int]int{}
+	spreadCards := 0
+	for _, draw := range draws {
+		if draw.Clarifies > 0 {
+			counts[draw.Clarifies]++
+		} else {
+			spreadCards++
+		}
+	}
+
+	for i, n := range positions {
+		if n < 1 || n > spreadCards {
+			return nil, fmt.Errorf("cannot clarify position %d: the reading has %d cards", n, spreadCards)
+		}
+		parent := draws[n-1].Position
+		counts[n]++
+		offset := clarifierOffset * float64(counts[n])
+		name := parent.Name + " clarifier"
+		if counts[n] > 1 {
+			name += fmt.Sprintf(" %d", counts[n])
+		}
+		draws = append(draws, Draw{
+			Position:  Position{Name: name, X: parent.X + offset, Y: parent.Y + offset},
+			Card:      rest[i],
+			Clarifies: n,
+		})
+	}
+	return draws, nil
+}
+
+// Adjacent returns the index pairs of the draws whose positions touch: side
+// by side, diagonally or one laid across the other. Clarifiers only touch the
+// card they clarify.
+func Adjacent(draws []Draw) [][2]int {
+	var pairs [][2]int
+	for i := range draws {
+		for j := i + 1; j < len(draws); j++ {
+			if a, b := draws[i].Clarifies, draws[j].Clarifies; a > 0 || b > 0 {
+				if a == j+1 || b == i+1 {
+					pairs = append(pairs, [2]int{i, j})
+				}
+				continue
+			}
+			dx := math.Abs(draws[i].Position.X - draws[j].Position.X)
+			dy := math.Abs(draws[i].Position.Y - draws[j].Position.Y)
+			if dx <= 1+adjacentSlack && dy <= 1+adjacentSlack {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return pairs
+}
+
+// adjacentSlack allows for positions placed slightly apart from their neighbours
+const adjacentSlack = 0.01
@@ -0,0 +1,482 @@
+// Package sqlite writes SQLite database files from scratch, for exports that
+// other tools open with SQLite. It writes tables only, without indexes, and
+// builds each table in one pass from rows held in memory, which suits
+// database files of a few thousand rows generated in one go.
+//
+// The layout follows the SQLite file format documentation:
+// https://www.sqlite.org/fileformat2.html
+package sqlite
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// pageSize is the size of every page in the file
+const pageSize = 4096
+
+// headerSize is the size of the database header at the start of page 1
+const headerSize = 100
+
+// B-tree page types
+const (
+	tableInterior = 0x05
+	tableLeaf     = 0x0D
+)
+
+// sqliteVersion is reported as the version of SQLite that last wrote the file
+const sqliteVersion = 3045000
+
+// DB is a database being built in memory
+type DB struct {
+	tables []*Table
+}
+
+// Table is a table and its rows
+type Table struct {
+	name string
+	sql  string
+	rows []row
+}
+
+// row is a record and its rowid
+type row struct {
+	rowid  int64
+	values []interface{}
+}
+
+// New returns an empty database
+func New() *DB {
+	return &DB{}
+}
+
+// CreateTable adds a table defined by a CREATE TABLE statement. The
+// statement is stored as is and not parsed; it must match the rows inserted.
+func (db *DB) CreateTable(name, sql string) *Table {
+	t := &Table{name: name, sql: sql}
+	db.tables = append(db.tables, t)
+	return t
+}
+
+// Insert adds a row. Values may be nil, integers, bools, float64, string or
+// []byte. A column declared INTEGER PRIMARY KEY is an alias for the rowid,
+// and SQLite expects nil in its place in the values.
+func (t *Table) Insert(rowid int64, values ...interface{}) error {
+	for i, v := range values {
+		if _, err := serialType(v); err != nil {
+			return fmt.Errorf("table %s, column %d: %v", t.name, i+1, err)
+		}
+	}
+	t.rows = append(t.rows, row{rowid: rowid, values: values})
+	return nil
+}
+
+// Save writes the database to a file
+func (db *DB) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := db.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the database file
+func (db *DB) Write(w io.Writer) error {
+	b := &builder{pages: [][]byte{make([]byte, pageSize)}}
+
+	schema := make([]row, 0, len(db.tables))
+	for i, t := range db.tables {
+		rows := append([]row(nil), t.rows...)
+		sort.SliceStable(rows, func(a, b int) bool { return rows[a].rowid < rows[b].rowid })
+		for j := 1; j < len(rows); j++ {
+			if rows[j].rowid == rows[j-1].rowid {
+				return fmt.Errorf("table %s: duplicate rowid %d", t.name, rows[j].rowid)
+			}
+		}
+
+		root, err := b.buildTree(rows)
+		if err != nil {
+			return fmt.Errorf("table %s: %v", t.name, err)
+		}
+		schema = append(schema, row{
+			rowid:  int64(i + 1),
+			values: []interface{}{"table", t.name, t.name, int64(root), t.sql},
+		})
+	}
+
+	// The schema table is rooted at page 1, after the database header
+	cells, err := b.leafCells(schema)
+	if err != nil {
+		return fmt.Errorf("schema: %v", err)
+	}
+	if !fits(cells, headerSize+8) {
+		return fmt.Errorf("schema of %d tables does not fit in the first page", len(db.tables))
+	}
+	writePage(b.pages[0], headerSize, tableLeaf, cells, 0)
+	writeHeader(b.pages[0], len(b.pages))
+
+	for _, page := range b.pages {
+		if _, err := w.Write(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// builder allocates the pages of a database
+type builder struct {
+	pages [][]byte
+}
+
+// allocate adds an empty page, returning its 1-based page number
+func (b *builder) allocate() (uint32, []byte) {
+	page := make([]byte, pageSize)
+	b.pages = append(b.pages, page)
+	return uint32(len(b.pages)), page
+}
+
+// cell is an encoded b-tree cell with the largest rowid it covers
+type cell struct {
+	data  []byte
+	rowid int64
+}
+
+// buildTree writes the b-tree of a table, returning its root page number
+func (b *builder) buildTree(rows []row) (uint32, error) {
+	cells, err := b.leafCells(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	// Leaf pages, with the cells that point to them from the level above
+	var level []cell
+	for _, group := range paginate(cells, 8) {
+		number, page := b.allocate()
+		writePage(page, 0, tableLeaf, group, 0)
+		level = append(level, childCell(number, group))
+	}
+	if len(level) == 0 {
+		number, page := b.allocate()
+		writePage(page, 0, tableLeaf, nil, 0)
+		return number, nil
+	}
+
+	// Interior levels until a single root remains. Each page keeps its last
+	// child as the right-most pointer rather than as a cell.
+	for len(level) > 1 {
+		var next []cell
+		for _, group := range paginateInterior(level) {
+			number, page := b.allocate()
+			last := group[len(group)-1]
+			writePage(page, 0, tableInterior, group[:len(group)-1], binary.BigEndian.Uint32(last.data))
+			next = append(next, childCell(number, group))
+		}
+		level = next
+	}
+
+	return binary.BigEndian.Uint32(level[0].data), nil
+}
+
+// childCell returns the interior cell pointing to a page holding cells
+func childCell(page uint32, cells []cell) cell {
+	rowid := cells[len(cells)-1].rowid
+	data := binary.BigEndian.AppendUint32(nil, page)
+	return cell{data: appendVarint(data, uint64(rowid)), rowid: rowid}
+}
+
+// leafCells encodes rows as table leaf cells, moving large payloads to
+// overflow pages
+func (b *builder) leafCells(rows []row) ([]cell, error) {
+	cells := make([]cell, 0, len(rows))
+	for _, r := range rows {
+		payload, err := encodeRecord(r.values)
+		if err != nil {
+			return nil, err
+		}
+
+		data := appendVarint(nil, uint64(len(payload)))
+		data = appendVarint(data, uint64(r.rowid))
+
+		local := localPayload(len(payload))
+		data = append(data, payload[:local]...)
+		if local < len(payload) {
+			data = binary.BigEndian.AppendUint32(data, b.writeOverflow(payload[local:]))
+		}
+
+		cells = append(cells, cell{data: data, rowid: r.rowid})
+	}
+	return cells, nil
+}
+
+// localPayload returns how much of a payload is stored in the leaf cell
+func localPayload(size int) int {
+	maxLocal := pageSize - 35
+	if size <= maxLocal {
+		return size
+	}
+	minLocal := (pageSize-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(pageSize-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	return local
+}
+
+// writeOverflow writes a chain of overflow pages, returning the first page number
+func (b *builder) writeOverflow(data []byte) uint32 {
+	var first uint32
+	var previous []byte
+	for len(data) > 0 {
+		number, page := b.allocate()
+		if previous != nil {
+			binary.BigEndian.PutUint32(previous, number)
+		} else {
+			first = number
+		}
+		n := copy(page[4:], data)
+		data = data[n:]
+		previous = page
+	}
+	return first
+}
+
+// paginate splits leaf cells into groups that each fit in one page
+func paginate(cells []cell, pageHeader int) [][]cell {
+	var groups [][]cell
+	var group []cell
+	used := pageHeader
+	for _, c := range cells {
+		size := len(c.data) + 2
+		if len(group) > 0 && used+size > pageSize {
+			groups = append(groups, group)
+			group, used = nil, pageHeader
+		}
+		group = append(group, c)
+		used += size
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// paginateInterior splits child cells into interior pages. The last child of
+// each page takes no cell space as it becomes the right-most pointer.
+func paginateInterior(children []cell) [][]cell {
+	var groups [][]cell
+	var group []cell
+	used := 12
+	for _, c := range children {
+		size := len(c.data) + 2
+		if len(group) > 1 && used+size > pageSize {
+			groups = append(groups, group)
+			group, used = nil, 12
+		}
+		group = append(group, c)
+		used += size
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+
+	// A page needs at least two children; borrow one from the previous page
+	if n := len(groups); n > 1 && len(groups[n-1]) == 1 {
+		prev := groups[n-2]
+		groups[n-1] = append([]cell{prev[len(prev)-1]}, groups[n-1]...)
+		groups[n-2] = prev[:len(prev)-1]
+	}
+	return groups
+}
+
+// fits reports whether cells fit in a page after offset bytes of headers
+func fits(cells []cell, offset int) bool {
+	used := offset
+	for _, c := range cells {
+		used += len(c.data) + 2
+	}
+	return used <= pageSize
+}
+
+// writePage lays out a b-tree page: the page header at offset, the cell
+// pointer array after it and the cells packed at the end of the page
+func writePage(page []byte, offset int, pageType byte, cells []cell, rightChild uint32) {
+	header := 8
+	if pageType == tableInterior {
+		header = 12
+		binary.BigEndian.PutUint32(page[offset+8:], rightChild)
+	}
+
+	page[offset] = pageType
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+
+	content := pageSize
+	pointer := offset + header
+	for _, c := range cells {
+		content -= len(c.data)
+		copy(page[content:], c.data)
+		binary.BigEndian.PutUint16(page[pointer:], uint16(content))
+		pointer += 2
+	}
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+// writeHeader writes the database header at the start of page 1
+func writeHeader(page []byte, pageCount int) {
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], pageSize)
+	page[18] = 1                             // Legacy write version
+	page[19] = 1                             // Legacy read version
+	page[21] = 64                            // Maximum embedded payload fraction
+	page[22] = 32                            // Minimum embedded payload fraction
+	page[23] = 32                            // Leaf payload fraction
+	binary.BigEndian.PutUint32(page[24:], 1) // File change counter
+	binary.BigEndian.PutUint32(page[28:], uint32(pageCount))
+	binary.BigEndian.PutUint32(page[40:], 1) // Schema cookie
+	binary.BigEndian.PutUint32(page[44:], 4) // Schema format
+	binary.BigEndian.PutUint32(page[56:], 1) // UTF-8 text encoding
+	binary.BigEndian.PutUint32(page[92:], 1) // Version-valid-for, matching the change counter
+	binary.BigEndian.PutUint32(page[96:], sqliteVersion)
+}
+
+// encodeRecord encodes values in the SQLite record format
+func encodeRecord(values []interface{}) ([]byte, error) {
+	var types, body []byte
+	for _, v := range values {
+		t, err := serialType(v)
+		if err != nil {
+			return nil, err
+		}
+		types = appendVarint(types, t)
+		body = appendValue(body, v, t)
+	}
+
+	// The header size includes the varint holding it
+	size := len(types) + 1
+	for size != len(types)+varintLen(uint64(size)) {
+		size = len(types) + varintLen(uint64(size))
+	}
+
+	record := appendVarint(nil, uint64(size))
+	record = append(record, types...)
+	return append(record, body...), nil
+}
+
+// serialType returns the record serial type of a value
+func serialType(v interface{}) (uint64, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if v {
+			return 9, nil
+		}
+		return 8, nil
+	case int:
+		return intSerialType(int64(v)), nil
+	case int64:
+		return intSerialType(v), nil
+	case float64:
+		return 7, nil
+	case string:
+		return uint64(len(v))*2 + 13, nil
+	case []byte:
+		return uint64(len(v))*2 + 12, nil
+	}
+	return 0, fmt.Errorf("unsupported value type %T", v)
+}
+
+// intSerialType returns the smallest serial type holding an integer
+func intSerialType(v int64) uint64 {
+	switch {
+	case v == 0:
+		return 8
+	case v == 1:
+		return 9
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return 2
+	case v >= -1<<23 && v < 1<<23:
+		return 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return 4
+	case v >= -1<<47 && v < 1<<47:
+		return 5
+	}
+	return 6
+}
+
+// intSizes are the byte sizes of integer serial types 1-6
+var intSizes = map[uint64]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 6, 6: 8}
+
+// appendValue appends the body of a value with the given serial type
+func appendValue(body []byte, v interface{}, t uint64) []byte {
+	switch v := v.(type) {
+	case int:
+		return appendInt(body, int64(v), intSizes[t])
+	case int64:
+		return appendInt(body, v, intSizes[t])
+	case float64:
+		return binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+	case string:
+		return append(body, v...)
+	case []byte:
+		return append(body, v...)
+	}
+	return body // nil and bools are held by the serial type alone
+}
+
+// appendInt appends a big-endian two's complement integer of n bytes
+func appendInt(body []byte, v int64, n int) []byte {
+	for i := n - 1; i >= 0; i-- {
+		body = append(body, byte(v>>(8*i)))
+	}
+	return body
+}
+
+// appendVarint appends a SQLite variable-length integer: big-endian groups of
+// seven bits, with a ninth byte holding a full eight bits
+func appendVarint(buf []byte, v uint64) []byte {
+	if v > 0x00FFFFFFFFFFFFFF {
+		var tmp [9]byte
+		tmp[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			tmp[i] = byte(v&0x7F) | 0x80
+			v >>= 7
+		}
+		return append(buf, tmp[:]...)
+	}
+
+	var tmp [8]byte
+	n := 0
+	for {
+		tmp[n] = byte(v & 0x7F)
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		if i > 0 {
+			buf = append(buf, tmp[i]|0x80)
+		} else {
+			buf = append(buf, tmp[i])
+		}
+	}
+	return buf
+}
+
+// varintLen returns the encoded size of a varint
+func varintLen(v uint64) int {
+	return len(appendVarint(nil, v))
+}
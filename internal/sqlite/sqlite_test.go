@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestAppendVarint(t *testing.T) {
+	tests := []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7F}},
+		{128, []byte{0x81, 0x00}},
+		{16383, []byte{0xFF, 0x7F}},
+		{16384, []byte{0x81, 0x80, 0x00}},
+		{1<<64 - 1, bytes.Repeat([]byte{0xFF}, 9)},
+	}
+	for _, tt := range tests {
+		if got := appendVarint(nil, tt.v); !bytes.Equal(got, tt.want) {
+			t.Errorf("appendVarint(%d) = %x, want %x", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeRecord(t *testing.T) {
+	got, err := encodeRecord([]interface{}{nil, int64(0), int64(1), int64(-2), 1.5, "hi", []byte{0xAB}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		8,                     // Header size
+		0, 8, 9, 1, 7, 17, 14, // Serial types: NULL, 0, 1, int8, float, 2-byte text, 1-byte blob
+		0xFE,                                           // -2
+		0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // 1.5
+		'h', 'i',
+		0xAB,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeRecord() = %x, want %x", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	db := New()
+	notes := db.CreateTable("notes", "CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)")
+	for i := 1; i <= 500; i++ {
+		// Large enough to need overflow pages and several leaf pages
+		if err := notes.Insert(int64(i), nil, strings.Repeat("card ", i*3)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.CreateTable("empty", "CREATE TABLE empty (a)")
+
+	var buf bytes.Buffer
+	if err := db.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("SQLite format 3\x00")) {
+		t.Fatal("missing SQLite header")
+	}
+	if len(data)%pageSize != 0 {
+		t.Fatalf("file size %d is not a multiple of the page size", len(data))
+	}
+	if pages := binary.BigEndian.Uint32(data[28:]); int(pages) != len(data)/pageSize {
+		t.Errorf("header records %d pages, file has %d", pages, len(data)/pageSize)
+	}
+	if data[headerSize] != tableLeaf || binary.BigEndian.Uint16(data[headerSize+3:]) != 2 {
+		t.Errorf("schema page should be a leaf with 2 tables")
+	}
+
+	if err := notes.Insert(1, nil, "again"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Write(&bytes.Buffer{}); err == nil {
+		t.Error("Write() accepted a duplicate rowid")
+	}
+	if err := notes.Insert(1000, struct{}{}); err == nil {
+		t.Error("Insert() accepted an unsupported value")
+	}
+}
@@ -0,0 +1,175 @@
+// Package study schedules flashcard reviews of a deck's cards with a Leitner
+// style spaced repetition system.
+package study
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+)
+
+// intervals is the review interval of each Leitner box. A correct answer moves
+// a card up one box, a wrong answer sends it back to the first.
+var intervals = []time.Duration{
+	0,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+// MasteredBox is the box from which a card counts as mastered
+const MasteredBox = 4
+
+// Record is the review history of a single card
+type Record struct {
+	Box      int       `toml:"box"`
+	Due      time.Time `toml:"due"`
+	Right    int       `toml:"right"`
+	Wrong    int       `toml:"wrong"`
+	Reviewed time.Time `toml:"reviewed"`
+}
+
+// Progress is the review history of every studied card in a deck
+type Progress struct {
+	DeckID string             `toml:"deck_id"`
+	Cards  map[string]*Record `toml:"cards"`
+}
+
+// Stats summarizes a deck's progress
+type Stats struct {
+	Total    int
+	New      int // Never reviewed
+	Due      int // Reviewed and due again
+	Learning int
+	Mastered int
+	Right    int
+	Wrong    int
+}
+
+// path returns the file holding a deck's progress, refusing deck IDs that
+// would place it outside dir
+func path(dir, deckID string) (string, error) {
+	if err := deck.CheckID(deckID); err != nil {
+		return "", fmt.Errorf("invalid deck id: %v", err)
+	}
+	return filepath.Join(dir, deckID+".toml"), nil
+}
+
+// Load reads a deck's progress. A deck that was never studied has empty progress.
+func Load(dir, deckID string) (*Progress, error) {
+	p := &Progress{DeckID: deckID, Cards: map[string]*Record{}}
+
+	file, err := path(dir, deckID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := toml.DecodeFile(file, p); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading study progress: %v", err)
+	}
+	if p.Cards == nil {
+		p.Cards = map[string]*Record{}
+	}
+
+	return p, nil
+}
+
+// Save writes a deck's progress
+func (p *Progress) Save(dir string) error {
+	filePath, err := path(dir, p.DeckID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating study directory: %v", err)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("error creating study progress: %v", err)
+	}
+	defer file.Close()
+
+	if err := toml.NewEncoder(file).Encode(p); err != nil {
+		return fmt.Errorf("error encoding study progress: %v", err)
+	}
+
+	return nil
+}
+
+// Answer records a review of a card and schedules its next one
+func (p *Progress) Answer(cardID string, correct bool, now time.Time) *Record {
+	r, ok := p.Cards[cardID]
+	if !ok {
+		r = &Record{}
+		p.Cards[cardID] = r
+	}
+
+	if correct {
+		r.Right++
+		r.Box = min(r.Box+1, len(intervals)-1)
+	} else {
+		r.Wrong++
+		r.Box = 0
+	}
+	r.Reviewed = now
+	r.Due = now.Add(intervals[r.Box])
+
+	return r
+}
+
+// Next returns up to n cards to review: due cards, most overdue first,
+// followed by cards that were never reviewed in deck order
+func (p *Progress) Next(cards []*card.Card, n int, now time.Time) []*card.Card {
+	var due, fresh []*card.Card
+	for _, c := range cards {
+		r, ok := p.Cards[c.ID]
+		switch {
+		case !ok:
+			fresh = append(fresh, c)
+		case !r.Due.After(now):
+			due = append(due, c)
+		}
+	}
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return p.Cards[due[i].ID].Due.Before(p.Cards[due[j].ID].Due)
+	})
+
+	queue := append(due, fresh...)
+	if n > 0 && len(queue) > n {
+		queue = queue[:n]
+	}
+	return queue
+}
+
+// Stats summarizes the progress over the given cards
+func (p *Progress) Stats(cards []*card.Card, now time.Time) Stats {
+	s := Stats{Total: len(cards)}
+	for _, c := range cards {
+		r, ok := p.Cards[c.ID]
+		if !ok {
+			s.New++
+			continue
+		}
+
+		s.Right += r.Right
+		s.Wrong += r.Wrong
+		if r.Box >= MasteredBox {
+			s.Mastered++
+		} else {
+			s.Learning++
+		}
+		if !r.Due.After(now) {
+			s.Due++
+		}
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+// Package suggest finds the closest match to a mistyped name, for "did you
+// mean" hints.
+package suggest
+
+import "strings"
+
+// Closest returns the option nearest to input by edit distance, ignoring
+// case, or "" when none is within half the input's length
+func Closest(input string, options []string) string {
+	best := ""
+	bestDistance := len(input)/2 + 1
+	for _, option := range options {
+		if d := Distance(strings.ToLower(input), strings.ToLower(option)); d < bestDistance {
+			best, bestDistance = option, d
+		}
+	}
+	return best
+}
+
+// Distance returns the Levenshtein edit distance between two strings
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,38 @@
+package suggest
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"cups", "cups", 0},
+		{"cup", "cups", 1},
+		{"kitten", "sitting", 3},
+		{"pentacels", "pentacles", 2},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	options := []string{"rider-waite", "thoth", "marseille"}
+	tests := []struct {
+		input, want string
+	}{
+		{"rider-wiate", "rider-waite"},
+		{"THOTH", "thoth"},
+		{"marsielle", "marseille"},
+		{"tarot", ""},
+	}
+	for _, tt := range tests {
+		if got := Closest(tt.input, options); got != tt.want {
+			t.Errorf("Closest(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
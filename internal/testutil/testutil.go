@@ -0,0 +1,88 @@
+// Package testutil provides deterministic fixtures and golden-file helpers for tests.
+package testutil
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"image"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//go:embed all:fixture
+var fixtureFS embed.FS
+
+var updateGolden = flag.Bool("update-golden", false, "rewrite golden files with the current output")
+
+// FixtureDeck copies the embedded fixture deck to a temporary directory and returns its path
+func FixtureDeck(t testing.TB) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	err := fs.WalkDir(fixtureFS, "fixture", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel("fixture", path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := fixtureFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("error copying fixture deck: %v", err)
+	}
+	return dir
+}
+
+// FixtureImage returns a deterministic gradient image of the given size
+func FixtureImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{
+				R: uint8(x * 255 / max(width-1, 1)),
+				G: uint8(y * 255 / max(height-1, 1)),
+				B: 128,
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+// Golden compares got against testdata/<name>.golden, rewriting the file when -update-golden is set
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("error creating testdata directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("error writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading golden file (run with -update-golden to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s (run with -update-golden to accept)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package theme
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"golang.org/x/term"
+)
+
+// Terminal backgrounds that output is adapted to
+const (
+	BackgroundAuto  = "auto"  // Ask the terminal for its background color
+	BackgroundDark  = "dark"  // Light text on a dark background
+	BackgroundLight = "light" // Dark text on a light background
+)
+
+// backgroundTimeout is how long DetectBackground waits for the terminal to
+// answer before assuming a dark background
+const backgroundTimeout = 200 * time.Millisecond
+
+// oscBackground matches a terminal's answer to the OSC 11 background color
+// query, rgb:RRRR/GGGG/BBBB with one to four hex digits per component
+var oscBackground = regexp.MustCompile(`\]11;rgb:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})`)
+
+// deviceAttributes matches the answer to the primary device attributes query,
+// which every terminal sends, so its arrival means no background answer is coming
+var deviceAttributes = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+
+// DetectBackground guesses whether the terminal has a dark or light
+// background. It asks the terminal for its background color (OSC 11) when
+// stdout is a terminal, then falls back to the COLORFGBG variable some
+// terminals set, and to dark, which most terminals default to.
+func DetectBackground() string {
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		if background, ok := queryBackground(); ok {
+			return background
+		}
+	}
+
+	// COLORFGBG is "fg;bg" or "fg;default;bg" in the 16 ANSI colors, where
+	// 7 (white) and 9-15 except 8 (grey) are light
+	if fgbg := os.Getenv("COLORFGBG"); fgbg != "" {
+		parts := strings.Split(fgbg, ";")
+		if bg, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			if bg == 7 || bg > 8 && bg < 16 {
+				return BackgroundLight
+			}
+			return BackgroundDark
+		}
+	}
+
+	return BackgroundDark
+}
+
+// queryBackground asks the controlling terminal for its background color,
+// following the query with a device attributes query so that terminals
+// without OSC 11 support do not make it wait for the full timeout
+func queryBackground() (string, bool) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", false
+	}
+	defer tty.Close()
+
+	// Calling Fd would put the file in blocking mode and disable the read
+	// deadline, so the terminal is switched to raw mode through Control
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return "", false
+	}
+	var state *term.State
+	conn.Control(func(fd uintptr) { state, err = term.MakeRaw(int(fd)) })
+	if err != nil {
+		return "", false
+	}
+	defer conn.Control(func(fd uintptr) { term.Restore(int(fd), state) })
+
+	if err := tty.SetReadDeadline(time.Now().Add(backgroundTimeout)); err != nil {
+		return "", false
+	}
+	if _, err := tty.WriteString("\033]11;?\033\\\033[c"); err != nil {
+		return "", false
+	}
+
+	var answer []byte
+	buf := make([]byte, 64)
+	for !deviceAttributes.Match(answer) {
+		n, err := tty.Read(buf)
+		answer = append(answer, buf[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	return parseBackground(answer)
+}
+
+// parseBackground reads the background from a terminal's answer to the OSC 11
+// query, judging it light when black text has more contrast with it than white
+func parseBackground(answer []byte) (string, bool) {
+	match := oscBackground.FindSubmatch(answer)
+	if match == nil {
+		return "", false
+	}
+
+	var rgb [3]float64
+	for i, hex := range match[1:] {
+		v, _ := strconv.ParseUint(string(hex), 16, 16)
+		rgb[i] = float64(v) / float64(uint64(1)<<(4*len(hex))-1)
+	}
+	r, g, b := colorful.Color{R: rgb[0], G: rgb[1], B: rgb[2]}.LinearRgb()
+	if l := luminance(r, g, b); (l+0.05)/0.05 > 1.05/(l+0.05) {
+		return BackgroundLight, true
+	}
+	return BackgroundDark, true
+}
+
+// luminance returns the relative luminance of a color from its linear RGB
+// components
+func luminance(r, g, b float64) float64 {
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
@@ -0,0 +1,88 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DetectSymbols guesses the richest symbol set the terminal can display. A
+// non-UTF-8 locale or the Linux console gets ASCII, terminals known to bundle
+// Nerd Font symbols or systems with a Nerd Font installed get Nerd Font glyphs,
+// and everything else gets plain Unicode.
+func DetectSymbols() string {
+	if !utf8Locale() || os.Getenv("TERM") == "linux" {
+		return SymbolsASCII
+	}
+
+	// WezTerm ships the Nerd Font symbols as a fallback font
+	if os.Getenv("TERM_PROGRAM") == "WezTerm" {
+		return SymbolsNerd
+	}
+
+	if nerdFontInstalled() {
+		return SymbolsNerd
+	}
+
+	return SymbolsUnicode
+}
+
+// utf8Locale reports whether the locale environment selects a UTF-8 encoding.
+// The first non-empty variable in order of precedence decides.
+func utf8Locale() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+
+	// No locale set, most modern terminals default to UTF-8
+	return true
+}
+
+// nerdFontInstalled looks for a patched Nerd Font in the usual font
+// directories. It runs on every invocation, so only the names directly in
+// each directory and in its subdirectories are checked, which is where font
+// installers and package managers put fonts, rather than walking whole trees.
+func nerdFontInstalled() bool {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs,
+			filepath.Join(home, ".local", "share", "fonts"),
+			filepath.Join(home, ".fonts"),
+			filepath.Join(home, "Library", "Fonts"))
+	}
+	dirs = append(dirs, "/usr/share/fonts", "/usr/local/share/fonts", "/Library/Fonts")
+
+	for _, dir := range dirs {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if isNerdFont(entry.Name()) {
+				return true
+			}
+			if !entry.IsDir() {
+				continue
+			}
+			files, _ := os.ReadDir(filepath.Join(dir, entry.Name()))
+			for _, file := range files {
+				if isNerdFont(file.Name()) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// isNerdFont reports whether a font file or directory name is a Nerd Font's
+func isNerdFont(name string) bool {
+	name = strings.ToLower(name)
+	return strings.Contains(name, "nerdfont") || strings.Contains(name, "nerd font")
+}
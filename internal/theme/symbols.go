@@ -0,0 +1,118 @@
+package theme
+
+import "fmt"
+
+// Symbol set names, from most to least demanding of the terminal font
+const (
+	SymbolsNerd    = "nerd"    // Nerd Font private-use glyphs
+	SymbolsUnicode = "unicode" // Standard Unicode symbols
+	SymbolsASCII   = "ascii"   // Plain ASCII for limited terminals
+	SymbolsAuto    = "auto"    // Detect what the terminal supports
+)
+
+// symbolLevels orders symbol sets by how much font support they need
+var symbolLevels = map[string]int{
+	SymbolsASCII:   0,
+	SymbolsUnicode: 1,
+	SymbolsNerd:    2,
+}
+
+// SymbolSet holds the glyphs used for arcana and suits
+type SymbolSet struct {
+	Name      string
+	Major     string
+	Minor     string
+	Suits     map[string]string
+	Unknown   string // Used for suits without a glyph of their own
+	Separator string // Separates a value from its symbol
+	Favorite  string // Marks favorite cards in listings
+}
+
+// Suit returns the symbol for a suit, falling back to the set's generic symbol
+func (s SymbolSet) Suit(suit string) string {
+	if symbol, ok := s.Suits[suit]; ok {
+		return symbol
+	}
+	return s.Unknown
+}
+
+// Arcana returns the symbol for the major or minor arcana
+func (s SymbolSet) Arcana(isMinor bool) string {
+	if isMinor {
+		return s.Minor
+	}
+	return s.Major
+}
+
+// symbolSets holds the built-in symbol sets
+var symbolSets = map[string]SymbolSet{
+	SymbolsNerd: {
+		Name:  SymbolsNerd,
+		Major: "",
+		Minor: "󱀝",
+		Suits: map[string]string{
+			"wands":     "",
+			"cups":      "",
+			"swords":    "󰞇",
+			"pentacles": "󱙧",
+		},
+		Unknown:   "•",
+		Separator: "·",
+		Favorite:  "",
+	},
+	SymbolsUnicode: {
+		Name:  SymbolsUnicode,
+		Major: "☉",
+		Minor: "☽",
+		Suits: map[string]string{
+			"wands":     "♣",
+			"cups":      "♥",
+			"swords":    "♠",
+			"pentacles": "♦",
+		},
+		Unknown:   "•",
+		Separator: "·",
+		Favorite:  "★",
+	},
+	SymbolsASCII: {
+		Name:  SymbolsASCII,
+		Major: "*",
+		Minor: "+",
+		Suits: map[string]string{
+			"wands":     "W",
+			"cups":      "C",
+			"swords":    "S",
+			"pentacles": "P",
+		},
+		Unknown:   "-",
+		Separator: "-",
+		Favorite:  "*",
+	},
+}
+
+// LookupSymbols returns the built-in symbol set with the given name
+func LookupSymbols(name string) (SymbolSet, bool) {
+	set, ok := symbolSets[name]
+	return set, ok
+}
+
+// UseSymbols selects the theme's symbol set. An explicit set name always wins;
+// "auto" or an empty setting keeps the theme's own set unless the terminal is
+// not expected to display it, in which case the best supported set is used.
+func (t *Theme) UseSymbols(setting string) error {
+	if setting == "" || setting == SymbolsAuto {
+		detected := DetectSymbols()
+		if symbolLevels[detected] < symbolLevels[t.Symbols.Name] {
+			t.Symbols = symbolSets[detected]
+		}
+		return nil
+	}
+
+	set, ok := symbolSets[setting]
+	if !ok {
+		return fmt.Errorf("unknown symbol set: %s (available: %s, %s, %s, %s)",
+			setting, SymbolsAuto, SymbolsNerd, SymbolsUnicode, SymbolsASCII)
+	}
+	t.Symbols = set
+	return nil
+}
@@ -0,0 +1,283 @@
+// Package theme defines the colors and symbols used for CLI output.
+package theme
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Style formats text in a single color. The zero Style leaves text unchanged.
+type Style struct {
+	color *color.Color
+}
+
+// Sprint formats its arguments with the style
+func (s Style) Sprint(a ...interface{}) string {
+	if s.color == nil {
+		return fmt.Sprint(a...)
+	}
+	return s.color.Sprint(a...)
+}
+
+// Sprintf formats according to a format specifier with the style
+func (s Style) Sprintf(format string, a ...interface{}) string {
+	if s.color == nil {
+		return fmt.Sprintf(format, a...)
+	}
+	return s.color.Sprintf(format, a...)
+}
+
+// Border holds the characters used to draw boxes and rules
+type Border struct {
+	TopLeft     string
+	TopRight    string
+	BottomLeft  string
+	BottomRight string
+	Horizontal  string
+	Vertical    string
+	TeeLeft     string // Joins a horizontal rule to the left edge
+	TeeRight    string // Joins a horizontal rule to the right edge
+}
+
+// Theme describes how CLI output is styled
+type Theme struct {
+	Name    string
+	Label   Style // Field labels such as "Card:"
+	Value   Style // Field values
+	Heading Style // Titles and section headings
+	Muted   Style // Secondary information
+	Border  Border
+	Symbols SymbolSet
+	Light   bool // Styled for a light terminal background
+
+	// fixed themes keep their own styles when decks set an accent color
+	fixed bool
+}
+
+// Default is the theme used when none is configured
+const Default = "default"
+
+// HighContrast is the theme that styles text with bold and underline in the
+// terminal's own foreground color, readable on any background
+const HighContrast = "high-contrast"
+
+// rgb builds a 24-bit color. The attributes are written out as 38;2;r;g;b,
+// which fatih/color passes through to the terminal unchanged.
+func rgb(r, g, b int) *color.Color {
+	return color.New(38, 2, color.Attribute(r), color.Attribute(g), color.Attribute(b))
+}
+
+var lightBorder = Border{
+	TopLeft: "┌", TopRight: "┐", BottomLeft: "└", BottomRight: "┘",
+	Horizontal: "─", Vertical: "│",
+	TeeLeft: "├", TeeRight: "┤",
+}
+
+var roundedBorder = Border{
+	TopLeft: "╭", TopRight: "╮", BottomLeft: "╰", BottomRight: "╯",
+	Horizontal: "─", Vertical: "│",
+	TeeLeft: "├", TeeRight: "┤",
+}
+
+var heavyBorder = Border{
+	TopLeft: "┏", TopRight: "┓", BottomLeft: "┗", BottomRight: "┛",
+	Horizontal: "━", Vertical: "┃",
+	TeeLeft: "┣", TeeRight: "┫",
+}
+
+var doubleBorder = Border{
+	TopLeft: "╔", TopRight: "╗", BottomLeft: "╚", BottomRight: "╝",
+	Horizontal: "═", Vertical: "║",
+	TeeLeft: "╠", TeeRight: "╣",
+}
+
+var asciiBorder = Border{
+	TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+	Horizontal: "-", Vertical: "|",
+	TeeLeft: "+", TeeRight: "+",
+}
+
+// borders holds the named border styles that decks can select
+var borders = map[string]Border{
+	"light":   lightBorder,
+	"rounded": roundedBorder,
+	"heavy":   heavyBorder,
+	"double":  doubleBorder,
+	"ascii":   asciiBorder,
+}
+
+// GetBorder returns a named border style
+func GetBorder(name string) (Border, bool) {
+	b, ok := borders[name]
+	return b, ok
+}
+
+// BorderNames returns the names of all border styles
+func BorderNames() []string {
+	names := make([]string, 0, len(borders))
+	for name := range borders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// themes holds the built-in themes
+var themes = map[string]*Theme{
+	"default": {
+		Name:    "default",
+		Label:   Style{color.New(color.FgCyan)},
+		Value:   Style{color.New(color.FgHiWhite)},
+		Heading: Style{color.New(color.FgHiWhite, color.Bold)},
+		Muted:   Style{color.New(color.FgHiBlack)},
+		Border:  lightBorder,
+		Symbols: symbolSets[SymbolsNerd],
+	},
+	"mono": {
+		Name:    "mono",
+		Border:  asciiBorder,
+		Symbols: symbolSets[SymbolsUnicode],
+	},
+	"solarized": {
+		Name:    "solarized",
+		Label:   Style{rgb(38, 139, 210)},
+		Value:   Style{rgb(147, 161, 161)},
+		Heading: Style{rgb(181, 137, 0)},
+		Muted:   Style{rgb(88, 110, 117)},
+		Border:  lightBorder,
+		Symbols: symbolSets[SymbolsUnicode],
+	},
+	"mystic": {
+		Name:    "mystic",
+		Label:   Style{rgb(186, 140, 255)},
+		Value:   Style{rgb(255, 223, 150)},
+		Heading: Style{rgb(255, 200, 87)},
+		Muted:   Style{rgb(120, 100, 150)},
+		Border:  doubleBorder,
+		Symbols: symbolSets[SymbolsUnicode],
+	},
+	HighContrast: {
+		Name:    HighContrast,
+		Label:   Style{color.New(color.Bold)},
+		Heading: Style{color.New(color.Bold, color.Underline)},
+		Border:  heavyBorder,
+		Symbols: symbolSets[SymbolsUnicode],
+		fixed:   true,
+	},
+}
+
+// lightThemes holds the styles of the built-in themes for light terminal
+// backgrounds, where the bright text of the dark styles fades into the
+// background. Themes without an entry look the same on both.
+var lightThemes = map[string]*Theme{
+	"default": {
+		Label:   Style{color.New(color.FgBlue)},
+		Value:   Style{color.New(color.FgBlack)},
+		Heading: Style{color.New(color.FgBlack, color.Bold)},
+		Muted:   Style{color.New(color.FgHiBlack)},
+	},
+	"solarized": {
+		Label:   Style{rgb(38, 139, 210)},
+		Value:   Style{rgb(88, 110, 117)},
+		Heading: Style{rgb(181, 137, 0)},
+		Muted:   Style{rgb(147, 161, 161)},
+	},
+	"mystic": {
+		Label:   Style{rgb(106, 58, 184)},
+		Value:   Style{rgb(128, 88, 16)},
+		Heading: Style{rgb(150, 96, 0)},
+		Muted:   Style{rgb(130, 110, 160)},
+	},
+}
+
+// ForBackground returns a copy of the theme styled for a terminal background,
+// BackgroundDark or BackgroundLight
+func (t *Theme) ForBackground(background string) *Theme {
+	copied := *t
+	copied.Light = background == BackgroundLight
+	if light, ok := lightThemes[t.Name]; ok && copied.Light {
+		copied.Label, copied.Value, copied.Heading, copied.Muted = light.Label, light.Value, light.Heading, light.Muted
+	}
+	return &copied
+}
+
+// WithAccent returns a copy of the theme with labels and headings drawn in the
+// given 24-bit color. Themes without colors, such as mono, and high-contrast
+// are left unchanged. On light backgrounds, the accent is darkened as far as
+// needed to stay readable.
+func (t *Theme) WithAccent(r, g, b uint8) *Theme {
+	copied := *t
+	if t.Label.color == nil || t.fixed {
+		return &copied
+	}
+
+	if t.Light {
+		r, g, b = darkenForLight(r, g, b)
+	}
+	accent := Style{rgb(int(r), int(g), int(b))}
+	copied.Label = accent
+	copied.Heading = accent
+	return &copied
+}
+
+// minLightContrast is the contrast ratio with white that accents on light
+// backgrounds are darkened to, the WCAG minimum for text
+const minLightContrast = 4.5
+
+// darkenForLight lowers the lightness of a color, keeping its hue, until it
+// has enough contrast with a white background
+func darkenForLight(r, g, b uint8) (uint8, uint8, uint8) {
+	c := colorful.Color{R: float64(r) / 255, G: float64(g) / 255, B: float64(b) / 255}
+	for l, a, bb := c.Lab(); l > 0 && contrastWithWhite(c) < minLightContrast; l, a, bb = c.Lab() {
+		c = colorful.Lab(l-0.02, a, bb).Clamped()
+	}
+	return c.RGB255()
+}
+
+// contrastWithWhite returns the WCAG contrast ratio of a color with white
+func contrastWithWhite(c colorful.Color) float64 {
+	lr, lg, lb := c.LinearRgb()
+	return 1.05 / (luminance(lr, lg, lb) + 0.05)
+}
+
+// WithBorder returns a copy of the theme using a named border style. Unknown
+// names keep the theme's own border, and ASCII-only symbol sets always use
+// the ASCII border.
+func (t *Theme) WithBorder(name string) *Theme {
+	copied := *t
+	if t.Symbols.Name == SymbolsASCII {
+		copied.Border = asciiBorder
+	} else if b, ok := borders[name]; ok {
+		copied.Border = b
+	}
+	return &copied
+}
+
+// Get returns a built-in theme by name. An empty name selects the default theme.
+func Get(name string) (*Theme, error) {
+	if name == "" {
+		name = Default
+	}
+
+	t, ok := themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme: %s (available: %v)", name, Names())
+	}
+
+	// Return a copy so callers can adjust it without affecting other users
+	copied := *t
+	return &copied, nil
+}
+
+// Names returns the names of all built-in themes
+func Names() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
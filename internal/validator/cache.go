@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arcanaland/cartomancer/internal/atomicfile"
+)
+
+// cacheVersion changes whenever the file checks do, invalidating older caches
+const cacheVersion = 1
+
+// Cache persists the results of the per-file checks between runs, keyed by
+// content hash, so re-validating a large deck only reads the files that
+// changed. A nil *Cache checks every file.
+type Cache struct {
+	path    string
+	version int
+	files   map[string]cacheEntry
+	seen    map[string]bool
+	dirty   bool
+}
+
+// cacheEntry is the cached result of one file. Size and modification time
+// skip hashing files that were not touched.
+type cacheEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash"`
+	fileResult
+}
+
+// cacheFile is the on-disk form of a Cache
+type cacheFile struct {
+	Version int                   `json:"version"`
+	Files   map[string]cacheEntry `json:"files"`
+}
+
+// CachePath returns the cache file for a deck in a cache directory
+func CachePath(cacheDir, deckPath string) string {
+	if abs, err := filepath.Abs(deckPath); err == nil {
+		deckPath = abs
+	}
+	sum := sha256.Sum256([]byte(deckPath))
+	return filepath.Join(cacheDir, "validate", hex.EncodeToString(sum[:8])+".json")
+}
+
+// LoadCache reads a validation cache. Missing, unreadable or outdated caches
+// start empty.
+func LoadCache(path string) *Cache {
+	c := &Cache{path: path, version: cacheVersion, files: map[string]cacheEntry{}, seen: map[string]bool{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != cacheVersion {
+		c.dirty = true
+		return c
+	}
+	if file.Files != nil {
+		c.files = file.Files
+	}
+	return c
+}
+
+// Save writes the cache if any results changed
+func (c *Cache) Save() error {
+	if c == nil || !c.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+
+	data, err := json.Marshal(cacheFile{Version: c.version, Files: c.files})
+	if err != nil {
+		return fmt.Errorf("error encoding validation cache: %v", err)
+	}
+	// An interrupted run or a concurrent one leaves a complete cache
+	if err := atomicfile.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing validation cache: %v", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// lookup returns the cached result of a file if its content is unchanged
+func (c *Cache) lookup(rel, path string, info fs.FileInfo) (fileResult, bool) {
+	if c == nil {
+		return fileResult{}, false
+	}
+	c.seen[rel] = true
+
+	entry, ok := c.files[rel]
+	if !ok || entry.Size != info.Size() {
+		return fileResult{}, false
+	}
+	if entry.ModTime.Equal(info.ModTime()) {
+		return entry.fileResult, true
+	}
+
+	// Touched but possibly unchanged, as after a checkout
+	hash, err := hashFile(path)
+	if err != nil || hash != entry.Hash {
+		return fileResult{}, false
+	}
+	entry.ModTime = info.ModTime()
+	c.files[rel] = entry
+	c.dirty = true
+	return entry.fileResult, true
+}
+
+// store records the result of checking a file
+func (c *Cache) store(rel string, info fs.FileInfo, data []byte, result fileResult) {
+	if c == nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	c.files[rel] = cacheEntry{
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		Hash:       hex.EncodeToString(sum[:]),
+		fileResult: result,
+	}
+	c.seen[rel] = true
+	c.dirty = true
+}
+
+// prune forgets files that were not seen since the last prune, so deleted
+// files do not accumulate
+func (c *Cache) prune() {
+	if c == nil {
+		return
+	}
+	for rel := range c.files {
+		if !c.seen[rel] {
+			delete(c.files, rel)
+			c.dirty = true
+		}
+	}
+	c.seen = map[string]bool{}
+}
+
+// hashFile returns the hex SHA-256 of a file's content
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,132 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arcanaland/cartomancer/internal/archive"
+)
+
+// fileResult holds the problems found in one asset file
+type fileResult struct {
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// validateFiles checks the contents of every card image and ANSI art file.
+// Files unchanged since the cached run are not read again.
+func (v *Validator) validateFiles() {
+	ig, _ := archive.LoadIgnore(v.DeckPath) // Errors are reported by validateOrphans
+	filepath.WalkDir(v.DeckPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(v.DeckPath, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if path != v.DeckPath && (strings.HasPrefix(d.Name(), ".") || ig.Match(rel, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Working files named in .deckignore are not part of the deck
+		check := fileCheck(path)
+		if check == nil || ig.Match(rel, false) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		var result fileResult
+		if cached, ok := v.Cache.lookup(rel, path, info); ok {
+			result = cached
+		} else {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				result = fileResult{Errors: []string{fmt.Sprintf("error reading %s: %v", rel, err)}}
+			} else {
+				result = check(rel, data)
+				v.Cache.store(rel, info, data, result)
+			}
+		}
+
+		v.Results.Errors = append(v.Results.Errors, result.Errors...)
+		v.Results.Warnings = append(v.Results.Warnings, result.Warnings...)
+		return nil
+	})
+	v.Cache.prune()
+}
+
+// fileCheck returns the content check for a file, or nil for files that are
+// not card art
+func fileCheck(path string) func(rel string, data []byte) fileResult {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return checkRaster
+	case ".webp":
+		return checkWebP
+	case ".svg":
+		return checkSVG
+	case ".ansi":
+		return checkAnsi
+	}
+	return nil
+}
+
+// checkRaster checks that an image decodes
+func checkRaster(rel string, data []byte) fileResult {
+	var r fileResult
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: invalid image: %v", rel, err))
+	} else if config.Width == 0 || config.Height == 0 {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: image has no pixels", rel))
+	}
+	return r
+}
+
+// checkWebP checks the RIFF header of a WebP image, which is not decoded
+func checkWebP(rel string, data []byte) fileResult {
+	var r fileResult
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: invalid image: not a WebP file", rel))
+	}
+	return r
+}
+
+// checkSVG checks that a file holds an SVG document
+func checkSVG(rel string, data []byte) fileResult {
+	var r fileResult
+	if !bytes.Contains(data, []byte("<svg")) {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: invalid image: no <svg> element", rel))
+	}
+	return r
+}
+
+// checkAnsi checks that ANSI art is non-empty UTF-8 text
+func checkAnsi(rel string, data []byte) fileResult {
+	var r fileResult
+	switch {
+	case len(bytes.TrimSpace(data)) == 0:
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%s: empty ANSI art", rel))
+	case !utf8.Valid(data):
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: ANSI art is not valid UTF-8", rel))
+	}
+	return r
+}
@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/archive"
+	"github.com/arcanaland/cartomancer/internal/deck"
+)
+
+// junkFiles are files operating systems leave behind in folders
+var junkFiles = map[string]bool{
+	".ds_store":   true,
+	"thumbs.db":   true,
+	"desktop.ini": true,
+}
+
+// validateOrphans warns about files in the structured directories that are
+// not part of the deck: layered sources, copies such as "00 copy.png" and
+// files left behind by the operating system. Files named in .deckignore are
+// expected working files and are not reported.
+func (v *Validator) validateOrphans() {
+	ig, err := archive.LoadIgnore(v.DeckPath)
+	if err != nil {
+		v.Results.Errors = append(v.Results.Errors, err.Error())
+		return
+	}
+
+	// Card art is expected at the card's path in every tier, or wherever
+	// deck.toml points a custom card's image
+	cardPaths := make(map[string]bool)
+	if d, err := deck.LoadDeck(v.DeckPath); err == nil {
+		for _, c := range d.Cards() {
+			cardPaths[strings.ReplaceAll(c.ID, ".", "/")] = true
+		}
+	}
+	var deckConfig deck.DeckConfig
+	if _, err := deck.DecodeTomlFile(filepath.Join(v.DeckPath, "deck.toml"), &deckConfig); err == nil && deckConfig.CustomCards != nil {
+		for _, custom := range deckConfig.CustomCards.MajorArcana {
+			if custom.Image != "" {
+				cardPaths[path.Clean(filepath.ToSlash(custom.Image))] = true
+			}
+		}
+	}
+
+	checkTier := func(extensions []string) func(rel, inDir string) string {
+		return func(rel, inDir string) string {
+			ext := path.Ext(inDir)
+			if slices.Contains(extensions, strings.ToLower(ext)) && (cardPaths[strings.TrimSuffix(inDir, ext)] || cardPaths[rel]) {
+				return ""
+			}
+			return "card art"
+		}
+	}
+	checks := map[string]func(rel, inDir string) string{
+		"card_backs": func(rel, inDir string) string {
+			if isImage(inDir) && !strings.Contains(inDir, "/") {
+				return ""
+			}
+			return "a card back image"
+		},
+		"names": func(rel, inDir string) string {
+			if path.Ext(inDir) == ".toml" && !strings.Contains(inDir, "/") {
+				return ""
+			}
+			return "a names file"
+		},
+	}
+	for _, tier := range imageTiers(v.DeckPath) {
+		checks[tier] = checkTier(imageExtensions)
+	}
+	entries, _ := os.ReadDir(v.DeckPath)
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "ansi") {
+			checks[entry.Name()] = checkTier([]string{".ansi"})
+		}
+	}
+
+	for _, dir := range sortedKeys(checks) {
+		check := checks[dir]
+		filepath.WalkDir(filepath.Join(v.DeckPath, dir), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			rel, err := filepath.Rel(v.DeckPath, p)
+			if err != nil {
+				return nil
+			}
+			rel = filepath.ToSlash(rel)
+			hidden := strings.HasPrefix(d.Name(), ".")
+			if d.IsDir() {
+				if rel != dir && (hidden || ig.Match(rel, true)) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			switch {
+			case junkFiles[strings.ToLower(d.Name())] || strings.HasPrefix(d.Name(), "._"):
+				v.Results.Warnings = append(v.Results.Warnings,
+					fmt.Sprintf("%s was left by the operating system; delete it", rel))
+			case hidden || ig.Match(rel, false):
+				// Left out of packages already
+			default:
+				if kind := check(rel, strings.TrimPrefix(rel, dir+"/")); kind != "" {
+					v.Results.Warnings = append(v.Results.Warnings,
+						fmt.Sprintf("%s is not %s of this deck; delete it or list it in %s to leave it out of packages", rel, kind, archive.IgnoreFile))
+				}
+			}
+			return nil
+		})
+	}
+}
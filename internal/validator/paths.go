@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arcanaland/cartomancer/internal/archive"
+)
+
+// Path limits that keep a deck usable on every platform. Windows refuses paths
+// over 260 characters by default, and a deck installed in a library is nested
+// some way below the drive, so paths inside the deck are kept well short of it.
+const (
+	maxPathLength = 200 // Bytes in a path relative to the deck root
+	maxNameLength = 255 // Bytes in one file or directory name
+)
+
+// windowsReserved are device names Windows will not use for a file, with or
+// without an extension
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// AuditPaths checks the names of the files in a deck for problems that break
+// it when moved between macOS, Linux and Windows or packaged: names differing
+// only by case, names that are not UTF-8 or that Windows reserves, overly long
+// paths, and symlinks. Hidden files and directories and those named in
+// .deckignore are left out, as they are from packages. Problems are returned
+// in path order.
+func AuditPaths(root string) ([]string, error) {
+	ig, err := archive.LoadIgnore(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return checkCaseCollisions(path, ".", ig, &problems)
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(d.Name(), ".") || ig.Match(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		problems = append(problems, checkName(rel, d.Name())...)
+		if len(rel) > maxPathLength {
+			problems = append(problems, fmt.Sprintf("%s: path is %d bytes long, over the portable limit of %d", rel, len(rel), maxPathLength))
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			problems = append(problems, fmt.Sprintf("%s: symlinks are not portable and cannot be packaged", rel))
+		}
+
+		if d.IsDir() {
+			return checkCaseCollisions(path, rel, ig, &problems)
+		}
+		return nil
+	})
+	if err != nil {
+		return problems, fmt.Errorf("error auditing paths: %v", err)
+	}
+	return problems, nil
+}
+
+// checkName returns the problems with one file or directory name
+func checkName(rel, name string) []string {
+	var problems []string
+	if !utf8.ValidString(name) {
+		problems = append(problems, fmt.Sprintf("%s: name is not valid UTF-8", rel))
+	}
+	if len(name) > maxNameLength {
+		problems = append(problems, fmt.Sprintf("%s: name is %d bytes long, over the limit of %d", rel, len(name), maxNameLength))
+	}
+
+	base, _, _ := strings.Cut(name, ".")
+	if windowsReserved[strings.ToUpper(strings.TrimRight(base, " "))] {
+		problems = append(problems, fmt.Sprintf("%s: %s is a reserved name on Windows", rel, base))
+	}
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"\|?*`, r)
+	}); i >= 0 {
+		problems = append(problems, fmt.Sprintf("%s: name contains %q, which Windows does not allow", rel, name[i:i+1]))
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		problems = append(problems, fmt.Sprintf("%s: name ends in a dot or space, which Windows drops", rel))
+	}
+	return problems
+}
+
+// checkCaseCollisions reports names in a directory that differ only by case,
+// which would overwrite each other on case-insensitive file systems
+func checkCaseCollisions(dir, rel string, ig *archive.Ignore, problems *[]string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	byFold := make(map[string][]string)
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") && !ig.Match(path.Join(rel, entry.Name()), entry.IsDir()) {
+			key := strings.ToLower(entry.Name())
+			byFold[key] = append(byFold[key], entry.Name())
+		}
+	}
+
+	var collisions []string
+	for _, names := range byFold {
+		if len(names) > 1 {
+			collisions = append(collisions, strings.Join(names, ", "))
+		}
+	}
+	sort.Strings(collisions)
+	for _, names := range collisions {
+		*problems = append(*problems, fmt.Sprintf("%s: names differ only by case: %s", rel, names))
+	}
+	return nil
+}
+
+// validatePaths warns about file names that are not portable. Such decks
+// work where they were made, so these are not errors.
+func (v *Validator) validatePaths() {
+	problems, err := AuditPaths(v.DeckPath)
+	if err != nil {
+		v.Results.Warnings = append(v.Results.Warnings, err.Error())
+	}
+	v.Results.Warnings = append(v.Results.Warnings, problems...)
+}
@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/archive"
+	"github.com/arcanaland/cartomancer/internal/deck"
+)
+
+// Policy is the hosting constraints a registry or CI pipeline places on the
+// decks it accepts, loaded from a policy file:
+//
+//	max_archive_mib = 50                # size of the packaged .tar.gz
+//	max_image_mib = 2                   # size of any one image file
+//	required_tiers = ["scalable", "h750"]
+type Policy struct {
+	MaxArchiveMiB float64  `toml:"max_archive_mib"` // 0 for no limit
+	MaxImageMiB   float64  `toml:"max_image_mib"`   // 0 for no limit
+	RequiredTiers []string `toml:"required_tiers"`  // Tiers that must hold art for every card
+}
+
+// mib is the number of bytes in a mebibyte
+const mib = 1 << 20
+
+// LoadPolicy reads a policy file
+func LoadPolicy(path string) (*Policy, error) {
+	var p Policy
+	md, err := toml.DecodeFile(path, &p)
+	if err != nil {
+		return nil, fmt.Errorf("error loading policy %s: %v", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("error loading policy %s: unknown key %s", path, undecoded[0])
+	}
+	if p.MaxArchiveMiB < 0 || p.MaxImageMiB < 0 {
+		return nil, fmt.Errorf("error loading policy %s: sizes must not be negative", path)
+	}
+	return &p, nil
+}
+
+// ValidatePolicy checks the deck against a registry policy. Violations are
+// errors, as the registry would reject the deck.
+func (v *Validator) ValidatePolicy(p *Policy) (ValidationResults, error) {
+	if p.MaxArchiveMiB > 0 {
+		// Packages are rooted at the deck ID, which affects the size slightly
+		var deckConfig deck.DeckConfig
+		deck.DecodeTomlFile(filepath.Join(v.DeckPath, "deck.toml"), &deckConfig)
+		size, err := ArchiveSize(v.DeckPath, deckConfig.Deck.ID)
+		if err != nil {
+			return v.Results, err
+		}
+		if limit := int64(p.MaxArchiveMiB * mib); size > limit {
+			v.Results.Errors = append(v.Results.Errors,
+				fmt.Sprintf("policy: packaged archive is %s, over the limit of %s", formatSize(size), formatSize(limit)))
+		}
+	}
+
+	if p.MaxImageMiB > 0 {
+		limit := int64(p.MaxImageMiB * mib)
+		err := archive.Walk(v.DeckPath, func(rel string, entry fs.DirEntry) error {
+			if entry.IsDir() || !isImage(rel) {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if info.Size() > limit {
+				v.Results.Errors = append(v.Results.Errors,
+					fmt.Sprintf("policy: %s is %s, over the image limit of %s", rel, formatSize(info.Size()), formatSize(limit)))
+			}
+			return nil
+		})
+		if err != nil {
+			return v.Results, fmt.Errorf("error checking image sizes: %v", err)
+		}
+	}
+
+	if len(p.RequiredTiers) > 0 {
+		d, err := deck.LoadDeck(v.DeckPath)
+		if err != nil {
+			return v.Results, fmt.Errorf("error loading deck for policy: %v", err)
+		}
+		coverage := make(map[string]Coverage)
+		for _, c := range tierCoverage(d) {
+			coverage[c.Name] = c
+		}
+		for _, tier := range p.RequiredTiers {
+			c, ok := coverage[tier]
+			switch {
+			case !ok:
+				v.Results.Errors = append(v.Results.Errors, fmt.Sprintf("policy: required tier %s is missing", tier))
+			case c.Present < c.Total:
+				v.Results.Errors = append(v.Results.Errors,
+					fmt.Sprintf("policy: required tier %s covers %d of %d cards", tier, c.Present, c.Total))
+			}
+		}
+	}
+
+	return v.Results, nil
+}
+
+// ArchiveSize returns the size of the archive deck package would write for
+// the deck, with entries under prefix, without writing it
+func ArchiveSize(deckPath, prefix string) (int64, error) {
+	var w countingWriter
+	if err := archive.CreateTarGz(&w, deckPath, prefix); err != nil {
+		return 0, err
+	}
+	return int64(w), nil
+}
+
+// countingWriter discards what is written to it, counting the bytes
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// isImage reports whether a file name has an image extension
+func isImage(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range imageExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// formatSize formats a size in bytes in the largest unit it reaches
+func formatSize(size int64) string {
+	switch {
+	case size >= mib:
+		return fmt.Sprintf("%.1f MiB", float64(size)/mib)
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
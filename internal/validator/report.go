@@ -0,0 +1,304 @@
+package validator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/license"
+)
+
+// Report is a scored summary of a deck's quality, for registries that want a
+// single signal per deck
+type Report struct {
+	Deck       string      `json:"deck"`
+	Path       string      `json:"path"`
+	Score      int         `json:"score"` // Weighted average of the category scores, 0-100
+	Grade      string      `json:"grade"` // A to F
+	Categories []Category  `json:"categories"`
+	Tiers      []Coverage  `json:"tiers"`            // Art coverage per asset tier
+	Matrix     *TierMatrix `json:"matrix,omitempty"` // Image tiers holding each card's art
+	Languages  []Coverage  `json:"languages"`        // Name coverage per language file
+	Metadata   []Field     `json:"metadata"`
+	Errors     []string    `json:"errors,omitempty"`
+	Warnings   []string    `json:"warnings,omitempty"`
+}
+
+// Category is a scored aspect of a deck
+type Category struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"` // Share of the overall score, in percent
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// Coverage counts the cards an asset tier or language file covers
+type Coverage struct {
+	Name    string `json:"name"`
+	Present int    `json:"present"`
+	Total   int    `json:"total"`
+}
+
+// Percent returns the share of cards covered
+func (c Coverage) Percent() int {
+	if c.Total == 0 {
+		return 0
+	}
+	return c.Present * 100 / c.Total
+}
+
+// Field is a metadata field checked for completeness
+type Field struct {
+	Name    string `json:"name"`
+	Present bool   `json:"present"`
+}
+
+// Category weights, in percent
+const (
+	weightAssets     = 35
+	weightAltText    = 25
+	weightLanguages  = 15
+	weightMetadata   = 15
+	weightValidation = 10
+)
+
+// warningPenalty is the validation score lost per warning
+const warningPenalty = 10
+
+// Report scores the deck from the results of Validate. Decks that cannot be
+// loaded only score for validation, which their errors already zero.
+func (v *Validator) Report(results ValidationResults) *Report {
+	r := &Report{
+		Deck:     filepath.Base(v.DeckPath),
+		Path:     v.DeckPath,
+		Errors:   results.Errors,
+		Warnings: results.Warnings,
+	}
+
+	d, err := deck.LoadDeck(v.DeckPath)
+	if err == nil {
+		r.Deck = d.ID
+		r.Tiers = tierCoverage(d)
+		r.Matrix = tierMatrix(d)
+		r.Languages = languageCoverage(d)
+		r.Metadata = metadataFields(d)
+	}
+
+	// Art counts once a card has an image in some tier; ANSI tiers do not
+	// count, as they are generated from the images
+	assets := Category{Name: "Assets", Weight: weightAssets, Detail: "no loadable card art"}
+	if d != nil {
+		covered := 0
+		tiers := imageTiers(d.Path)
+		for _, c := range d.Cards() {
+			if hasArt(d.Path, tiers, c) {
+				covered++
+			}
+		}
+		assets.Score = percent(covered, len(d.Cards()))
+		assets.Detail = fmt.Sprintf("%d of %d cards have art in %d image tiers", covered, len(d.Cards()), len(tiers))
+	}
+
+	altText := Category{Name: "Alt text", Weight: weightAltText, Detail: "no loadable cards"}
+	if d != nil {
+		described := len(d.Cards()) - len(d.MissingAltText())
+		altText.Score = percent(described, len(d.Cards()))
+		altText.Detail = fmt.Sprintf("%d of %d cards described", described, len(d.Cards()))
+	}
+
+	languages := Category{Name: "Languages", Weight: weightLanguages, Detail: "no language files"}
+	if len(r.Languages) > 0 {
+		sum := 0
+		for _, l := range r.Languages {
+			sum += l.Percent()
+		}
+		languages.Score = sum / len(r.Languages)
+		languages.Detail = fmt.Sprintf("%d languages, %d%% of names on average", len(r.Languages), languages.Score)
+	}
+
+	metadata := Category{Name: "Metadata", Weight: weightMetadata, Detail: "deck.toml could not be loaded"}
+	if len(r.Metadata) > 0 {
+		present := 0
+		var missing []string
+		for _, f := range r.Metadata {
+			if f.Present {
+				present++
+			} else {
+				missing = append(missing, f.Name)
+			}
+		}
+		metadata.Score = percent(present, len(r.Metadata))
+		metadata.Detail = fmt.Sprintf("%d of %d fields", present, len(r.Metadata))
+		if len(missing) > 0 {
+			metadata.Detail += "; missing " + strings.Join(missing, ", ")
+		}
+	}
+
+	validation := Category{Name: "Validation", Weight: weightValidation}
+	if len(results.Errors) == 0 {
+		validation.Score = max(0, 100-warningPenalty*len(results.Warnings))
+	}
+	validation.Detail = fmt.Sprintf("%d errors, %d warnings", len(results.Errors), len(results.Warnings))
+
+	r.Categories = []Category{assets, altText, languages, metadata, validation}
+	for _, c := range r.Categories {
+		r.Score += c.Score * c.Weight
+	}
+	r.Score /= 100
+	r.Grade = grade(r.Score)
+
+	return r
+}
+
+// grade converts a score to a letter grade
+func grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	}
+	return "F"
+}
+
+// percent returns n as a share of total, in whole percent
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
+
+// cardFile returns the path of a card's asset in a tier directory without its
+// extension, e.g. h750/minor_arcana/cups/queen
+func cardFile(tierDir string, c *card.Card) string {
+	return filepath.Join(append([]string{tierDir}, strings.Split(c.ID, ".")...)...)
+}
+
+// imageExtensions are the extensions of card images
+var imageExtensions = []string{".svg", ".png", ".jpg", ".jpeg", ".webp"}
+
+// hasFile reports whether a file exists with any of the extensions
+func hasFile(base string, extensions []string) bool {
+	for _, ext := range extensions {
+		if _, err := os.Stat(base + ext); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// imageTiers returns the deck's image tier directories: scalable and h*
+func imageTiers(deckPath string) []string {
+	var tiers []string
+	entries, _ := os.ReadDir(deckPath)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if _, err := fmt.Sscanf(name, "h%d", new(int)); name == "scalable" || (strings.HasPrefix(name, "h") && err == nil) {
+			tiers = append(tiers, name)
+		}
+	}
+	return tiers
+}
+
+// hasArt reports whether a card has an image in any of the tiers
+func hasArt(deckPath string, tiers []string, c *card.Card) bool {
+	for _, tier := range tiers {
+		if hasFile(cardFile(filepath.Join(deckPath, tier), c), imageExtensions) {
+			return true
+		}
+	}
+	return false
+}
+
+// tierCoverage counts the cards in each image and ANSI tier
+func tierCoverage(d *deck.Deck) []Coverage {
+	cards := d.Cards()
+	var coverage []Coverage
+
+	count := func(tier string, extensions []string) {
+		cov := Coverage{Name: tier, Total: len(cards)}
+		for _, c := range cards {
+			if hasFile(cardFile(filepath.Join(d.Path, tier), c), extensions) {
+				cov.Present++
+			}
+		}
+		coverage = append(coverage, cov)
+	}
+
+	for _, tier := range imageTiers(d.Path) {
+		count(tier, imageExtensions)
+	}
+	entries, _ := os.ReadDir(d.Path)
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), "ansi") {
+			count(entry.Name(), []string{".ansi"})
+		}
+	}
+	return coverage
+}
+
+// languageCoverage counts the cards named in each language file
+func languageCoverage(d *deck.Deck) []Coverage {
+	names, err := d.LocalizedNames()
+	if err != nil {
+		return nil
+	}
+
+	cards := d.Cards()
+	var coverage []Coverage
+	for lang, byID := range names {
+		cov := Coverage{Name: lang, Total: len(cards)}
+		for _, c := range cards {
+			if byID[c.ID] != "" {
+				cov.Present++
+			}
+		}
+		coverage = append(coverage, cov)
+	}
+	sort.Slice(coverage, func(i, j int) bool { return coverage[i].Name < coverage[j].Name })
+	return coverage
+}
+
+// metadataFields checks the deck.toml fields registries display
+func metadataFields(d *deck.Deck) []Field {
+	_, iconErr := d.IconPath()
+	credited := false
+	for _, c := range d.Cards() {
+		if c.Credit.Artist != "" {
+			credited = true
+			break
+		}
+	}
+	return []Field{
+		{"name", d.Name != ""},
+		{"version", d.Version != ""},
+		{"author", d.Author != ""},
+		{"license", knownLicense(d.License)},
+		{"description", d.Description != ""},
+		{"icon", iconErr == nil},
+		{"tags", len(d.Tags) > 0},
+		{"website", d.Website != "" || d.Publisher != ""},
+		{"art credits", credited},
+	}
+}
+
+// knownLicense reports whether a license expression parses and uses only SPDX IDs
+func knownLicense(text string) bool {
+	if text == "" {
+		return false
+	}
+	expr, err := license.Parse(text)
+	return err == nil && len(expr.Unknown) == 0
+}
@@ -0,0 +1,130 @@
+package validator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/deck"
+)
+
+// maxListedCards is how many cards a tier warning names before summarizing
+const maxListedCards = 5
+
+// TierMatrix records which image tiers hold art for each card
+type TierMatrix struct {
+	Tiers []string    `json:"tiers"` // scalable first, then raster tiers by height
+	Cards []CardTiers `json:"cards"`
+}
+
+// CardTiers is a row of the tier matrix
+type CardTiers struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Present []bool `json:"present"` // Art in each of the matrix's tiers, in order
+}
+
+// Complete reports whether the card has art in every tier
+func (c CardTiers) Complete() bool {
+	for _, present := range c.Present {
+		if !present {
+			return false
+		}
+	}
+	return true
+}
+
+// tierMatrix builds the tier matrix of a deck's image tiers
+func tierMatrix(d *deck.Deck) *TierMatrix {
+	m := &TierMatrix{Tiers: imageTiers(d.Path)}
+	sort.Slice(m.Tiers, func(i, j int) bool { return tierHeight(m.Tiers[i]) < tierHeight(m.Tiers[j]) })
+
+	for _, c := range d.Cards() {
+		row := CardTiers{ID: c.ID, Name: c.Name, Present: make([]bool, len(m.Tiers))}
+		for i, tier := range m.Tiers {
+			row.Present[i] = hasFile(cardFile(filepath.Join(d.Path, tier), c), imageExtensions)
+		}
+		m.Cards = append(m.Cards, row)
+	}
+	return m
+}
+
+// tierHeight returns the height of a raster tier such as h750, or 0 for the
+// scalable tier so that it sorts first
+func tierHeight(tier string) int {
+	height, _ := strconv.Atoi(strings.TrimPrefix(tier, "h"))
+	return height
+}
+
+// validateTiers checks that the image tiers agree: every raster tier should
+// hold the same cards, and a scalable tier should cover every card the raster
+// tiers do. Gaps are warnings, as cards fall back to another tier.
+func (v *Validator) validateTiers() {
+	d, err := deck.LoadDeck(v.DeckPath)
+	if err != nil {
+		return // Reported by the other checks
+	}
+	m := tierMatrix(d)
+
+	var raster []int
+	scalable := -1
+	for i, tier := range m.Tiers {
+		if tier == "scalable" {
+			scalable = i
+		} else {
+			raster = append(raster, i)
+		}
+	}
+
+	for _, i := range raster {
+		var missing []string
+		for _, row := range m.Cards {
+			if row.Present[i] {
+				continue
+			}
+			var in []string
+			for _, j := range raster {
+				if row.Present[j] {
+					in = append(in, m.Tiers[j])
+				}
+			}
+			if len(in) > 0 {
+				missing = append(missing, fmt.Sprintf("%s (in %s)", row.ID, strings.Join(in, ", ")))
+			}
+		}
+		if len(missing) > 0 {
+			v.Results.Warnings = append(v.Results.Warnings,
+				fmt.Sprintf("tier %s is missing %d cards other raster tiers have: %s", m.Tiers[i], len(missing), listCards(missing)))
+		}
+	}
+
+	if scalable < 0 {
+		return
+	}
+	var missing []string
+	for _, row := range m.Cards {
+		if row.Present[scalable] {
+			continue
+		}
+		for _, j := range raster {
+			if row.Present[j] {
+				missing = append(missing, row.ID)
+				break
+			}
+		}
+	}
+	if len(missing) > 0 {
+		v.Results.Warnings = append(v.Results.Warnings,
+			fmt.Sprintf("scalable tier is missing %d cards the raster tiers have: %s", len(missing), listCards(missing)))
+	}
+}
+
+// listCards joins the first few cards of a list, summarizing the rest
+func listCards(cards []string) string {
+	if len(cards) <= maxListedCards {
+		return strings.Join(cards, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(cards[:maxListedCards], ", "), len(cards)-maxListedCards)
+}
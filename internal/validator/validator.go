@@ -2,11 +2,24 @@ package validator
 
 import (
 	"fmt"
+	"image/color"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/BurntSushi/toml"
+	"github.com/arcanaland/cartomancer/internal/archive"
+	"github.com/arcanaland/cartomancer/internal/canon"
+	"github.com/arcanaland/cartomancer/internal/card"
+	"github.com/arcanaland/cartomancer/internal/deck"
+	"github.com/arcanaland/cartomancer/internal/license"
+	"github.com/arcanaland/cartomancer/internal/script"
+	"github.com/arcanaland/cartomancer/internal/spread"
+	"github.com/arcanaland/cartomancer/internal/theme"
+	"github.com/arcanaland/cartomancer/pkg/render"
+	"github.com/lucasb-eyer/go-colorful"
 )
 
 type ValidationResults struct {
@@ -16,6 +29,8 @@ type ValidationResults struct {
 
 type Validator struct {
 	DeckPath string
+	Public   bool   // Validate for a public registry, warning about proprietary licenses
+	Cache    *Cache // Results of the per-file checks from earlier runs, if any
 	Results  ValidationResults
 }
 
@@ -36,7 +51,14 @@ func (v *Validator) Validate() (ValidationResults, error) {
 	v.validateMajorArcana()
 	v.validateMinorArcana()
 	v.validateNames()
+	v.validateReadings()
+	v.validateContentWarnings()
 	v.validateAnsiArt()
+	v.validateVariants()
+	v.validateTiers()
+	v.validateFiles()
+	v.validateOrphans()
+	v.validatePaths()
 
 	return v.Results, nil
 }
@@ -47,11 +69,33 @@ func (v *Validator) validateDeckToml() error {
 		return fmt.Errorf("deck.toml not found in %s", v.DeckPath)
 	}
 
-	var deckConfig DeckConfig
-	if _, err := toml.DecodeFile(deckTomlPath, &deckConfig); err != nil {
+	var deckConfig deck.DeckConfig
+	if _, err := deck.DecodeTomlFile(deckTomlPath, &deckConfig); err != nil {
 		return fmt.Errorf("error parsing deck.toml: %v", err)
 	}
 
+	// Enforce loader limits and keep referenced files inside the deck root
+	for _, err := range deck.CheckLimits(v.DeckPath, &deckConfig) {
+		v.Results.Errors = append(v.Results.Errors, err.Error())
+	}
+	for _, err := range deck.CheckAliases(&deckConfig) {
+		v.Results.Errors = append(v.Results.Errors, err.Error())
+	}
+	for _, err := range deck.CheckCustomSuits(&deckConfig) {
+		v.Results.Errors = append(v.Results.Errors, err.Error())
+	}
+	for _, err := range deck.CheckCardURL(&deckConfig) {
+		v.Results.Errors = append(v.Results.Errors, err.Error())
+	}
+
+	if deckConfig.Style != nil {
+		v.validateStyle(deckConfig.Style, &deckConfig)
+	}
+
+	if deckConfig.Credits != nil {
+		v.validateCredits(deckConfig.Credits, &deckConfig)
+	}
+
 	if deckConfig.Deck.ID == "" {
 		v.Results.Errors = append(v.Results.Errors, "deck.id is required in deck.toml")
 	}
@@ -71,6 +115,13 @@ func (v *Validator) validateDeckToml() error {
 			fmt.Sprintf("unsupported schema_version: %s (supported: 1.0)", deckConfig.Deck.SchemaVersion))
 	}
 
+	if deckConfig.Deck.License == "" {
+		v.Results.Warnings = append(v.Results.Warnings,
+			"deck.license is not set; declare an SPDX license expression such as \"CC-BY-4.0\"")
+	} else {
+		v.validateLicense("deck.license", deckConfig.Deck.License)
+	}
+
 	// Validate card backs
 	if deckConfig.CardBacks != nil {
 		if len(deckConfig.CardBacks.Variants) > 1 && deckConfig.CardBacks.Default == "" {
@@ -82,8 +133,7 @@ func (v *Validator) validateDeckToml() error {
 			if variant.Image == "" {
 				v.Results.Errors = append(v.Results.Errors,
 					fmt.Sprintf("card_backs.variants.%s.image is required", variantName))
-			} else {
-				imagePath := filepath.Join(v.DeckPath, variant.Image)
+			} else if imagePath, err := deck.SafeJoin(v.DeckPath, variant.Image); err == nil {
 				if _, err := os.Stat(imagePath); os.IsNotExist(err) {
 					v.Results.Errors = append(v.Results.Errors,
 						fmt.Sprintf("card back image not found: %s", variant.Image))
@@ -94,6 +144,58 @@ func (v *Validator) validateDeckToml() error {
 	return nil
 }
 
+// validateLicense checks that a license is a well-formed SPDX expression
+// naming known licenses
+func (v *Validator) validateLicense(key, text string) {
+	expr, err := license.Parse(text)
+	if err != nil {
+		v.Results.Warnings = append(v.Results.Warnings, fmt.Sprintf("%s: %v", key, err))
+		return
+	}
+
+	for _, id := range expr.Unknown {
+		v.Results.Warnings = append(v.Results.Warnings,
+			fmt.Sprintf("%s: unknown SPDX license ID %q", key, id))
+	}
+
+	if v.Public && expr.Proprietary() {
+		v.Results.Warnings = append(v.Results.Warnings,
+			fmt.Sprintf("%s %q references a proprietary license; public registries expect a license from the SPDX list", key, text))
+	}
+}
+
+// ValidateRules checks every card in the deck against custom validation rules
+func (v *Validator) ValidateRules(rules []*script.Rule) (ValidationResults, error) {
+	if len(rules) == 0 {
+		return v.Results, nil
+	}
+
+	d, err := deck.LoadDeck(v.DeckPath)
+	if err != nil {
+		return v.Results, fmt.Errorf("error loading deck for rules: %v", err)
+	}
+
+	for _, rule := range rules {
+		for _, c := range d.Cards() {
+			message, err := rule.Check(c)
+			if err != nil {
+				return v.Results, err
+			}
+			if message == "" {
+				continue
+			}
+
+			if rule.Severity == "warning" {
+				v.Results.Warnings = append(v.Results.Warnings, message)
+			} else {
+				v.Results.Errors = append(v.Results.Errors, message)
+			}
+		}
+	}
+
+	return v.Results, nil
+}
+
 // validateDirectoryStructure checks if the deck has the expected directory structure
 func (v *Validator) validateDirectoryStructure() {
 	// Check for card_backs directory
@@ -134,6 +236,12 @@ func (v *Validator) validateDirectoryStructure() {
 	if _, err := os.Stat(namesDir); os.IsNotExist(err) {
 		v.Results.Warnings = append(v.Results.Warnings, "names directory not found")
 	}
+
+	// Generated placeholder art is for testing and should not be published
+	if _, err := os.Stat(filepath.Join(v.DeckPath, deck.PlaceholderDir)); err == nil {
+		v.Results.Warnings = append(v.Results.Warnings,
+			deck.PlaceholderDir+" directory found: some cards still use generated placeholder art")
+	}
 }
 
 // validateCardBacks checks if card backs exist and are valid
@@ -154,6 +262,261 @@ func (v *Validator) validateCardBacks() {
 	if len(entries) == 0 {
 		v.Results.Errors = append(v.Results.Errors, "no card backs found in card_backs directory")
 	}
+
+	var deckConfig deck.DeckConfig
+	if _, err := deck.DecodeTomlFile(filepath.Join(v.DeckPath, "deck.toml"), &deckConfig); err != nil {
+		return // Already reported by validateDeckToml
+	}
+	backs := deckConfig.CardBacks
+	if backs == nil {
+		backs = &deck.CardBackSection{}
+	}
+	ig, _ := archive.LoadIgnore(v.DeckPath) // Errors are reported by validateOrphans
+	v.reconcileCardBacks(backs, entries, ig)
+	for _, name := range sortedKeys(deckConfig.Variants) {
+		back := deckConfig.Variants[name].CardBack
+		if back == "" {
+			continue
+		}
+		if _, ok := backs.Variants[back]; !ok {
+			v.Results.Errors = append(v.Results.Errors,
+				fmt.Sprintf("variants.%s.card_back: %q is not declared in [card_backs.variants]", name, back))
+		}
+	}
+}
+
+// reconcileCardBacks checks the [card_backs] table against the files in
+// card_backs/: the default must name a declared variant, every variant should
+// have alt text and an image in card_backs/, and every file there should be
+// declared unless .deckignore names it. Decks without the table fall back to the first file, so there is
+// nothing to reconcile.
+func (v *Validator) reconcileCardBacks(backs *deck.CardBackSection, entries []os.DirEntry, ig *archive.Ignore) {
+	if len(backs.Variants) == 0 {
+		return
+	}
+
+	if _, ok := backs.Variants[backs.Default]; backs.Default != "" && !ok {
+		v.Results.Errors = append(v.Results.Errors,
+			fmt.Sprintf("card_backs.default: %q is not declared in [card_backs.variants]", backs.Default))
+	}
+
+	declared := make(map[string]bool)
+	for _, name := range sortedKeys(backs.Variants) {
+		variant := backs.Variants[name]
+		if strings.TrimSpace(variant.AltText) == "" {
+			v.Results.Warnings = append(v.Results.Warnings,
+				fmt.Sprintf("card_backs.variants.%s has no alt_text", name))
+		}
+		if variant.Image == "" {
+			continue // Already reported by validateDeckToml
+		}
+		image := filepath.ToSlash(filepath.Clean(variant.Image))
+		declared[image] = true
+		if path.Dir(image) != "card_backs" {
+			v.Results.Warnings = append(v.Results.Warnings,
+				fmt.Sprintf("card_backs.variants.%s.image is outside card_backs/: %s", name, variant.Image))
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || junkFiles[strings.ToLower(entry.Name())] {
+			continue // Operating system files are reported by validateOrphans
+		}
+		if rel := "card_backs/" + entry.Name(); !declared[rel] && !ig.Match(rel, false) {
+			v.Results.Warnings = append(v.Results.Warnings,
+				fmt.Sprintf("card back %s is not declared in [card_backs.variants]", rel))
+		}
+	}
+}
+
+// sortedKeys returns the keys of a map in order, for stable messages
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateStyle checks accent colors, border names and the suits and cards
+// referenced by the [style] table
+func (v *Validator) validateStyle(style *deck.StyleSection, config *deck.DeckConfig) {
+	entries := style.StyleEntries()
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		entry := entries[key]
+		if entry.Accent != "" {
+			if _, err := deck.ParseHexColor(entry.Accent); err != nil {
+				v.Results.Errors = append(v.Results.Errors, fmt.Sprintf("%s.accent: %v", key, err))
+			}
+		}
+		if entry.Border != "" {
+			if _, ok := theme.GetBorder(entry.Border); !ok {
+				v.Results.Errors = append(v.Results.Errors,
+					fmt.Sprintf("%s.border: unknown border style %q (available: %s)",
+						key, entry.Border, strings.Join(theme.BorderNames(), ", ")))
+			}
+		}
+	}
+
+	for suit := range style.Suits {
+		if !knownSuit(config, suit) {
+			v.Results.Errors = append(v.Results.Errors, fmt.Sprintf("style.suits.%s: unknown suit", suit))
+		}
+	}
+
+	for id := range style.Cards {
+		if _, err := card.ParseID(id); err != nil {
+			v.Results.Errors = append(v.Results.Errors, fmt.Sprintf("style.cards: invalid card ID %q", id))
+		}
+	}
+
+	v.validateAccentContrast(style)
+}
+
+// minAccentDistance is the smallest CIEDE2000 difference at which accent
+// colors are told apart at a glance
+const minAccentDistance = 10
+
+// validateAccentContrast warns about suit and major arcana accent colors
+// that look alike to readers with a common color vision deficiency, though
+// they differ with full color vision
+func (v *Validator) validateAccentContrast(style *deck.StyleSection) {
+	accents := make(map[string]color.RGBA)
+	add := func(key string, entry *deck.StyleEntry) {
+		if entry == nil {
+			return
+		}
+		if c, err := deck.ParseHexColor(entry.Accent); err == nil {
+			accents[key+".accent"] = c
+		}
+	}
+	add("style.major_arcana", style.MajorArcana)
+	for suit, entry := range style.Suits {
+		add("style.suits."+suit, &entry)
+	}
+
+	distance := func(a, b color.RGBA) float64 {
+		ca, _ := colorful.MakeColor(a)
+		cb, _ := colorful.MakeColor(b)
+		return ca.DistanceCIEDE2000(cb) * 100
+	}
+	keys := sortedKeys(accents)
+	for i, a := range keys {
+		for _, b := range keys[i+1:] {
+			if distance(accents[a], accents[b]) < minAccentDistance {
+				continue
+			}
+			var alike []string
+			for _, deficiency := range render.Deficiencies() {
+				if distance(render.SimulateCVD(accents[a], deficiency), render.SimulateCVD(accents[b], deficiency)) < minAccentDistance {
+					alike = append(alike, deficiency)
+				}
+			}
+			if len(alike) > 0 {
+				v.Results.Warnings = append(v.Results.Warnings,
+					fmt.Sprintf("%s and %s look alike with %s; make them differ in lightness as well as hue",
+						a, b, strings.Join(alike, " and ")))
+			}
+		}
+	}
+}
+
+// knownSuit reports whether a suit is one of the standard suits or a custom
+// suit declared in [custom_cards.minor_arcana]
+func knownSuit(config *deck.DeckConfig, suit string) bool {
+	if _, err := card.ParseID(suit + ".ace"); err == nil {
+		return true
+	}
+	if config.CustomCards == nil {
+		return false
+	}
+	_, ok := config.CustomCards.MinorArcana[suit]
+	return ok
+}
+
+// validateCredits checks the licenses and the suits and cards referenced by
+// the [credits] table
+func (v *Validator) validateCredits(credits *deck.CreditsSection, config *deck.DeckConfig) {
+	entries := credits.CreditEntries()
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		entry := entries[key]
+		if key != "credits" && entry == (deck.CreditEntry{}) {
+			v.Results.Warnings = append(v.Results.Warnings,
+				fmt.Sprintf("%s: empty entry (expected artist, source or license)", key))
+		}
+		if entry.License != "" {
+			v.validateLicense(key+".license", entry.License)
+		}
+	}
+
+	for suit := range credits.Suits {
+		if !knownSuit(config, suit) {
+			v.Results.Errors = append(v.Results.Errors, fmt.Sprintf("credits.suits.%s: unknown suit", suit))
+		}
+	}
+
+	for id := range credits.Cards {
+		if _, err := card.ParseID(id); err != nil {
+			v.Results.Errors = append(v.Results.Errors, fmt.Sprintf("credits.cards: invalid card ID %q", id))
+		}
+	}
+}
+
+// validateVariants checks that variant overlay directories exist and only
+// override files that are present in the base deck
+func (v *Validator) validateVariants() {
+	var deckConfig deck.DeckConfig
+	if _, err := deck.DecodeTomlFile(filepath.Join(v.DeckPath, "deck.toml"), &deckConfig); err != nil {
+		return // Already reported by validateDeckToml
+	}
+
+	for name, variant := range deckConfig.Variants {
+		if variant.Directory == "" {
+			continue
+		}
+
+		overlayDir, err := deck.SafeJoin(v.DeckPath, variant.Directory)
+		if err != nil {
+			continue // Already reported by deck.CheckLimits
+		}
+		if info, err := os.Stat(overlayDir); err != nil || !info.IsDir() {
+			v.Results.Errors = append(v.Results.Errors,
+				fmt.Sprintf("variants.%s.directory not found: %s", name, variant.Directory))
+			continue
+		}
+
+		filepath.WalkDir(overlayDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(overlayDir, path)
+			if err != nil {
+				return err
+			}
+
+			// Overrides may change the file format, so match on the path without extension
+			base := filepath.Join(v.DeckPath, strings.TrimSuffix(rel, filepath.Ext(rel)))
+			if matches, _ := filepath.Glob(base + ".*"); len(matches) == 0 {
+				v.Results.Warnings = append(v.Results.Warnings,
+					fmt.Sprintf("variant %s overrides a file missing from the base deck: %s", name, filepath.ToSlash(rel)))
+			}
+			return nil
+		})
+	}
 }
 
 // validateMajorArcana checks if major arcana cards exist
@@ -187,8 +550,7 @@ func (v *Validator) validateMajorArcana() {
 		foundMajorArcana = true
 		// Check for all 22 major arcana cards (00-21)
 		missingCards := []string{}
-		for i := 0; i <= 21; i++ {
-			cardName := fmt.Sprintf("%02d", i)
+		for _, cardName := range canon.MajorNumbers() {
 			found := false
 
 			// Check for common image extensions
@@ -216,6 +578,16 @@ func (v *Validator) validateMajorArcana() {
 	}
 }
 
+// deckRanks returns the ranks each standard suit of the deck has, leaving out
+// the courts that [aliases.courts] drops
+func (v *Validator) deckRanks() []string {
+	var deckConfig deck.DeckConfig
+	if _, err := deck.DecodeTomlFile(filepath.Join(v.DeckPath, "deck.toml"), &deckConfig); err != nil {
+		return canon.Ranks() // Already reported by validateDeckToml
+	}
+	return deckConfig.Ranks()
+}
+
 // validateMinorArcana checks if minor arcana cards exist
 func (v *Validator) validateMinorArcana() {
 	// Find the image directories
@@ -246,12 +618,9 @@ func (v *Validator) validateMinorArcana() {
 
 		foundMinorArcana = true
 
-		// Check for all four suits
-		suits := []string{"wands", "cups", "swords", "pentacles"}
-		cardRanks := []string{
-			"ace", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
-			"page", "knight", "queen", "king",
-		}
+		// Check for all four suits, with the courts the deck declares
+		suits := canon.Suits()
+		cardRanks := v.deckRanks()
 
 		for _, suit := range suits {
 			suitDir := filepath.Join(minorArcanaDir, suit)
@@ -261,7 +630,7 @@ func (v *Validator) validateMinorArcana() {
 				continue
 			}
 
-			// Check for all 14 cards in each suit
+			// Check for every rank in each suit
 			missingCards := []string{}
 			for _, rank := range cardRanks {
 				found := false
@@ -318,7 +687,7 @@ func (v *Validator) validateNames() {
 		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".toml") {
 			langPath := filepath.Join(namesDir, entry.Name())
 			var langConfig NameConfig
-			if _, err := toml.DecodeFile(langPath, &langConfig); err != nil {
+			if _, err := deck.DecodeTomlFile(langPath, &langConfig); err != nil {
 				v.Results.Errors = append(v.Results.Errors,
 					fmt.Sprintf("error parsing language file %s: %v", entry.Name(), err))
 				continue
@@ -345,7 +714,7 @@ func (v *Validator) validateNames() {
 			}
 
 			if !hasAltText && langConfig.MinorArcana != nil {
-				for _, suit := range []string{"wands", "cups", "swords", "pentacles"} {
+				for _, suit := range canon.Suits() {
 					suitConfig := langConfig.MinorArcana.GetSuit(suit)
 					if suitConfig != nil && suitConfig.AltText != nil {
 						hasAltText = true
@@ -363,6 +732,79 @@ func (v *Validator) validateNames() {
 
 	if !foundValidLangFile {
 		v.Results.Errors = append(v.Results.Errors, "no valid language files found in names directory")
+		return
+	}
+
+	// Keys that are not cards would be ignored by the loader, and alt text
+	// makes the card art accessible to screen reader users
+	if d, err := deck.LoadDeck(v.DeckPath); err == nil {
+		for _, err := range d.CheckNames() {
+			v.Results.Errors = append(v.Results.Errors, err.Error())
+		}
+		if missing := d.MissingAltText(); len(missing) > 0 {
+			v.Results.Warnings = append(v.Results.Warnings,
+				fmt.Sprintf("%d of %d cards have no alt text (draft it with 'cartomancer deck gen-alt-text')",
+					len(missing), len(d.Cards())))
+		}
+	}
+}
+
+// validateContentWarnings checks the cards and warnings named in the
+// [content_warnings] table
+func (v *Validator) validateContentWarnings() {
+	d, err := deck.LoadDeck(v.DeckPath)
+	if err != nil {
+		return // Reported by the other checks
+	}
+	for _, err := range d.CheckContentWarnings() {
+		v.Results.Errors = append(v.Results.Errors, err.Error())
+	}
+}
+
+// validateReadings checks the author's interpretations in readings/<lang>.toml
+func (v *Validator) validateReadings() {
+	readingsDir := filepath.Join(v.DeckPath, "readings")
+	entries, err := os.ReadDir(readingsDir)
+	if os.IsNotExist(err) {
+		return // Readings are optional
+	} else if err != nil {
+		v.Results.Errors = append(v.Results.Errors,
+			fmt.Sprintf("error reading readings directory: %v", err))
+		return
+	}
+
+	d, err := deck.LoadDeck(v.DeckPath)
+	if err != nil {
+		return // Reported by the other checks
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			v.Results.Warnings = append(v.Results.Warnings,
+				fmt.Sprintf("unexpected file in readings directory: %s", entry.Name()))
+			continue
+		}
+
+		r, problems, err := d.LoadReadings(filepath.Join(readingsDir, entry.Name()))
+		if err != nil {
+			v.Results.Errors = append(v.Results.Errors, err.Error())
+			continue
+		}
+		for _, problem := range problems {
+			v.Results.Errors = append(v.Results.Errors, fmt.Sprintf("%s: %v", entry.Name(), problem))
+		}
+
+		ids := make([]string, 0, len(r.Spreads))
+		for id := range r.Spreads {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if _, err := spread.Get(id); err != nil {
+				v.Results.Warnings = append(v.Results.Warnings,
+					fmt.Sprintf("%s: spreads.%s is not a built-in spread, so readers need it installed", entry.Name(), id))
+			}
+		}
 	}
 }
 
@@ -400,8 +842,7 @@ func (v *Validator) validateAnsiDirectory(ansiDir, dirName string) {
 	} else {
 		// Check for all 22 major arcana cards (00-21)
 		missingCards := []string{}
-		for i := 0; i <= 21; i++ {
-			cardName := fmt.Sprintf("%02d", i)
+		for _, cardName := range canon.MajorNumbers() {
 			cardPath := filepath.Join(majorArcanaDir, cardName+".ansi")
 			if _, err := os.Stat(cardPath); os.IsNotExist(err) {
 				missingCards = append(missingCards, cardName)
@@ -421,12 +862,9 @@ func (v *Validator) validateAnsiDirectory(ansiDir, dirName string) {
 		v.Results.Warnings = append(v.Results.Warnings,
 			fmt.Sprintf("minor_arcana directory not found in %s", dirName))
 	} else {
-		// Check for all four suits
-		suits := []string{"wands", "cups", "swords", "pentacles"}
-		cardRanks := []string{
-			"ace", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
-			"page", "knight", "queen", "king",
-		}
+		// Check for all four suits, with the courts the deck declares
+		suits := canon.Suits()
+		cardRanks := v.deckRanks()
 
 		for _, suit := range suits {
 			suitDir := filepath.Join(minorArcanaDir, suit)
@@ -436,7 +874,7 @@ func (v *Validator) validateAnsiDirectory(ansiDir, dirName string) {
 				continue
 			}
 
-			// Check for all 14 cards in each suit
+			// Check for every rank in each suit
 			missingCards := []string{}
 			for _, rank := range cardRanks {
 				cardPath := filepath.Join(suitDir, rank+".ansi")
@@ -452,6 +890,27 @@ func (v *Validator) validateAnsiDirectory(ansiDir, dirName string) {
 			}
 		}
 	}
+
+	// Hand-written art often draws wrongly, so it is linted; the first
+	// problem of each file is reported
+	filepath.WalkDir(ansiDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".ansi" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if problems := render.LintANSI(string(data)); len(problems) > 0 {
+			rel, _ := filepath.Rel(v.DeckPath, path)
+			message := fmt.Sprintf("%s: %s", filepath.ToSlash(rel), problems[0])
+			if len(problems) > 1 {
+				message += fmt.Sprintf(" (and %d more problems; see cartomancer ansi preview)", len(problems)-1)
+			}
+			v.Results.Warnings = append(v.Results.Warnings, message)
+		}
+		return nil
+	})
 }
 
 // Deck configuration structures
@@ -478,6 +937,7 @@ type DeckSection struct {
 	UpdatedDate   string               `toml:"updated_date"`
 	Publisher     string               `toml:"publisher"`
 	Website       string               `toml:"website"`
+	CardURL       string               `toml:"card_url"`
 	Tags          []string             `toml:"tags"`
 	ExcludedCards *ExcludedCardSection `toml:"excluded_cards"`
 }
@@ -0,0 +1,296 @@
+// Package websocket implements the server side of the WebSocket protocol
+// (RFC 6455) for text messages, which is all the serve command needs.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// acceptGUID is appended to the client key to compute Sec-WebSocket-Accept
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// MaxMessageSize bounds the size of messages read from clients
+const MaxMessageSize = 64 * 1024
+
+// writeTimeout bounds the time spent writing a frame, so a client that stops
+// reading cannot stall its writers
+const writeTimeout = 10 * time.Second
+
+// Frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// ErrClosed is returned by ReadMessage once the client has closed the connection
+var ErrClosed = errors.New("websocket: connection closed")
+
+// Conn is a server-side WebSocket connection
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	mu     sync.Mutex // Serialises writes
+	closed bool
+
+	readTimeout time.Duration
+}
+
+// Upgrade switches an HTTP request to the WebSocket protocol. Requests from a
+// browser page on another host are refused, so other sites cannot open
+// sessions on a user's local server.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected a WebSocket upgrade request", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: not an upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, fmt.Errorf("websocket: missing key")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, r.Host) {
+			http.Error(w, "cross-origin WebSocket requests are not allowed", http.StatusForbidden)
+			return nil, fmt.Errorf("websocket: origin %s not allowed", origin)
+		}
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket upgrade not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: response does not support hijacking")
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: %v", err)
+	}
+	// Deadlines the HTTP server set for reading the request stay on the
+	// connection, so they are cleared for the connection's new life
+	conn.SetDeadline(time.Time{})
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: %v", err)
+	}
+
+	return &Conn{conn: conn, br: brw.Reader}, nil
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for a client key
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContains reports whether a comma-separated header holds a token
+func headerContains(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMessage returns the next text or binary message, answering pings and
+// reassembling fragmented messages. ErrClosed is returned when the client
+// closes the connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	started := false
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.writeFrame(opClose, payload)
+			c.Close()
+			return nil, ErrClosed
+		case opText, opBinary:
+			if started {
+				return nil, c.fail("new message before the previous one ended")
+			}
+			started = true
+		case opContinuation:
+			if !started {
+				return nil, c.fail("continuation frame without a message")
+			}
+		default:
+			return nil, c.fail(fmt.Sprintf("unknown opcode %d", opcode))
+		}
+
+		if len(message)+len(payload) > MaxMessageSize {
+			return nil, c.fail("message too large")
+		}
+		message = append(message, payload...)
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// SetReadTimeout sets how long ReadMessage waits for each frame from the
+// client, pongs included, before failing. Zero, the default, waits forever.
+// Pinging the client more often than the timeout keeps idle connections open
+// for as long as the client answers.
+func (c *Conn) SetReadTimeout(d time.Duration) {
+	c.readTimeout = d
+}
+
+// readFrame reads a single frame, unmasking its payload
+func (c *Conn) readFrame() (bool, byte, []byte, error) {
+	if c.readTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail("reserved bits set")
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, c.fail("client frames must be masked")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if opcode >= opClose && (length > 125 || !fin) {
+		return false, 0, nil, c.fail("invalid control frame")
+	}
+	if length > MaxMessageSize {
+		return false, 0, nil, c.fail("message too large")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage sends a text message. It is safe to call concurrently.
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// Ping sends a ping, which the client answers with a pong. It is safe to
+// call concurrently.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// writeFrame writes a single unfragmented, unmasked frame
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// fail closes the connection with a protocol error
+func (c *Conn) fail(reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, 1002)
+	c.writeFrame(opClose, append(payload, reason...))
+	c.Close()
+	return fmt.Errorf("websocket: %s", reason)
+}
+
+// Close closes the underlying connection
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
@@ -0,0 +1,152 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455, section 1.3
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("AcceptKey() = %q", got)
+	}
+}
+
+// clientFrame builds a masked client frame
+func clientFrame(fin bool, opcode byte, payload string) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i := range payload {
+		frame = append(frame, payload[i]^mask[i%4])
+	}
+	return frame
+}
+
+func TestEcho(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(msg)
+		}
+	}))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: " + strings.TrimPrefix(srv.URL, "http://") + "\r\n" +
+		"Upgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	// A fragmented message with a ping in the middle
+	conn.Write(clientFrame(false, opText, "hel"))
+	conn.Write(clientFrame(true, opPing, "p"))
+	conn.Write(clientFrame(true, opContinuation, "lo"))
+
+	readFrame := func() (byte, string) {
+		var header [2]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			t.Fatal(err)
+		}
+		payload := make([]byte, header[1]&0x7F)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			t.Fatal(err)
+		}
+		return header[0] & 0x0F, string(payload)
+	}
+
+	if op, payload := readFrame(); op != opPong || payload != "p" {
+		t.Errorf("got opcode %d %q, want pong", op, payload)
+	}
+	if op, payload := readFrame(); op != opText || payload != "hello" {
+		t.Errorf("got opcode %d %q, want text \"hello\"", op, payload)
+	}
+
+	conn.Write(clientFrame(true, opClose, string(binary.BigEndian.AppendUint16(nil, 1000))))
+	if op, _ := readFrame(); op != opClose {
+		t.Errorf("got opcode %d, want close", op)
+	}
+}
+
+func TestUpgradeRejectsCrossOrigin(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://localhost:8080/", nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Origin", "https://example.com")
+
+	rec := httptest.NewRecorder()
+	if _, err := Upgrade(rec, req); err == nil {
+		t.Fatal("Upgrade() succeeded for a cross-origin request")
+	}
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestReadTimeout(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	c := &Conn{conn: server, br: bufio.NewReader(server)}
+	defer c.Close()
+	c.SetReadTimeout(100 * time.Millisecond)
+
+	// The client answers a ping late, then sends a message after the
+	// timeout would have passed had the pong not reset it
+	go func() {
+		var header [2]byte
+		if _, err := io.ReadFull(client, header[:]); err != nil || header[0]&0x0F != opPing {
+			return
+		}
+		time.Sleep(70 * time.Millisecond)
+		client.Write(clientFrame(true, opPong, ""))
+		time.Sleep(70 * time.Millisecond)
+		client.Write(clientFrame(true, opText, "still here"))
+	}()
+
+	if err := c.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := c.ReadMessage(); err != nil || string(msg) != "still here" {
+		t.Fatalf("ReadMessage() = %q, %v", msg, err)
+	}
+
+	// A silent client times out
+	var netErr net.Error
+	if _, err := c.ReadMessage(); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("ReadMessage() from a silent client = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Deck browser for cartomancer serve --static. Everything shown comes from
+// the JSON API; a token given as ?token= in the page address is sent in the
+// Authorization header of every request.
+"use strict";
+
+const token = new URLSearchParams(location.search).get("token");
+const status = document.getElementById("status");
+
+// request fetches an API path with the page's token, throwing the server's
+// error message on failure
+async function request(path) {
+  const headers = token ? { Authorization: "Bearer " + token } : {};
+  const response = await fetch(path, { headers });
+  if (!response.ok) {
+    throw new Error((await response.text()).trim() || response.statusText);
+  }
+  return response;
+}
+
+// api fetches a JSON API path
+async function api(path) {
+  return (await request(path)).json();
+}
+
+// Images cannot send the token themselves, so with a token they are fetched
+// once scrolled into view and shown from the downloaded data
+const lazyImages = new IntersectionObserver((entries) => {
+  for (const entry of entries) {
+    if (entry.isIntersecting) {
+      lazyImages.unobserve(entry.target);
+      fetchImage(entry.target);
+    }
+  }
+});
+
+async function fetchImage(img) {
+  try {
+    const response = await request(img.dataset.src);
+    img.onload = () => URL.revokeObjectURL(img.src);
+    img.src = URL.createObjectURL(await response.blob());
+  } catch (err) {
+    img.title = err.message;
+  }
+}
+
+// el creates an element with text content
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text) {
+    node.textContent = text;
+  }
+  if (className) {
+    node.className = className;
+  }
+  return node;
+}
+
+// cardFigure shows a card image captioned with its name and position
+function cardFigure(card, height) {
+  const figure = el("figure");
+  const img = el("img");
+  img.alt = card.alt_text || card.name;
+  if (token) {
+    img.dataset.src = card.image + "?h=" + height;
+    lazyImages.observe(img);
+  } else {
+    img.src = card.image + "?h=" + height;
+    img.loading = "lazy";
+  }
+  figure.append(img);
+
+  const caption = el("figcaption");
+  if (card.position) {
+    caption.append(el("span", card.position, "position"));
+  }
+  caption.append(card.name);
+  figure.append(caption);
+  return figure;
+}
+
+async function showDecks() {
+  const list = document.querySelector(".decks");
+  const decks = await api("/decks");
+  list.replaceChildren();
+  if (decks.length === 0) {
+    list.append(el("li", "No decks found in the deck library."));
+  }
+  for (const deck of decks) {
+    const item = el("li");
+    const link = el("a", deck.name);
+    link.href = "#deck=" + encodeURIComponent(deck.id);
+    item.append(link, " ");
+    item.append(el("span", deck.card_count + " cards" + (deck.default ? ", default" : ""), "meta"));
+    list.append(item);
+  }
+}
+
+async function showDeck(id) {
+  const section = document.getElementById("deck");
+  const deck = await api("/decks/" + encodeURIComponent(id));
+  section.dataset.id = deck.id;
+  document.getElementById("deck-heading").textContent = deck.name;
+  section.querySelector(".description").textContent = deck.description || "";
+  section.querySelector(".reading").hidden = true;
+
+  const grid = section.querySelector(".cards.grid");
+  grid.replaceChildren();
+  for (const card of deck.cards) {
+    const item = el("li");
+    item.append(cardFigure(card, 300));
+    grid.append(item);
+  }
+  section.hidden = false;
+  document.getElementById("decks").hidden = true;
+}
+
+async function loadSpreads() {
+  const select = document.querySelector("form.draw select[name=spread]");
+  for (const spread of await api("/spreads")) {
+    const option = el("option", spread.name + " (" + spread.positions.length + ")");
+    option.value = spread.id;
+    select.append(option);
+  }
+}
+
+async function draw(event) {
+  event.preventDefault();
+  const form = event.target;
+  const section = document.getElementById("deck");
+  const params = new URLSearchParams({ pool: form.pool.value });
+  if (form.spread.value) {
+    params.set("spread", form.spread.value);
+  } else {
+    params.set("count", form.count.value);
+  }
+
+  const result = await api("/decks/" + encodeURIComponent(section.dataset.id) + "/draw?" + params);
+  const reading = section.querySelector(".reading");
+  const cards = reading.querySelector(".cards");
+  cards.replaceChildren();
+  for (const card of result.cards) {
+    const item = el("li");
+    item.append(cardFigure(card, 400));
+    cards.append(item);
+  }
+  reading.querySelector(".seed").textContent = "Seed " + result.seed;
+  reading.hidden = false;
+  status.textContent = "Drew " + result.cards.length + (result.cards.length === 1 ? " card" : " cards");
+}
+
+// route shows the deck named in the address, or the deck list
+async function route() {
+  status.textContent = "";
+  const id = new URLSearchParams(location.hash.slice(1)).get("deck");
+  try {
+    if (id) {
+      await showDeck(id);
+    } else {
+      document.getElementById("deck").hidden = true;
+      document.getElementById("decks").hidden = false;
+      await showDecks();
+    }
+  } catch (err) {
+    status.textContent = err.message;
+  }
+}
+
+document.querySelector("form.draw").addEventListener("submit", (event) => {
+  draw(event).catch((err) => {
+    status.textContent = err.message;
+  });
+});
+window.addEventListener("hashchange", route);
+loadSpreads().catch((err) => {
+  status.textContent = err.message;
+});
+route();
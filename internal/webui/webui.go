@@ -0,0 +1,22 @@
+// Package webui holds the deck browser web UI that the serve command serves
+// with --static: a single page listing the decks of the deck library, their
+// cards with images and alt text, and draws made through the JSON API.
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the web UI's files
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServerFS(files)
+}
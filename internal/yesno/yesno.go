@@ -0,0 +1,175 @@
+// Package yesno answers yes or no questions with the common card methods:
+// counting upright against reversed cards, and hunting for an ace.
+package yesno
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+// Answers
+const (
+	Yes     = "yes"
+	No      = "no"
+	Unclear = "unclear"
+)
+
+// Drawn is a card dealt for a reading, upright or reversed
+type Drawn struct {
+	Card     *card.Card
+	Reversed bool
+}
+
+// Result is the answer of a yes/no reading
+type Result struct {
+	Method     string
+	Answer     string  // Yes, No or Unclear
+	Confidence float64 // From 0 to 1
+	Reason     string  // How the cards gave the answer
+	Dealt      []Drawn // Every card dealt
+	Supporting []Drawn // The cards the answer rests on
+}
+
+// Options tunes a method. Zero values take the method's defaults.
+type Options struct {
+	Cards          int     // Cards drawn (reversals) or most cards dealt looking for an ace (aces)
+	ReversalChance float64 // Chance of a card being dealt reversed, default 0.5
+}
+
+// Method is a way of reading a yes or no answer from the cards
+type Method struct {
+	Name         string
+	Description  string
+	DefaultCards int
+
+	read func(dealt []Drawn) Result
+}
+
+// methods lists the supported methods
+var methods = []*Method{
+	{
+		Name:         "reversals",
+		Description:  "More upright than reversed cards means yes, more reversed no",
+		DefaultCards: 3,
+		read:         readReversals,
+	},
+	{
+		Name:         "aces",
+		Description:  "Deal until an ace turns up: upright means yes, reversed no",
+		DefaultCards: 13,
+		read:         readAces,
+	},
+}
+
+// DefaultMethod is the method used unless another is chosen
+const DefaultMethod = "reversals"
+
+// Methods returns the supported methods
+func Methods() []*Method {
+	return methods
+}
+
+// Names returns the names of the supported methods
+func Names() []string {
+	names := make([]string, len(methods))
+	for i, m := range methods {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// Get returns a method by name
+func Get(name string) (*Method, error) {
+	for _, m := range methods {
+		if m.Name == name {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown yes/no method: %s (supported: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Read shuffles the cards, deals them upright or reversed and reads the answer
+func (m *Method) Read(cards []*card.Card, opts Options, rng *rand.Rand) (*Result, error) {
+	n := opts.Cards
+	if n <= 0 {
+		n = m.DefaultCards
+	}
+	chance := opts.ReversalChance
+	if chance <= 0 {
+		chance = 0.5
+	}
+	if chance > 1 {
+		return nil, fmt.Errorf("reversal chance must be between 0 and 1, not %g", chance)
+	}
+	if n > len(cards) {
+		return nil, fmt.Errorf("method %s needs %d cards but only %d are available", m.Name, n, len(cards))
+	}
+
+	shuffled := make([]*card.Card, len(cards))
+	copy(shuffled, cards)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	dealt := make([]Drawn, n)
+	for i, c := range shuffled[:n] {
+		dealt[i] = Drawn{Card: c, Reversed: rng.Float64() < chance}
+	}
+
+	result := m.read(dealt)
+	result.Method = m.Name
+	return &result, nil
+}
+
+// readReversals counts upright against reversed cards
+func readReversals(dealt []Drawn) Result {
+	upright := 0
+	for _, d := range dealt {
+		if !d.Reversed {
+			upright++
+		}
+	}
+	reversed := len(dealt) - upright
+
+	r := Result{Dealt: dealt, Supporting: dealt}
+	switch {
+	case upright > reversed:
+		r.Answer = Yes
+	case reversed > upright:
+		r.Answer = No
+	default:
+		r.Answer = Unclear
+	}
+	diff := upright - reversed
+	if diff < 0 {
+		diff = -diff
+	}
+	r.Confidence = float64(diff) / float64(len(dealt))
+	r.Reason = fmt.Sprintf("%d upright, %d reversed", upright, reversed)
+	return r
+}
+
+// readAces looks for the first ace among the dealt cards
+func readAces(dealt []Drawn) Result {
+	for i, d := range dealt {
+		if d.Card.Type != "minor_arcana" || d.Card.Rank != "ace" {
+			continue
+		}
+		r := Result{
+			Answer:     Yes,
+			Confidence: 1 - float64(i)/float64(len(dealt)),
+			Dealt:      dealt[:i+1],
+			Supporting: []Drawn{d},
+			Reason:     fmt.Sprintf("upright ace after %d cards", i+1),
+		}
+		if d.Reversed {
+			r.Answer = No
+			r.Reason = fmt.Sprintf("reversed ace after %d cards", i+1)
+		}
+		return r
+	}
+	return Result{Answer: Unclear, Dealt: dealt, Reason: fmt.Sprintf("no ace in %d cards", len(dealt))}
+}
@@ -0,0 +1,68 @@
+package yesno
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/arcanaland/cartomancer/internal/card"
+)
+
+func TestReadReversals(t *testing.T) {
+	c := card.NewMajorArcana(0)
+	tests := []struct {
+		reversed   []bool
+		answer     string
+		confidence float64
+	}{
+		{[]bool{false, false, true}, Yes, 1.0 / 3},
+		{[]bool{true, true, true}, No, 1},
+		{[]bool{false, true}, Unclear, 0},
+	}
+	for _, tt := range tests {
+		var dealt []Drawn
+		for _, r := range tt.reversed {
+			dealt = append(dealt, Drawn{Card: c, Reversed: r})
+		}
+		r := readReversals(dealt)
+		if r.Answer != tt.answer || r.Confidence != tt.confidence {
+			t.Errorf("%v: answer %s (%g), want %s (%g)", tt.reversed, r.Answer, r.Confidence, tt.answer, tt.confidence)
+		}
+	}
+}
+
+func TestReadAces(t *testing.T) {
+	dealt := []Drawn{
+		{Card: card.NewMajorArcana(3)},
+		{Card: card.NewMinorArcana("cups", "two")},
+		{Card: card.NewMinorArcana("swords", "ace"), Reversed: true},
+		{Card: card.NewMinorArcana("wands", "ace")},
+	}
+	r := readAces(dealt)
+	if r.Answer != No || r.Confidence != 0.5 || len(r.Dealt) != 3 || len(r.Supporting) != 1 {
+		t.Errorf("readAces = %+v, want no at 0.5 after 3 cards", r)
+	}
+	if r := readAces(dealt[:2]); r.Answer != Unclear || r.Supporting != nil {
+		t.Errorf("readAces without an ace = %+v, want unclear", r)
+	}
+}
+
+func TestReadIsReproducible(t *testing.T) {
+	var cards []*card.Card
+	for i := 0; i < 22; i++ {
+		cards = append(cards, card.NewMajorArcana(i))
+	}
+	m, err := Get("reversals")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := m.Read(cards, Options{Cards: 5}, rand.New(rand.NewSource(7)))
+	b, _ := m.Read(cards, Options{Cards: 5}, rand.New(rand.NewSource(7)))
+	for i := range a.Dealt {
+		if a.Dealt[i] != b.Dealt[i] {
+			t.Fatalf("same seed dealt %v and %v", a.Dealt[i], b.Dealt[i])
+		}
+	}
+	if _, err := m.Read(cards, Options{Cards: 30}, rand.New(rand.NewSource(7))); err == nil {
+		t.Error("Read with more cards than the deck succeeded")
+	}
+}
@@ -0,0 +1,53 @@
+// Package client calls the gRPC API of cartomancer serve, started with
+// --grpc-addr. The generated service client and messages are in the
+// cartomancerv1 package; New dials a server and embeds its client.
+package client
+
+import (
+	"context"
+
+	"github.com/arcanaland/cartomancer/pkg/client/cartomancerv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Client is a connection to a cartomancer gRPC server
+type Client struct {
+	cartomancerv1.CartomancerClient
+	conn *grpc.ClientConn
+}
+
+// New connects to the server at target, e.g. "127.0.0.1:9090". A non-empty
+// token is sent with every call, for servers that require one. The
+// connection is in plain text, like the server's, unless opts give
+// transport credentials.
+func New(target, token string, opts ...grpc.DialOption) (*Client, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{CartomancerClient: cartomancerv1.NewCartomancerClient(conn), conn: conn}, nil
+}
+
+// Close closes the connection to the server
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// bearerToken sends a token as the server's access policy expects it
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity allows tokens over plain text, as the server
+// does not serve TLS itself
+func (t bearerToken) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Color vision deficiencies that colors can be simulated or compensated for
+const (
+	Deuteranopia = "deuteranopia" // No green cones, the most common
+	Protanopia   = "protanopia"   // No red cones
+	Tritanopia   = "tritanopia"   // No blue cones
+)
+
+// simulatePrefix marks profiles that show colors as seen with a deficiency
+// rather than compensating for it
+const simulatePrefix = "simulate-"
+
+// Deficiencies lists the supported color vision deficiencies
+func Deficiencies() []string {
+	return []string{Deuteranopia, Protanopia, Tritanopia}
+}
+
+// CVDProfiles lists the color vision profiles: each deficiency, which shifts
+// colors that look alike with it apart, and simulate-<deficiency>, which shows
+// colors as seen with it, for artists checking their work
+func CVDProfiles() []string {
+	profiles := Deficiencies()
+	for _, d := range Deficiencies() {
+		profiles = append(profiles, simulatePrefix+d)
+	}
+	return profiles
+}
+
+// cvdMatrices simulate each deficiency at full severity in linear RGB
+// (Machado, Oliveira and Fernandes, 2009)
+var cvdMatrices = map[string][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// shiftMatrices move the difference a deficiency hides into the channels
+// still seen (daltonization)
+var shiftMatrices = map[string][3][3]float64{
+	Protanopia:   {{0, 0, 0}, {0.7, 1, 0}, {0.7, 0, 1}},
+	Deuteranopia: {{0, 0, 0}, {0.7, 1, 0}, {0.7, 0, 1}},
+	Tritanopia:   {{1, 0, 0.7}, {0, 1, 0.7}, {0, 0, 0}},
+}
+
+// SimulateCVD returns a color as seen with a color vision deficiency
+func SimulateCVD(c color.RGBA, deficiency string) color.RGBA {
+	r, g, b := colorful.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255}.LinearRgb()
+	r, g, b = apply(cvdMatrices[deficiency], r, g, b)
+	return toRGBA(colorful.LinearRgb(clamp(r), clamp(g), clamp(b)), c.A)
+}
+
+// CompensateCVD returns a color shifted so that colors which look alike with
+// a color vision deficiency are told apart more easily
+func CompensateCVD(c color.RGBA, deficiency string) color.RGBA {
+	r, g, b := colorful.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255}.LinearRgb()
+	sr, sg, sb := apply(cvdMatrices[deficiency], r, g, b)
+	dr, dg, db := apply(shiftMatrices[deficiency], r-sr, g-sg, b-sb)
+	return toRGBA(colorful.LinearRgb(clamp(r+dr), clamp(g+dg), clamp(b+db)), c.A)
+}
+
+// CVDTransform returns the color transform of a profile from CVDProfiles,
+// for Options.Transform and TransformANSI
+func CVDTransform(profile string) (func(color.RGBA) color.RGBA, error) {
+	deficiency, simulate := strings.CutPrefix(profile, simulatePrefix)
+	if _, ok := cvdMatrices[deficiency]; !ok {
+		return nil, fmt.Errorf("unknown color vision profile: %s (supported: %s)", profile, strings.Join(CVDProfiles(), ", "))
+	}
+	if simulate {
+		return func(c color.RGBA) color.RGBA { return SimulateCVD(c, deficiency) }, nil
+	}
+	return func(c color.RGBA) color.RGBA { return CompensateCVD(c, deficiency) }, nil
+}
+
+// apply multiplies a color by a matrix
+func apply(m [3][3]float64, r, g, b float64) (float64, float64, float64) {
+	return m[0][0]*r + m[0][1]*g + m[0][2]*b,
+		m[1][0]*r + m[1][1]*g + m[1][2]*b,
+		m[2][0]*r + m[2][1]*g + m[2][2]*b
+}
+
+// clamp limits a color component to the range 0-1
+func clamp(v float64) float64 {
+	return math.Min(math.Max(v, 0), 1)
+}
+
+// toRGBA converts a colorful.Color to 8-bit components, rounding
+func toRGBA(c colorful.Color, alpha uint8) color.RGBA {
+	return color.RGBA{
+		R: uint8(math.Round(c.R * 255)),
+		G: uint8(math.Round(c.G * 255)),
+		B: uint8(math.Round(c.B * 255)),
+		A: alpha,
+	}
+}
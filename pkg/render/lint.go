@@ -0,0 +1,103 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LintANSI reports problems that make hand-written ANSI art draw wrongly:
+// lines of a different width from the rest, lines that leave colors set, which
+// bleed past the art into the rest of the terminal line, escape sequences
+// other than colors, which move the cursor or change the terminal, and tabs,
+// whose width depends on the terminal. Problems name the line they are on.
+func LintANSI(art string) []string {
+	if art == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(art, "\n"), "\n")
+
+	// Problems are gathered by line, so they are reported in line order
+	found := make([][]string, len(lines))
+	add := func(n int, format string, args ...any) {
+		found[n] = append(found[n], fmt.Sprintf("line %d: "+format, append([]any{n + 1}, args...)...))
+	}
+	widths := make([]int, len(lines))
+	for n, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		styled, tab := false, false
+		runes := []rune(line)
+		for i := 0; i < len(runes); i++ {
+			switch r := runes[i]; {
+			case r == '\033':
+				if i+1 >= len(runes) || runes[i+1] != '[' {
+					add(n, "escape character not starting a control sequence")
+					continue
+				}
+				end := i + 2
+				for end < len(runes) && (runes[end] < '@' || runes[end] > '~') {
+					end++
+				}
+				if end == len(runes) {
+					add(n, "escape sequence cut off at the end of the line")
+					i = end
+					continue
+				}
+				sequence := "ESC[" + string(runes[i+2:end+1])
+				if runes[end] != 'm' {
+					add(n, "%s is not a color, so it moves the cursor or changes the terminal", sequence)
+				} else {
+					styled = !isReset(string(runes[i+2 : end]))
+				}
+				i = end
+			case r == '\t':
+				tab = true
+				widths[n] += 8 - widths[n]%8
+			case r >= ' ':
+				widths[n]++
+			}
+		}
+		if tab {
+			add(n, "tabs are as wide as the terminal's tab stops; use spaces")
+		}
+		if styled {
+			add(n, "colors are still set at the end of the line; end it with ESC[0m so they do not bleed past the art")
+		}
+	}
+
+	if width := commonWidth(widths); width > 0 {
+		for n, w := range widths {
+			if w != width {
+				add(n, "%d columns wide, but most lines are %d", w, width)
+			}
+		}
+	}
+
+	var problems []string
+	for _, line := range found {
+		problems = append(problems, line...)
+	}
+	return problems
+}
+
+// isReset reports whether SGR parameters only reset the colors and attributes
+func isReset(params string) bool {
+	for _, code := range strings.Split(params, ";") {
+		if code != "" && strings.Trim(code, "0") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// commonWidth returns the most common of the widths, the widest on a tie
+func commonWidth(widths []int) int {
+	counts := make(map[int]int)
+	best := 0
+	for _, w := range widths {
+		counts[w]++
+		if counts[w] > counts[best] || counts[w] == counts[best] && w > best {
+			best = w
+		}
+	}
+	return best
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Color depths of Options.Colors
+const (
+	ColorsTrue = 0   // 24-bit color
+	Colors256  = 256 // The xterm 256-color palette
+	Colors16   = 16  // The 16 standard terminal colors, as the terminal's theme shows them
+)
+
+// xterm16 are the colors xterm uses for the 16 standard colors, which themes
+// change, so art in 16 colors only approximates the image
+var xterm16 = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the component levels of the 6x6x6 color cube of the
+// 256-color palette
+var cubeLevels = []uint8{0, 95, 135, 175, 215, 255}
+
+// quantizer maps the pixels of each row to the nearest palette color, and
+// with dithering carries the difference over to the pixels right of and
+// below each one (Floyd-Steinberg)
+type quantizer struct {
+	palette []colorful.Color
+	offset  int // Palette index of palette[0]
+	dither  bool
+
+	errs, nextErrs []colorful.Color // Error carried to the current and next row
+}
+
+// newQuantizer returns a quantizer for rows of width pixels in a color depth,
+// or nil for 24-bit color
+func newQuantizer(colors int, dither bool, width int) (*quantizer, error) {
+	q := &quantizer{dither: dither}
+	switch colors {
+	case ColorsTrue:
+		return nil, nil
+	case Colors16:
+		for _, rgb := range xterm16 {
+			q.palette = append(q.palette, rgbColor(rgb[0], rgb[1], rgb[2]))
+		}
+	case Colors256:
+		// The 6x6x6 color cube and the gray ramp. The first 16 colors are
+		// left out, since themes change them.
+		for _, r := range cubeLevels {
+			for _, g := range cubeLevels {
+				for _, b := range cubeLevels {
+					q.palette = append(q.palette, rgbColor(r, g, b))
+				}
+			}
+		}
+		for i := 0; i < 24; i++ {
+			gray := uint8(8 + 10*i)
+			q.palette = append(q.palette, rgbColor(gray, gray, gray))
+		}
+		q.offset = 16
+	default:
+		return nil, fmt.Errorf("unsupported color depth: %d (supported: %d, %d or %d for 24-bit)", colors, Colors256, Colors16, ColorsTrue)
+	}
+	if dither {
+		q.errs = make([]colorful.Color, width+2)
+		q.nextErrs = make([]colorful.Color, width+2)
+	}
+	return q, nil
+}
+
+// rgbColor converts 8-bit color components to a colorful.Color
+func rgbColor(r, g, b uint8) colorful.Color {
+	return colorful.Color{R: float64(r) / 255, G: float64(g) / 255, B: float64(b) / 255}
+}
+
+// row replaces the pixels of a row with their palette colors and returns
+// their palette indices. Rows must be passed top to bottom.
+func (q *quantizer) row(pixels []colorful.Color) []int {
+	indices := make([]int, len(pixels))
+	for x, c := range pixels {
+		if q.dither {
+			// errs is offset by one so the pixel left of the first has a slot
+			e := q.errs[x+1]
+			c = colorful.Color{R: c.R + e.R, G: c.G + e.G, B: c.B + e.B}.Clamped()
+		}
+		i := q.nearest(c)
+		indices[x] = i + q.offset
+		pixels[x] = q.palette[i]
+
+		if q.dither {
+			diff := colorful.Color{R: c.R - q.palette[i].R, G: c.G - q.palette[i].G, B: c.B - q.palette[i].B}
+			spread(&q.errs[x+2], diff, 7.0/16)
+			spread(&q.nextErrs[x], diff, 3.0/16)
+			spread(&q.nextErrs[x+1], diff, 5.0/16)
+			spread(&q.nextErrs[x+2], diff, 1.0/16)
+		}
+	}
+	if q.dither {
+		q.errs, q.nextErrs = q.nextErrs, q.errs
+		clear(q.nextErrs)
+	}
+	return indices
+}
+
+// spread adds a share of a pixel's error to a neighbor's
+func spread(dst *colorful.Color, diff colorful.Color, share float64) {
+	dst.R += diff.R * share
+	dst.G += diff.G * share
+	dst.B += diff.B * share
+}
+
+// nearest returns the index in the palette of the color closest to c, by a
+// distance weighted for how the eye sees red, green and blue
+func (q *quantizer) nearest(c colorful.Color) int {
+	best, bestDist := 0, -1.0
+	for i, p := range q.palette {
+		rMean := (c.R + p.R) / 2
+		dr, dg, db := c.R-p.R, c.G-p.G, c.B-p.B
+		dist := (2+rMean)*dr*dr + 4*dg*dg + (3-rMean)*db*db
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// PaletteColor returns the color xterm shows for an index of the 256-color
+// palette, whose first 16 colors are the standard colors
+func PaletteColor(index int) color.RGBA {
+	switch {
+	case index < 0 || index > 255:
+		return color.RGBA{A: 255}
+	case index < 16:
+		rgb := xterm16[index]
+		return color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 255}
+	case index < 232:
+		i := index - 16
+		return color.RGBA{R: cubeLevels[i/36], G: cubeLevels[i/6%6], B: cubeLevels[i%6], A: 255}
+	default:
+		gray := uint8(8 + 10*(index-232))
+		return color.RGBA{R: gray, G: gray, B: gray, A: 255}
+	}
+}
@@ -0,0 +1,232 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Colors a terminal shows text in before any escape sequence sets them
+var (
+	DefaultForeground = color.RGBA{R: 229, G: 229, B: 229, A: 255}
+	DefaultBackground = color.RGBA{A: 255}
+)
+
+// Cell is one character cell of ANSI art
+type Cell struct {
+	Char rune
+	FG   color.RGBA
+	BG   color.RGBA
+}
+
+// ParseANSI splits ANSI art into rows of cells, following the color escape
+// sequences: 24-bit, 256-color and the 16 standard colors, as well as reset,
+// bold (which brightens the standard colors) and reverse video. Other escape
+// sequences are skipped, tabs stop every eight columns and rows are padded
+// with blank cells to the width of the widest.
+func ParseANSI(art string) [][]Cell {
+	fg, bg := DefaultForeground, DefaultBackground
+	fgIndex := -1 // Standard color of the foreground, brightened by bold
+	bold, reverse := false, false
+
+	cell := func(r rune) Cell {
+		c := Cell{Char: r, FG: fg, BG: bg}
+		if bold && fgIndex >= 0 && fgIndex < 8 {
+			c.FG = PaletteColor(fgIndex + 8)
+		}
+		if reverse {
+			c.FG, c.BG = c.BG, c.FG
+		}
+		return c
+	}
+
+	var rows [][]Cell
+	var row []Cell
+	width := 0
+	runes := []rune(strings.TrimSuffix(art, "\n"))
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\n':
+			rows = append(rows, row)
+			width = max(width, len(row))
+			row = nil
+		case '\r':
+		case '\t':
+			row = append(row, cell(' '))
+			for len(row)%8 != 0 {
+				row = append(row, cell(' '))
+			}
+		case '\033':
+			if i+1 >= len(runes) || runes[i+1] != '[' {
+				continue
+			}
+			// A control sequence runs to its final byte, from @ to ~
+			end := i + 2
+			for end < len(runes) && (runes[end] < '@' || runes[end] > '~') {
+				end++
+			}
+			if end < len(runes) && runes[end] == 'm' {
+				params := string(runes[i+2 : end])
+				fg, bg, fgIndex, bold, reverse = applySGR(params, fg, bg, fgIndex, bold, reverse)
+			}
+			i = end
+		default:
+			if r >= ' ' {
+				row = append(row, cell(r))
+			}
+		}
+	}
+	if len(row) > 0 || len(runes) > 0 {
+		rows = append(rows, row)
+		width = max(width, len(row))
+	}
+
+	for i := range rows {
+		for len(rows[i]) < width {
+			rows[i] = append(rows[i], Cell{Char: ' ', FG: DefaultForeground, BG: DefaultBackground})
+		}
+	}
+	return rows
+}
+
+// applySGR applies the parameters of a Select Graphic Rendition sequence to
+// the current colors and attributes
+func applySGR(params string, fg, bg color.RGBA, fgIndex int, bold, reverse bool) (color.RGBA, color.RGBA, int, bool, bool) {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil && codes[i] != "" {
+			continue
+		}
+		switch {
+		case code == 0:
+			fg, bg, fgIndex, bold, reverse = DefaultForeground, DefaultBackground, -1, false, false
+		case code == 1:
+			bold = true
+		case code == 22:
+			bold = false
+		case code == 7:
+			reverse = true
+		case code == 27:
+			reverse = false
+		case code >= 30 && code <= 37:
+			fg, fgIndex = PaletteColor(code-30), code-30
+		case code >= 90 && code <= 97:
+			fg, fgIndex = PaletteColor(code-90+8), -1
+		case code == 39:
+			fg, fgIndex = DefaultForeground, -1
+		case code >= 40 && code <= 47:
+			bg = PaletteColor(code - 40)
+		case code >= 100 && code <= 107:
+			bg = PaletteColor(code - 100 + 8)
+		case code == 49:
+			bg = DefaultBackground
+		case code == 38 || code == 48:
+			c, n, ok := extendedColor(codes[i+1:])
+			i += n
+			if !ok {
+				continue
+			}
+			if code == 38 {
+				fg, fgIndex = c, -1
+			} else {
+				bg = c
+			}
+		}
+	}
+	return fg, bg, fgIndex, bold, reverse
+}
+
+// extendedColor parses the color of a 38 or 48 parameter, "5;n" or "2;r;g;b",
+// returning the number of parameters it used
+func extendedColor(codes []string) (color.RGBA, int, bool) {
+	if len(codes) == 0 {
+		return color.RGBA{}, 0, false
+	}
+	values := make([]int, 0, 4)
+	for _, code := range codes {
+		v, err := strconv.Atoi(code)
+		if err != nil {
+			break
+		}
+		values = append(values, v)
+	}
+	switch {
+	case len(values) >= 2 && values[0] == 5:
+		return PaletteColor(values[1]), 2, true
+	case len(values) >= 4 && values[0] == 2:
+		return color.RGBA{R: uint8(values[1]), G: uint8(values[2]), B: uint8(values[3]), A: 255}, 4, true
+	}
+	return color.RGBA{}, len(values), false
+}
+
+// TransformANSI applies a color transform to the colors of ANSI art, which are
+// written back as 24-bit colors. The terminal's default colors are left as
+// they are.
+func TransformANSI(art string, transform func(color.RGBA) color.RGBA) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(art, "\033[")
+		if start < 0 {
+			b.WriteString(art)
+			return b.String()
+		}
+		end := start + 2
+		for end < len(art) && (art[end] < '@' || art[end] > '~') {
+			end++
+		}
+		if end == len(art) {
+			b.WriteString(art)
+			return b.String()
+		}
+		b.WriteString(art[:start])
+		if art[end] == 'm' {
+			b.WriteString("\033[" + transformSGR(art[start+2:end], transform) + "m")
+		} else {
+			b.WriteString(art[start : end+1])
+		}
+		art = art[end+1:]
+	}
+}
+
+// transformSGR applies a color transform to the colors set by the parameters
+// of a Select Graphic Rendition sequence
+func transformSGR(params string, transform func(color.RGBA) color.RGBA) string {
+	codes := strings.Split(params, ";")
+	out := make([]string, 0, len(codes))
+	rgb := func(background bool, c color.RGBA) {
+		prefix := "38;2;"
+		if background {
+			prefix = "48;2;"
+		}
+		t := transform(c)
+		out = append(out, fmt.Sprintf("%s%d;%d;%d", prefix, t.R, t.G, t.B))
+	}
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		switch {
+		case err != nil:
+			out = append(out, codes[i])
+		case code >= 30 && code <= 37:
+			rgb(false, PaletteColor(code-30))
+		case code >= 90 && code <= 97:
+			rgb(false, PaletteColor(code-90+8))
+		case code >= 40 && code <= 47:
+			rgb(true, PaletteColor(code-40))
+		case code >= 100 && code <= 107:
+			rgb(true, PaletteColor(code-100+8))
+		case code == 38 || code == 48:
+			c, n, ok := extendedColor(codes[i+1:])
+			if ok {
+				rgb(code == 48, c)
+			} else {
+				out = append(out, codes[i:i+1+n]...)
+			}
+			i += n
+		default:
+			out = append(out, codes[i])
+		}
+	}
+	return strings.Join(out, ";")
+}
@@ -0,0 +1,308 @@
+// Package render converts card images into ANSI terminal art.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/nfnt/resize"
+)
+
+// Renderers of Options.Renderer
+const (
+	RendererHalfBlock = "halfblock" // Two pixels per cell, drawn with the upper half block
+	RendererASCII     = "ascii"     // One character per cell, picked by brightness, for fonts without block elements
+)
+
+// asciiRamp are the characters of the ASCII renderer, from dark to bright
+const asciiRamp = " .:-=+*#%@"
+
+// Options controls how an image is converted to ANSI art
+type Options struct {
+	Width     int    // Width in terminal columns
+	Height    int    // Height in terminal rows
+	TrueColor bool   // Emit color escapes; when false only the glyphs are written
+	Colors    int    // Color depth of the escapes: ColorsTrue, Colors256 or Colors16
+	Dither    bool   // Spread the error of palette colors over neighboring pixels
+	Renderer  string // RendererHalfBlock, the default, or RendererASCII
+
+	// Transform changes every color before it is written, such as to
+	// compensate for a color vision deficiency (see CVDTransform)
+	Transform func(color.RGBA) color.RGBA
+}
+
+// DefaultOptions returns the options used for cached card art
+func DefaultOptions() Options {
+	return Options{
+		Width:     40,
+		Height:    32,
+		TrueColor: true,
+	}
+}
+
+// RenderANSI converts an image to ANSI art and returns it as a string
+func RenderANSI(img image.Image, opts Options) (string, error) {
+	var buffer strings.Builder
+	if err := RenderToWriter(&buffer, img, opts); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// RenderToWriter converts an image to ANSI art and writes it to w, one line
+// at a time
+func RenderToWriter(w io.Writer, img image.Image, opts Options) error {
+	return RenderLines(img, opts, func(line []byte) error {
+		_, err := w.Write(append(line, '\n'))
+		return err
+	})
+}
+
+// Lines converts an image to ANSI art and returns its lines, without newlines
+func Lines(img image.Image, opts Options) ([]string, error) {
+	lines := make([]string, 0, opts.Height)
+	err := RenderLines(img, opts, func(line []byte) error {
+		lines = append(lines, string(line))
+		return nil
+	})
+	return lines, err
+}
+
+// RenderLines converts an image to ANSI art, calling fn with each line as it
+// is rendered, without a newline. The line is only valid during the call, so
+// callers streaming frames can write it out without building the whole art.
+func RenderLines(img image.Image, opts Options, fn func(line []byte) error) error {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return fmt.Errorf("invalid render size: %dx%d", opts.Width, opts.Height)
+	}
+
+	if opts.Renderer != "" && opts.Renderer != RendererHalfBlock && opts.Renderer != RendererASCII {
+		return fmt.Errorf("unknown renderer: %s (supported: %s, %s)", opts.Renderer, RendererHalfBlock, RendererASCII)
+	}
+	var q *quantizer
+	if opts.TrueColor {
+		var err error
+		if q, err = newQuantizer(opts.Colors, opts.Dither, opts.Width); err != nil {
+			return err
+		}
+	}
+
+	// Resize image to desired dimensions (doubled for half-block characters)
+	resized := resize.Resize(uint(opts.Width*2), uint(opts.Height*2), img, resize.Lanczos3)
+
+	// Process the image
+	var line []byte
+	upper := make([]colorful.Color, opts.Width)
+	lower := make([]colorful.Color, opts.Width)
+	for y := 0; y < opts.Height*2; y += 2 {
+		for x := 0; x < opts.Width; x++ {
+			// Get the four pixels that will make up one character cell
+			col1, _ := colorful.MakeColor(getColorAt(resized, 2*x, y))
+			col2, _ := colorful.MakeColor(getColorAt(resized, 2*x+1, y))
+			col3, _ := colorful.MakeColor(getColorAt(resized, 2*x, y+1))
+			col4, _ := colorful.MakeColor(getColorAt(resized, 2*x+1, y+1))
+
+			// The top pixels are the foreground of the upper half block and
+			// the bottom pixels its background
+			upper[x] = averageColor(col1, col2)
+			lower[x] = averageColor(col3, col4)
+			if opts.Transform != nil {
+				upper[x] = transformColor(upper[x], opts.Transform)
+				lower[x] = transformColor(lower[x], opts.Transform)
+			}
+		}
+
+		line = line[:0]
+		if opts.Renderer == RendererASCII {
+			for x := range upper {
+				upper[x] = averageColor(upper[x], lower[x])
+			}
+			brightness := make([]float64, len(upper))
+			for x, c := range upper {
+				brightness[x] = 0.2126*c.R + 0.7152*c.G + 0.0722*c.B
+			}
+			var indices []int
+			if q != nil {
+				indices = q.row(upper)
+			}
+			for x, c := range upper {
+				char := rune(asciiRamp[int(math.Round(min(max(brightness[x], 0), 1)*float64(len(asciiRamp)-1)))])
+				if !opts.TrueColor {
+					line = utf8.AppendRune(line, char)
+					continue
+				}
+				line = appendColor(line, c, paletteIndex(indices, x), opts.Colors, false)
+				line = utf8.AppendRune(line, char)
+				line = append(line, "\x1b[0m"...)
+			}
+		} else {
+			var upperIndices, lowerIndices []int
+			if q != nil {
+				upperIndices = q.row(upper)
+				lowerIndices = q.row(lower)
+			}
+			for x := range upper {
+				if !opts.TrueColor {
+					line = utf8.AppendRune(line, '▀')
+					continue
+				}
+				line = appendColor(line, upper[x], paletteIndex(upperIndices, x), opts.Colors, false)
+				line = appendColor(line, lower[x], paletteIndex(lowerIndices, x), opts.Colors, true)
+				line = utf8.AppendRune(line, '▀')
+				line = append(line, "\x1b[0m"...)
+			}
+		}
+
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// paletteIndex returns the palette index of pixel x, or 0 without a palette
+func paletteIndex(indices []int, x int) int {
+	if indices == nil {
+		return 0
+	}
+	return indices[x]
+}
+
+// getColorAt returns the color at a specific coordinate
+func getColorAt(img image.Image, x, y int) color.Color {
+	bounds := img.Bounds()
+	if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
+		return img.At(x, y)
+	}
+	return color.RGBA{0, 0, 0, 255} // Return black for out-of-bounds
+}
+
+// averageColor calculates the average of multiple colors
+func averageColor(colors ...colorful.Color) colorful.Color {
+	var r, g, b float64
+	for _, c := range colors {
+		r += c.R
+		g += c.G
+		b += c.B
+	}
+	count := float64(len(colors))
+	return colorful.Color{R: r / count, G: g / count, B: b / count}
+}
+
+// transformColor applies a color transform to a colorful.Color
+func transformColor(c colorful.Color, transform func(color.RGBA) color.RGBA) colorful.Color {
+	t := transform(colorfulToColor(c).(color.RGBA))
+	return rgbColor(t.R, t.G, t.B)
+}
+
+// colorfulToColor converts a colorful.Color to a standard color.Color
+func colorfulToColor(c colorful.Color) color.Color {
+	// Always return direct RGB values rather than mapping
+	r := uint8(c.R * 255)
+	g := uint8(c.G * 255)
+	b := uint8(c.B * 255)
+
+	return color.RGBA{R: r, G: g, B: b, A: 255}
+}
+
+// appendColor appends the escape sequence that sets the foreground color, or
+// the background color if background is set. With a palette the color is
+// written as its index in the palette.
+func appendColor(line []byte, c colorful.Color, index, colors int, background bool) []byte {
+	line = append(line, "\x1b["...)
+	switch colors {
+	case Colors256:
+		if background {
+			line = append(line, "48;5;"...)
+		} else {
+			line = append(line, "38;5;"...)
+		}
+		line = strconv.AppendInt(line, int64(index), 10)
+	case Colors16:
+		// 30-37 and 90-97 set the normal and bright foreground colors, and
+		// the background codes are 10 higher
+		code := 30 + index
+		if index >= 8 {
+			code = 90 + index - 8
+		}
+		if background {
+			code += 10
+		}
+		line = strconv.AppendInt(line, int64(code), 10)
+	default:
+		if background {
+			line = append(line, "48;2;"...)
+		} else {
+			line = append(line, "38;2;"...)
+		}
+		// RGBA returns values in the range 0-65535
+		r, g, b, _ := colorfulToColor(c).RGBA()
+		line = appendRGB(line, r>>8, g>>8, b>>8)
+	}
+	return append(line, 'm')
+}
+
+// appendRGB appends the color components of an escape sequence, separated by semicolons
+func appendRGB(line []byte, r, g, b uint32) []byte {
+	line = strconv.AppendUint(line, uint64(r), 10)
+	line = append(line, ';')
+	line = strconv.AppendUint(line, uint64(g), 10)
+	line = append(line, ';')
+	return strconv.AppendUint(line, uint64(b), 10)
+}
+
+// FlipFrames returns the frames of a card turning over from its back to its
+// face: the back narrows to an edge as it turns away, then the face widens
+// back to full width. Each side takes steps frames, narrowed by the cosine of
+// the turn angle, and every frame is centered in opts.Width columns so the
+// frames can be drawn over each other. The last frame is the full face.
+func FlipFrames(back, face image.Image, opts Options, steps int) ([][]string, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("invalid flip steps: %d", steps)
+	}
+
+	frame := func(img image.Image, width int) ([]string, error) {
+		narrowed := opts
+		narrowed.Width = max(width, 1)
+		lines, err := Lines(img, narrowed)
+		if err != nil {
+			return nil, err
+		}
+		left := strings.Repeat(" ", (opts.Width-narrowed.Width)/2)
+		right := strings.Repeat(" ", opts.Width-narrowed.Width-len(left))
+		for i, line := range lines {
+			lines[i] = left + line + right
+		}
+		return lines, nil
+	}
+
+	// width returns the width of the card turned k steps away from flat
+	width := func(k int) int {
+		return int(math.Round(float64(opts.Width) * math.Cos(float64(k)/float64(steps)*math.Pi/2)))
+	}
+
+	frames := make([][]string, 0, 2*steps)
+	for k := 0; k < steps; k++ {
+		lines, err := frame(back, width(k))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, lines)
+	}
+	for k := steps - 1; k >= 0; k-- {
+		lines, err := frame(face, width(k))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, lines)
+	}
+	return frames, nil
+}
@@ -0,0 +1,274 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/arcanaland/cartomancer/internal/testutil"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+func TestRenderANSIGolden(t *testing.T) {
+	img := testutil.FixtureImage(16, 16)
+
+	sizes := []struct{ width, height int }{{4, 2}, {8, 4}}
+	for _, size := range sizes {
+		for _, trueColor := range []bool{true, false} {
+			name := fmt.Sprintf("gradient_%dx%d_truecolor_%t", size.width, size.height, trueColor)
+			t.Run(name, func(t *testing.T) {
+				out, err := RenderANSI(img, Options{Width: size.width, Height: size.height, TrueColor: trueColor})
+				if err != nil {
+					t.Fatalf("RenderANSI: %v", err)
+				}
+				testutil.Golden(t, name, []byte(out))
+			})
+		}
+	}
+}
+
+func TestRenderPaletteGolden(t *testing.T) {
+	img := testutil.FixtureImage(16, 16)
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"gradient_8x4_colors_256", Options{Colors: Colors256}},
+		{"gradient_8x4_colors_256_dither", Options{Colors: Colors256, Dither: true}},
+		{"gradient_8x4_colors_16_dither", Options{Colors: Colors16, Dither: true}},
+		{"gradient_8x4_ascii", Options{Renderer: RendererASCII}},
+		{"gradient_8x4_ascii_colors_16", Options{Renderer: RendererASCII, Colors: Colors16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Width, opts.Height, opts.TrueColor = 8, 4, true
+			out, err := RenderANSI(img, opts)
+			if err != nil {
+				t.Fatalf("RenderANSI: %v", err)
+			}
+			if opts.Colors != ColorsTrue && strings.Contains(out, ";2;") {
+				t.Errorf("palette art contains 24-bit escapes: %q", out)
+			}
+			testutil.Golden(t, tt.name, []byte(out))
+		})
+	}
+}
+
+func TestRenderInvalidOptions(t *testing.T) {
+	img := testutil.FixtureImage(2, 2)
+	if _, err := RenderANSI(img, Options{Width: 2, Height: 2, TrueColor: true, Colors: 88}); err == nil {
+		t.Error("expected an error for an unsupported color depth")
+	}
+	if _, err := RenderANSI(img, Options{Width: 2, Height: 2, Renderer: "braille"}); err == nil {
+		t.Error("expected an error for an unknown renderer")
+	}
+}
+
+func TestRenderFixtureCard(t *testing.T) {
+	deckPath := testutil.FixtureDeck(t)
+
+	f, err := os.Open(filepath.Join(deckPath, "h14", "major_arcana", "00.png"))
+	if err != nil {
+		t.Fatalf("opening fixture card: %v", err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("decoding fixture card: %v", err)
+	}
+
+	out, err := RenderANSI(img, Options{Width: 4, Height: 7, TrueColor: true})
+	if err != nil {
+		t.Fatalf("RenderANSI: %v", err)
+	}
+	testutil.Golden(t, "fixture_major_00_4x7", []byte(out))
+}
+
+func TestRenderInvalidSize(t *testing.T) {
+	if _, err := RenderANSI(testutil.FixtureImage(2, 2), Options{Width: 0, Height: 4}); err == nil {
+		t.Fatal("expected an error for zero width")
+	}
+}
+
+func TestRenderLinesMatchesRenderANSI(t *testing.T) {
+	img := testutil.FixtureImage(16, 16)
+	opts := Options{Width: 8, Height: 4, TrueColor: true}
+
+	art, err := RenderANSI(img, opts)
+	if err != nil {
+		t.Fatalf("RenderANSI: %v", err)
+	}
+	lines, err := Lines(img, opts)
+	if err != nil {
+		t.Fatalf("Lines: %v", err)
+	}
+	if got := strings.Join(lines, "\n") + "\n"; got != art {
+		t.Errorf("Lines joined = %q, want %q", got, art)
+	}
+
+	// Returning an error stops rendering
+	stop := errors.New("stop")
+	calls := 0
+	err = RenderLines(img, opts, func(line []byte) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("RenderLines = %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+func TestFlipFrames(t *testing.T) {
+	back := testutil.FixtureImage(16, 16)
+	face := testutil.FixtureImage(20, 16)
+	opts := Options{Width: 8, Height: 4}
+
+	frames, err := FlipFrames(back, face, opts, 4)
+	if err != nil {
+		t.Fatalf("FlipFrames: %v", err)
+	}
+	if len(frames) != 8 {
+		t.Fatalf("got %d frames, want 8", len(frames))
+	}
+
+	// Without color every cell is a half block, so the art width shows in
+	// the glyphs and each frame keeps the full width with padding
+	widths := make([]int, len(frames))
+	for i, frame := range frames {
+		if len(frame) != opts.Height {
+			t.Fatalf("frame %d has %d lines, want %d", i, len(frame), opts.Height)
+		}
+		for _, line := range frame {
+			if n := utf8.RuneCountInString(line); n != opts.Width {
+				t.Fatalf("frame %d line %q is %d columns, want %d", i, line, n, opts.Width)
+			}
+		}
+		widths[i] = strings.Count(frame[0], "▀")
+	}
+	if want := []int{8, 7, 6, 3, 3, 6, 7, 8}; !slices.Equal(widths, want) {
+		t.Errorf("frame widths = %v, want %v", widths, want)
+	}
+
+	last, err := Lines(face, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(frames[len(frames)-1], last) {
+		t.Error("last frame is not the full face")
+	}
+
+	if _, err := FlipFrames(back, face, opts, 0); err == nil {
+		t.Error("expected an error for zero steps")
+	}
+}
+
+func TestParseANSI(t *testing.T) {
+	art, err := RenderANSI(testutil.FixtureImage(16, 16), Options{Width: 4, Height: 2, TrueColor: true, Colors: Colors256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := ParseANSI(art)
+	if len(rows) != 2 || len(rows[0]) != 4 {
+		t.Fatalf("parsed %d rows of %d cells, want 2 of 4", len(rows), len(rows[0]))
+	}
+	first := rows[0][0]
+	if first.Char != '▀' || first.FG != PaletteColor(18) {
+		t.Errorf("first cell = %q in %v, want ▀ in %v", first.Char, first.FG, PaletteColor(18))
+	}
+
+	rows = ParseANSI("\x1b[1;31mA\x1b[7mB\x1b[0m\tC\x1b[38;2;1;2;3;48;5;16mD\nE")
+	if len(rows) != 2 || len(rows[0]) != 10 || len(rows[1]) != 10 {
+		t.Fatalf("parsed rows %v, want 2 rows of 10 cells", rows)
+	}
+	tests := []struct {
+		cell   Cell
+		char   rune
+		fg, bg color.RGBA
+	}{
+		{rows[0][0], 'A', PaletteColor(9), DefaultBackground},
+		{rows[0][1], 'B', DefaultBackground, PaletteColor(9)},
+		{rows[0][2], ' ', DefaultForeground, DefaultBackground},
+		{rows[0][8], 'C', DefaultForeground, DefaultBackground},
+		{rows[0][9], 'D', color.RGBA{R: 1, G: 2, B: 3, A: 255}, PaletteColor(16)},
+		// Colors carry over to the next line, as in a terminal
+		{rows[1][0], 'E', color.RGBA{R: 1, G: 2, B: 3, A: 255}, PaletteColor(16)},
+	}
+	for i, tt := range tests {
+		if tt.cell.Char != tt.char || tt.cell.FG != tt.fg || tt.cell.BG != tt.bg {
+			t.Errorf("cell %d = %q %v on %v, want %q %v on %v", i, tt.cell.Char, tt.cell.FG, tt.cell.BG, tt.char, tt.fg, tt.bg)
+		}
+	}
+}
+
+func TestLintANSI(t *testing.T) {
+	art, err := RenderANSI(testutil.FixtureImage(16, 16), Options{Width: 8, Height: 4, TrueColor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := LintANSI(art); len(problems) != 0 {
+		t.Errorf("rendered art has lint problems: %v", problems)
+	}
+
+	art = "\x1b[31mabcd\x1b[0m\n\x1b[31mabcd\nab\tcd\x1b[0m\n\x1b[2Jabcd\n\x1b[0;0mabcd\x1b[0"
+	want := []string{
+		"line 2: colors are still set at the end of the line; end it with ESC[0m so they do not bleed past the art",
+		"line 3: tabs are as wide as the terminal's tab stops; use spaces",
+		"line 3: 10 columns wide, but most lines are 4",
+		"line 4: ESC[2J is not a color, so it moves the cursor or changes the terminal",
+		"line 5: escape sequence cut off at the end of the line",
+	}
+	if got := LintANSI(art); !slices.Equal(got, want) {
+		t.Errorf("LintANSI =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCVDTransform(t *testing.T) {
+	red := color.RGBA{R: 200, G: 60, B: 40, A: 255}
+	green := color.RGBA{R: 70, G: 150, B: 40, A: 255}
+	distance := func(a, b color.RGBA) float64 {
+		ca, _ := colorful.MakeColor(a)
+		cb, _ := colorful.MakeColor(b)
+		return ca.DistanceCIEDE2000(cb)
+	}
+
+	// Red and green draw closer together with deuteranopia, and compensating
+	// moves them further apart again
+	seen := distance(SimulateCVD(red, Deuteranopia), SimulateCVD(green, Deuteranopia))
+	if seen >= distance(red, green) {
+		t.Errorf("simulated distance %.3f is not below the original %.3f", seen, distance(red, green))
+	}
+	compensated := distance(SimulateCVD(CompensateCVD(red, Deuteranopia), Deuteranopia), SimulateCVD(CompensateCVD(green, Deuteranopia), Deuteranopia))
+	if compensated <= seen {
+		t.Errorf("compensated distance %.3f is not above the simulated %.3f", compensated, seen)
+	}
+
+	for _, profile := range CVDProfiles() {
+		if _, err := CVDTransform(profile); err != nil {
+			t.Errorf("CVDTransform(%q): %v", profile, err)
+		}
+	}
+	if _, err := CVDTransform("achromatopsia"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestTransformANSI(t *testing.T) {
+	invert := func(c color.RGBA) color.RGBA {
+		return color.RGBA{R: 255 - c.R, G: 255 - c.G, B: 255 - c.B, A: c.A}
+	}
+	art := "\x1b[1;31mA\x1b[38;5;16;48;2;10;20;30mB\x1b[2K\x1b[0m\n"
+	want := "\x1b[1;38;2;50;255;255mA\x1b[38;2;255;255;255;48;2;245;235;225mB\x1b[2K\x1b[0m\n"
+	if got := TransformANSI(art, invert); got != want {
+		t.Errorf("TransformANSI = %q, want %q", got, want)
+	}
+}